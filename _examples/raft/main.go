@@ -32,7 +32,6 @@ var (
 	fsm        *stateMachine
 	httpAddr   string
 	raftAddr   string
-	stateCh    chan raft.StateType
 )
 
 func init() {
@@ -43,11 +42,9 @@ func init() {
 	flag.Parse()
 
 	startOpts = append(startOpts, raft.WithAddress(*addr))
-	stateCh = make(chan raft.StateType, 1)
 	opts = append(
 		opts,
 		raft.WithStateDIR(*state),
-		raft.WithStateChangeCh(stateCh),
 		raft.WithPreVote(),
 		raft.WithCheckQuorum(),
 		raft.WithTickInterval(10*time.Millisecond),
@@ -100,11 +97,12 @@ func main() {
 
 	go func() {
 		last := raft.StateType(0)
-		for s := range stateCh {
-			if s != last {
-				log.Printf("node state changed from %v to %v", last, s)
-				last = s
+		for li := range node.LeaderChanges() {
+			if li.State != last {
+				log.Printf("node state changed from %v to %v", last, li.State)
+				last = li.State
 			}
+			log.Printf("leader %x (%s) at term %d", li.ID, li.Address, li.Term)
 		}
 	}()
 
@@ -0,0 +1,207 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	rafterrors "github.com/shaj13/raft/errors"
+	"github.com/shaj13/raft/internal/membership"
+	"github.com/shaj13/raft/internal/raftengine"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/raftlog"
+)
+
+var (
+	// ErrJoinDenied is returned by the Join and PromoteMember handlers,
+	// when the request rejected by the admission policy.
+	ErrJoinDenied = raftengine.ErrJoinDenied
+	// ErrRateLimited is returned by the Join and PromoteMember handlers,
+	// when the request source exceeded the admission policy rate limit.
+	ErrRateLimited = rafterrors.ErrRateLimited
+)
+
+// AdmissionPolicy define the server side policy of the join and promote member requests,
+// so an errant script can't flood the cluster with conf changes, See WithAdmissionPolicy.
+type AdmissionPolicy struct {
+	// MaxLearners limits the number of learner and staging members at once,
+	// join requests of new non-voting members rejected beyond the limit.
+	// A zero value means no limit.
+	MaxLearners int
+	// AllowList restricts the requests sources to the given IP addresses or CIDR ranges.
+	// An empty list means all sources allowed.
+	AllowList []string
+	// RateLimit limits the number of requests per source within the RateInterval.
+	// A zero value means no limit.
+	RateLimit int
+	// RateInterval is the rate limit window, Default Value: 1m.
+	RateInterval time.Duration
+	// Admit is an optional function called with the request member,
+	// after the other checks passed, to admit or reject the request,
+	// The request source address can be retrieved using SourceFromContext.
+	Admit func(ctx context.Context, m RawMember) error
+}
+
+// SourceFromContext returns the network address of the join or promote member request source,
+// or an empty string if it's unknown.
+func SourceFromContext(ctx context.Context) string {
+	return transport.SourceFromContext(ctx)
+}
+
+func newAdmission(p *AdmissionPolicy, logger raftlog.Logger) *admission {
+	if p == nil {
+		return nil
+	}
+
+	a := &admission{
+		policy:  *p,
+		windows: make(map[string]*window),
+	}
+
+	if a.policy.RateInterval <= 0 {
+		a.policy.RateInterval = time.Minute
+	}
+
+	for _, v := range p.AllowList {
+		if _, ipnet, err := net.ParseCIDR(v); err == nil {
+			a.nets = append(a.nets, ipnet)
+			continue
+		}
+
+		ip := net.ParseIP(v)
+		if ip == nil {
+			// the entry ignored, therefore its source rejected.
+			logger.Warningf("raft: ignoring invalid admission allow list entry %q", v)
+			continue
+		}
+
+		a.nets = append(a.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+	}
+
+	return a
+}
+
+// admission enforces the admission policy.
+type admission struct {
+	policy  AdmissionPolicy
+	nets    []*net.IPNet
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// window holds the number of requests of a source within the rate limit window.
+type window struct {
+	start time.Time
+	count int
+}
+
+// admit returns error if the request of the given member is rejected,
+// the join argument reports whether the request is a join request.
+func (a *admission) admit(ctx context.Context, pool membership.Pool, m raftpb.Member, join bool) error {
+	if a == nil {
+		return nil
+	}
+
+	host := SourceFromContext(ctx)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if err := a.allowed(host); err != nil {
+		return err
+	}
+
+	if err := a.limit(host); err != nil {
+		return err
+	}
+
+	if join {
+		if err := a.maxLearners(pool, m); err != nil {
+			return err
+		}
+	}
+
+	if a.policy.Admit != nil {
+		if err := a.policy.Admit(ctx, m); err != nil {
+			return fmt.Errorf("%w: %v", ErrJoinDenied, err)
+		}
+	}
+
+	return nil
+}
+
+// maxLearners returns error if the given member joins as a new non-voting member,
+// while the max learners already reached.
+func (a *admission) maxLearners(pool membership.Pool, m raftpb.Member) error {
+	if a.policy.MaxLearners <= 0 || m.Type != raftpb.LearnerMember && m.Type != raftpb.StagingMember {
+		return nil
+	}
+
+	// existing members re-joining does not add new learners.
+	if _, ok := pool.Get(m.ID); ok {
+		return nil
+	}
+
+	learners := 0
+	for _, mem := range pool.Members() {
+		if t := mem.Type(); t == raftpb.LearnerMember || t == raftpb.StagingMember {
+			learners++
+		}
+	}
+
+	if learners >= a.policy.MaxLearners {
+		return fmt.Errorf("%w: reached max learners %d", ErrJoinDenied, a.policy.MaxLearners)
+	}
+
+	return nil
+}
+
+func (a *admission) allowed(host string) error {
+	if len(a.policy.AllowList) == 0 {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		for _, ipnet := range a.nets {
+			if ipnet.Contains(ip) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: source %q not allowed", ErrJoinDenied, host)
+}
+
+func (a *admission) limit(host string) error {
+	if a.policy.RateLimit <= 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+
+	// drop expired windows to not grow with the number of sources.
+	for k, w := range a.windows {
+		if now.Sub(w.start) >= a.policy.RateInterval {
+			delete(a.windows, k)
+		}
+	}
+
+	w, ok := a.windows[host]
+	if !ok {
+		w = &window{start: now}
+		a.windows[host] = w
+	}
+
+	if w.count >= a.policy.RateLimit {
+		return fmt.Errorf("%w: source %q", ErrRateLimited, host)
+	}
+
+	w.count++
+	return nil
+}
@@ -0,0 +1,71 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shaj13/raft/internal/membership"
+	membershipmock "github.com/shaj13/raft/internal/mocks/membership"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/raftlog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pool := membershipmock.NewMockPool(ctrl)
+	learner := membershipmock.NewMockMember(ctrl)
+	learner.EXPECT().Type().Return(raftpb.LearnerMember).AnyTimes()
+	pool.EXPECT().Get(gomock.Any()).Return(nil, false).AnyTimes()
+	pool.EXPECT().Members().Return([]membership.Member{learner}).AnyTimes()
+
+	ctx := transport.ContextWithSource(context.TODO(), "10.0.0.1:5000")
+	denied := transport.ContextWithSource(context.TODO(), "192.168.0.1:5000")
+	voter := raftpb.Member{ID: 1}
+	staging := raftpb.Member{ID: 2, Type: raftpb.StagingMember}
+
+	// it admit all requests when no policy.
+	var a *admission
+	require.NoError(t, a.admit(denied, pool, staging, true))
+
+	a = newAdmission(&AdmissionPolicy{
+		MaxLearners: 1,
+		AllowList:   []string{"10.0.0.0/24", "127.0.0.1", "invalid"},
+		RateLimit:   3,
+	}, raftlog.DefaultLogger)
+
+	// it reject sources not in the allow list.
+	err := a.admit(denied, pool, voter, true)
+	require.ErrorIs(t, err, ErrJoinDenied)
+
+	err = a.admit(context.TODO(), pool, voter, true)
+	require.ErrorIs(t, err, ErrJoinDenied)
+
+	// it reject new learners beyond the max learners.
+	require.NoError(t, a.admit(ctx, pool, voter, true))
+	require.NoError(t, a.admit(ctx, pool, staging, false))
+	err = a.admit(ctx, pool, staging, true)
+	require.ErrorIs(t, err, ErrJoinDenied)
+
+	// it reject requests beyond the rate limit.
+	err = a.admit(ctx, pool, voter, true)
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	// it reset the rate limit window.
+	a.windows["10.0.0.1"].start = time.Now().Add(-time.Hour)
+	require.NoError(t, a.admit(ctx, pool, voter, true))
+
+	// it reject requests denied by the admit func.
+	a.policy.Admit = func(ctx context.Context, m RawMember) error {
+		require.Equal(t, "10.0.0.1:5000", SourceFromContext(ctx))
+		return errors.New("invalid token")
+	}
+
+	err = a.admit(ctx, pool, voter, true)
+	require.ErrorIs(t, err, ErrJoinDenied)
+	require.ErrorContains(t, err, "invalid token")
+}
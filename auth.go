@@ -0,0 +1,89 @@
+package raft
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/shaj13/raft/internal/raftengine"
+	"github.com/shaj13/raft/internal/transport"
+)
+
+// ErrUnauthenticated is returned by the transport handlers,
+// when the request credentials rejected by the auth policy.
+var ErrUnauthenticated = raftengine.ErrUnauthenticated
+
+// Credentials represents the credentials presented by the member calling the node rpc's,
+// See CredentialsFromContext.
+type Credentials = transport.Credentials
+
+// AuthPolicy define the authentication of the rpc's between the cluster members,
+// including the join, promote member, message, and snapshot rpc's, See WithAuthPolicy.
+//
+// A request authenticated when it satisfies all the configured checks.
+type AuthPolicy struct {
+	// Token is the bearer token sent by the node to the members,
+	// and required from the members requests.
+	// An empty token means no token required.
+	Token string
+	// AllowedIdentities restricts the members to the given mutual TLS identities,
+	// matched against the common name, DNS, and URI (e.g. SPIFFE ID) names of the member leaf certificate.
+	// An empty list means all identities allowed.
+	AllowedIdentities []string
+	// Authenticate is an optional function called with the request credentials,
+	// after the other checks passed, to accept or reject the request.
+	Authenticate func(ctx context.Context, c Credentials) error
+}
+
+// CredentialsFromContext returns the credentials of the member calling the node rpc's,
+// or empty credentials if they are unknown.
+func CredentialsFromContext(ctx context.Context) Credentials {
+	return transport.CredentialsFromContext(ctx)
+}
+
+// authenticate returns error if the credentials carried by the given ctx rejected by the given policy.
+func authenticate(ctx context.Context, p *AuthPolicy) error {
+	if p == nil {
+		return nil
+	}
+
+	creds := CredentialsFromContext(ctx)
+
+	if len(p.Token) > 0 && subtle.ConstantTimeCompare([]byte(p.Token), []byte(creds.Token)) != 1 {
+		return fmt.Errorf("%w: invalid token", ErrUnauthenticated)
+	}
+
+	if len(p.AllowedIdentities) > 0 && !allowedIdentity(creds, p.AllowedIdentities) {
+		return fmt.Errorf("%w: identity not allowed", ErrUnauthenticated)
+	}
+
+	if p.Authenticate != nil {
+		if err := p.Authenticate(ctx, creds); err != nil {
+			return fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+	}
+
+	return nil
+}
+
+func allowedIdentity(creds Credentials, allowed []string) bool {
+	if len(creds.PeerCertificates) == 0 {
+		return false
+	}
+
+	leaf := creds.PeerCertificates[0]
+	names := append([]string{leaf.Subject.CommonName}, leaf.DNSNames...)
+	for _, u := range leaf.URIs {
+		names = append(names, u.String())
+	}
+
+	for _, name := range names {
+		for _, id := range allowed {
+			if len(name) > 0 && name == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
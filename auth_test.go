@@ -0,0 +1,61 @@
+package raft
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/raft/sa/raft")
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "raft-0"},
+		DNSNames: []string{"raft-0.raft"},
+		URIs:     []*url.URL{spiffe},
+	}
+
+	ctx := func(token string, certs ...*x509.Certificate) context.Context {
+		return transport.ContextWithCredentials(context.TODO(), Credentials{
+			Token:            token,
+			PeerCertificates: certs,
+		})
+	}
+
+	// it accept all requests when no policy.
+	require.NoError(t, authenticate(context.TODO(), nil))
+
+	// it reject invalid tokens.
+	p := &AuthPolicy{Token: "secret"}
+	require.NoError(t, authenticate(ctx("secret"), p))
+	require.ErrorIs(t, authenticate(ctx("invalid"), p), ErrUnauthenticated)
+	require.ErrorIs(t, authenticate(context.TODO(), p), ErrUnauthenticated)
+
+	// it reject identities not in the allow list.
+	p = &AuthPolicy{AllowedIdentities: []string{spiffe.String()}}
+	require.NoError(t, authenticate(ctx("", cert), p))
+	require.ErrorIs(t, authenticate(ctx(""), p), ErrUnauthenticated)
+
+	p = &AuthPolicy{AllowedIdentities: []string{"raft-1"}}
+	require.ErrorIs(t, authenticate(ctx("", cert), p), ErrUnauthenticated)
+
+	p = &AuthPolicy{AllowedIdentities: []string{"raft-0.raft"}}
+	require.NoError(t, authenticate(ctx("", cert), p))
+
+	// it reject requests rejected by the authenticate func.
+	p = &AuthPolicy{
+		Authenticate: func(ctx context.Context, c Credentials) error {
+			if c.Token != "secret" {
+				return errors.New("denied")
+			}
+			return nil
+		},
+	}
+	require.NoError(t, authenticate(ctx("secret"), p))
+	require.ErrorIs(t, authenticate(ctx(""), p), ErrUnauthenticated)
+}
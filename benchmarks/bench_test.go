@@ -0,0 +1,212 @@
+package benchmarks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/raftlog"
+	"github.com/shaj13/raft/transport"
+	"github.com/shaj13/raft/transport/raftlocal"
+)
+
+const (
+	// payloadSize is the size of the proposed payloads.
+	payloadSize = 256
+	// readRatio is the ratio of the reads within the mixed workload.
+	readRatio = 0.5
+)
+
+// clusters is the sequence number of the benchmark clusters, used to assign unique pipe addresses.
+var clusters uint64
+
+type workload func(ctx context.Context, c *cluster, rnd *rand.Rand) error
+
+func write(ctx context.Context, c *cluster, _ *rand.Rand) error {
+	return c.leader.Replicate(ctx, c.payload)
+}
+
+func read(ctx context.Context, c *cluster, _ *rand.Rand) error {
+	return c.leader.LinearizableRead(ctx)
+}
+
+func mixed(ctx context.Context, c *cluster, rnd *rand.Rand) error {
+	if rnd.Float64() < readRatio {
+		return read(ctx, c, rnd)
+	}
+	return write(ctx, c, rnd)
+}
+
+func BenchmarkCluster(b *testing.B) {
+	workloads := []struct {
+		name string
+		fn   workload
+	}{
+		{"write", write},
+		{"read", read},
+		{"mixed", mixed},
+	}
+
+	for _, storage := range []string{"memory", "disk"} {
+		for _, size := range []int{1, 3, 5} {
+			b.Run(fmt.Sprintf("%s/%d-nodes", storage, size), func(b *testing.B) {
+				c := newCluster(b, size, storage == "memory")
+				for _, w := range workloads {
+					b.Run(w.name, func(b *testing.B) {
+						run(b, c, w.fn)
+					})
+				}
+			})
+		}
+	}
+}
+
+// run runs the given workload b.N times using concurrent clients,
+// and reports the throughput and the latency percentiles.
+func run(b *testing.B, c *cluster, fn workload) {
+	var (
+		mu        sync.Mutex
+		latencies = make([]time.Duration, 0, b.N)
+		seed      int64
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+
+	b.RunParallel(func(pb *testing.PB) {
+		rnd := rand.New(rand.NewSource(atomic.AddInt64(&seed, 1)))
+		local := []time.Duration{}
+
+		for pb.Next() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			t := time.Now()
+			err := fn(ctx, c, rnd)
+			local = append(local, time.Since(t))
+			cancel()
+
+			if err != nil {
+				b.Error(err)
+				return
+			}
+		}
+
+		mu.Lock()
+		latencies = append(latencies, local...)
+		mu.Unlock()
+	})
+
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	if len(latencies) == 0 {
+		return
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) float64 {
+		return float64(latencies[int(float64(len(latencies)-1)*p)].Nanoseconds())
+	}
+
+	b.ReportMetric(float64(len(latencies))/elapsed.Seconds(), "ops/s")
+	b.ReportMetric(percentile(0.50), "p50-ns")
+	b.ReportMetric(percentile(0.99), "p99-ns")
+}
+
+// cluster is an in-process cluster, its members communicating over pipes.
+type cluster struct {
+	leader  *raft.Node
+	nodes   []*raft.Node
+	payload []byte
+}
+
+func newCluster(b *testing.B, size int, inMemory bool) *cluster {
+	b.Helper()
+
+	id := atomic.AddUint64(&clusters, 1)
+	c := &cluster{
+		payload: bytes.Repeat([]byte{'x'}, payloadSize),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var lead string
+	for i := 1; i <= size; i++ {
+		addr := fmt.Sprintf("%sbench-%d-%d", raftlocal.PipeScheme, id, i)
+		opts := []raft.Option{
+			raft.WithLogger(raftlog.New(0, "", io.Discard)),
+			raft.WithTickInterval(time.Millisecond * 10),
+		}
+
+		if inMemory {
+			opts = append(opts, raft.WithInMemoryStorage())
+		} else {
+			opts = append(opts, raft.WithStateDIR(b.TempDir()))
+		}
+
+		node := raft.NewNode(new(stateMachine), transport.LOCAL, opts...)
+		lis, err := raftlocal.Listen(addr)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		srv := raftlocal.NewServer(node.Handler())
+		go srv.Serve(lis) //nolint:errcheck
+
+		startOpts := []raft.StartOption{raft.WithAddress(addr)}
+		if i == 1 {
+			lead = addr
+			startOpts = append(startOpts, raft.WithInitCluster())
+		} else {
+			startOpts = append(startOpts, raft.WithJoin(lead, time.Second))
+		}
+
+		go node.Start(startOpts...) //nolint:errcheck
+
+		if err := node.WaitForLeader(ctx); err != nil {
+			b.Fatalf("node %s: %v", addr, err)
+		}
+
+		c.nodes = append(c.nodes, node)
+		b.Cleanup(func() { shutdown(node, srv) })
+	}
+
+	c.leader = c.nodes[0]
+	return c
+}
+
+func shutdown(node *raft.Node, srv *grpc.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = node.Shutdown(ctx)
+	srv.Stop()
+}
+
+// stateMachine is a raft.StateMachine that counts the applied entries.
+type stateMachine struct {
+	applied uint64
+}
+
+func (s *stateMachine) Apply([]byte) error {
+	atomic.AddUint64(&s.applied, 1)
+	return nil
+}
+
+func (s *stateMachine) Snapshot() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(nil)), nil
+}
+
+func (s *stateMachine) Restore(r io.ReadCloser) error {
+	return r.Close()
+}
@@ -0,0 +1,18 @@
+// Package benchmarks provides a reproducible benchmark suite of the raft engine and storage,
+// running standardized workloads against in-process clusters.
+//
+// The suite runs the write, read, and mixed workloads against 1, 3, and 5 node clusters,
+// backed by the in-memory and the disk storage, and communicating over in-process pipes,
+// so the results reflect the engine and the WAL rather than the network stack.
+// Every workload proposes fixed size payloads through the leader,
+// and the mixed workload interleaves the writes and the linearizable reads using a seeded random source.
+//
+// Besides the standard ns/op, every benchmark reports the throughput in ops/s,
+// and the p50 and p99 operations latency.
+//
+//	go test ./benchmarks -run XXX -bench . -count 10 | tee new.txt
+//	benchstat old.txt new.txt
+//
+// Use the -cpu flag to vary the number of concurrent clients,
+// and the -bench flag to select a subset, e.g. -bench 'Cluster/disk/3-nodes/write'.
+package benchmarks
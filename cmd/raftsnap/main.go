@@ -0,0 +1,167 @@
+// Command raftsnap inspects the snapshot files, to validate backups without booting a node.
+//
+// The show command prints the snapshot metadata, once the snapshot checksums verified:
+//
+//	raftsnap show /var/lib/raft/snap/0000000000000002-0000000000000010.snap
+//
+// The extract command writes the state machine payload to stdout, or to the -o file:
+//
+//	raftsnap extract -o fsm.bin /var/lib/raft/snap/0000000000000002-0000000000000010.snap
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/internal/storage/disk"
+)
+
+const usage = `usage: raftsnap <command> [flags] <file>
+
+commands:
+  show      print the snapshot metadata
+  extract   write the state machine payload to stdout
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "raftsnap: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("no command given")
+	}
+
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("raftsnap "+cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	switch cmd {
+	case "show":
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		if fs.NArg() != 1 {
+			return fmt.Errorf("show requires a snapshot file")
+		}
+
+		return show(stdout, fs.Arg(0))
+	case "extract":
+		out := fs.String("o", "", "output file, defaults to stdout")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		if fs.NArg() != 1 {
+			return fmt.Errorf("extract requires a snapshot file")
+		}
+
+		w := stdout
+		if len(*out) > 0 {
+			f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			w = f
+		}
+
+		return extract(w, stderr, fs.Arg(0))
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func show(w io.Writer, path string) error {
+	sf, err := disk.ReadSnapshot(context.Background(), path)
+	if err != nil {
+		return err
+	}
+
+	defer sf.Data.Close()
+
+	meta := sf.Raw.Metadata
+	cs := meta.ConfState
+
+	fmt.Fprintf(w, "term: %d\n", meta.Term)
+	fmt.Fprintf(w, "index: %d\n", meta.Index)
+	fmt.Fprintf(w, "version: %s\n", sf.Version)
+	fmt.Fprintf(w, "compression: %s\n", sf.Compression)
+
+	if sf.CreatedAt > 0 {
+		fmt.Fprintf(w, "created at: %s\n", time.Unix(0, sf.CreatedAt).UTC().Format(time.RFC3339))
+	}
+
+	if sf.BaseIndex > 0 {
+		fmt.Fprintf(w, "base: term %d, index %d\n", sf.BaseTerm, sf.BaseIndex)
+	}
+
+	fmt.Fprintf(w, "crc64: %s\n", hex.EncodeToString(sf.CRC))
+	if len(sf.SHA256) > 0 {
+		fmt.Fprintf(w, "sha256: %s\n", hex.EncodeToString(sf.SHA256))
+	}
+
+	fmt.Fprintf(w, "chunks: %d\n", len(sf.ChunkCRCs))
+	fmt.Fprintf(w, "conf state: voters %x, learners %x", cs.Voters, cs.Learners)
+	if len(cs.VotersOutgoing) > 0 || len(cs.LearnersNext) > 0 {
+		fmt.Fprintf(w, ", outgoing voters %x, next learners %x", cs.VotersOutgoing, cs.LearnersNext)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "members: %d\n", len(sf.Members))
+	for _, m := range sf.Members {
+		fmt.Fprintf(w, "  %x: address %s, type %s\n", m.ID, m.Address, m.Type)
+	}
+
+	if len(sf.Alarms) > 0 {
+		fmt.Fprintf(w, "alarms: %d\n", len(sf.Alarms))
+		for _, a := range sf.Alarms {
+			fmt.Fprintf(w, "  %x: %s\n", a.ID, a.Type)
+		}
+	}
+
+	// the checksums verified while reading the snapshot.
+	fmt.Fprintln(w, "checksum: ok")
+
+	if err := raft.ValidateSnapshot(path, nil); err != nil {
+		fmt.Fprintf(w, "validation: %v\n", err)
+		return nil
+	}
+
+	fmt.Fprintln(w, "validation: ok")
+	return nil
+}
+
+func extract(w, stderr io.Writer, path string) error {
+	sf, err := disk.ReadSnapshot(context.Background(), path)
+	if err != nil {
+		return err
+	}
+
+	defer sf.Data.Close()
+
+	if sf.BaseIndex > 0 {
+		fmt.Fprintf(
+			stderr,
+			"raftsnap: delta snapshot chained onto term %d index %d, the payload can't be restored alone\n",
+			sf.BaseTerm,
+			sf.BaseIndex,
+		)
+	}
+
+	_, err = io.Copy(w, sf.Data)
+	return err
+}
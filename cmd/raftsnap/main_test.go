@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/storage"
+	"github.com/shaj13/raft/internal/storage/disk"
+)
+
+func TestShowAndExtract(t *testing.T) {
+	sf := &storage.Snapshot{
+		Data: io.NopCloser(strings.NewReader("fsm payload")),
+	}
+	sf.Raw.Metadata.Term = 2
+	sf.Raw.Metadata.Index = 16
+	sf.Raw.Metadata.ConfState.Voters = []uint64{1}
+	sf.Members = []raftpb.Member{{ID: 1, Address: ":8080", Type: raftpb.VoterMember}}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, disk.WriteSnapshot(buf, sf, raftpb.SnappyCompression))
+
+	path := filepath.Join(t.TempDir(), "snap")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	require.NoError(t, run([]string{"show", path}, stdout, stderr))
+
+	out := stdout.String()
+	require.Contains(t, out, "term: 2\n")
+	require.Contains(t, out, "index: 16\n")
+	require.Contains(t, out, "conf state: voters [1], learners []\n")
+	require.Contains(t, out, "1: address :8080, type voter\n")
+	require.Contains(t, out, "checksum: ok\n")
+	require.Contains(t, out, "validation: ok\n")
+
+	stdout.Reset()
+	require.NoError(t, run([]string{"extract", path}, stdout, stderr))
+	require.Equal(t, "fsm payload", stdout.String())
+
+	// it should fail on corrupted snapshots.
+	data := buf.Bytes()
+	data[0] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	require.Error(t, run([]string{"show", path}, stdout, stderr))
+
+	require.Error(t, run([]string{"unknown"}, stdout, stderr))
+}
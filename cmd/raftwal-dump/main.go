@@ -0,0 +1,186 @@
+// Command raftwal-dump prints the content of a node WAL, for offline debugging of a node state dir.
+//
+// It prints the WAL metadata (the local member), the segments headers,
+// the entries ranges, and optionally the entries themselves.
+//
+//	raftwal-dump -state-dir /var/lib/raft -entries
+//
+// The application data of the replicated entries can be decoded by a user-provided Go plugin,
+// built using "go build -buildmode=plugin", exporting a Decode function:
+//
+//	func Decode(data []byte) (string, error)
+//
+//	raftwal-dump -state-dir /var/lib/raft -entries -decoder ./decoder.so
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"plugin"
+
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/storage/disk"
+)
+
+// decoder decodes the application data of a replicated entry into a printable text.
+type decoder func(data []byte) (string, error)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "raftwal-dump: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("raftwal-dump", flag.ContinueOnError)
+	statedir := fs.String("state-dir", "", "node state dir, the WAL read from its wal sub dir")
+	waldir := fs.String("wal-dir", "", "node WAL dir, overrides the state dir")
+	entries := fs.Bool("entries", false, "print the entries within the segments")
+	plug := fs.String("decoder", "", "path of a Go plugin exporting Decode(data []byte) (string, error)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dir := *waldir
+	if len(dir) == 0 && len(*statedir) > 0 {
+		dir = filepath.Join(*statedir, "wal")
+	}
+
+	if len(dir) == 0 {
+		fs.Usage()
+		return fmt.Errorf("no WAL dir set, use -state-dir or -wal-dir")
+	}
+
+	var dec decoder
+	if len(*plug) > 0 {
+		var err error
+		dec, err = loadDecoder(*plug)
+		if err != nil {
+			return err
+		}
+	}
+
+	return dump(w, dir, *entries, dec)
+}
+
+// loadDecoder loads the Decode function exported by the plugin at the given path.
+func loadDecoder(path string) (decoder, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open decoder plugin: %v", err)
+	}
+
+	sym, err := p.Lookup("Decode")
+	if err != nil {
+		return nil, fmt.Errorf("lookup decoder plugin: %v", err)
+	}
+
+	fn, ok := sym.(func([]byte) (string, error))
+	if !ok {
+		return nil, fmt.Errorf("decoder plugin Decode is %T, want func([]byte) (string, error)", sym)
+	}
+
+	return fn, nil
+}
+
+func dump(w io.Writer, waldir string, entries bool, dec decoder) error {
+	segs, err := disk.ReadSegments(waldir)
+	if err != nil {
+		return err
+	}
+
+	if len(segs) == 0 {
+		return fmt.Errorf("no WAL segments found in %s", waldir)
+	}
+
+	if meta := segs[0].Metadata; len(meta) > 0 {
+		mem := new(raftpb.Member)
+		if err := mem.Unmarshal(meta); err != nil {
+			fmt.Fprintf(w, "metadata: %x (%v)\n", meta, err)
+		} else {
+			fmt.Fprintf(w, "metadata: member %x address %s\n", mem.ID, mem.Address)
+		}
+	}
+
+	for _, seg := range segs {
+		fmt.Fprintf(
+			w,
+			"segment %s: seq %d, index %d, size %d, crc %08x, entries %d [%d, %d], torn %t\n",
+			seg.Name,
+			seg.Seq,
+			seg.Index,
+			seg.Size,
+			seg.CRC,
+			len(seg.Entries),
+			seg.FirstIndex(),
+			seg.LastIndex(),
+			seg.Torn,
+		)
+
+		for _, snap := range seg.Snapshots {
+			fmt.Fprintf(w, "  snapshot: term %d, index %d\n", snap.Term, snap.Index)
+		}
+
+		hs := seg.HardState
+		fmt.Fprintf(w, "  hardstate: term %d, vote %x, commit %d\n", hs.Term, hs.Vote, hs.Commit)
+
+		if !entries {
+			continue
+		}
+
+		for _, ent := range seg.Entries {
+			fmt.Fprintf(w, "  entry: term %d, index %d, %s\n", ent.Term, ent.Index, describe(ent, dec))
+		}
+	}
+
+	return nil
+}
+
+// describe returns a printable description of the given entry.
+func describe(ent etcdraftpb.Entry, dec decoder) string {
+	switch ent.Type {
+	case etcdraftpb.EntryConfChange:
+		cc := etcdraftpb.ConfChange{}
+		if err := cc.Unmarshal(ent.Data); err != nil {
+			return fmt.Sprintf("conf change: %v", err)
+		}
+
+		mem := new(raftpb.Member)
+		if err := mem.Unmarshal(cc.Context); err != nil {
+			return fmt.Sprintf("conf change: %s %x", cc.Type, cc.NodeID)
+		}
+
+		return fmt.Sprintf("conf change: %s %x address %s", cc.Type, cc.NodeID, mem.Address)
+	case etcdraftpb.EntryNormal:
+		// empty entry appended by a new leader.
+		if len(ent.Data) == 0 {
+			return "empty"
+		}
+
+		r := new(raftpb.Replicate)
+		if err := r.Unmarshal(ent.Data); err != nil {
+			return fmt.Sprintf("normal: %v", err)
+		}
+
+		desc := fmt.Sprintf("%s: cid %d, size %d", r.Type, r.CID, len(r.Data))
+		if dec == nil || r.Type != raftpb.ReplicateData {
+			return desc
+		}
+
+		out, err := dec(r.Data)
+		if err != nil {
+			return fmt.Sprintf("%s, decode: %v", desc, err)
+		}
+
+		return fmt.Sprintf("%s, %s", desc, out)
+	default:
+		return fmt.Sprintf("%s: size %d", ent.Type, len(ent.Data))
+	}
+}
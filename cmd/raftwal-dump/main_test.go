@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/wal"
+
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+func TestDump(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "wal")
+	mem := &raftpb.Member{ID: 1, Address: ":8080"}
+
+	w, err := wal.Create(nil, dir, pbutil.MustMarshal(mem))
+	require.NoError(t, err)
+
+	cc := etcdraftpb.ConfChange{
+		Type:    etcdraftpb.ConfChangeAddNode,
+		NodeID:  1,
+		Context: pbutil.MustMarshal(mem),
+	}
+
+	r := &raftpb.Replicate{CID: 7, Data: []byte("data")}
+	err = w.Save(etcdraftpb.HardState{Term: 1, Vote: 1, Commit: 3}, []etcdraftpb.Entry{
+		{Term: 1, Index: 1, Type: etcdraftpb.EntryConfChange, Data: pbutil.MustMarshal(&cc)},
+		{Term: 1, Index: 2},
+		{Term: 1, Index: 3, Data: pbutil.MustMarshal(r)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	dec := func(data []byte) (string, error) {
+		return strings.ToUpper(string(data)), nil
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, dump(buf, dir, true, dec))
+
+	out := buf.String()
+	require.Contains(t, out, "metadata: member 1 address :8080")
+	require.Contains(t, out, "entries 3 [1, 3]")
+	require.Contains(t, out, "hardstate: term 1, vote 1, commit 3")
+	require.Contains(t, out, "entry: term 1, index 1, conf change: ConfChangeAddNode 1 address :8080")
+	require.Contains(t, out, "entry: term 1, index 2, empty")
+	require.Contains(t, out, "entry: term 1, index 3, data: cid 7, size 4, DATA")
+
+	// it should fail without a WAL dir.
+	require.Error(t, run([]string{}, buf))
+}
@@ -0,0 +1,121 @@
+// Package concurrency provides distributed mutexes and leader elections replicated through the raft log,
+// analogous to the etcd concurrency package.
+//
+// The mutexes and elections are queues of waiters, ordered by the raft log,
+// the queue head holds the mutex or leads the election. Each waiter belongs to a Session,
+// a lease kept alive by its owner, once the session lease expired or revoked,
+// its waiters dequeued, so a crashed owner never holds a mutex forever, See the lease package.
+//
+// The Client wraps the application state machine, passed to raft.NewNode,
+// and runs the lessor of the sessions leases.
+//
+//	c := concurrency.NewClient(fsm)
+//	node := raft.NewNode(c.StateMachine(), transport.GRPC)
+//	go node.Start(opts...)
+//	go c.Run(ctx, node)
+//
+//	s, err := c.NewSession(ctx, concurrency.WithTTL(10*time.Second))
+//	m := concurrency.NewMutex(s, "my-lock")
+//	err = m.Lock(ctx)
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/lease"
+)
+
+var (
+	// ErrLocked is returned by the Mutex TryLock method when the mutex is held by another owner.
+	ErrLocked = errors.New("raft/concurrency: mutex locked by another owner")
+	// ErrSessionExpired is returned when the session lease expired or revoked.
+	ErrSessionExpired = errors.New("raft/concurrency: session expired")
+	// ErrElectionNotLeader is returned when proclaiming a value without leading the election.
+	ErrElectionNotLeader = errors.New("raft/concurrency: election not led by the caller")
+	// ErrElectionNoLeader is returned when the election has no leader.
+	ErrElectionNoLeader = errors.New("raft/concurrency: election has no leader")
+	// ErrNotRunning is returned when proposing an operation while the client not running, See Client.Run.
+	ErrNotRunning = errors.New("raft/concurrency: client not running")
+)
+
+// Node is the raft node replicating the concurrency entries, implemented by *raft.Node.
+type Node interface {
+	lease.Node
+	LinearizableRead(ctx context.Context) error
+}
+
+// NewClient returns a new Client wrapping the given application state machine,
+// the given options configure the sessions lessor.
+func NewClient(fsm raft.StateMachine, opts ...lease.Option) *Client {
+	sm := newStateMachine(fsm)
+	sm.lessor = lease.NewLessor(sm, opts...)
+	return &Client{sm: sm}
+}
+
+// Client creates the sessions, and replicates the mutexes and elections entries.
+type Client struct {
+	sm *stateMachine
+
+	mu   sync.Mutex
+	node Node
+}
+
+// StateMachine returns the state machine to be passed to raft.NewNode,
+// it applies the sessions and concurrency entries and delegates other entries to the wrapped state machine.
+func (c *Client) StateMachine() raft.StateMachine {
+	return c.sm.lessor
+}
+
+// Lessor returns the lessor of the sessions leases.
+func (c *Client) Lessor() *lease.Lessor {
+	return c.sm.lessor
+}
+
+// Run attaches the client to the given node, and runs the sessions lessor until the given context done,
+// See lease.Lessor.Run.
+func (c *Client) Run(ctx context.Context, node Node) error {
+	c.mu.Lock()
+	c.node = node
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.node = nil
+		c.mu.Unlock()
+	}()
+
+	return c.sm.lessor.Run(ctx, node)
+}
+
+func (c *Client) propose(ctx context.Context, cmd command) error {
+	node, err := c.attached()
+	if err != nil {
+		return err
+	}
+
+	ctx = raft.ContextWithMetadata(ctx, map[string]string{metadataKey: metadataVersion})
+	return node.Replicate(ctx, cmd.marshal())
+}
+
+func (c *Client) linearizableRead(ctx context.Context) error {
+	node, err := c.attached()
+	if err != nil {
+		return err
+	}
+
+	return node.LinearizableRead(ctx)
+}
+
+func (c *Client) attached() (Node, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.node == nil {
+		return nil, ErrNotRunning
+	}
+
+	return c.node, nil
+}
@@ -0,0 +1,277 @@
+package concurrency
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/lease"
+	"github.com/shaj13/raft/rafttest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutex(t *testing.T) {
+	c := testClient(t, new(testStateMachine))
+	ctx := context.Background()
+	m1 := NewMutex(testSession(t, c), "lock")
+	m2 := NewMutex(testSession(t, c), "lock")
+	require.Equal(t, "lock", m1.Key())
+
+	// it acquire the mutex.
+	require.NoError(t, m1.Lock(ctx))
+	require.ErrorIs(t, m2.TryLock(ctx), ErrLocked)
+
+	// it wait the mutex release.
+	errc := make(chan error)
+	go func() {
+		errc <- m2.Lock(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		c.sm.mu.Lock()
+		defer c.sm.mu.Unlock()
+		return len(c.sm.queues["mutex/lock"]) == 2
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, m1.Unlock(ctx))
+	require.NoError(t, <-errc)
+	require.Greater(t, m2.Token(), m1.Token())
+	require.NoError(t, m2.Unlock(ctx))
+
+	// it acquire the released mutex.
+	require.NoError(t, m1.TryLock(ctx))
+}
+
+func TestMutexLockCancel(t *testing.T) {
+	c := testClient(t, new(testStateMachine))
+	m1 := NewMutex(testSession(t, c), "lock")
+	m2 := NewMutex(testSession(t, c), "lock")
+	require.NoError(t, m1.Lock(context.Background()))
+
+	// it dequeue the owner once the context done.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	require.ErrorIs(t, m2.Lock(ctx), context.DeadlineExceeded)
+
+	c.sm.mu.Lock()
+	defer c.sm.mu.Unlock()
+	require.Len(t, c.sm.queues["mutex/lock"], 1)
+}
+
+func TestMutexSessionRevoked(t *testing.T) {
+	fsm := new(testStateMachine)
+	c := testClient(t, fsm)
+	ctx := context.Background()
+	s := testSession(t, c)
+	m1 := NewMutex(s, "lock")
+	m2 := NewMutex(testSession(t, c), "lock")
+	require.NoError(t, m1.Lock(ctx))
+
+	// it release the mutexes of the revoked session.
+	require.NoError(t, s.Close(ctx))
+	require.NoError(t, m2.TryLock(ctx))
+	require.Equal(t, []uint64{s.Lease()}, fsm.revoked)
+
+	// it reject the operations of the closed session.
+	require.ErrorIs(t, m1.Lock(ctx), ErrSessionExpired)
+}
+
+func TestElection(t *testing.T) {
+	c := testClient(t, new(testStateMachine))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e1 := NewElection(testSession(t, c), "election")
+	e2 := NewElection(testSession(t, c), "election")
+	require.Equal(t, "election", e1.Name())
+
+	_, err := e1.Leader(ctx)
+	require.ErrorIs(t, err, ErrElectionNoLeader)
+
+	values := e2.Observe(ctx)
+
+	// it elect the first candidate.
+	require.NoError(t, e1.Campaign(ctx, "a"))
+	require.True(t, e1.IsLeader())
+	require.Equal(t, "a", <-values)
+
+	v, err := e2.Leader(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a", v)
+
+	// it update the leader value.
+	require.ErrorIs(t, e2.Proclaim(ctx, "b"), ErrElectionNotLeader)
+	require.NoError(t, e1.Proclaim(ctx, "c"))
+	require.Equal(t, "c", <-values)
+
+	// it elect the next candidate once the leader resigned.
+	errc := make(chan error)
+	go func() {
+		errc <- e2.Campaign(ctx, "b")
+	}()
+
+	require.Eventually(t, func() bool {
+		c.sm.mu.Lock()
+		defer c.sm.mu.Unlock()
+		return len(c.sm.queues["election/election"]) == 2
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, e1.Resign(ctx))
+	require.NoError(t, <-errc)
+	require.True(t, e2.IsLeader())
+	require.Equal(t, "b", <-values)
+}
+
+func TestSessionKeepAlive(t *testing.T) {
+	c := testClient(t, new(testStateMachine))
+	clock := rafttest.NewVirtualClock()
+	s, err := c.NewSession(context.Background(), WithTTL(3*time.Second), WithSessionClock(clock))
+	require.NoError(t, err)
+
+	// it renew the session lease.
+	clock.Advance(time.Second)
+	clock.Advance(time.Second)
+	require.False(t, s.expired())
+
+	// it close the session once the lease not found.
+	require.NoError(t, c.Lessor().Revoke(context.Background(), s.Lease()))
+	clock.Advance(time.Second)
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected session done")
+	}
+
+	require.NoError(t, s.Close(context.Background()))
+}
+
+func TestClientNotRunning(t *testing.T) {
+	c := NewClient(new(testStateMachine))
+	_, err := c.NewSession(context.Background())
+	require.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestStateMachineSnapshot(t *testing.T) {
+	c := testClient(t, &testStateMachine{data: "data"})
+	s := testSession(t, c)
+	require.NoError(t, NewMutex(s, "lock").Lock(context.Background()))
+	require.NoError(t, NewElection(s, "election").Campaign(context.Background(), "a"))
+
+	rc, err := c.sm.Snapshot()
+	require.NoError(t, err)
+
+	fsm := new(testStateMachine)
+	sm := newStateMachine(fsm)
+	require.NoError(t, sm.Restore(rc))
+	require.Equal(t, "data", fsm.data)
+	require.Equal(t, c.sm.rev, sm.rev)
+	require.Equal(t, c.sm.queues, sm.queues)
+
+	err = sm.Restore(io.NopCloser(strings.NewReader("")))
+	require.Error(t, err)
+}
+
+func TestCommandMarshal(t *testing.T) {
+	table := []command{
+		{op: opAcquire, key: "mutex/a", owner: 1, lease: 2},
+		{op: opTryAcquire, key: "mutex/b", owner: 3, lease: 4},
+		{op: opRelease, key: "election/c", owner: 5},
+		{op: opProclaim, key: "election/d", owner: 6, value: "v"},
+	}
+
+	for _, cmd := range table {
+		got := command{}
+		require.NoError(t, got.unmarshal(cmd.marshal()))
+		require.Equal(t, cmd, got)
+	}
+
+	require.Error(t, new(command).unmarshal([]byte{100}))
+}
+
+func testClient(t *testing.T, fsm raft.StateMachine) *Client {
+	c := NewClient(fsm, lease.WithClock(rafttest.NewVirtualClock()))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.Run(ctx, &testNode{sm: c.StateMachine()})
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	require.Eventually(t, func() bool {
+		_, err := c.attached()
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	// the lessor attached once running.
+	require.Eventually(t, func() bool {
+		_, err := c.Lessor().Grant(ctx, time.Second)
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	return c
+}
+
+func testSession(t *testing.T, c *Client) *Session {
+	s, err := c.NewSession(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		s.cancel()
+		s.wg.Wait()
+	})
+	return s
+}
+
+// testNode applies the replicated entries directly to the state machine.
+type testNode struct {
+	sm raft.StateMachine
+}
+
+func (n *testNode) Replicate(ctx context.Context, data []byte) error {
+	return n.sm.(raft.MetadataStateMachine).ApplyWithMetadata(data, raft.MetadataFromContext(ctx))
+}
+
+func (n *testNode) LinearizableRead(context.Context) error {
+	return nil
+}
+
+func (n *testNode) Leader() uint64 {
+	return 1
+}
+
+func (n *testNode) Whoami() uint64 {
+	return 1
+}
+
+type testStateMachine struct {
+	data    string
+	revoked []uint64
+}
+
+func (sm *testStateMachine) Apply(data []byte) error {
+	sm.data = string(data)
+	return nil
+}
+
+func (sm *testStateMachine) Snapshot() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(sm.data)), nil
+}
+
+func (sm *testStateMachine) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	sm.data = string(b)
+	return err
+}
+
+func (sm *testStateMachine) LeaseRevoked(l lease.Lease, expired bool) error {
+	sm.revoked = append(sm.revoked, l.ID)
+	return nil
+}
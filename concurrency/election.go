@@ -0,0 +1,102 @@
+package concurrency
+
+import (
+	"context"
+)
+
+// NewElection returns a new leader election of the given name, the candidates belong to the given session.
+func NewElection(s *Session, name string) *Election {
+	return &Election{
+		s:     s,
+		key:   "election/" + name,
+		owner: newOwner(),
+	}
+}
+
+// Election is a distributed leader election, led by a single candidate cluster-wide at a time,
+// the candidates campaigning for the election lead it in their Campaign calls order.
+type Election struct {
+	s     *Session
+	key   string
+	owner uint64
+}
+
+// Name returns the election name.
+func (e *Election) Name() string {
+	return e.key[len("election/"):]
+}
+
+// Campaign proposes to queue the candidate with the given value, and blocks until elected.
+// If the given context done before, Campaign dequeues the candidate and returns the context's error.
+// Campaign returns ErrSessionExpired if the session expired while waiting.
+func (e *Election) Campaign(ctx context.Context, value string) error {
+	cmd := command{op: opAcquire, key: e.key, owner: e.owner, lease: e.s.id, value: value}
+	_, err := wait(ctx, e.s, e.key, e.owner, cmd)
+	return err
+}
+
+// Proclaim proposes to update the leader value without an election,
+// It returns ErrElectionNotLeader unless the candidate leads the election.
+func (e *Election) Proclaim(ctx context.Context, value string) error {
+	return e.s.c.propose(ctx, command{op: opProclaim, key: e.key, owner: e.owner, value: value})
+}
+
+// Resign proposes to dequeue the candidate, so the next candidate elected.
+func (e *Election) Resign(ctx context.Context) error {
+	return e.s.c.propose(ctx, command{op: opRelease, key: e.key, owner: e.owner})
+}
+
+// IsLeader reports whether the candidate leads the election, as known by the local member.
+func (e *Election) IsLeader() bool {
+	w, ok, _ := e.s.c.sm.head(e.key)
+	return ok && w.owner == e.owner
+}
+
+// Leader performs a linearizable read, then returns the election leader value,
+// It returns ErrElectionNoLeader if no candidate campaigning.
+func (e *Election) Leader(ctx context.Context) (string, error) {
+	if err := e.s.c.linearizableRead(ctx); err != nil {
+		return "", err
+	}
+
+	w, ok, _ := e.s.c.sm.head(e.key)
+	if !ok {
+		return "", ErrElectionNoLeader
+	}
+
+	return w.value, nil
+}
+
+// Observe returns a channel streaming the election leader values as known by the local member,
+// starting with the current value, if any. The channel closed once the given context done.
+func (e *Election) Observe(ctx context.Context) <-chan string {
+	c := make(chan string)
+	go func() {
+		defer close(c)
+
+		var last waiter
+		for {
+			w, ok, changed := e.s.c.sm.head(e.key)
+			if ok && (w.owner != last.owner || w.value != last.value) {
+				select {
+				case c <- w.value:
+					last = w
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if !ok {
+				last = waiter{}
+			}
+
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c
+}
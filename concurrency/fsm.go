@@ -0,0 +1,383 @@
+package concurrency
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/lease"
+)
+
+var (
+	_ raft.MetadataStateMachine = &stateMachine{}
+	_ lease.StateMachine        = &stateMachine{}
+)
+
+// metadataKey is the proposal metadata key marking the concurrency entries,
+// its value is the entries encoding version.
+const (
+	metadataKey     = "raft-concurrency"
+	metadataVersion = "1"
+)
+
+// maxStringSize is the max size of a decoded key or value,
+// guarding against the malformed entries and snapshots.
+const maxStringSize = 1 << 20
+
+// Possible values for op.
+const (
+	opAcquire op = iota + 1
+	opTryAcquire
+	opRelease
+	opProclaim
+)
+
+// op is a replicated concurrency operation.
+type op byte
+
+// command is a replicated concurrency entry.
+type command struct {
+	op    op
+	key   string
+	owner uint64
+	lease uint64
+	value string
+}
+
+func (c command) marshal() []byte {
+	buf := []byte{byte(c.op)}
+	buf = appendString(buf, c.key)
+	buf = binary.AppendUvarint(buf, c.owner)
+	buf = binary.AppendUvarint(buf, c.lease)
+	buf = appendString(buf, c.value)
+	return buf
+}
+
+func (c *command) unmarshal(data []byte) (err error) {
+	if len(data) == 0 {
+		return errors.New("raft/concurrency: empty entry")
+	}
+
+	c.op = op(data[0])
+	if c.op < opAcquire || c.op > opProclaim {
+		return fmt.Errorf("raft/concurrency: unknown entry operation %d", c.op)
+	}
+
+	r := bytes.NewReader(data[1:])
+	if c.key, err = readString(r); err != nil {
+		return fmt.Errorf("raft/concurrency: malformed entry: %w", err)
+	}
+
+	if c.owner, err = binary.ReadUvarint(r); err != nil {
+		return fmt.Errorf("raft/concurrency: malformed entry: %w", err)
+	}
+
+	if c.lease, err = binary.ReadUvarint(r); err != nil {
+		return fmt.Errorf("raft/concurrency: malformed entry: %w", err)
+	}
+
+	if c.value, err = readString(r); err != nil {
+		return fmt.Errorf("raft/concurrency: malformed entry: %w", err)
+	}
+
+	return nil
+}
+
+// waiter is a queued owner of a mutex or an election candidate,
+// the queue head holds the mutex or leads the election.
+type waiter struct {
+	owner uint64
+	lease uint64
+	value string
+	// rev is the revision at which the waiter queued, the mutex fencing token.
+	rev uint64
+}
+
+// stateMachine holds the replicated mutexes and elections queues,
+// applying the concurrency entries and delegating other entries to the wrapped state machine.
+type stateMachine struct {
+	fsm    raft.StateMachine
+	lessor *lease.Lessor
+
+	mu sync.Mutex
+	// rev increased by each applied change.
+	rev    uint64
+	queues map[string][]waiter
+	// changed closed and replaced on each applied change.
+	changed chan struct{}
+}
+
+func newStateMachine(fsm raft.StateMachine) *stateMachine {
+	return &stateMachine{
+		fsm:     fsm,
+		queues:  make(map[string][]waiter),
+		changed: make(chan struct{}),
+	}
+}
+
+// head returns the head waiter of the given key queue, and a channel closed once the queues changed.
+func (sm *stateMachine) head(key string) (waiter, bool, <-chan struct{}) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	q := sm.queues[key]
+	if len(q) == 0 {
+		return waiter{}, false, sm.changed
+	}
+
+	return q[0], true, sm.changed
+}
+
+// notify wakes up the waiters, it must be called while holding the lock.
+func (sm *stateMachine) notify() {
+	close(sm.changed)
+	sm.changed = make(chan struct{})
+}
+
+func (sm *stateMachine) Apply(data []byte) error {
+	return sm.fsm.Apply(data)
+}
+
+func (sm *stateMachine) ApplyWithMetadata(data []byte, md map[string]string) error {
+	if _, ok := md[metadataKey]; ok {
+		return sm.apply(data)
+	}
+
+	if fsm, ok := sm.fsm.(raft.MetadataStateMachine); ok {
+		return fsm.ApplyWithMetadata(data, md)
+	}
+
+	return sm.fsm.Apply(data)
+}
+
+func (sm *stateMachine) apply(data []byte) error {
+	c := command{}
+	if err := c.unmarshal(data); err != nil {
+		return err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	q := sm.queues[c.key]
+	i := indexOf(q, c.owner)
+
+	switch c.op {
+	case opAcquire, opTryAcquire:
+		// already queued, e.g. a retried proposal.
+		if i >= 0 {
+			return nil
+		}
+
+		// the session expired before the entry applied.
+		if _, ok := sm.lessor.Lease(c.lease); !ok {
+			return ErrSessionExpired
+		}
+
+		if c.op == opTryAcquire && len(q) > 0 {
+			return ErrLocked
+		}
+
+		sm.rev++
+		sm.queues[c.key] = append(q, waiter{owner: c.owner, lease: c.lease, value: c.value, rev: sm.rev})
+	case opRelease:
+		if i < 0 {
+			return nil
+		}
+
+		sm.rev++
+		sm.remove(c.key, i)
+	case opProclaim:
+		if i != 0 {
+			return ErrElectionNotLeader
+		}
+
+		sm.rev++
+		q[0].value = c.value
+	}
+
+	sm.notify()
+	return nil
+}
+
+// remove removes the waiter at the given index of the key queue, it must be called while holding the lock.
+func (sm *stateMachine) remove(key string, i int) {
+	q := sm.queues[key]
+	q = append(q[:i:i], q[i+1:]...)
+	if len(q) == 0 {
+		delete(sm.queues, key)
+		return
+	}
+
+	sm.queues[key] = q
+}
+
+// LeaseRevoked releases the mutexes and resigns the elections of the revoked session lease.
+func (sm *stateMachine) LeaseRevoked(l lease.Lease, expired bool) error {
+	sm.mu.Lock()
+	changed := false
+	for key, q := range sm.queues {
+		for i := len(q) - 1; i >= 0; i-- {
+			if q[i].lease == l.ID {
+				sm.remove(key, i)
+				q = sm.queues[key]
+				changed = true
+			}
+		}
+	}
+
+	if changed {
+		sm.rev++
+		sm.notify()
+	}
+	sm.mu.Unlock()
+
+	if fsm, ok := sm.fsm.(lease.StateMachine); ok {
+		return fsm.LeaseRevoked(l, expired)
+	}
+
+	return nil
+}
+
+// Snapshot returns the queues followed by the wrapped state machine snapshot.
+func (sm *stateMachine) Snapshot() (io.ReadCloser, error) {
+	sm.mu.Lock()
+	buf := binary.AppendUvarint(nil, sm.rev)
+	buf = binary.AppendUvarint(buf, uint64(len(sm.queues)))
+	for key, q := range sm.queues {
+		buf = appendString(buf, key)
+		buf = binary.AppendUvarint(buf, uint64(len(q)))
+		for _, w := range q {
+			buf = binary.AppendUvarint(buf, w.owner)
+			buf = binary.AppendUvarint(buf, w.lease)
+			buf = appendString(buf, w.value)
+			buf = binary.AppendUvarint(buf, w.rev)
+		}
+	}
+	sm.mu.Unlock()
+
+	rc, err := sm.fsm.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return readCloser{
+		Reader: io.MultiReader(bytes.NewReader(buf), rc),
+		Closer: rc,
+	}, nil
+}
+
+// Restore restores the queues, and the wrapped state machine from the remaining snapshot data.
+func (sm *stateMachine) Restore(rc io.ReadCloser) error {
+	br := bufio.NewReader(rc)
+	rev, queues, err := readQueues(br)
+	if err != nil {
+		rc.Close()
+		return fmt.Errorf("raft/concurrency: malformed snapshot: %w", err)
+	}
+
+	sm.mu.Lock()
+	sm.rev, sm.queues = rev, queues
+	sm.notify()
+	sm.mu.Unlock()
+
+	return sm.fsm.Restore(readCloser{Reader: br, Closer: rc})
+}
+
+func readQueues(r *bufio.Reader) (uint64, map[string][]waiter, error) {
+	rev, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	queues := make(map[string][]waiter)
+	for i := uint64(0); i < n; i++ {
+		key, err := readString(r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		q := []waiter{}
+		for j := uint64(0); j < size; j++ {
+			w := waiter{}
+			if w.owner, err = binary.ReadUvarint(r); err != nil {
+				return 0, nil, err
+			}
+
+			if w.lease, err = binary.ReadUvarint(r); err != nil {
+				return 0, nil, err
+			}
+
+			if w.value, err = readString(r); err != nil {
+				return 0, nil, err
+			}
+
+			if w.rev, err = binary.ReadUvarint(r); err != nil {
+				return 0, nil, err
+			}
+
+			q = append(q, w)
+		}
+
+		queues[key] = q
+	}
+
+	return rev, queues, nil
+}
+
+func indexOf(q []waiter, owner uint64) int {
+	for i, w := range q {
+		if w.owner == owner {
+			return i
+		}
+	}
+	return -1
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func readString(r byteReader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+
+	if n > maxStringSize {
+		return "", fmt.Errorf("string size %d exceeds %d", n, maxStringSize)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,111 @@
+package concurrency
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// releaseTimeout is the timeout of dequeuing a waiter once its Lock or Campaign call cancelled.
+var releaseTimeout = 5 * time.Second
+
+// NewMutex returns a new distributed mutex of the given key, owned by the given session.
+func NewMutex(s *Session, key string) *Mutex {
+	return &Mutex{
+		s:     s,
+		key:   "mutex/" + key,
+		owner: newOwner(),
+	}
+}
+
+// Mutex is a distributed mutex, held by a single owner cluster-wide at a time,
+// the owners waiting for the mutex acquire it in their Lock calls order.
+type Mutex struct {
+	s     *Session
+	key   string
+	owner uint64
+	token uint64
+}
+
+// Key returns the mutex key.
+func (m *Mutex) Key() string {
+	return m.key[len("mutex/"):]
+}
+
+// Token returns the fencing token of the held mutex, increasing with each acquisition of the mutex,
+// to be attached to the guarded requests so the resources reject the requests of a former owner.
+func (m *Mutex) Token() uint64 {
+	return m.token
+}
+
+// Lock proposes to queue the mutex owner, and blocks until the mutex acquired.
+// If the given context done before, Lock dequeues the owner and returns the context's error.
+// Lock returns ErrSessionExpired if the session expired while waiting.
+func (m *Mutex) Lock(ctx context.Context) error {
+	w, err := wait(ctx, m.s, m.key, m.owner, command{op: opAcquire, key: m.key, owner: m.owner, lease: m.s.id})
+	if err != nil {
+		return err
+	}
+
+	m.token = w.rev
+	return nil
+}
+
+// TryLock proposes to acquire the mutex without waiting,
+// It returns ErrLocked if the mutex is held by another owner.
+func (m *Mutex) TryLock(ctx context.Context) error {
+	cmd := command{op: opTryAcquire, key: m.key, owner: m.owner, lease: m.s.id}
+	if err := m.s.c.propose(ctx, cmd); err != nil {
+		return err
+	}
+
+	w, ok, _ := m.s.c.sm.head(m.key)
+	if !ok || w.owner != m.owner {
+		return ErrLocked
+	}
+
+	m.token = w.rev
+	return nil
+}
+
+// Unlock proposes to release the mutex.
+func (m *Mutex) Unlock(ctx context.Context) error {
+	return m.s.c.propose(ctx, command{op: opRelease, key: m.key, owner: m.owner})
+}
+
+// wait proposes the given queue command, and blocks until the owner reaches the queue head.
+func wait(ctx context.Context, s *Session, key string, owner uint64, cmd command) (waiter, error) {
+	if s.expired() {
+		return waiter{}, ErrSessionExpired
+	}
+
+	if err := s.c.propose(ctx, cmd); err != nil {
+		return waiter{}, err
+	}
+
+	for {
+		w, ok, changed := s.c.sm.head(key)
+		if ok && w.owner == owner {
+			return w, nil
+		}
+
+		select {
+		case <-changed:
+		case <-s.Done():
+			return waiter{}, ErrSessionExpired
+		case <-ctx.Done():
+			rctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+			defer cancel()
+			_ = s.c.propose(rctx, command{op: opRelease, key: key, owner: owner})
+			return waiter{}, ctx.Err()
+		}
+	}
+}
+
+func newOwner() uint64 {
+	id := rand.Uint64()
+	for id == 0 {
+		id = rand.Uint64()
+	}
+	return id
+}
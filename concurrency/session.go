@@ -0,0 +1,161 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/lease"
+)
+
+// SessionOption configures the Session.
+type SessionOption func(*Session)
+
+// WithTTL set the session lease TTL, the session mutexes released and elections resigned,
+// once its owner fails to keep it alive for the TTL.
+//
+// Default Value: 60's.
+func WithTTL(ttl time.Duration) SessionOption {
+	return func(s *Session) {
+		s.ttl = ttl
+	}
+}
+
+// WithSessionClock set the clock providing the ticks keeping the session alive,
+// e.g. the virtual clock driving the nodes of a simulation, See rafttest.
+//
+// Default Value: nil, the wall clock.
+func WithSessionClock(clock raft.Clock) SessionOption {
+	return func(s *Session) {
+		s.clock = clock
+	}
+}
+
+// NewSession grants a new session lease, and keeps it alive until the session closed.
+func (c *Client) NewSession(ctx context.Context, opts ...SessionOption) (*Session, error) {
+	s := &Session{
+		c:     c,
+		ttl:   time.Second * 60,
+		donec: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// the lessor replicates through the attached node.
+	if _, err := c.attached(); err != nil {
+		return nil, err
+	}
+
+	l, err := c.sm.lessor.Grant(ctx, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	s.id = l.ID
+	ctx, s.cancel = context.WithCancel(context.Background())
+	s.wg.Add(1)
+	go s.keepAlive(ctx, s.newTicker())
+	return s, nil
+}
+
+// Session is a lease kept alive by its owner,
+// the mutexes and elections waiters belong to a session.
+type Session struct {
+	c      *Client
+	id     uint64
+	ttl    time.Duration
+	clock  raft.Clock
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	donec  chan struct{}
+}
+
+// Lease returns the session lease id.
+func (s *Session) Lease() uint64 {
+	return s.id
+}
+
+// Client returns the client that created the session.
+func (s *Session) Client() *Client {
+	return s.c
+}
+
+// Done returns a channel closed once the session lease expired or revoked, or the session closed.
+func (s *Session) Done() <-chan struct{} {
+	return s.donec
+}
+
+// Close stops keeping the session alive and revokes its lease,
+// so the session mutexes released and elections resigned.
+func (s *Session) Close(ctx context.Context) error {
+	s.cancel()
+	s.wg.Wait()
+	s.done()
+
+	err := s.c.sm.lessor.Revoke(ctx, s.id)
+	if errors.Is(err, lease.ErrLeaseNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// keepAlive renews the session lease three times per TTL,
+// until the lease found expired or the session closed.
+func (s *Session) keepAlive(ctx context.Context, t raft.Ticker) {
+	defer s.wg.Done()
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+		}
+
+		rctx, cancel := context.WithTimeout(ctx, s.ttl/3)
+		err := s.c.sm.lessor.Renew(rctx, s.id)
+		cancel()
+
+		// other errors are transient, the renewal retried on the next tick.
+		if errors.Is(err, lease.ErrLeaseNotFound) {
+			s.done()
+			return
+		}
+	}
+}
+
+// expired reports whether the session is done.
+func (s *Session) expired() bool {
+	select {
+	case <-s.donec:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Session) done() {
+	s.once.Do(func() { close(s.donec) })
+}
+
+func (s *Session) newTicker() raft.Ticker {
+	d := s.ttl / 3
+	if s.clock == nil {
+		return realTicker{time.NewTicker(d)}
+	}
+	return s.clock.NewTicker(d)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -15,16 +15,58 @@ import (
 )
 
 type controller struct {
-	node    *Node
-	engine  raftengine.Engine
-	pool    membership.Pool
-	storage storage.Storage
+	node      *Node
+	engine    raftengine.Engine
+	pool      membership.Pool
+	storage   storage.Storage
+	admission *admission
+	auth      *AuthPolicy
+	metrics   transport.Metrics
+	quota     *proposalQuota
+	relay     bool
+}
+
+func (c *controller) Authenticate(ctx context.Context, gid uint64) error {
+	if err := c.fence(transport.CredentialsFromContext(ctx).ClusterID); err != nil {
+		return err
+	}
+
+	return authenticate(ctx, c.auth)
+}
+
+func (c *controller) ClusterID(gid uint64) uint64 {
+	return c.node.engine.ClusterID()
+}
+
+// fence returns error if the given cluster id of a request source or a joining member,
+// is different from the local cluster id, the zero ids are unknown and never rejected,
+// e.g. a node state predating the cluster id.
+func (c *controller) fence(id uint64) error {
+	local := c.node.engine.ClusterID()
+	if id == 0 || local == 0 || id == local {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w: cluster %x, local cluster %x", ErrUnauthenticated, ErrClusterMismatch, id, local)
 }
 
 func (c *controller) Join(ctx context.Context, gid uint64, m *raftpb.Member) (*raftpb.JoinResponse, error) {
+	if err := c.admission.admit(ctx, c.pool, *m, true); err != nil {
+		return nil, err
+	}
+
+	if err := c.fence(m.ClusterID); err != nil {
+		return nil, err
+	}
+
+	// the features activated by the cluster may have replicated entries the member can't apply.
+	if v := c.node.ClusterVersion(); m.Version < v {
+		return nil, fmt.Errorf("%w: member %x version %d, cluster version %d", ErrIncompatibleVersion, m.ID, m.Version, v)
+	}
+
 	var err error
 
-	if _, ok := c.node.GetMemebr(m.ID); !ok {
+	if _, ok := c.node.Member(m.ID); !ok {
 		err = c.node.AddMember(ctx, m)
 	} else {
 		err = c.node.UpdateMember(ctx, m)
@@ -35,27 +77,101 @@ func (c *controller) Join(ctx context.Context, gid uint64, m *raftpb.Member) (*r
 	}
 
 	resp := &raftpb.JoinResponse{
-		ID:      m.ID,
-		Members: c.pool.Snapshot(),
+		ID:        m.ID,
+		Members:   c.pool.Snapshot(),
+		ClusterID: c.node.engine.ClusterID(),
 	}
 
 	return resp, nil
 }
 
 func (c *controller) Push(ctx context.Context, gid uint64, m etcdraftpb.Message) error {
+	if c.metrics != nil {
+		c.metrics.AddMessagesReceived(m.From, 1)
+		c.metrics.AddBytesReceived(m.From, m.Size())
+	}
+
+	// the message destined to another member, relayed through the current member.
+	if mem, ok := c.pool.Get(m.To); ok && !membership.IsLocal(mem) {
+		if !c.relay {
+			return fmt.Errorf("raft: message destined to member %x, relay disabled", m.To)
+		}
+		return mem.Send(m)
+	}
+
+	// reject corrupted snapshots so the sender re-send them,
+	// instead of feeding garbage to the state machine.
+	if m.Type == etcdraftpb.MsgSnap {
+		meta := m.Snapshot.Metadata
+
+		// fetch the snapshot out of band when the message carries only its key.
+		if len(m.Snapshot.Data) > 0 {
+			if err := c.storage.Snapshotter().Fetch(ctx, string(m.Snapshot.Data)); err != nil {
+				return err
+			}
+			m.Snapshot.Data = nil
+		}
+
+		if err := c.storage.Snapshotter().Verify(ctx, meta.Term, meta.Index); err != nil {
+			return err
+		}
+	}
+
+	if err := c.quota.admitForwarded(ctx, m); err != nil {
+		return err
+	}
+
 	return c.engine.Push(m)
 }
 
+func (c *controller) Health(gid uint64) error {
+	return c.node.Health()
+}
+
 func (c *controller) PromoteMember(ctx context.Context, gid uint64, m raftpb.Member) error {
+	if err := c.admission.admit(ctx, c.pool, m, false); err != nil {
+		return err
+	}
+
 	return c.node.promoteMember(ctx, m.ID, true)
 }
 
-func (c *controller) SnapshotWriter(gid, term, index uint64) (io.WriteCloser, error) {
-	return c.storage.Snapshotter().Writer(term, index)
+func (c *controller) SnapshotChain(gid, term, index uint64) ([]etcdraftpb.SnapshotMetadata, error) {
+	return c.storage.Snapshotter().Chain(term, index)
+}
+
+func (c *controller) SnapshotPublish(ctx context.Context, gid, term, index uint64) (string, error) {
+	return c.storage.Snapshotter().Publish(ctx, term, index)
 }
 
-func (c *controller) SnapshotReader(gid, term uint64, index uint64) (io.ReadCloser, error) {
-	return c.storage.Snapshotter().Reader(term, index)
+func (c *controller) SnapshotOffset(gid, term, index uint64) (uint64, error) {
+	return c.storage.Snapshotter().Offset(term, index)
+}
+
+func (c *controller) SnapshotWriter(gid, term, index, offset uint64) (transport.SnapshotWriter, error) {
+	return c.storage.Snapshotter().Writer(term, index, offset)
+}
+
+func (c *controller) SnapshotReader(gid, term, index, offset uint64, compress bool) (io.ReadCloser, error) {
+	// send the snapshot uncompressed when the remote member does not support compression.
+	comp := raftpb.NoCompression
+	if compress {
+		comp = c.node.cfg.snapshotCompression()
+	}
+
+	r, err := c.storage.Snapshotter().Reader(c.node.cfg.Context(), term, index, comp)
+	if err != nil {
+		return nil, err
+	}
+
+	// skip the bytes already received by the remote member,
+	// the remote member may already have the whole snapshot file.
+	if _, err := io.CopyN(io.Discard, r, int64(offset)); err != nil && err != io.EOF {
+		_ = r.Close()
+		return nil, err
+	}
+
+	return r, nil
 }
 
 type router struct {
@@ -93,6 +209,22 @@ func (r *router) Join(ctx context.Context, gid uint64, m *raftpb.Member) (*raftp
 	return ctrl.Join(ctx, gid, m)
 }
 
+func (r *router) Authenticate(ctx context.Context, gid uint64) error {
+	ctrl, err := r.get(gid)
+	if err != nil {
+		return err
+	}
+	return ctrl.Authenticate(ctx, gid)
+}
+
+func (r *router) ClusterID(gid uint64) uint64 {
+	ctrl, err := r.get(gid)
+	if err != nil {
+		return 0
+	}
+	return ctrl.ClusterID(gid)
+}
+
 func (r *router) Push(ctx context.Context, gid uint64, m etcdraftpb.Message) error {
 	ctrl, err := r.get(gid)
 	if err != nil {
@@ -101,6 +233,14 @@ func (r *router) Push(ctx context.Context, gid uint64, m etcdraftpb.Message) err
 	return ctrl.Push(ctx, gid, m)
 }
 
+func (r *router) Health(gid uint64) error {
+	ctrl, err := r.get(gid)
+	if err != nil {
+		return err
+	}
+	return ctrl.Health(gid)
+}
+
 func (r *router) PromoteMember(ctx context.Context, gid uint64, m raftpb.Member) error {
 	ctrl, err := r.get(gid)
 	if err != nil {
@@ -109,19 +249,43 @@ func (r *router) PromoteMember(ctx context.Context, gid uint64, m raftpb.Member)
 	return ctrl.PromoteMember(ctx, gid, m)
 }
 
-func (r *router) SnapshotWriter(gid, term, index uint64) (io.WriteCloser, error) {
+func (r *router) SnapshotPublish(ctx context.Context, gid, term, index uint64) (string, error) {
+	ctrl, err := r.get(gid)
+	if err != nil {
+		return "", err
+	}
+	return ctrl.SnapshotPublish(ctx, gid, term, index)
+}
+
+func (r *router) SnapshotChain(gid, term, index uint64) ([]etcdraftpb.SnapshotMetadata, error) {
+	ctrl, err := r.get(gid)
+	if err != nil {
+		return nil, err
+	}
+	return ctrl.SnapshotChain(gid, term, index)
+}
+
+func (r *router) SnapshotOffset(gid, term, index uint64) (uint64, error) {
+	ctrl, err := r.get(gid)
+	if err != nil {
+		return 0, err
+	}
+	return ctrl.SnapshotOffset(gid, term, index)
+}
+
+func (r *router) SnapshotWriter(gid, term, index, offset uint64) (transport.SnapshotWriter, error) {
 	ctrl, err := r.get(gid)
 	if err != nil {
 		return nil, err
 	}
 
-	return ctrl.SnapshotWriter(gid, term, index)
+	return ctrl.SnapshotWriter(gid, term, index, offset)
 }
 
-func (r *router) SnapshotReader(gid, term, index uint64) (io.ReadCloser, error) {
+func (r *router) SnapshotReader(gid, term, index, offset uint64, compress bool) (io.ReadCloser, error) {
 	ctrl, err := r.get(gid)
 	if err != nil {
 		return nil, err
 	}
-	return ctrl.SnapshotReader(gid, term, index)
+	return ctrl.SnapshotReader(gid, term, index, offset, compress)
 }
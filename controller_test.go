@@ -5,10 +5,14 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/shaj13/raft/internal/membership"
 	membershipmock "github.com/shaj13/raft/internal/mocks/membership"
 	raftenginemock "github.com/shaj13/raft/internal/mocks/raftengine"
+	storagemock "github.com/shaj13/raft/internal/mocks/storage"
+	transportmock "github.com/shaj13/raft/internal/mocks/transport"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/raftlog"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/v3"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
@@ -18,10 +22,110 @@ func TestControllerPush(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	eng := raftenginemock.NewMockEngine(ctrl)
 	eng.EXPECT().Push(gomock.Any()).Return(nil)
+	pool := membershipmock.NewMockPool(ctrl)
+	pool.EXPECT().Get(gomock.Any()).Return(nil, false).AnyTimes()
 	c := new(controller)
 	c.engine = eng
+	c.pool = pool
 	err := c.Push(context.TODO(), 0, etcdraftpb.Message{})
 	require.NoError(t, err)
+
+	// it report the received messages metrics.
+	msg := etcdraftpb.Message{From: 1, Type: etcdraftpb.MsgApp}
+	metrics := transportmock.NewMockMetrics(ctrl)
+	metrics.EXPECT().AddMessagesReceived(gomock.Eq(uint64(1)), gomock.Eq(1))
+	metrics.EXPECT().AddBytesReceived(gomock.Eq(uint64(1)), gomock.Eq(msg.Size()))
+	eng.EXPECT().Push(gomock.Any()).Return(nil)
+	c.metrics = metrics
+	err = c.Push(context.TODO(), 0, msg)
+	require.NoError(t, err)
+}
+
+func TestControllerPushRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	remote := membershipmock.NewMockMember(ctrl)
+	remote.EXPECT().Type().Return(raftpb.VoterMember).AnyTimes()
+	pool.EXPECT().Get(uint64(1)).Return(nil, false).AnyTimes()
+	pool.EXPECT().Get(uint64(2)).Return(remote, true).AnyTimes()
+	c := new(controller)
+	c.engine = eng
+	c.pool = pool
+
+	// it push the message not destined to a remote member to the engine.
+	eng.EXPECT().Push(gomock.Any()).Return(nil)
+	err := c.Push(context.TODO(), 0, etcdraftpb.Message{To: 1})
+	require.NoError(t, err)
+
+	// it reject the message destined to another member when relay disabled.
+	err = c.Push(context.TODO(), 0, etcdraftpb.Message{To: 2})
+	require.Error(t, err)
+
+	// it forward the message destined to another member when relay enabled.
+	remote.EXPECT().Send(gomock.Eq(etcdraftpb.Message{To: 2})).Return(nil)
+	c.relay = true
+	err = c.Push(context.TODO(), 0, etcdraftpb.Message{To: 2})
+	require.NoError(t, err)
+}
+
+func TestControllerPushLocal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	cfg := membershipmock.NewMockConfig(ctrl)
+	cfg.EXPECT().Logger().Return(raftlog.DefaultLogger).AnyTimes()
+	cfg.EXPECT().Reporter().Return(nil).AnyTimes()
+
+	// the local member reports its raft role, not LocalMember.
+	pool := membership.New(cfg)
+	pool.RegisterTypeMatcher(func(m raftpb.Member) raftpb.MemberType {
+		return raftpb.LocalMember
+	})
+	require.NoError(t, pool.Add(raftpb.Member{ID: 1, Type: raftpb.VoterMember}))
+
+	c := new(controller)
+	c.engine = eng
+	c.pool = pool
+
+	// it push the message destined to the current member when relay disabled.
+	eng.EXPECT().Push(gomock.Any()).Return(nil)
+	err := c.Push(context.TODO(), 0, etcdraftpb.Message{To: 1})
+	require.NoError(t, err)
+}
+
+func TestControllerPushSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	stg := storagemock.NewMockStorage(ctrl)
+	shotter := storagemock.NewMockSnapshotter(ctrl)
+	stg.EXPECT().Snapshotter().Return(shotter).AnyTimes()
+	pool := membershipmock.NewMockPool(ctrl)
+	pool.EXPECT().Get(gomock.Any()).Return(nil, false).AnyTimes()
+	c := new(controller)
+	c.engine = eng
+	c.storage = stg
+	c.pool = pool
+
+	msg := etcdraftpb.Message{Type: etcdraftpb.MsgSnap}
+	msg.Snapshot.Metadata = etcdraftpb.SnapshotMetadata{Term: 1, Index: 2}
+
+	// it reject corrupted snapshot.
+	shotter.EXPECT().Verify(gomock.Any(), uint64(1), uint64(2)).Return(ErrSnapshotCorrupted)
+	err := c.Push(context.TODO(), 0, msg)
+	require.ErrorIs(t, err, ErrSnapshotCorrupted)
+
+	// it fetch the snapshot out of band when the message carries its key.
+	ref := msg
+	ref.Snapshot.Data = []byte("key")
+	shotter.EXPECT().Fetch(gomock.Any(), "key").Return(ErrNodeStopped)
+	err = c.Push(context.TODO(), 0, ref)
+	require.Equal(t, ErrNodeStopped, err)
+
+	// it push verified snapshot to the engine.
+	shotter.EXPECT().Verify(gomock.Any(), uint64(1), uint64(2)).Return(nil)
+	eng.EXPECT().Push(gomock.Any()).Return(nil)
+	err = c.Push(context.TODO(), 0, msg)
+	require.NoError(t, err)
 }
 
 func TestControllerPromoteMember(t *testing.T) {
@@ -50,6 +154,8 @@ func TestControllerJoin(t *testing.T) {
 				pool := membershipmock.NewMockPool(ctrl)
 				pool.EXPECT().Get(gomock.Any()).Return(nil, false)
 				eng := raftenginemock.NewMockEngine(ctrl)
+				eng.EXPECT().ClusterID().Return(uint64(0))
+				eng.EXPECT().ClusterVersion().Return(uint32(0))
 				eng.EXPECT().Status().Return(raft.Status{}, nil)
 				eng.
 					EXPECT().
@@ -73,6 +179,8 @@ func TestControllerJoin(t *testing.T) {
 				mem.EXPECT().Type().Return(VoterMember)
 				pool.EXPECT().Get(gomock.Any()).Return(mem, true).MaxTimes(2)
 				pool.EXPECT().Snapshot().Return(nil)
+				eng.EXPECT().ClusterID().Return(uint64(7)).Times(2)
+				eng.EXPECT().ClusterVersion().Return(ProtocolVersion)
 				eng.EXPECT().Status().Return(raft.Status{}, nil)
 				eng.EXPECT().ProposeConfChange(gomock.Any(), gomock.Any(), gomock.Eq(etcdraftpb.ConfChangeUpdateNode)).Return(nil)
 				n := new(Node)
@@ -82,7 +190,7 @@ func TestControllerJoin(t *testing.T) {
 				c.node = n
 				c.pool = pool
 			},
-			raw: &RawMember{ID: 123},
+			raw: &RawMember{ID: 123, Version: ProtocolVersion, ClusterID: 7},
 			id:  123,
 		},
 	}
@@ -95,9 +203,48 @@ func TestControllerJoin(t *testing.T) {
 		if tt.err == nil {
 			require.NotNil(t, resp)
 			require.Equal(t, tt.id, resp.ID)
+			require.Equal(t, tt.raw.ClusterID, resp.ClusterID)
 		}
 	}
 
+	// it reject the member older than the cluster version.
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	eng.EXPECT().ClusterID().Return(uint64(0))
+	eng.EXPECT().ClusterVersion().Return(ProtocolVersion)
+	c := new(controller)
+	c.node = &Node{engine: eng}
+	_, err := c.Join(context.TODO(), 0, &RawMember{ID: 1})
+	require.ErrorIs(t, err, ErrIncompatibleVersion)
+
+	// it reject the member of another cluster.
+	eng.EXPECT().ClusterID().Return(uint64(7))
+	_, err = c.Join(context.TODO(), 0, &RawMember{ID: 1, ClusterID: 8})
+	require.ErrorIs(t, err, ErrClusterMismatch)
+}
+
+func TestControllerAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	c := new(controller)
+	c.node = &Node{engine: eng}
+
+	ctx := func(id uint64) context.Context {
+		return transport.ContextWithCredentials(context.TODO(), transport.Credentials{ClusterID: id})
+	}
+
+	// it accept the requests while the cluster id unknown.
+	eng.EXPECT().ClusterID().Return(uint64(0))
+	require.NoError(t, c.Authenticate(ctx(8), 0))
+
+	eng.EXPECT().ClusterID().Return(uint64(7)).Times(3)
+	require.NoError(t, c.Authenticate(ctx(0), 0))
+	require.NoError(t, c.Authenticate(ctx(7), 0))
+
+	// it reject the requests of another cluster.
+	err := c.Authenticate(ctx(8), 0)
+	require.ErrorIs(t, err, ErrUnauthenticated)
+	require.ErrorIs(t, err, ErrClusterMismatch)
 }
 
 func TestRouterMethodsErr(t *testing.T) {
@@ -0,0 +1,144 @@
+package raft
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	debugStatusURI  = "/debug/raft/status"
+	debugMembersURI = "/debug/raft/members"
+	debugStorageURI = "/debug/raft/storage"
+	debugEventsURI  = "/debug/raft/events"
+)
+
+// debugMember is the JSON rendering of a member, See Node.DebugHandler.
+type debugMember struct {
+	ID          uint64            `json:"id"`
+	Address     string            `json:"address"`
+	Type        string            `json:"type"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Active      bool              `json:"active"`
+	ActiveSince time.Time         `json:"activeSince"`
+	LastContact time.Time         `json:"lastContact"`
+	Draining    bool              `json:"draining"`
+	Leader      bool              `json:"leader"`
+}
+
+// debugStorage is the JSON rendering of the node storage, See Node.DebugHandler.
+type debugStorage struct {
+	StateDir  string  `json:"stateDir"`
+	WALDir    string  `json:"walDir"`
+	SnapDir   string  `json:"snapDir"`
+	Available uint64  `json:"available"`
+	Alarms    []Alarm `json:"alarms"`
+}
+
+// debugEvent is the JSON rendering of an engine event, See Node.DebugHandler.
+type debugEvent struct {
+	Type   string    `json:"type"`
+	Time   time.Time `json:"time"`
+	Term   uint64    `json:"term,omitempty"`
+	Index  uint64    `json:"index,omitempty"`
+	Member uint64    `json:"member,omitempty"`
+	Detail string    `json:"detail"`
+}
+
+// DebugHandler returns an http.Handler that renders the node engine, members pool, and storage state as JSON,
+// for support bundles and live debugging, it serves:
+//
+//	/debug/raft/status   the raft status, See Node.Status.
+//	/debug/raft/members  the cluster members, See Node.Members.
+//	/debug/raft/storage  the state directories, the available disk space, and the active alarms.
+//	/debug/raft/events   the recent engine events, See Node.RecentEvents.
+//
+// The handler is mountable into the embedder HTTP server, e.g.
+//
+//	mux.Handle("/debug/raft/", node.DebugHandler())
+//
+// Note: the handler exposes the cluster topology and state, therefore it must not be publicly reachable.
+func (n *Node) DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(debugStatusURI, debugHandler(n.debugStatus))
+	mux.HandleFunc(debugMembersURI, debugHandler(n.debugMembers))
+	mux.HandleFunc(debugStorageURI, debugHandler(n.debugStorage))
+	mux.HandleFunc(debugEventsURI, debugHandler(n.debugEvents))
+	return mux
+}
+
+func (n *Node) debugStatus() (interface{}, error) {
+	return n.Status()
+}
+
+func (n *Node) debugMembers() (interface{}, error) {
+	membs := n.Members()
+	out := make([]debugMember, 0, len(membs))
+	for _, m := range membs {
+		info := m.Info()
+		out = append(out, debugMember{
+			ID:          info.ID,
+			Address:     info.Address,
+			Type:        info.Type.String(),
+			Labels:      info.Labels,
+			Active:      info.Active,
+			ActiveSince: info.ActiveSince,
+			LastContact: info.LastContact,
+			Draining:    info.Draining,
+			Leader:      info.Leader,
+		})
+	}
+	return out, nil
+}
+
+func (n *Node) debugStorage() (interface{}, error) {
+	available, err := n.storage.Available()
+	if err != nil {
+		return nil, err
+	}
+
+	return debugStorage{
+		StateDir:  n.cfg.StateDir(),
+		WALDir:    n.cfg.WALDir(),
+		SnapDir:   n.cfg.SnapDir(),
+		Available: available,
+		Alarms:    n.Alarms(),
+	}, nil
+}
+
+func (n *Node) debugEvents() (interface{}, error) {
+	evs := n.RecentEvents()
+	out := make([]debugEvent, 0, len(evs))
+	for _, ev := range evs {
+		out = append(out, debugEvent{
+			Type:   ev.Type.String(),
+			Time:   ev.Time,
+			Term:   ev.Term,
+			Index:  ev.Index,
+			Member: ev.Member,
+			Detail: ev.Detail,
+		})
+	}
+	return out, nil
+}
+
+func debugHandler(fn func() (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			code := http.StatusMethodNotAllowed
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+
+		v, err := fn()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(v)
+	}
+}
@@ -0,0 +1,86 @@
+package raft
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3"
+
+	"github.com/shaj13/raft/internal/membership"
+	membershipmock "github.com/shaj13/raft/internal/mocks/membership"
+	raftenginemock "github.com/shaj13/raft/internal/mocks/raftengine"
+	storagemock "github.com/shaj13/raft/internal/mocks/storage"
+)
+
+func TestNodeDebugHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	stg := storagemock.NewMockStorage(ctrl)
+	rs := raft.Status{}
+	rs.ID = 1
+	rs.Lead = 1
+	eng.EXPECT().Status().Return(rs, nil).AnyTimes()
+	eng.EXPECT().Alarms().Return(nil)
+	pool.EXPECT().Members().Return([]membership.Member{mem})
+	mem.EXPECT().ID().Return(uint64(1)).AnyTimes()
+	mem.EXPECT().Address().Return(":8080")
+	mem.EXPECT().Type().Return(VoterMember)
+	mem.EXPECT().Labels().Return(nil)
+	mem.EXPECT().IsActive().Return(true)
+	mem.EXPECT().ActiveSince().AnyTimes()
+	mem.EXPECT().LastContact().AnyTimes()
+	mem.EXPECT().Draining().Return(false)
+	mem.EXPECT().Version().Return(ProtocolVersion)
+	stg.EXPECT().Available().Return(uint64(1024), nil)
+	eng.EXPECT().RecentEvents().Return([]Event{{Type: EventConfChange, Index: 3, Detail: "added"}})
+	n := new(Node)
+	n.engine = eng
+	n.pool = pool
+	n.storage = stg
+	n.cfg = newConfig(WithStateDIR("/raft"))
+
+	ts := httptest.NewServer(n.DebugHandler())
+	defer ts.Close()
+
+	get := func(uri string, v interface{}) {
+		res, err := ts.Client().Get(ts.URL + uri)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, json.NewDecoder(res.Body).Decode(v))
+	}
+
+	status := map[string]interface{}{}
+	get(debugStatusURI, &status)
+	require.Equal(t, float64(1), status["Leader"])
+
+	membs := []debugMember{}
+	get(debugMembersURI, &membs)
+	require.Len(t, membs, 1)
+	require.Equal(t, ":8080", membs[0].Address)
+	require.Equal(t, VoterMember.String(), membs[0].Type)
+	require.True(t, membs[0].Leader)
+
+	stor := debugStorage{}
+	get(debugStorageURI, &stor)
+	require.Equal(t, "/raft", stor.StateDir)
+	require.Equal(t, uint64(1024), stor.Available)
+
+	evs := []debugEvent{}
+	get(debugEventsURI, &evs)
+	require.Len(t, evs, 1)
+	require.Equal(t, EventConfChange.String(), evs[0].Type)
+	require.Equal(t, uint64(3), evs[0].Index)
+
+	// it reject non GET requests.
+	res, err := ts.Client().Post(ts.URL+debugStatusURI, "", nil)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
@@ -0,0 +1,269 @@
+// Package errors defines the typed errors returned by the raft node and its transports.
+//
+// The errors carry a canonical gRPC status code and a stable reason,
+// so they survive the wire and clients can branch on the error type rather than strings.
+//
+//	if errors.Is(err, rafterrors.ErrNotLeader) {
+//		var nle *rafterrors.NotLeaderError
+//		if errors.As(err, &nle) {
+//			// retry against nle.Address.
+//		}
+//	}
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the gRPC error info domain of the raft errors.
+const Domain = "raft"
+
+var (
+	// ErrStopped is returned when the node not ready yet or has been stopped.
+	ErrStopped = newError(codes.Unavailable, "STOPPED", "raft: node not ready yet or has been stopped")
+	// ErrNotLeader is returned when an operation can't be completed on a
+	// follower or candidate node, See NotLeaderError.
+	ErrNotLeader = newError(codes.FailedPrecondition, "NOT_LEADER", "raft: node is not the leader")
+	// ErrNoLeader is returned when leader lost, or there is no elected cluster leader.
+	ErrNoLeader = newError(codes.Unavailable, "NO_LEADER", "raft: no elected cluster leader")
+	// ErrApplyStalled is returned when the applied index not advancing toward the committed index.
+	ErrApplyStalled = newError(codes.Unavailable, "APPLY_STALLED", "raft: applied index is not advancing")
+	// ErrOverloaded is returned when the node buffers are full and the request dropped.
+	ErrOverloaded = newError(codes.ResourceExhausted, "OVERLOADED", "raft: overloaded, buffer is full")
+	// ErrProposalDropped is returned when a proposal dropped before being handed to raft,
+	// e.g. its context expired while queued, therefore it will never be committed and safe to retry.
+	ErrProposalDropped = newError(codes.Aborted, "PROPOSAL_DROPPED", "raft: proposal dropped, it will never be committed")
+	// ErrRateLimited is returned when the source exceeds the requests rate limit.
+	ErrRateLimited = newError(codes.ResourceExhausted, "RATE_LIMITED", "raft: too many requests, rate limit exceeded")
+	// ErrMemberNotFound is returned when the requested member is not part of the cluster.
+	ErrMemberNotFound = newError(codes.NotFound, "MEMBER_NOT_FOUND", "raft: member not found")
+	// ErrCorrupted is returned when the persisted or received data is corrupted or inconsistent.
+	ErrCorrupted = newError(codes.DataLoss, "CORRUPTED", "raft: data corrupted")
+	// ErrAlreadySnapshotting is returned when a snapshot is already in progress.
+	ErrAlreadySnapshotting = newError(codes.Aborted, "ALREADY_SNAPSHOTTING", "raft: already snapshotting")
+	// ErrFailedPrecondition is returned when the precondition of an operation is not met.
+	ErrFailedPrecondition = newError(codes.FailedPrecondition, "FAILED_PRECONDITION", "raft: precondition failed")
+	// ErrNoSpace is returned when the cluster has an active NOSPACE alarm.
+	ErrNoSpace = newError(
+		codes.ResourceExhausted,
+		"NO_SPACE",
+		"raft: no space alarm is active, cluster is in maintenance mode",
+	)
+	// ErrMetadataTooLarge is returned when the metadata attached to a proposal is too large.
+	ErrMetadataTooLarge = newError(codes.InvalidArgument, "METADATA_TOO_LARGE", "raft: proposal metadata too large")
+	// ErrProposalTooLarge is returned when the proposal data exceeds the max proposal size.
+	ErrProposalTooLarge = newError(codes.InvalidArgument, "PROPOSAL_TOO_LARGE", "raft: proposal too large")
+	// ErrJoinDenied is returned when a request denied by the admission policy.
+	ErrJoinDenied = newError(codes.PermissionDenied, "JOIN_DENIED", "raft: request denied by the admission policy")
+	// ErrUnauthenticated is returned when a request missing or carrying invalid credentials.
+	ErrUnauthenticated = newError(codes.Unauthenticated, "UNAUTHENTICATED", "raft: unauthenticated request")
+	// ErrJoinRejected is returned when the cluster members rejected the join request.
+	ErrJoinRejected = newError(codes.PermissionDenied, "JOIN_REJECTED", "raft: join request rejected")
+	// ErrClusterNotReady is returned when the cluster not ready to serve the join request.
+	ErrClusterNotReady = newError(codes.Unavailable, "CLUSTER_NOT_READY", "raft: cluster not ready to serve join request")
+	// ErrClusterMismatch is returned when a request source belongs to a different cluster.
+	ErrClusterMismatch = newError(codes.PermissionDenied, "CLUSTER_MISMATCH", "raft: request belongs to a different cluster")
+	// ErrCompacted is returned when the requested log index compacted from the retained log entries.
+	ErrCompacted = newError(codes.OutOfRange, "COMPACTED", "raft: requested index is compacted")
+	// ErrIncompatibleVersion is returned when a member protocol version is older than the cluster version.
+	ErrIncompatibleVersion = newError(
+		codes.FailedPrecondition,
+		"INCOMPATIBLE_VERSION",
+		"raft: member protocol version is older than the cluster version",
+	)
+	// ErrFeatureUnsupported is returned when a feature is not supported yet by all the cluster members.
+	ErrFeatureUnsupported = newError(
+		codes.FailedPrecondition,
+		"FEATURE_UNSUPPORTED",
+		"raft: feature not supported by all the cluster members",
+	)
+)
+
+// reasons maps the errors reasons to the errors.
+var reasons = map[string]*Error{}
+
+// Error is a typed raft error.
+type Error struct {
+	code   codes.Code
+	reason string
+	msg    string
+}
+
+func newError(code codes.Code, reason, msg string) *Error {
+	err := &Error{
+		code:   code,
+		reason: reason,
+		msg:    msg,
+	}
+	reasons[reason] = err
+	return err
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Code returns the canonical gRPC status code of the error.
+func (e *Error) Code() codes.Code {
+	return e.code
+}
+
+// Reason returns the stable reason identifying the error over the wire.
+func (e *Error) Reason() string {
+	return e.reason
+}
+
+// GRPCStatus returns the gRPC status of the error.
+func (e *Error) GRPCStatus() *status.Status {
+	return withInfo(status.New(e.code, e.msg), e.reason, nil)
+}
+
+// NotLeaderError is returned when an operation can't be completed on a
+// follower or candidate node, it carries the known leader hint if any.
+// NotLeaderError matches ErrNotLeader, using errors.Is.
+type NotLeaderError struct {
+	// Leader is the known leader member id, or 0 if none.
+	Leader uint64
+	// Address is the known leader member address, or empty if none.
+	Address string
+}
+
+// Error implements error.
+func (e *NotLeaderError) Error() string {
+	if e.Leader == 0 {
+		return ErrNotLeader.msg
+	}
+	return fmt.Sprintf("%s, leader is member %x on address %s", ErrNotLeader.msg, e.Leader, e.Address)
+}
+
+// Is reports whether the target is ErrNotLeader.
+func (e *NotLeaderError) Is(target error) bool {
+	return target == ErrNotLeader
+}
+
+// GRPCStatus returns the gRPC status of the error, that carries the leader hint.
+func (e *NotLeaderError) GRPCStatus() *status.Status {
+	md := map[string]string{
+		"leader":  strconv.FormatUint(e.Leader, 10),
+		"address": e.Address,
+	}
+	return withInfo(status.New(ErrNotLeader.code, e.Error()), ErrNotLeader.reason, md)
+}
+
+// remoteError is a typed raft error received over the wire,
+// it carries the remote error message.
+type remoteError struct {
+	err *Error
+	msg string
+}
+
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+func (e *remoteError) Unwrap() error {
+	return e.err
+}
+
+func (e *remoteError) GRPCStatus() *status.Status {
+	return withInfo(status.New(e.err.code, e.msg), e.err.reason, nil)
+}
+
+// ReasonOf returns the reason of the typed raft error wrapped by the given error,
+// or an empty string if none.
+func ReasonOf(err error) string {
+	var (
+		rerr *Error
+		nle  *NotLeaderError
+	)
+
+	if errors.As(err, &nle) {
+		return ErrNotLeader.reason
+	}
+
+	if errors.As(err, &rerr) {
+		return rerr.reason
+	}
+
+	return ""
+}
+
+// FromReason returns the typed raft error of the given reason, that carries the given message,
+// e.g. received from a remote member over a transport without gRPC status.
+// It returns nil if the reason is unknown.
+func FromReason(reason, msg string) error {
+	rerr, ok := reasons[reason]
+	if !ok {
+		return nil
+	}
+	return &remoteError{err: rerr, msg: msg}
+}
+
+// FromError returns the typed raft error of the given gRPC status error,
+// received from a remote member, so it can be matched by errors.Is and errors.As.
+// Otherwise, it returns the given error as is.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		rerr *Error
+		nle  *NotLeaderError
+	)
+
+	if errors.As(err, &rerr) || errors.As(err, &nle) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != Domain {
+			continue
+		}
+
+		if info.Reason == ErrNotLeader.reason {
+			leader, _ := strconv.ParseUint(info.Metadata["leader"], 10, 64)
+			return &NotLeaderError{
+				Leader:  leader,
+				Address: info.Metadata["address"],
+			}
+		}
+
+		if rerr := FromReason(info.Reason, st.Message()); rerr != nil {
+			return rerr
+		}
+	}
+
+	// the members authenticate the requests before handling them,
+	// and may reject them with a plain status.
+	if st.Code() == codes.Unauthenticated {
+		return &remoteError{err: ErrUnauthenticated, msg: st.Message()}
+	}
+
+	return err
+}
+
+func withInfo(st *status.Status, reason string, md map[string]string) *status.Status {
+	sst, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: md,
+	})
+	if err != nil {
+		return st
+	}
+	return sst
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromError(t *testing.T) {
+	table := []struct {
+		name string
+		err  error
+		code codes.Code
+		is   error
+	}{
+		{
+			name: "it restore typed error",
+			err:  ErrNoSpace,
+			code: codes.ResourceExhausted,
+			is:   ErrNoSpace,
+		},
+		{
+			name: "it restore wrapped typed error",
+			err:  fmt.Errorf("%w: source %q not allowed", ErrJoinDenied, "1.1.1.1"),
+			code: codes.PermissionDenied,
+			is:   ErrJoinDenied,
+		},
+		{
+			name: "it restore unauthenticated plain status",
+			err:  status.Error(codes.Unauthenticated, "invalid token"),
+			code: codes.Unauthenticated,
+			is:   ErrUnauthenticated,
+		},
+		{
+			name: "it restore corrupted error",
+			err:  fmt.Errorf("%w: snapshot file corrupted", ErrCorrupted),
+			code: codes.DataLoss,
+			is:   ErrCorrupted,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			// simulate the wire.
+			st, _ := status.FromError(tt.err)
+			require.Equal(t, tt.code, st.Code())
+
+			err := FromError(st.Err())
+			require.ErrorIs(t, err, tt.is)
+			require.Equal(t, st.Message(), err.Error())
+			require.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestFromErrorNotLeader(t *testing.T) {
+	st, _ := status.FromError(&NotLeaderError{Leader: 2, Address: ":8080"})
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+
+	err := FromError(st.Err())
+	require.ErrorIs(t, err, ErrNotLeader)
+
+	nle := new(NotLeaderError)
+	require.True(t, errors.As(err, &nle))
+	require.Equal(t, uint64(2), nle.Leader)
+	require.Equal(t, ":8080", nle.Address)
+}
+
+func TestFromErrorUnknown(t *testing.T) {
+	err := errors.New("TestFromErrorUnknown")
+	require.Equal(t, err, FromError(err))
+
+	err = status.Error(codes.Internal, "TestFromErrorUnknown")
+	require.Equal(t, err, FromError(err))
+	require.Nil(t, FromError(nil))
+}
+
+func TestReason(t *testing.T) {
+	err := fmt.Errorf("%w: message dropped", ErrOverloaded)
+	reason := ReasonOf(err)
+	require.Equal(t, "OVERLOADED", reason)
+
+	rerr := FromReason(reason, err.Error())
+	require.ErrorIs(t, rerr, ErrOverloaded)
+	require.Equal(t, err.Error(), rerr.Error())
+
+	require.Equal(t, "NOT_LEADER", ReasonOf(&NotLeaderError{}))
+	require.Empty(t, ReasonOf(errors.New("TestReason")))
+	require.Nil(t, FromReason("", "TestReason"))
+}
@@ -1,6 +1,7 @@
 package raft_test
 
 import (
+	"context"
 	"io"
 	"net/http"
 
@@ -31,3 +32,23 @@ func Example_http() {
 		Handler: handler,
 	}
 }
+
+func Example_runtime() {
+	rt := raft.NewRuntime(transport.HTTP, raft.WithStateDIR("/var/lib/raft"))
+	tenantA := rt.NewNode(1, stateMachine{})
+	tenantB := rt.NewNode(2, stateMachine{})
+	_ = http.Server{
+		Handler: rafthttp.Handler(rt.Handler()),
+	}
+	_, _ = tenantA, tenantB
+}
+
+func Example_singleNode() {
+	node, err := raft.NewSingleNode(stateMachine{})
+	if err != nil {
+		panic(err)
+	}
+
+	defer node.Shutdown(context.Background()) //nolint:errcheck
+	_ = node.Replicate(context.Background(), []byte("data"))
+}
@@ -56,6 +56,11 @@ func (u *Uint64) Get() uint64 {
 	return atomic.LoadUint64((*uint64)(u))
 }
 
+// Inc atomically increments u by one and returns the new value.
+func (u *Uint64) Inc() uint64 {
+	return atomic.AddUint64((*uint64)(u), 1)
+}
+
 // String returns u as string.
 func (u *Uint64) String() string {
 	return strconv.FormatUint(u.Get(), 10)
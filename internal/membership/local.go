@@ -19,6 +19,15 @@ func newLocal(cfg Config, m raftpb.Member) (Member, error) {
 	return l, nil
 }
 
+// IsLocal reports whether the given member is the current cluster member.
+//
+// Note: the member type reports the member raft role, e.g. voter or learner,
+// therefore it can't tell whether the member is the current member.
+func IsLocal(m Member) bool {
+	_, ok := m.(*local)
+	return ok
+}
+
 // local represents the current cluster member.
 type local struct {
 	r      Reporter
@@ -42,10 +51,36 @@ func (l *local) IsActive() bool {
 	return !l.active.IsZero()
 }
 
+// LastContact returns the current time, the local member always reachable.
+func (l *local) LastContact() time.Time {
+	return time.Now()
+}
+
+func (l *local) Failures() int {
+	return 0
+}
+
+// QueueStats returns zero stats, messages never queued to the local member.
+func (l *local) QueueStats() QueueStats {
+	return QueueStats{}
+}
+
 func (l *local) Type() raftpb.MemberType {
 	return l.Raw().Type
 }
 
+func (l *local) Labels() map[string]string {
+	return l.Raw().Labels
+}
+
+func (l *local) Draining() bool {
+	return l.Raw().Draining
+}
+
+func (l *local) Version() uint32 {
+	return l.Raw().Version
+}
+
 func (l *local) Update(m raftpb.Member) (err error) {
 	l.raw.Store(m)
 	return
@@ -18,8 +18,10 @@ func TestLocal(t *testing.T) {
 		ID:      id,
 		Address: addr,
 		Type:    raftpb.LearnerMember,
+		Labels:  map[string]string{"zone": "a"},
 	})
 	require.Equal(t, l.ID(), id)
+	require.Equal(t, map[string]string{"zone": "a"}, l.Labels())
 	require.Equal(t, l.Address(), addr)
 	require.False(t, l.IsActive())
 	require.Equal(t, l.ActiveSince(), time.Time{})
@@ -28,4 +30,6 @@ func TestLocal(t *testing.T) {
 	require.Empty(t, l.Address())
 	require.Panics(t, func() { l.Send(etcdraftpb.Message{}) })
 	require.Equal(t, raw, l.Raw())
+	require.True(t, IsLocal(&l))
+	require.False(t, IsLocal(new(remote)))
 }
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/raftlog"
 	"golang.org/x/sync/errgroup"
@@ -18,7 +20,7 @@ func init() {
 
 // New construct and returns a new pool members.
 func New(cfg Config) Pool {
-	return &pool{
+	p := &pool{
 		cfg:    cfg,
 		logger: cfg.Logger(),
 		membs:  make(map[uint64]Member),
@@ -26,6 +28,8 @@ func New(cfg Config) Pool {
 			return m.Type
 		},
 	}
+	p.publish()
+	return p
 }
 
 type pool struct {
@@ -34,6 +38,7 @@ type pool struct {
 	matcher func(m raftpb.Member) raftpb.MemberType
 	mu      sync.Mutex // protects the membs
 	membs   map[uint64]Member
+	view    atomic.Value // []Member, copy of membs.
 }
 
 func (p *pool) RegisterTypeMatcher(fn func(m raftpb.Member) raftpb.MemberType) {
@@ -51,14 +56,20 @@ func (p *pool) NextID() uint64 {
 	return id
 }
 
+// Members returns the members without locking the pool, from the copy published on each change,
+// so the members can look up their peers while the pool closing them.
 func (p *pool) Members() []Member {
-	membs := []Member{}
-	p.mu.Lock()
+	view, _ := p.view.Load().([]Member)
+	return append([]Member{}, view...)
+}
+
+// publish copies the members to be returned by Members, it must be called with the mu held.
+func (p *pool) publish() {
+	view := make([]Member, 0, len(p.membs))
 	for _, m := range p.membs {
-		membs = append(membs, m)
+		view = append(view, m)
 	}
-	p.mu.Unlock()
-	return membs
+	p.view.Store(view)
 }
 
 func (p *pool) Get(id uint64) (Member, bool) {
@@ -83,6 +94,7 @@ func (p *pool) Add(m raftpb.Member) error {
 	}
 
 	p.membs[m.ID] = mem
+	p.publish()
 	return nil
 }
 
@@ -98,7 +110,7 @@ func (p *pool) Update(m raftpb.Member) error {
 func (p *pool) Remove(m raftpb.Member) error {
 	mem, ok := p.Get(m.ID)
 	if !ok {
-		return fmt.Errorf("raft/membership: member %x not found", m.ID)
+		return fmt.Errorf("%w: %x", rafterrors.ErrMemberNotFound, m.ID)
 	}
 
 	if mem.Type() == m.Type {
@@ -118,6 +130,7 @@ func (p *pool) Remove(m raftpb.Member) error {
 	}
 
 	p.membs[m.ID] = mem
+	p.publish()
 	return nil
 }
 
@@ -154,6 +167,7 @@ func (p *pool) TearDown(ctx context.Context) error {
 		eg.Go(fn(mem))
 	}
 	p.membs = make(map[uint64]Member)
+	p.publish()
 	return eg.Wait()
 }
 
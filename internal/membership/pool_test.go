@@ -131,6 +131,12 @@ func testConfig(t *testing.T) *MockConfig {
 	cfg.EXPECT().StreamTimeout().Return(time.Duration(-1)).AnyTimes()
 	cfg.EXPECT().Context().Return(context.TODO()).AnyTimes()
 	cfg.EXPECT().Logger().Return(raftlog.DefaultLogger).AnyTimes()
+	cfg.EXPECT().BatchSize().Return(0).AnyTimes()
+	cfg.EXPECT().BatchWindow().Return(time.Duration(0)).AnyTimes()
+	cfg.EXPECT().RetryPolicy().Return(RetryPolicy{}).AnyTimes()
+	cfg.EXPECT().QueuePolicy().Return(QueuePolicy{}).AnyTimes()
+	cfg.EXPECT().TransportMetrics().Return(nil).AnyTimes()
+	cfg.EXPECT().AllowRelay().Return(false).AnyTimes()
 	return cfg
 }
 
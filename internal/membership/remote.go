@@ -8,6 +8,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/transport"
 	"github.com/shaj13/raft/raftlog"
@@ -15,6 +16,11 @@ import (
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 )
 
+// controlBufSize is the size of the control messages buffer.
+const controlBufSize = 128
+
+var errMessageDropped = fmt.Errorf("%w: message dropped", rafterrors.ErrOverloaded)
+
 func newRemote(cfg Config, m raftpb.Member) (Member, error) {
 	connPerPipeline := 1
 	pipelineBufSize := 4096
@@ -27,18 +33,40 @@ func newRemote(cfg Config, m raftpb.Member) (Member, error) {
 		connPerPipeline = 4
 	}
 
-	rpc, err := cfg.Dial()(ctx, m.Address)
+	qp := cfg.QueuePolicy()
+	if qp.MaxMessages > 0 {
+		pipelineBufSize = qp.MaxMessages
+	}
+
+	dial := dialer(cfg.Dial(), cfg.RetryPolicy().DialTimeout)
+	rpc, err := dial(ctx, m.Address)
 	if err != nil {
 		return nil, err
 	}
 
+	// the control messages sent over a dedicated connection,
+	// so the bulk append and snapshot traffic can't delay them.
+	crpc, err := dial(ctx, m.Address)
+	if err != nil {
+		_ = rpc.Close()
+		return nil, err
+	}
+
 	r := new(remote)
 	r.ctx, r.cancel = context.WithCancel(ctx)
 	r.rc = rpc
+	r.crc = crpc
 	r.cfg = cfg
 	r.r = cfg.Reporter()
-	r.dial = cfg.Dial()
+	r.dial = dial
+	r.qp = qp
+	r.allowRelay = cfg.AllowRelay()
+	r.metrics = cfg.TransportMetrics()
+	if r.metrics == nil {
+		r.metrics = nopMetrics{}
+	}
 	r.msgc = make(chan etcdraftpb.Message, pipelineBufSize)
+	r.ctrlc = make(chan etcdraftpb.Message, controlBufSize)
 	r.active = true
 	r.activeSince = time.Now()
 	r.logger = cfg.Logger()
@@ -51,14 +79,19 @@ func newRemote(cfg Config, m raftpb.Member) (Member, error) {
 		pipelineBufSize,
 	)
 
-	r.wg.Add(connPerPipeline)
+	r.wg.Add(connPerPipeline + 1)
 	for i := 0; i < connPerPipeline; i++ {
 		go func() {
 			defer r.wg.Done()
-			r.process(r.ctx)
+			r.process(r.ctx, r.msgc, r.client)
 		}()
 	}
 
+	go func() {
+		defer r.wg.Done()
+		r.process(r.ctx, r.ctrlc, r.controlClient)
+	}()
+
 	return r, nil
 }
 
@@ -71,12 +104,23 @@ type remote struct {
 	cfg         Config
 	dial        transport.Dial
 	msgc        chan etcdraftpb.Message
+	ctrlc       chan etcdraftpb.Message
+	qp          QueuePolicy
+	metrics     transport.Metrics
+	allowRelay  bool
+	qbytes      atomic.Int64
+	dropped     atomic.Uint64
+	rejected    atomic.Uint64
 	wg          sync.WaitGroup
 	mu          sync.Mutex // protects following fields
 	raw         atomic.Value
 	active      bool
 	rc          transport.Client
+	crc         transport.Client
 	activeSince time.Time
+	downSince   time.Time
+	lastContact time.Time
+	failures    int
 }
 
 func (r *remote) Raw() raftpb.Member {
@@ -87,6 +131,10 @@ func (r *remote) Type() raftpb.MemberType {
 	return r.Raw().Type
 }
 
+func (r *remote) Labels() map[string]string {
+	return r.Raw().Labels
+}
+
 func (r *remote) Send(msg etcdraftpb.Message) (err error) {
 	defer func() {
 		if err != nil {
@@ -98,15 +146,73 @@ func (r *remote) Send(msg etcdraftpb.Message) (err error) {
 		return err
 	}
 
+	size := msg.Size()
+	if isControl(msg) {
+		return r.enqueue(r.ctrlc, msg, size)
+	}
+
+	for r.qp.DropOldest && r.overflow(size) {
+		if !r.dropOldest() {
+			break
+		}
+	}
+
+	if r.overflow(size) {
+		r.rejected.Add(1)
+		return fmt.Errorf("cluster member %x, buffer is full (overloaded network)", r.ID())
+	}
+
+	return r.enqueue(r.msgc, msg, size)
+}
+
+func (r *remote) QueueStats() QueueStats {
+	return QueueStats{
+		Messages: len(r.msgc) + len(r.ctrlc),
+		Bytes:    int(r.qbytes.Load()),
+		Dropped:  r.dropped.Load(),
+		Rejected: r.rejected.Load(),
+	}
+}
+
+// enqueue queues the given message of the given size to the given msgc.
+func (r *remote) enqueue(msgc chan etcdraftpb.Message, msg etcdraftpb.Message, size int) error {
+	r.qbytes.Add(int64(size))
+
 	select {
-	case r.msgc <- msg:
+	case msgc <- msg:
+		return nil
 	case <-r.ctx.Done():
+		r.qbytes.Add(-int64(size))
 		return r.ctx.Err()
 	default:
+		r.qbytes.Add(-int64(size))
+		r.rejected.Add(1)
 		return fmt.Errorf("cluster member %x, buffer is full (overloaded network)", r.ID())
 	}
+}
 
-	return
+// overflow reports whether queuing a message of the given size exceeds the queue bounds.
+func (r *remote) overflow(size int) bool {
+	if len(r.msgc) == cap(r.msgc) {
+		return true
+	}
+
+	queued := r.qbytes.Load()
+	return r.qp.MaxBytes > 0 && queued > 0 && queued+int64(size) > int64(r.qp.MaxBytes)
+}
+
+// dropOldest drops the oldest queued message, and reports whether a message dropped.
+func (r *remote) dropOldest() bool {
+	select {
+	case msg := <-r.msgc:
+		r.qbytes.Add(-int64(msg.Size()))
+		r.dropped.Add(1)
+		r.report(msg, errMessageDropped)
+		r.logger.V(2).Infof("raft.membership: dropped message %s to member %x, buffer is full", msg.Type, r.ID())
+		return true
+	default:
+		return false
+	}
 }
 
 func (r *remote) Update(m raftpb.Member) error {
@@ -124,11 +230,22 @@ func (r *remote) Update(m raftpb.Member) error {
 		return err
 	}
 
+	crc, err := r.dial(r.ctx, m.Address)
+	if err != nil {
+		_ = rc.Close()
+		return err
+	}
+
 	if err := r.rc.Close(); err != nil {
 		return err
 	}
 
+	if err := r.crc.Close(); err != nil {
+		return err
+	}
+
 	r.rc = rc
+	r.crc = crc
 	r.raw.Store(m)
 	return nil
 }
@@ -143,6 +260,26 @@ func (r *remote) ActiveSince() time.Time {
 	return r.activeSince
 }
 
+func (r *remote) Draining() bool {
+	return r.Raw().Draining
+}
+
+func (r *remote) Version() uint32 {
+	return r.Raw().Version
+}
+
+func (r *remote) LastContact() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastContact
+}
+
+func (r *remote) Failures() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures
+}
+
 func (r *remote) IsActive() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -161,25 +298,68 @@ func (r *remote) Close() error {
 
 func (r *remote) TearDown(ctx context.Context) error {
 	r.cancel()
+	r.mu.Lock()
 	close(r.msgc) // ctx.Done no goroutines will write to msgc.
+	close(r.ctrlc)
+	r.mu.Unlock()
 	r.wg.Wait()
-	r.process(ctx) // drain msgc
+	r.process(ctx, r.ctrlc, r.controlClient) // drain ctrlc
+	r.process(ctx, r.msgc, r.client)         // drain msgc
 	r.setStatus(false)
-	return r.client().Close()
+
+	cerr := r.controlClient().Close()
+	if err := r.client().Close(); err != nil {
+		return err
+	}
+
+	return cerr
 }
 
-func (r *remote) setStatus(active bool) {
+// setStatus sets the member status, and reports whether the status changed,
+// along with the duration the member has been unreachable.
+func (r *remote) setStatus(active bool) (bool, time.Duration) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	now := time.Now()
+
 	switch {
 	case !r.active && active:
-		r.activeSince = time.Now()
+		var d time.Duration
+		if !r.downSince.IsZero() {
+			d = now.Sub(r.downSince)
+		}
+		r.activeSince = now
+		r.downSince = time.Time{}
 		r.active = true
+		return true, d
 	case r.active && !active:
+		var d time.Duration
+		if !r.lastContact.IsZero() {
+			d = now.Sub(r.lastContact)
+		}
 		r.activeSince = time.Time{}
+		r.downSince = now
 		r.active = false
+		return true, d
+	}
+
+	return false, 0
+}
+
+// contact records the result of sending a message to the member,
+// either the last successful send time, or the number of consecutive failures.
+func (r *remote) contact(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.failures++
+		return
 	}
+
+	r.lastContact = time.Now()
+	r.failures = 0
 }
 
 func (r *remote) report(msg etcdraftpb.Message, err error) {
@@ -199,24 +379,274 @@ func (r *remote) client() transport.Client {
 	return r.rc
 }
 
-func (r *remote) process(ctx context.Context) {
+// controlClient returns the client of the dedicated control messages connection.
+func (r *remote) controlClient() transport.Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.crc
+}
+
+// process sends the messages received from the given msgc,
+// using the client returned by the given rpc func.
+func (r *remote) process(ctx context.Context, msgc chan etcdraftpb.Message, rpc func() transport.Client) {
 	// perr capture the previous error to avoid overflow logs writer with the same error.
 	var perr error
-	for msg := range r.msgc {
+	for msg := range msgc {
 		if err := ctx.Err(); err != nil {
 			return
 		}
-		ctx, cancel := context.WithTimeout(ctx, r.cfg.StreamTimeout())
-		rpc := r.client()
-		err := rpc.Message(ctx, msg)
-		if err != nil && !errors.Is(err, perr) || err != nil && r.logger.V(3).Enabled() {
-			r.logger.Errorf("raft.membership: sending message to member %x: %v", r.ID(), err)
-		} else if err == nil && perr != nil {
-			r.logger.Infof("raft.membership: sending message to member %x succeed", r.ID())
+
+		batch, next := r.batch(msgc, msg)
+		perr = r.send(ctx, rpc(), batch, perr)
+		if next != nil {
+			perr = r.send(ctx, rpc(), []etcdraftpb.Message{*next}, perr)
+		}
+	}
+}
+
+// batch collects the messages queued after the given message, up to the batch size,
+// and within the batch window, to send them in a single rpc.
+// the snapshot messages never batched, and returned as next when they interrupt the batch.
+func (r *remote) batch(msgc chan etcdraftpb.Message, msg etcdraftpb.Message) (batch []etcdraftpb.Message, next *etcdraftpb.Message) {
+	batch = []etcdraftpb.Message{msg}
+	size := r.cfg.BatchSize()
+	if size <= 1 || msg.Type == etcdraftpb.MsgSnap {
+		return
+	}
+
+	var timeout <-chan time.Time
+	if w := r.cfg.BatchWindow(); w > 0 {
+		t := time.NewTimer(w)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	for len(batch) < size {
+		var (
+			m  etcdraftpb.Message
+			ok bool
+		)
+
+		if timeout == nil {
+			select {
+			case m, ok = <-msgc:
+			default:
+				return
+			}
+		} else {
+			select {
+			case m, ok = <-msgc:
+			case <-timeout:
+				return
+			}
+		}
+
+		if !ok {
+			return
+		}
+
+		if m.Type == etcdraftpb.MsgSnap {
+			return batch, &m
+		}
+
+		batch = append(batch, m)
+	}
+
+	return
+}
+
+// send the given messages to the member, and retry on failure according to the retry policy.
+// it returns the send error, and logs it unless equal to the given previous error.
+func (r *remote) send(ctx context.Context, rpc transport.Client, msgs []etcdraftpb.Message, perr error) error {
+	size := 0
+	for _, msg := range msgs {
+		size += msg.Size()
+	}
+	r.qbytes.Add(-int64(size))
+
+	start := time.Now()
+	err := r.deliver(ctx, rpc, msgs)
+
+	// retry the failed messages, within the retry policy, before reporting the member unreachable.
+	p := r.cfg.RetryPolicy()
+	for i := 0; err != nil && i < p.MaxRetries && msgs[0].Type != etcdraftpb.MsgSnap; i++ {
+		if !sleep(ctx, p.Backoff(i)) {
+			break
 		}
-		perr = err
+		err = r.deliver(ctx, rpc, msgs)
+	}
+
+	if err != nil && r.relay(msgs, err) {
+		err = nil
+	}
+
+	if msg := msgs[0]; msg.Type == etcdraftpb.MsgSnap {
+		r.r.ReportSnapshotTransfer(r.ID(), msg.Snapshot.Metadata, time.Since(start), err)
+	}
+	if err != nil && !errors.Is(err, perr) || err != nil && r.logger.V(3).Enabled() {
+		r.logger.Errorf("raft.membership: sending message to member %x: %v", r.ID(), err)
+	} else if err == nil && perr != nil {
+		r.logger.Infof("raft.membership: sending message to member %x succeed", r.ID())
+	}
+
+	for _, msg := range msgs {
 		r.report(msg, err)
-		r.setStatus(err == nil)
-		cancel()
+	}
+	if err == nil {
+		r.metrics.AddMessagesSent(r.ID(), len(msgs))
+		r.metrics.AddBytesSent(r.ID(), size)
+	}
+	r.contact(err)
+	if changed, d := r.setStatus(err == nil); changed {
+		r.r.ReportMemberStatus(r.Raw(), err == nil, d)
+	}
+	return err
+}
+
+// relay queues the given messages, failed to be sent to the member directly, to another active member
+// that forwards them to the member, and reports whether the messages relayed.
+// only the messages originated from the local member relayed, so a relayed message never relayed again,
+// and the snapshot messages never relayed.
+func (r *remote) relay(msgs []etcdraftpb.Message, err error) bool {
+	if !r.allowRelay || msgs[0].Type == etcdraftpb.MsgSnap {
+		return false
+	}
+
+	membs := r.cfg.Pool().Members()
+
+	local := false
+	for _, m := range membs {
+		if m.ID() == msgs[0].From && IsLocal(m) {
+			local = true
+			break
+		}
+	}
+
+	if !local {
+		return false
+	}
+
+	for _, m := range membs {
+		peer, ok := m.(*remote)
+		if !ok || peer.ID() == r.ID() || !peer.IsActive() {
+			continue
+		}
+
+		if peer.forward(msgs) != nil {
+			continue
+		}
+
+		r.logger.V(2).Infof(
+			"raft.membership: relayed %d messages to member %x through member %x: %v",
+			len(msgs),
+			r.ID(),
+			peer.ID(),
+			err,
+		)
+
+		return true
+	}
+
+	return false
+}
+
+// forward queues the given messages, destined to another member, to be relayed by the member.
+func (r *remote) forward(msgs []etcdraftpb.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		msgc := r.msgc
+		if isControl(msg) {
+			msgc = r.ctrlc
+		}
+
+		if err := r.enqueue(msgc, msg, msg.Size()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver sends the given messages to the member, in a single rpc if supported by the transport.
+func (r *remote) deliver(ctx context.Context, rpc transport.Client, msgs []etcdraftpb.Message) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.StreamTimeout())
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		r.metrics.ObserveLatency(r.ID(), time.Since(start), err != nil)
+	}()
+
+	if bc, ok := rpc.(transport.BatchClient); ok && len(msgs) > 1 {
+		return bc.Messages(ctx, msgs)
+	}
+
+	for _, msg := range msgs {
+		if err = rpc.Message(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) AddMessagesSent(uint64, int)                {}
+func (nopMetrics) AddBytesSent(uint64, int)                   {}
+func (nopMetrics) AddMessagesReceived(uint64, int)            {}
+func (nopMetrics) AddBytesReceived(uint64, int)               {}
+func (nopMetrics) ObserveLatency(uint64, time.Duration, bool) {}
+
+// isControl reports whether the given message is a control message,
+// that must not be delayed by the bulk traffic to avoid spurious elections.
+func isControl(msg etcdraftpb.Message) bool {
+	switch msg.Type {
+	case etcdraftpb.MsgHeartbeat,
+		etcdraftpb.MsgHeartbeatResp,
+		etcdraftpb.MsgVote,
+		etcdraftpb.MsgVoteResp,
+		etcdraftpb.MsgPreVote,
+		etcdraftpb.MsgPreVoteResp,
+		etcdraftpb.MsgTimeoutNow:
+		return true
+	default:
+		return false
+	}
+}
+
+// dialer returns the given dial bounded by the given dial timeout.
+func dialer(dial transport.Dial, timeout time.Duration) transport.Dial {
+	if timeout <= 0 {
+		return dial
+	}
+
+	return func(ctx context.Context, addr string) (transport.Client, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return dial(ctx, addr)
+	}
+}
+
+// sleep waits for the given duration, and reports whether it elapsed before the given ctx done.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
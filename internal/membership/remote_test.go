@@ -19,10 +19,14 @@ func TestNewRemote(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	client := transportmock.NewMockClient(ctrl)
 	cfg := NewMockConfig(ctrl)
-	client.EXPECT().Close().Return(nil)
+	client.EXPECT().Close().Return(nil).Times(2)
 	dial := mockDial(client, nil)
-	cfg.EXPECT().Dial().Return(dial).MaxTimes(2)
+	cfg.EXPECT().Dial().Return(dial).MaxTimes(3)
 	cfg.EXPECT().Reporter().Return(nil)
+	cfg.EXPECT().RetryPolicy().Return(RetryPolicy{DialTimeout: time.Second})
+	cfg.EXPECT().QueuePolicy().Return(QueuePolicy{MaxMessages: 10})
+	cfg.EXPECT().TransportMetrics().Return(nil)
+	cfg.EXPECT().AllowRelay().Return(false)
 	cfg.EXPECT().DrainTimeout().Return(time.Duration(-1))
 	cfg.EXPECT().Context().Return(context.Background())
 	cfg.EXPECT().AllowPipelining().Return(true)
@@ -84,9 +88,18 @@ func TestRemoteSetStatus(t *testing.T) {
 	for _, tt := range table {
 		r := new(remote)
 		r.active = tt.currentstate
-		r.setStatus(tt.in)
+		changed, _ := r.setStatus(tt.in)
 		require.Equal(t, tt.in, r.IsActive())
+		require.Equal(t, tt.in != tt.currentstate, changed)
 	}
+
+	// it return the duration the member has been unreachable.
+	r := new(remote)
+	r.active = true
+	r.setStatus(false)
+	time.Sleep(time.Millisecond)
+	_, d := r.setStatus(true)
+	require.GreaterOrEqual(t, d, time.Millisecond)
 }
 
 func TestRemoteUpdate(t *testing.T) {
@@ -96,11 +109,12 @@ func TestRemoteUpdate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	client := transportmock.NewMockClient(ctrl)
 
-	client.EXPECT().Close().Return(nil)
+	client.EXPECT().Close().Return(nil).Times(2)
 
 	r := new(remote)
 	r.raw.Store(raftpb.Member{Address: addr})
 	r.rc = client
+	r.crc = client
 	r.ctx = context.TODO()
 	r.dial = mockDial(nil, err)
 
@@ -121,6 +135,19 @@ func TestRemoteUpdate(t *testing.T) {
 	require.Equal(t, uaddr, r.Address())
 }
 
+func TestRemoteContact(t *testing.T) {
+	r := new(remote)
+
+	r.contact(fmt.Errorf("TestRemoteContact"))
+	r.contact(fmt.Errorf("TestRemoteContact"))
+	require.Equal(t, 2, r.Failures())
+	require.True(t, r.LastContact().IsZero())
+
+	r.contact(nil)
+	require.Equal(t, 0, r.Failures())
+	require.False(t, r.LastContact().IsZero())
+}
+
 func TestRemoteReport(t *testing.T) {
 	id := uint64(1)
 	err := fmt.Errorf("TestRemoteReport error")
@@ -201,25 +228,180 @@ func TestRemoteSend(t *testing.T) {
 	require.Contains(t, err.Error(), "buffer is full")
 }
 
+func TestRemoteSendControl(t *testing.T) {
+	r := new(remote)
+	r.ctx = context.Background()
+	r.msgc = make(chan etcdraftpb.Message, 1)
+	r.ctrlc = make(chan etcdraftpb.Message, 1)
+	r.raw.Store(raftpb.Member{})
+
+	// it queue the control messages to the dedicated control buffer.
+	err := r.Send(etcdraftpb.Message{Type: etcdraftpb.MsgHeartbeat})
+	require.NoError(t, err)
+	require.Len(t, r.ctrlc, 1)
+	require.Len(t, r.msgc, 0)
+
+	// it queue the other messages to the pipeline buffer.
+	err = r.Send(etcdraftpb.Message{Type: etcdraftpb.MsgApp})
+	require.NoError(t, err)
+	require.Len(t, r.ctrlc, 1)
+	require.Len(t, r.msgc, 1)
+}
+
+func TestRemoteSendQueuePolicy(t *testing.T) {
+	msg := etcdraftpb.Message{Type: etcdraftpb.MsgApp, Entries: []etcdraftpb.Entry{{Data: make([]byte, 100)}}}
+	size := msg.Size()
+
+	table := []struct {
+		name     string
+		qp       QueuePolicy
+		stats    QueueStats
+		rejected bool
+	}{
+		{
+			name:     "it reject new message when queue is full",
+			qp:       QueuePolicy{},
+			stats:    QueueStats{Messages: 2, Bytes: 2 * size, Rejected: 1},
+			rejected: true,
+		},
+		{
+			name:     "it reject new message when queue bytes limit exceeded",
+			qp:       QueuePolicy{MaxBytes: size},
+			stats:    QueueStats{Messages: 1, Bytes: size, Rejected: 2},
+			rejected: true,
+		},
+		{
+			name:  "it drop oldest message when queue is full",
+			qp:    QueuePolicy{DropOldest: true},
+			stats: QueueStats{Messages: 2, Bytes: 2 * size, Dropped: 1},
+		},
+		{
+			name:  "it drop oldest messages when queue bytes limit exceeded",
+			qp:    QueuePolicy{MaxBytes: size, DropOldest: true},
+			stats: QueueStats{Messages: 1, Bytes: size, Dropped: 2},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			rep := NewMockReporter(ctrl)
+			rep.EXPECT().ReportUnreachable(gomock.Any()).Times(int(tt.stats.Rejected + tt.stats.Dropped))
+
+			r := new(remote)
+			r.ctx = context.Background()
+			r.r = rep
+			r.qp = tt.qp
+			r.logger = raftlog.DefaultLogger
+			r.msgc = make(chan etcdraftpb.Message, 2)
+			r.raw.Store(raftpb.Member{})
+
+			var rejected bool
+			for i := 0; i < 3; i++ {
+				if err := r.Send(msg); err != nil {
+					rejected = true
+				}
+			}
+
+			require.Equal(t, tt.rejected, rejected)
+			require.Equal(t, tt.stats, r.QueueStats())
+		})
+	}
+}
+
+func TestRemoteRelay(t *testing.T) {
+	err := fmt.Errorf("TestRemoteRelay error")
+	msg := etcdraftpb.Message{From: 1, To: 3, Type: etcdraftpb.MsgApp}
+
+	table := []struct {
+		name    string
+		msg     etcdraftpb.Message
+		disable bool
+		down    bool
+		relayed bool
+	}{
+		{
+			name:    "it relay the local messages through an active member",
+			msg:     msg,
+			relayed: true,
+		},
+		{
+			name:    "it does not relay when relay disabled",
+			msg:     msg,
+			disable: true,
+		},
+		{
+			name: "it does not relay snapshot messages",
+			msg:  etcdraftpb.Message{From: 1, To: 3, Type: etcdraftpb.MsgSnap},
+		},
+		{
+			name: "it does not relay relayed messages",
+			msg:  etcdraftpb.Message{From: 2, To: 3, Type: etcdraftpb.MsgApp},
+		},
+		{
+			name: "it does not relay when no active member",
+			msg:  msg,
+			down: true,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			p := New(cfg).(*pool)
+			cfg.EXPECT().Pool().Return(p).AnyTimes()
+
+			l := new(local)
+			l.raw.Store(raftpb.Member{ID: 1, Type: raftpb.VoterMember})
+
+			peer := new(remote)
+			peer.ctx = context.Background()
+			peer.active = !tt.down
+			peer.msgc = make(chan etcdraftpb.Message, 1)
+			peer.ctrlc = make(chan etcdraftpb.Message, 1)
+			peer.raw.Store(raftpb.Member{ID: 2})
+
+			r := new(remote)
+			r.cfg = cfg
+			r.allowRelay = !tt.disable
+			r.logger = raftlog.DefaultLogger
+			r.raw.Store(raftpb.Member{ID: 3})
+
+			p.membs = map[uint64]Member{1: l, 2: peer, 3: r}
+			p.publish()
+
+			got := r.relay([]etcdraftpb.Message{tt.msg}, err)
+			require.Equal(t, tt.relayed, got)
+			if tt.relayed {
+				require.Equal(t, tt.msg, <-peer.msgc)
+			}
+		})
+	}
+}
+
 func TestRemoteProcess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	rep := NewMockReporter(ctrl)
 	client := transportmock.NewMockClient(ctrl)
 
 	rep.EXPECT().ReportUnreachable(gomock.Any())
+	rep.EXPECT().ReportMemberStatus(gomock.Any(), gomock.Eq(false), gomock.Any())
 	client.EXPECT().Message(gomock.Any(), gomock.Any()).Return(fmt.Errorf("TestRemoteRun Message error"))
-	client.EXPECT().Close().Return(nil)
+	client.EXPECT().Close().Return(nil).Times(2)
 
 	r := new(remote)
 	r.r = rep
 	r.raw.Store(raftpb.Member{})
 	r.cfg = testConfig(t)
 	r.rc = client
+	r.crc = client
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 	r.active = true
 	r.msgc = make(chan etcdraftpb.Message, 1)
+	r.ctrlc = make(chan etcdraftpb.Message, 1)
 	r.logger = raftlog.DefaultLogger
-	go r.process(r.ctx)
+	r.metrics = nopMetrics{}
+	go r.process(r.ctx, r.msgc, r.client)
 
 	_ = r.Send(etcdraftpb.Message{})
 
@@ -244,3 +426,210 @@ func TestRemoteProcess(t *testing.T) {
 	r.Close()
 	require.False(t, r.active)
 }
+
+func TestRemoteBatch(t *testing.T) {
+	app := etcdraftpb.Message{Type: etcdraftpb.MsgApp}
+	hb := etcdraftpb.Message{Type: etcdraftpb.MsgHeartbeat}
+	snap := etcdraftpb.Message{Type: etcdraftpb.MsgSnap}
+
+	table := []struct {
+		name    string
+		size    int
+		window  time.Duration
+		msg     etcdraftpb.Message
+		queued  []etcdraftpb.Message
+		batch   []etcdraftpb.Message
+		next    *etcdraftpb.Message
+		pending int
+	}{
+		{
+			name:    "it does not batch when batching disabled",
+			size:    0,
+			msg:     app,
+			queued:  []etcdraftpb.Message{hb},
+			batch:   []etcdraftpb.Message{app},
+			pending: 1,
+		},
+		{
+			name:    "it does not batch snapshot message",
+			size:    10,
+			msg:     snap,
+			queued:  []etcdraftpb.Message{hb},
+			batch:   []etcdraftpb.Message{snap},
+			pending: 1,
+		},
+		{
+			name:    "it batch up to the batch size",
+			size:    2,
+			msg:     app,
+			queued:  []etcdraftpb.Message{hb, app},
+			batch:   []etcdraftpb.Message{app, hb},
+			pending: 1,
+		},
+		{
+			name:   "it batch the queued messages",
+			size:   10,
+			msg:    app,
+			queued: []etcdraftpb.Message{hb, app},
+			batch:  []etcdraftpb.Message{app, hb, app},
+		},
+		{
+			name:   "it batch the queued messages within the window",
+			size:   10,
+			window: time.Millisecond,
+			msg:    app,
+			queued: []etcdraftpb.Message{hb},
+			batch:  []etcdraftpb.Message{app, hb},
+		},
+		{
+			name:    "it return the snapshot message that interrupt the batch",
+			size:    10,
+			msg:     app,
+			queued:  []etcdraftpb.Message{hb, snap, app},
+			batch:   []etcdraftpb.Message{app, hb},
+			next:    &snap,
+			pending: 1,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			cfg := NewMockConfig(ctrl)
+			cfg.EXPECT().BatchSize().Return(tt.size)
+			cfg.EXPECT().BatchWindow().Return(tt.window).AnyTimes()
+
+			r := new(remote)
+			r.cfg = cfg
+			r.msgc = make(chan etcdraftpb.Message, len(tt.queued))
+			for _, m := range tt.queued {
+				r.msgc <- m
+			}
+
+			batch, next := r.batch(r.msgc, tt.msg)
+			require.Equal(t, tt.batch, batch)
+			require.Equal(t, tt.next, next)
+			require.Equal(t, tt.pending, len(r.msgc))
+		})
+	}
+}
+
+func TestRemoteSendBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := transportmock.NewMockBatchClient(ctrl)
+	msgs := []etcdraftpb.Message{{Type: etcdraftpb.MsgApp}, {Type: etcdraftpb.MsgHeartbeat}}
+
+	// it send the messages in a single rpc.
+	client.EXPECT().Messages(gomock.Any(), gomock.Eq(msgs)).Return(nil)
+
+	rep := NewMockReporter(ctrl)
+	rep.EXPECT().ReportMemberStatus(gomock.Any(), gomock.Eq(true), gomock.Any())
+
+	// it report the sent messages metrics.
+	metrics := transportmock.NewMockMetrics(ctrl)
+	metrics.EXPECT().ObserveLatency(gomock.Eq(uint64(1)), gomock.Any(), gomock.Eq(false))
+	metrics.EXPECT().AddMessagesSent(gomock.Eq(uint64(1)), gomock.Eq(len(msgs)))
+	metrics.EXPECT().AddBytesSent(gomock.Eq(uint64(1)), gomock.Eq(msgs[0].Size()+msgs[1].Size()))
+
+	r := new(remote)
+	r.raw.Store(raftpb.Member{ID: 1})
+	r.r = rep
+	r.cfg = testConfig(t)
+	r.logger = raftlog.DefaultLogger
+	r.metrics = metrics
+
+	err := r.send(context.Background(), client, msgs, nil)
+	require.NoError(t, err)
+	require.True(t, r.IsActive())
+	require.NotZero(t, r.LastContact())
+}
+
+func TestRemoteSendRetry(t *testing.T) {
+	err := fmt.Errorf("TestRemoteSendRetry error")
+	msg := etcdraftpb.Message{Type: etcdraftpb.MsgApp}
+
+	table := []struct {
+		name   string
+		msg    etcdraftpb.Message
+		expect func(*transportmock.MockClient, *MockReporter)
+		err    error
+	}{
+		{
+			name: "it retry until the message sent",
+			msg:  msg,
+			expect: func(c *transportmock.MockClient, rep *MockReporter) {
+				gomock.InOrder(
+					c.EXPECT().Message(gomock.Any(), gomock.Eq(msg)).Return(err).Times(2),
+					c.EXPECT().Message(gomock.Any(), gomock.Eq(msg)).Return(nil),
+				)
+				rep.EXPECT().ReportMemberStatus(gomock.Any(), gomock.Eq(true), gomock.Any())
+			},
+		},
+		{
+			name: "it report member unreachable when retries exhausted",
+			msg:  msg,
+			err:  err,
+			expect: func(c *transportmock.MockClient, rep *MockReporter) {
+				c.EXPECT().Message(gomock.Any(), gomock.Eq(msg)).Return(err).Times(4)
+				rep.EXPECT().ReportUnreachable(gomock.Any())
+				rep.EXPECT().ReportMemberStatus(gomock.Any(), gomock.Eq(false), gomock.Any())
+			},
+		},
+		{
+			name: "it does not retry snapshot messages",
+			msg:  etcdraftpb.Message{Type: etcdraftpb.MsgSnap},
+			err:  err,
+			expect: func(c *transportmock.MockClient, rep *MockReporter) {
+				c.EXPECT().Message(gomock.Any(), gomock.Any()).Return(err)
+				rep.EXPECT().ReportSnapshot(gomock.Any(), gomock.Eq(raft.SnapshotFailure))
+				rep.EXPECT().ReportSnapshotTransfer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq(err))
+				rep.EXPECT().ReportMemberStatus(gomock.Any(), gomock.Eq(false), gomock.Any())
+			},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := transportmock.NewMockClient(ctrl)
+			rep := NewMockReporter(ctrl)
+			tt.expect(client, rep)
+
+			cfg := NewMockConfig(ctrl)
+			cfg.EXPECT().StreamTimeout().Return(time.Second).AnyTimes()
+			cfg.EXPECT().RetryPolicy().Return(RetryPolicy{
+				MaxRetries:     3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     2 * time.Millisecond,
+			})
+
+			r := new(remote)
+			r.raw.Store(raftpb.Member{})
+			r.r = rep
+			r.cfg = cfg
+			r.logger = raftlog.DefaultLogger
+			r.metrics = nopMetrics{}
+			r.active = tt.err != nil
+
+			got := r.send(context.Background(), client, []etcdraftpb.Message{tt.msg}, nil)
+			require.Equal(t, tt.err, got)
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}
+
+	require.Equal(t, time.Millisecond, p.Backoff(0))
+	require.Equal(t, 2*time.Millisecond, p.Backoff(1))
+	require.Equal(t, 4*time.Millisecond, p.Backoff(2))
+	require.Equal(t, 5*time.Millisecond, p.Backoff(3))
+	require.Equal(t, 5*time.Millisecond, p.Backoff(100))
+
+	// it does not limit the backoff when max backoff unset.
+	p.MaxBackoff = 0
+	require.Equal(t, 8*time.Millisecond, p.Backoff(3))
+}
@@ -42,6 +42,18 @@ func (r removed) Update(raftpb.Member) error {
 	return errRemovedMember
 }
 
+func (r removed) Labels() map[string]string {
+	return r.raw.Labels
+}
+
+func (r removed) Draining() bool {
+	return r.raw.Draining
+}
+
+func (r removed) Version() uint32 {
+	return r.raw.Version
+}
+
 func (r removed) Raw() raftpb.Member {
 	return r.raw
 }
@@ -50,3 +62,6 @@ func (r removed) Close() (err error)                       { return }
 func (r removed) TearDown(ctx context.Context) (err error) { return }
 func (r removed) ActiveSince() (t time.Time)               { return }
 func (r removed) IsActive() (ok bool)                      { return }
+func (r removed) LastContact() (t time.Time)               { return }
+func (r removed) Failures() (n int)                        { return }
+func (r removed) QueueStats() (s QueueStats)               { return }
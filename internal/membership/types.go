@@ -21,9 +21,15 @@ type Member interface {
 	Address() string
 	ActiveSince() time.Time
 	IsActive() bool
+	LastContact() time.Time
+	Failures() int
+	QueueStats() QueueStats
 	Update(m raftpb.Member) error
 	Send(etcdraftpb.Message) error
 	Type() raftpb.MemberType
+	Labels() map[string]string
+	Draining() bool
+	Version() uint32
 	Raw() raftpb.Member
 	Close() error
 	TearDown(ctx context.Context) error
@@ -34,6 +40,65 @@ type Reporter interface {
 	ReportUnreachable(id uint64)
 	ReportShutdown(id uint64)
 	ReportSnapshot(id uint64, status raft.SnapshotStatus)
+	ReportSnapshotTransfer(id uint64, meta etcdraftpb.SnapshotMetadata, d time.Duration, err error)
+	// ReportMemberStatus reports the member transition to reachable (active) or unreachable,
+	// d is the duration the member has been unreachable.
+	ReportMemberStatus(m raftpb.Member, active bool, d time.Duration)
+}
+
+// RetryPolicy define the member clients dial timeout, and the retry policy
+// of the messages failed to be sent to the member.
+type RetryPolicy struct {
+	// DialTimeout is the max duration to dial a member, zero means no timeout.
+	DialTimeout time.Duration
+	// MaxRetries is the max number of retries to send a message before reporting the member unreachable,
+	// zero means no retries. The snapshot messages never retried.
+	MaxRetries int
+	// InitialBackoff is the duration to wait before the first retry,
+	// doubled for each subsequent retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff is the max duration to wait between retries, zero means no limit.
+	MaxBackoff time.Duration
+}
+
+// Backoff returns the duration to wait before the given retry attempt, starting from zero.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 0; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	return d
+}
+
+// QueuePolicy define the bounds of the member outbound messages queue,
+// and the policy applied to the new messages when the queue is full.
+// The control messages (e.g. heartbeats) are queued separately and never dropped to make room.
+type QueuePolicy struct {
+	// MaxMessages is the max number of queued messages, zero means the default queue size.
+	MaxMessages int
+	// MaxBytes is the max size in bytes of the queued messages, zero means no limit.
+	// A message larger than MaxBytes is queued only when the queue is empty.
+	MaxBytes int
+	// DropOldest drops the oldest queued messages to make room for the new messages,
+	// instead of rejecting the new messages, when the queue is full.
+	DropOldest bool
+}
+
+// QueueStats represents the member outbound messages queue statistics.
+type QueueStats struct {
+	// Messages is the number of queued messages.
+	Messages int
+	// Bytes is the size in bytes of the queued messages.
+	Bytes int
+	// Dropped is the total number of queued messages dropped to make room for the new messages.
+	Dropped uint64
+	// Rejected is the total number of messages rejected when the queue is full.
+	Rejected uint64
 }
 
 // Config define common configuration used by the pool.
@@ -45,6 +110,13 @@ type Config interface {
 	Logger() raftlog.Logger
 	Dial() transport.Dial
 	AllowPipelining() bool
+	BatchSize() int
+	BatchWindow() time.Duration
+	RetryPolicy() RetryPolicy
+	QueuePolicy() QueuePolicy
+	TransportMetrics() transport.Metrics
+	AllowRelay() bool
+	Pool() Pool
 }
 
 // Pool represents a set of raft Members.
@@ -83,6 +83,34 @@ func (mr *MockMemberMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockMember)(nil).Close))
 }
 
+// Draining mocks base method.
+func (m *MockMember) Draining() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Draining")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Draining indicates an expected call of Draining.
+func (mr *MockMemberMockRecorder) Draining() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Draining", reflect.TypeOf((*MockMember)(nil).Draining))
+}
+
+// Failures mocks base method.
+func (m *MockMember) Failures() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Failures")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Failures indicates an expected call of Failures.
+func (mr *MockMemberMockRecorder) Failures() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failures", reflect.TypeOf((*MockMember)(nil).Failures))
+}
+
 // ID mocks base method.
 func (m *MockMember) ID() uint64 {
 	m.ctrl.T.Helper()
@@ -111,6 +139,48 @@ func (mr *MockMemberMockRecorder) IsActive() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsActive", reflect.TypeOf((*MockMember)(nil).IsActive))
 }
 
+// Labels mocks base method.
+func (m *MockMember) Labels() map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Labels")
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// Labels indicates an expected call of Labels.
+func (mr *MockMemberMockRecorder) Labels() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Labels", reflect.TypeOf((*MockMember)(nil).Labels))
+}
+
+// LastContact mocks base method.
+func (m *MockMember) LastContact() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastContact")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// LastContact indicates an expected call of LastContact.
+func (mr *MockMemberMockRecorder) LastContact() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastContact", reflect.TypeOf((*MockMember)(nil).LastContact))
+}
+
+// QueueStats mocks base method.
+func (m *MockMember) QueueStats() membership.QueueStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueStats")
+	ret0, _ := ret[0].(membership.QueueStats)
+	return ret0
+}
+
+// QueueStats indicates an expected call of QueueStats.
+func (mr *MockMemberMockRecorder) QueueStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueStats", reflect.TypeOf((*MockMember)(nil).QueueStats))
+}
+
 // Raw mocks base method.
 func (m *MockMember) Raw() raftpb.Member {
 	m.ctrl.T.Helper()
@@ -181,6 +251,20 @@ func (mr *MockMemberMockRecorder) Update(m interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMember)(nil).Update), m)
 }
 
+// Version mocks base method.
+func (m *MockMember) Version() uint32 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(uint32)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockMemberMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockMember)(nil).Version))
+}
+
 // MockReporter is a mock of Reporter interface.
 type MockReporter struct {
 	ctrl     *gomock.Controller
@@ -204,6 +288,18 @@ func (m *MockReporter) EXPECT() *MockReporterMockRecorder {
 	return m.recorder
 }
 
+// ReportMemberStatus mocks base method.
+func (m_2 *MockReporter) ReportMemberStatus(m raftpb.Member, active bool, d time.Duration) {
+	m_2.ctrl.T.Helper()
+	m_2.ctrl.Call(m_2, "ReportMemberStatus", m, active, d)
+}
+
+// ReportMemberStatus indicates an expected call of ReportMemberStatus.
+func (mr *MockReporterMockRecorder) ReportMemberStatus(m, active, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportMemberStatus", reflect.TypeOf((*MockReporter)(nil).ReportMemberStatus), m, active, d)
+}
+
 // ReportShutdown mocks base method.
 func (m *MockReporter) ReportShutdown(id uint64) {
 	m.ctrl.T.Helper()
@@ -228,6 +324,18 @@ func (mr *MockReporterMockRecorder) ReportSnapshot(id, status interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSnapshot", reflect.TypeOf((*MockReporter)(nil).ReportSnapshot), id, status)
 }
 
+// ReportSnapshotTransfer mocks base method.
+func (m *MockReporter) ReportSnapshotTransfer(id uint64, meta raftpb0.SnapshotMetadata, d time.Duration, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportSnapshotTransfer", id, meta, d, err)
+}
+
+// ReportSnapshotTransfer indicates an expected call of ReportSnapshotTransfer.
+func (mr *MockReporterMockRecorder) ReportSnapshotTransfer(id, meta, d, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSnapshotTransfer", reflect.TypeOf((*MockReporter)(nil).ReportSnapshotTransfer), id, meta, d, err)
+}
+
 // ReportUnreachable mocks base method.
 func (m *MockReporter) ReportUnreachable(id uint64) {
 	m.ctrl.T.Helper()
@@ -277,6 +385,48 @@ func (mr *MockConfigMockRecorder) AllowPipelining() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowPipelining", reflect.TypeOf((*MockConfig)(nil).AllowPipelining))
 }
 
+// AllowRelay mocks base method.
+func (m *MockConfig) AllowRelay() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowRelay")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// AllowRelay indicates an expected call of AllowRelay.
+func (mr *MockConfigMockRecorder) AllowRelay() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowRelay", reflect.TypeOf((*MockConfig)(nil).AllowRelay))
+}
+
+// BatchSize mocks base method.
+func (m *MockConfig) BatchSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// BatchSize indicates an expected call of BatchSize.
+func (mr *MockConfigMockRecorder) BatchSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchSize", reflect.TypeOf((*MockConfig)(nil).BatchSize))
+}
+
+// BatchWindow mocks base method.
+func (m *MockConfig) BatchWindow() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchWindow")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// BatchWindow indicates an expected call of BatchWindow.
+func (mr *MockConfigMockRecorder) BatchWindow() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchWindow", reflect.TypeOf((*MockConfig)(nil).BatchWindow))
+}
+
 // Context mocks base method.
 func (m *MockConfig) Context() context.Context {
 	m.ctrl.T.Helper()
@@ -333,6 +483,34 @@ func (mr *MockConfigMockRecorder) Logger() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logger", reflect.TypeOf((*MockConfig)(nil).Logger))
 }
 
+// Pool mocks base method.
+func (m *MockConfig) Pool() membership.Pool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pool")
+	ret0, _ := ret[0].(membership.Pool)
+	return ret0
+}
+
+// Pool indicates an expected call of Pool.
+func (mr *MockConfigMockRecorder) Pool() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pool", reflect.TypeOf((*MockConfig)(nil).Pool))
+}
+
+// QueuePolicy mocks base method.
+func (m *MockConfig) QueuePolicy() membership.QueuePolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueuePolicy")
+	ret0, _ := ret[0].(membership.QueuePolicy)
+	return ret0
+}
+
+// QueuePolicy indicates an expected call of QueuePolicy.
+func (mr *MockConfigMockRecorder) QueuePolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueuePolicy", reflect.TypeOf((*MockConfig)(nil).QueuePolicy))
+}
+
 // Reporter mocks base method.
 func (m *MockConfig) Reporter() membership.Reporter {
 	m.ctrl.T.Helper()
@@ -347,6 +525,20 @@ func (mr *MockConfigMockRecorder) Reporter() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reporter", reflect.TypeOf((*MockConfig)(nil).Reporter))
 }
 
+// RetryPolicy mocks base method.
+func (m *MockConfig) RetryPolicy() membership.RetryPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryPolicy")
+	ret0, _ := ret[0].(membership.RetryPolicy)
+	return ret0
+}
+
+// RetryPolicy indicates an expected call of RetryPolicy.
+func (mr *MockConfigMockRecorder) RetryPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryPolicy", reflect.TypeOf((*MockConfig)(nil).RetryPolicy))
+}
+
 // StreamTimeout mocks base method.
 func (m *MockConfig) StreamTimeout() time.Duration {
 	m.ctrl.T.Helper()
@@ -361,6 +553,20 @@ func (mr *MockConfigMockRecorder) StreamTimeout() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTimeout", reflect.TypeOf((*MockConfig)(nil).StreamTimeout))
 }
 
+// TransportMetrics mocks base method.
+func (m *MockConfig) TransportMetrics() transport.Metrics {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransportMetrics")
+	ret0, _ := ret[0].(transport.Metrics)
+	return ret0
+}
+
+// TransportMetrics indicates an expected call of TransportMetrics.
+func (mr *MockConfigMockRecorder) TransportMetrics() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransportMetrics", reflect.TypeOf((*MockConfig)(nil).TransportMetrics))
+}
+
 // MockPool is a mock of Pool interface.
 type MockPool struct {
 	ctrl     *gomock.Controller
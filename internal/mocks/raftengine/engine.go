@@ -7,6 +7,7 @@ package raftenginemock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	raftengine "github.com/shaj13/raft/internal/raftengine"
@@ -38,6 +39,62 @@ func (m *MockEngine) EXPECT() *MockEngineMockRecorder {
 	return m.recorder
 }
 
+// Alarms mocks base method.
+func (m *MockEngine) Alarms() []raftpb.Alarm {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Alarms")
+	ret0, _ := ret[0].([]raftpb.Alarm)
+	return ret0
+}
+
+// Alarms indicates an expected call of Alarms.
+func (mr *MockEngineMockRecorder) Alarms() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Alarms", reflect.TypeOf((*MockEngine)(nil).Alarms))
+}
+
+// AuditLog mocks base method.
+func (m *MockEngine) AuditLog(sinceIndex uint64) []raftpb.AuditRecord {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", sinceIndex)
+	ret0, _ := ret[0].([]raftpb.AuditRecord)
+	return ret0
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockEngineMockRecorder) AuditLog(sinceIndex interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockEngine)(nil).AuditLog), sinceIndex)
+}
+
+// ClusterID mocks base method.
+func (m *MockEngine) ClusterID() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClusterID")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// ClusterID indicates an expected call of ClusterID.
+func (mr *MockEngineMockRecorder) ClusterID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterID", reflect.TypeOf((*MockEngine)(nil).ClusterID))
+}
+
+// ClusterVersion mocks base method.
+func (m *MockEngine) ClusterVersion() uint32 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClusterVersion")
+	ret0, _ := ret[0].(uint32)
+	return ret0
+}
+
+// ClusterVersion indicates an expected call of ClusterVersion.
+func (mr *MockEngineMockRecorder) ClusterVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterVersion", reflect.TypeOf((*MockEngine)(nil).ClusterVersion))
+}
+
 // CreateSnapshot mocks base method.
 func (m *MockEngine) CreateSnapshot() (raftpb0.Snapshot, error) {
 	m.ctrl.T.Helper()
@@ -53,6 +110,20 @@ func (mr *MockEngineMockRecorder) CreateSnapshot() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSnapshot", reflect.TypeOf((*MockEngine)(nil).CreateSnapshot))
 }
 
+// LeaderChanges mocks base method.
+func (m *MockEngine) LeaderChanges() <-chan raftengine.LeaderInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaderChanges")
+	ret0, _ := ret[0].(<-chan raftengine.LeaderInfo)
+	return ret0
+}
+
+// LeaderChanges indicates an expected call of LeaderChanges.
+func (mr *MockEngineMockRecorder) LeaderChanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaderChanges", reflect.TypeOf((*MockEngine)(nil).LeaderChanges))
+}
+
 // LinearizableRead mocks base method.
 func (m *MockEngine) LinearizableRead(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -67,6 +138,20 @@ func (mr *MockEngineMockRecorder) LinearizableRead(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinearizableRead", reflect.TypeOf((*MockEngine)(nil).LinearizableRead), ctx)
 }
 
+// ProposeAlarm mocks base method.
+func (m *MockEngine) ProposeAlarm(ctx context.Context, ac raftpb.AlarmChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeAlarm", ctx, ac)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProposeAlarm indicates an expected call of ProposeAlarm.
+func (mr *MockEngineMockRecorder) ProposeAlarm(ctx, ac interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeAlarm", reflect.TypeOf((*MockEngine)(nil).ProposeAlarm), ctx, ac)
+}
+
 // ProposeConfChange mocks base method.
 func (m_2 *MockEngine) ProposeConfChange(ctx context.Context, m *raftpb.Member, t raftpb0.ConfChangeType) error {
 	m_2.ctrl.T.Helper()
@@ -109,6 +194,60 @@ func (mr *MockEngineMockRecorder) Push(m interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockEngine)(nil).Push), m)
 }
 
+// ReadStateMachine mocks base method.
+func (m *MockEngine) ReadStateMachine(fn func(raftengine.StateMachine) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadStateMachine", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReadStateMachine indicates an expected call of ReadStateMachine.
+func (mr *MockEngineMockRecorder) ReadStateMachine(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadStateMachine", reflect.TypeOf((*MockEngine)(nil).ReadStateMachine), fn)
+}
+
+// Ready mocks base method.
+func (m *MockEngine) Ready() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockEngineMockRecorder) Ready() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockEngine)(nil).Ready))
+}
+
+// RecentEvents mocks base method.
+func (m *MockEngine) RecentEvents() []raftengine.Event {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecentEvents")
+	ret0, _ := ret[0].([]raftengine.Event)
+	return ret0
+}
+
+// RecentEvents indicates an expected call of RecentEvents.
+func (mr *MockEngineMockRecorder) RecentEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecentEvents", reflect.TypeOf((*MockEngine)(nil).RecentEvents))
+}
+
+// ReportMemberStatus mocks base method.
+func (m_2 *MockEngine) ReportMemberStatus(m raftpb.Member, active bool, d time.Duration) {
+	m_2.ctrl.T.Helper()
+	m_2.ctrl.Call(m_2, "ReportMemberStatus", m, active, d)
+}
+
+// ReportMemberStatus indicates an expected call of ReportMemberStatus.
+func (mr *MockEngineMockRecorder) ReportMemberStatus(m, active, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportMemberStatus", reflect.TypeOf((*MockEngine)(nil).ReportMemberStatus), m, active, d)
+}
+
 // ReportShutdown mocks base method.
 func (m *MockEngine) ReportShutdown(id uint64) {
 	m.ctrl.T.Helper()
@@ -133,6 +272,18 @@ func (mr *MockEngineMockRecorder) ReportSnapshot(id, status interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSnapshot", reflect.TypeOf((*MockEngine)(nil).ReportSnapshot), id, status)
 }
 
+// ReportSnapshotTransfer mocks base method.
+func (m *MockEngine) ReportSnapshotTransfer(id uint64, meta raftpb0.SnapshotMetadata, d time.Duration, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReportSnapshotTransfer", id, meta, d, err)
+}
+
+// ReportSnapshotTransfer indicates an expected call of ReportSnapshotTransfer.
+func (mr *MockEngineMockRecorder) ReportSnapshotTransfer(id, meta, d, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSnapshotTransfer", reflect.TypeOf((*MockEngine)(nil).ReportSnapshotTransfer), id, meta, d, err)
+}
+
 // ReportUnreachable mocks base method.
 func (m *MockEngine) ReportUnreachable(id uint64) {
 	m.ctrl.T.Helper()
@@ -159,6 +310,20 @@ func (mr *MockEngineMockRecorder) Shutdown(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockEngine)(nil).Shutdown), arg0)
 }
 
+// SlowOps mocks base method.
+func (m *MockEngine) SlowOps() raftengine.SlowOps {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlowOps")
+	ret0, _ := ret[0].(raftengine.SlowOps)
+	return ret0
+}
+
+// SlowOps indicates an expected call of SlowOps.
+func (mr *MockEngineMockRecorder) SlowOps() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlowOps", reflect.TypeOf((*MockEngine)(nil).SlowOps))
+}
+
 // Start mocks base method.
 func (m *MockEngine) Start(addr string, oprs ...raftengine.Operator) error {
 	m.ctrl.T.Helper()
@@ -193,6 +358,20 @@ func (mr *MockEngineMockRecorder) Status() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockEngine)(nil).Status))
 }
 
+// Supports mocks base method.
+func (m *MockEngine) Supports(arg0 raftengine.Feature) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Supports", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Supports indicates an expected call of Supports.
+func (mr *MockEngineMockRecorder) Supports(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Supports", reflect.TypeOf((*MockEngine)(nil).Supports), arg0)
+}
+
 // TransferLeadership mocks base method.
 func (m *MockEngine) TransferLeadership(arg0 context.Context, arg1 uint64) error {
 	m.ctrl.T.Helper()
@@ -206,3 +385,32 @@ func (mr *MockEngineMockRecorder) TransferLeadership(arg0, arg1 interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferLeadership", reflect.TypeOf((*MockEngine)(nil).TransferLeadership), arg0, arg1)
 }
+
+// Waiters mocks base method.
+func (m *MockEngine) Waiters() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Waiters")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Waiters indicates an expected call of Waiters.
+func (mr *MockEngineMockRecorder) Waiters() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Waiters", reflect.TypeOf((*MockEngine)(nil).Waiters))
+}
+
+// Watch mocks base method.
+func (m *MockEngine) Watch(ctx context.Context, fromIndex uint64) (<-chan raftengine.WatchEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, fromIndex)
+	ret0, _ := ret[0].(<-chan raftengine.WatchEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockEngineMockRecorder) Watch(ctx, fromIndex interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockEngine)(nil).Watch), ctx, fromIndex)
+}
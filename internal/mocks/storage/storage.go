@@ -5,14 +5,83 @@
 package storagemock
 
 import (
+	context "context"
 	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	raftpb "github.com/shaj13/raft/internal/raftpb"
 	storage "github.com/shaj13/raft/internal/storage"
-	raftpb "go.etcd.io/etcd/raft/v3/raftpb"
+	raftpb0 "go.etcd.io/etcd/raft/v3/raftpb"
 )
 
+// MockSnapshotWriter is a mock of SnapshotWriter interface.
+type MockSnapshotWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotWriterMockRecorder
+}
+
+// MockSnapshotWriterMockRecorder is the mock recorder for MockSnapshotWriter.
+type MockSnapshotWriterMockRecorder struct {
+	mock *MockSnapshotWriter
+}
+
+// NewMockSnapshotWriter creates a new mock instance.
+func NewMockSnapshotWriter(ctrl *gomock.Controller) *MockSnapshotWriter {
+	mock := &MockSnapshotWriter{ctrl: ctrl}
+	mock.recorder = &MockSnapshotWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotWriter) EXPECT() *MockSnapshotWriterMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockSnapshotWriter) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockSnapshotWriterMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSnapshotWriter)(nil).Close))
+}
+
+// Commit mocks base method.
+func (m *MockSnapshotWriter) Commit() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockSnapshotWriterMockRecorder) Commit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockSnapshotWriter)(nil).Commit))
+}
+
+// Write mocks base method.
+func (m *MockSnapshotWriter) Write(p []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockSnapshotWriterMockRecorder) Write(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSnapshotWriter)(nil).Write), p)
+}
+
 // MockSnapshotter is a mock of Snapshotter interface.
 type MockSnapshotter struct {
 	ctrl     *gomock.Controller
@@ -36,49 +105,122 @@ func (m *MockSnapshotter) EXPECT() *MockSnapshotterMockRecorder {
 	return m.recorder
 }
 
+// Chain mocks base method.
+func (m *MockSnapshotter) Chain(term, index uint64) ([]raftpb0.SnapshotMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Chain", term, index)
+	ret0, _ := ret[0].([]raftpb0.SnapshotMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Chain indicates an expected call of Chain.
+func (mr *MockSnapshotterMockRecorder) Chain(term, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Chain", reflect.TypeOf((*MockSnapshotter)(nil).Chain), term, index)
+}
+
+// Fetch mocks base method.
+func (m *MockSnapshotter) Fetch(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockSnapshotterMockRecorder) Fetch(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockSnapshotter)(nil).Fetch), ctx, key)
+}
+
+// Offset mocks base method.
+func (m *MockSnapshotter) Offset(arg0, arg1 uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Offset", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Offset indicates an expected call of Offset.
+func (mr *MockSnapshotterMockRecorder) Offset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Offset", reflect.TypeOf((*MockSnapshotter)(nil).Offset), arg0, arg1)
+}
+
+// Publish mocks base method.
+func (m *MockSnapshotter) Publish(ctx context.Context, term, index uint64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, term, index)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockSnapshotterMockRecorder) Publish(ctx, term, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockSnapshotter)(nil).Publish), ctx, term, index)
+}
+
 // Read mocks base method.
-func (m *MockSnapshotter) Read(arg0, arg1 uint64) (*storage.Snapshot, error) {
+func (m *MockSnapshotter) Read(arg0 context.Context, arg1, arg2 uint64) (*storage.Snapshot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Read", arg0, arg1)
+	ret := m.ctrl.Call(m, "Read", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*storage.Snapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Read indicates an expected call of Read.
-func (mr *MockSnapshotterMockRecorder) Read(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockSnapshotterMockRecorder) Read(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockSnapshotter)(nil).Read), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockSnapshotter)(nil).Read), arg0, arg1, arg2)
 }
 
 // ReadFrom mocks base method.
-func (m *MockSnapshotter) ReadFrom(arg0 string) (*storage.Snapshot, error) {
+func (m *MockSnapshotter) ReadFrom(arg0 context.Context, arg1 string) (*storage.Snapshot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadFrom", arg0)
+	ret := m.ctrl.Call(m, "ReadFrom", arg0, arg1)
 	ret0, _ := ret[0].(*storage.Snapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ReadFrom indicates an expected call of ReadFrom.
-func (mr *MockSnapshotterMockRecorder) ReadFrom(arg0 interface{}) *gomock.Call {
+func (mr *MockSnapshotterMockRecorder) ReadFrom(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFrom", reflect.TypeOf((*MockSnapshotter)(nil).ReadFrom), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFrom", reflect.TypeOf((*MockSnapshotter)(nil).ReadFrom), arg0, arg1)
 }
 
 // Reader mocks base method.
-func (m *MockSnapshotter) Reader(arg0, arg1 uint64) (io.ReadCloser, error) {
+func (m *MockSnapshotter) Reader(arg0 context.Context, arg1, arg2 uint64, arg3 raftpb.Compression) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Reader", arg0, arg1)
+	ret := m.ctrl.Call(m, "Reader", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(io.ReadCloser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Reader indicates an expected call of Reader.
-func (mr *MockSnapshotterMockRecorder) Reader(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockSnapshotterMockRecorder) Reader(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reader", reflect.TypeOf((*MockSnapshotter)(nil).Reader), arg0, arg1, arg2, arg3)
+}
+
+// Verify mocks base method.
+func (m *MockSnapshotter) Verify(ctx context.Context, term, index uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, term, index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockSnapshotterMockRecorder) Verify(ctx, term, index interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reader", reflect.TypeOf((*MockSnapshotter)(nil).Reader), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockSnapshotter)(nil).Verify), ctx, term, index)
 }
 
 // Write mocks base method.
@@ -96,18 +238,18 @@ func (mr *MockSnapshotterMockRecorder) Write(arg0 interface{}) *gomock.Call {
 }
 
 // Writer mocks base method.
-func (m *MockSnapshotter) Writer(arg0, arg1 uint64) (io.WriteCloser, error) {
+func (m *MockSnapshotter) Writer(term, index, offset uint64) (storage.SnapshotWriter, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Writer", arg0, arg1)
-	ret0, _ := ret[0].(io.WriteCloser)
+	ret := m.ctrl.Call(m, "Writer", term, index, offset)
+	ret0, _ := ret[0].(storage.SnapshotWriter)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Writer indicates an expected call of Writer.
-func (mr *MockSnapshotterMockRecorder) Writer(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockSnapshotterMockRecorder) Writer(term, index, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Writer", reflect.TypeOf((*MockSnapshotter)(nil).Writer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Writer", reflect.TypeOf((*MockSnapshotter)(nil).Writer), term, index, offset)
 }
 
 // MockStorage is a mock of Storage interface.
@@ -133,22 +275,37 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// Available mocks base method.
+func (m *MockStorage) Available() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Available")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Available indicates an expected call of Available.
+func (mr *MockStorageMockRecorder) Available() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Available", reflect.TypeOf((*MockStorage)(nil).Available))
+}
+
 // Boot mocks base method.
-func (m *MockStorage) Boot(arg0 []byte) ([]byte, raftpb.HardState, []raftpb.Entry, *storage.Snapshot, error) {
+func (m *MockStorage) Boot(arg0 context.Context, arg1 []byte) ([]byte, raftpb0.HardState, []raftpb0.Entry, *storage.Snapshot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Boot", arg0)
+	ret := m.ctrl.Call(m, "Boot", arg0, arg1)
 	ret0, _ := ret[0].([]byte)
-	ret1, _ := ret[1].(raftpb.HardState)
-	ret2, _ := ret[2].([]raftpb.Entry)
+	ret1, _ := ret[1].(raftpb0.HardState)
+	ret2, _ := ret[2].([]raftpb0.Entry)
 	ret3, _ := ret[3].(*storage.Snapshot)
 	ret4, _ := ret[4].(error)
 	return ret0, ret1, ret2, ret3, ret4
 }
 
 // Boot indicates an expected call of Boot.
-func (mr *MockStorageMockRecorder) Boot(arg0 interface{}) *gomock.Call {
+func (mr *MockStorageMockRecorder) Boot(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Boot", reflect.TypeOf((*MockStorage)(nil).Boot), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Boot", reflect.TypeOf((*MockStorage)(nil).Boot), arg0, arg1)
 }
 
 // Close mocks base method.
@@ -180,7 +337,7 @@ func (mr *MockStorageMockRecorder) Exist() *gomock.Call {
 }
 
 // SaveEntries mocks base method.
-func (m *MockStorage) SaveEntries(arg0 raftpb.HardState, arg1 []raftpb.Entry) error {
+func (m *MockStorage) SaveEntries(arg0 raftpb0.HardState, arg1 []raftpb0.Entry) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveEntries", arg0, arg1)
 	ret0, _ := ret[0].(error)
@@ -194,7 +351,7 @@ func (mr *MockStorageMockRecorder) SaveEntries(arg0, arg1 interface{}) *gomock.C
 }
 
 // SaveSnapshot mocks base method.
-func (m *MockStorage) SaveSnapshot(arg0 raftpb.Snapshot) error {
+func (m *MockStorage) SaveSnapshot(arg0 raftpb0.Snapshot) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveSnapshot", arg0)
 	ret0, _ := ret[0].(error)
@@ -220,3 +377,170 @@ func (mr *MockStorageMockRecorder) Snapshotter() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshotter", reflect.TypeOf((*MockStorage)(nil).Snapshotter))
 }
+
+// MockObjectStore is a mock of ObjectStore interface.
+type MockObjectStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockObjectStoreMockRecorder
+}
+
+// MockObjectStoreMockRecorder is the mock recorder for MockObjectStore.
+type MockObjectStoreMockRecorder struct {
+	mock *MockObjectStore
+}
+
+// NewMockObjectStore creates a new mock instance.
+func NewMockObjectStore(ctrl *gomock.Controller) *MockObjectStore {
+	mock := &MockObjectStore{ctrl: ctrl}
+	mock.recorder = &MockObjectStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockObjectStore) EXPECT() *MockObjectStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockObjectStore) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockObjectStoreMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockObjectStore)(nil).Delete), ctx, key)
+}
+
+// Get mocks base method.
+func (m *MockObjectStore) Get(ctx context.Context, key string, off, n int64) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key, off, n)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockObjectStoreMockRecorder) Get(ctx, key, off, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockObjectStore)(nil).Get), ctx, key, off, n)
+}
+
+// List mocks base method.
+func (m *MockObjectStore) List(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockObjectStoreMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockObjectStore)(nil).List), ctx)
+}
+
+// Put mocks base method.
+func (m *MockObjectStore) Put(ctx context.Context, key string, r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, key, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockObjectStoreMockRecorder) Put(ctx, key, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockObjectStore)(nil).Put), ctx, key, r)
+}
+
+// Size mocks base method.
+func (m *MockObjectStore) Size(ctx context.Context, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Size", ctx, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Size indicates an expected call of Size.
+func (mr *MockObjectStoreMockRecorder) Size(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockObjectStore)(nil).Size), ctx, key)
+}
+
+// MockMetrics is a mock of Metrics interface.
+type MockMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsMockRecorder
+}
+
+// MockMetricsMockRecorder is the mock recorder for MockMetrics.
+type MockMetricsMockRecorder struct {
+	mock *MockMetrics
+}
+
+// NewMockMetrics creates a new mock instance.
+func NewMockMetrics(ctrl *gomock.Controller) *MockMetrics {
+	mock := &MockMetrics{ctrl: ctrl}
+	mock.recorder = &MockMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
+	return m.recorder
+}
+
+// AddBytesWritten mocks base method.
+func (m *MockMetrics) AddBytesWritten(arg0 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddBytesWritten", arg0)
+}
+
+// AddBytesWritten indicates an expected call of AddBytesWritten.
+func (mr *MockMetricsMockRecorder) AddBytesWritten(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBytesWritten", reflect.TypeOf((*MockMetrics)(nil).AddBytesWritten), arg0)
+}
+
+// ObserveCompaction mocks base method.
+func (m *MockMetrics) ObserveCompaction(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveCompaction", arg0)
+}
+
+// ObserveCompaction indicates an expected call of ObserveCompaction.
+func (mr *MockMetricsMockRecorder) ObserveCompaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveCompaction", reflect.TypeOf((*MockMetrics)(nil).ObserveCompaction), arg0)
+}
+
+// ObserveSync mocks base method.
+func (m *MockMetrics) ObserveSync(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveSync", arg0)
+}
+
+// ObserveSync indicates an expected call of ObserveSync.
+func (mr *MockMetricsMockRecorder) ObserveSync(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveSync", reflect.TypeOf((*MockMetrics)(nil).ObserveSync), arg0)
+}
+
+// SetSegments mocks base method.
+func (m *MockMetrics) SetSegments(arg0 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSegments", arg0)
+}
+
+// SetSegments indicates an expected call of SetSegments.
+func (mr *MockMetricsMockRecorder) SetSegments(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegments", reflect.TypeOf((*MockMetrics)(nil).SetSegments), arg0)
+}
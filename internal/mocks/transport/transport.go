@@ -6,8 +6,10 @@ package transportmock
 
 import (
 	context "context"
+	tls "crypto/tls"
 	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	raftpb "github.com/shaj13/raft/internal/raftpb"
@@ -39,6 +41,34 @@ func (m *MockConfig) EXPECT() *MockConfigMockRecorder {
 	return m.recorder
 }
 
+// AuthToken mocks base method.
+func (m *MockConfig) AuthToken() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthToken")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// AuthToken indicates an expected call of AuthToken.
+func (mr *MockConfigMockRecorder) AuthToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthToken", reflect.TypeOf((*MockConfig)(nil).AuthToken))
+}
+
+// CompressionThreshold mocks base method.
+func (m *MockConfig) CompressionThreshold() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompressionThreshold")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// CompressionThreshold indicates an expected call of CompressionThreshold.
+func (mr *MockConfigMockRecorder) CompressionThreshold() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompressionThreshold", reflect.TypeOf((*MockConfig)(nil).CompressionThreshold))
+}
+
 // Controller mocks base method.
 func (m *MockConfig) Controller() transport.Controller {
 	m.ctrl.T.Helper()
@@ -81,6 +111,117 @@ func (mr *MockConfigMockRecorder) Logger() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logger", reflect.TypeOf((*MockConfig)(nil).Logger))
 }
 
+// MessageCompression mocks base method.
+func (m *MockConfig) MessageCompression() raftpb.Compression {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessageCompression")
+	ret0, _ := ret[0].(raftpb.Compression)
+	return ret0
+}
+
+// MessageCompression indicates an expected call of MessageCompression.
+func (mr *MockConfigMockRecorder) MessageCompression() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageCompression", reflect.TypeOf((*MockConfig)(nil).MessageCompression))
+}
+
+// TLSConfig mocks base method.
+func (m *MockConfig) TLSConfig() *tls.Config {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TLSConfig")
+	ret0, _ := ret[0].(*tls.Config)
+	return ret0
+}
+
+// TLSConfig indicates an expected call of TLSConfig.
+func (mr *MockConfigMockRecorder) TLSConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TLSConfig", reflect.TypeOf((*MockConfig)(nil).TLSConfig))
+}
+
+// MockMetrics is a mock of Metrics interface.
+type MockMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsMockRecorder
+}
+
+// MockMetricsMockRecorder is the mock recorder for MockMetrics.
+type MockMetricsMockRecorder struct {
+	mock *MockMetrics
+}
+
+// NewMockMetrics creates a new mock instance.
+func NewMockMetrics(ctrl *gomock.Controller) *MockMetrics {
+	mock := &MockMetrics{ctrl: ctrl}
+	mock.recorder = &MockMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
+	return m.recorder
+}
+
+// AddBytesReceived mocks base method.
+func (m *MockMetrics) AddBytesReceived(id uint64, n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddBytesReceived", id, n)
+}
+
+// AddBytesReceived indicates an expected call of AddBytesReceived.
+func (mr *MockMetricsMockRecorder) AddBytesReceived(id, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBytesReceived", reflect.TypeOf((*MockMetrics)(nil).AddBytesReceived), id, n)
+}
+
+// AddBytesSent mocks base method.
+func (m *MockMetrics) AddBytesSent(id uint64, n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddBytesSent", id, n)
+}
+
+// AddBytesSent indicates an expected call of AddBytesSent.
+func (mr *MockMetricsMockRecorder) AddBytesSent(id, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBytesSent", reflect.TypeOf((*MockMetrics)(nil).AddBytesSent), id, n)
+}
+
+// AddMessagesReceived mocks base method.
+func (m *MockMetrics) AddMessagesReceived(id uint64, n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddMessagesReceived", id, n)
+}
+
+// AddMessagesReceived indicates an expected call of AddMessagesReceived.
+func (mr *MockMetricsMockRecorder) AddMessagesReceived(id, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMessagesReceived", reflect.TypeOf((*MockMetrics)(nil).AddMessagesReceived), id, n)
+}
+
+// AddMessagesSent mocks base method.
+func (m *MockMetrics) AddMessagesSent(id uint64, n int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddMessagesSent", id, n)
+}
+
+// AddMessagesSent indicates an expected call of AddMessagesSent.
+func (mr *MockMetricsMockRecorder) AddMessagesSent(id, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMessagesSent", reflect.TypeOf((*MockMetrics)(nil).AddMessagesSent), id, n)
+}
+
+// ObserveLatency mocks base method.
+func (m *MockMetrics) ObserveLatency(id uint64, d time.Duration, failed bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveLatency", id, d, failed)
+}
+
+// ObserveLatency indicates an expected call of ObserveLatency.
+func (mr *MockMetricsMockRecorder) ObserveLatency(id, d, failed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveLatency", reflect.TypeOf((*MockMetrics)(nil).ObserveLatency), id, d, failed)
+}
+
 // MockHandler is a mock of Handler interface.
 type MockHandler struct {
 	ctrl     *gomock.Controller
@@ -184,6 +325,166 @@ func (mr *MockClientMockRecorder) PromoteMember(ctx, m interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteMember", reflect.TypeOf((*MockClient)(nil).PromoteMember), ctx, m)
 }
 
+// MockBatchClient is a mock of BatchClient interface.
+type MockBatchClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchClientMockRecorder
+}
+
+// MockBatchClientMockRecorder is the mock recorder for MockBatchClient.
+type MockBatchClientMockRecorder struct {
+	mock *MockBatchClient
+}
+
+// NewMockBatchClient creates a new mock instance.
+func NewMockBatchClient(ctrl *gomock.Controller) *MockBatchClient {
+	mock := &MockBatchClient{ctrl: ctrl}
+	mock.recorder = &MockBatchClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchClient) EXPECT() *MockBatchClientMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockBatchClient) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockBatchClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBatchClient)(nil).Close))
+}
+
+// Join mocks base method.
+func (m *MockBatchClient) Join(arg0 context.Context, arg1 raftpb.Member) (*raftpb.JoinResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Join", arg0, arg1)
+	ret0, _ := ret[0].(*raftpb.JoinResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Join indicates an expected call of Join.
+func (mr *MockBatchClientMockRecorder) Join(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Join", reflect.TypeOf((*MockBatchClient)(nil).Join), arg0, arg1)
+}
+
+// Message mocks base method.
+func (m *MockBatchClient) Message(arg0 context.Context, arg1 raftpb0.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Message", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Message indicates an expected call of Message.
+func (mr *MockBatchClientMockRecorder) Message(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Message", reflect.TypeOf((*MockBatchClient)(nil).Message), arg0, arg1)
+}
+
+// Messages mocks base method.
+func (m *MockBatchClient) Messages(arg0 context.Context, arg1 []raftpb0.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Messages", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Messages indicates an expected call of Messages.
+func (mr *MockBatchClientMockRecorder) Messages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Messages", reflect.TypeOf((*MockBatchClient)(nil).Messages), arg0, arg1)
+}
+
+// PromoteMember mocks base method.
+func (m_2 *MockBatchClient) PromoteMember(ctx context.Context, m raftpb.Member) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "PromoteMember", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PromoteMember indicates an expected call of PromoteMember.
+func (mr *MockBatchClientMockRecorder) PromoteMember(ctx, m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteMember", reflect.TypeOf((*MockBatchClient)(nil).PromoteMember), ctx, m)
+}
+
+// MockSnapshotWriter is a mock of SnapshotWriter interface.
+type MockSnapshotWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotWriterMockRecorder
+}
+
+// MockSnapshotWriterMockRecorder is the mock recorder for MockSnapshotWriter.
+type MockSnapshotWriterMockRecorder struct {
+	mock *MockSnapshotWriter
+}
+
+// NewMockSnapshotWriter creates a new mock instance.
+func NewMockSnapshotWriter(ctrl *gomock.Controller) *MockSnapshotWriter {
+	mock := &MockSnapshotWriter{ctrl: ctrl}
+	mock.recorder = &MockSnapshotWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotWriter) EXPECT() *MockSnapshotWriterMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockSnapshotWriter) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockSnapshotWriterMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSnapshotWriter)(nil).Close))
+}
+
+// Commit mocks base method.
+func (m *MockSnapshotWriter) Commit() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockSnapshotWriterMockRecorder) Commit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockSnapshotWriter)(nil).Commit))
+}
+
+// Write mocks base method.
+func (m *MockSnapshotWriter) Write(p []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockSnapshotWriterMockRecorder) Write(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSnapshotWriter)(nil).Write), p)
+}
+
 // MockController is a mock of Controller interface.
 type MockController struct {
 	ctrl     *gomock.Controller
@@ -207,6 +508,48 @@ func (m *MockController) EXPECT() *MockControllerMockRecorder {
 	return m.recorder
 }
 
+// Authenticate mocks base method.
+func (m *MockController) Authenticate(ctx context.Context, gid uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, gid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockControllerMockRecorder) Authenticate(ctx, gid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockController)(nil).Authenticate), ctx, gid)
+}
+
+// ClusterID mocks base method.
+func (m *MockController) ClusterID(gid uint64) uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClusterID", gid)
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// ClusterID indicates an expected call of ClusterID.
+func (mr *MockControllerMockRecorder) ClusterID(gid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterID", reflect.TypeOf((*MockController)(nil).ClusterID), gid)
+}
+
+// Health mocks base method.
+func (m *MockController) Health(gid uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health", gid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockControllerMockRecorder) Health(gid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockController)(nil).Health), gid)
+}
+
 // Join mocks base method.
 func (m *MockController) Join(arg0 context.Context, arg1 uint64, arg2 *raftpb.Member) (*raftpb.JoinResponse, error) {
 	m.ctrl.T.Helper()
@@ -250,32 +593,77 @@ func (mr *MockControllerMockRecorder) Push(arg0, arg1, arg2 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockController)(nil).Push), arg0, arg1, arg2)
 }
 
+// SnapshotChain mocks base method.
+func (m *MockController) SnapshotChain(gid, term, index uint64) ([]raftpb0.SnapshotMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotChain", gid, term, index)
+	ret0, _ := ret[0].([]raftpb0.SnapshotMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotChain indicates an expected call of SnapshotChain.
+func (mr *MockControllerMockRecorder) SnapshotChain(gid, term, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotChain", reflect.TypeOf((*MockController)(nil).SnapshotChain), gid, term, index)
+}
+
+// SnapshotOffset mocks base method.
+func (m *MockController) SnapshotOffset(gid, term, index uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotOffset", gid, term, index)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotOffset indicates an expected call of SnapshotOffset.
+func (mr *MockControllerMockRecorder) SnapshotOffset(gid, term, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotOffset", reflect.TypeOf((*MockController)(nil).SnapshotOffset), gid, term, index)
+}
+
+// SnapshotPublish mocks base method.
+func (m *MockController) SnapshotPublish(ctx context.Context, gid, term, index uint64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotPublish", ctx, gid, term, index)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotPublish indicates an expected call of SnapshotPublish.
+func (mr *MockControllerMockRecorder) SnapshotPublish(ctx, gid, term, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotPublish", reflect.TypeOf((*MockController)(nil).SnapshotPublish), ctx, gid, term, index)
+}
+
 // SnapshotReader mocks base method.
-func (m *MockController) SnapshotReader(arg0, arg1, arg2 uint64) (io.ReadCloser, error) {
+func (m *MockController) SnapshotReader(gid, term, index, offset uint64, compress bool) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SnapshotReader", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "SnapshotReader", gid, term, index, offset, compress)
 	ret0, _ := ret[0].(io.ReadCloser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SnapshotReader indicates an expected call of SnapshotReader.
-func (mr *MockControllerMockRecorder) SnapshotReader(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockControllerMockRecorder) SnapshotReader(gid, term, index, offset, compress interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotReader", reflect.TypeOf((*MockController)(nil).SnapshotReader), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotReader", reflect.TypeOf((*MockController)(nil).SnapshotReader), gid, term, index, offset, compress)
 }
 
 // SnapshotWriter mocks base method.
-func (m *MockController) SnapshotWriter(arg0, arg1, arg2 uint64) (io.WriteCloser, error) {
+func (m *MockController) SnapshotWriter(gid, term, index, offset uint64) (transport.SnapshotWriter, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SnapshotWriter", arg0, arg1, arg2)
-	ret0, _ := ret[0].(io.WriteCloser)
+	ret := m.ctrl.Call(m, "SnapshotWriter", gid, term, index, offset)
+	ret0, _ := ret[0].(transport.SnapshotWriter)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SnapshotWriter indicates an expected call of SnapshotWriter.
-func (mr *MockControllerMockRecorder) SnapshotWriter(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockControllerMockRecorder) SnapshotWriter(gid, term, index, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotWriter", reflect.TypeOf((*MockController)(nil).SnapshotWriter), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotWriter", reflect.TypeOf((*MockController)(nil).SnapshotWriter), gid, term, index, offset)
 }
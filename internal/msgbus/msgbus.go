@@ -1,6 +1,7 @@
 package msgbus
 
 import (
+	"errors"
 	"math/rand"
 	"sync"
 	"time"
@@ -8,38 +9,83 @@ import (
 	"go.etcd.io/etcd/pkg/v3/idutil"
 )
 
+var (
+	// ErrFull is returned by the subscribe methods when the msgbus reached its capacity.
+	ErrFull = errors.New("raft/msgbus: too many outstanding subscriptions")
+	// ErrExpired is published to a subscription garbage collected after its TTL.
+	ErrExpired = errors.New("raft/msgbus: subscription expired")
+)
+
+// Option configures msgbus using the functional options paradigm.
+type Option func(*MsgBus)
+
+// WithCapacity sets the max number of outstanding subscriptions, 0 for unbounded.
+func WithCapacity(n int) Option {
+	return func(m *MsgBus) {
+		m.capacity = n
+	}
+}
+
+// WithTTL sets the duration after which an outstanding subscription considered stale,
+// e.g. a waiter abandoned without unsubscribing, and garbage collected, 0 to disable.
+func WithTTL(d time.Duration) Option {
+	return func(m *MsgBus) {
+		m.ttl = d
+	}
+}
+
 // New create a new msgbus.
-func New() *MsgBus {
+func New(opts ...Option) *MsgBus {
 	id := rand.Int63() + 1
 	idgen := idutil.NewGenerator(uint16(id), time.Now())
-	return &MsgBus{
+	m := &MsgBus{
 		subid:  idgen,
 		events: map[uint64]map[uint64]*Subscription{},
+		now:    time.Now,
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.lastGC = m.now()
+	return m
 }
 
 // MsgBus is a 1-to-n event distribution.
 type MsgBus struct {
-	subid  *idutil.Generator // generate subscription id
-	mu     sync.Mutex
-	events map[uint64]map[uint64]*Subscription
+	subid    *idutil.Generator // generate subscription id
+	mu       sync.Mutex
+	events   map[uint64]map[uint64]*Subscription
+	capacity int
+	ttl      time.Duration
+	size     int // number of outstanding subscriptions
+	lastGC   time.Time
+	now      func() time.Time
 }
 
 // Subscribe creates an async subscription for event.
-func (m *MsgBus) Subscribe(id uint64) *Subscription {
+func (m *MsgBus) Subscribe(id uint64) (*Subscription, error) {
 	return m.subscribe(id, 1, false)
 }
 
 // SubscribeBuffered creates an async buffered subscription for event.
-func (m *MsgBus) SubscribeBuffered(id uint64, n int) *Subscription {
+func (m *MsgBus) SubscribeBuffered(id uint64, n int) (*Subscription, error) {
 	return m.subscribe(id, n, false)
 }
 
 // SubscribeOnce creates an async one time subscription for event.
-func (m *MsgBus) SubscribeOnce(id uint64) *Subscription {
+func (m *MsgBus) SubscribeOnce(id uint64) (*Subscription, error) {
 	return m.subscribe(id, 1, true)
 }
 
+// Len returns the number of outstanding subscriptions.
+func (m *MsgBus) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.size
+}
+
 // BroadcastToAll sends v to all events subscribers.
 func (m *MsgBus) BroadcastToAll(v interface{}) {
 	m.mu.Lock()
@@ -68,6 +114,7 @@ func (m *MsgBus) Close() error {
 		delete(m.events, id)
 	}
 
+	m.size = 0
 	return nil
 }
 
@@ -92,17 +139,29 @@ func (m *MsgBus) broadcast(id uint64, v interface{}) {
 	}(sv, v)
 }
 
-func (m *MsgBus) subscribe(id uint64, n int, once bool) *Subscription {
+func (m *MsgBus) subscribe(id uint64, n int, once bool) (*Subscription, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	now := m.now()
+	full := m.capacity > 0 && m.size >= m.capacity
+	if m.ttl > 0 && (full || now.Sub(m.lastGC) >= m.ttl) {
+		m.gc(now)
+		full = m.capacity > 0 && m.size >= m.capacity
+	}
+
+	if full {
+		return nil, ErrFull
+	}
+
 	s := &Subscription{
-		id:     m.subid.Next(),
-		eid:    id,
-		once:   once,
-		closed: make(chan struct{}),
-		c:      make(chan interface{}, n),
-		delete: m.delete,
+		id:      m.subid.Next(),
+		eid:     id,
+		once:    once,
+		created: now,
+		closed:  make(chan struct{}),
+		c:       make(chan interface{}, n),
+		delete:  m.delete,
 	}
 
 	subs, ok := m.events[s.eid]
@@ -112,7 +171,30 @@ func (m *MsgBus) subscribe(id uint64, n int, once bool) *Subscription {
 
 	subs[s.id] = s
 	m.events[s.eid] = subs
-	return s
+	m.size++
+	return s, nil
+}
+
+// gc removes the subscriptions outstanding for longer than the TTL,
+// their waiters, if any, receive ErrExpired.
+func (m *MsgBus) gc(now time.Time) {
+	m.lastGC = now
+
+	for id, subs := range m.events {
+		for sid, s := range subs {
+			if now.Sub(s.created) < m.ttl {
+				continue
+			}
+
+			s.expire()
+			delete(subs, sid)
+			m.size--
+		}
+
+		if len(subs) == 0 {
+			delete(m.events, id)
+		}
+	}
 }
 
 func (m *MsgBus) delete(id, sid uint64) {
@@ -124,22 +206,28 @@ func (m *MsgBus) delete(id, sid uint64) {
 		return
 	}
 
+	if _, ok := subs[sid]; !ok {
+		return
+	}
+
 	if len(subs) == 1 {
 		delete(m.events, id)
 	}
 
 	delete(subs, sid)
+	m.size--
 }
 
 // Subscription represents interest in a given event.
 type Subscription struct {
-	id     uint64 // subscription id
-	eid    uint64 // event id
-	once   bool
-	c      chan interface{}
-	closed chan struct{}
-	sonce  sync.Once
-	delete func(id, sid uint64)
+	id      uint64 // subscription id
+	eid     uint64 // event id
+	once    bool
+	created time.Time
+	c       chan interface{}
+	closed  chan struct{}
+	sonce   sync.Once
+	delete  func(id, sid uint64)
 }
 
 // Chan returns subscription channel.
@@ -165,6 +253,16 @@ func (s *Subscription) publish(v interface{}) {
 	}
 }
 
+// expire publishes ErrExpired without blocking, then closes the subscription.
+func (s *Subscription) expire() {
+	select {
+	case s.c <- ErrExpired:
+	default:
+	}
+
+	s.close()
+}
+
 func (s *Subscription) close() {
 	s.sonce.Do(func() {
 		close(s.closed)
@@ -13,8 +13,8 @@ func TestMsgBus(t *testing.T) {
 
 	val := "some value"
 	m := New()
-	s1 := m.Subscribe(eventid)
-	s2 := m.SubscribeOnce(onceid)
+	s1, _ := m.Subscribe(eventid)
+	s2, _ := m.SubscribeOnce(onceid)
 	defer m.Close()
 
 	run := func(id uint64, s1, s2 *Subscription) bool {
@@ -55,7 +55,7 @@ func TestMsgBus(t *testing.T) {
 
 	// Round #2 check once subscribe
 	// caled only once.
-	s1 = m.Subscribe(eventid)
+	s1, _ = m.Subscribe(eventid)
 	count := 0
 	for i := 0; i < 2; i++ {
 		if ok := run(onceid, s2, s1); ok {
@@ -73,3 +73,46 @@ func TestMsgBus(t *testing.T) {
 
 	unsubscribe(s2)
 }
+
+func TestMsgBusCapacity(t *testing.T) {
+	m := New(WithCapacity(2))
+	defer m.Close()
+
+	s1, err := m.SubscribeOnce(1)
+	assert.NoError(t, err)
+	_, err = m.SubscribeOnce(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, m.Len())
+
+	_, err = m.SubscribeOnce(2)
+	assert.ErrorIs(t, err, ErrFull)
+
+	// unsubscribing release the capacity, even twice.
+	s1.Unsubscribe()
+	s1.Unsubscribe()
+	assert.Equal(t, 1, m.Len())
+
+	_, err = m.SubscribeOnce(2)
+	assert.NoError(t, err)
+}
+
+func TestMsgBusTTL(t *testing.T) {
+	now := time.Now()
+	m := New(WithCapacity(1), WithTTL(time.Minute))
+	m.now = func() time.Time { return now }
+	defer m.Close()
+
+	stale, err := m.SubscribeOnce(1)
+	assert.NoError(t, err)
+
+	// it should garbage collect the stale subscription when full.
+	now = now.Add(time.Minute)
+	_, err = m.SubscribeOnce(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.Len())
+	assert.Equal(t, ErrExpired, <-stale.Chan())
+
+	// it should not collect the live subscriptions.
+	_, err = m.SubscribeOnce(3)
+	assert.ErrorIs(t, err, ErrFull)
+}
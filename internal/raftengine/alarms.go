@@ -0,0 +1,95 @@
+package raftengine
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+func newAlarms() *alarms {
+	return &alarms{
+		types: make(map[raftpb.AlarmType]map[uint64]raftpb.Alarm),
+	}
+}
+
+// alarms represents the cluster active alarms,
+// indexed by alarm type and then by member id.
+type alarms struct {
+	mu    sync.RWMutex
+	types map[raftpb.AlarmType]map[uint64]raftpb.Alarm
+}
+
+// apply the given alarm change.
+func (a *alarms) apply(ac raftpb.AlarmChange) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	alarm := ac.Alarm
+	membs, ok := a.types[alarm.Type]
+
+	switch ac.Action {
+	case raftpb.ActivateAlarm:
+		if !ok {
+			membs = make(map[uint64]raftpb.Alarm)
+			a.types[alarm.Type] = membs
+		}
+		membs[alarm.ID] = alarm
+	case raftpb.DeactivateAlarm:
+		delete(membs, alarm.ID)
+		if len(membs) == 0 {
+			delete(a.types, alarm.Type)
+		}
+	}
+}
+
+// active reports whether an alarm of the given type is active on any member.
+func (a *alarms) active(t raftpb.AlarmType) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.types[t]) > 0
+}
+
+// exist reports whether the given alarm is active.
+func (a *alarms) exist(alarm raftpb.Alarm) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, ok := a.types[alarm.Type][alarm.ID]
+	return ok
+}
+
+// snapshot returns a sorted copy of active alarms.
+func (a *alarms) snapshot() []raftpb.Alarm {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	list := []raftpb.Alarm{}
+	for _, membs := range a.types {
+		for _, alarm := range membs {
+			list = append(list, alarm)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Type == list[j].Type {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].Type < list[j].Type
+	})
+
+	return list
+}
+
+// restore replace active alarms with the given alarms.
+func (a *alarms) restore(list []raftpb.Alarm) {
+	a.mu.Lock()
+	a.types = make(map[raftpb.AlarmType]map[uint64]raftpb.Alarm)
+	a.mu.Unlock()
+
+	for _, alarm := range list {
+		a.apply(raftpb.AlarmChange{
+			Action: raftpb.ActivateAlarm,
+			Alarm:  alarm,
+		})
+	}
+}
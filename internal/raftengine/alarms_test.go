@@ -0,0 +1,34 @@
+package raftengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+func TestAlarms(t *testing.T) {
+	a := newAlarms()
+	x := raftpb.Alarm{ID: 2, Type: raftpb.NoSpaceAlarm}
+	y := raftpb.Alarm{ID: 1, Type: raftpb.NoSpaceAlarm}
+
+	require.False(t, a.active(raftpb.NoSpaceAlarm))
+
+	a.apply(raftpb.AlarmChange{Action: raftpb.ActivateAlarm, Alarm: x})
+	a.apply(raftpb.AlarmChange{Action: raftpb.ActivateAlarm, Alarm: y})
+	require.True(t, a.active(raftpb.NoSpaceAlarm))
+	require.True(t, a.exist(x))
+	require.Equal(t, []raftpb.Alarm{y, x}, a.snapshot())
+
+	a.apply(raftpb.AlarmChange{Action: raftpb.DeactivateAlarm, Alarm: x})
+	require.False(t, a.exist(x))
+	require.True(t, a.active(raftpb.NoSpaceAlarm))
+
+	a.apply(raftpb.AlarmChange{Action: raftpb.DeactivateAlarm, Alarm: y})
+	require.False(t, a.active(raftpb.NoSpaceAlarm))
+	require.Empty(t, a.snapshot())
+
+	a.restore([]raftpb.Alarm{x})
+	require.Equal(t, []raftpb.Alarm{x}, a.snapshot())
+}
@@ -0,0 +1,66 @@
+package raftengine
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+// newAuditLog returns a new audit log retaining the given number of the latest records,
+// zero or negative retains all the records.
+func newAuditLog(retention int) *auditLog {
+	return &auditLog{retention: retention}
+}
+
+// auditLog represents the replicated audit log of the administrative actions,
+// sorted by the records index.
+type auditLog struct {
+	mu        sync.RWMutex
+	retention int
+	records   []raftpb.AuditRecord
+}
+
+// append the given record, it drops the oldest records beyond the retention.
+func (a *auditLog) append(rec raftpb.AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if n := len(a.records); n > 0 && a.records[n-1].Index >= rec.Index {
+		// already applied, e.g. replayed from the WAL after a snapshot.
+		return
+	}
+
+	a.records = append(a.records, rec)
+	if n := len(a.records) - a.retention; a.retention > 0 && n > 0 {
+		a.records = append([]raftpb.AuditRecord(nil), a.records[n:]...)
+	}
+}
+
+// since returns a copy of the records applied after the given index.
+func (a *auditLog) since(index uint64) []raftpb.AuditRecord {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	i := sort.Search(len(a.records), func(i int) bool {
+		return a.records[i].Index > index
+	})
+
+	list := make([]raftpb.AuditRecord, len(a.records)-i)
+	copy(list, a.records[i:])
+	return list
+}
+
+// snapshot returns a copy of the retained records.
+func (a *auditLog) snapshot() []raftpb.AuditRecord {
+	return a.since(0)
+}
+
+// restore replace the retained records with the given records.
+func (a *auditLog) restore(list []raftpb.AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.records = make([]raftpb.AuditRecord, len(list))
+	copy(a.records, list)
+}
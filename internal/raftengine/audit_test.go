@@ -0,0 +1,45 @@
+package raftengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+func TestAuditLog(t *testing.T) {
+	a := newAuditLog(8)
+	x := raftpb.AuditRecord{Index: 1, Action: raftpb.AuditConfChange}
+	y := raftpb.AuditRecord{Index: 3, Action: raftpb.AuditTransferLeadership}
+
+	require.Empty(t, a.snapshot())
+
+	a.append(x)
+	a.append(y)
+	require.Equal(t, []raftpb.AuditRecord{x, y}, a.snapshot())
+	require.Equal(t, []raftpb.AuditRecord{y}, a.since(1))
+	require.Empty(t, a.since(3))
+
+	// it should ignore replayed records.
+	a.append(x)
+	require.Equal(t, []raftpb.AuditRecord{x, y}, a.snapshot())
+
+	// it should drop the oldest records beyond the retention.
+	for i := 0; i < 8; i++ {
+		a.append(raftpb.AuditRecord{Index: uint64(i + 4)})
+	}
+	list := a.snapshot()
+	require.Len(t, list, 8)
+	require.Equal(t, uint64(4), list[0].Index)
+
+	// it should retain all the records without retention.
+	a = newAuditLog(0)
+	for i := 0; i < 16; i++ {
+		a.append(raftpb.AuditRecord{Index: uint64(i + 1)})
+	}
+	require.Len(t, a.snapshot(), 16)
+
+	a.restore([]raftpb.AuditRecord{x})
+	require.Equal(t, []raftpb.AuditRecord{x}, a.snapshot())
+}
@@ -0,0 +1,41 @@
+package raftengine
+
+import "time"
+
+// Clock provides the tickers driving the engine, e.g. the raft logical clock,
+// so a virtual clock can drive the engine in deterministic simulations.
+type Clock interface {
+	// NewTicker returns a new ticker, that delivers the ticks at the given interval.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// realClock implements Clock using the wall clock.
+type realClock struct{}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// newTicker returns a new ticker of the engine clock, the wall clock if none.
+func (eng *engine) newTicker(d time.Duration) Ticker {
+	if eng.clock == nil {
+		return realClock{}.NewTicker(d)
+	}
+	return eng.clock.NewTicker(d)
+}
@@ -0,0 +1,214 @@
+package raftengine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"go.etcd.io/etcd/raft/v3"
+)
+
+// hashChecks holds the members hashes reported for the latest consistency check,
+// guarded by mu.
+type hashChecks struct {
+	mu     sync.Mutex
+	index  uint64
+	hashes map[uint64]uint64
+}
+
+// report records the given member hash, and returns the members whose hashes diverged
+// from the reference member hash at the same index.
+func (c *hashChecks) report(ref uint64, hr raftpb.HashReport) []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if hr.Index < c.index {
+		return nil
+	}
+
+	if hr.Index > c.index {
+		c.index = hr.Index
+		c.hashes = make(map[uint64]uint64)
+	}
+
+	c.hashes[hr.Member] = hr.Hash
+
+	want, ok := c.hashes[ref]
+	if !ok {
+		return nil
+	}
+
+	// compare the reported hash, or all the hashes once the reference reported.
+	membs := map[uint64]uint64{hr.Member: hr.Hash}
+	if hr.Member == ref {
+		membs = c.hashes
+	}
+
+	diverged := []uint64{}
+	for id, hash := range membs {
+		if hash != want {
+			diverged = append(diverged, id)
+		}
+	}
+
+	return diverged
+}
+
+// scheduleCorruptChecks proposes a consistency check periodically while the local member is the leader,
+// every member hashes its state machine when applying the check entry and reports the hash.
+func (eng *engine) scheduleCorruptChecks() {
+	if eng.corruptCheck <= 0 {
+		return
+	}
+
+	if _, ok := eng.fsm.(HashStateMachine); !ok {
+		eng.logger.Warningf("raft.engine: corruption check disabled, state machine does not implement HashStateMachine")
+		return
+	}
+
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		ticker := eng.newTicker(eng.corruptCheck)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C():
+			case <-eng.ctx.Done():
+				return
+			}
+
+			if eng.node.Status().RaftState != raft.StateLeader {
+				continue
+			}
+
+			if !eng.Supports(FeatureCorruptCheck) {
+				eng.logger.V(1).Infof("raft.engine: corruption check skipped, cluster version %d", eng.ClusterVersion())
+				continue
+			}
+
+			r := &raftpb.Replicate{
+				CID:  eng.idgen.Next(),
+				Type: raftpb.ReplicateHashCheck,
+			}
+
+			ctx, cancel := context.WithTimeout(eng.ctx, eng.cfg.TickInterval()*5)
+			err := eng.proposeReplicate(ContextWithPriority(ctx, PriorityHigh), r)
+			cancel()
+
+			if err != nil {
+				eng.logger.Warningf("raft.engine: proposing corruption check: %v", err)
+			}
+		}
+	}()
+}
+
+// publishHashCheck hashes the state machine at the given index, and proposes the hash report.
+func (eng *engine) publishHashCheck(index uint64) error {
+	hsm, ok := eng.fsm.(HashStateMachine)
+	// the replayed checks already reported before the restart.
+	if !ok || index <= eng.replayIndex {
+		return nil
+	}
+
+	hash, err := hsm.Hash(index)
+	if err != nil {
+		return fmt.Errorf("raft: hashing state machine at index %d: %w", index, err)
+	}
+
+	hr := raftpb.HashReport{
+		Member: eng.local.ID,
+		Index:  index,
+		Hash:   hash,
+	}
+
+	buf, err := hr.Marshal()
+	if err != nil {
+		return err
+	}
+
+	r := &raftpb.Replicate{
+		CID:  eng.idgen.Next(),
+		Data: buf,
+		Type: raftpb.ReplicateHashReport,
+	}
+
+	// propose asynchronously, the proposal waits for the apply path.
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		ctx, cancel := context.WithTimeout(eng.ctx, eng.cfg.TickInterval()*5)
+		defer cancel()
+
+		if err := eng.proposeReplicate(ContextWithPriority(ctx, PriorityHigh), r); err != nil {
+			eng.logger.Warningf("raft.engine: reporting state machine hash at index %d: %v", index, err)
+		}
+	}()
+
+	return nil
+}
+
+// publishHashReport compares the reported hash with the leader hash at the same index,
+// the leader raises a CORRUPT alarm for each diverged member.
+func (eng *engine) publishHashReport(data []byte) error {
+	hr := raftpb.HashReport{}
+	if err := hr.Unmarshal(data); err != nil {
+		return err
+	}
+
+	eng.logger.V(2).Infof(
+		"raft.engine: member %x state machine hash at index %d => %x",
+		hr.Member,
+		hr.Index,
+		hr.Hash,
+	)
+
+	if eng.lead != eng.local.ID {
+		return nil
+	}
+
+	for _, id := range eng.hashChecks.report(eng.local.ID, hr) {
+		eng.logger.Errorf(
+			"raft.engine: member %x state machine diverged from the leader at index %d",
+			id,
+			hr.Index,
+		)
+
+		eng.events.record(Event{
+			Type:   EventCorruption,
+			Index:  hr.Index,
+			Member: id,
+			Detail: fmt.Sprintf("state machine diverged from leader %x", eng.local.ID),
+		})
+
+		ac := raftpb.AlarmChange{
+			Action: raftpb.ActivateAlarm,
+			Alarm: raftpb.Alarm{
+				ID:   id,
+				Type: raftpb.CorruptAlarm,
+			},
+		}
+
+		if eng.alarms.exist(ac.Alarm) {
+			continue
+		}
+
+		eng.wg.Add(1)
+		go func() {
+			defer eng.wg.Done()
+
+			ctx, cancel := context.WithTimeout(eng.ctx, eng.cfg.TickInterval()*5)
+			defer cancel()
+
+			if err := eng.proposeAlarm(ctx, ac); err != nil {
+				eng.logger.Warningf("raft.engine: raising CORRUPT alarm: %v", err)
+			}
+		}()
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package raftengine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/pkg/v3/idutil"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+
+	membershipmock "github.com/shaj13/raft/internal/mocks/membership"
+	"github.com/shaj13/raft/internal/msgbus"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/raftlog"
+)
+
+func TestHashChecks(t *testing.T) {
+	c := new(hashChecks)
+
+	// it should wait for the reference hash.
+	require.Empty(t, c.report(1, raftpb.HashReport{Member: 2, Index: 10, Hash: 2}))
+	require.Empty(t, c.report(1, raftpb.HashReport{Member: 3, Index: 10, Hash: 1}))
+
+	// it should compare all the hashes once the reference reported.
+	require.Equal(t, []uint64{2}, c.report(1, raftpb.HashReport{Member: 1, Index: 10, Hash: 1}))
+
+	// it should compare the late hashes.
+	require.Equal(t, []uint64{4}, c.report(1, raftpb.HashReport{Member: 4, Index: 10, Hash: 4}))
+	require.Empty(t, c.report(1, raftpb.HashReport{Member: 5, Index: 10, Hash: 1}))
+
+	// it should ignore the stale checks.
+	require.Empty(t, c.report(1, raftpb.HashReport{Member: 1, Index: 20, Hash: 1}))
+	require.Empty(t, c.report(1, raftpb.HashReport{Member: 2, Index: 10, Hash: 2}))
+}
+
+func TestPublishHashCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := NewMockConfig(ctrl)
+	node := NewMockNode(ctrl)
+	fsm := NewMockHashStateMachine(ctrl)
+	cfg.EXPECT().TickInterval().Return(time.Second).AnyTimes()
+
+	eng := &engine{
+		cfg:    cfg,
+		node:   node,
+		fsm:    fsm,
+		logger: raftlog.DefaultLogger,
+		local:  &raftpb.Member{ID: 1},
+		idgen:  idutil.NewGenerator(1, time.Now()),
+		msgbus: msgbus.New(),
+		propq:  newProposalQueue(1),
+	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+	eng.scheduleProposals()
+
+	// it should skip the replayed checks.
+	eng.replayIndex = 5
+	require.NoError(t, eng.publishHashCheck(5))
+
+	// it should return the hash error.
+	fsm.EXPECT().Hash(uint64(6)).Return(uint64(0), errors.New("TestPublishHashCheck"))
+	require.Error(t, eng.publishHashCheck(6))
+
+	// it should report the state machine hash.
+	done := make(chan struct{})
+	fsm.EXPECT().Hash(uint64(7)).Return(uint64(42), nil)
+	node.EXPECT().Propose(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, data []byte) error {
+		defer close(done)
+		r := new(raftpb.Replicate)
+		pbutil.MustUnmarshal(r, data)
+		require.Equal(t, raftpb.ReplicateHashReport, r.Type)
+		hr := raftpb.HashReport{}
+		pbutil.MustUnmarshal(&hr, r.Data)
+		require.Equal(t, raftpb.HashReport{Member: 1, Index: 7, Hash: 42}, hr)
+		return errors.New("TestPublishHashCheck")
+	})
+	require.NoError(t, eng.publishHashCheck(7))
+	<-done
+
+	eng.cancel()
+	eng.wg.Wait()
+}
+
+func TestPublishHashReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := NewMockConfig(ctrl)
+	node := NewMockNode(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	cfg.EXPECT().TickInterval().Return(time.Second).AnyTimes()
+	pool.EXPECT().Members().Return(nil).AnyTimes()
+
+	eng := &engine{
+		cfg:        cfg,
+		node:       node,
+		pool:       pool,
+		logger:     raftlog.DefaultLogger,
+		local:      &raftpb.Member{ID: 1},
+		idgen:      idutil.NewGenerator(1, time.Now()),
+		msgbus:     msgbus.New(),
+		alarms:     newAlarms(),
+		events:     newEventRing(10),
+		hashChecks: new(hashChecks),
+		propq:      newProposalQueue(1),
+	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+	eng.scheduleProposals()
+
+	report := func(hr raftpb.HashReport) {
+		require.NoError(t, eng.publishHashReport(pbutil.MustMarshal(&hr)))
+	}
+
+	// it should only be checked by the leader.
+	report(raftpb.HashReport{Member: 1, Index: 10, Hash: 1})
+	report(raftpb.HashReport{Member: 2, Index: 10, Hash: 2})
+	require.Empty(t, eng.RecentEvents())
+
+	// it should raise a corrupt alarm for the diverged member.
+	eng.lead = 1
+	done := make(chan struct{})
+	node.EXPECT().Propose(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, data []byte) error {
+		defer close(done)
+		r := new(raftpb.Replicate)
+		pbutil.MustUnmarshal(r, data)
+		require.Equal(t, raftpb.ReplicateAlarm, r.Type)
+		ac := raftpb.AlarmChange{}
+		pbutil.MustUnmarshal(&ac, r.Data)
+		require.Equal(t, raftpb.Alarm{ID: 2, Type: raftpb.CorruptAlarm}, ac.Alarm)
+		return errors.New("TestPublishHashReport")
+	})
+	report(raftpb.HashReport{Member: 1, Index: 20, Hash: 1})
+	report(raftpb.HashReport{Member: 3, Index: 20, Hash: 1})
+	report(raftpb.HashReport{Member: 2, Index: 20, Hash: 2})
+	<-done
+
+	evs := eng.RecentEvents()
+	require.Len(t, evs, 1)
+	require.Equal(t, EventCorruption, evs[0].Type)
+	require.Equal(t, uint64(2), evs[0].Member)
+
+	eng.cancel()
+	eng.wg.Wait()
+}
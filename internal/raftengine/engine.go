@@ -5,13 +5,16 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
+	"syscall"
 	"time"
 
 	"go.etcd.io/etcd/pkg/v3/idutil"
 	"go.etcd.io/etcd/raft/v3"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/atomic"
 	"github.com/shaj13/raft/internal/membership"
 	"github.com/shaj13/raft/internal/msgbus"
@@ -23,16 +26,52 @@ import (
 var (
 	// ErrStopped is returned by the Engine methods after a call to
 	// Shutdown or when it has not started.
-	ErrStopped = errors.New("raft: node not ready yet or has been stopped")
+	ErrStopped = rafterrors.ErrStopped
 	// ErrNoLeader is returned by the Engine methods when leader lost, or
 	// no elected cluster leader.
-	ErrNoLeader = errors.New("raft: no elected cluster leader")
+	ErrNoLeader = rafterrors.ErrNoLeader
 	// ErrAlreadySnapshotting can be returned by the StateMachine.Snapshot method
 	// or the ForceSnapshot method to indicate that a snapshot is already in progress.
-	ErrAlreadySnapshotting = errors.New("raft: already snapshotting")
+	ErrAlreadySnapshotting = rafterrors.ErrAlreadySnapshotting
 	// ErrFailedPrecondition can be returned by the StateMachine.Snapshot method
 	// to indicate that the precondition for creating a snapshot is not met.
-	ErrFailedPrecondition = errors.New("raft: precondition failed")
+	ErrFailedPrecondition = rafterrors.ErrFailedPrecondition
+	// ErrNoSpace is returned by the Engine methods when the cluster has
+	// an active NOSPACE alarm, and no new data can be replicated.
+	ErrNoSpace = rafterrors.ErrNoSpace
+	// ErrMetadataTooLarge is returned by the ProposeReplicate method,
+	// when the attached metadata exceeds MaxMetadataSize.
+	ErrMetadataTooLarge = rafterrors.ErrMetadataTooLarge
+	// ErrJoinDenied is returned by the Join and PromoteMember handlers,
+	// when the request rejected by the admission policy.
+	ErrJoinDenied = rafterrors.ErrJoinDenied
+	// ErrUnauthenticated is returned by the transport handlers,
+	// when the request credentials rejected by the auth policy.
+	ErrUnauthenticated = rafterrors.ErrUnauthenticated
+	// ErrJoinRejected is returned by the join operators when the cluster rejected the join request,
+	// e.g. denied by the admission policy, unauthenticated, or incompatible version, the join request not retried.
+	ErrJoinRejected = rafterrors.ErrJoinRejected
+	// ErrClusterNotReady is returned by the join operators when the cluster can't serve the join request,
+	// e.g. unreachable or no elected leader, the join request retried per the join retry policy.
+	ErrClusterNotReady = rafterrors.ErrClusterNotReady
+	// ErrOverloaded is returned by the Push method when the engine queue is full.
+	ErrOverloaded = rafterrors.ErrOverloaded
+	// ErrProposalDropped is returned by the ProposeReplicate method,
+	// when the proposal dropped before being handed to raft, e.g. its context expired while queued,
+	// unlike the context error returned after that, the proposal will never be committed.
+	ErrProposalDropped = rafterrors.ErrProposalDropped
+	// ErrIncompatibleVersion is returned by the Join handler,
+	// when the joining member protocol version is older than the cluster version.
+	ErrIncompatibleVersion = rafterrors.ErrIncompatibleVersion
+	// ErrFeatureUnsupported is returned by the Engine methods,
+	// when the feature not supported yet by all the cluster members, e.g. during a rolling upgrade.
+	ErrFeatureUnsupported = rafterrors.ErrFeatureUnsupported
+	// ErrClusterMismatch is returned by the transport handlers,
+	// when the request source belongs to a different cluster.
+	ErrClusterMismatch = rafterrors.ErrClusterMismatch
+	// ErrCompacted is returned by the Watch method,
+	// when the requested index compacted from the retained log entries.
+	ErrCompacted = rafterrors.ErrCompacted
 )
 
 //go:generate mockgen -package raftenginemock -source engine.go -destination ../mocks/raftengine/engine.go
@@ -51,7 +90,22 @@ type Engine interface {
 	Start(addr string, oprs ...Operator) error
 	ReportUnreachable(id uint64)
 	ReportSnapshot(id uint64, status raft.SnapshotStatus)
+	ReportSnapshotTransfer(id uint64, meta etcdraftpb.SnapshotMetadata, d time.Duration, err error)
+	ReportMemberStatus(m raftpb.Member, active bool, d time.Duration)
 	ReportShutdown(id uint64)
+	ProposeAlarm(ctx context.Context, ac raftpb.AlarmChange) error
+	Alarms() []raftpb.Alarm
+	LeaderChanges() <-chan LeaderInfo
+	Ready() <-chan struct{}
+	ReadStateMachine(fn func(StateMachine) error) error
+	SlowOps() SlowOps
+	AuditLog(sinceIndex uint64) []raftpb.AuditRecord
+	RecentEvents() []Event
+	Waiters() int
+	ClusterVersion() uint32
+	Supports(Feature) bool
+	ClusterID() uint64
+	Watch(ctx context.Context, fromIndex uint64) (<-chan WatchEvent, error)
 }
 
 // New construct and return new engine from the provided config.
@@ -60,14 +114,38 @@ func New(cfg Config) Engine {
 	d.cfg = cfg
 	d.fsm = d.cfg.StateMachine()
 	d.storage = cfg.Storage()
-	d.msgbus = msgbus.New()
+	d.msgbus = msgbus.New(
+		msgbus.WithCapacity(cfg.MaxWaiters()),
+		msgbus.WithTTL(cfg.WaiterTTL()),
+	)
 	d.pool = cfg.Pool()
 	d.started = atomic.NewBool()
 	d.appliedIndex = atomic.NewUint64()
+	d.applied = new(appliedNotifier)
 	d.snapIndex = atomic.NewUint64()
 	d.snapshoting = atomic.NewBool()
+	d.nospace = atomic.NewBool()
+	d.alarms = newAlarms()
+	d.audit = newAuditLog(cfg.AuditLogRetention())
 	d.logger = cfg.Logger()
 	d.stateCh = cfg.StateChangeCh()
+	d.snapEventCh = cfg.SnapshotEventCh()
+	d.membEventCh = cfg.MemberEventCh()
+	d.applyHook = cfg.ApplyHook()
+	d.proposalWeight = cfg.ProposalWeight()
+	d.slowApply = cfg.SlowApplyThreshold()
+	d.slowSync = cfg.SlowSyncThreshold()
+	d.slowOpHook = cfg.SlowOpHook()
+	d.slowApplies = atomic.NewUint64()
+	d.msgTap = newMessageTap(cfg)
+	d.events = newEventRing(cfg.EventBufferSize())
+	d.corruptCheck = cfg.CorruptCheckInterval()
+	d.hashChecks = new(hashChecks)
+	d.clock = cfg.Clock()
+	d.faults = cfg.FaultInjector()
+	d.slowSyncs = atomic.NewUint64()
+	d.leaderCh = make(chan LeaderInfo, 1)
+	d.readyc = make(chan struct{})
 	return d
 }
 
@@ -86,15 +164,23 @@ type engine struct {
 	propwg sync.WaitGroup
 	// processwg waits for all the process goroutines to be terminated before
 	// shutting down the node.
-	processwg    sync.WaitGroup
-	cache        *raft.MemoryStorage
-	storage      storage.Storage
-	msgbus       *msgbus.MsgBus
-	idgen        *idutil.Generator
-	pool         membership.Pool
-	started      *atomic.Bool
-	snapIndex    *atomic.Uint64
-	snapshoting  *atomic.Bool
+	processwg   sync.WaitGroup
+	cache       *raft.MemoryStorage
+	storage     storage.Storage
+	msgbus      *msgbus.MsgBus
+	idgen       IDGenerator
+	pool        membership.Pool
+	started     *atomic.Bool
+	snapIndex   *atomic.Uint64
+	snapshoting *atomic.Bool
+	// snapbase is the latest snapshot and the number of
+	// delta snapshots it chained onto, guarded by snapmu.
+	snapmu       sync.Mutex
+	snapbase     etcdraftpb.SnapshotMetadata
+	snapdeltas   int
+	nospace      *atomic.Bool
+	alarms       *alarms
+	audit        *auditLog
 	appliedIndex *atomic.Uint64
 	proposec     chan etcdraftpb.Message
 	msgc         chan etcdraftpb.Message
@@ -102,6 +188,44 @@ type engine struct {
 	confState    *etcdraftpb.ConfState
 	logger       raftlog.Logger
 	stateCh      chan raft.StateType
+	snapEventCh  chan SnapshotEvent
+	membEventCh  chan MemberEvent
+	applyHook    func(ApplyEvent)
+	// propq schedules the replicate proposals by their priority,
+	// serving up to proposalWeight high priority proposals for each normal one.
+	propq          *proposalQueue
+	proposalWeight int
+	// slowApply and slowSync are the thresholds beyond which the state machine applies
+	// and the storage syncs reported as slow, and counted by slowApplies and slowSyncs.
+	slowApply   time.Duration
+	slowSync    time.Duration
+	slowOpHook  func(SlowOpEvent)
+	slowApplies *atomic.Uint64
+	slowSyncs   *atomic.Uint64
+	// msgTap hands the inbound and outbound messages to the user tap, if any.
+	msgTap *messageTap
+	// events retains the last significant engine events, See RecentEvents.
+	events *eventRing
+	// corruptCheck is the interval of the state machines consistency checks,
+	// hashChecks holds the members hashes of the latest check.
+	corruptCheck time.Duration
+	hashChecks   *hashChecks
+	// clock provides the engine tickers, the wall clock if nil.
+	clock Clock
+	// faults injects the faults for chaos testing, if any.
+	faults FaultInjector
+	// leaderCh holds the latest leader change, See notifyLeaderChange.
+	leaderCh chan LeaderInfo
+	// readyc closed once the node has a leader and replayed its WAL up to replayIndex,
+	// guarded by readymu. lead is the leader known by the event loop.
+	readymu     sync.Mutex
+	readyc      chan struct{}
+	replayIndex uint64
+	lead        uint64
+	// restoremu guards the state machine from being read while restoring a snapshot.
+	restoremu sync.RWMutex
+	// applied notifies the watchers of the applied index progress, See Watch.
+	applied *appliedNotifier
 }
 
 func (eng *engine) LinearizableRead(ctx context.Context) error {
@@ -118,8 +242,12 @@ func (eng *engine) LinearizableRead(ctx context.Context) error {
 		buf := make([]byte, 8)
 		id := eng.idgen.Next()
 		binary.BigEndian.PutUint64(buf, id)
-		sub := eng.msgbus.SubscribeOnce(id)
-		t := time.NewTicker(dur)
+		sub, err := eng.subscribe(id)
+		if err != nil {
+			return 0, err
+		}
+
+		t := eng.newTicker(dur)
 
 		defer t.Stop()
 		defer sub.Unsubscribe()
@@ -131,7 +259,7 @@ func (eng *engine) LinearizableRead(ctx context.Context) error {
 			}
 
 			select {
-			case <-t.C:
+			case <-t.C():
 			case v := <-sub.Chan():
 				if err, ok := v.(error); ok {
 					return 0, err
@@ -175,6 +303,45 @@ func (eng *engine) ReportSnapshot(id uint64, status raft.SnapshotStatus) {
 	eng.node.ReportSnapshot(id, status)
 }
 
+func (eng *engine) ReportMemberStatus(m raftpb.Member, active bool, d time.Duration) {
+	ev := MemberEvent{
+		Type:        MemberUnreachable,
+		Member:      m,
+		Unreachable: d,
+	}
+
+	if active {
+		ev.Type = MemberReachable
+	}
+
+	eng.notifyMemberEvent(ev)
+}
+
+func (eng *engine) ReportSnapshotTransfer(id uint64, meta etcdraftpb.SnapshotMetadata, d time.Duration, err error) {
+	if eng.snapEventCh == nil {
+		return
+	}
+
+	ev := SnapshotEvent{
+		Type:     SnapshotSent,
+		Term:     meta.Term,
+		Index:    meta.Index,
+		Member:   id,
+		Duration: d,
+		Err:      err,
+	}
+
+	// sum the size of the sent snapshot files.
+	if chain, cerr := eng.storage.Snapshotter().Chain(meta.Term, meta.Index); cerr == nil {
+		for _, m := range chain {
+			n, _ := eng.storage.Snapshotter().Offset(m.Term, m.Index)
+			ev.Bytes += int64(n)
+		}
+	}
+
+	eng.notifySnapshotEvent(ev)
+}
+
 func (eng *engine) ReportShutdown(id uint64) {
 	if eng.started.False() {
 		return
@@ -186,7 +353,7 @@ func (eng *engine) ReportShutdown(id uint64) {
 	defer cancel()
 
 	if err := eng.Shutdown(ctx); err != nil {
-		eng.logger.Fatal(err)
+		eng.logger.Errorf("raft.engine: shutting down: %v", err)
 	}
 }
 
@@ -203,6 +370,8 @@ func (eng *engine) Push(msg etcdraftpb.Message) error {
 		return err
 	}
 
+	eng.msgTap.tap(Inbound, msg)
+
 	// chan based on msg type.
 	c := eng.msgc
 	if msg.Type == etcdraftpb.MsgProp {
@@ -214,7 +383,12 @@ func (eng *engine) Push(msg etcdraftpb.Message) error {
 	case <-eng.ctx.Done():
 		return eng.ctx.Err()
 	default:
-		return errors.New("buffer is full (overloaded network)")
+		eng.events.record(Event{
+			Type:   EventMessageDropped,
+			Member: msg.From,
+			Detail: fmt.Sprintf("inbound %s: %v", msg.Type, ErrOverloaded),
+		})
+		return ErrOverloaded
 	}
 
 	return nil
@@ -282,10 +456,11 @@ func (eng *engine) TransferLeadership(ctx context.Context, transferee uint64) er
 	eng.propwg.Add(1)
 	defer eng.propwg.Done()
 
-	eng.logger.Infof("raft.engine: start transfer leadership %x -> %x", eng.node.Status().Lead, transferee)
+	lead := eng.node.Status().Lead
+	eng.logger.Infof("raft.engine: start transfer leadership %x -> %x", lead, transferee)
 
-	eng.node.TransferLeadership(ctx, eng.node.Status().Lead, transferee)
-	ticker := time.NewTicker(eng.cfg.TickInterval() / 10)
+	eng.node.TransferLeadership(ctx, lead, transferee)
+	ticker := eng.newTicker(eng.cfg.TickInterval() / 10)
 	defer ticker.Stop()
 	for {
 		leader := eng.node.Status().Lead
@@ -297,10 +472,11 @@ func (eng *engine) TransferLeadership(ctx context.Context, transferee uint64) er
 			return ErrStopped
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-ticker.C:
+		case <-ticker.C():
 		}
 	}
 
+	eng.recordAudit(ctx, raftpb.AuditTransferLeadership, fmt.Sprintf("leadership %x -> %x", lead, transferee))
 	return nil
 }
 
@@ -313,24 +489,40 @@ func (eng *engine) ProposeReplicate(ctx context.Context, data []byte) error {
 	eng.propwg.Add(1)
 	defer eng.propwg.Done()
 
-	r := &raftpb.Replicate{
-		CID:  eng.idgen.Next(),
-		Data: data,
+	// reject locally until the NOSPACE alarm raised, once a write failed due to lack of space.
+	if eng.nospace.True() || eng.alarms.active(raftpb.NoSpaceAlarm) {
+		return ErrNoSpace
 	}
 
-	buf, err := r.Marshal()
-	if err != nil {
-		return err
+	md := MetadataFromContext(ctx)
+	if metadataSize(md) > MaxMetadataSize {
+		return ErrMetadataTooLarge
 	}
 
-	eng.logger.V(1).Infof("raft.engine: propose replicate data, change id => %d", r.CID)
+	r := &raftpb.Replicate{
+		CID:      eng.idgen.Next(),
+		Data:     data,
+		Metadata: md,
+	}
 
-	if err := eng.node.Propose(ctx, buf); err != nil {
-		return err
+	return eng.proposeReplicate(ctx, r)
+}
+
+// ProposeAlarm proposes to activate or deactivate a cluster alarm.
+func (eng *engine) ProposeAlarm(ctx context.Context, ac raftpb.AlarmChange) error {
+	if eng.started.False() {
+		return ErrStopped
 	}
 
-	// wait for changes to be done
-	return eng.wait(ctx, r.CID)
+	eng.propwg.Add(1)
+	defer eng.propwg.Done()
+
+	return eng.proposeAlarm(ctx, ac)
+}
+
+// Alarms returns the cluster active alarms.
+func (eng *engine) Alarms() []raftpb.Alarm {
+	return eng.alarms.snapshot()
 }
 
 // ProposeConfChange proposes a configuration change to the cluster pool members.
@@ -351,12 +543,15 @@ func (eng *engine) ProposeConfChange(ctx context.Context, m *raftpb.Member, cct
 	return eng.wait(ctx, id)
 }
 
-// CreateSnapshot begin a snapshot and return snap metadata.
+// CreateSnapshot creates a snapshot and return snap metadata, once the snapshot written.
 func (eng *engine) CreateSnapshot() (etcdraftpb.Snapshot, error) {
 	if eng.started.False() {
 		return etcdraftpb.Snapshot{}, ErrStopped
 	}
 
+	eng.propwg.Add(1)
+	defer eng.propwg.Done()
+
 	appliedIndex := eng.appliedIndex.Get()
 	snapIndex := eng.snapIndex.Get()
 
@@ -371,35 +566,89 @@ func (eng *engine) CreateSnapshot() (etcdraftpb.Snapshot, error) {
 		return etcdraftpb.Snapshot{}, err
 	}
 
-	return eng.cache.Snapshot()
+	snap, err := eng.cache.Snapshot()
+	if err != nil {
+		return snap, err
+	}
+
+	eng.recordAudit(eng.ctx, raftpb.AuditForceSnapshot, fmt.Sprintf("snapshot at index %d", snap.Metadata.Index))
+	return snap, nil
+}
+
+// ClusterID returns the id of the cluster the local member belongs to,
+// zero when the node not started yet or its state predates the cluster id.
+func (eng *engine) ClusterID() uint64 {
+	if eng.started.False() {
+		return 0
+	}
+	return eng.local.ClusterID
+}
+
+// RecentEvents returns the last significant engine events, the oldest first.
+func (eng *engine) RecentEvents() []Event {
+	return eng.events.events()
+}
+
+// AuditLog returns the retained records of the replicated audit log,
+// applied after the given index.
+func (eng *engine) AuditLog(sinceIndex uint64) []raftpb.AuditRecord {
+	return eng.audit.since(sinceIndex)
 }
 
 // Start engine.
 func (eng *engine) Start(addr string, oprs ...Operator) error {
+	var sched schedule
+	if spec := eng.cfg.SnapshotSchedule(); spec != "" {
+		s, err := parseSchedule(spec)
+		if err != nil {
+			return err
+		}
+		sched = s
+	}
+
 	sp := setup{addr: addr}
 	ssp := stateSetup{publishSnapshotFile: eng.publishSnapshotFile}
 	rm := removedMembers{}
 	oprs = append(oprs, sp, ssp, rm)
+	eng.ctx, eng.cancel = context.WithCancel(eng.cfg.Context())
 	ost, err := invoke(eng, oprs...)
 	if err != nil {
+		eng.cancel()
 		return err
 	}
 
 	if eng.node == nil {
+		eng.cancel()
 		return errors.New("raft: node not initialized, use raft.WithInitCluster() or raft.WithRestart()")
 	}
 
-	// set local member.
-	eng.local = ost.local
+	// set local member, advertising the local protocol version.
+	local := *ost.local
+	local.Version = ProtocolVersion
+	if len(ost.addr) > 0 {
+		local.Address = ost.addr
+	}
+	eng.local = &local
+	eng.resetReady(ost.hst.Commit)
 	eng.idgen = idutil.NewGenerator(uint16(eng.local.ID), time.Now())
-	eng.ctx, eng.cancel = context.WithCancel(eng.cfg.Context())
+	if fn := eng.cfg.IDGenerator(); fn != nil {
+		eng.idgen = fn(eng.local.ID)
+	}
 	eng.proposec = make(chan etcdraftpb.Message, 4096)
 	eng.msgc = make(chan etcdraftpb.Message, 4096)
 	eng.snapshotc = make(chan chan error)
+	eng.propq = newProposalQueue(eng.proposalWeight)
 	eng.started.Set()
 
 	eng.process(eng.proposec)
 	eng.process(eng.msgc)
+	eng.scheduleProposals()
+	eng.monitorSpace()
+	eng.monitorMembers()
+	eng.updateLocalMember(ost.addr)
+	eng.auditRestore(ost.restored)
+	eng.scheduleCorruptChecks()
+	eng.scheduleSnapshots(sched)
 	return eng.eventLoop()
 }
 
@@ -407,19 +656,25 @@ func (eng *engine) eventLoop() error {
 	eng.wg.Add(1)
 	defer eng.wg.Done()
 
-	ticker := time.NewTicker(eng.cfg.TickInterval())
+	ticker := eng.newTicker(eng.cfg.TickInterval())
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			eng.node.Tick()
 		case rd := <-eng.node.Ready():
 			prevIndex := eng.appliedIndex.Get()
 
-			if err := eng.storage.SaveEntries(rd.HardState, rd.Entries); err != nil {
+			if err := eng.syncFault(rd.HardState, rd.Entries); err != nil {
+				return err
+			}
+
+			start := time.Now()
+			if err := eng.saveEntries(rd.HardState, rd.Entries); err != nil {
 				return err
 			}
+			eng.observeSync(rd.HardState, rd.Entries, time.Since(start))
 
 			if err := eng.publishSnapshot(rd.Snapshot); err != nil {
 				return err
@@ -436,16 +691,23 @@ func (eng *engine) eventLoop() error {
 					eng.msgbus.BroadcastToAll(ErrNoLeader)
 				}
 				go eng.notifyStateChange(rd.SoftState.RaftState)
+				eng.notifyLeaderChange(rd.SoftState, rd.HardState)
+				eng.lead = rd.SoftState.Lead
 			}
 
-			eng.publishCommitted(rd.CommittedEntries)
+			if err := eng.publishCommitted(rd.CommittedEntries); err != nil {
+				return err
+			}
 			eng.publishReadState(rd.ReadStates)
 			eng.publishAppliedIndices(prevIndex, eng.appliedIndex.Get())
+			eng.maybeReady()
 			eng.promotions()
 			eng.maybeCreateSnapshot()
 			eng.node.Advance()
 		case c := <-eng.snapshotc:
-			c <- eng.createSnapshot()
+			if err := eng.beginSnapshot(c); err != nil {
+				c <- err
+			}
 		case <-eng.ctx.Done():
 			return ErrStopped
 		}
@@ -465,202 +727,1039 @@ func (eng *engine) notifyStateChange(state raft.StateType) {
 	}
 }
 
-func (eng *engine) proposeConfChange(
-	ctx context.Context,
-	m *raftpb.Member,
-	t etcdraftpb.ConfChangeType,
-) (uint64, error) {
+// ReadStateMachine calls the given function with the state machine,
+// guaranteeing no snapshot restored concurrently.
+func (eng *engine) ReadStateMachine(fn func(StateMachine) error) error {
+	eng.restoremu.RLock()
+	defer eng.restoremu.RUnlock()
+	return fn(eng.fsm)
+}
 
-	buf, err := m.Marshal()
-	if err != nil {
-		return 0, err
-	}
+func (eng *engine) Ready() <-chan struct{} {
+	eng.readymu.Lock()
+	defer eng.readymu.Unlock()
+	return eng.readyc
+}
 
-	cc := etcdraftpb.ConfChange{
-		ID:      eng.idgen.Next(),
-		Type:    t,
-		NodeID:  m.ID,
-		Context: buf,
+// resetReady renews the ready channel if closed by a previous start,
+// and sets the index the WAL must be replayed up to.
+func (eng *engine) resetReady(index uint64) {
+	eng.readymu.Lock()
+	defer eng.readymu.Unlock()
+
+	select {
+	case <-eng.readyc:
+		eng.readyc = make(chan struct{})
+	default:
 	}
 
-	eng.logger.V(1).Infof("raft.engine: propose conf change, change id => %d", cc.ID)
-	return cc.ID, eng.node.ProposeConfChange(ctx, cc)
+	eng.replayIndex = index
+	eng.lead = raft.None
 }
 
-func (eng *engine) publishReadState(rss []raft.ReadState) {
-	for _, rs := range rss {
-		id := binary.BigEndian.Uint64(rs.RequestCtx)
-		eng.msgbus.Broadcast(id, rs.Index)
+// maybeReady closes the ready channel,
+// once the node has a leader and replayed its WAL.
+func (eng *engine) maybeReady() {
+	if eng.lead == raft.None || eng.appliedIndex.Get() < eng.replayIndex {
+		return
 	}
-}
 
-func (eng *engine) publishAppliedIndices(prev, curr uint64) {
-	for i := prev + 1; i < curr+1; i++ {
-		eng.msgbus.Broadcast(i, nil)
+	eng.readymu.Lock()
+	defer eng.readymu.Unlock()
+
+	select {
+	case <-eng.readyc:
+	default:
+		close(eng.readyc)
 	}
 }
 
-func (eng *engine) publishSnapshot(snap etcdraftpb.Snapshot) error {
-	if raft.IsEmptySnap(snap) {
-		return nil
+func (eng *engine) LeaderChanges() <-chan LeaderInfo {
+	return eng.leaderCh
+}
+
+// notifyLeaderChange sends the given soft state to the leader changes channel,
+// the pending change replaced when the channel is full, so it always holds the latest change.
+func (eng *engine) notifyLeaderChange(ss *raft.SoftState, hs etcdraftpb.HardState) {
+	li := LeaderInfo{
+		ID:    ss.Lead,
+		Term:  hs.Term,
+		State: ss.RaftState,
 	}
 
-	if snap.Metadata.Index <= eng.appliedIndex.Get() {
-		return fmt.Errorf(
-			"raft: snapshot index [%d] should > progress.appliedIndex [%s]",
-			snap.Metadata.Index,
-			eng.appliedIndex,
-		)
+	if raft.IsEmptyHardState(hs) {
+		li.Term = eng.node.Status().Term
 	}
 
-	if err := eng.storage.SaveSnapshot(snap); err != nil {
-		return err
+	if mem, ok := eng.pool.Get(ss.Lead); ok {
+		li.Address = mem.Address()
 	}
 
-	meta := snap.Metadata
-	sf, err := eng.storage.Snapshotter().Read(meta.Term, meta.Index)
-	if err != nil {
-		return err
+	if ss.Lead != eng.lead {
+		eng.events.record(Event{
+			Type:   EventLeaderChange,
+			Term:   li.Term,
+			Member: ss.Lead,
+			Detail: fmt.Sprintf("leader %x -> %x, local state %s", eng.lead, ss.Lead, ss.RaftState),
+		})
 	}
 
-	return eng.publishSnapshotFile(sf)
-}
+	for {
+		select {
+		case eng.leaderCh <- li:
+			return
+		default:
+		}
 
-func (eng *engine) publishSnapshotFile(sf *storage.Snapshot) error {
-	snap := sf.Raw
+		// drop the pending change.
+		select {
+		case <-eng.leaderCh:
+		default:
+		}
+	}
+}
 
-	if err := eng.cache.ApplySnapshot(snap); err != nil {
-		return err
+// notifySnapshotEvent sends the given event to the snapshot events channel,
+// the event dropped when the channel is full to not block the engine.
+func (eng *engine) notifySnapshotEvent(ev SnapshotEvent) {
+	if eng.snapEventCh == nil {
+		return
 	}
 
-	eng.pool.Restore(sf.Members)
+	select {
+	case eng.snapEventCh <- ev:
+	default:
+		eng.logger.V(2).Infof("raft.engine: dropped snapshot event %s, events channel is full", ev.Type)
+	}
+}
 
-	if err := eng.fsm.Restore(sf.Data); err != nil {
-		return err
+// notifyMemberEvent sends the given event to the member events channel,
+// the event dropped when the channel is full to not block the engine.
+func (eng *engine) notifyMemberEvent(ev MemberEvent) {
+	if eng.membEventCh == nil {
+		return
 	}
 
-	eng.confState = &snap.Metadata.ConfState
-	eng.snapIndex.Set(snap.Metadata.Index)
-	eng.appliedIndex.Set(snap.Metadata.Index)
-	return nil
+	select {
+	case eng.membEventCh <- ev:
+	default:
+		eng.logger.V(2).Infof("raft.engine: dropped member event %s, events channel is full", ev.Type)
+	}
 }
 
-func (eng *engine) publishCommitted(ents []etcdraftpb.Entry) {
-	for _, ent := range ents {
-		if ent.Type == etcdraftpb.EntryNormal && len(ent.Data) > 0 {
-			eng.publishReplicate(ent)
-		}
-		if ent.Type == etcdraftpb.EntryConfChange {
-			eng.publishConfChange(ent)
-		}
-		eng.appliedIndex.Set(ent.Index)
+func (eng *engine) proposeReplicate(ctx context.Context, r *raftpb.Replicate) error {
+	buf, err := r.Marshal()
+	if err != nil {
+		return err
 	}
-}
 
-func (eng *engine) publishReplicate(ent etcdraftpb.Entry) {
-	var err error
-	r := new(raftpb.Replicate)
-	defer func() {
-		eng.msgbus.Broadcast(r.CID, err)
-		if err != nil {
-			eng.logger.Warningf(
-				"raft.engine: publishing replicate data: %v",
-				err,
-			)
-		}
-	}()
+	eng.logger.V(1).Infof("raft.engine: propose replicate data, change id => %d", r.CID)
 
-	if err = r.Unmarshal(ent.Data); err != nil {
-		return
+	p := &proposal{
+		ctx:  ctx,
+		data: buf,
+		errc: make(chan error, 1),
 	}
 
-	eng.logger.V(1).Infof("raft.engine: publishing replicate data, change id => %d", r.CID)
+	// subscribe before proposing, the entry might be applied before the proposal returns,
+	// e.g. a single member cluster commits the entry right away.
+	sub, err := eng.subscribe(r.CID)
+	if err != nil {
+		return err
+	}
 
-	err = eng.fsm.Apply(r.Data)
-	return
-}
+	defer sub.Unsubscribe()
 
-func (eng *engine) publishConfChange(ent etcdraftpb.Entry) {
-	var err error
-	cc := new(etcdraftpb.ConfChange)
-	mem := new(raftpb.Member)
+	select {
+	case eng.propq.lane(PriorityFromContext(ctx)) <- p:
+	case <-ctx.Done():
+		return proposalDropped(ctx.Err())
+	case <-eng.ctx.Done():
+		return ErrStopped
+	}
 
-	defer func() {
-		eng.msgbus.Broadcast(cc.ID, err)
-		if err != nil {
-			eng.logger.Warningf("raft.engine: publishing conf change: %v", err)
+	select {
+	case err = <-p.errc:
+	case <-ctx.Done():
+		// the proposal still queued, cancel it so it never handed to raft.
+		if p.cancel() {
+			return proposalDropped(ctx.Err())
 		}
-	}()
+		// the proposal being handed to raft, it may still be committed.
+		return ctx.Err()
+	case <-eng.ctx.Done():
+		return ErrStopped
+	}
 
-	if err = cc.Unmarshal(ent.Data); err != nil {
-		return
+	if err != nil {
+		return err
 	}
 
-	eng.logger.V(1).Infof("raft.engine: publishing conf change, change id => %d", cc.ID)
+	// wait for changes to be done
+	return eng.waitFor(ctx, sub)
+}
 
-	if len(cc.Context) == 0 {
-		return
+func (eng *engine) proposeAlarm(ctx context.Context, ac raftpb.AlarmChange) error {
+	// the members not upgraded yet apply the alarm change as the state machine data.
+	if !eng.Supports(FeatureAlarms) {
+		return fmt.Errorf("%w: %s, cluster version %d", ErrFeatureUnsupported, FeatureAlarms, eng.ClusterVersion())
 	}
 
-	if err = mem.Unmarshal(cc.Context); err != nil {
-		return
+	buf, err := ac.Marshal()
+	if err != nil {
+		return err
 	}
 
-	switch cc.Type {
-	case etcdraftpb.ConfChangeAddNode, etcdraftpb.ConfChangeAddLearnerNode:
-		err = eng.pool.Add(*mem)
-	case etcdraftpb.ConfChangeUpdateNode:
-		err = eng.pool.Update(*mem)
-	case etcdraftpb.ConfChangeRemoveNode:
-		eng.wg.Add(1)
-		go func(mem raftpb.Member) {
-			defer eng.wg.Done()
-			select {
-			// wait for two ticks then go and remove the member from the pool.
-			// to make sure the commit ack sent before closing connection.
-			case <-time.After(eng.cfg.TickInterval() * 2):
-				if err := eng.pool.Remove(mem); err != nil {
-					eng.logger.Errorf("raft.engine: removing member %x: %v", mem.ID, err)
-				}
-			case <-eng.ctx.Done():
-				return
-			}
-		}(*mem)
+	r := &raftpb.Replicate{
+		CID:  eng.idgen.Next(),
+		Data: buf,
+		Type: raftpb.ReplicateAlarm,
 	}
 
-	eng.confState = eng.node.ApplyConfChange(cc)
+	eng.logger.Infof("raft.engine: propose %s alarm %s for member %x", ac.Action, ac.Alarm.Type, ac.Alarm.ID)
+	return eng.proposeReplicate(ContextWithPriority(ctx, PriorityHigh), r)
 }
 
-// process the incoming messages from the given chan.
-func (eng *engine) process(c chan etcdraftpb.Message) {
-	eng.processwg.Add(1)
+// scheduleProposals hands the queued proposals to the raft node by their priority,
+// until the engine context is done.
+func (eng *engine) scheduleProposals() {
+	eng.wg.Add(1)
 	go func() {
-		defer eng.processwg.Done()
-		// process must keep processing msg until c closed or ctx.Done(),
-		// for graceful shutdown purposes.
-		for m := range c {
-			if err := eng.ctx.Err(); err != nil {
+		defer eng.wg.Done()
+		for {
+			p, ok := eng.propq.next(eng.ctx.Done())
+			if !ok {
 				return
 			}
 
-			if err := eng.node.Step(eng.ctx, m); err != nil {
-				eng.logger.Warningf("raft.engine: process raft message: %v", err)
+			// the expired and cancelled proposals never handed to raft,
+			// so they don't count toward the uncommitted entries size limit.
+			if err := p.ctx.Err(); err != nil {
+				if p.cancel() {
+					p.errc <- proposalDropped(err)
+				}
+				continue
+			}
+
+			if !p.propose() {
+				continue
+			}
+
+			err := eng.node.Propose(p.ctx, p.data)
+			if errors.Is(err, raft.ErrProposalDropped) {
+				err = proposalDropped(err)
 			}
+
+			p.errc <- err
 		}
 	}()
 }
 
-func (eng *engine) send(msgs []etcdraftpb.Message) {
-	lg := func(m etcdraftpb.Message, str string) {
-		eng.logger.Warningf(
-			"raft.engine: sending message %s to member %x: %v",
+// proposalDropped returns ErrProposalDropped wrapping the given cause.
+func proposalDropped(cause error) error {
+	return fmt.Errorf("%w: %v", ErrProposalDropped, cause)
+}
+
+// monitorSpace periodically checks the available disk space and raises
+// a NOSPACE alarm when it falls below the configured low watermark, or
+// when a previous write failed due to lack of space.
+func (eng *engine) monitorSpace() {
+	check := func() {
+		low := eng.nospace.True()
+		if watermark := eng.cfg.DiskLowWatermark(); watermark > 0 && !low {
+			avail, err := eng.storage.Available()
+			if err != nil {
+				eng.logger.V(2).Infof("raft.engine: checking available disk space: %v", err)
+				return
+			}
+			low = avail < watermark
+		}
+
+		alarm := raftpb.Alarm{
+			ID:   eng.local.ID,
+			Type: raftpb.NoSpaceAlarm,
+		}
+
+		if !low || eng.alarms.exist(alarm) {
+			return
+		}
+
+		ac := raftpb.AlarmChange{
+			Action: raftpb.ActivateAlarm,
+			Alarm:  alarm,
+		}
+
+		ctx, cancel := context.WithTimeout(eng.ctx, eng.cfg.TickInterval()*5)
+		defer cancel()
+
+		if err := eng.proposeAlarm(ctx, ac); err != nil {
+			eng.logger.Warningf("raft.engine: raising NOSPACE alarm: %v", err)
+			return
+		}
+
+		eng.nospace.UnSet()
+	}
+
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		ticker := eng.newTicker(eng.cfg.TickInterval() * 10)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C():
+				check()
+			case <-eng.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// monitorMembers periodically checks the cluster members reachability while being the leader,
+// and proposes to remove the members unreachable longer than the configured dead member timeout.
+func (eng *engine) monitorMembers() {
+	timeout := eng.cfg.DeadMemberTimeout()
+	if timeout <= 0 {
+		return
+	}
+
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		ticker := eng.newTicker(eng.cfg.TickInterval() * 10)
+		defer ticker.Stop()
+
+		// unreachables holds the time each member first seen unreachable,
+		// since the current node became the leader.
+		unreachables := make(map[uint64]time.Time)
+
+		for {
+			select {
+			case <-ticker.C():
+				eng.removeDeadMembers(unreachables, timeout)
+			case <-eng.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// removeDeadMembers proposes to remove the first member unreachable longer than the given timeout,
+// as long as the remaining voters still hold a quorum.
+func (eng *engine) removeDeadMembers(unreachables map[uint64]time.Time, timeout time.Duration) {
+	rs := eng.node.Status()
+
+	// the current node is not the leader.
+	if rs.Progress == nil {
+		for id := range unreachables {
+			delete(unreachables, id)
+		}
+		return
+	}
+
+	now := time.Now()
+	membs := eng.pool.Members()
+	seen := make(map[uint64]bool, len(membs))
+	reachables := 0
+	voters := 0
+	var dead *raftpb.Member
+	var since time.Time
+
+	for _, mem := range membs {
+		raw := mem.Raw()
+		if raw.Type == raftpb.RemovedMember {
+			continue
+		}
+
+		pr, ok := rs.Progress[raw.ID]
+		voter := ok && !pr.IsLearner
+		if voter {
+			voters++
+		}
+
+		if raw.ID == rs.ID || mem.IsActive() {
+			if voter {
+				reachables++
+			}
+			continue
+		}
+
+		seen[raw.ID] = true
+		if _, ok := unreachables[raw.ID]; !ok {
+			unreachables[raw.ID] = now
+		}
+
+		if dead == nil && now.Sub(unreachables[raw.ID]) >= timeout {
+			dead, since = &raw, unreachables[raw.ID]
+		}
+	}
+
+	for id := range unreachables {
+		if !seen[id] {
+			delete(unreachables, id)
+		}
+	}
+
+	if dead == nil {
+		return
+	}
+
+	remaining := voters
+	if pr, ok := rs.Progress[dead.ID]; ok && !pr.IsLearner {
+		remaining--
+	}
+
+	// quorum lost and the cluster unavailable, or removing the member
+	// leaves the reachable voters short of the remaining voters quorum.
+	if reachables < voters/2+1 || reachables < remaining/2+1 {
+		eng.logger.V(2).Infof("raft.engine: skip removing dead member %x, quorum at risk", dead.ID)
+		return
+	}
+
+	ev := MemberEvent{
+		Type:        MemberDeadRemoved,
+		Member:      *dead,
+		Unreachable: now.Sub(since),
+	}
+
+	eng.logger.Warningf("raft.engine: removing member %x, unreachable for %s", dead.ID, ev.Unreachable)
+
+	raw := *dead
+	raw.Type = raftpb.RemovedMember
+	ctx, cancel := context.WithTimeout(eng.ctx, eng.cfg.TickInterval()*5)
+	defer cancel()
+
+	if _, ev.Err = eng.proposeConfChange(ctx, &raw, etcdraftpb.ConfChangeRemoveNode); ev.Err != nil {
+		eng.logger.Warningf("raft.engine: removing dead member %x: %v", dead.ID, ev.Err)
+	} else {
+		delete(unreachables, dead.ID)
+	}
+
+	eng.notifyMemberEvent(ev)
+}
+
+// updateLocalMember proposes to update the replicated local member record
+// with the given address if not empty, and with the local protocol version once upgraded,
+// until the record updated.
+func (eng *engine) updateLocalMember(addr string) {
+	if len(addr) > 0 {
+		eng.logger.Infof("raft.engine: local member %x address changed to %s", eng.local.ID, addr)
+	}
+
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		ticker := eng.newTicker(eng.cfg.TickInterval() * 10)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C():
+			case <-eng.ctx.Done():
+				return
+			}
+
+			mem, ok := eng.pool.Get(eng.local.ID)
+			if !ok {
+				continue
+			}
+
+			raw := mem.Raw()
+			if raw.Type == raftpb.RemovedMember {
+				return
+			}
+
+			if (len(addr) == 0 || raw.Address == addr) && raw.Version >= ProtocolVersion {
+				return
+			}
+
+			if len(addr) > 0 {
+				raw.Address = addr
+			}
+
+			if raw.Version < ProtocolVersion {
+				eng.logger.Infof(
+					"raft.engine: local member %x protocol version upgraded from %d to %d",
+					raw.ID,
+					raw.Version,
+					ProtocolVersion,
+				)
+				raw.Version = ProtocolVersion
+			}
+
+			ctx, cancel := context.WithTimeout(eng.ctx, eng.cfg.TickInterval()*5)
+			_, err := eng.proposeConfChange(ctx, &raw, etcdraftpb.ConfChangeUpdateNode)
+			cancel()
+
+			if err != nil {
+				eng.logger.Warningf("raft.engine: updating local member %x: %v", raw.ID, err)
+			}
+		}
+	}()
+}
+
+// scheduleSnapshots triggers a snapshot at each activation of the given schedule,
+// independent of the number of log entries applied since the last snapshot.
+func (eng *engine) scheduleSnapshots(sched schedule) {
+	if sched == nil {
+		return
+	}
+
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		for {
+			next := sched.next(time.Now())
+			if next.IsZero() {
+				eng.logger.Warning("raft.engine: snapshot schedule never activates")
+				return
+			}
+
+			tm := time.NewTimer(time.Until(next))
+			select {
+			case <-tm.C:
+			case <-eng.ctx.Done():
+				tm.Stop()
+				return
+			}
+
+			c := make(chan error)
+			select {
+			case eng.snapshotc <- c:
+			case <-eng.ctx.Done():
+				return
+			}
+
+			if err := <-c; err != nil && !errors.Is(err, ErrAlreadySnapshotting) {
+				eng.logger.Errorf("raft.engine: creating scheduled snapshot: %v", err)
+			}
+		}
+	}()
+}
+
+func (eng *engine) proposeConfChange(
+	ctx context.Context,
+	m *raftpb.Member,
+	t etcdraftpb.ConfChangeType,
+) (uint64, error) {
+	buf, err := m.Marshal()
+	if err != nil {
+		return 0, err
+	}
+
+	cc := etcdraftpb.ConfChange{
+		ID:      eng.idgen.Next(),
+		Type:    t,
+		NodeID:  m.ID,
+		Context: buf,
+	}
+
+	eng.logger.V(1).Infof("raft.engine: propose conf change, change id => %d", cc.ID)
+	if err := eng.node.ProposeConfChange(ctx, cc); err != nil {
+		return 0, err
+	}
+
+	eng.recordAudit(ctx, raftpb.AuditConfChange, fmt.Sprintf("%s member %x address %s", t, m.ID, m.Address))
+	return cc.ID, nil
+}
+
+func (eng *engine) publishReadState(rss []raft.ReadState) {
+	for _, rs := range rss {
+		id := binary.BigEndian.Uint64(rs.RequestCtx)
+		eng.msgbus.Broadcast(id, rs.Index)
+	}
+}
+
+func (eng *engine) publishAppliedIndices(prev, curr uint64) {
+	for i := prev + 1; i < curr+1; i++ {
+		eng.msgbus.Broadcast(i, nil)
+	}
+
+	if curr > prev {
+		eng.applied.notify()
+	}
+}
+
+func (eng *engine) publishSnapshot(snap etcdraftpb.Snapshot) error {
+	if raft.IsEmptySnap(snap) {
+		return nil
+	}
+
+	if snap.Metadata.Index <= eng.appliedIndex.Get() {
+		return fmt.Errorf(
+			"raft: snapshot index [%d] should > progress.appliedIndex [%s]",
+			snap.Metadata.Index,
+			eng.appliedIndex,
+		)
+	}
+
+	if err := eng.storage.SaveSnapshot(snap); err != nil {
+		return err
+	}
+
+	meta := snap.Metadata
+	sf, err := eng.storage.Snapshotter().Read(eng.ctx, meta.Term, meta.Index)
+	if err != nil {
+		return err
+	}
+
+	if err := eng.publishSnapshotFile(sf); err != nil {
+		return err
+	}
+
+	eng.events.record(Event{
+		Type:   EventSnapshotInstalled,
+		Term:   meta.Term,
+		Index:  meta.Index,
+		Detail: fmt.Sprintf("snapshot at index %d", meta.Index),
+	})
+	return nil
+}
+
+func (eng *engine) publishSnapshotFile(sf *storage.Snapshot) error {
+	snap := sf.Raw
+
+	if err := eng.cache.ApplySnapshot(snap); err != nil {
+		return err
+	}
+
+	eng.pool.Restore(sf.Members)
+	eng.alarms.restore(sf.Alarms)
+	eng.audit.restore(sf.Audit)
+
+	eng.restoremu.Lock()
+	deltas, err := eng.restoreStateMachine(sf)
+	eng.restoremu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	eng.setSnapshotBase(snap.Metadata, deltas)
+
+	eng.confState = &snap.Metadata.ConfState
+	eng.snapIndex.Set(snap.Metadata.Index)
+	eng.appliedIndex.Set(snap.Metadata.Index)
+	return nil
+}
+
+// restoreStateMachine restores the state machine from the given snapshot,
+// a delta snapshot restored on top of the snapshots it chained onto.
+// it returns the number of delta snapshots the given snapshot chained onto.
+func (eng *engine) restoreStateMachine(sf *storage.Snapshot) (int, error) {
+	if sf.BaseIndex == 0 {
+		return 0, eng.restore(sf.Data)
+	}
+
+	meta := sf.Raw.Metadata
+	ifsm, ok := eng.fsm.(IncrementalStateMachine)
+	if !ok {
+		return 0, fmt.Errorf(
+			"raft: snapshot at index %d is a delta snapshot, state machine does not implement IncrementalStateMachine",
+			meta.Index,
+		)
+	}
+
+	chain, err := eng.storage.Snapshotter().Chain(meta.Term, meta.Index)
+	if err != nil {
+		return 0, err
+	}
+
+	restore := func(i int, m etcdraftpb.SnapshotMetadata) error {
+		base, err := eng.storage.Snapshotter().Read(eng.ctx, m.Term, m.Index)
+		if err != nil {
+			return err
+		}
+
+		defer base.Data.Close()
+
+		if i == 0 {
+			return ifsm.Restore(base.Data)
+		}
+
+		return ifsm.RestoreIncremental(base.Data)
+	}
+
+	for i, m := range chain[:len(chain)-1] {
+		if err := restore(i, m); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(chain) - 1, ifsm.RestoreIncremental(sf.Data)
+}
+
+func (eng *engine) snapshotBase() (etcdraftpb.SnapshotMetadata, int) {
+	eng.snapmu.Lock()
+	defer eng.snapmu.Unlock()
+	return eng.snapbase, eng.snapdeltas
+}
+
+func (eng *engine) setSnapshotBase(meta etcdraftpb.SnapshotMetadata, deltas int) {
+	eng.snapmu.Lock()
+	defer eng.snapmu.Unlock()
+	eng.snapbase = meta
+	eng.snapdeltas = deltas
+}
+
+func (eng *engine) publishCommitted(ents []etcdraftpb.Entry) error {
+	for _, ent := range ents {
+		if err := eng.applyFault(BeforeApply, ent.Index); err != nil {
+			return err
+		}
+		if ent.Type == etcdraftpb.EntryNormal && len(ent.Data) > 0 {
+			eng.publishReplicate(ent)
+		}
+		if ent.Type == etcdraftpb.EntryConfChange {
+			eng.publishConfChange(ent)
+		}
+		eng.appliedIndex.Set(ent.Index)
+		if err := eng.applyFault(AfterApply, ent.Index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (eng *engine) publishReplicate(ent etcdraftpb.Entry) {
+	var err error
+	r := new(raftpb.Replicate)
+	defer func() {
+		eng.msgbus.Broadcast(r.CID, err)
+		if err != nil {
+			eng.logger.Warningf(
+				"raft.engine: publishing replicate data: %v",
+				err,
+			)
+		}
+	}()
+
+	if err = r.Unmarshal(ent.Data); err != nil {
+		return
+	}
+
+	eng.logger.V(1).Infof("raft.engine: publishing replicate data, change id => %d", r.CID)
+
+	switch r.Type {
+	case raftpb.ReplicateAlarm:
+		err = eng.publishAlarm(r.Data)
+	case raftpb.ReplicateAudit:
+		err = eng.publishAudit(ent.Index, r.Data)
+	case raftpb.ReplicateHashCheck:
+		err = eng.publishHashCheck(ent.Index)
+	case raftpb.ReplicateHashReport:
+		err = eng.publishHashReport(r.Data)
+	case raftpb.ReplicateData:
+		start := time.Now()
+		err = eng.apply(r.Data, r.Metadata)
+		eng.observeApply(ent.Index, time.Since(start))
+		if eng.applyHook != nil {
+			eng.applyHook(ApplyEvent{
+				Index:    ent.Index,
+				Term:     ent.Term,
+				Metadata: r.Metadata,
+				Err:      err,
+			})
+		}
+	default:
+		// the entry replicated by a newer member, never applied to the state machine.
+		err = fmt.Errorf("raft: unsupported replicate type %s, protocol version %d", r.Type, ProtocolVersion)
+	}
+}
+
+// observeApply reports the state machine apply of the entry at the given index,
+// if it took longer than the slow apply threshold.
+func (eng *engine) observeApply(index uint64, d time.Duration) {
+	if eng.slowApply <= 0 || d < eng.slowApply {
+		return
+	}
+
+	eng.slowApplies.Inc()
+	eng.logger.Warningf(
+		"raft.engine: slow apply, applying entry %d took %s, exceeds %s",
+		index,
+		d,
+		eng.slowApply,
+	)
+	eng.notifySlowOp(SlowOpEvent{Type: SlowApply, Index: index, Duration: d})
+}
+
+// saveEntries persists the given hard state and entries, when the disk runs out of space
+// it flags the lack of space to reject the local proposals, and returns the error to stop the node.
+// The save not retried, the WAL left in an unknown state by the partial write.
+func (eng *engine) saveEntries(hs etcdraftpb.HardState, ents []etcdraftpb.Entry) error {
+	err := eng.storage.SaveEntries(hs, ents)
+	if errors.Is(err, syscall.ENOSPC) {
+		eng.nospace.Set()
+		return fmt.Errorf("raft.engine: saving entries: %w", err)
+	}
+
+	return err
+}
+
+// observeSync reports the storage sync of the given hard state and entries,
+// if it took longer than the slow sync threshold.
+func (eng *engine) observeSync(hs etcdraftpb.HardState, ents []etcdraftpb.Entry, d time.Duration) {
+	if eng.slowSync <= 0 || d < eng.slowSync || (raft.IsEmptyHardState(hs) && len(ents) == 0) {
+		return
+	}
+
+	index := hs.Commit
+	if len(ents) > 0 {
+		index = ents[len(ents)-1].Index
+	}
+
+	eng.slowSyncs.Inc()
+	eng.logger.Warningf(
+		"raft.engine: slow sync, syncing entries up to %d took %s, exceeds %s",
+		index,
+		d,
+		eng.slowSync,
+	)
+	eng.notifySlowOp(SlowOpEvent{Type: SlowSync, Index: index, Duration: d})
+}
+
+func (eng *engine) notifySlowOp(ev SlowOpEvent) {
+	if eng.slowOpHook != nil {
+		eng.slowOpHook(ev)
+	}
+}
+
+// SlowOps returns the number of the slow operations since the node started.
+func (eng *engine) SlowOps() SlowOps {
+	return SlowOps{
+		Applies: eng.slowApplies.Get(),
+		Syncs:   eng.slowSyncs.Get(),
+	}
+}
+
+// apply applies the given data and its metadata to the state machine,
+// a StateMachineV2 applies it with the engine context, carrying the metadata.
+func (eng *engine) apply(data []byte, md map[string]string) error {
+	if sm, ok := AsStateMachineV2(eng.fsm); ok {
+		ctx := eng.ctx
+		if len(md) > 0 {
+			ctx = ContextWithMetadata(ctx, md)
+		}
+		return sm.Apply(ctx, data)
+	}
+
+	if sm, ok := eng.fsm.(MetadataStateMachine); ok {
+		return sm.ApplyWithMetadata(data, md)
+	}
+
+	return eng.fsm.Apply(data)
+}
+
+// restore restores the state machine from the given snapshot data,
+// a StateMachineV2 restores it with the engine context.
+func (eng *engine) restore(r io.ReadCloser) error {
+	if sm, ok := AsStateMachineV2(eng.fsm); ok {
+		return sm.Restore(eng.ctx, r)
+	}
+	return eng.fsm.Restore(r)
+}
+
+// snapshot writes the state machine snapshot,
+// a StateMachineV2 writes it with the engine context.
+func (eng *engine) snapshot() (io.ReadCloser, error) {
+	if sm, ok := AsStateMachineV2(eng.fsm); ok {
+		return sm.Snapshot(eng.ctx)
+	}
+	return eng.fsm.Snapshot()
+}
+
+func (eng *engine) publishAlarm(data []byte) error {
+	ac := raftpb.AlarmChange{}
+	if err := ac.Unmarshal(data); err != nil {
+		return err
+	}
+
+	eng.logger.Warningf("raft.engine: %s alarm %s for member %x", ac.Action, ac.Alarm.Type, ac.Alarm.ID)
+	eng.alarms.apply(ac)
+	return nil
+}
+
+func (eng *engine) publishAudit(index uint64, data []byte) error {
+	rec := raftpb.AuditRecord{}
+	if err := rec.Unmarshal(data); err != nil {
+		return err
+	}
+
+	rec.Index = index
+	eng.logger.Infof("raft.engine: audit %s by member %x: %s", rec.Action, rec.Member, rec.Detail)
+	eng.audit.append(rec)
+	return nil
+}
+
+// recordAudit proposes asynchronously to record the given administrative action into the replicated audit log,
+// along with the metadata attached to the given context, e.g. the principal.
+// The record replicated in its own log entry, hence the replicated configurations, e.g. the members, remain free of it.
+// The action already performed, therefore a failure logged rather than returned.
+func (eng *engine) recordAudit(ctx context.Context, action raftpb.AuditAction, detail string) {
+	if !eng.Supports(FeatureAuditLog) {
+		eng.logger.V(1).Infof("raft.engine: audit %s not recorded, cluster version %d", action, eng.ClusterVersion())
+		return
+	}
+
+	rec := raftpb.AuditRecord{
+		Time:     time.Now().UnixNano(),
+		Member:   eng.local.ID,
+		Action:   action,
+		Detail:   detail,
+		Metadata: MetadataFromContext(ctx),
+	}
+
+	buf, err := rec.Marshal()
+	if err != nil {
+		eng.logger.Warningf("raft.engine: recording audit %s: %s: %v", action, detail, err)
+		return
+	}
+
+	r := &raftpb.Replicate{
+		CID:  eng.idgen.Next(),
+		Data: buf,
+		Type: raftpb.ReplicateAudit,
+	}
+
+	// the caller not blocked on the record, and the record outlives the caller context.
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		// bound the wait, the record may never be committed, e.g. the leader stepped down.
+		ctx, cancel := context.WithTimeout(ContextWithPriority(eng.ctx, PriorityHigh), eng.cfg.TickInterval()*50)
+		defer cancel()
+
+		eng.logger.V(1).Infof("raft.engine: propose audit record %s: %s", action, detail)
+		if err := eng.proposeReplicate(ctx, r); err != nil {
+			eng.logger.Warningf("raft.engine: recording audit %s: %s: %v", action, detail, err)
+		}
+	}()
+}
+
+// auditRestore records the cluster restore from the given snapshot file path,
+// once the node is ready.
+func (eng *engine) auditRestore(path string) {
+	if len(path) == 0 {
+		return
+	}
+
+	eng.wg.Add(1)
+	go func() {
+		defer eng.wg.Done()
+
+		select {
+		case <-eng.Ready():
+		case <-eng.ctx.Done():
+			return
+		}
+
+		eng.recordAudit(eng.ctx, raftpb.AuditRestore, fmt.Sprintf("restore from snapshot %s", path))
+	}()
+}
+
+func (eng *engine) publishConfChange(ent etcdraftpb.Entry) {
+	var err error
+	cc := new(etcdraftpb.ConfChange)
+	mem := new(raftpb.Member)
+
+	defer func() {
+		eng.msgbus.Broadcast(cc.ID, err)
+		if err != nil {
+			eng.logger.Warningf("raft.engine: publishing conf change: %v", err)
+		}
+	}()
+
+	if err = cc.Unmarshal(ent.Data); err != nil {
+		return
+	}
+
+	eng.logger.V(1).Infof("raft.engine: publishing conf change, change id => %d", cc.ID)
+
+	if len(cc.Context) == 0 {
+		return
+	}
+
+	if err = mem.Unmarshal(cc.Context); err != nil {
+		return
+	}
+
+	switch cc.Type {
+	case etcdraftpb.ConfChangeAddNode, etcdraftpb.ConfChangeAddLearnerNode:
+		err = eng.pool.Add(*mem)
+	case etcdraftpb.ConfChangeUpdateNode:
+		err = eng.pool.Update(*mem)
+	case etcdraftpb.ConfChangeRemoveNode:
+		eng.wg.Add(1)
+		go func(mem raftpb.Member) {
+			defer eng.wg.Done()
+			select {
+			// wait for two ticks then go and remove the member from the pool.
+			// to make sure the commit ack sent before closing connection.
+			case <-time.After(eng.cfg.TickInterval() * 2):
+				if err := eng.pool.Remove(mem); err != nil {
+					eng.logger.Errorf("raft.engine: removing member %x: %v", mem.ID, err)
+				}
+			case <-eng.ctx.Done():
+				return
+			}
+		}(*mem)
+	}
+
+	eng.confState = eng.node.ApplyConfChange(cc)
+	eng.events.record(Event{
+		Type:   EventConfChange,
+		Term:   ent.Term,
+		Index:  ent.Index,
+		Member: mem.ID,
+		Detail: fmt.Sprintf("%s member %x address %s", cc.Type, mem.ID, mem.Address),
+	})
+}
+
+// process the incoming messages from the given chan.
+func (eng *engine) process(c chan etcdraftpb.Message) {
+	eng.processwg.Add(1)
+	go func() {
+		defer eng.processwg.Done()
+		// process must keep processing msg until c closed or ctx.Done(),
+		// for graceful shutdown purposes.
+		for m := range c {
+			if err := eng.ctx.Err(); err != nil {
+				return
+			}
+
+			if err := eng.node.Step(eng.ctx, m); err != nil {
+				eng.logger.Warningf("raft.engine: process raft message: %v", err)
+			}
+		}
+	}()
+}
+
+func (eng *engine) send(msgs []etcdraftpb.Message) {
+	lg := func(m etcdraftpb.Message, str string) {
+		eng.logger.Warningf(
+			"raft.engine: sending message %s to member %x: %v",
 			m.Type,
 			m.To,
 			str,
 		)
+		eng.events.record(Event{
+			Type:   EventMessageDropped,
+			Member: m.To,
+			Detail: fmt.Sprintf("outbound %s: %s", m.Type, str),
+		})
 	}
 
 	for _, m := range msgs {
+		eng.msgTap.tap(Outbound, m)
+
 		mem, ok := eng.pool.Get(m.To)
 		if !ok {
 			lg(m, "unknown member")
@@ -671,13 +1770,17 @@ func (eng *engine) send(msgs []etcdraftpb.Message) {
 			continue
 		}
 
-		if err := mem.Send(m); err != nil {
+		if err := eng.sendMessage(mem, m); err != nil {
 			lg(m, err.Error())
 		}
 	}
 }
 
 func (eng *engine) promotions() {
+	if eng.cfg.DisableAutoPromotion() {
+		return
+	}
+
 	rs := eng.node.Status()
 
 	// the current node is not the leader.
@@ -704,6 +1807,11 @@ func (eng *engine) promotions() {
 			continue
 		}
 
+		// the staging member not healthy for long enough yet.
+		if d := eng.cfg.PromotionMinHealthy(); d > 0 && (!mem.IsActive() || time.Since(mem.ActiveSince()) < d) {
+			continue
+		}
+
 		leader := rs.Progress[rs.ID].Match
 		staging := rs.Progress[raw.ID].Match
 
@@ -790,10 +1898,20 @@ func (eng *engine) maybeCreateSnapshot() {
 }
 
 func (eng *engine) createSnapshot() error {
+	return eng.beginSnapshot(nil)
+}
+
+// beginSnapshot begins a snapshot and writes it in the background,
+// once written, the write error sent to the given done chan if not nil.
+// the done chan is not notified when it returns an error.
+func (eng *engine) beginSnapshot(done chan<- error) error {
 	appliedIndex := eng.appliedIndex.Get()
 	snapIndex := eng.snapIndex.Get()
 
 	if appliedIndex == snapIndex {
+		if done != nil {
+			done <- nil
+		}
 		return nil
 	}
 
@@ -802,21 +1920,57 @@ func (eng *engine) createSnapshot() error {
 	}
 
 	eng.snapshoting.Set()
+	start := time.Now()
+
+	// write a delta snapshot chained onto the latest snapshot,
+	// unless the chain reached the maximum number of delta snapshots.
+	base, deltas := eng.snapshotBase()
+	ifsm, incremental := eng.fsm.(IncrementalStateMachine)
+	incremental = incremental && base.Index > 0 && deltas < eng.cfg.MaxSnapshotDeltas()
+
+	var (
+		r   io.ReadCloser
+		src SnapshotSource
+		err error
+	)
+
+	// a two phase state machine only capture a consistent view of its state,
+	// and serialize it later without blocking applies.
+	tfsm, twoPhase := eng.fsm.(TwoPhaseStateMachine)
+
+	switch {
+	case incremental:
+		r, err = ifsm.IncrementalSnapshot(base.Index)
+	case twoPhase:
+		src, err = tfsm.PrepareSnapshot()
+	default:
+		r, err = eng.snapshot()
+	}
 
-	r, err := eng.fsm.Snapshot()
 	if err != nil {
 		eng.snapshoting.UnSet()
+		eng.notifySnapshotEvent(SnapshotEvent{
+			Type:     SnapshotCreated,
+			Index:    appliedIndex,
+			Duration: time.Since(start),
+			Delta:    incremental,
+			Err:      err,
+		})
 		return err
 	}
 
 	eng.logger.Infof(
-		"raft.engine: start snapshot [applied index: %d | last snapshot index: %d]",
+		"raft.engine: start snapshot [applied index: %d | last snapshot index: %d | delta: %t]",
 		appliedIndex,
 		snapIndex,
+		incremental,
 	)
 
 	snap, err := eng.cache.CreateSnapshot(appliedIndex, eng.confState, nil)
 	if err != nil {
+		if src != nil {
+			src.Release()
+		}
 		eng.snapshoting.UnSet()
 		return err
 	}
@@ -825,29 +1979,73 @@ func (eng *engine) createSnapshot() error {
 		SnapshotState: raftpb.SnapshotState{
 			Raw:     snap,
 			Members: eng.pool.Snapshot(),
+			Alarms:  eng.alarms.snapshot(),
+			Audit:   eng.audit.snapshot(),
 		},
-		Data: r,
+	}
+
+	var cr *countReader
+	if eng.snapEventCh != nil {
+		cr = new(countReader)
+	}
+
+	if incremental {
+		ss.BaseTerm = base.Term
+		ss.BaseIndex = base.Index
+		deltas++
+	} else {
+		deltas = 0
 	}
 
 	if err := eng.storage.SaveSnapshot(snap); err != nil {
+		if src != nil {
+			src.Release()
+		}
 		return err
 	}
 
 	fn := func() error {
 		defer eng.snapshoting.UnSet()
 
+		r := r
+		if src != nil {
+			defer src.Release()
+
+			var err error
+			if r, err = src.Reader(); err != nil {
+				return err
+			}
+			defer r.Close()
+		}
+
+		ss.Data = r
+		if cr != nil {
+			cr.ReadCloser = r
+			ss.Data = cr
+		}
+
 		if err := eng.storage.Snapshotter().Write(&ss); err != nil {
+			if errors.Is(err, syscall.ENOSPC) {
+				eng.nospace.Set()
+			}
 			return err
 		}
 
 		eng.snapIndex.Set(appliedIndex)
+		eng.setSnapshotBase(snap.Metadata, deltas)
 
-		if appliedIndex <= eng.cfg.SnapInterval() {
+		// keep the trailing entries for the slow followers,
+		// to catch up from the log instead of a snapshot transfer.
+		retain := eng.cfg.RetainEntries()
+		if appliedIndex <= retain {
 			return nil
 		}
 
-		compactIndex := appliedIndex - eng.cfg.SnapInterval()
+		compactIndex := appliedIndex - retain
 		if err := eng.cache.Compact(compactIndex); err != nil {
+			if errors.Is(err, raft.ErrCompacted) {
+				return nil
+			}
 			return err
 		}
 
@@ -858,7 +2056,8 @@ func (eng *engine) createSnapshot() error {
 	eng.wg.Add(1)
 	go func() {
 		defer eng.wg.Done()
-		if err := fn(); err != nil {
+		err := fn()
+		if err != nil {
 			eng.snapIndex.Set(snapIndex)
 			eng.logger.Errorf(
 				"raft.engine: creating new snapshot at index %s failed: %v",
@@ -866,14 +2065,69 @@ func (eng *engine) createSnapshot() error {
 				err,
 			)
 		}
+
+		if done != nil {
+			done <- err
+		}
+
+		if cr == nil {
+			return
+		}
+
+		eng.notifySnapshotEvent(SnapshotEvent{
+			Type:     SnapshotCreated,
+			Term:     snap.Metadata.Term,
+			Index:    snap.Metadata.Index,
+			Bytes:    cr.n,
+			Duration: time.Since(start),
+			Delta:    incremental,
+			Err:      err,
+		})
 	}()
 	return nil
 }
 
+// countReader counts the number of bytes read.
+type countReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
 func (eng *engine) wait(ctx context.Context, id uint64) error {
-	sub := eng.msgbus.SubscribeOnce(id)
+	sub, err := eng.subscribe(id)
+	if err != nil {
+		return err
+	}
+
 	defer sub.Unsubscribe()
+	return eng.waitFor(ctx, sub)
+}
+
+// subscribe returns a one time subscription to the result of the given id,
+// Or ErrOverloaded when the outstanding waiters reached the limit.
+func (eng *engine) subscribe(id uint64) (*msgbus.Subscription, error) {
+	sub, err := eng.msgbus.SubscribeOnce(id)
+	if errors.Is(err, msgbus.ErrFull) {
+		eng.logger.Warningf("raft.engine: %v, id => %d", err, id)
+		return nil, fmt.Errorf("%w: %v", ErrOverloaded, err)
+	}
+
+	return sub, err
+}
+
+// Waiters returns the number of the outstanding waiters of the operations results.
+func (eng *engine) Waiters() int {
+	return eng.msgbus.Len()
+}
 
+// waitFor waits for the event of the given subscription.
+func (eng *engine) waitFor(ctx context.Context, sub *msgbus.Subscription) error {
 	select {
 	case v := <-sub.Chan():
 		if v != nil {
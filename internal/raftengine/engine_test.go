@@ -4,6 +4,11 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -34,6 +39,21 @@ func TestNew(t *testing.T) {
 	cfg.EXPECT().StateMachine()
 	cfg.EXPECT().Logger()
 	cfg.EXPECT().StateChangeCh()
+	cfg.EXPECT().SnapshotEventCh()
+	cfg.EXPECT().MemberEventCh()
+	cfg.EXPECT().ApplyHook()
+	cfg.EXPECT().ProposalWeight()
+	cfg.EXPECT().SlowApplyThreshold()
+	cfg.EXPECT().SlowSyncThreshold()
+	cfg.EXPECT().SlowOpHook()
+	cfg.EXPECT().MessageTap()
+	cfg.EXPECT().EventBufferSize()
+	cfg.EXPECT().CorruptCheckInterval()
+	cfg.EXPECT().Clock()
+	cfg.EXPECT().FaultInjector()
+	cfg.EXPECT().MaxWaiters()
+	cfg.EXPECT().WaiterTTL()
+	cfg.EXPECT().AuditLogRetention()
 
 	eng := New(cfg)
 	require.NotNil(t, eng)
@@ -55,6 +75,8 @@ func TestStart(t *testing.T) {
 		cfg:          cfg,
 		msgbus:       msgbus.New(),
 		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
 		started:      atomic.NewBool(),
 		snapIndex:    atomic.NewUint64(),
 		appliedIndex: atomic.NewUint64(),
@@ -68,12 +90,16 @@ func TestStart(t *testing.T) {
 	cfg.EXPECT().Context().Return(ctx).MaxTimes(2)
 	cfg.EXPECT().Logger().Return(raftlog.DefaultLogger).MaxTimes(2)
 	cfg.EXPECT().RaftConfig().Return(&raft.Config{}).MaxTimes(2)
-	cfg.EXPECT().TickInterval().Return(time.Second).MaxTimes(2)
+	cfg.EXPECT().TickInterval().Return(time.Second).MaxTimes(3)
 	cfg.EXPECT().DrainTimeout().Return(time.Nanosecond).MaxTimes(2)
+	cfg.EXPECT().SnapshotSchedule().Return("").MaxTimes(2)
+	cfg.EXPECT().DeadMemberTimeout().Return(time.Duration(0)).MaxTimes(2)
+	cfg.EXPECT().MemberTypeMatcher().MaxTimes(2)
+	cfg.EXPECT().IDGenerator().MaxTimes(2)
 	stg.EXPECT().Exist().Return(false).MaxTimes(2)
 	pool.EXPECT().RegisterTypeMatcher(gomock.Any()).MaxTimes(2)
 	pool.EXPECT().TearDown(gomock.Any()).MaxTimes(2)
-	stg.EXPECT().Boot(gomock.Any()).MaxTimes(2)
+	stg.EXPECT().Boot(gomock.Any(), gomock.Any()).MaxTimes(2)
 	stg.EXPECT().Close().MaxTimes(2)
 	node.EXPECT().Ready().Return(ready).MaxTimes(2)
 	node.EXPECT().Stop().MaxTimes(2)
@@ -89,6 +115,26 @@ func TestStart(t *testing.T) {
 	require.Equal(t, ErrStopped, err)
 }
 
+func TestScheduleSnapshots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	eng := &engine{
+		ctx:       ctx,
+		logger:    raftlog.DefaultLogger,
+		snapshotc: make(chan chan error),
+	}
+
+	eng.scheduleSnapshots(every(time.Millisecond))
+
+	// it request a snapshot on each schedule activation.
+	for i := 0; i < 2; i++ {
+		c := <-eng.snapshotc
+		c <- ErrAlreadySnapshotting
+	}
+
+	cancel()
+	eng.wg.Wait()
+}
+
 func TestReportUnreachable(t *testing.T) {
 	id := uint64(1)
 	ctrl := gomock.NewController(t)
@@ -127,6 +173,132 @@ func TestReportSnapshot(t *testing.T) {
 	eng.ReportSnapshot(id, raft.SnapshotFinish)
 }
 
+func TestReportMemberStatus(t *testing.T) {
+	m := raftpb.Member{ID: 1}
+	eng := &engine{
+		logger:      raftlog.DefaultLogger,
+		membEventCh: make(chan MemberEvent, 2),
+	}
+
+	eng.ReportMemberStatus(m, false, time.Second)
+	eng.ReportMemberStatus(m, true, time.Minute)
+
+	ev := <-eng.membEventCh
+	require.Equal(t, MemberUnreachable, ev.Type)
+	require.Equal(t, m, ev.Member)
+	require.Equal(t, time.Second, ev.Unreachable)
+
+	ev = <-eng.membEventCh
+	require.Equal(t, MemberReachable, ev.Type)
+	require.Equal(t, time.Minute, ev.Unreachable)
+}
+
+func TestNotifyLeaderChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	eng := &engine{
+		pool:     pool,
+		leaderCh: make(chan LeaderInfo, 1),
+	}
+
+	pool.EXPECT().Get(uint64(1)).Return(nil, false)
+	pool.EXPECT().Get(uint64(2)).Return(mem, true)
+	mem.EXPECT().Address().Return(":8080")
+
+	// it coalesce the pending changes.
+	eng.notifyLeaderChange(&raft.SoftState{Lead: 1, RaftState: raft.StateLeader}, etcdraftpb.HardState{Term: 1})
+	eng.notifyLeaderChange(&raft.SoftState{Lead: 2, RaftState: raft.StateFollower}, etcdraftpb.HardState{Term: 2})
+
+	li := <-eng.LeaderChanges()
+	require.Equal(t, LeaderInfo{ID: 2, Address: ":8080", Term: 2, State: raft.StateFollower}, li)
+	require.Len(t, eng.leaderCh, 0)
+}
+
+func TestReady(t *testing.T) {
+	eng := &engine{
+		appliedIndex: atomic.NewUint64(),
+		readyc:       make(chan struct{}),
+	}
+
+	ready := func() bool {
+		select {
+		case <-eng.Ready():
+			return true
+		default:
+			return false
+		}
+	}
+
+	// it should not be ready before having a leader.
+	eng.resetReady(5)
+	eng.appliedIndex.Set(5)
+	eng.maybeReady()
+	require.False(t, ready())
+
+	// it should not be ready before replaying the WAL.
+	eng.lead = 1
+	eng.appliedIndex.Set(4)
+	eng.maybeReady()
+	require.False(t, ready())
+
+	eng.appliedIndex.Set(5)
+	eng.maybeReady()
+	eng.maybeReady()
+	require.True(t, ready())
+
+	// it should renew the ready channel on restart.
+	eng.resetReady(5)
+	require.False(t, ready())
+}
+
+func TestReadStateMachine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fsm := NewMockStateMachine(ctrl)
+	eng := &engine{fsm: fsm}
+
+	// it should call the function with the state machine.
+	err := eng.ReadStateMachine(func(sm StateMachine) error {
+		require.Equal(t, fsm, sm)
+		return ErrStopped
+	})
+	require.ErrorIs(t, err, ErrStopped)
+}
+
+func TestStateMachineV2(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sm := NewMockStateMachineV2(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	eng := &engine{
+		ctx: ctx,
+		fsm: FromStateMachineV2(sm),
+	}
+
+	got, ok := AsStateMachineV2(eng.fsm)
+	require.True(t, ok)
+	require.Equal(t, sm, got)
+
+	// it should call the state machine with the engine context.
+	cancel()
+	sm.EXPECT().Apply(gomock.Eq(ctx), gomock.Any()).DoAndReturn(func(ctx context.Context, _ []byte) error {
+		return ctx.Err()
+	})
+	sm.EXPECT().Snapshot(gomock.Eq(ctx)).Return(nil, context.Canceled)
+	sm.EXPECT().Restore(gomock.Eq(ctx), gomock.Any()).Return(context.Canceled)
+
+	require.ErrorIs(t, eng.apply(nil, nil), context.Canceled)
+	_, err := eng.snapshot()
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, eng.restore(nil), context.Canceled)
+
+	// it should call the state machine with a background context when used directly.
+	sm.EXPECT().Apply(gomock.Eq(context.Background()), gomock.Any()).Return(nil)
+	require.NoError(t, eng.fsm.Apply(nil))
+
+	_, ok = AsStateMachineV2(NewMockStateMachine(ctrl))
+	require.False(t, ok)
+}
+
 func TestReportShutdown(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	node := NewMockNode(ctrl)
@@ -163,6 +335,7 @@ func TestPush(t *testing.T) {
 		ctx:     context.TODO(),
 		started: atomic.NewBool(),
 		logger:  raftlog.DefaultLogger,
+		events:  newEventRing(1),
 	}
 
 	// round #1 it return err when daemon not started
@@ -178,6 +351,7 @@ func TestPush(t *testing.T) {
 	err = eng.Push(etcdraftpb.Message{})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "buffer is full")
+	require.Equal(t, EventMessageDropped, eng.RecentEvents()[0].Type)
 
 	// round #4 it return err when ctx.Done
 	eng.ctx, eng.cancel = context.WithCancel(eng.ctx)
@@ -215,8 +389,14 @@ func TestProposeReplicate(t *testing.T) {
 		node:    node,
 		started: atomic.NewBool(),
 		msgbus:  msgbus.New(),
-		ctx:     context.TODO(),
+		alarms:  newAlarms(),
+		nospace: atomic.NewBool(),
+		audit:   newAuditLog(0),
+		propq:   newProposalQueue(1),
 	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+	defer eng.cancel()
+	eng.scheduleProposals()
 
 	// round #1 it return err when daemon not started
 	err := eng.ProposeReplicate(context.TODO(), data)
@@ -229,27 +409,112 @@ func TestProposeReplicate(t *testing.T) {
 	err = eng.ProposeReplicate(context.TODO(), data)
 	require.Equal(t, expected, err)
 
-	// round #3 it return ctx done
+	// round #3 it return proposal dropped when ctx done before proposing
 	node = NewMockNode(ctrl)
-	node.EXPECT().Propose(gomock.Any(), gomock.Any()).Return(nil)
 	eng.node = node
 	ctx, cancel := context.WithCancel(context.TODO())
 	cancel()
 	err = eng.ProposeReplicate(ctx, data)
-	require.Equal(t, context.Canceled, err)
+	require.ErrorIs(t, err, ErrProposalDropped)
+	require.NotErrorIs(t, err, context.Canceled)
+
+	// round #4 it return proposal dropped when raft drops the proposal
+	node.EXPECT().Propose(gomock.Any(), gomock.Any()).Return(raft.ErrProposalDropped)
+	err = eng.ProposeReplicate(context.TODO(), data)
+	require.ErrorIs(t, err, ErrProposalDropped)
+
+	// round #5 it return ctx err when ctx done after proposing, the proposal may still commit
+	node.EXPECT().Propose(gomock.Any(), gomock.Any()).Return(nil)
+	ctx, cancel = context.WithTimeout(context.TODO(), time.Millisecond*10)
+	defer cancel()
+	err = eng.ProposeReplicate(ctx, data)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, 0, eng.Waiters())
+
+	// round #6 it return err when nospace alarm active
+	eng.alarms.apply(raftpb.AlarmChange{
+		Alarm: raftpb.Alarm{ID: 1, Type: raftpb.NoSpaceAlarm},
+	})
+	err = eng.ProposeReplicate(context.TODO(), data)
+	require.Equal(t, ErrNoSpace, err)
+}
+
+func TestProposeAlarm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	node := NewMockNode(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	pool.EXPECT().Members().Return([]membership.Member{mem}).AnyTimes()
+	version := ProtocolVersion
+	mem.EXPECT().Raw().DoAndReturn(func() raftpb.Member {
+		return raftpb.Member{ID: 1, Version: version}
+	}).AnyTimes()
+	eng := &engine{
+		logger:  raftlog.DefaultLogger,
+		idgen:   idutil.NewGenerator(1, time.Now()),
+		node:    node,
+		pool:    pool,
+		started: atomic.NewBool(),
+		msgbus:  msgbus.New(),
+		alarms:  newAlarms(),
+		audit:   newAuditLog(0),
+		propq:   newProposalQueue(1),
+	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+	defer eng.cancel()
+	eng.scheduleProposals()
+
+	ac := raftpb.AlarmChange{
+		Alarm: raftpb.Alarm{ID: 1, Type: raftpb.NoSpaceAlarm},
+	}
+
+	// round #1 it return err when daemon not started
+	err := eng.ProposeAlarm(context.TODO(), ac)
+	require.Equal(t, ErrStopped, err)
+
+	// round #2 it propose alarm replicate type.
+	eng.started.Set()
+	node.EXPECT().Propose(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, data []byte) error {
+		r := new(raftpb.Replicate)
+		pbutil.MustUnmarshal(r, data)
+		require.Equal(t, raftpb.ReplicateAlarm, r.Type)
+		return errors.New("TestProposeAlarm Error")
+	})
+	err = eng.ProposeAlarm(context.TODO(), ac)
+	require.Error(t, err)
+
+	// round #3 it return err when a member does not support the alarms.
+	version = 0
+	err = eng.ProposeAlarm(context.TODO(), ac)
+	require.ErrorIs(t, err, ErrFeatureUnsupported)
 }
 
 func TestProposeConfChange(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	node := NewMockNode(ctrl)
+	cfg := NewMockConfig(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	cfg.EXPECT().TickInterval().Return(time.Second).AnyTimes()
+	pool.EXPECT().Members().Return([]membership.Member{mem}).AnyTimes()
+	version := uint32(0)
+	mem.EXPECT().Raw().DoAndReturn(func() raftpb.Member {
+		return raftpb.Member{ID: 2, Version: version}
+	}).AnyTimes()
 	eng := &engine{
+		cfg:     cfg,
 		logger:  raftlog.DefaultLogger,
 		idgen:   idutil.NewGenerator(1, time.Now()),
 		node:    node,
+		pool:    pool,
 		started: atomic.NewBool(),
 		msgbus:  msgbus.New(),
-		ctx:     context.TODO(),
+		local:   &raftpb.Member{ID: 2},
+		propq:   newProposalQueue(1),
 	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+	defer eng.cancel()
+	eng.scheduleProposals()
 
 	// round #1 it return err when daemon not started
 	err := eng.ProposeConfChange(context.TODO(), nil, etcdraftpb.ConfChangeAddNode)
@@ -263,13 +528,43 @@ func TestProposeConfChange(t *testing.T) {
 	require.Equal(t, expected, err)
 
 	// round #3 it return ctx done
-	node = NewMockNode(ctrl)
 	node.EXPECT().ProposeConfChange(gomock.Any(), gomock.Any()).Return(nil)
-	eng.node = node
 	ctx, cancel := context.WithCancel(context.TODO())
 	cancel()
 	err = eng.ProposeConfChange(ctx, &raftpb.Member{}, etcdraftpb.ConfChangeAddNode)
 	require.Equal(t, context.Canceled, err)
+
+	// round #4 it propose the audit record in its own entry, the member carried as is.
+	version = ProtocolVersion
+	done := make(chan struct{})
+	node.EXPECT().ProposeConfChange(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, cc etcdraftpb.ConfChangeI) error {
+			v1, _ := cc.AsV1()
+			m := new(raftpb.Member)
+			pbutil.MustUnmarshal(m, v1.Context)
+			require.Equal(t, raftpb.Member{ID: 3}, *m)
+			return nil
+		},
+	)
+	node.EXPECT().Propose(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, data []byte) error {
+		defer close(done)
+		r := new(raftpb.Replicate)
+		pbutil.MustUnmarshal(r, data)
+		require.Equal(t, raftpb.ReplicateAudit, r.Type)
+		rec := new(raftpb.AuditRecord)
+		pbutil.MustUnmarshal(rec, r.Data)
+		require.Equal(t, raftpb.AuditConfChange, rec.Action)
+		require.Equal(t, uint64(2), rec.Member)
+		require.Equal(t, map[string]string{"k": "v"}, rec.Metadata)
+		return expected
+	})
+	ctx, cancel = context.WithCancel(ContextWithMetadata(context.TODO(), map[string]string{"k": "v"}))
+	cancel()
+	err = eng.ProposeConfChange(ctx, &raftpb.Member{ID: 3}, etcdraftpb.ConfChangeAddNode)
+	require.Equal(t, context.Canceled, err)
+	<-done
+	eng.cancel()
+	eng.wg.Wait()
 }
 
 func TestTransferLeadership(t *testing.T) {
@@ -277,15 +572,24 @@ func TestTransferLeadership(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	cfg := NewMockConfig(ctrl)
 	node := NewMockNode(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
 	cfg.EXPECT().TickInterval().Return(time.Second).AnyTimes()
+	pool.EXPECT().Members().AnyTimes()
 
 	eng := &engine{
 		logger:  raftlog.DefaultLogger,
 		started: atomic.NewBool(),
 		node:    node,
 		cfg:     cfg,
-		ctx:     context.TODO(),
+		pool:    pool,
+		local:   &raftpb.Member{ID: 2},
+		idgen:   idutil.NewGenerator(2, time.Now()),
+		msgbus:  msgbus.New(),
+		propq:   newProposalQueue(1),
 	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+	defer eng.cancel()
+	eng.scheduleProposals()
 
 	// round #1 it return err when daemon not started.
 	err := eng.TransferLeadership(context.TODO(), id)
@@ -318,9 +622,23 @@ func TestTransferLeadership(t *testing.T) {
 		return raft.Status{}
 	})
 	node.EXPECT().TransferLeadership(gomock.Any(), gomock.Any(), gomock.Eq(id))
+	// it record the transfer into the audit log, without blocking the caller.
+	proposed := make(chan struct{})
+	node.EXPECT().Propose(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, data []byte) error {
+		defer close(proposed)
+		r := new(raftpb.Replicate)
+		pbutil.MustUnmarshal(r, data)
+		require.Equal(t, raftpb.ReplicateAudit, r.Type)
+		rec := new(raftpb.AuditRecord)
+		pbutil.MustUnmarshal(rec, r.Data)
+		require.Equal(t, raftpb.AuditTransferLeadership, rec.Action)
+		require.Equal(t, uint64(2), rec.Member)
+		return errors.New("TestTransferLeadership Error")
+	})
 	eng.node = node
 	err = eng.TransferLeadership(context.TODO(), id)
 	require.NoError(t, err)
+	<-proposed
 }
 
 func TestLinearizableRead(t *testing.T) {
@@ -426,7 +744,7 @@ func TestLocalCreateSnapshot(t *testing.T) {
 	expectedErr := errors.New("TestCreateSnapshot")
 	ctrl := gomock.NewController(t)
 	cfg := NewMockConfig(ctrl)
-	cfg.EXPECT().SnapInterval().Return(uint64(1))
+	cfg.EXPECT().RetainEntries().Return(uint64(1))
 	eng := &engine{
 		logger:       raftlog.DefaultLogger,
 		cfg:          cfg,
@@ -434,6 +752,8 @@ func TestLocalCreateSnapshot(t *testing.T) {
 		appliedIndex: atomic.NewUint64(),
 		snapIndex:    atomic.NewUint64(),
 		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
 		snapshoting:  atomic.NewBool(),
 	}
 
@@ -471,6 +791,243 @@ func TestLocalCreateSnapshot(t *testing.T) {
 	require.Equal(t, uint64(1), eng.snapIndex.Get())
 }
 
+func TestLocalCreateSnapshotRetainEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := NewMockConfig(ctrl)
+	cfg.EXPECT().RetainEntries().Return(uint64(2))
+	pool := membershipmock.NewMockPool(ctrl)
+	pool.EXPECT().Snapshot().Return(nil)
+	stg := storagemock.NewMockStorage(ctrl)
+	shotter := storagemock.NewMockSnapshotter(ctrl)
+	stg.EXPECT().Snapshotter().Return(shotter)
+	stg.EXPECT().SaveSnapshot(gomock.Any()).Return(nil)
+	shotter.EXPECT().Write(gomock.Any()).Return(nil)
+	fsm := NewMockStateMachine(ctrl)
+	fsm.EXPECT().Snapshot().Return(nil, nil)
+
+	eng := &engine{
+		logger:       raftlog.DefaultLogger,
+		cfg:          cfg,
+		fsm:          fsm,
+		pool:         pool,
+		storage:      stg,
+		appliedIndex: atomic.NewUint64(),
+		snapIndex:    atomic.NewUint64(),
+		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
+		snapshoting:  atomic.NewBool(),
+	}
+
+	for i := uint64(1); i <= 5; i++ {
+		_ = eng.cache.Append([]etcdraftpb.Entry{{Index: i, Term: 1}})
+	}
+
+	// it keep the trailing entries after the snapshot.
+	eng.appliedIndex.Set(5)
+	require.NoError(t, eng.createSnapshot())
+	eng.wg.Wait()
+
+	first, err := eng.cache.FirstIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), first)
+}
+
+func TestLocalCreateDeltaSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := NewMockConfig(ctrl)
+	cfg.EXPECT().SnapInterval().Return(uint64(10)).AnyTimes()
+	cfg.EXPECT().RetainEntries().Return(uint64(10)).AnyTimes()
+	cfg.EXPECT().MaxSnapshotDeltas().Return(1).AnyTimes()
+	pool := membershipmock.NewMockPool(ctrl)
+	pool.EXPECT().Snapshot().Return(nil).AnyTimes()
+	stg := storagemock.NewMockStorage(ctrl)
+	shotter := storagemock.NewMockSnapshotter(ctrl)
+	stg.EXPECT().Snapshotter().Return(shotter).AnyTimes()
+	stg.EXPECT().SaveSnapshot(gomock.Any()).Return(nil).AnyTimes()
+	fsm := NewMockIncrementalStateMachine(ctrl)
+
+	eng := &engine{
+		logger:       raftlog.DefaultLogger,
+		cfg:          cfg,
+		fsm:          fsm,
+		pool:         pool,
+		storage:      stg,
+		appliedIndex: atomic.NewUint64(),
+		snapIndex:    atomic.NewUint64(),
+		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
+		snapshoting:  atomic.NewBool(),
+	}
+
+	_ = eng.cache.Append([]etcdraftpb.Entry{{Index: 1, Term: 1}, {Index: 2, Term: 1}, {Index: 3, Term: 1}})
+	eng.setSnapshotBase(etcdraftpb.SnapshotMetadata{Index: 1, Term: 1}, 0)
+	eng.snapIndex.Set(1)
+
+	// round #1 it create a delta snapshot chained onto the latest snapshot.
+	fsm.EXPECT().IncrementalSnapshot(gomock.Eq(uint64(1))).Return(nil, nil)
+	shotter.EXPECT().Write(gomock.Any()).DoAndReturn(func(sf *storage.Snapshot) error {
+		require.Equal(t, uint64(1), sf.BaseIndex)
+		require.Equal(t, uint64(1), sf.BaseTerm)
+		return nil
+	})
+
+	eng.appliedIndex.Set(2)
+	err := eng.createSnapshot()
+	require.NoError(t, err)
+	eng.wg.Wait()
+
+	base, deltas := eng.snapshotBase()
+	require.Equal(t, uint64(2), base.Index)
+	require.Equal(t, 1, deltas)
+
+	// round #2 it create a full snapshot when reaching max deltas.
+	fsm.EXPECT().Snapshot().Return(nil, nil)
+	shotter.EXPECT().Write(gomock.Any()).DoAndReturn(func(sf *storage.Snapshot) error {
+		require.Zero(t, sf.BaseIndex)
+		return nil
+	})
+
+	eng.appliedIndex.Set(3)
+	err = eng.createSnapshot()
+	require.NoError(t, err)
+	eng.wg.Wait()
+
+	base, deltas = eng.snapshotBase()
+	require.Equal(t, uint64(3), base.Index)
+	require.Equal(t, 0, deltas)
+}
+
+func TestLocalCreateTwoPhaseSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := NewMockConfig(ctrl)
+	cfg.EXPECT().SnapInterval().Return(uint64(10)).AnyTimes()
+	cfg.EXPECT().RetainEntries().Return(uint64(10)).AnyTimes()
+	cfg.EXPECT().MaxSnapshotDeltas().Return(0).AnyTimes()
+	pool := membershipmock.NewMockPool(ctrl)
+	pool.EXPECT().Snapshot().Return(nil).AnyTimes()
+	stg := storagemock.NewMockStorage(ctrl)
+	shotter := storagemock.NewMockSnapshotter(ctrl)
+	stg.EXPECT().Snapshotter().Return(shotter).AnyTimes()
+	stg.EXPECT().SaveSnapshot(gomock.Any()).Return(nil).AnyTimes()
+	fsm := NewMockTwoPhaseStateMachine(ctrl)
+	src := NewMockSnapshotSource(ctrl)
+
+	eng := &engine{
+		logger:       raftlog.DefaultLogger,
+		cfg:          cfg,
+		fsm:          fsm,
+		pool:         pool,
+		storage:      stg,
+		appliedIndex: atomic.NewUint64(),
+		snapIndex:    atomic.NewUint64(),
+		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
+		snapshoting:  atomic.NewBool(),
+	}
+
+	_ = eng.cache.Append([]etcdraftpb.Entry{{Index: 1, Term: 1}, {Index: 2, Term: 1}})
+
+	// it serialize the prepared snapshot source then release it.
+	gomock.InOrder(
+		fsm.EXPECT().PrepareSnapshot().Return(src, nil),
+		src.EXPECT().Reader().Return(io.NopCloser(strings.NewReader("data")), nil),
+		shotter.EXPECT().Write(gomock.Any()).DoAndReturn(func(sf *storage.Snapshot) error {
+			buf, err := io.ReadAll(sf.Data)
+			require.Equal(t, "data", string(buf))
+			return err
+		}),
+		src.EXPECT().Release(),
+	)
+
+	eng.appliedIndex.Set(1)
+	require.NoError(t, eng.createSnapshot())
+	eng.wg.Wait()
+	require.Equal(t, uint64(1), eng.snapIndex.Get())
+
+	// it release the snapshot source when serialization fails.
+	gomock.InOrder(
+		fsm.EXPECT().PrepareSnapshot().Return(src, nil),
+		src.EXPECT().Reader().Return(nil, ErrStopped),
+		src.EXPECT().Release(),
+	)
+
+	eng.appliedIndex.Set(2)
+	require.NoError(t, eng.createSnapshot())
+	eng.wg.Wait()
+	require.Equal(t, uint64(1), eng.snapIndex.Get())
+}
+
+func TestSnapshotEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := NewMockConfig(ctrl)
+	cfg.EXPECT().SnapInterval().Return(uint64(10)).AnyTimes()
+	cfg.EXPECT().RetainEntries().Return(uint64(10)).AnyTimes()
+	cfg.EXPECT().MaxSnapshotDeltas().Return(0).AnyTimes()
+	pool := membershipmock.NewMockPool(ctrl)
+	pool.EXPECT().Snapshot().Return(nil).AnyTimes()
+	stg := storagemock.NewMockStorage(ctrl)
+	shotter := storagemock.NewMockSnapshotter(ctrl)
+	stg.EXPECT().Snapshotter().Return(shotter).AnyTimes()
+	stg.EXPECT().SaveSnapshot(gomock.Any()).Return(nil).AnyTimes()
+	fsm := NewMockStateMachine(ctrl)
+
+	eng := &engine{
+		logger:       raftlog.DefaultLogger,
+		cfg:          cfg,
+		fsm:          fsm,
+		pool:         pool,
+		storage:      stg,
+		appliedIndex: atomic.NewUint64(),
+		snapIndex:    atomic.NewUint64(),
+		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
+		snapshoting:  atomic.NewBool(),
+		snapEventCh:  make(chan SnapshotEvent, 1),
+	}
+
+	_ = eng.cache.Append([]etcdraftpb.Entry{{Index: 1, Term: 1}, {Index: 2, Term: 1}})
+
+	// round #1 it emit an event when creating a snapshot.
+	fsm.EXPECT().Snapshot().Return(io.NopCloser(strings.NewReader("data")), nil)
+	shotter.EXPECT().Write(gomock.Any()).DoAndReturn(func(sf *storage.Snapshot) error {
+		_, err := io.ReadAll(sf.Data)
+		return err
+	})
+
+	eng.appliedIndex.Set(2)
+	require.NoError(t, eng.createSnapshot())
+	eng.wg.Wait()
+
+	ev := <-eng.snapEventCh
+	require.Equal(t, SnapshotCreated, ev.Type)
+	require.Equal(t, uint64(2), ev.Index)
+	require.Equal(t, int64(4), ev.Bytes)
+	require.NoError(t, ev.Err)
+
+	// round #2 it emit an event when sending a snapshot.
+	meta := etcdraftpb.SnapshotMetadata{Term: 1, Index: 2}
+	shotter.EXPECT().Chain(uint64(1), uint64(2)).Return([]etcdraftpb.SnapshotMetadata{meta}, nil)
+	shotter.EXPECT().Offset(uint64(1), uint64(2)).Return(uint64(10), nil)
+
+	eng.ReportSnapshotTransfer(3, meta, time.Second, ErrStopped)
+	ev = <-eng.snapEventCh
+	require.Equal(t, SnapshotSent, ev.Type)
+	require.Equal(t, uint64(3), ev.Member)
+	require.Equal(t, int64(10), ev.Bytes)
+	require.Equal(t, time.Second, ev.Duration)
+	require.Equal(t, ErrStopped, ev.Err)
+
+	// round #3 it drop events when the channel is full.
+	shotter.EXPECT().Chain(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	eng.ReportSnapshotTransfer(3, meta, time.Second, nil)
+	eng.ReportSnapshotTransfer(3, meta, time.Second, nil)
+	require.Len(t, eng.snapEventCh, 1)
+}
+
 func TestEventLoop(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	count := 0
@@ -490,6 +1047,7 @@ func TestEventLoop(t *testing.T) {
 
 	cfg.EXPECT().TickInterval().Return(time.Millisecond * 100)
 	cfg.EXPECT().SnapInterval().Return(uint64(100))
+	cfg.EXPECT().DisableAutoPromotion()
 	node.EXPECT().Advance()
 	node.EXPECT().Status()
 	node.EXPECT().Ready().Return(readyc).AnyTimes()
@@ -517,7 +1075,7 @@ func TestPublishReadState(t *testing.T) {
 		RequestCtx: buf,
 	}
 	bus := msgbus.New()
-	sub := bus.SubscribeOnce(sid)
+	sub, _ := bus.SubscribeOnce(sid)
 	eng := &engine{
 		msgbus: bus,
 	}
@@ -529,11 +1087,12 @@ func TestPublishReadState(t *testing.T) {
 func TestPublishAppliedIndices(t *testing.T) {
 	bus := msgbus.New()
 	eng := &engine{
-		msgbus: bus,
+		msgbus:  bus,
+		applied: new(appliedNotifier),
 	}
 
-	s1 := bus.SubscribeOnce(2)
-	s2 := bus.SubscribeOnce(3)
+	s1, _ := bus.SubscribeOnce(2)
+	s2, _ := bus.SubscribeOnce(3)
 
 	eng.publishAppliedIndices(1, 3)
 
@@ -544,6 +1103,21 @@ func TestPublishAppliedIndices(t *testing.T) {
 	require.Nil(t, v1)
 }
 
+func TestWaitOverloaded(t *testing.T) {
+	bus := msgbus.New(msgbus.WithCapacity(1))
+	eng := &engine{
+		msgbus: bus,
+		logger: raftlog.DefaultLogger,
+	}
+
+	_, err := bus.SubscribeOnce(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, eng.Waiters())
+
+	err = eng.wait(context.Background(), 2)
+	require.ErrorIs(t, err, ErrOverloaded)
+}
+
 func TestPublishSnapshot(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	stg := storagemock.NewMockStorage(ctrl)
@@ -565,13 +1139,15 @@ func TestPublishSnapshot(t *testing.T) {
 
 	stg.EXPECT().SaveSnapshot(gomock.Any()).Return(nil)
 	stg.EXPECT().Snapshotter().Return(shotter)
-	shotter.EXPECT().Read(gomock.Any(), gomock.Any()).Return(sf, nil)
+	shotter.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any()).Return(sf, nil)
 	pool.EXPECT().Restore(gomock.Any())
 	fsm.EXPECT().Restore(gomock.Any()).Return(nil)
 
 	eng := &engine{
 		logger:       raftlog.DefaultLogger,
 		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
 		storage:      stg,
 		appliedIndex: atomic.NewUint64(),
 		snapIndex:    atomic.NewUint64(),
@@ -594,6 +1170,74 @@ func TestPublishSnapshot(t *testing.T) {
 	require.Equal(t, snap.Metadata.Index, eng.appliedIndex.Get())
 }
 
+func TestPublishDeltaSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stg := storagemock.NewMockStorage(ctrl)
+	shotter := storagemock.NewMockSnapshotter(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+
+	newSnapshot := func(index, base uint64) *storage.Snapshot {
+		return &storage.Snapshot{
+			SnapshotState: raftpb.SnapshotState{
+				Raw: etcdraftpb.Snapshot{
+					Metadata: etcdraftpb.SnapshotMetadata{Index: index, Term: 1},
+				},
+				BaseIndex: base,
+				BaseTerm:  1,
+			},
+			Data: io.NopCloser(strings.NewReader(strconv.FormatUint(index, 10))),
+		}
+	}
+
+	sf := newSnapshot(3, 2)
+	chain := []etcdraftpb.SnapshotMetadata{{Index: 1, Term: 1}, {Index: 2, Term: 1}, {Index: 3, Term: 1}}
+
+	stg.EXPECT().Snapshotter().Return(shotter).AnyTimes()
+	pool.EXPECT().Restore(gomock.Any()).AnyTimes()
+
+	eng := &engine{
+		logger:       raftlog.DefaultLogger,
+		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
+		storage:      stg,
+		appliedIndex: atomic.NewUint64(),
+		snapIndex:    atomic.NewUint64(),
+		pool:         pool,
+		fsm:          NewMockStateMachine(ctrl),
+	}
+
+	// round #1 it return err when fsm does not support delta snapshots.
+	err := eng.publishSnapshotFile(sf)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not implement IncrementalStateMachine")
+
+	// round #2 it restore the snapshots chain in order.
+	restored := []string{}
+	read := func(r io.ReadCloser) error {
+		buf, err := io.ReadAll(r)
+		restored = append(restored, string(buf))
+		return err
+	}
+
+	fsm := NewMockIncrementalStateMachine(ctrl)
+	fsm.EXPECT().Restore(gomock.Any()).DoAndReturn(read)
+	fsm.EXPECT().RestoreIncremental(gomock.Any()).DoAndReturn(read).Times(2)
+	shotter.EXPECT().Chain(gomock.Eq(uint64(1)), gomock.Eq(uint64(3))).Return(chain, nil)
+	shotter.EXPECT().Read(gomock.Any(), gomock.Eq(uint64(1)), gomock.Eq(uint64(1))).Return(newSnapshot(1, 0), nil)
+	shotter.EXPECT().Read(gomock.Any(), gomock.Eq(uint64(1)), gomock.Eq(uint64(2))).Return(newSnapshot(2, 1), nil)
+
+	eng.fsm = fsm
+	eng.cache = raft.NewMemoryStorage()
+	err = eng.publishSnapshotFile(sf)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2", "3"}, restored)
+
+	base, deltas := eng.snapshotBase()
+	require.Equal(t, uint64(3), base.Index)
+	require.Equal(t, 2, deltas)
+}
+
 func TestPublishReplicate(t *testing.T) {
 	sid := uint64(1)
 	data := []byte("testData")
@@ -604,7 +1248,7 @@ func TestPublishReplicate(t *testing.T) {
 		fsm:    fsm,
 		msgbus: msgbus.New(),
 	}
-	sub := eng.msgbus.SubscribeOnce(sid)
+	sub, _ := eng.msgbus.SubscribeOnce(sid)
 	rp := &raftpb.Replicate{
 		Data: data,
 		CID:  sid,
@@ -618,6 +1262,161 @@ func TestPublishReplicate(t *testing.T) {
 	require.Nil(t, v)
 }
 
+func TestPublishReplicateMetadata(t *testing.T) {
+	md := map[string]string{"request-id": "1"}
+	data := []byte("testData")
+	ctrl := gomock.NewController(t)
+	fsm := NewMockStateMachine(ctrl)
+	sm := NewMockStateMachineV2(ctrl)
+	mfsm := &metadataFSM{StateMachine: fsm}
+	events := []ApplyEvent{}
+	eng := &engine{
+		ctx:    context.Background(),
+		logger: raftlog.DefaultLogger,
+		msgbus: msgbus.New(),
+		applyHook: func(ev ApplyEvent) {
+			events = append(events, ev)
+		},
+	}
+	ent := etcdraftpb.Entry{
+		Term:  1,
+		Index: 2,
+		Data:  pbutil.MustMarshal(&raftpb.Replicate{Data: data, Metadata: md}),
+	}
+
+	// it should hand the metadata to a metadata state machine.
+	eng.fsm = mfsm
+	eng.publishReplicate(ent)
+	require.Equal(t, md, mfsm.md)
+
+	// it should hand the metadata to a state machine v2 within the context.
+	eng.fsm = FromStateMachineV2(sm)
+	sm.EXPECT().Apply(gomock.Any(), gomock.Eq(data)).DoAndReturn(func(ctx context.Context, _ []byte) error {
+		require.Equal(t, md, MetadataFromContext(ctx))
+		return nil
+	})
+	eng.publishReplicate(ent)
+
+	// it should call the apply hook.
+	require.Len(t, events, 2)
+	require.Equal(t, ApplyEvent{Term: 1, Index: 2, Metadata: md}, events[0])
+}
+
+func TestSlowOps(t *testing.T) {
+	events := []SlowOpEvent{}
+	eng := &engine{
+		logger:      raftlog.DefaultLogger,
+		slowApply:   time.Millisecond,
+		slowSync:    time.Second,
+		slowApplies: atomic.NewUint64(),
+		slowSyncs:   atomic.NewUint64(),
+		slowOpHook: func(ev SlowOpEvent) {
+			events = append(events, ev)
+		},
+	}
+
+	// it should ignore fast operations.
+	eng.observeApply(1, time.Microsecond)
+	eng.observeSync(etcdraftpb.HardState{Commit: 1}, nil, time.Millisecond)
+	require.Empty(t, events)
+
+	// it should ignore empty syncs.
+	eng.observeSync(etcdraftpb.HardState{}, nil, time.Second*2)
+	require.Empty(t, events)
+
+	// it should report slow operations.
+	eng.observeApply(2, time.Millisecond*2)
+	eng.observeSync(etcdraftpb.HardState{Commit: 2}, []etcdraftpb.Entry{{Index: 3}, {Index: 4}}, time.Second*2)
+	require.Equal(t, []SlowOpEvent{
+		{Type: SlowApply, Index: 2, Duration: time.Millisecond * 2},
+		{Type: SlowSync, Index: 4, Duration: time.Second * 2},
+	}, events)
+	require.Equal(t, SlowOps{Applies: 1, Syncs: 1}, eng.SlowOps())
+
+	// it should not report when disabled.
+	eng.slowApply = 0
+	eng.observeApply(5, time.Hour)
+	require.Equal(t, uint64(1), eng.SlowOps().Applies)
+}
+
+func TestProposeReplicateMetadataTooLarge(t *testing.T) {
+	eng := &engine{
+		started: atomic.NewBool(),
+		alarms:  newAlarms(),
+		nospace: atomic.NewBool(),
+		audit:   newAuditLog(0),
+	}
+	eng.started.Set()
+
+	md := map[string]string{"key": string(make([]byte, MaxMetadataSize))}
+	err := eng.ProposeReplicate(ContextWithMetadata(context.Background(), md), nil)
+	require.ErrorIs(t, err, ErrMetadataTooLarge)
+}
+
+type metadataFSM struct {
+	StateMachine
+	md map[string]string
+}
+
+func (m *metadataFSM) ApplyWithMetadata(data []byte, md map[string]string) error {
+	m.md = md
+	return nil
+}
+
+func TestPublishAudit(t *testing.T) {
+	eng := &engine{
+		logger: raftlog.DefaultLogger,
+		msgbus: msgbus.New(),
+		audit:  newAuditLog(0),
+	}
+
+	rec := raftpb.AuditRecord{
+		Member:   1,
+		Action:   raftpb.AuditConfChange,
+		Detail:   "TestPublishAudit",
+		Metadata: map[string]string{"principal": "admin"},
+	}
+	ent := etcdraftpb.Entry{
+		Index: 5,
+		Data: pbutil.MustMarshal(&raftpb.Replicate{
+			Type: raftpb.ReplicateAudit,
+			Data: pbutil.MustMarshal(&rec),
+		}),
+	}
+
+	eng.publishReplicate(ent)
+	rec.Index = 5
+	require.Equal(t, []raftpb.AuditRecord{rec}, eng.AuditLog(0))
+	require.Empty(t, eng.AuditLog(5))
+}
+
+func TestPublishAlarm(t *testing.T) {
+	sid := uint64(1)
+	eng := &engine{
+		logger: raftlog.DefaultLogger,
+		msgbus: msgbus.New(),
+		alarms: newAlarms(),
+		audit:  newAuditLog(0),
+	}
+	sub, _ := eng.msgbus.SubscribeOnce(sid)
+	ac := &raftpb.AlarmChange{
+		Action: raftpb.ActivateAlarm,
+		Alarm:  raftpb.Alarm{ID: 2, Type: raftpb.NoSpaceAlarm},
+	}
+	rp := &raftpb.Replicate{
+		CID:  sid,
+		Type: raftpb.ReplicateAlarm,
+		Data: pbutil.MustMarshal(ac),
+	}
+	ent := etcdraftpb.Entry{
+		Data: pbutil.MustMarshal(rp),
+	}
+	eng.publishReplicate(ent)
+	v := <-sub.Chan()
+	require.Nil(t, v)
+	require.True(t, eng.alarms.exist(ac.Alarm))
+}
+
 func TestPublishConfChange(t *testing.T) {
 	closedc := make(chan struct{})
 	close(closedc)
@@ -631,7 +1430,7 @@ func TestPublishConfChange(t *testing.T) {
 			change: etcdraftpb.ConfChangeAddNode,
 			expect: func(ctrl *gomock.Controller, d *engine) <-chan struct{} {
 				pool := membershipmock.NewMockPool(ctrl)
-				pool.EXPECT().Add(gomock.Any()).MinTimes(1).Return(ErrStopped)
+				pool.EXPECT().Add(gomock.Eq(raftpb.Member{ID: 1})).MinTimes(1).Return(ErrStopped)
 				d.pool = pool
 				return closedc
 			},
@@ -673,18 +1472,18 @@ func TestPublishConfChange(t *testing.T) {
 			node:   node,
 			msgbus: msgbus.New(),
 			ctx:    context.TODO(),
+			audit:  newAuditLog(0),
 		}
-		sub := eng.msgbus.SubscribeOnce(sid)
-		mem := &raftpb.Member{
-			ID: 1,
-		}
+		sub, _ := eng.msgbus.SubscribeOnce(sid)
+		mem := &raftpb.Member{ID: 1}
 		cc := &etcdraftpb.ConfChange{
 			Type:    tt.change,
 			ID:      sid,
 			Context: pbutil.MustMarshal(mem),
 		}
 		ent := etcdraftpb.Entry{
-			Data: pbutil.MustMarshal(cc),
+			Index: 5,
+			Data:  pbutil.MustMarshal(cc),
 		}
 
 		node.EXPECT().ApplyConfChange(gomock.Eq(cc))
@@ -693,6 +1492,9 @@ func TestPublishConfChange(t *testing.T) {
 		v := <-sub.Chan()
 		require.Equal(t, tt.err, v)
 		<-wait
+
+		// the audit record proposed in its own entry, not carried by the conf change.
+		require.Empty(t, eng.audit.snapshot())
 		ctrl.Finish()
 	}
 }
@@ -761,6 +1563,7 @@ func TestPromotions(t *testing.T) {
 		cfg:     cfg,
 		idgen:   idutil.NewGenerator(1, time.Now()),
 		started: atomic.NewBool(),
+		local:   &raftpb.Member{ID: 1},
 	}
 	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
 	rs := raft.Status{
@@ -774,11 +1577,26 @@ func TestPromotions(t *testing.T) {
 	}
 
 	cfg.EXPECT().TickInterval().Return(time.Duration(-1))
-	voter.EXPECT().Raw().Return(raftpb.Member{ID: 1})
-	voter.EXPECT().IsActive().Return(true)
-	staging.EXPECT().Raw().Return(raftpb.Member{ID: 2, Type: raftpb.StagingMember})
-	staging.EXPECT().IsActive().Return(true)
-	pool.EXPECT().Members().Return([]membership.Member{voter, staging})
+	voter.EXPECT().Raw().Return(raftpb.Member{ID: 1}).AnyTimes()
+	voter.EXPECT().IsActive().Return(true).AnyTimes()
+	staging.EXPECT().Raw().Return(raftpb.Member{ID: 2, Type: raftpb.StagingMember}).AnyTimes()
+	staging.EXPECT().IsActive().Return(true).AnyTimes()
+	staging.EXPECT().ActiveSince().Return(time.Now()).AnyTimes()
+	pool.EXPECT().Members().Return([]membership.Member{voter, staging}).AnyTimes()
+
+	// round #1 it does not promote staging members when auto promotion disabled.
+	cfg.EXPECT().DisableAutoPromotion().Return(true)
+	eng.promotions()
+
+	// round #2 it does not promote staging members not healthy for long enough.
+	cfg.EXPECT().DisableAutoPromotion().Return(false)
+	cfg.EXPECT().PromotionMinHealthy().Return(time.Hour)
+	node.EXPECT().Status().Return(rs)
+	eng.promotions()
+
+	// round #3 it promote staging members.
+	cfg.EXPECT().DisableAutoPromotion().Return(false)
+	cfg.EXPECT().PromotionMinHealthy().Return(time.Duration(0))
 	node.EXPECT().Status().Return(rs)
 	node.
 		EXPECT().
@@ -790,10 +1608,186 @@ func TestPromotions(t *testing.T) {
 	ctrl.Finish()
 }
 
+func TestRemoveDeadMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	node := NewMockNode(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	cfg := NewMockConfig(ctrl)
+	membs := []*membershipmock.MockMember{}
+	active := map[uint64]bool{1: true, 2: true}
+
+	for i := uint64(1); i <= 3; i++ {
+		id := i
+		m := membershipmock.NewMockMember(ctrl)
+		m.EXPECT().Raw().Return(raftpb.Member{ID: id, Type: raftpb.VoterMember}).AnyTimes()
+		m.EXPECT().IsActive().DoAndReturn(func() bool { return active[id] }).AnyTimes()
+		membs = append(membs, m)
+	}
+
+	eng := &engine{
+		logger:      raftlog.DefaultLogger,
+		node:        node,
+		pool:        pool,
+		cfg:         cfg,
+		idgen:       idutil.NewGenerator(1, time.Now()),
+		membEventCh: make(chan MemberEvent, 1),
+		local:       &raftpb.Member{ID: 1},
+	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+	rs := raft.Status{
+		BasicStatus: raft.BasicStatus{
+			ID: 1,
+		},
+		Progress: map[uint64]tracker.Progress{
+			1: {},
+			2: {},
+			3: {},
+		},
+	}
+
+	cfg.EXPECT().TickInterval().Return(time.Second).AnyTimes()
+	pool.EXPECT().Members().Return([]membership.Member{membs[0], membs[1], membs[2]}).AnyTimes()
+	unreachables := make(map[uint64]time.Time)
+
+	// it skip removing members when quorum at risk.
+	active[2] = false
+	node.EXPECT().Status().Return(rs)
+	eng.removeDeadMembers(unreachables, 0)
+	require.Len(t, unreachables, 2)
+
+	// it forget members unreachable when not the leader.
+	node.EXPECT().Status().Return(raft.Status{})
+	eng.removeDeadMembers(unreachables, 0)
+	require.Empty(t, unreachables)
+
+	// it skip removing members not yet exceeding the timeout.
+	active[2] = true
+	node.EXPECT().Status().Return(rs)
+	eng.removeDeadMembers(unreachables, time.Hour)
+	require.Contains(t, unreachables, uint64(3))
+
+	// it propose to remove members exceeding the timeout.
+	unreachables[3] = time.Now().Add(-2 * time.Hour)
+	node.EXPECT().Status().Return(rs)
+	node.
+		EXPECT().
+		ProposeConfChange(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, cc etcdraftpb.ConfChangeI) error {
+			v1, _ := cc.AsV1()
+			require.Equal(t, etcdraftpb.ConfChangeRemoveNode, v1.Type)
+			require.Equal(t, uint64(3), v1.NodeID)
+			return nil
+		})
+
+	eng.removeDeadMembers(unreachables, time.Hour)
+	require.Empty(t, unreachables)
+
+	ev := <-eng.membEventCh
+	require.Equal(t, MemberDeadRemoved, ev.Type)
+	require.Equal(t, uint64(3), ev.Member.ID)
+	require.NoError(t, ev.Err)
+	require.GreaterOrEqual(t, ev.Unreachable, 2*time.Hour)
+}
+
+func TestUpdateLocalMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	node := NewMockNode(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	cfg := NewMockConfig(ctrl)
+	done := make(chan struct{})
+
+	eng := &engine{
+		logger: raftlog.DefaultLogger,
+		node:   node,
+		pool:   pool,
+		cfg:    cfg,
+		local:  &raftpb.Member{ID: 1},
+		idgen:  idutil.NewGenerator(1, time.Now()),
+	}
+	eng.ctx, eng.cancel = context.WithCancel(context.TODO())
+
+	cfg.EXPECT().TickInterval().Return(time.Millisecond).AnyTimes()
+	pool.EXPECT().Get(uint64(1)).Return(mem, true).AnyTimes()
+	// the cluster not upgraded yet, the audit record not proposed.
+	old := membershipmock.NewMockMember(ctrl)
+	old.EXPECT().Raw().Return(raftpb.Member{ID: 2}).AnyTimes()
+	pool.EXPECT().Members().Return([]membership.Member{old}).AnyTimes()
+	gomock.InOrder(
+		mem.EXPECT().Raw().Return(raftpb.Member{ID: 1, Address: ":80", Version: ProtocolVersion}),
+		mem.EXPECT().Raw().Return(raftpb.Member{ID: 1, Address: ":80"}),
+		mem.EXPECT().Raw().Return(raftpb.Member{ID: 1, Address: ":8080", Version: ProtocolVersion}),
+	)
+
+	node.
+		EXPECT().
+		ProposeConfChange(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, cc etcdraftpb.ConfChangeI) error {
+			v1, _ := cc.AsV1()
+			m := new(raftpb.Member)
+			require.NoError(t, m.Unmarshal(v1.Context))
+			require.Equal(t, etcdraftpb.ConfChangeUpdateNode, v1.Type)
+			require.Equal(t, ":8080", m.Address)
+			require.Equal(t, ProtocolVersion, m.Version)
+			return nil
+		})
+
+	// it does nothing when the address and version not changed.
+	eng.updateLocalMember("")
+	eng.wg.Wait()
+
+	// it propose to update the address and version until committed.
+	go func() {
+		eng.updateLocalMember(":8080")
+		eng.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected local address update to complete")
+	}
+}
+
+func TestSaveEntriesNoSpace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	stg := storagemock.NewMockStorage(ctrl)
+	nospace := &os.PathError{Op: "write", Path: "wal", Err: syscall.ENOSPC}
+
+	eng := &engine{
+		logger:  raftlog.DefaultLogger,
+		storage: stg,
+		nospace: atomic.NewBool(),
+		alarms:  newAlarms(),
+		started: atomic.NewBool(),
+	}
+	eng.started.Set()
+
+	// round #1 it return other errors as is.
+	expected := errors.New("TestSaveEntriesNoSpace")
+	stg.EXPECT().SaveEntries(gomock.Any(), gomock.Any()).Return(expected)
+	err := eng.saveEntries(etcdraftpb.HardState{}, nil)
+	require.Equal(t, expected, err)
+	require.False(t, eng.nospace.True())
+
+	// round #2 it flag the lack of space and return the error without retrying.
+	stg.EXPECT().SaveEntries(gomock.Any(), gomock.Any()).Return(nospace)
+	err = eng.saveEntries(etcdraftpb.HardState{}, nil)
+	require.ErrorIs(t, err, syscall.ENOSPC)
+	require.True(t, eng.nospace.True())
+
+	// round #3 it reject the local proposals.
+	err = eng.ProposeReplicate(context.TODO(), []byte("data"))
+	require.Equal(t, ErrNoSpace, err)
+}
+
 func TestCreateSnapshot(t *testing.T) {
 	eng := &engine{
 		logger:       raftlog.DefaultLogger,
 		cache:        raft.NewMemoryStorage(),
+		alarms:       newAlarms(),
+		audit:        newAuditLog(0),
 		started:      atomic.NewBool(),
 		snapIndex:    atomic.NewUint64(),
 		appliedIndex: atomic.NewUint64(),
@@ -0,0 +1,113 @@
+package raftengine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventType is the type of a significant engine event.
+type EventType int
+
+const (
+	// EventLeaderChange is recorded when a new leader elected, or the leader lost.
+	EventLeaderChange EventType = iota
+	// EventSnapshotInstalled is recorded when a snapshot received from the leader installed.
+	EventSnapshotInstalled
+	// EventConfChange is recorded when a conf change applied.
+	EventConfChange
+	// EventMessageDropped is recorded when an inbound or outbound message dropped.
+	EventMessageDropped
+	// EventCorruption is recorded when a member state machine diverged from the leader.
+	EventCorruption
+)
+
+// String returns the event type name.
+func (t EventType) String() string {
+	switch t {
+	case EventLeaderChange:
+		return "LeaderChange"
+	case EventSnapshotInstalled:
+		return "SnapshotInstalled"
+	case EventConfChange:
+		return "ConfChange"
+	case EventMessageDropped:
+		return "MessageDropped"
+	case EventCorruption:
+		return "Corruption"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event describes a significant engine event.
+type Event struct {
+	// Type of the event.
+	Type EventType
+	// Time the event recorded.
+	Time time.Time
+	// Term and Index of the event, if any.
+	Term  uint64
+	Index uint64
+	// Member is the member id the event related to, e.g. the elected leader,
+	// the conf change member, or the dropped message peer.
+	Member uint64
+	// Detail is a human readable description of the event.
+	Detail string
+}
+
+func newEventRing(size int) *eventRing {
+	if size <= 0 {
+		return nil
+	}
+
+	return &eventRing{
+		buf: make([]Event, size),
+	}
+}
+
+// eventRing retains the last recorded events, overwriting the oldest.
+type eventRing struct {
+	mu   sync.Mutex
+	buf  []Event
+	next int
+	full bool
+}
+
+// record the given event, it stamps the event time.
+func (r *eventRing) record(ev Event) {
+	if r == nil {
+		return
+	}
+
+	ev.Time = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf[r.next] = ev
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// events returns a copy of the retained events, the oldest first.
+func (r *eventRing) events() []Event {
+	if r == nil {
+		return []Event{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		list := make([]Event, r.next)
+		copy(list, r.buf[:r.next])
+		return list
+	}
+
+	list := make([]Event, 0, len(r.buf))
+	list = append(list, r.buf[r.next:]...)
+	return append(list, r.buf[:r.next]...)
+}
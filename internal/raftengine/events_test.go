@@ -0,0 +1,35 @@
+package raftengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRing(t *testing.T) {
+	// it should be disabled when the size is zero.
+	r := newEventRing(0)
+	require.Nil(t, r)
+	r.record(Event{})
+	require.Empty(t, r.events())
+
+	r = newEventRing(3)
+	r.record(Event{Index: 1})
+	r.record(Event{Index: 2})
+	require.Equal(t, []uint64{1, 2}, eventIndices(r.events()))
+
+	// it should overwrite the oldest events.
+	r.record(Event{Index: 3})
+	r.record(Event{Index: 4})
+	evs := r.events()
+	require.Equal(t, []uint64{2, 3, 4}, eventIndices(evs))
+	require.False(t, evs[0].Time.IsZero())
+}
+
+func eventIndices(evs []Event) []uint64 {
+	list := []uint64{}
+	for _, ev := range evs {
+		list = append(list, ev.Index)
+	}
+	return list
+}
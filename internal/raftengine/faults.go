@@ -0,0 +1,79 @@
+package raftengine
+
+import (
+	"errors"
+
+	"github.com/shaj13/raft/internal/membership"
+	"go.etcd.io/etcd/raft/v3"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// errFaultDrop is returned when the fault injector drops an outbound message.
+var errFaultDrop = errors.New("raft: message dropped by fault injector")
+
+// sendMessage sends the given message to the given member, injecting the message fault if any.
+func (eng *engine) sendMessage(mem membership.Member, m etcdraftpb.Message) error {
+	if eng.faults == nil {
+		return mem.Send(m)
+	}
+
+	action, d := eng.faults.MessageFault(m)
+	switch action {
+	case FaultDrop:
+		return errFaultDrop
+	case FaultDuplicate:
+		if err := mem.Send(m); err != nil {
+			return err
+		}
+		return mem.Send(m)
+	case FaultDelay:
+		eng.wg.Add(1)
+		go func() {
+			defer eng.wg.Done()
+
+			ticker := eng.newTicker(d)
+			defer ticker.Stop()
+
+			select {
+			case <-ticker.C():
+			case <-eng.ctx.Done():
+				return
+			}
+
+			if err := mem.Send(m); err != nil {
+				eng.logger.Warningf(
+					"raft.engine: sending delayed message %s to member %x: %v",
+					m.Type,
+					m.To,
+					err,
+				)
+			}
+		}()
+		return nil
+	default:
+		return mem.Send(m)
+	}
+}
+
+// syncFault returns the injected fault of syncing the given hard state and entries, if any.
+func (eng *engine) syncFault(hs etcdraftpb.HardState, ents []etcdraftpb.Entry) error {
+	if eng.faults == nil || (raft.IsEmptyHardState(hs) && len(ents) == 0) {
+		return nil
+	}
+
+	index := hs.Commit
+	if len(ents) > 0 {
+		index = ents[len(ents)-1].Index
+	}
+
+	return eng.faults.SyncFault(index)
+}
+
+// applyFault returns the injected crash at the given stage of applying the entry at the given index, if any.
+func (eng *engine) applyFault(stage ApplyStage, index uint64) error {
+	if eng.faults == nil {
+		return nil
+	}
+
+	return eng.faults.ApplyFault(stage, index)
+}
@@ -0,0 +1,108 @@
+package raftengine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/atomic"
+	membershipmock "github.com/shaj13/raft/internal/mocks/membership"
+	"github.com/shaj13/raft/raftlog"
+)
+
+func TestSendMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mem := membershipmock.NewMockMember(ctrl)
+	faults := NewMockFaultInjector(ctrl)
+	msg := etcdraftpb.Message{To: 1, Type: etcdraftpb.MsgApp}
+
+	eng := &engine{logger: raftlog.DefaultLogger}
+	eng.ctx, eng.cancel = context.WithCancel(context.Background())
+	defer eng.cancel()
+
+	// it should send the message as is without a fault injector.
+	mem.EXPECT().Send(msg).Return(nil)
+	require.NoError(t, eng.sendMessage(mem, msg))
+
+	eng.faults = faults
+
+	faults.EXPECT().MessageFault(msg).Return(FaultNone, time.Duration(0))
+	mem.EXPECT().Send(msg).Return(nil)
+	require.NoError(t, eng.sendMessage(mem, msg))
+
+	faults.EXPECT().MessageFault(msg).Return(FaultDrop, time.Duration(0))
+	require.ErrorIs(t, eng.sendMessage(mem, msg), errFaultDrop)
+
+	faults.EXPECT().MessageFault(msg).Return(FaultDuplicate, time.Duration(0))
+	mem.EXPECT().Send(msg).Return(nil).Times(2)
+	require.NoError(t, eng.sendMessage(mem, msg))
+
+	sent := make(chan struct{})
+	faults.EXPECT().MessageFault(msg).Return(FaultDelay, time.Millisecond)
+	mem.EXPECT().Send(msg).DoAndReturn(func(etcdraftpb.Message) error {
+		close(sent)
+		return nil
+	})
+	require.NoError(t, eng.sendMessage(mem, msg))
+	<-sent
+	eng.wg.Wait()
+}
+
+func TestSyncFault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	faults := NewMockFaultInjector(ctrl)
+	eng := &engine{}
+
+	// it should not inject faults without a fault injector.
+	require.NoError(t, eng.syncFault(etcdraftpb.HardState{Commit: 1}, nil))
+
+	eng.faults = faults
+
+	// it should not inject faults when nothing to sync.
+	require.NoError(t, eng.syncFault(etcdraftpb.HardState{}, nil))
+
+	err := errors.New("sync failed")
+	faults.EXPECT().SyncFault(uint64(3)).Return(err)
+	ents := []etcdraftpb.Entry{{Index: 2}, {Index: 3}}
+	require.Equal(t, err, eng.syncFault(etcdraftpb.HardState{Commit: 1}, ents))
+
+	faults.EXPECT().SyncFault(uint64(4)).Return(nil)
+	require.NoError(t, eng.syncFault(etcdraftpb.HardState{Commit: 4}, nil))
+}
+
+func TestPublishCommittedApplyFault(t *testing.T) {
+	err := errors.New("crash")
+	ents := []etcdraftpb.Entry{{Index: 1}, {Index: 2}, {Index: 3}}
+
+	table := []struct {
+		stage   ApplyStage
+		applied uint64
+	}{
+		{stage: BeforeApply, applied: 1},
+		{stage: AfterApply, applied: 2},
+	}
+
+	for _, tt := range table {
+		ctrl := gomock.NewController(t)
+		faults := NewMockFaultInjector(ctrl)
+		eng := &engine{
+			faults:       faults,
+			appliedIndex: atomic.NewUint64(),
+		}
+
+		faults.EXPECT().ApplyFault(gomock.Any(), gomock.Any()).DoAndReturn(func(stage ApplyStage, index uint64) error {
+			if stage == tt.stage && index == 2 {
+				return err
+			}
+			return nil
+		}).AnyTimes()
+
+		require.Equal(t, err, eng.publishCommitted(ents))
+		require.Equal(t, tt.applied, eng.appliedIndex.Get())
+	}
+}
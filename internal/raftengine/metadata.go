@@ -0,0 +1,28 @@
+package raftengine
+
+import "context"
+
+// MaxMetadataSize is the maximum size in bytes of the metadata keys and values,
+// attached to a proposal.
+const MaxMetadataSize = 4096
+
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of the given context that carries the given metadata.
+func ContextWithMetadata(ctx context.Context, md map[string]string) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFromContext returns the metadata carried by the given context, if any.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return md
+}
+
+// metadataSize returns the size in bytes of the given metadata keys and values.
+func metadataSize(md map[string]string) (n int) {
+	for k, v := range md {
+		n += len(k) + len(v)
+	}
+	return
+}
@@ -126,7 +126,8 @@ func TestMux(t *testing.T) {
 	defer mux.Stop()
 
 	peers := []raft.Peer{{ID: 1}}
-	node := bootstrap(cfg, peers)
+	node, err := bootstrap(cfg, peers)
+	require.NoError(t, err)
 
 	rd := <-node.Ready()
 	ent := rd.CommittedEntries[0]
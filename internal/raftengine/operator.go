@@ -2,6 +2,9 @@ package raftengine
 
 import (
 	"context"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -13,6 +16,7 @@ import (
 	"go.etcd.io/etcd/raft/v3"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 
+	"github.com/shaj13/raft/internal/membership"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/storage"
 )
@@ -20,7 +24,12 @@ import (
 // order define a weight to operators to obtain the execution order.
 var order = map[string]int{
 	new(setup).String():           0,
+	new(joinRetry).String():       0,
 	new(members).String():         1,
+	new(initialMembers).String():  1,
+	new(labels).String():          2,
+	new(clusterToken).String():    2,
+	new(learner).String():         2,
 	new(forceNewCluster).String(): 2,
 	new(restore).String():         2,
 	new(stateSetup).String():      3,
@@ -34,13 +43,36 @@ var order = map[string]int{
 
 // bootstrapFunc declare function signature for initializes and return
 // a raft.Node ready for use.
-type bootstrapFunc func(cfg Config, peers []raft.Peer) raft.Node
+type bootstrapFunc func(cfg Config, peers []raft.Peer) (raft.Node, error)
 
 // Members returns operator that adds the given members to the raft node.
 func Members(membs ...raftpb.Member) Operator {
 	return members{membs: membs}
 }
 
+// InitialMembers returns operator that initializes a new cluster with the given members,
+// or restarts the raft node from state dir if exist.
+func InitialMembers(membs ...raftpb.Member) Operator {
+	return initialMembers{membs: membs}
+}
+
+// ClusterToken returns operator that sets the token of a new cluster,
+// the cluster id derived from the token and the initial members.
+func ClusterToken(token string) Operator {
+	return clusterToken(token)
+}
+
+// Labels returns operator that sets the given labels on the current raft node member.
+func Labels(l map[string]string) Operator {
+	return labels(l)
+}
+
+// Learner returns operator that sets the current raft node member as a staging member,
+// so it joins the cluster as a learner, and promoted to a voter once caught up with the leader.
+func Learner() Operator {
+	return learner{}
+}
+
 // Join returns operator that sends rpc request to join an existing cluster.
 func Join(addr string, timeout time.Duration) Operator {
 	return join{
@@ -78,6 +110,12 @@ func Restart() Operator {
 	}
 }
 
+// JoinRetry returns operator that retries the join requests with exponential backoff,
+// while the cluster not ready to serve them, the fallback join operators retried together.
+func JoinRetry(p membership.RetryPolicy) Operator {
+	return joinRetry(p)
+}
+
 // Fallback returns operator that can be used if other operators do not succeed.
 func Fallback(ops ...Operator) Operator {
 	return &fallback{operators: ops}
@@ -89,20 +127,35 @@ type forceJoin struct {
 }
 
 func (f forceJoin) before(ost *operatorsState) error {
+	// the fallback retries its operators together.
+	if ost.fallback {
+		return f.join(ost)
+	}
+
+	return retryJoin(ost, func() error {
+		return f.join(ost)
+	})
+}
+
+// join sends a single join request.
+func (f forceJoin) join(ost *operatorsState) error {
 	ctx, cancel := context.WithTimeout(context.TODO(), f.timeout)
 	defer cancel()
 
 	rpc, err := ost.eng.cfg.Dial()(ctx, f.addr)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %s: %v", ErrClusterNotReady, f.addr, err)
 	}
 
+	defer rpc.Close()
+
 	resp, err := rpc.Join(ctx, *ost.local)
 	if err != nil {
-		return err
+		return joinError(f.addr, err)
 	}
 
 	ost.local.ID, ost.membs = resp.ID, resp.Members
+	ost.local.ClusterID = resp.ClusterID
 	return nil
 }
 
@@ -144,9 +197,57 @@ func (c initCluster) before(ost *operatorsState) error {
 	if ost.hasExistingState {
 		return errors.New("raft: cluster is already exist")
 	}
+
+	if ost.local.Type != raftpb.VoterMember {
+		return fmt.Errorf("raft: cluster can't be initialized by a %s member", ost.local.Type)
+	}
+
+	local := *ost.local
+	local.ClusterID = newClusterID(ost.clusterToken, append([]raftpb.Member{local}, ost.membs...))
+	ost.local = &local
 	return nil
 }
 
+// newClusterID returns the id of a new cluster of the given initial members.
+// Given a cluster token, the id derived from the token and the members ids and addresses,
+// so the initial members agree on it. Otherwise, a single member cluster gets a random id,
+// so a cluster bootstrapped again from the same config gets a new id,
+// whereas the initial members of a multiple members cluster can't agree on a random id,
+// hence the id left unknown, i.e. zero, and the cluster not fenced.
+func newClusterID(token string, membs []raftpb.Member) uint64 {
+	if len(token) > 0 {
+		return clusterID(token, membs)
+	}
+
+	if len(membs) > 1 {
+		return 0
+	}
+
+	var buf [8]byte
+	for {
+		if _, err := crand.Read(buf[:]); err != nil {
+			return 0
+		}
+
+		if id := binary.BigEndian.Uint64(buf[:]); id != 0 {
+			return id
+		}
+	}
+}
+
+// clusterID returns the id of a new cluster of the given token and initial members,
+// derived from the token and the members ids and addresses so the initial members agree on it.
+func clusterID(token string, membs []raftpb.Member) uint64 {
+	keys := make([]string, 0, len(membs))
+	for _, m := range membs {
+		keys = append(keys, fmt.Sprintf("%x/%s", m.ID, m.Address))
+	}
+
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(token + "|" + strings.Join(keys, ",")))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
 func (c initCluster) after(ost *operatorsState) error {
 	membs := ost.membs
 	membs = append([]raftpb.Member{*ost.local}, membs...)
@@ -159,7 +260,12 @@ func (c initCluster) after(ost *operatorsState) error {
 		}
 	}
 
-	ost.eng.node = c.bootstrap(ost.eng.cfg, peers)
+	node, err := c.bootstrap(ost.eng.cfg, peers)
+	if err != nil {
+		return err
+	}
+
+	ost.eng.node = node
 	return nil
 }
 
@@ -179,7 +285,12 @@ func (r restart) before(ost *operatorsState) error {
 }
 
 func (r restart) after(ost *operatorsState) error {
-	ost.eng.node = r.bootstrap(ost.eng.cfg, nil)
+	node, err := r.bootstrap(ost.eng.cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	ost.eng.node = node
 	return nil
 }
 
@@ -193,7 +304,6 @@ type fallback struct {
 }
 
 func (f *fallback) before(ost *operatorsState) error {
-	errs := []string{}
 	for _, op := range f.operators {
 		_, ok := op.(interface {
 			noFallback()
@@ -201,18 +311,26 @@ func (f *fallback) before(ost *operatorsState) error {
 		if ok {
 			return fmt.Errorf("raft: %s can't be used with fallback", op)
 		}
+	}
 
-		// call operator.
-		err := op.before(ost)
-		if err == nil {
-			f.success = op
-			return nil
-		}
+	ost.fallback = true
+	defer func() { ost.fallback = false }()
 
-		errs = append(errs, err.Error())
-	}
+	return retryJoin(ost, func() error {
+		errs := fallbackError{}
+		for _, op := range f.operators {
+			// call operator.
+			err := op.before(ost)
+			if err == nil {
+				f.success = op
+				return nil
+			}
+
+			errs = append(errs, err)
+		}
 
-	return fmt.Errorf(strings.Join(errs, ", "))
+		return errs
+	})
 }
 
 func (f *fallback) after(ost *operatorsState) error {
@@ -227,6 +345,69 @@ func (f fallback) String() string {
 	return "Fallback"
 }
 
+// fallbackError is the errors of the fallback operators.
+type fallbackError []error
+
+func (errs fallbackError) Error() string {
+	strs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		strs = append(strs, err.Error())
+	}
+	return strings.Join(strs, ", ")
+}
+
+func (errs fallbackError) Unwrap() []error {
+	return errs
+}
+
+type joinRetry membership.RetryPolicy
+
+func (r joinRetry) before(ost *operatorsState) (err error) {
+	ost.joinRetry = membership.RetryPolicy(r)
+	return
+}
+
+func (r joinRetry) after(ost *operatorsState) (err error) { return }
+
+func (r joinRetry) String() string {
+	return "JoinRetry"
+}
+
+// retryJoin calls the given join function, and retries it per the join retry policy,
+// while the cluster not ready to serve the join request and not rejected it.
+func retryJoin(ost *operatorsState, fn func() error) error {
+	p := ost.joinRetry
+	err := fn()
+
+	for i := 0; i < p.MaxRetries && errors.Is(err, ErrClusterNotReady) && !errors.Is(err, ErrJoinRejected); i++ {
+		d := p.Backoff(i)
+		ost.eng.logger.Warningf("raft.engine: join: %v, retrying in %s", err, d)
+
+		select {
+		case <-time.After(d):
+		case <-ost.eng.ctx.Done():
+			return err
+		}
+
+		err = fn()
+	}
+
+	return err
+}
+
+// joinError returns the given join request error,
+// wrapped by ErrJoinRejected when the cluster rejected the request, Otherwise by ErrClusterNotReady.
+func joinError(addr string, err error) error {
+	for _, rerr := range []error{ErrJoinDenied, ErrUnauthenticated, ErrIncompatibleVersion} {
+		// the errors returned by the remote members carry only the error message.
+		if errors.Is(err, rerr) || strings.Contains(err.Error(), rerr.Error()) {
+			return fmt.Errorf("%w: %s: %w", ErrJoinRejected, addr, err)
+		}
+	}
+
+	return fmt.Errorf("%w: %s: %v", ErrClusterNotReady, addr, err)
+}
+
 type stateSetup struct {
 	publishSnapshotFile func(*storage.Snapshot) error
 }
@@ -262,6 +443,7 @@ func (s setup) before(ost *operatorsState) (err error) {
 		// generate a random id in case this is the first member in the cluster.
 		ID:      uint64(rand.Int63()) + 1,
 		Address: s.addr,
+		Version: ProtocolVersion,
 	}
 	return
 }
@@ -272,7 +454,7 @@ func (s setup) after(ost *operatorsState) (err error) {
 	}
 
 	meta := pbutil.MustMarshal(ost.local)
-	meta, ost.hst, ost.ents, ost.sf, err = ost.eng.storage.Boot(meta)
+	meta, ost.hst, ost.ents, ost.sf, err = ost.eng.storage.Boot(ost.eng.ctx, meta)
 	if err != nil {
 		return
 	}
@@ -280,6 +462,11 @@ func (s setup) after(ost *operatorsState) (err error) {
 	local := new(raftpb.Member)
 	pbutil.MustUnmarshal(local, meta)
 
+	// the node restarted with a new address, e.g. a rescheduled pod.
+	if len(local.Address) > 0 && local.Address != ost.local.Address {
+		ost.addr = ost.local.Address
+	}
+
 	// create memory storage at first place so the operators append hs/ents
 	// and to avoid using the same storage on different start invocations.
 	ost.eng.cache = raft.NewMemoryStorage()
@@ -290,10 +477,16 @@ func (s setup) after(ost *operatorsState) (err error) {
 	ost.cfg = cfg
 	ost.local = local
 
+	match := ost.eng.cfg.MemberTypeMatcher()
 	ost.eng.pool.RegisterTypeMatcher(func(m raftpb.Member) raftpb.MemberType {
 		if cfg.ID == m.ID && m.Type != raftpb.RemovedMember {
 			return raftpb.LocalMember
 		}
+
+		if match != nil {
+			return match(m)
+		}
+
 		return m.Type
 	})
 
@@ -313,15 +506,19 @@ func (f forceNewCluster) after(ost *operatorsState) (err error) {
 	storage := ost.eng.storage
 	sf := ost.sf
 	local := *ost.local
-	membs := ost.membs
 	ents := ost.ents
 	hs := ost.hst
 	next := hs.Commit + 1
 
+	// the local member becomes the single voter of the cluster, even if it was a learner.
+	local.Type = raftpb.VoterMember
+	membs := append([]raftpb.Member{local}, ost.membs...)
+
 	if !raft.IsEmptySnap(sf.Raw) {
-		// reset latest snapshot pool members and conf state.
+		// reset latest snapshot pool members and conf state,
+		// including the learners and the joint configuration.
 		sf.Members = []raftpb.Member{local}
-		sf.Raw.Metadata.ConfState.Voters = []uint64{local.ID}
+		sf.Raw.Metadata.ConfState = etcdraftpb.ConfState{Voters: []uint64{local.ID}}
 
 		err := storage.Snapshotter().Write(sf)
 		if err != nil {
@@ -329,7 +526,7 @@ func (f forceNewCluster) after(ost *operatorsState) (err error) {
 		}
 
 		meta := sf.Raw.Metadata
-		sf, err = storage.Snapshotter().Read(meta.Term, meta.Index)
+		sf, err = storage.Snapshotter().Read(ost.eng.ctx, meta.Term, meta.Index)
 		if err != nil {
 			return err
 		}
@@ -427,15 +624,23 @@ func (r restore) before(ost *operatorsState) (err error) {
 
 	// update state to existed.
 	ost.hasExistingState = true
+	ost.restored = r.path
 
-	sf, err := storage.Snapshotter().ReadFrom(r.path)
+	sf, err := storage.Snapshotter().ReadFrom(ost.eng.ctx, r.path)
 	if err != nil {
 		return err
 	}
 
+	defer sf.Data.Close()
+
+	// a delta snapshot can't be restored without the snapshots it chained onto.
+	if sf.BaseIndex != 0 {
+		return fmt.Errorf("raft: restore from a delta snapshot %s, a full snapshot is required", r.path)
+	}
+
 	// boot storage.
 	meta := pbutil.MustMarshal(ost.local)
-	_, _, _, _, err = storage.Boot(meta)
+	_, _, _, _, err = storage.Boot(ost.eng.ctx, meta)
 	if err != nil {
 		return err
 	}
@@ -445,17 +650,24 @@ func (r restore) before(ost *operatorsState) (err error) {
 	copy(membs, ost.membs)
 	membs = append(membs, *ost.local)
 
-	// issue conf change for membs.
+	// issue conf change for membs,
+	// and re-stamp the snapshot conf state with the new members.
 	ents := make([]etcdraftpb.Entry, len(membs))
-	nodeIDs := make([]uint64, len(membs))
+	confState := etcdraftpb.ConfState{}
 	for i, m := range membs {
-		nodeIDs[i] = m.ID
 		cc := etcdraftpb.ConfChange{
 			Type:    etcdraftpb.ConfChangeAddNode,
 			NodeID:  m.ID,
 			Context: pbutil.MustMarshal(&m),
 		}
 
+		if m.Type == raftpb.LearnerMember {
+			cc.Type = etcdraftpb.ConfChangeAddLearnerNode
+			confState.Learners = append(confState.Learners, m.ID)
+		} else {
+			confState.Voters = append(confState.Voters, m.ID)
+		}
+
 		ents[i] = etcdraftpb.Entry{
 			Type:  etcdraftpb.EntryConfChange,
 			Term:  1,
@@ -467,7 +679,7 @@ func (r restore) before(ost *operatorsState) (err error) {
 	commit, term := uint64(len(ents)), uint64(1)
 	hs := etcdraftpb.HardState{
 		Term:   term,
-		Vote:   membs[0].ID,
+		Vote:   ost.local.ID,
 		Commit: commit,
 	}
 
@@ -476,10 +688,6 @@ func (r restore) before(ost *operatorsState) (err error) {
 		return err
 	}
 
-	confState := etcdraftpb.ConfState{
-		Voters: nodeIDs,
-	}
-
 	snap := etcdraftpb.Snapshot{
 		Metadata: etcdraftpb.SnapshotMetadata{
 			Index:     commit,
@@ -491,6 +699,7 @@ func (r restore) before(ost *operatorsState) (err error) {
 	// update snapshot file meta.
 	sf.Members = membs
 	sf.Raw = snap
+	sf.BaseTerm, sf.BaseIndex = 0, 0
 
 	// save new snapshot file to state dir.
 	if err := storage.Snapshotter().Write(sf); err != nil {
@@ -538,6 +747,133 @@ func (m members) String() string {
 	return "Members"
 }
 
+type initialMembers struct {
+	membs []raftpb.Member
+}
+
+func (m initialMembers) after(ost *operatorsState) (err error) { return }
+
+func (m initialMembers) noFallback() {}
+
+func (m initialMembers) before(ost *operatorsState) (err error) {
+	if len(m.membs) == 0 {
+		return errors.New("raft: initial members must not be empty")
+	}
+
+	if len(ost.local.Address) == 0 {
+		return errors.New("raft: no address set, use raft.WithAddress() to identify the node within the initial members")
+	}
+
+	ids := make(map[uint64]struct{}, len(m.membs))
+	addrs := make(map[string]struct{}, len(m.membs))
+	var local *raftpb.Member
+	membs := []raftpb.Member{}
+
+	for _, mem := range m.membs {
+		if mem.ID == 0 || len(mem.Address) == 0 {
+			return errors.New("raft: initial members must have an id and an address")
+		}
+
+		if _, ok := ids[mem.ID]; ok {
+			return fmt.Errorf("raft: duplicate initial member id %x", mem.ID)
+		}
+
+		if _, ok := addrs[mem.Address]; ok {
+			return fmt.Errorf("raft: duplicate initial member address %s", mem.Address)
+		}
+
+		ids[mem.ID], addrs[mem.Address] = struct{}{}, struct{}{}
+		mem.Type = raftpb.VoterMember
+
+		if mem.Address == ost.local.Address {
+			mem := mem
+			local = &mem
+			continue
+		}
+
+		membs = append(membs, mem)
+	}
+
+	if local == nil {
+		return fmt.Errorf("raft: node address %s not found in the initial members", ost.local.Address)
+	}
+
+	ost.local = local
+	ost.membs = append(ost.membs, membs...)
+	return
+}
+
+func (m initialMembers) addOns() []Operator {
+	return []Operator{Fallback(Restart(), InitCluster())}
+}
+
+func (m initialMembers) String() string {
+	return "InitialMembers"
+}
+
+type labels map[string]string
+
+func (l labels) after(ost *operatorsState) (err error) { return }
+
+func (l labels) noFallback() {}
+
+func (l labels) before(ost *operatorsState) (err error) {
+	if len(l) == 0 {
+		return
+	}
+
+	// copy labels to not share the map with the caller or the members operator.
+	m := make(map[string]string, len(ost.local.Labels)+len(l))
+	for k, v := range ost.local.Labels {
+		m[k] = v
+	}
+
+	for k, v := range l {
+		m[k] = v
+	}
+
+	local := *ost.local
+	local.Labels = m
+	ost.local = &local
+	return
+}
+
+func (l labels) String() string {
+	return "Labels"
+}
+
+type clusterToken string
+
+func (t clusterToken) after(ost *operatorsState) (err error) { return }
+
+func (t clusterToken) noFallback() {}
+
+func (t clusterToken) before(ost *operatorsState) (err error) {
+	ost.clusterToken = string(t)
+	return
+}
+
+func (t clusterToken) String() string {
+	return "ClusterToken"
+}
+
+type learner struct{}
+
+func (l learner) after(ost *operatorsState) (err error) { return }
+
+func (l learner) noFallback() {}
+
+func (l learner) before(ost *operatorsState) (err error) {
+	local := *ost.local
+	local.Type = raftpb.StagingMember
+	ost.local = &local
+	return
+}
+
+func (l learner) String() string {
+	return "Learner"
+}
+
 type removedMembers struct{}
 
 func (rm removedMembers) before(ost *operatorsState) (err error) { return }
@@ -599,29 +935,29 @@ func invoke(d *engine, oprs ...Operator) (*operatorsState, error) {
 
 // bootstrap implements bootstrapFunc and return's
 // an raft.Node ready for use.
-func bootstrap(cfg Config, peers []raft.Peer) raft.Node {
+func bootstrap(cfg Config, peers []raft.Peer) (raft.Node, error) {
 	mux := cfg.Mux()
 	rcfg := cfg.RaftConfig()
 	gid := cfg.GroupID()
 
 	if mux == nil && len(peers) == 0 {
-		return raft.RestartNode(rcfg)
+		return raft.RestartNode(rcfg), nil
 	}
 
 	if mux == nil && len(peers) > 0 {
-		return raft.StartNode(rcfg, peers)
+		return raft.StartNode(rcfg, peers), nil
 	}
 
 	rn, err := raft.NewRawNode(rcfg)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("raft: create raw node: %v", err)
 	}
 
 	if len(peers) > 0 {
 		if err := rn.Bootstrap(peers); err != nil {
-			panic(err)
+			return nil, fmt.Errorf("raft: bootstrap raw node: %v", err)
 		}
 	}
 
-	return mux.add(gid, rn, rcfg)
+	return mux.add(gid, rn, rcfg), nil
 }
@@ -2,7 +2,9 @@ package raftengine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -10,9 +12,11 @@ import (
 	storagemock "github.com/shaj13/raft/internal/mocks/storage"
 	transportmock "github.com/shaj13/raft/internal/mocks/transport"
 
+	"github.com/shaj13/raft/internal/membership"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/storage"
 	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/raftlog"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/pkg/v3/pbutil"
 	"go.etcd.io/etcd/raft/v3"
@@ -95,6 +99,122 @@ func TestMembers(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestInitialMembers(t *testing.T) {
+	membs := []raftpb.Member{
+		{ID: 1, Address: ":1"},
+		{ID: 2, Address: ":2", Type: raftpb.LearnerMember},
+		{ID: 3, Address: ":3"},
+	}
+
+	table := []struct {
+		name  string
+		addr  string
+		membs []raftpb.Member
+		err   string
+	}{
+		{
+			name: "it return error when no members",
+			addr: ":1",
+			err:  "must not be empty",
+		},
+		{
+			name:  "it return error when no address",
+			membs: membs,
+			err:   "no address",
+		},
+		{
+			name:  "it return error when address not found",
+			addr:  ":4",
+			membs: membs,
+			err:   "not found",
+		},
+		{
+			name:  "it return error when member id missing",
+			addr:  ":1",
+			membs: []raftpb.Member{{Address: ":1"}},
+			err:   "must have an id",
+		},
+		{
+			name:  "it return error when duplicate id",
+			addr:  ":1",
+			membs: []raftpb.Member{{ID: 1, Address: ":1"}, {ID: 1, Address: ":2"}},
+			err:   "duplicate initial member id",
+		},
+		{
+			name:  "it return error when duplicate address",
+			addr:  ":1",
+			membs: []raftpb.Member{{ID: 1, Address: ":1"}, {ID: 2, Address: ":1"}},
+			err:   "duplicate initial member address",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ost := new(operatorsState)
+			ost.local = &raftpb.Member{ID: 10, Address: tt.addr}
+			err := InitialMembers(tt.membs...).before(ost)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	// it should set local and membs regardless of the members order.
+	ost := new(operatorsState)
+	ost.local = &raftpb.Member{ID: 10, Address: ":2"}
+	opr := InitialMembers(membs...)
+	err := opr.before(ost)
+	require.NoError(t, err)
+	require.Equal(t, raftpb.Member{ID: 2, Address: ":2"}, *ost.local)
+	require.Equal(t, []raftpb.Member{membs[0], membs[2]}, ost.membs)
+	require.Equal(t, raftpb.LearnerMember, membs[1].Type)
+
+	// it should restart or init the cluster.
+	addOns := opr.(interface{ addOns() []Operator }).addOns()
+	require.Len(t, addOns, 1)
+	require.Equal(t, new(fallback).String(), addOns[0].String())
+}
+
+func TestLabels(t *testing.T) {
+	ost := new(operatorsState)
+	ost.local = &raftpb.Member{ID: 1, Labels: map[string]string{"zone": "a"}}
+	local := ost.local
+
+	// it should not change local when no labels.
+	err := Labels(nil).before(ost)
+	require.NoError(t, err)
+	require.Equal(t, local, ost.local)
+
+	// it should merge labels into local labels.
+	err = Labels(map[string]string{"zone": "b", "rack": "1"}).before(ost)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), ost.local.ID)
+	require.Equal(t, map[string]string{"zone": "b", "rack": "1"}, ost.local.Labels)
+	require.Equal(t, map[string]string{"zone": "a"}, local.Labels)
+
+	err = Labels(nil).after(ost)
+	require.NoError(t, err)
+}
+
+func TestLearner(t *testing.T) {
+	local := &raftpb.Member{ID: 1}
+	ost := new(operatorsState)
+	ost.local = local
+
+	// it should set the local member as a staging member.
+	err := Learner().before(ost)
+	require.NoError(t, err)
+	require.Equal(t, raftpb.StagingMember, ost.local.Type)
+	require.Equal(t, raftpb.VoterMember, local.Type)
+
+	// it should not initialize a cluster by a staging member.
+	err = InitCluster().before(ost)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't be initialized")
+
+	err = Learner().after(ost)
+	require.NoError(t, err)
+}
+
 func TestJoin(t *testing.T) {
 	ost := new(operatorsState)
 	ost.hasExistingState = true
@@ -127,6 +247,35 @@ func TestInitCluster(t *testing.T) {
 	require.Equal(t, uint64(2), peers[1].ID)
 }
 
+func TestClusterID(t *testing.T) {
+	a := raftpb.Member{ID: 1, Address: ":1"}
+	b := raftpb.Member{ID: 2, Address: ":2"}
+
+	// it generate the same id of a token regardless of the members order.
+	id := clusterID("token", []raftpb.Member{a, b})
+	require.NotZero(t, id)
+	require.Equal(t, id, clusterID("token", []raftpb.Member{b, a}))
+	require.NotEqual(t, id, clusterID("token", []raftpb.Member{a}))
+	require.NotEqual(t, id, clusterID("other", []raftpb.Member{a, b}))
+
+	// it assign the token id to the local member on cluster init.
+	ost := new(operatorsState)
+	ost.local = &b
+	ost.membs = []raftpb.Member{a}
+	require.NoError(t, ClusterToken("token").before(ost))
+	err := InitCluster().before(ost)
+	require.NoError(t, err)
+	require.Equal(t, id, ost.local.ClusterID)
+
+	// it generate a random id of a single member cluster.
+	single := newClusterID("", []raftpb.Member{a})
+	require.NotZero(t, single)
+	require.NotEqual(t, single, newClusterID("", []raftpb.Member{a}))
+
+	// it leave the id of a multiple members cluster unknown without a token.
+	require.Zero(t, newClusterID("", []raftpb.Member{a, b}))
+}
+
 func TestRestart(t *testing.T) {
 	nodeRestarted := false
 	ost := new(operatorsState)
@@ -156,7 +305,7 @@ func TestFallback(t *testing.T) {
 	second := NewMockOperator(ctrl)
 	first.EXPECT().before(gomock.Any()).Return(fmt.Errorf("1"))
 	second.EXPECT().before(gomock.Any()).Return(fmt.Errorf("2"))
-	err = Fallback(first, second).before(nil)
+	err = Fallback(first, second).before(new(operatorsState))
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "1, 2")
 
@@ -164,7 +313,7 @@ func TestFallback(t *testing.T) {
 	first.EXPECT().before(gomock.Any()).MaxTimes(1)
 	first.EXPECT().after(gomock.Any()).MaxTimes(1)
 	opr := Fallback(first)
-	err = opr.before(nil)
+	err = opr.before(new(operatorsState))
 	require.NoError(t, err)
 	err = opr.after(nil)
 	require.NoError(t, err)
@@ -173,8 +322,9 @@ func TestFallback(t *testing.T) {
 
 func TestForceJoin(t *testing.T) {
 	resp := &raftpb.JoinResponse{
-		ID:      1,
-		Members: []raftpb.Member{{ID: 2}},
+		ID:        1,
+		Members:   []raftpb.Member{{ID: 2}},
+		ClusterID: 3,
 	}
 	ctrl := gomock.NewController(t)
 	cfg := NewMockConfig(ctrl)
@@ -202,6 +352,10 @@ func TestForceJoin(t *testing.T) {
 		Join(gomock.Any(), gomock.Eq(*ost.local)).
 		Return(resp, nil)
 
+	client.
+		EXPECT().
+		Close()
+
 	pool.
 		EXPECT().
 		Add(gomock.Eq(resp.Members[0])).
@@ -211,6 +365,7 @@ func TestForceJoin(t *testing.T) {
 	err := ForceJoin("", 0).before(ost)
 	require.NoError(t, err)
 	require.Equal(t, resp.ID, ost.local.ID)
+	require.Equal(t, resp.ClusterID, ost.local.ClusterID)
 	require.Equal(t, resp.Members, ost.membs)
 
 	// it call pool add.
@@ -218,9 +373,92 @@ func TestForceJoin(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestForceJoinRetry(t *testing.T) {
+	table := []struct {
+		name    string
+		err     error
+		calls   int
+		target  error
+		retries int
+	}{
+		{
+			name:    "it retry while the cluster not ready",
+			err:     ErrNoLeader,
+			calls:   3,
+			target:  ErrClusterNotReady,
+			retries: 2,
+		},
+		{
+			name:    "it does not retry when the join denied",
+			err:     errors.New("rpc error: " + ErrJoinDenied.Error()),
+			calls:   1,
+			target:  ErrJoinRejected,
+			retries: 2,
+		},
+		{
+			name:    "it does not retry when unauthenticated",
+			err:     ErrUnauthenticated,
+			calls:   1,
+			target:  ErrJoinRejected,
+			retries: 2,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			cfg := NewMockConfig(ctrl)
+			client := transportmock.NewMockClient(ctrl)
+			dial := func(context.Context, string) (transport.Client, error) {
+				return client, nil
+			}
+			ost := new(operatorsState)
+			ost.local = &raftpb.Member{ID: 10}
+			ost.eng = new(engine)
+			ost.eng.cfg = cfg
+			ost.eng.ctx = context.Background()
+			ost.eng.logger = raftlog.DefaultLogger
+
+			cfg.EXPECT().Dial().Return(dial).Times(tt.calls)
+			client.EXPECT().Join(gomock.Any(), gomock.Any()).Return(nil, tt.err).Times(tt.calls)
+			client.EXPECT().Close().Times(tt.calls)
+
+			err := JoinRetry(membership.RetryPolicy{MaxRetries: tt.retries}).before(ost)
+			require.NoError(t, err)
+
+			err = ForceJoin("", 0).before(ost)
+			require.ErrorIs(t, err, tt.target)
+		})
+	}
+}
+
+func TestFallbackRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	first := NewMockOperator(ctrl)
+	second := NewMockOperator(ctrl)
+	ost := new(operatorsState)
+	ost.eng = new(engine)
+	ost.eng.ctx = context.Background()
+	ost.eng.logger = raftlog.DefaultLogger
+	ost.joinRetry = membership.RetryPolicy{MaxRetries: 1}
+
+	// it retry the operators together while the cluster not ready.
+	first.EXPECT().before(gomock.Any()).Return(ErrClusterNotReady).Times(2)
+	second.EXPECT().before(gomock.Any()).Return(ErrClusterNotReady).Times(2)
+	err := Fallback(first, second).before(ost)
+	require.ErrorIs(t, err, ErrClusterNotReady)
+	require.False(t, ost.fallback)
+
+	// it does not retry when the join rejected.
+	first.EXPECT().before(gomock.Any()).Return(ErrClusterNotReady)
+	second.EXPECT().before(gomock.Any()).Return(ErrJoinRejected)
+	err = Fallback(first, second).before(ost)
+	require.ErrorIs(t, err, ErrJoinRejected)
+}
+
 func TestSetup(t *testing.T) {
 	setup := &setup{}
-	local := &raftpb.Member{ID: 10}
+	local := &raftpb.Member{ID: 10, Address: ":80"}
 	meta := pbutil.MustMarshal(local)
 	ents := []etcdraftpb.Entry{{Index: 5}}
 	hs := etcdraftpb.HardState{Term: 2}
@@ -239,12 +477,22 @@ func TestSetup(t *testing.T) {
 	stg.EXPECT().Exist().Return(false).AnyTimes()
 	stg.
 		EXPECT().
-		Boot(gomock.Any()).
+		Boot(gomock.Any(), gomock.Any()).
 		Return(meta, hs, ents, sf, nil)
 
 	cfg.EXPECT().RaftConfig().Return(&raft.Config{})
 	cfg.EXPECT().Logger()
-	pool.EXPECT().RegisterTypeMatcher(gomock.Any())
+	cfg.EXPECT().MemberTypeMatcher().Return(func(m raftpb.Member) raftpb.MemberType {
+		if m.ID == 20 {
+			return raftpb.LocalMember
+		}
+		return m.Type
+	})
+
+	var match func(raftpb.Member) raftpb.MemberType
+	pool.EXPECT().RegisterTypeMatcher(gomock.Any()).Do(func(fn func(raftpb.Member) raftpb.MemberType) {
+		match = fn
+	})
 
 	ids := map[uint64]struct{}{}
 	for i := 0; i < 20; i++ {
@@ -267,11 +515,18 @@ func TestSetup(t *testing.T) {
 	err = setup.after(ost)
 	require.NoError(t, err)
 	require.Equal(t, local, ost.local)
+	// assert it detect the local member new address.
+	require.Equal(t, ":8080", ost.addr)
 	require.Equal(t, hs, ost.hst)
 	require.Equal(t, ents, ost.ents)
 	require.Equal(t, sf, ost.sf)
 	require.Equal(t, local.ID, ost.cfg.ID)
 
+	// assert it match the local member and consult the configured matcher.
+	require.Equal(t, raftpb.LocalMember, match(raftpb.Member{ID: 10}))
+	require.Equal(t, raftpb.RemovedMember, match(raftpb.Member{ID: 10, Type: raftpb.RemovedMember}))
+	require.Equal(t, raftpb.LocalMember, match(raftpb.Member{ID: 20, Type: raftpb.LearnerMember}))
+	require.Equal(t, raftpb.LearnerMember, match(raftpb.Member{ID: 30, Type: raftpb.LearnerMember}))
 }
 
 func TestStateSetup(t *testing.T) {
@@ -416,7 +671,7 @@ func TestForceNewCluster(t *testing.T) {
 
 	shotter.
 		EXPECT().
-		Read(gomock.Any(), gomock.Any()).
+		Read(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil, nil)
 
 	ost.eng.storage = stg
@@ -436,16 +691,68 @@ func TestForceNewCluster(t *testing.T) {
 	}
 
 	require.NoError(t, err)
-	require.Equal(t, uint64(5), ost.hst.Commit)
+	require.Equal(t, uint64(6), ost.hst.Commit)
 	require.Equal(t, 0, entNormal)
-	require.Equal(t, 5, confChange)
+	require.Equal(t, 6, confChange)
+
+	// it add the local member as a voter.
+	cc := new(etcdraftpb.ConfChange)
+	pbutil.MustUnmarshal(cc, ost.ents[3].Data)
+	mem := new(raftpb.Member)
+	pbutil.MustUnmarshal(mem, cc.Context)
+	require.Equal(t, etcdraftpb.ConfChangeAddNode, cc.Type)
+	require.Equal(t, uint64(1), cc.NodeID)
+	require.Equal(t, raftpb.VoterMember, mem.Type)
+}
+
+func TestForceNewClusterSnapshot(t *testing.T) {
+	ost := new(operatorsState)
+	ost.local = &raftpb.Member{ID: 1, Type: raftpb.LearnerMember}
+	ost.sf = &storage.Snapshot{
+		SnapshotState: raftpb.SnapshotState{
+			Raw: etcdraftpb.Snapshot{Metadata: etcdraftpb.SnapshotMetadata{
+				Index: 2,
+				Term:  1,
+				ConfState: etcdraftpb.ConfState{
+					Voters:         []uint64{2, 3},
+					Learners:       []uint64{1},
+					VotersOutgoing: []uint64{2},
+					AutoLeave:      true,
+				},
+			}},
+			Members: []raftpb.Member{{ID: 2}, {ID: 3}},
+		},
+	}
+	ost.hst = etcdraftpb.HardState{Term: 1, Commit: 2}
+	ost.eng = new(engine)
+	ctrl := gomock.NewController(t)
+	shotter := storagemock.NewMockSnapshotter(ctrl)
+	stg := storagemock.NewMockStorage(ctrl)
+	ost.eng.storage = stg
+
+	var written *storage.Snapshot
+	stg.EXPECT().Snapshotter().Return(shotter).Times(2)
+	stg.EXPECT().SaveEntries(gomock.Any(), gomock.Any()).Return(nil)
+	shotter.EXPECT().Write(gomock.Any()).DoAndReturn(func(sf *storage.Snapshot) error {
+		written = sf
+		return nil
+	})
+	shotter.EXPECT().Read(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, uint64, uint64) (*storage.Snapshot, error) { return written, nil },
+	)
+
+	// it reset the snapshot conf state to the local member as the single voter.
+	err := ForceNewCluster().after(ost)
+	require.NoError(t, err)
+	require.Equal(t, etcdraftpb.ConfState{Voters: []uint64{1}}, ost.sf.Raw.Metadata.ConfState)
+	require.Equal(t, []raftpb.Member{{ID: 1, Type: raftpb.VoterMember}}, ost.sf.Members)
 }
 
 func TestRestore(t *testing.T) {
 	hs := etcdraftpb.HardState{
 		Term:   1,
 		Vote:   1,
-		Commit: 1,
+		Commit: 2,
 	}
 	ctrl := gomock.NewController(t)
 	stg := storagemock.NewMockStorage(ctrl)
@@ -453,19 +760,20 @@ func TestRestore(t *testing.T) {
 	opr := restore{}
 	ost := new(operatorsState)
 	ost.local = &raftpb.Member{ID: 1}
+	ost.membs = []raftpb.Member{{ID: 2, Type: raftpb.LearnerMember}}
 	ost.eng = new(engine)
 	ost.eng.storage = stg
 
 	stg.
 		EXPECT().
-		Boot(gomock.Any()).
+		Boot(gomock.Any(), gomock.Any()).
 		Return(nil, etcdraftpb.HardState{}, nil, nil, nil)
 
 	stg.
 		EXPECT().
 		Snapshotter().
 		Return(shotter).
-		MaxTimes(2)
+		AnyTimes()
 
 	stg.
 		EXPECT().
@@ -484,13 +792,20 @@ func TestRestore(t *testing.T) {
 
 	shotter.
 		EXPECT().
-		ReadFrom(gomock.Any()).
-		Return(&storage.Snapshot{}, nil)
+		ReadFrom(gomock.Any(), gomock.Any()).
+		Return(&storage.Snapshot{Data: io.NopCloser(nil)}, nil)
 
 	shotter.
 		EXPECT().
 		Write(gomock.Any()).
-		Return(nil)
+		DoAndReturn(func(sf *storage.Snapshot) error {
+			// it re-stamp the conf state with the new members.
+			cs := sf.Raw.Metadata.ConfState
+			require.Equal(t, []uint64{1}, cs.Voters)
+			require.Equal(t, []uint64{2}, cs.Learners)
+			require.Len(t, sf.Members, 2)
+			return nil
+		})
 
 	ost.hasExistingState = true
 	err := opr.before(ost)
@@ -500,6 +815,19 @@ func TestRestore(t *testing.T) {
 	ost.hasExistingState = false
 	err = opr.before(ost)
 	require.NoError(t, err)
+
+	// it reject delta snapshots.
+	delta := &storage.Snapshot{Data: io.NopCloser(nil)}
+	delta.BaseIndex = 1
+	shotter.
+		EXPECT().
+		ReadFrom(gomock.Any(), gomock.Any()).
+		Return(delta, nil)
+
+	ost.hasExistingState = false
+	err = opr.before(ost)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "delta snapshot")
 }
 
 func TestRemovedMembers(t *testing.T) {
@@ -554,7 +882,8 @@ func TestBootstrap(t *testing.T) {
 	}
 
 	// it should start/restart raft node.
-	node := bootstrap(fn(nil), nil)
+	node, err := bootstrap(fn(nil), nil)
+	require.NoError(t, err)
 	node.Stop()
 	_, ok := node.(*muxNode)
 	require.False(t, ok)
@@ -564,19 +893,20 @@ func TestBootstrap(t *testing.T) {
 
 	// it should start raft node by using mux
 	peers := []raft.Peer{{ID: 1}}
-	node = bootstrap(fn(mux), peers)
+	node, err = bootstrap(fn(mux), peers)
+	require.NoError(t, err)
 	node.Stop()
 	_, ok = node.(*muxNode)
 	require.True(t, ok)
 }
 
 func mockBootstrap(called *bool, peers *[]raft.Peer) bootstrapFunc {
-	return func(_ Config, got []raft.Peer) raft.Node {
+	return func(_ Config, got []raft.Peer) (raft.Node, error) {
 		*called = true
 		if peers != nil {
 			*peers = got
 		}
-		return nil
+		return nil, nil
 	}
 }
 
@@ -0,0 +1,118 @@
+package raftengine
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Priority is the scheduling lane of a proposal.
+type Priority int
+
+const (
+	// PriorityNormal is the lane of the bulk data proposals.
+	PriorityNormal Priority = iota
+	// PriorityHigh is the lane of the small latency-sensitive proposals,
+	// such as the control operations and the metadata updates.
+	PriorityHigh
+)
+
+type priorityKey struct{}
+
+// ContextWithPriority returns a copy of the given context that carries the given proposal priority.
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the proposal priority carried by the given context,
+// or PriorityNormal if none.
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
+
+// Possible values for proposal state.
+const (
+	proposalQueued int32 = iota
+	proposalProposed
+	proposalCancelled
+)
+
+// proposal is a replicate proposal waiting to be scheduled.
+type proposal struct {
+	ctx   context.Context
+	data  []byte
+	errc  chan error
+	state int32
+}
+
+// propose marks the proposal as handed to raft,
+// it reports false if the proposal already cancelled.
+func (p *proposal) propose() bool {
+	return atomic.CompareAndSwapInt32(&p.state, proposalQueued, proposalProposed)
+}
+
+// cancel marks the queued proposal as cancelled,
+// it reports false if the proposal already handed to raft.
+func (p *proposal) cancel() bool {
+	return atomic.CompareAndSwapInt32(&p.state, proposalQueued, proposalCancelled)
+}
+
+// proposalQueue is a two-lane proposal queue, it serves up to weight
+// high priority proposals for each normal priority proposal, so that the bulk
+// data proposals can't starve the latency-sensitive ones and vice versa.
+type proposalQueue struct {
+	high   chan *proposal
+	normal chan *proposal
+	weight int
+	served int
+}
+
+func newProposalQueue(weight int) *proposalQueue {
+	if weight < 1 {
+		weight = 1
+	}
+
+	return &proposalQueue{
+		high:   make(chan *proposal),
+		normal: make(chan *proposal),
+		weight: weight,
+	}
+}
+
+// lane returns the queue lane of the given priority.
+func (q *proposalQueue) lane(p Priority) chan *proposal {
+	if p == PriorityHigh {
+		return q.high
+	}
+	return q.normal
+}
+
+// next blocks until a proposal is available or done closed.
+func (q *proposalQueue) next(done <-chan struct{}) (*proposal, bool) {
+	if q.served < q.weight {
+		select {
+		case p := <-q.high:
+			q.served++
+			return p, true
+		default:
+		}
+	}
+
+	q.served = 0
+
+	select {
+	case p := <-q.normal:
+		return p, true
+	default:
+	}
+
+	select {
+	case p := <-q.high:
+		q.served++
+		return p, true
+	case p := <-q.normal:
+		return p, true
+	case <-done:
+		return nil, false
+	}
+}
@@ -0,0 +1,59 @@
+package raftengine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityFromContext(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, PriorityNormal, PriorityFromContext(ctx))
+
+	ctx = ContextWithPriority(ctx, PriorityHigh)
+	require.Equal(t, PriorityHigh, PriorityFromContext(ctx))
+}
+
+func TestProposalQueue(t *testing.T) {
+	q := newProposalQueue(2)
+	q.high = make(chan *proposal, 10)
+	q.normal = make(chan *proposal, 10)
+	done := make(chan struct{})
+
+	for i := 0; i < 5; i++ {
+		q.high <- &proposal{data: []byte("h")}
+		q.normal <- &proposal{data: []byte("n")}
+	}
+
+	got := ""
+	for i := 0; i < 10; i++ {
+		p, ok := q.next(done)
+		require.True(t, ok)
+		got += string(p.data)
+	}
+
+	require.Equal(t, "hhnhhnhnnn", got)
+
+	close(done)
+	_, ok := q.next(done)
+	require.False(t, ok)
+}
+
+func TestNewProposalQueue(t *testing.T) {
+	q := newProposalQueue(0)
+	require.Equal(t, 1, q.weight)
+	require.Equal(t, q.high, q.lane(PriorityHigh))
+	require.Equal(t, q.normal, q.lane(PriorityNormal))
+}
+
+func TestProposalCancel(t *testing.T) {
+	p := new(proposal)
+	require.True(t, p.cancel())
+	require.False(t, p.propose())
+
+	// a proposal handed to raft can't be cancelled.
+	p = new(proposal)
+	require.True(t, p.propose())
+	require.False(t, p.cancel())
+}
@@ -0,0 +1,48 @@
+package raftengine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2021, time.March, 10, 14, 30, 15, 0, time.UTC) // Wednesday.
+
+	table := []struct {
+		spec     string
+		expected time.Time
+		err      bool
+	}{
+		{spec: "@every 1h", expected: now.Add(time.Hour)},
+		{spec: "@hourly", expected: time.Date(2021, time.March, 10, 15, 0, 0, 0, time.UTC)},
+		{spec: "@daily", expected: time.Date(2021, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 3 * * *", expected: time.Date(2021, time.March, 11, 3, 0, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", expected: time.Date(2021, time.March, 10, 14, 45, 0, 0, time.UTC)},
+		{spec: "0 9-17/4 * * *", expected: time.Date(2021, time.March, 10, 17, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", expected: time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * 1", expected: time.Date(2021, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1,15 4 *", expected: time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", expected: time.Time{}},
+		{spec: "@every -1h", err: true},
+		{spec: "@every x", err: true},
+		{spec: "0 3 * *", err: true},
+		{spec: "60 * * * *", err: true},
+		{spec: "* * * * 1-x", err: true},
+		{spec: "*/0 * * * *", err: true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := parseSchedule(tt.spec)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, s.next(now))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package raftengine
+
+import (
+	"fmt"
+
+	"github.com/shaj13/raft/internal/atomic"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// Direction is the direction of a raft message, relative to the local member.
+type Direction int
+
+const (
+	// Inbound is the direction of the messages received from the members.
+	Inbound Direction = iota
+	// Outbound is the direction of the messages sent to the members.
+	Outbound
+)
+
+// String returns the direction name.
+func (d Direction) String() string {
+	switch d {
+	case Inbound:
+		return "Inbound"
+	case Outbound:
+		return "Outbound"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+func newMessageTap(cfg Config) *messageTap {
+	fn := cfg.MessageTap()
+	if fn == nil {
+		return nil
+	}
+
+	t := &messageTap{
+		fn:    fn,
+		every: cfg.MessageTapSampling(),
+		count: atomic.NewUint64(),
+	}
+
+	if types := cfg.MessageTapTypes(); len(types) > 0 {
+		t.types = make(map[etcdraftpb.MessageType]struct{}, len(types))
+		for _, typ := range types {
+			t.types[typ] = struct{}{}
+		}
+	}
+
+	return t
+}
+
+// messageTap hands the inbound and outbound messages to the tap function,
+// filtered by types, and sampled one of each every messages.
+type messageTap struct {
+	fn    func(Direction, etcdraftpb.Message)
+	every uint64
+	types map[etcdraftpb.MessageType]struct{}
+	count *atomic.Uint64
+}
+
+func (t *messageTap) tap(dir Direction, m etcdraftpb.Message) {
+	if t == nil {
+		return
+	}
+
+	if t.types != nil {
+		if _, ok := t.types[m.Type]; !ok {
+			return
+		}
+	}
+
+	if t.every > 1 && (t.count.Inc()-1)%t.every != 0 {
+		return
+	}
+
+	t.fn(dir, m)
+}
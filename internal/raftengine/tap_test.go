@@ -0,0 +1,42 @@
+package raftengine
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestMessageTap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := NewMockConfig(ctrl)
+	cfg.EXPECT().MessageTap().Return(nil)
+
+	// it should be disabled by default.
+	tap := newMessageTap(cfg)
+	require.Nil(t, tap)
+	tap.tap(Inbound, etcdraftpb.Message{})
+
+	dirs := []Direction{}
+	msgs := []etcdraftpb.Message{}
+	cfg.EXPECT().MessageTap().Return(func(dir Direction, m etcdraftpb.Message) {
+		dirs = append(dirs, dir)
+		msgs = append(msgs, m)
+	})
+	cfg.EXPECT().MessageTapSampling().Return(uint64(2))
+	cfg.EXPECT().MessageTapTypes().Return([]etcdraftpb.MessageType{etcdraftpb.MsgApp})
+
+	tap = newMessageTap(cfg)
+	for i := 1; i <= 4; i++ {
+		tap.tap(Outbound, etcdraftpb.Message{Type: etcdraftpb.MsgHeartbeat, Index: uint64(i)})
+		tap.tap(Inbound, etcdraftpb.Message{Type: etcdraftpb.MsgApp, Index: uint64(i)})
+	}
+
+	// it should tap one of each two MsgApp messages.
+	require.Equal(t, []Direction{Inbound, Inbound}, dirs)
+	require.Equal(t, []etcdraftpb.Message{
+		{Type: etcdraftpb.MsgApp, Index: 1},
+		{Type: etcdraftpb.MsgApp, Index: 3},
+	}, msgs)
+}
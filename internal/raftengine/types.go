@@ -30,6 +30,9 @@ type Config interface {
 	Mux() Mux
 	RaftConfig() *raft.Config
 	SnapInterval() uint64
+	RetainEntries() uint64
+	MaxSnapshotDeltas() int
+	SnapshotSchedule() string
 	Pool() membership.Pool
 	Storage() storage.Storage
 	Dial() transport.Dial
@@ -37,9 +40,184 @@ type Config interface {
 	StateMachine() StateMachine
 	Context() context.Context
 	StateChangeCh() chan raft.StateType
+	SnapshotEventCh() chan SnapshotEvent
+	MemberEventCh() chan MemberEvent
+	DeadMemberTimeout() time.Duration
+	DisableAutoPromotion() bool
+	PromotionMinHealthy() time.Duration
+	MemberTypeMatcher() func(raftpb.Member) raftpb.MemberType
 	DrainTimeout() time.Duration
 	GroupID() uint64
 	Logger() raftlog.Logger
+	DiskLowWatermark() uint64
+	IDGenerator() func(memberID uint64) IDGenerator
+	ApplyHook() func(ApplyEvent)
+	ProposalWeight() int
+	SlowApplyThreshold() time.Duration
+	SlowSyncThreshold() time.Duration
+	SlowOpHook() func(SlowOpEvent)
+	MessageTap() func(Direction, etcdraftpb.Message)
+	MessageTapSampling() uint64
+	MessageTapTypes() []etcdraftpb.MessageType
+	EventBufferSize() int
+	CorruptCheckInterval() time.Duration
+	Clock() Clock
+	FaultInjector() FaultInjector
+	MaxWaiters() int
+	WaiterTTL() time.Duration
+	AuditLogRetention() int
+}
+
+// SlowOpType is the type of a slow operation.
+type SlowOpType int
+
+const (
+	// SlowApply is emitted when applying an entry to the state machine exceeds the threshold.
+	SlowApply SlowOpType = iota
+	// SlowSync is emitted when syncing the entries to the storage exceeds the threshold.
+	SlowSync
+)
+
+// String returns the slow operation type name.
+func (t SlowOpType) String() string {
+	switch t {
+	case SlowApply:
+		return "SlowApply"
+	case SlowSync:
+		return "SlowSync"
+	default:
+		return fmt.Sprintf("SlowOpType(%d)", int(t))
+	}
+}
+
+// SlowOpEvent describes a state machine apply or a storage sync that exceeded its threshold.
+type SlowOpEvent struct {
+	// Type of the slow operation.
+	Type SlowOpType
+	// Index of the applied entry, or the last synced entry.
+	Index uint64
+	// Duration of the operation.
+	Duration time.Duration
+}
+
+// SlowOps holds the number of the slow operations since the node started.
+type SlowOps struct {
+	// Applies is the number of the state machine applies that exceeded the threshold.
+	Applies uint64
+	// Syncs is the number of the storage syncs that exceeded the threshold.
+	Syncs uint64
+}
+
+// ApplyEvent describes a committed raft log entry applied to the state machine.
+type ApplyEvent struct {
+	// Term and Index of the entry.
+	Term  uint64
+	Index uint64
+	// Metadata is the application metadata attached to the entry proposal.
+	Metadata map[string]string
+	// Err is the state machine apply error, nil on success.
+	Err error
+}
+
+// IDGenerator generates the unique ids of the proposals, the linearizable reads,
+// and the conf changes, it must be safe for concurrent use.
+type IDGenerator interface {
+	Next() uint64
+}
+
+// SnapshotEventType is the type of a snapshot event.
+type SnapshotEventType int
+
+const (
+	// SnapshotCreated is emitted when creating a local snapshot ends.
+	SnapshotCreated SnapshotEventType = iota
+	// SnapshotSent is emitted when sending a snapshot to a member ends.
+	SnapshotSent
+)
+
+// String returns the snapshot event type name.
+func (t SnapshotEventType) String() string {
+	switch t {
+	case SnapshotCreated:
+		return "SnapshotCreated"
+	case SnapshotSent:
+		return "SnapshotSent"
+	default:
+		return fmt.Sprintf("SnapshotEventType(%d)", int(t))
+	}
+}
+
+// SnapshotEvent describes the creation of a local snapshot,
+// or the transfer of a snapshot to a member.
+type SnapshotEvent struct {
+	// Type of the snapshot event.
+	Type SnapshotEventType
+	// Term and Index of the snapshot.
+	Term  uint64
+	Index uint64
+	// Member is the destination member id of a snapshot transfer.
+	Member uint64
+	// Bytes is the number of snapshot data bytes written by the state machine
+	// when creating a snapshot, or the number of bytes of the snapshot files sent.
+	Bytes int64
+	// Duration of the snapshot creation or transfer.
+	Duration time.Duration
+	// Delta reports whether the created snapshot is a delta snapshot.
+	Delta bool
+	// Err is the failure reason, nil on success.
+	Err error
+}
+
+// MemberEventType is the type of a member event.
+type MemberEventType int
+
+const (
+	// MemberDeadRemoved is emitted when proposing the removal of a member,
+	// that has been unreachable longer than the dead member timeout.
+	MemberDeadRemoved MemberEventType = iota
+	// MemberUnreachable is emitted when a member becomes unreachable,
+	// after the messages failed to be sent within the retry policy.
+	MemberUnreachable
+	// MemberReachable is emitted when an unreachable member becomes reachable again.
+	MemberReachable
+)
+
+// String returns the member event type name.
+func (t MemberEventType) String() string {
+	switch t {
+	case MemberDeadRemoved:
+		return "MemberDeadRemoved"
+	case MemberUnreachable:
+		return "MemberUnreachable"
+	case MemberReachable:
+		return "MemberReachable"
+	default:
+		return fmt.Sprintf("MemberEventType(%d)", int(t))
+	}
+}
+
+// MemberEvent describes an action taken automatically on a cluster member.
+type MemberEvent struct {
+	// Type of the member event.
+	Type MemberEventType
+	// Member is the member the action was taken on.
+	Member raftpb.Member
+	// Unreachable is the duration the member has been unreachable.
+	Unreachable time.Duration
+	// Err is the failure reason, nil on success.
+	Err error
+}
+
+// LeaderInfo describes a change of the raft cluster leader, or the current node raft state.
+type LeaderInfo struct {
+	// ID is the leader member id, None when there is no elected leader.
+	ID uint64
+	// Address is the leader member address, empty when the leader is unknown to the current node.
+	Address string
+	// Term is the raft term at which the change observed.
+	Term uint64
+	// State is the current node raft state.
+	State raft.StateType
 }
 
 // StateMachine define an interface that must be implemented by
@@ -56,6 +234,147 @@ type StateMachine interface {
 	Restore(io.ReadCloser) error
 }
 
+// StateMachineV2 define a context-aware revision of the StateMachine interface,
+// the contexts cancelled on shutdown so the long-running operations terminate cleanly.
+type StateMachineV2 interface {
+	// Apply committed raft log entry.
+	Apply(ctx context.Context, data []byte) error
+
+	// Snapshot is used to write the current state to a snapshot file,
+	// on stable storage and compacting the raft logs.
+	Snapshot(ctx context.Context) (io.ReadCloser, error)
+
+	// Restore is used to restore state machine from a snapshot.
+	Restore(ctx context.Context, r io.ReadCloser) error
+}
+
+// FromStateMachineV2 returns a StateMachine that delegates to the given context-aware state machine,
+// the engine calls it with contexts cancelled on shutdown,
+// Otherwise, it called with context.Background().
+func FromStateMachineV2(sm StateMachineV2) StateMachine {
+	return stateMachineV2{sm: sm}
+}
+
+// AsStateMachineV2 returns the context-aware state machine wrapped by FromStateMachineV2.
+func AsStateMachineV2(sm StateMachine) (StateMachineV2, bool) {
+	v2, ok := sm.(stateMachineV2)
+	return v2.sm, ok
+}
+
+// stateMachineV2 implements StateMachine over a StateMachineV2.
+type stateMachineV2 struct {
+	sm StateMachineV2
+}
+
+func (s stateMachineV2) Apply(data []byte) error {
+	return s.sm.Apply(context.Background(), data)
+}
+
+func (s stateMachineV2) Snapshot() (io.ReadCloser, error) {
+	return s.sm.Snapshot(context.Background())
+}
+
+func (s stateMachineV2) Restore(r io.ReadCloser) error {
+	return s.sm.Restore(context.Background(), r)
+}
+
+// MetadataStateMachine is an optional interface implemented by a StateMachine,
+// to receive the application metadata attached to the entries proposals.
+type MetadataStateMachine interface {
+	StateMachine
+
+	// ApplyWithMetadata apply committed raft log entry, along with its proposal metadata.
+	// It called instead of Apply.
+	ApplyWithMetadata(data []byte, md map[string]string) error
+}
+
+// IncrementalStateMachine is an optional interface implemented by a StateMachine,
+// to write snapshots that only hold the changes applied since the previous snapshot,
+// chained onto a full snapshot.
+type IncrementalStateMachine interface {
+	StateMachine
+
+	// IncrementalSnapshot is used to write the changes applied
+	// after the given index to a snapshot file.
+	IncrementalSnapshot(sinceIndex uint64) (io.ReadCloser, error)
+
+	// RestoreIncremental is used to apply the changes of a delta snapshot
+	// on top of the restored state.
+	RestoreIncremental(io.ReadCloser) error
+}
+
+// HashStateMachine is an optional interface implemented by a StateMachine,
+// to detect the state machines divergence across the members.
+type HashStateMachine interface {
+	StateMachine
+
+	// Hash returns the hash of the state machine state, reflecting
+	// the entries applied up to the given index.
+	// It called on the apply path, at the same index on all the members.
+	Hash(upToIndex uint64) (uint64, error)
+}
+
+// FaultAction is the fault injected into an outbound message.
+type FaultAction int
+
+const (
+	// FaultNone sends the message as is.
+	FaultNone FaultAction = iota
+	// FaultDrop drops the message.
+	FaultDrop
+	// FaultDuplicate sends the message twice.
+	FaultDuplicate
+	// FaultDelay sends the message after a delay.
+	FaultDelay
+)
+
+// ApplyStage is the stage of applying an entry, at which a crash injected.
+type ApplyStage int
+
+const (
+	// BeforeApply is the stage before applying the entry to the state machine.
+	BeforeApply ApplyStage = iota
+	// AfterApply is the stage after applying the entry to the state machine.
+	AfterApply
+)
+
+// FaultInjector define the fault points injected into the engine for chaos testing,
+// it must be used for testing only.
+type FaultInjector interface {
+	// MessageFault returns the fault to inject into the given outbound message,
+	// and the delay of a FaultDelay.
+	MessageFault(m etcdraftpb.Message) (FaultAction, time.Duration)
+	// SyncFault returns an error to fail the storage sync of the entries up to the given index.
+	// The engine stops with the returned error, as a failed storage sync would.
+	SyncFault(index uint64) error
+	// ApplyFault returns an error to crash the engine at the given stage of applying the entry at the given index.
+	// The engine stops with the returned error, and the entries since the latest snapshot
+	// replayed from the WAL once the node restarted.
+	ApplyFault(stage ApplyStage, index uint64) error
+}
+
+// SnapshotSource is a consistent view of the state machine state,
+// captured by a TwoPhaseStateMachine to be serialized into a snapshot file.
+type SnapshotSource interface {
+	// Reader is used to serialize the captured state to the snapshot file,
+	// it called in a separate goroutine and may take long without blocking applies.
+	Reader() (io.ReadCloser, error)
+
+	// Release is invoked once the snapshot file is written or failed,
+	// to release the captured state resources.
+	Release()
+}
+
+// TwoPhaseStateMachine is an optional interface implemented by a StateMachine,
+// to decouple capturing a consistent view of the state, from serializing it to a snapshot file.
+type TwoPhaseStateMachine interface {
+	StateMachine
+
+	// PrepareSnapshot is used to quickly capture a consistent view of the current state,
+	// e.g. an LSM checkpoint, applies are blocked until it returns.
+	PrepareSnapshot() (SnapshotSource, error)
+}
+
 // Mux represents a multi node state that is participating in multiple consensus groups,
 // a mux is more efficient than a collection of nodes.
 // the name mux stands for "multiplexer". Like the standard "http.ServeMux".
@@ -74,6 +393,11 @@ type operatorsState struct {
 	ents             []etcdraftpb.Entry
 	sf               *storage.Snapshot
 	eng              *engine
+	addr             string
+	joinRetry        membership.RetryPolicy
+	fallback         bool
+	restored         string
+	clusterToken     string
 }
 
 type nodeLogger struct {
@@ -12,10 +12,12 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	membership "github.com/shaj13/raft/internal/membership"
+	raftpb "github.com/shaj13/raft/internal/raftpb"
 	storage "github.com/shaj13/raft/internal/storage"
 	transport "github.com/shaj13/raft/internal/transport"
 	raftlog "github.com/shaj13/raft/raftlog"
 	v3 "go.etcd.io/etcd/raft/v3"
+	raftpb0 "go.etcd.io/etcd/raft/v3/raftpb"
 )
 
 // MockOperator is a mock of Operator interface.
@@ -106,6 +108,48 @@ func (m *MockConfig) EXPECT() *MockConfigMockRecorder {
 	return m.recorder
 }
 
+// ApplyHook mocks base method.
+func (m *MockConfig) ApplyHook() func(ApplyEvent) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyHook")
+	ret0, _ := ret[0].(func(ApplyEvent))
+	return ret0
+}
+
+// ApplyHook indicates an expected call of ApplyHook.
+func (mr *MockConfigMockRecorder) ApplyHook() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyHook", reflect.TypeOf((*MockConfig)(nil).ApplyHook))
+}
+
+// AuditLogRetention mocks base method.
+func (m *MockConfig) AuditLogRetention() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLogRetention")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// AuditLogRetention indicates an expected call of AuditLogRetention.
+func (mr *MockConfigMockRecorder) AuditLogRetention() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLogRetention", reflect.TypeOf((*MockConfig)(nil).AuditLogRetention))
+}
+
+// Clock mocks base method.
+func (m *MockConfig) Clock() Clock {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clock")
+	ret0, _ := ret[0].(Clock)
+	return ret0
+}
+
+// Clock indicates an expected call of Clock.
+func (mr *MockConfigMockRecorder) Clock() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clock", reflect.TypeOf((*MockConfig)(nil).Clock))
+}
+
 // Context mocks base method.
 func (m *MockConfig) Context() context.Context {
 	m.ctrl.T.Helper()
@@ -120,6 +164,34 @@ func (mr *MockConfigMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockConfig)(nil).Context))
 }
 
+// CorruptCheckInterval mocks base method.
+func (m *MockConfig) CorruptCheckInterval() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CorruptCheckInterval")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// CorruptCheckInterval indicates an expected call of CorruptCheckInterval.
+func (mr *MockConfigMockRecorder) CorruptCheckInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CorruptCheckInterval", reflect.TypeOf((*MockConfig)(nil).CorruptCheckInterval))
+}
+
+// DeadMemberTimeout mocks base method.
+func (m *MockConfig) DeadMemberTimeout() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadMemberTimeout")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// DeadMemberTimeout indicates an expected call of DeadMemberTimeout.
+func (mr *MockConfigMockRecorder) DeadMemberTimeout() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadMemberTimeout", reflect.TypeOf((*MockConfig)(nil).DeadMemberTimeout))
+}
+
 // Dial mocks base method.
 func (m *MockConfig) Dial() transport.Dial {
 	m.ctrl.T.Helper()
@@ -134,6 +206,34 @@ func (mr *MockConfigMockRecorder) Dial() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dial", reflect.TypeOf((*MockConfig)(nil).Dial))
 }
 
+// DisableAutoPromotion mocks base method.
+func (m *MockConfig) DisableAutoPromotion() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableAutoPromotion")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// DisableAutoPromotion indicates an expected call of DisableAutoPromotion.
+func (mr *MockConfigMockRecorder) DisableAutoPromotion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableAutoPromotion", reflect.TypeOf((*MockConfig)(nil).DisableAutoPromotion))
+}
+
+// DiskLowWatermark mocks base method.
+func (m *MockConfig) DiskLowWatermark() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiskLowWatermark")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// DiskLowWatermark indicates an expected call of DiskLowWatermark.
+func (mr *MockConfigMockRecorder) DiskLowWatermark() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiskLowWatermark", reflect.TypeOf((*MockConfig)(nil).DiskLowWatermark))
+}
+
 // DrainTimeout mocks base method.
 func (m *MockConfig) DrainTimeout() time.Duration {
 	m.ctrl.T.Helper()
@@ -148,6 +248,34 @@ func (mr *MockConfigMockRecorder) DrainTimeout() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DrainTimeout", reflect.TypeOf((*MockConfig)(nil).DrainTimeout))
 }
 
+// EventBufferSize mocks base method.
+func (m *MockConfig) EventBufferSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EventBufferSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// EventBufferSize indicates an expected call of EventBufferSize.
+func (mr *MockConfigMockRecorder) EventBufferSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EventBufferSize", reflect.TypeOf((*MockConfig)(nil).EventBufferSize))
+}
+
+// FaultInjector mocks base method.
+func (m *MockConfig) FaultInjector() FaultInjector {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FaultInjector")
+	ret0, _ := ret[0].(FaultInjector)
+	return ret0
+}
+
+// FaultInjector indicates an expected call of FaultInjector.
+func (mr *MockConfigMockRecorder) FaultInjector() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FaultInjector", reflect.TypeOf((*MockConfig)(nil).FaultInjector))
+}
+
 // GroupID mocks base method.
 func (m *MockConfig) GroupID() uint64 {
 	m.ctrl.T.Helper()
@@ -162,6 +290,20 @@ func (mr *MockConfigMockRecorder) GroupID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupID", reflect.TypeOf((*MockConfig)(nil).GroupID))
 }
 
+// IDGenerator mocks base method.
+func (m *MockConfig) IDGenerator() func(uint64) IDGenerator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IDGenerator")
+	ret0, _ := ret[0].(func(uint64) IDGenerator)
+	return ret0
+}
+
+// IDGenerator indicates an expected call of IDGenerator.
+func (mr *MockConfigMockRecorder) IDGenerator() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IDGenerator", reflect.TypeOf((*MockConfig)(nil).IDGenerator))
+}
+
 // Logger mocks base method.
 func (m *MockConfig) Logger() raftlog.Logger {
 	m.ctrl.T.Helper()
@@ -176,6 +318,104 @@ func (mr *MockConfigMockRecorder) Logger() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logger", reflect.TypeOf((*MockConfig)(nil).Logger))
 }
 
+// MaxSnapshotDeltas mocks base method.
+func (m *MockConfig) MaxSnapshotDeltas() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxSnapshotDeltas")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxSnapshotDeltas indicates an expected call of MaxSnapshotDeltas.
+func (mr *MockConfigMockRecorder) MaxSnapshotDeltas() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxSnapshotDeltas", reflect.TypeOf((*MockConfig)(nil).MaxSnapshotDeltas))
+}
+
+// MaxWaiters mocks base method.
+func (m *MockConfig) MaxWaiters() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxWaiters")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxWaiters indicates an expected call of MaxWaiters.
+func (mr *MockConfigMockRecorder) MaxWaiters() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxWaiters", reflect.TypeOf((*MockConfig)(nil).MaxWaiters))
+}
+
+// MemberEventCh mocks base method.
+func (m *MockConfig) MemberEventCh() chan MemberEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MemberEventCh")
+	ret0, _ := ret[0].(chan MemberEvent)
+	return ret0
+}
+
+// MemberEventCh indicates an expected call of MemberEventCh.
+func (mr *MockConfigMockRecorder) MemberEventCh() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MemberEventCh", reflect.TypeOf((*MockConfig)(nil).MemberEventCh))
+}
+
+// MemberTypeMatcher mocks base method.
+func (m *MockConfig) MemberTypeMatcher() func(raftpb.Member) raftpb.MemberType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MemberTypeMatcher")
+	ret0, _ := ret[0].(func(raftpb.Member) raftpb.MemberType)
+	return ret0
+}
+
+// MemberTypeMatcher indicates an expected call of MemberTypeMatcher.
+func (mr *MockConfigMockRecorder) MemberTypeMatcher() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MemberTypeMatcher", reflect.TypeOf((*MockConfig)(nil).MemberTypeMatcher))
+}
+
+// MessageTap mocks base method.
+func (m *MockConfig) MessageTap() func(Direction, raftpb0.Message) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessageTap")
+	ret0, _ := ret[0].(func(Direction, raftpb0.Message))
+	return ret0
+}
+
+// MessageTap indicates an expected call of MessageTap.
+func (mr *MockConfigMockRecorder) MessageTap() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageTap", reflect.TypeOf((*MockConfig)(nil).MessageTap))
+}
+
+// MessageTapSampling mocks base method.
+func (m *MockConfig) MessageTapSampling() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessageTapSampling")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// MessageTapSampling indicates an expected call of MessageTapSampling.
+func (mr *MockConfigMockRecorder) MessageTapSampling() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageTapSampling", reflect.TypeOf((*MockConfig)(nil).MessageTapSampling))
+}
+
+// MessageTapTypes mocks base method.
+func (m *MockConfig) MessageTapTypes() []raftpb0.MessageType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessageTapTypes")
+	ret0, _ := ret[0].([]raftpb0.MessageType)
+	return ret0
+}
+
+// MessageTapTypes indicates an expected call of MessageTapTypes.
+func (mr *MockConfigMockRecorder) MessageTapTypes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageTapTypes", reflect.TypeOf((*MockConfig)(nil).MessageTapTypes))
+}
+
 // Mux mocks base method.
 func (m *MockConfig) Mux() Mux {
 	m.ctrl.T.Helper()
@@ -204,6 +444,34 @@ func (mr *MockConfigMockRecorder) Pool() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pool", reflect.TypeOf((*MockConfig)(nil).Pool))
 }
 
+// PromotionMinHealthy mocks base method.
+func (m *MockConfig) PromotionMinHealthy() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromotionMinHealthy")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// PromotionMinHealthy indicates an expected call of PromotionMinHealthy.
+func (mr *MockConfigMockRecorder) PromotionMinHealthy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromotionMinHealthy", reflect.TypeOf((*MockConfig)(nil).PromotionMinHealthy))
+}
+
+// ProposalWeight mocks base method.
+func (m *MockConfig) ProposalWeight() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposalWeight")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// ProposalWeight indicates an expected call of ProposalWeight.
+func (mr *MockConfigMockRecorder) ProposalWeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposalWeight", reflect.TypeOf((*MockConfig)(nil).ProposalWeight))
+}
+
 // RaftConfig mocks base method.
 func (m *MockConfig) RaftConfig() *v3.Config {
 	m.ctrl.T.Helper()
@@ -218,6 +486,62 @@ func (mr *MockConfigMockRecorder) RaftConfig() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RaftConfig", reflect.TypeOf((*MockConfig)(nil).RaftConfig))
 }
 
+// RetainEntries mocks base method.
+func (m *MockConfig) RetainEntries() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetainEntries")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// RetainEntries indicates an expected call of RetainEntries.
+func (mr *MockConfigMockRecorder) RetainEntries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetainEntries", reflect.TypeOf((*MockConfig)(nil).RetainEntries))
+}
+
+// SlowApplyThreshold mocks base method.
+func (m *MockConfig) SlowApplyThreshold() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlowApplyThreshold")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// SlowApplyThreshold indicates an expected call of SlowApplyThreshold.
+func (mr *MockConfigMockRecorder) SlowApplyThreshold() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlowApplyThreshold", reflect.TypeOf((*MockConfig)(nil).SlowApplyThreshold))
+}
+
+// SlowOpHook mocks base method.
+func (m *MockConfig) SlowOpHook() func(SlowOpEvent) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlowOpHook")
+	ret0, _ := ret[0].(func(SlowOpEvent))
+	return ret0
+}
+
+// SlowOpHook indicates an expected call of SlowOpHook.
+func (mr *MockConfigMockRecorder) SlowOpHook() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlowOpHook", reflect.TypeOf((*MockConfig)(nil).SlowOpHook))
+}
+
+// SlowSyncThreshold mocks base method.
+func (m *MockConfig) SlowSyncThreshold() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlowSyncThreshold")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// SlowSyncThreshold indicates an expected call of SlowSyncThreshold.
+func (mr *MockConfigMockRecorder) SlowSyncThreshold() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlowSyncThreshold", reflect.TypeOf((*MockConfig)(nil).SlowSyncThreshold))
+}
+
 // SnapInterval mocks base method.
 func (m *MockConfig) SnapInterval() uint64 {
 	m.ctrl.T.Helper()
@@ -232,6 +556,34 @@ func (mr *MockConfigMockRecorder) SnapInterval() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapInterval", reflect.TypeOf((*MockConfig)(nil).SnapInterval))
 }
 
+// SnapshotEventCh mocks base method.
+func (m *MockConfig) SnapshotEventCh() chan SnapshotEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotEventCh")
+	ret0, _ := ret[0].(chan SnapshotEvent)
+	return ret0
+}
+
+// SnapshotEventCh indicates an expected call of SnapshotEventCh.
+func (mr *MockConfigMockRecorder) SnapshotEventCh() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotEventCh", reflect.TypeOf((*MockConfig)(nil).SnapshotEventCh))
+}
+
+// SnapshotSchedule mocks base method.
+func (m *MockConfig) SnapshotSchedule() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotSchedule")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SnapshotSchedule indicates an expected call of SnapshotSchedule.
+func (mr *MockConfigMockRecorder) SnapshotSchedule() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotSchedule", reflect.TypeOf((*MockConfig)(nil).SnapshotSchedule))
+}
+
 // StateChangeCh mocks base method.
 func (m *MockConfig) StateChangeCh() chan v3.StateType {
 	m.ctrl.T.Helper()
@@ -288,6 +640,57 @@ func (mr *MockConfigMockRecorder) TickInterval() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TickInterval", reflect.TypeOf((*MockConfig)(nil).TickInterval))
 }
 
+// WaiterTTL mocks base method.
+func (m *MockConfig) WaiterTTL() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaiterTTL")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// WaiterTTL indicates an expected call of WaiterTTL.
+func (mr *MockConfigMockRecorder) WaiterTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaiterTTL", reflect.TypeOf((*MockConfig)(nil).WaiterTTL))
+}
+
+// MockIDGenerator is a mock of IDGenerator interface.
+type MockIDGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockIDGeneratorMockRecorder
+}
+
+// MockIDGeneratorMockRecorder is the mock recorder for MockIDGenerator.
+type MockIDGeneratorMockRecorder struct {
+	mock *MockIDGenerator
+}
+
+// NewMockIDGenerator creates a new mock instance.
+func NewMockIDGenerator(ctrl *gomock.Controller) *MockIDGenerator {
+	mock := &MockIDGenerator{ctrl: ctrl}
+	mock.recorder = &MockIDGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIDGenerator) EXPECT() *MockIDGeneratorMockRecorder {
+	return m.recorder
+}
+
+// Next mocks base method.
+func (m *MockIDGenerator) Next() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// Next indicates an expected call of Next.
+func (mr *MockIDGeneratorMockRecorder) Next() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockIDGenerator)(nil).Next))
+}
+
 // MockStateMachine is a mock of StateMachine interface.
 type MockStateMachine struct {
 	ctrl     *gomock.Controller
@@ -354,6 +757,525 @@ func (mr *MockStateMachineMockRecorder) Snapshot() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockStateMachine)(nil).Snapshot))
 }
 
+// MockStateMachineV2 is a mock of StateMachineV2 interface.
+type MockStateMachineV2 struct {
+	ctrl     *gomock.Controller
+	recorder *MockStateMachineV2MockRecorder
+}
+
+// MockStateMachineV2MockRecorder is the mock recorder for MockStateMachineV2.
+type MockStateMachineV2MockRecorder struct {
+	mock *MockStateMachineV2
+}
+
+// NewMockStateMachineV2 creates a new mock instance.
+func NewMockStateMachineV2(ctrl *gomock.Controller) *MockStateMachineV2 {
+	mock := &MockStateMachineV2{ctrl: ctrl}
+	mock.recorder = &MockStateMachineV2MockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStateMachineV2) EXPECT() *MockStateMachineV2MockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockStateMachineV2) Apply(ctx context.Context, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockStateMachineV2MockRecorder) Apply(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockStateMachineV2)(nil).Apply), ctx, data)
+}
+
+// Restore mocks base method.
+func (m *MockStateMachineV2) Restore(ctx context.Context, r io.ReadCloser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockStateMachineV2MockRecorder) Restore(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockStateMachineV2)(nil).Restore), ctx, r)
+}
+
+// Snapshot mocks base method.
+func (m *MockStateMachineV2) Snapshot(ctx context.Context) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockStateMachineV2MockRecorder) Snapshot(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockStateMachineV2)(nil).Snapshot), ctx)
+}
+
+// MockMetadataStateMachine is a mock of MetadataStateMachine interface.
+type MockMetadataStateMachine struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetadataStateMachineMockRecorder
+}
+
+// MockMetadataStateMachineMockRecorder is the mock recorder for MockMetadataStateMachine.
+type MockMetadataStateMachineMockRecorder struct {
+	mock *MockMetadataStateMachine
+}
+
+// NewMockMetadataStateMachine creates a new mock instance.
+func NewMockMetadataStateMachine(ctrl *gomock.Controller) *MockMetadataStateMachine {
+	mock := &MockMetadataStateMachine{ctrl: ctrl}
+	mock.recorder = &MockMetadataStateMachineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetadataStateMachine) EXPECT() *MockMetadataStateMachineMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockMetadataStateMachine) Apply(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockMetadataStateMachineMockRecorder) Apply(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockMetadataStateMachine)(nil).Apply), arg0)
+}
+
+// ApplyWithMetadata mocks base method.
+func (m *MockMetadataStateMachine) ApplyWithMetadata(data []byte, md map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyWithMetadata", data, md)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyWithMetadata indicates an expected call of ApplyWithMetadata.
+func (mr *MockMetadataStateMachineMockRecorder) ApplyWithMetadata(data, md interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyWithMetadata", reflect.TypeOf((*MockMetadataStateMachine)(nil).ApplyWithMetadata), data, md)
+}
+
+// Restore mocks base method.
+func (m *MockMetadataStateMachine) Restore(arg0 io.ReadCloser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockMetadataStateMachineMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockMetadataStateMachine)(nil).Restore), arg0)
+}
+
+// Snapshot mocks base method.
+func (m *MockMetadataStateMachine) Snapshot() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockMetadataStateMachineMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockMetadataStateMachine)(nil).Snapshot))
+}
+
+// MockIncrementalStateMachine is a mock of IncrementalStateMachine interface.
+type MockIncrementalStateMachine struct {
+	ctrl     *gomock.Controller
+	recorder *MockIncrementalStateMachineMockRecorder
+}
+
+// MockIncrementalStateMachineMockRecorder is the mock recorder for MockIncrementalStateMachine.
+type MockIncrementalStateMachineMockRecorder struct {
+	mock *MockIncrementalStateMachine
+}
+
+// NewMockIncrementalStateMachine creates a new mock instance.
+func NewMockIncrementalStateMachine(ctrl *gomock.Controller) *MockIncrementalStateMachine {
+	mock := &MockIncrementalStateMachine{ctrl: ctrl}
+	mock.recorder = &MockIncrementalStateMachineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIncrementalStateMachine) EXPECT() *MockIncrementalStateMachineMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockIncrementalStateMachine) Apply(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockIncrementalStateMachineMockRecorder) Apply(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockIncrementalStateMachine)(nil).Apply), arg0)
+}
+
+// IncrementalSnapshot mocks base method.
+func (m *MockIncrementalStateMachine) IncrementalSnapshot(sinceIndex uint64) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementalSnapshot", sinceIndex)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementalSnapshot indicates an expected call of IncrementalSnapshot.
+func (mr *MockIncrementalStateMachineMockRecorder) IncrementalSnapshot(sinceIndex interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementalSnapshot", reflect.TypeOf((*MockIncrementalStateMachine)(nil).IncrementalSnapshot), sinceIndex)
+}
+
+// Restore mocks base method.
+func (m *MockIncrementalStateMachine) Restore(arg0 io.ReadCloser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockIncrementalStateMachineMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockIncrementalStateMachine)(nil).Restore), arg0)
+}
+
+// RestoreIncremental mocks base method.
+func (m *MockIncrementalStateMachine) RestoreIncremental(arg0 io.ReadCloser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreIncremental", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreIncremental indicates an expected call of RestoreIncremental.
+func (mr *MockIncrementalStateMachineMockRecorder) RestoreIncremental(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreIncremental", reflect.TypeOf((*MockIncrementalStateMachine)(nil).RestoreIncremental), arg0)
+}
+
+// Snapshot mocks base method.
+func (m *MockIncrementalStateMachine) Snapshot() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockIncrementalStateMachineMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockIncrementalStateMachine)(nil).Snapshot))
+}
+
+// MockHashStateMachine is a mock of HashStateMachine interface.
+type MockHashStateMachine struct {
+	ctrl     *gomock.Controller
+	recorder *MockHashStateMachineMockRecorder
+}
+
+// MockHashStateMachineMockRecorder is the mock recorder for MockHashStateMachine.
+type MockHashStateMachineMockRecorder struct {
+	mock *MockHashStateMachine
+}
+
+// NewMockHashStateMachine creates a new mock instance.
+func NewMockHashStateMachine(ctrl *gomock.Controller) *MockHashStateMachine {
+	mock := &MockHashStateMachine{ctrl: ctrl}
+	mock.recorder = &MockHashStateMachineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHashStateMachine) EXPECT() *MockHashStateMachineMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockHashStateMachine) Apply(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockHashStateMachineMockRecorder) Apply(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockHashStateMachine)(nil).Apply), arg0)
+}
+
+// Hash mocks base method.
+func (m *MockHashStateMachine) Hash(upToIndex uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", upToIndex)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockHashStateMachineMockRecorder) Hash(upToIndex interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockHashStateMachine)(nil).Hash), upToIndex)
+}
+
+// Restore mocks base method.
+func (m *MockHashStateMachine) Restore(arg0 io.ReadCloser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockHashStateMachineMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockHashStateMachine)(nil).Restore), arg0)
+}
+
+// Snapshot mocks base method.
+func (m *MockHashStateMachine) Snapshot() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockHashStateMachineMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockHashStateMachine)(nil).Snapshot))
+}
+
+// MockFaultInjector is a mock of FaultInjector interface.
+type MockFaultInjector struct {
+	ctrl     *gomock.Controller
+	recorder *MockFaultInjectorMockRecorder
+}
+
+// MockFaultInjectorMockRecorder is the mock recorder for MockFaultInjector.
+type MockFaultInjectorMockRecorder struct {
+	mock *MockFaultInjector
+}
+
+// NewMockFaultInjector creates a new mock instance.
+func NewMockFaultInjector(ctrl *gomock.Controller) *MockFaultInjector {
+	mock := &MockFaultInjector{ctrl: ctrl}
+	mock.recorder = &MockFaultInjectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFaultInjector) EXPECT() *MockFaultInjectorMockRecorder {
+	return m.recorder
+}
+
+// ApplyFault mocks base method.
+func (m *MockFaultInjector) ApplyFault(stage ApplyStage, index uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyFault", stage, index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyFault indicates an expected call of ApplyFault.
+func (mr *MockFaultInjectorMockRecorder) ApplyFault(stage, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyFault", reflect.TypeOf((*MockFaultInjector)(nil).ApplyFault), stage, index)
+}
+
+// MessageFault mocks base method.
+func (m_2 *MockFaultInjector) MessageFault(m raftpb0.Message) (FaultAction, time.Duration) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "MessageFault", m)
+	ret0, _ := ret[0].(FaultAction)
+	ret1, _ := ret[1].(time.Duration)
+	return ret0, ret1
+}
+
+// MessageFault indicates an expected call of MessageFault.
+func (mr *MockFaultInjectorMockRecorder) MessageFault(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageFault", reflect.TypeOf((*MockFaultInjector)(nil).MessageFault), m)
+}
+
+// SyncFault mocks base method.
+func (m *MockFaultInjector) SyncFault(index uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncFault", index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncFault indicates an expected call of SyncFault.
+func (mr *MockFaultInjectorMockRecorder) SyncFault(index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncFault", reflect.TypeOf((*MockFaultInjector)(nil).SyncFault), index)
+}
+
+// MockSnapshotSource is a mock of SnapshotSource interface.
+type MockSnapshotSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotSourceMockRecorder
+}
+
+// MockSnapshotSourceMockRecorder is the mock recorder for MockSnapshotSource.
+type MockSnapshotSourceMockRecorder struct {
+	mock *MockSnapshotSource
+}
+
+// NewMockSnapshotSource creates a new mock instance.
+func NewMockSnapshotSource(ctrl *gomock.Controller) *MockSnapshotSource {
+	mock := &MockSnapshotSource{ctrl: ctrl}
+	mock.recorder = &MockSnapshotSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotSource) EXPECT() *MockSnapshotSourceMockRecorder {
+	return m.recorder
+}
+
+// Reader mocks base method.
+func (m *MockSnapshotSource) Reader() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reader")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reader indicates an expected call of Reader.
+func (mr *MockSnapshotSourceMockRecorder) Reader() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reader", reflect.TypeOf((*MockSnapshotSource)(nil).Reader))
+}
+
+// Release mocks base method.
+func (m *MockSnapshotSource) Release() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Release")
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockSnapshotSourceMockRecorder) Release() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockSnapshotSource)(nil).Release))
+}
+
+// MockTwoPhaseStateMachine is a mock of TwoPhaseStateMachine interface.
+type MockTwoPhaseStateMachine struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoPhaseStateMachineMockRecorder
+}
+
+// MockTwoPhaseStateMachineMockRecorder is the mock recorder for MockTwoPhaseStateMachine.
+type MockTwoPhaseStateMachineMockRecorder struct {
+	mock *MockTwoPhaseStateMachine
+}
+
+// NewMockTwoPhaseStateMachine creates a new mock instance.
+func NewMockTwoPhaseStateMachine(ctrl *gomock.Controller) *MockTwoPhaseStateMachine {
+	mock := &MockTwoPhaseStateMachine{ctrl: ctrl}
+	mock.recorder = &MockTwoPhaseStateMachineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoPhaseStateMachine) EXPECT() *MockTwoPhaseStateMachineMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockTwoPhaseStateMachine) Apply(arg0 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockTwoPhaseStateMachineMockRecorder) Apply(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockTwoPhaseStateMachine)(nil).Apply), arg0)
+}
+
+// PrepareSnapshot mocks base method.
+func (m *MockTwoPhaseStateMachine) PrepareSnapshot() (SnapshotSource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareSnapshot")
+	ret0, _ := ret[0].(SnapshotSource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrepareSnapshot indicates an expected call of PrepareSnapshot.
+func (mr *MockTwoPhaseStateMachineMockRecorder) PrepareSnapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareSnapshot", reflect.TypeOf((*MockTwoPhaseStateMachine)(nil).PrepareSnapshot))
+}
+
+// Restore mocks base method.
+func (m *MockTwoPhaseStateMachine) Restore(arg0 io.ReadCloser) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTwoPhaseStateMachineMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTwoPhaseStateMachine)(nil).Restore), arg0)
+}
+
+// Snapshot mocks base method.
+func (m *MockTwoPhaseStateMachine) Snapshot() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockTwoPhaseStateMachineMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockTwoPhaseStateMachine)(nil).Snapshot))
+}
+
 // MockMux is a mock of Mux interface.
 type MockMux struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,76 @@
+package raftengine
+
+import (
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+// ProtocolVersion is the protocol version supported by the local member,
+// advertised to the cluster within the local member record.
+//
+// Version 1 introduced the replicated alarms, audit log and the corruption checks.
+const ProtocolVersion uint32 = 1
+
+// Possible values for Feature.
+const (
+	// FeatureAuditLog replicates the administrative actions records.
+	FeatureAuditLog Feature = iota
+	// FeatureCorruptCheck replicates the state machine hash checks and reports.
+	FeatureCorruptCheck
+	// FeatureAlarms replicates the alarms activation and deactivation.
+	FeatureAlarms
+)
+
+// featureVersions maps the features to the protocol version that introduced them.
+var featureVersions = map[Feature]uint32{
+	FeatureAuditLog:     1,
+	FeatureCorruptCheck: 1,
+	FeatureAlarms:       1,
+}
+
+// Feature represents a protocol feature replicated to the cluster members,
+// it activated only once all the cluster members support it,
+// so the members not upgraded yet never see an entry they can't apply during a rolling upgrade.
+type Feature int
+
+// Version returns the protocol version that introduced the feature.
+func (f Feature) Version() uint32 {
+	return featureVersions[f]
+}
+
+func (f Feature) String() string {
+	switch f {
+	case FeatureAuditLog:
+		return "AuditLog"
+	case FeatureCorruptCheck:
+		return "CorruptCheck"
+	case FeatureAlarms:
+		return "Alarms"
+	default:
+		return "Unknown"
+	}
+}
+
+// ClusterVersion returns the protocol version supported by all the cluster members,
+// the minimum version advertised by the members records.
+// It returns ProtocolVersion when the membership not known yet.
+func (eng *engine) ClusterVersion() uint32 {
+	v := ProtocolVersion
+	for _, m := range eng.pool.Members() {
+		raw := m.Raw()
+		if raw.Type == raftpb.RemovedMember {
+			continue
+		}
+
+		if raw.Version < v {
+			v = raw.Version
+		}
+	}
+
+	return v
+}
+
+// Supports reports whether the given feature is active,
+// i.e. all the cluster members support it.
+func (eng *engine) Supports(f Feature) bool {
+	return f.Version() <= eng.ClusterVersion()
+}
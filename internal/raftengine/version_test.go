@@ -0,0 +1,90 @@
+package raftengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/membership"
+	membershipmock "github.com/shaj13/raft/internal/mocks/membership"
+	"github.com/shaj13/raft/internal/msgbus"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/raftlog"
+)
+
+func TestClusterVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pool := membershipmock.NewMockPool(ctrl)
+	eng := &engine{pool: pool}
+
+	member := func(raw raftpb.Member) membership.Member {
+		mem := membershipmock.NewMockMember(ctrl)
+		mem.EXPECT().Raw().Return(raw).AnyTimes()
+		return mem
+	}
+
+	// it return the local version when the membership not known yet.
+	pool.EXPECT().Members().Return(nil)
+	require.Equal(t, ProtocolVersion, eng.ClusterVersion())
+
+	// it return the minimum members version, ignoring the removed members.
+	pool.EXPECT().Members().Return([]membership.Member{
+		member(raftpb.Member{ID: 1, Version: ProtocolVersion}),
+		member(raftpb.Member{ID: 2, Type: raftpb.RemovedMember}),
+	}).Times(2)
+	require.Equal(t, ProtocolVersion, eng.ClusterVersion())
+	require.True(t, eng.Supports(FeatureAuditLog))
+
+	// it deactivate the features until all the members upgraded.
+	pool.EXPECT().Members().Return([]membership.Member{
+		member(raftpb.Member{ID: 1, Version: ProtocolVersion}),
+		member(raftpb.Member{ID: 3}),
+	}).Times(2)
+	require.Equal(t, uint32(0), eng.ClusterVersion())
+	require.False(t, eng.Supports(FeatureCorruptCheck))
+}
+
+func TestRecordAuditUnsupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	mem.EXPECT().Raw().Return(raftpb.Member{ID: 1}).AnyTimes()
+	pool.EXPECT().Members().Return([]membership.Member{mem}).AnyTimes()
+
+	// it does not propose the audit record, node mock not set.
+	eng := &engine{
+		logger: raftlog.DefaultLogger,
+		pool:   pool,
+		local:  &raftpb.Member{ID: 1},
+	}
+	eng.recordAudit(context.TODO(), raftpb.AuditTransferLeadership, "")
+}
+
+func TestPublishReplicateUnsupported(t *testing.T) {
+	eng := &engine{
+		logger: raftlog.DefaultLogger,
+		msgbus: msgbus.New(),
+	}
+
+	sub, err := eng.msgbus.SubscribeOnce(1)
+	require.NoError(t, err)
+
+	r := raftpb.Replicate{CID: 1, Type: raftpb.ReplicateType(100)}
+	buf, err := r.Marshal()
+	require.NoError(t, err)
+
+	// it reject the replicate type unknown to the local protocol version.
+	eng.publishReplicate(etcdraftpb.Entry{Data: buf})
+
+	select {
+	case v := <-sub.Chan():
+		require.Error(t, v.(error))
+		require.Contains(t, v.(error).Error(), "unsupported replicate type")
+	case <-time.After(time.Second):
+		t.Fatal("expected replicate result")
+	}
+}
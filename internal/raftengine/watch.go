@@ -0,0 +1,166 @@
+package raftengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"go.etcd.io/etcd/raft/v3"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// Possible values for the watchers buffers.
+const (
+	// watchBuffer is the size of a watcher channel buffer.
+	watchBuffer = 128
+	// watchBatch is the max number of entries read from the log at once.
+	watchBatch = 64
+)
+
+// WatchEvent is an applied entry streamed to a watcher, See Engine.Watch.
+type WatchEvent struct {
+	// Index is the entry log index.
+	Index uint64
+	// Term is the entry term.
+	Term uint64
+	// Data is the replicated data applied to the state machine.
+	Data []byte
+	// Metadata is the entry proposal metadata, if any.
+	Metadata map[string]string
+	// Err is the error terminating the watch, e.g. ErrCompacted
+	// when the watcher fell behind the log compaction, the channel closed afterward.
+	Err error
+}
+
+// appliedNotifier notifies the watchers of the applied index progress.
+type appliedNotifier struct {
+	mu sync.Mutex
+	c  chan struct{}
+}
+
+// wait returns a channel closed once the applied index advanced.
+func (n *appliedNotifier) wait() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.c == nil {
+		n.c = make(chan struct{})
+	}
+
+	return n.c
+}
+
+func (n *appliedNotifier) notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.c != nil {
+		close(n.c)
+		n.c = nil
+	}
+}
+
+// Watch returns a channel streaming the replicated data entries applied to the state machine,
+// starting from the given index, replayed from the retained log entries,
+// or starting from the next applied entry if the given index is zero.
+// The channel closed once the given context done or the engine stopped.
+func (eng *engine) Watch(ctx context.Context, fromIndex uint64) (<-chan WatchEvent, error) {
+	if eng.started.False() {
+		return nil, ErrStopped
+	}
+
+	if fromIndex == 0 {
+		fromIndex = eng.appliedIndex.Get() + 1
+	}
+
+	first, err := eng.cache.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	if fromIndex < first {
+		return nil, fmt.Errorf("%w: watch index %d, first retained index %d", ErrCompacted, fromIndex, first)
+	}
+
+	c := make(chan WatchEvent, watchBuffer)
+	eng.wg.Add(1)
+	go eng.watch(ctx, fromIndex, c)
+	return c, nil
+}
+
+func (eng *engine) watch(ctx context.Context, next uint64, c chan WatchEvent) {
+	defer eng.wg.Done()
+	defer close(c)
+
+	send := func(ev WatchEvent) bool {
+		select {
+		case c <- ev:
+			return true
+		case <-ctx.Done():
+		case <-eng.ctx.Done():
+		}
+		return false
+	}
+
+	for {
+		// wait before reading the applied index, so no progress missed.
+		wait := eng.applied.wait()
+		applied := eng.appliedIndex.Get()
+
+		for next <= applied {
+			hi := applied + 1
+			if hi-next > watchBatch {
+				hi = next + watchBatch
+			}
+
+			ents, err := eng.cache.Entries(next, hi, math.MaxUint64)
+			if errors.Is(err, raft.ErrCompacted) {
+				err = fmt.Errorf("%w: watch index %d", ErrCompacted, next)
+			}
+
+			if err != nil {
+				send(WatchEvent{Err: err})
+				return
+			}
+
+			for _, ent := range ents {
+				next = ent.Index + 1
+				ev, ok := watchEvent(ent)
+				if ok && !send(ev) {
+					return
+				}
+			}
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return
+		case <-eng.ctx.Done():
+			return
+		}
+	}
+}
+
+// watchEvent returns the watch event of the given entry,
+// It returns false if the entry is not a replicated data entry.
+func watchEvent(ent etcdraftpb.Entry) (WatchEvent, bool) {
+	if ent.Type != etcdraftpb.EntryNormal || len(ent.Data) == 0 {
+		return WatchEvent{}, false
+	}
+
+	r := new(raftpb.Replicate)
+	if err := r.Unmarshal(ent.Data); err != nil || r.Type != raftpb.ReplicateData {
+		return WatchEvent{}, false
+	}
+
+	return WatchEvent{
+		Index:    ent.Index,
+		Term:     ent.Term,
+		Data:     r.Data,
+		Metadata: r.Metadata,
+	}, true
+}
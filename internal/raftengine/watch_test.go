@@ -0,0 +1,124 @@
+package raftengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/raft/v3"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/atomic"
+	"github.com/shaj13/raft/internal/msgbus"
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+func TestWatch(t *testing.T) {
+	eng := testWatchEngine(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ents := []etcdraftpb.Entry{
+		watchEntry(1, raftpb.Replicate{Type: raftpb.ReplicateData, Data: []byte("1")}),
+		{Index: 2, Term: 1, Type: etcdraftpb.EntryConfChange},
+		watchEntry(3, raftpb.Replicate{Type: raftpb.ReplicateAlarm}),
+		watchEntry(4, raftpb.Replicate{Type: raftpb.ReplicateData, Data: []byte("4")}),
+	}
+
+	require.NoError(t, eng.cache.Append(ents))
+	eng.appliedIndex.Set(2)
+
+	// it replay the applied data entries.
+	c, err := eng.Watch(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, WatchEvent{Index: 1, Term: 1, Data: []byte("1")}, recvWatchEvent(t, c))
+
+	// it stream the data entries once applied.
+	eng.appliedIndex.Set(4)
+	eng.publishAppliedIndices(2, 4)
+	require.Equal(t, WatchEvent{Index: 4, Term: 1, Data: []byte("4")}, recvWatchEvent(t, c))
+
+	// it close the channel once the context done.
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-c
+		return !ok
+	}, time.Second, time.Millisecond)
+}
+
+func TestWatchCompacted(t *testing.T) {
+	eng := testWatchEngine(t)
+	ents := []etcdraftpb.Entry{
+		watchEntry(1, raftpb.Replicate{Type: raftpb.ReplicateData}),
+		watchEntry(2, raftpb.Replicate{Type: raftpb.ReplicateData}),
+	}
+
+	require.NoError(t, eng.cache.Append(ents))
+	require.NoError(t, eng.cache.Compact(1))
+	eng.appliedIndex.Set(2)
+
+	// it return error when the index already compacted.
+	_, err := eng.Watch(context.Background(), 1)
+	require.ErrorIs(t, err, ErrCompacted)
+
+	// it terminate the watcher fell behind the compaction.
+	c, err := eng.Watch(context.Background(), 3)
+	require.NoError(t, err)
+	require.NoError(t, eng.cache.ApplySnapshot(etcdraftpb.Snapshot{
+		Metadata: etcdraftpb.SnapshotMetadata{Index: 5, Term: 1},
+	}))
+	eng.appliedIndex.Set(5)
+	eng.publishAppliedIndices(2, 5)
+
+	ev := recvWatchEvent(t, c)
+	require.ErrorIs(t, ev.Err, ErrCompacted)
+	_, ok := <-c
+	require.False(t, ok)
+}
+
+func TestWatchStopped(t *testing.T) {
+	eng := &engine{started: atomic.NewBool()}
+	_, err := eng.Watch(context.Background(), 0)
+	require.ErrorIs(t, err, ErrStopped)
+}
+
+func testWatchEngine(t *testing.T) *engine {
+	eng := &engine{
+		started:      atomic.NewBool(),
+		appliedIndex: atomic.NewUint64(),
+		applied:      new(appliedNotifier),
+		cache:        raft.NewMemoryStorage(),
+		msgbus:       msgbus.New(),
+	}
+
+	eng.ctx, eng.cancel = context.WithCancel(context.Background())
+	eng.started.Set()
+	t.Cleanup(func() {
+		eng.cancel()
+		eng.wg.Wait()
+	})
+
+	return eng
+}
+
+func watchEntry(index uint64, r raftpb.Replicate) etcdraftpb.Entry {
+	return etcdraftpb.Entry{
+		Index: index,
+		Term:  1,
+		Type:  etcdraftpb.EntryNormal,
+		Data:  pbutil.MustMarshal(&r),
+	}
+}
+
+func recvWatchEvent(t *testing.T, c <-chan WatchEvent) WatchEvent {
+	select {
+	case ev := <-c:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("expected watch event")
+	}
+
+	return WatchEvent{}
+}
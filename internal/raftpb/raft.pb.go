@@ -24,6 +24,155 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+type ReplicateType int32
+
+const (
+	ReplicateData       ReplicateType = 0
+	ReplicateAlarm      ReplicateType = 1
+	ReplicateAudit      ReplicateType = 2
+	ReplicateHashCheck  ReplicateType = 3
+	ReplicateHashReport ReplicateType = 4
+)
+
+var ReplicateType_name = map[int32]string{
+	0: "data",
+	1: "alarm",
+	2: "audit",
+	3: "hash_check",
+	4: "hash_report",
+}
+
+var ReplicateType_value = map[string]int32{
+	"data":        0,
+	"alarm":       1,
+	"audit":       2,
+	"hash_check":  3,
+	"hash_report": 4,
+}
+
+func (x ReplicateType) String() string {
+	return proto.EnumName(ReplicateType_name, int32(x))
+}
+
+func (ReplicateType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{0}
+}
+
+type AuditAction int32
+
+const (
+	AuditConfChange         AuditAction = 0
+	AuditTransferLeadership AuditAction = 1
+	AuditForceSnapshot      AuditAction = 2
+	AuditRestore            AuditAction = 3
+)
+
+var AuditAction_name = map[int32]string{
+	0: "conf_change",
+	1: "transfer_leadership",
+	2: "force_snapshot",
+	3: "restore_snapshot",
+}
+
+var AuditAction_value = map[string]int32{
+	"conf_change":         0,
+	"transfer_leadership": 1,
+	"force_snapshot":      2,
+	"restore_snapshot":    3,
+}
+
+func (x AuditAction) String() string {
+	return proto.EnumName(AuditAction_name, int32(x))
+}
+
+func (AuditAction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{1}
+}
+
+type AlarmType int32
+
+const (
+	NoneAlarm    AlarmType = 0
+	NoSpaceAlarm AlarmType = 1
+	CorruptAlarm AlarmType = 2
+)
+
+var AlarmType_name = map[int32]string{
+	0: "none",
+	1: "nospace",
+	2: "corrupt",
+}
+
+var AlarmType_value = map[string]int32{
+	"none":    0,
+	"nospace": 1,
+	"corrupt": 2,
+}
+
+func (x AlarmType) String() string {
+	return proto.EnumName(AlarmType_name, int32(x))
+}
+
+func (AlarmType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{2}
+}
+
+type Compression int32
+
+const (
+	NoCompression     Compression = 0
+	SnappyCompression Compression = 1
+	GzipCompression   Compression = 2
+	ZstdCompression   Compression = 3
+)
+
+var Compression_name = map[int32]string{
+	0: "none_compression",
+	1: "snappy",
+	2: "gzip",
+	3: "zstd",
+}
+
+var Compression_value = map[string]int32{
+	"none_compression": 0,
+	"snappy":           1,
+	"gzip":             2,
+	"zstd":             3,
+}
+
+func (x Compression) String() string {
+	return proto.EnumName(Compression_name, int32(x))
+}
+
+func (Compression) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{3}
+}
+
+type AlarmAction int32
+
+const (
+	ActivateAlarm   AlarmAction = 0
+	DeactivateAlarm AlarmAction = 1
+)
+
+var AlarmAction_name = map[int32]string{
+	0: "activate",
+	1: "deactivate",
+}
+
+var AlarmAction_value = map[string]int32{
+	"activate":   0,
+	"deactivate": 1,
+}
+
+func (x AlarmAction) String() string {
+	return proto.EnumName(AlarmAction_name, int32(x))
+}
+
+func (AlarmAction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{4}
+}
+
 type MemberType int32
 
 const (
@@ -55,7 +204,7 @@ func (x MemberType) String() string {
 }
 
 func (MemberType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_dbd5440484cc1d7f, []int{0}
+	return fileDescriptor_dbd5440484cc1d7f, []int{5}
 }
 
 // Version represents the snapshot file version.
@@ -79,7 +228,7 @@ func (x SnapshotState_Version) String() string {
 }
 
 func (SnapshotState_Version) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_dbd5440484cc1d7f, []int{3, 0}
+	return fileDescriptor_dbd5440484cc1d7f, []int{8, 0}
 }
 
 type Member struct {
@@ -91,7 +240,18 @@ type Member struct {
 	Type MemberType `protobuf:"varint,3,opt,name=type,proto3,enum=raftpb.MemberType" json:"type,omitempty"`
 	// Context is treated as an opaque payload and can be used to
 	// attach an extra info/data on member.
-	Context              []byte   `protobuf:"bytes,4,opt,name=context,proto3" json:"context,omitempty"`
+	Context []byte `protobuf:"bytes,4,opt,name=context,proto3" json:"context,omitempty"`
+	// Labels specifies the member metadata replicated cluster-wide (zone, rack, version, etc).
+	Labels map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Draining specifies whether the member is draining for maintenance,
+	// it does not receive the leadership and clients should stop targeting it.
+	Draining bool `protobuf:"varint,6,opt,name=draining,proto3" json:"draining,omitempty"`
+	// Version specifies the protocol version supported by the member,
+	// zero for the members predating the protocol versioning.
+	Version uint32 `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	// ClusterID specifies the id of the cluster the member belongs to,
+	// persisted along with the local member, zero when unknown.
+	ClusterID            uint64   `protobuf:"varint,8,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -134,10 +294,15 @@ type Replicate struct {
 	// CID specifies the transaction change id.
 	CID uint64 `protobuf:"varint,1,opt,name=cid,proto3" json:"cid,omitempty"`
 	// Data specifies the raw replicate data.
-	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// Type used to distinguish replicate data (state machine, alarm, etc).
+	Type ReplicateType `protobuf:"varint,3,opt,name=type,proto3,enum=raftpb.ReplicateType" json:"type,omitempty"`
+	// Metadata specifies the application metadata attached to the replicate data,
+	// e.g. request id, trace id, or principal.
+	Metadata             map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *Replicate) Reset()         { *m = Replicate{} }
@@ -173,11 +338,196 @@ func (m *Replicate) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Replicate proto.InternalMessageInfo
 
+type HashReport struct {
+	// Member specifies the id of the member that computed the hash.
+	Member uint64 `protobuf:"varint,1,opt,name=member,proto3" json:"member,omitempty"`
+	// Index specifies the applied index the hash computed at.
+	Index uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Hash specifies the state machine hash.
+	Hash                 uint64   `protobuf:"varint,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HashReport) Reset()         { *m = HashReport{} }
+func (m *HashReport) String() string { return proto.CompactTextString(m) }
+func (*HashReport) ProtoMessage()    {}
+func (*HashReport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{2}
+}
+func (m *HashReport) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HashReport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_HashReport.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *HashReport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HashReport.Merge(m, src)
+}
+func (m *HashReport) XXX_Size() int {
+	return m.Size()
+}
+func (m *HashReport) XXX_DiscardUnknown() {
+	xxx_messageInfo_HashReport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HashReport proto.InternalMessageInfo
+
+type AuditRecord struct {
+	// Index specifies the raft log index of the record entry.
+	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Time specifies the action time in unix nanoseconds.
+	Time int64 `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	// Member specifies the id of the member that performed the action.
+	Member uint64 `protobuf:"varint,3,opt,name=member,proto3" json:"member,omitempty"`
+	// Action specifies the administrative action.
+	Action AuditAction `protobuf:"varint,4,opt,name=action,proto3,enum=raftpb.AuditAction" json:"action,omitempty"`
+	// Detail specifies the action description, e.g. the target member.
+	Detail string `protobuf:"bytes,5,opt,name=detail,proto3" json:"detail,omitempty"`
+	// Metadata specifies the application metadata attached to the action context,
+	// e.g. request id or principal.
+	Metadata             map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *AuditRecord) Reset()         { *m = AuditRecord{} }
+func (m *AuditRecord) String() string { return proto.CompactTextString(m) }
+func (*AuditRecord) ProtoMessage()    {}
+func (*AuditRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{3}
+}
+func (m *AuditRecord) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AuditRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AuditRecord.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AuditRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditRecord.Merge(m, src)
+}
+func (m *AuditRecord) XXX_Size() int {
+	return m.Size()
+}
+func (m *AuditRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditRecord proto.InternalMessageInfo
+
+type Alarm struct {
+	// ID specifies the id of the member that raised the alarm.
+	ID uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Type specifies the alarm type.
+	Type                 AlarmType `protobuf:"varint,2,opt,name=type,proto3,enum=raftpb.AlarmType" json:"type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Alarm) Reset()         { *m = Alarm{} }
+func (m *Alarm) String() string { return proto.CompactTextString(m) }
+func (*Alarm) ProtoMessage()    {}
+func (*Alarm) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{4}
+}
+func (m *Alarm) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Alarm) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Alarm.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Alarm) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Alarm.Merge(m, src)
+}
+func (m *Alarm) XXX_Size() int {
+	return m.Size()
+}
+func (m *Alarm) XXX_DiscardUnknown() {
+	xxx_messageInfo_Alarm.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Alarm proto.InternalMessageInfo
+
+type AlarmChange struct {
+	// Action specifies whether to activate or deactivate the alarm.
+	Action AlarmAction `protobuf:"varint,1,opt,name=action,proto3,enum=raftpb.AlarmAction" json:"action,omitempty"`
+	// Alarm specifies the alarm to change.
+	Alarm                Alarm    `protobuf:"bytes,2,opt,name=alarm,proto3" json:"alarm"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AlarmChange) Reset()         { *m = AlarmChange{} }
+func (m *AlarmChange) String() string { return proto.CompactTextString(m) }
+func (*AlarmChange) ProtoMessage()    {}
+func (*AlarmChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{5}
+}
+func (m *AlarmChange) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AlarmChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AlarmChange.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AlarmChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AlarmChange.Merge(m, src)
+}
+func (m *AlarmChange) XXX_Size() int {
+	return m.Size()
+}
+func (m *AlarmChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_AlarmChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AlarmChange proto.InternalMessageInfo
+
 type JoinResponse struct {
 	// ID specifies the ID assigned to the new member..
 	ID uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	// Members specifies the cluster pool members.
-	Members              []Member `protobuf:"bytes,2,rep,name=members,proto3" json:"members"`
+	Members []Member `protobuf:"bytes,2,rep,name=members,proto3" json:"members"`
+	// ClusterID specifies the id of the cluster the new member joined.
+	ClusterID            uint64   `protobuf:"varint,3,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -187,7 +537,7 @@ func (m *JoinResponse) Reset()         { *m = JoinResponse{} }
 func (m *JoinResponse) String() string { return proto.CompactTextString(m) }
 func (*JoinResponse) ProtoMessage()    {}
 func (*JoinResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dbd5440484cc1d7f, []int{2}
+	return fileDescriptor_dbd5440484cc1d7f, []int{6}
 }
 func (m *JoinResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -216,6 +566,47 @@ func (m *JoinResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_JoinResponse proto.InternalMessageInfo
 
+type MessageBatch struct {
+	// Messages specifies the raft messages sent in a single rpc.
+	Messages             []raftpb.Message `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *MessageBatch) Reset()         { *m = MessageBatch{} }
+func (m *MessageBatch) String() string { return proto.CompactTextString(m) }
+func (*MessageBatch) ProtoMessage()    {}
+func (*MessageBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dbd5440484cc1d7f, []int{7}
+}
+func (m *MessageBatch) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MessageBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MessageBatch.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MessageBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MessageBatch.Merge(m, src)
+}
+func (m *MessageBatch) XXX_Size() int {
+	return m.Size()
+}
+func (m *MessageBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_MessageBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MessageBatch proto.InternalMessageInfo
+
 type SnapshotState struct {
 	// CRC specifies the snapshot crc sum.
 	CRC []byte `protobuf:"bytes,1,opt,name=CRC,proto3" json:"CRC,omitempty"`
@@ -224,17 +615,35 @@ type SnapshotState struct {
 	// Members specifies the cluster pool members.
 	Members []Member `protobuf:"bytes,3,rep,name=members,proto3" json:"members"`
 	// Raw specifies the the etcd raftpb snapshot.
-	Raw                  raftpb.Snapshot `protobuf:"bytes,4,opt,name=Raw,proto3" json:"Raw"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	Raw raftpb.Snapshot `protobuf:"bytes,4,opt,name=Raw,proto3" json:"Raw"`
+	// Alarms specifies the cluster active alarms.
+	Alarms []Alarm `protobuf:"bytes,5,rep,name=alarms,proto3" json:"alarms"`
+	// Compression specifies the snapshot data compression.
+	Compression Compression `protobuf:"varint,6,opt,name=compression,proto3,enum=raftpb.Compression" json:"compression,omitempty"`
+	// BaseTerm specifies the term of the base snapshot,
+	// the snapshot data chained onto, zero for full snapshots.
+	BaseTerm uint64 `protobuf:"varint,7,opt,name=base_term,json=baseTerm,proto3" json:"base_term,omitempty"`
+	// BaseIndex specifies the index of the base snapshot,
+	// the snapshot data chained onto, zero for full snapshots.
+	BaseIndex uint64 `protobuf:"varint,8,opt,name=base_index,json=baseIndex,proto3" json:"base_index,omitempty"`
+	// SHA256 specifies the snapshot data sha256 sum.
+	SHA256 []byte `protobuf:"bytes,9,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	// ChunkCRCs specifies the crc32 sums of the snapshot data chunks.
+	ChunkCRCs []uint32 `protobuf:"varint,10,rep,packed,name=chunk_crcs,json=chunkCrcs,proto3" json:"chunk_crcs,omitempty"`
+	// CreatedAt specifies the snapshot creation time in unix nanoseconds.
+	CreatedAt int64 `protobuf:"varint,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Audit specifies the retained records of the replicated audit log.
+	Audit                []AuditRecord `protobuf:"bytes,12,rep,name=audit,proto3" json:"audit"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
 func (m *SnapshotState) Reset()         { *m = SnapshotState{} }
 func (m *SnapshotState) String() string { return proto.CompactTextString(m) }
 func (*SnapshotState) ProtoMessage()    {}
 func (*SnapshotState) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dbd5440484cc1d7f, []int{3}
+	return fileDescriptor_dbd5440484cc1d7f, []int{8}
 }
 func (m *SnapshotState) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -264,49 +673,118 @@ func (m *SnapshotState) XXX_DiscardUnknown() {
 var xxx_messageInfo_SnapshotState proto.InternalMessageInfo
 
 func init() {
+	proto.RegisterEnum("raftpb.ReplicateType", ReplicateType_name, ReplicateType_value)
+	proto.RegisterEnum("raftpb.AuditAction", AuditAction_name, AuditAction_value)
+	proto.RegisterEnum("raftpb.AlarmType", AlarmType_name, AlarmType_value)
+	proto.RegisterEnum("raftpb.Compression", Compression_name, Compression_value)
+	proto.RegisterEnum("raftpb.AlarmAction", AlarmAction_name, AlarmAction_value)
 	proto.RegisterEnum("raftpb.MemberType", MemberType_name, MemberType_value)
 	proto.RegisterEnum("raftpb.SnapshotState_Version", SnapshotState_Version_name, SnapshotState_Version_value)
 	proto.RegisterType((*Member)(nil), "raftpb.Member")
+	proto.RegisterMapType((map[string]string)(nil), "raftpb.Member.LabelsEntry")
 	proto.RegisterType((*Replicate)(nil), "raftpb.Replicate")
+	proto.RegisterMapType((map[string]string)(nil), "raftpb.Replicate.MetadataEntry")
+	proto.RegisterType((*HashReport)(nil), "raftpb.HashReport")
+	proto.RegisterType((*AuditRecord)(nil), "raftpb.AuditRecord")
+	proto.RegisterMapType((map[string]string)(nil), "raftpb.AuditRecord.MetadataEntry")
+	proto.RegisterType((*Alarm)(nil), "raftpb.Alarm")
+	proto.RegisterType((*AlarmChange)(nil), "raftpb.AlarmChange")
 	proto.RegisterType((*JoinResponse)(nil), "raftpb.JoinResponse")
+	proto.RegisterType((*MessageBatch)(nil), "raftpb.MessageBatch")
 	proto.RegisterType((*SnapshotState)(nil), "raftpb.SnapshotState")
 }
 
 func init() { proto.RegisterFile("internal/raftpb/raft.proto", fileDescriptor_dbd5440484cc1d7f) }
 
 var fileDescriptor_dbd5440484cc1d7f = []byte{
-	// 491 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0x41, 0x6e, 0xd3, 0x40,
-	0x14, 0x86, 0x33, 0xb6, 0x1b, 0xab, 0x2f, 0x69, 0x70, 0x47, 0x15, 0x32, 0x96, 0xb0, 0x2d, 0x2f,
-	0x90, 0x61, 0xe1, 0xa0, 0x74, 0x81, 0xc4, 0x8e, 0xb4, 0x9b, 0xa2, 0xb2, 0x99, 0xa0, 0xec, 0x27,
-	0xf6, 0x60, 0x2c, 0x39, 0x1e, 0x6b, 0x3c, 0x0a, 0x74, 0xcd, 0xae, 0x77, 0xe8, 0x8e, 0x23, 0xb0,
-	0xe2, 0x02, 0x64, 0xd9, 0x13, 0x44, 0xd4, 0x27, 0x41, 0x9e, 0x49, 0x48, 0x0b, 0x42, 0x62, 0x35,
-	0xef, 0x7f, 0xff, 0x3f, 0xef, 0x7d, 0x89, 0x07, 0xbc, 0xa2, 0x92, 0x4c, 0x54, 0xb4, 0x1c, 0x0b,
-	0xfa, 0x41, 0xd6, 0x0b, 0x75, 0x24, 0xb5, 0xe0, 0x92, 0xe3, 0xbe, 0x6e, 0x79, 0x27, 0x39, 0xcf,
-	0xb9, 0x6a, 0x8d, 0xbb, 0x4a, 0xbb, 0xde, 0xf3, 0x9c, 0x27, 0x4c, 0xa6, 0x59, 0x52, 0xf0, 0x71,
-	0x77, 0xaa, 0x9b, 0xe3, 0xd5, 0xe9, 0xdf, 0x83, 0xa2, 0x2f, 0x08, 0xfa, 0xef, 0xd8, 0x72, 0xc1,
-	0x04, 0x7e, 0x0c, 0x46, 0x91, 0xb9, 0x28, 0x44, 0xb1, 0x35, 0xed, 0xb7, 0x9b, 0xc0, 0xb8, 0x38,
-	0x27, 0x46, 0x91, 0xe1, 0x00, 0x2c, 0x9a, 0x65, 0xc2, 0x35, 0x42, 0x14, 0x1f, 0x4e, 0x07, 0xed,
-	0x26, 0xb0, 0xdf, 0x64, 0x99, 0x60, 0x4d, 0x43, 0x94, 0x81, 0x9f, 0x81, 0x25, 0xaf, 0x6a, 0xe6,
-	0x9a, 0x21, 0x8a, 0x47, 0x13, 0x9c, 0xe8, 0x2d, 0x89, 0x1e, 0xfb, 0xfe, 0xaa, 0x66, 0x44, 0xf9,
-	0xd8, 0x05, 0x3b, 0xe5, 0x95, 0x64, 0x9f, 0xa5, 0x6b, 0x85, 0x28, 0x1e, 0x92, 0x9d, 0x8c, 0x5e,
-	0xc3, 0x21, 0x61, 0x75, 0x59, 0xa4, 0x54, 0x32, 0xfc, 0x04, 0xcc, 0xf4, 0x37, 0x88, 0xdd, 0x6e,
-	0x02, 0xf3, 0xec, 0xe2, 0x9c, 0x74, 0x3d, 0x8c, 0xc1, 0xca, 0xa8, 0xa4, 0x0a, 0x65, 0x48, 0x54,
-	0x1d, 0xcd, 0x61, 0xf8, 0x96, 0x17, 0x15, 0x61, 0x4d, 0xcd, 0xab, 0x86, 0xfd, 0xf3, 0x67, 0x24,
-	0x60, 0x2f, 0x15, 0x51, 0xe3, 0x1a, 0xa1, 0x19, 0x0f, 0x26, 0xa3, 0x87, 0xa0, 0x53, 0x6b, 0xbd,
-	0x09, 0x7a, 0x64, 0x17, 0x8a, 0x7e, 0x20, 0x38, 0x9a, 0x55, 0xb4, 0x6e, 0x3e, 0x72, 0x39, 0x93,
-	0x1d, 0x98, 0x03, 0xe6, 0x19, 0x39, 0x53, 0xa3, 0x87, 0xa4, 0x2b, 0xf1, 0x2b, 0xb0, 0x57, 0x4c,
-	0x34, 0x05, 0xaf, 0x14, 0xd2, 0x68, 0xf2, 0x74, 0x37, 0xf3, 0xc1, 0xcd, 0x64, 0xae, 0x43, 0x64,
-	0x97, 0xbe, 0x0f, 0x63, 0xfe, 0x07, 0x0c, 0x8e, 0xc1, 0x24, 0xf4, 0x93, 0xfa, 0xdb, 0x06, 0x13,
-	0xe7, 0xcf, 0x25, 0xdb, 0x74, 0x17, 0x89, 0x8e, 0xc1, 0xde, 0x6e, 0xc3, 0x7d, 0x30, 0xe6, 0x2f,
-	0x9d, 0xde, 0x8b, 0x6f, 0x08, 0x60, 0xff, 0x31, 0xb0, 0x07, 0x07, 0x2b, 0x2e, 0x99, 0x70, 0x7a,
-	0xde, 0xa3, 0xeb, 0x9b, 0x70, 0x30, 0xef, 0xc4, 0xf6, 0x0d, 0xf8, 0x60, 0x0b, 0xb6, 0xe4, 0x2b,
-	0x96, 0x39, 0xc8, 0x3b, 0xbe, 0xbe, 0x09, 0x8f, 0x88, 0x96, 0x7b, 0xbf, 0x64, 0x54, 0x54, 0x4c,
-	0x38, 0x86, 0xf6, 0x2f, 0xb5, 0xdc, 0xfb, 0x8d, 0xa4, 0x79, 0x51, 0xe5, 0x8e, 0xa9, 0xfd, 0x99,
-	0x96, 0x5b, 0xdf, 0x83, 0x83, 0x92, 0xa7, 0xb4, 0x74, 0x2c, 0xbd, 0xfb, 0xb2, 0x13, 0xda, 0xf3,
-	0x46, 0xdf, 0xbf, 0xfa, 0xf7, 0x38, 0xa7, 0x27, 0xeb, 0x3b, 0xbf, 0x77, 0x7b, 0xe7, 0xf7, 0xd6,
-	0xad, 0x8f, 0x6e, 0x5b, 0x1f, 0xfd, 0x6c, 0x7d, 0xb4, 0xe8, 0xab, 0x77, 0x7b, 0xfa, 0x2b, 0x00,
-	0x00, 0xff, 0xff, 0x2e, 0x76, 0x7e, 0xda, 0x1e, 0x03, 0x00, 0x00,
+	// 1381 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0xbf, 0x72, 0xdb, 0xc6,
+	0x13, 0x16, 0x08, 0x10, 0x14, 0x97, 0xa2, 0x04, 0x9d, 0xfc, 0x07, 0x3f, 0x78, 0x44, 0xc2, 0xfc,
+	0x25, 0x0e, 0xad, 0x64, 0xa8, 0x44, 0x8e, 0x9d, 0x3f, 0x9e, 0x14, 0x14, 0x15, 0xc7, 0xf2, 0xc8,
+	0x2a, 0x4e, 0x1e, 0x17, 0x69, 0x38, 0x27, 0xe0, 0x44, 0x62, 0x4c, 0x02, 0xc8, 0xe1, 0xa4, 0x58,
+	0xae, 0xd3, 0x84, 0x55, 0x5e, 0x80, 0x9d, 0x8a, 0x3c, 0x40, 0x2a, 0x3d, 0x81, 0xab, 0xc4, 0x0f,
+	0x90, 0x51, 0x62, 0x3e, 0x49, 0xe6, 0xee, 0x40, 0x10, 0x94, 0xed, 0x4c, 0x52, 0xa4, 0x22, 0x76,
+	0xbf, 0xef, 0x76, 0x6f, 0xbf, 0xbd, 0xdd, 0x21, 0x38, 0x41, 0xc8, 0x29, 0x0b, 0xc9, 0x60, 0x93,
+	0x91, 0x23, 0x1e, 0x1f, 0xca, 0x9f, 0x56, 0xcc, 0x22, 0x1e, 0x21, 0x53, 0xb9, 0x9c, 0x2b, 0xbd,
+	0xa8, 0x17, 0x49, 0xd7, 0xa6, 0xf8, 0x52, 0xa8, 0x73, 0xbb, 0x17, 0xb5, 0x28, 0xf7, 0xfc, 0x56,
+	0x10, 0x6d, 0x8a, 0x5f, 0x79, 0x72, 0xf3, 0xe4, 0xce, 0x9b, 0x81, 0x1a, 0xbf, 0x17, 0xc0, 0x7c,
+	0x4c, 0x87, 0x87, 0x94, 0xa1, 0x6b, 0x50, 0x08, 0x7c, 0x5b, 0x73, 0xb5, 0xa6, 0xb1, 0x6d, 0x4e,
+	0x2e, 0xea, 0x85, 0xdd, 0x1d, 0x5c, 0x08, 0x7c, 0x54, 0x07, 0x83, 0xf8, 0x3e, 0xb3, 0x0b, 0xae,
+	0xd6, 0x2c, 0x6f, 0x57, 0x26, 0x17, 0xf5, 0x52, 0xdb, 0xf7, 0x19, 0x4d, 0x12, 0x2c, 0x01, 0x74,
+	0x0b, 0x0c, 0x7e, 0x1a, 0x53, 0x5b, 0x77, 0xb5, 0xe6, 0xf2, 0x16, 0x6a, 0xa9, 0x2c, 0x2d, 0x15,
+	0xf6, 0xc9, 0x69, 0x4c, 0xb1, 0xc4, 0x91, 0x0d, 0x25, 0x2f, 0x0a, 0x39, 0x7d, 0xce, 0x6d, 0xc3,
+	0xd5, 0x9a, 0x4b, 0x78, 0x6a, 0xa2, 0x2d, 0x30, 0x07, 0xe4, 0x90, 0x0e, 0x12, 0xbb, 0xe8, 0xea,
+	0xcd, 0xca, 0x96, 0x33, 0x1f, 0xa3, 0xb5, 0x27, 0xc1, 0xaf, 0x43, 0xce, 0x4e, 0x71, 0xca, 0x44,
+	0x0e, 0x2c, 0xfa, 0x8c, 0x04, 0x61, 0x10, 0xf6, 0x6c, 0xd3, 0xd5, 0x9a, 0x8b, 0x38, 0xb3, 0x45,
+	0xa6, 0x13, 0xca, 0x92, 0x20, 0x0a, 0xed, 0x92, 0xab, 0x35, 0xab, 0x78, 0x6a, 0xa2, 0x8f, 0x00,
+	0xbc, 0xc1, 0x71, 0xc2, 0x29, 0xeb, 0x06, 0xbe, 0xbd, 0x28, 0x8b, 0xad, 0x4e, 0x2e, 0xea, 0xe5,
+	0x8e, 0xf2, 0xee, 0xee, 0xe0, 0x72, 0x4a, 0xd8, 0xf5, 0x9d, 0x2f, 0xa0, 0x92, 0x4b, 0x8d, 0x2c,
+	0xd0, 0x9f, 0xd1, 0x53, 0x29, 0x51, 0x19, 0x8b, 0x4f, 0x74, 0x05, 0x8a, 0x27, 0x64, 0x70, 0x4c,
+	0x95, 0x38, 0x58, 0x19, 0x5f, 0x16, 0x3e, 0xd7, 0x1e, 0x19, 0x8b, 0x65, 0x0b, 0x1a, 0x7f, 0x68,
+	0x50, 0xc6, 0x34, 0x1e, 0x04, 0x1e, 0xe1, 0x14, 0xfd, 0x0f, 0x74, 0x2f, 0x93, 0xb8, 0x34, 0xb9,
+	0xa8, 0xeb, 0x9d, 0xdd, 0x1d, 0x2c, 0x7c, 0x08, 0x81, 0xe1, 0x13, 0x4e, 0x64, 0x9c, 0x25, 0x2c,
+	0xbf, 0xd1, 0xed, 0x39, 0x5d, 0xaf, 0x4e, 0x35, 0xc9, 0xe2, 0xe5, 0xa4, 0xbd, 0x0f, 0x8b, 0x43,
+	0xca, 0x89, 0x0c, 0x61, 0x48, 0x09, 0xeb, 0x6f, 0xd0, 0x5b, 0x8f, 0x53, 0x86, 0xd2, 0x31, 0x3b,
+	0xe0, 0xdc, 0x87, 0xea, 0x1c, 0xf4, 0x6f, 0xea, 0x6c, 0xec, 0x03, 0x3c, 0x24, 0x49, 0x1f, 0xd3,
+	0x38, 0x62, 0x1c, 0x5d, 0x03, 0x73, 0x28, 0x5b, 0xa6, 0x8a, 0xc4, 0xa9, 0x25, 0xce, 0x07, 0xa1,
+	0x4f, 0x9f, 0xcb, 0xf3, 0x06, 0x56, 0x86, 0x28, 0xba, 0x4f, 0x92, 0xbe, 0x2c, 0xd0, 0xc0, 0xf2,
+	0xbb, 0xf1, 0x53, 0x01, 0x2a, 0xed, 0x63, 0x3f, 0xe0, 0x98, 0x7a, 0x11, 0xf3, 0x67, 0x27, 0xb5,
+	0x4b, 0x27, 0x79, 0x30, 0x54, 0xd7, 0xd1, 0xb1, 0xfc, 0xce, 0xe5, 0xd6, 0xe7, 0x72, 0x7f, 0x08,
+	0x26, 0xf1, 0xb8, 0x78, 0x0b, 0x86, 0x14, 0x72, 0x6d, 0xaa, 0x8c, 0x4c, 0xd3, 0x96, 0x10, 0x4e,
+	0x29, 0x22, 0x88, 0x4f, 0x39, 0x09, 0x06, 0x76, 0x51, 0x56, 0x9a, 0x5a, 0xe8, 0xab, 0x9c, 0xc0,
+	0xa6, 0x14, 0xf8, 0xe6, 0x5c, 0x18, 0x75, 0xdb, 0xff, 0x46, 0xe2, 0x07, 0x50, 0x6c, 0x0f, 0x08,
+	0x1b, 0xbe, 0x73, 0x42, 0xdf, 0x4f, 0x1f, 0x4a, 0x41, 0xd6, 0xb7, 0x9a, 0x5d, 0x4c, 0x1c, 0x9a,
+	0x3d, 0x92, 0x06, 0x85, 0x8a, 0x74, 0x75, 0xfa, 0x24, 0xec, 0xd1, 0x9c, 0x2e, 0xda, 0x25, 0x5d,
+	0x04, 0xe9, 0x92, 0x2e, 0xb7, 0xa1, 0x48, 0x84, 0x5b, 0xe6, 0xa8, 0x6c, 0x55, 0xe7, 0xb8, 0xdb,
+	0xc6, 0xcb, 0x8b, 0xfa, 0x02, 0x56, 0x8c, 0xc6, 0x0f, 0x1a, 0x2c, 0x3d, 0x8a, 0x82, 0x10, 0xd3,
+	0x24, 0x8e, 0xc2, 0x84, 0xbe, 0xf3, 0xda, 0x2d, 0x28, 0xa9, 0x16, 0x25, 0x76, 0x41, 0x4a, 0xba,
+	0x3c, 0x3f, 0xf6, 0x69, 0xd8, 0x29, 0xe9, 0xd2, 0xec, 0xea, 0x7f, 0x3f, 0xbb, 0x8d, 0x36, 0x2c,
+	0x3d, 0xa6, 0x49, 0x42, 0x7a, 0x74, 0x9b, 0x70, 0xaf, 0x8f, 0x3e, 0x11, 0x1d, 0x94, 0x76, 0x62,
+	0x6b, 0x32, 0xdd, 0xca, 0x2c, 0x9d, 0xe2, 0xa9, 0x7c, 0x19, 0xad, 0xf1, 0xa3, 0x01, 0xd5, 0x83,
+	0x90, 0xc4, 0x49, 0x3f, 0xe2, 0x07, 0x5c, 0x4c, 0xb0, 0x05, 0x7a, 0x07, 0x77, 0x64, 0x2d, 0x4b,
+	0x58, 0x7c, 0xa2, 0xcf, 0x66, 0xab, 0x46, 0xc9, 0xbf, 0x3e, 0x8d, 0x3a, 0x77, 0xb2, 0xf5, 0x54,
+	0x91, 0x66, 0x9b, 0x28, 0x57, 0xbd, 0xfe, 0x4f, 0xaa, 0x6f, 0x82, 0x8e, 0xc9, 0xf7, 0xf2, 0x0d,
+	0x57, 0xb6, 0xac, 0xcb, 0x49, 0x52, 0xb6, 0xa0, 0xc8, 0xc6, 0x8a, 0x4e, 0x4c, 0xb7, 0xe9, 0x5b,
+	0x9b, 0x95, 0x52, 0xd0, 0x5d, 0xa8, 0x78, 0xd1, 0x30, 0x16, 0xeb, 0x5c, 0xd4, 0x60, 0xce, 0x3f,
+	0x85, 0xce, 0x0c, 0xc2, 0x79, 0x1e, 0xba, 0x01, 0xe5, 0x43, 0x92, 0xd0, 0x2e, 0xa7, 0x6c, 0x28,
+	0x77, 0xac, 0x81, 0x17, 0x85, 0xe3, 0x09, 0x65, 0x43, 0xb4, 0x0e, 0x20, 0x41, 0x35, 0xb8, 0x72,
+	0xc9, 0x62, 0x49, 0xdf, 0x15, 0x0e, 0xd4, 0x00, 0x33, 0xe9, 0x93, 0xad, 0xbb, 0xf7, 0xec, 0xb2,
+	0xd0, 0x71, 0x1b, 0x26, 0x17, 0x75, 0xf3, 0xe0, 0x61, 0x7b, 0xeb, 0xee, 0x3d, 0x9c, 0x22, 0xb2,
+	0xd7, 0xfd, 0xe3, 0xf0, 0x59, 0xd7, 0x63, 0x5e, 0x62, 0x83, 0xab, 0x37, 0xab, 0x69, 0xaf, 0x85,
+	0xb7, 0x83, 0x3b, 0x09, 0x2e, 0x4b, 0x42, 0x87, 0x79, 0x89, 0x48, 0xe8, 0x31, 0x4a, 0x38, 0xf5,
+	0xbb, 0x84, 0xdb, 0x15, 0xb9, 0x14, 0xca, 0xa9, 0xa7, 0xcd, 0xd1, 0x26, 0x14, 0x89, 0x18, 0x52,
+	0x7b, 0x49, 0xea, 0xb1, 0xf6, 0x96, 0xc9, 0xcd, 0x9e, 0xb0, 0x70, 0x35, 0x56, 0xa1, 0x94, 0xf6,
+	0x0b, 0x99, 0x50, 0x78, 0xfa, 0xb1, 0xb5, 0xb0, 0xf1, 0x9b, 0x06, 0xd5, 0xb9, 0xcd, 0x8b, 0x6e,
+	0xa8, 0x95, 0x6d, 0x2d, 0x38, 0xab, 0xa3, 0xb1, 0x3b, 0x03, 0x77, 0xc4, 0xee, 0x5e, 0x4f, 0xe7,
+	0xc5, 0xd2, 0x1c, 0x34, 0x1a, 0xbb, 0xcb, 0x19, 0xaa, 0x26, 0x79, 0x3d, 0xbd, 0x91, 0x55, 0xb8,
+	0x0c, 0x0b, 0x2f, 0xba, 0x05, 0x20, 0x96, 0x61, 0xd7, 0xeb, 0x53, 0xef, 0x99, 0xa5, 0x3b, 0xd7,
+	0x46, 0x63, 0x17, 0x65, 0x1c, 0xb1, 0x6f, 0x3b, 0x02, 0x41, 0x4d, 0xa8, 0x48, 0x1e, 0x93, 0xdb,
+	0xd7, 0x32, 0x9c, 0xeb, 0xa3, 0xb1, 0xbb, 0x36, 0x47, 0x54, 0x8b, 0xd9, 0x59, 0x3d, 0x3f, 0xab,
+	0xcd, 0xdf, 0x7f, 0xe3, 0x57, 0x2d, 0xdd, 0xb4, 0x6a, 0xd4, 0xd1, 0x7b, 0xe2, 0x25, 0x84, 0x47,
+	0x5d, 0x4f, 0xae, 0x07, 0x6b, 0xc1, 0x59, 0x1b, 0x8d, 0xdd, 0x15, 0xc9, 0xe8, 0x44, 0xe1, 0x51,
+	0xba, 0x35, 0x3e, 0x85, 0x35, 0xce, 0x48, 0x98, 0x1c, 0x51, 0xd6, 0x1d, 0x50, 0xe2, 0x53, 0x96,
+	0xf4, 0x83, 0xd8, 0xd2, 0x9c, 0x1b, 0xa3, 0xb1, 0x7b, 0x5d, 0xb2, 0x9f, 0xa4, 0xf8, 0x5e, 0x06,
+	0xa3, 0x0d, 0x58, 0x3e, 0x8a, 0x98, 0x47, 0xbb, 0x49, 0xfa, 0x5e, 0xad, 0x82, 0x2a, 0x4a, 0x1e,
+	0x78, 0x20, 0xa0, 0xe9, 0x4b, 0x46, 0xb7, 0xc0, 0x62, 0x34, 0xe1, 0x11, 0xcb, 0xb1, 0x75, 0xc7,
+	0x1a, 0x8d, 0xdd, 0xa5, 0xb4, 0x61, 0x12, 0x74, 0x56, 0xce, 0xcf, 0x6a, 0xf9, 0x02, 0x36, 0xbe,
+	0x83, 0x72, 0xb6, 0xf2, 0xd0, 0x75, 0x30, 0xc2, 0x28, 0x14, 0x65, 0x54, 0x47, 0x63, 0xb7, 0xbc,
+	0x1f, 0x85, 0x99, 0xf4, 0xa5, 0x30, 0x4a, 0x62, 0xe2, 0x51, 0x4b, 0x53, 0x51, 0xf7, 0xa3, 0x03,
+	0x61, 0x66, 0xb0, 0x17, 0x31, 0x76, 0x1c, 0x8b, 0x2b, 0x4a, 0xb8, 0xa3, 0x4c, 0x09, 0x3b, 0xd5,
+	0xf3, 0xb3, 0xda, 0x2c, 0xcb, 0xc6, 0xcf, 0x1a, 0x54, 0x72, 0x33, 0x82, 0x3e, 0x00, 0x4b, 0x64,
+	0xed, 0xe6, 0x46, 0x65, 0xfa, 0x3e, 0xf6, 0xa3, 0x3c, 0xf1, 0x26, 0x98, 0xa2, 0xb8, 0xf8, 0xd4,
+	0xd2, 0x9c, 0xab, 0xa3, 0xb1, 0xbb, 0x7a, 0x20, 0xad, 0x3c, 0x65, 0x1d, 0x8c, 0xde, 0x8b, 0x20,
+	0xb6, 0x0a, 0xaa, 0x11, 0xdf, 0xbc, 0x08, 0xe2, 0x4b, 0xf0, 0x8b, 0x84, 0xfb, 0x96, 0xae, 0xe0,
+	0x6f, 0x13, 0xee, 0xe7, 0x60, 0xa5, 0x4e, 0xce, 0xb1, 0xe1, 0xa7, 0xdb, 0x3f, 0xed, 0x76, 0x1d,
+	0x16, 0xc5, 0x6a, 0x3f, 0x21, 0x9c, 0x4e, 0x6f, 0xd8, 0x4e, 0x6d, 0x25, 0xc4, 0xff, 0x01, 0x7c,
+	0x9a, 0x51, 0x34, 0x95, 0x65, 0x87, 0x92, 0x3c, 0x29, 0xed, 0xc1, 0x2c, 0xec, 0xc6, 0x2f, 0x1a,
+	0xc0, 0xec, 0x8f, 0x1f, 0x72, 0xa0, 0x78, 0x12, 0x71, 0xca, 0xac, 0x05, 0x67, 0x65, 0x34, 0x76,
+	0x2b, 0x4f, 0x85, 0xa1, 0x70, 0x54, 0x83, 0x12, 0xa3, 0xc3, 0xe8, 0x84, 0xfa, 0x96, 0x36, 0x1d,
+	0x21, 0x69, 0xce, 0xf0, 0x01, 0x25, 0x2c, 0xa4, 0xcc, 0x2a, 0x28, 0x7c, 0x4f, 0x99, 0x33, 0x3c,
+	0xe1, 0xa4, 0x17, 0x84, 0x3d, 0x4b, 0x57, 0xf8, 0x81, 0x32, 0x53, 0xdc, 0x81, 0xe2, 0x20, 0xf2,
+	0xc8, 0xc0, 0x32, 0x54, 0xee, 0x3d, 0x61, 0x28, 0xcc, 0x59, 0x3e, 0x3f, 0xab, 0xe5, 0xee, 0xb9,
+	0x7d, 0xe5, 0xe5, 0xeb, 0xda, 0xc2, 0xab, 0xd7, 0xb5, 0x85, 0x97, 0x93, 0x9a, 0xf6, 0x6a, 0x52,
+	0xd3, 0xfe, 0x9c, 0xd4, 0xb4, 0x43, 0x53, 0xfe, 0x47, 0xbe, 0xf3, 0xd7, 0x00, 0x5f, 0x6b, 0xf5,
+	0x23, 0x8a, 0x0b, 0x00, 0x00,
 }
 
 func (m *Member) Marshal() (dAtA []byte, err error) {
@@ -333,6 +811,45 @@ func (m *Member) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.ClusterID != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.ClusterID))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Version != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Draining {
+		i--
+		if m.Draining {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.Labels) > 0 {
+		for k := range m.Labels {
+			v := m.Labels[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintRaft(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintRaft(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintRaft(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
 	if len(m.Context) > 0 {
 		i -= len(m.Context)
 		copy(dAtA[i:], m.Context)
@@ -384,6 +901,30 @@ func (m *Replicate) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Metadata) > 0 {
+		for k := range m.Metadata {
+			v := m.Metadata[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintRaft(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintRaft(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintRaft(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if m.Type != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Type))
+		i--
+		dAtA[i] = 0x18
+	}
 	if len(m.Data) > 0 {
 		i -= len(m.Data)
 		copy(dAtA[i:], m.Data)
@@ -399,7 +940,7 @@ func (m *Replicate) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *JoinResponse) Marshal() (dAtA []byte, err error) {
+func (m *HashReport) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -409,12 +950,12 @@ func (m *JoinResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *JoinResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *HashReport) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *JoinResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *HashReport) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
@@ -423,29 +964,25 @@ func (m *JoinResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if len(m.Members) > 0 {
-		for iNdEx := len(m.Members) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size, err := m.Members[iNdEx].MarshalToSizedBuffer(dAtA[:i])
-				if err != nil {
-					return 0, err
-				}
-				i -= size
-				i = encodeVarintRaft(dAtA, i, uint64(size))
-			}
-			i--
-			dAtA[i] = 0x12
-		}
+	if m.Hash != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Hash))
+		i--
+		dAtA[i] = 0x18
 	}
-	if m.ID != 0 {
-		i = encodeVarintRaft(dAtA, i, uint64(m.ID))
+	if m.Index != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Index))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Member != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Member))
 		i--
 		dAtA[i] = 0x8
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *SnapshotState) Marshal() (dAtA []byte, err error) {
+func (m *AuditRecord) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -455,12 +992,12 @@ func (m *SnapshotState) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SnapshotState) MarshalTo(dAtA []byte) (int, error) {
+func (m *AuditRecord) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SnapshotState) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *AuditRecord) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
@@ -469,23 +1006,340 @@ func (m *SnapshotState) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	{
-		size, err := m.Raw.MarshalToSizedBuffer(dAtA[:i])
-		if err != nil {
-			return 0, err
+	if len(m.Metadata) > 0 {
+		for k := range m.Metadata {
+			v := m.Metadata[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintRaft(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintRaft(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintRaft(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x32
 		}
-		i -= size
-		i = encodeVarintRaft(dAtA, i, uint64(size))
 	}
-	i--
-	dAtA[i] = 0x22
-	if len(m.Members) > 0 {
-		for iNdEx := len(m.Members) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size, err := m.Members[iNdEx].MarshalToSizedBuffer(dAtA[:i])
-				if err != nil {
-					return 0, err
-				}
+	if len(m.Detail) > 0 {
+		i -= len(m.Detail)
+		copy(dAtA[i:], m.Detail)
+		i = encodeVarintRaft(dAtA, i, uint64(len(m.Detail)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Action != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Action))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Member != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Member))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Time != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Time))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Index != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Index))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Alarm) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Alarm) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Alarm) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Type != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Type))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.ID != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.ID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AlarmChange) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AlarmChange) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AlarmChange) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	{
+		size, err := m.Alarm.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintRaft(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x12
+	if m.Action != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Action))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *JoinResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *JoinResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *JoinResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.ClusterID != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.ClusterID))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Members) > 0 {
+		for iNdEx := len(m.Members) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Members[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRaft(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.ID != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.ID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *MessageBatch) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MessageBatch) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MessageBatch) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Messages) > 0 {
+		for iNdEx := len(m.Messages) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Messages[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRaft(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SnapshotState) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SnapshotState) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SnapshotState) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Audit) > 0 {
+		for iNdEx := len(m.Audit) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Audit[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRaft(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x62
+		}
+	}
+	if m.CreatedAt != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.CreatedAt))
+		i--
+		dAtA[i] = 0x58
+	}
+	if len(m.ChunkCRCs) > 0 {
+		dAtA3 := make([]byte, len(m.ChunkCRCs)*10)
+		var j2 int
+		for _, num := range m.ChunkCRCs {
+			for num >= 1<<7 {
+				dAtA3[j2] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j2++
+			}
+			dAtA3[j2] = uint8(num)
+			j2++
+		}
+		i -= j2
+		copy(dAtA[i:], dAtA3[:j2])
+		i = encodeVarintRaft(dAtA, i, uint64(j2))
+		i--
+		dAtA[i] = 0x52
+	}
+	if len(m.SHA256) > 0 {
+		i -= len(m.SHA256)
+		copy(dAtA[i:], m.SHA256)
+		i = encodeVarintRaft(dAtA, i, uint64(len(m.SHA256)))
+		i--
+		dAtA[i] = 0x4a
+	}
+	if m.BaseIndex != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.BaseIndex))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.BaseTerm != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.BaseTerm))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Compression != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Compression))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.Alarms) > 0 {
+		for iNdEx := len(m.Alarms) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Alarms[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRaft(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	{
+		size, err := m.Raw.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintRaft(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x22
+	if len(m.Members) > 0 {
+		for iNdEx := len(m.Members) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Members[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
 				i -= size
 				i = encodeVarintRaft(dAtA, i, uint64(size))
 			}
@@ -539,6 +1393,23 @@ func (m *Member) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRaft(uint64(l))
 	}
+	if len(m.Labels) > 0 {
+		for k, v := range m.Labels {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovRaft(uint64(len(k))) + 1 + len(v) + sovRaft(uint64(len(v)))
+			n += mapEntrySize + 1 + sovRaft(uint64(mapEntrySize))
+		}
+	}
+	if m.Draining {
+		n += 2
+	}
+	if m.Version != 0 {
+		n += 1 + sovRaft(uint64(m.Version))
+	}
+	if m.ClusterID != 0 {
+		n += 1 + sovRaft(uint64(m.ClusterID))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -558,19 +1429,122 @@ func (m *Replicate) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRaft(uint64(l))
 	}
+	if m.Type != 0 {
+		n += 1 + sovRaft(uint64(m.Type))
+	}
+	if len(m.Metadata) > 0 {
+		for k, v := range m.Metadata {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovRaft(uint64(len(k))) + 1 + len(v) + sovRaft(uint64(len(v)))
+			n += mapEntrySize + 1 + sovRaft(uint64(mapEntrySize))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
 	return n
 }
 
-func (m *JoinResponse) Size() (n int) {
+func (m *HashReport) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if m.ID != 0 {
+	if m.Member != 0 {
+		n += 1 + sovRaft(uint64(m.Member))
+	}
+	if m.Index != 0 {
+		n += 1 + sovRaft(uint64(m.Index))
+	}
+	if m.Hash != 0 {
+		n += 1 + sovRaft(uint64(m.Hash))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AuditRecord) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Index != 0 {
+		n += 1 + sovRaft(uint64(m.Index))
+	}
+	if m.Time != 0 {
+		n += 1 + sovRaft(uint64(m.Time))
+	}
+	if m.Member != 0 {
+		n += 1 + sovRaft(uint64(m.Member))
+	}
+	if m.Action != 0 {
+		n += 1 + sovRaft(uint64(m.Action))
+	}
+	l = len(m.Detail)
+	if l > 0 {
+		n += 1 + l + sovRaft(uint64(l))
+	}
+	if len(m.Metadata) > 0 {
+		for k, v := range m.Metadata {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovRaft(uint64(len(k))) + 1 + len(v) + sovRaft(uint64(len(v)))
+			n += mapEntrySize + 1 + sovRaft(uint64(mapEntrySize))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Alarm) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != 0 {
+		n += 1 + sovRaft(uint64(m.ID))
+	}
+	if m.Type != 0 {
+		n += 1 + sovRaft(uint64(m.Type))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AlarmChange) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Action != 0 {
+		n += 1 + sovRaft(uint64(m.Action))
+	}
+	l = m.Alarm.Size()
+	n += 1 + l + sovRaft(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *JoinResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ID != 0 {
 		n += 1 + sovRaft(uint64(m.ID))
 	}
 	if len(m.Members) > 0 {
@@ -579,6 +1553,27 @@ func (m *JoinResponse) Size() (n int) {
 			n += 1 + l + sovRaft(uint64(l))
 		}
 	}
+	if m.ClusterID != 0 {
+		n += 1 + sovRaft(uint64(m.ClusterID))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *MessageBatch) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Messages) > 0 {
+		for _, e := range m.Messages {
+			l = e.Size()
+			n += 1 + l + sovRaft(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -606,6 +1601,41 @@ func (m *SnapshotState) Size() (n int) {
 	}
 	l = m.Raw.Size()
 	n += 1 + l + sovRaft(uint64(l))
+	if len(m.Alarms) > 0 {
+		for _, e := range m.Alarms {
+			l = e.Size()
+			n += 1 + l + sovRaft(uint64(l))
+		}
+	}
+	if m.Compression != 0 {
+		n += 1 + sovRaft(uint64(m.Compression))
+	}
+	if m.BaseTerm != 0 {
+		n += 1 + sovRaft(uint64(m.BaseTerm))
+	}
+	if m.BaseIndex != 0 {
+		n += 1 + sovRaft(uint64(m.BaseIndex))
+	}
+	l = len(m.SHA256)
+	if l > 0 {
+		n += 1 + l + sovRaft(uint64(l))
+	}
+	if len(m.ChunkCRCs) > 0 {
+		l = 0
+		for _, e := range m.ChunkCRCs {
+			l += sovRaft(uint64(e))
+		}
+		n += 1 + sovRaft(uint64(l)) + l
+	}
+	if m.CreatedAt != 0 {
+		n += 1 + sovRaft(uint64(m.CreatedAt))
+	}
+	if len(m.Audit) > 0 {
+		for _, e := range m.Audit {
+			l = e.Size()
+			n += 1 + l + sovRaft(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -700,9 +1730,1031 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 			iNdEx = postIndex
 		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= MemberType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Context", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Context = append(m.Context[:0], dAtA[iNdEx:postIndex]...)
+			if m.Context == nil {
+				m.Context = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Labels == nil {
+				m.Labels = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthRaft
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthRaft
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRaft(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Labels[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Draining", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Draining = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClusterID", wireType)
+			}
+			m.ClusterID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ClusterID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Replicate) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Replicate: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Replicate: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CID", wireType)
+			}
+			m.CID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= ReplicateType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metadata == nil {
+				m.Metadata = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthRaft
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthRaft
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRaft(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Metadata[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HashReport) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HashReport: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HashReport: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Member", wireType)
+			}
+			m.Member = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Member |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hash", wireType)
+			}
+			m.Hash = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Hash |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AuditRecord) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AuditRecord: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AuditRecord: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			m.Time = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Time |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Member", wireType)
+			}
+			m.Member = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Member |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Action", wireType)
+			}
+			m.Action = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Action |= AuditAction(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Detail", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Detail = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Metadata == nil {
+				m.Metadata = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthRaft
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthRaft
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipRaft(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthRaft
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Metadata[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Alarm) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Alarm: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Alarm: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			m.ID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= AlarmType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AlarmChange) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AlarmChange: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AlarmChange: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Action", wireType)
 			}
-			m.Type = 0
+			m.Action = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRaft
@@ -712,16 +2764,16 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= MemberType(b&0x7F) << shift
+				m.Action |= AlarmAction(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 4:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Context", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Alarm", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRaft
@@ -731,24 +2783,23 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthRaft
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthRaft
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Context = append(m.Context[:0], dAtA[iNdEx:postIndex]...)
-			if m.Context == nil {
-				m.Context = []byte{}
+			if err := m.Alarm.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
 		default:
@@ -773,7 +2824,7 @@ func (m *Member) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Replicate) Unmarshal(dAtA []byte) error {
+func (m *JoinResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -796,17 +2847,17 @@ func (m *Replicate) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Replicate: wiretype end group for non-group")
+			return fmt.Errorf("proto: JoinResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Replicate: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: JoinResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
 			}
-			m.CID = 0
+			m.ID = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRaft
@@ -816,16 +2867,16 @@ func (m *Replicate) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.CID |= uint64(b&0x7F) << shift
+				m.ID |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Members", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowRaft
@@ -835,26 +2886,45 @@ func (m *Replicate) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthRaft
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthRaft
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
-			if m.Data == nil {
-				m.Data = []byte{}
+			m.Members = append(m.Members, Member{})
+			if err := m.Members[len(m.Members)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClusterID", wireType)
+			}
+			m.ClusterID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ClusterID |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
@@ -877,7 +2947,7 @@ func (m *Replicate) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *JoinResponse) Unmarshal(dAtA []byte) error {
+func (m *MessageBatch) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -900,34 +2970,15 @@ func (m *JoinResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: JoinResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: MessageBatch: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: JoinResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: MessageBatch: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
-			}
-			m.ID = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowRaft
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.ID |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Members", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Messages", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -954,8 +3005,8 @@ func (m *JoinResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Members = append(m.Members, Member{})
-			if err := m.Members[len(m.Members)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Messages = append(m.Messages, raftpb.Message{})
+			if err := m.Messages[len(m.Messages)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1130,6 +3181,260 @@ func (m *SnapshotState) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Alarms", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Alarms = append(m.Alarms, Alarm{})
+			if err := m.Alarms[len(m.Alarms)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			m.Compression = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Compression |= Compression(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BaseTerm", wireType)
+			}
+			m.BaseTerm = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BaseTerm |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BaseIndex", wireType)
+			}
+			m.BaseIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BaseIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SHA256", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SHA256 = append(m.SHA256[:0], dAtA[iNdEx:postIndex]...)
+			if m.SHA256 == nil {
+				m.SHA256 = []byte{}
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType == 0 {
+				var v uint32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.ChunkCRCs = append(m.ChunkCRCs, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowRaft
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthRaft
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthRaft
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.ChunkCRCs) == 0 {
+					m.ChunkCRCs = make([]uint32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowRaft
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.ChunkCRCs = append(m.ChunkCRCs, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkCRCs", wireType)
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedAt", wireType)
+			}
+			m.CreatedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CreatedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Audit", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaft
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Audit = append(m.Audit, AuditRecord{})
+			if err := m.Audit[len(m.Audit)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
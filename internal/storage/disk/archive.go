@@ -0,0 +1,303 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/shaj13/raft/internal/storage"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// archiveManifest is the key of the archive manifest object.
+const archiveManifest = "manifest.json"
+
+// ArchiveManifest indexes the WAL segments and blob files archived into an object store.
+type ArchiveManifest struct {
+	// Segments specifies the archived WAL segments, in the archived order.
+	Segments []ArchivedSegment `json:"segments"`
+	// Blobs specifies the archived blob files names.
+	Blobs []string `json:"blobs,omitempty"`
+}
+
+// ArchivedSegment describes an archived WAL segment.
+type ArchivedSegment struct {
+	// Name specifies the segment file name, and its object key.
+	Name string `json:"name"`
+	// Seq specifies the segment sequence number.
+	Seq uint64 `json:"seq"`
+	// Index specifies the raft index the segment starts after.
+	Index uint64 `json:"index"`
+	// Size specifies the segment file size.
+	Size int64 `json:"size"`
+}
+
+// archiver uploads the purged WAL segments and blob files into an object store,
+// before removing them from the WAL dir.
+type archiver struct {
+	ctx   context.Context
+	store storage.ObjectStore
+
+	mu       sync.Mutex
+	manifest *ArchiveManifest
+}
+
+// segment uploads the given WAL segment, then records it in the manifest.
+func (a *archiver) segment(waldir, name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range m.Segments {
+		if s.Name == name {
+			return nil
+		}
+	}
+
+	var seq, index uint64
+	if _, err := fmt.Sscanf(name, format+walExt, &seq, &index); err != nil {
+		return err
+	}
+
+	size, err := a.upload(filepath.Join(waldir, name), name)
+	if err != nil {
+		return err
+	}
+
+	m.Segments = append(m.Segments, ArchivedSegment{
+		Name:  name,
+		Seq:   seq,
+		Index: index,
+		Size:  size,
+	})
+
+	return a.save()
+}
+
+// blobs uploads the blob files of entries before the given index, then records them in the manifest.
+func (a *archiver) blobs(dir string, index uint64) error {
+	files, err := list(dir, blobExt)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load()
+	if err != nil {
+		return err
+	}
+
+	archived := make(map[string]struct{}, len(m.Blobs))
+	for _, b := range m.Blobs {
+		archived[b] = struct{}{}
+	}
+
+	n := len(m.Blobs)
+	for _, f := range files {
+		var bt, bi uint64
+		if _, err := fmt.Sscanf(f, format+blobExt, &bt, &bi); err != nil {
+			return err
+		}
+
+		if _, ok := archived[f]; ok || bi >= index {
+			continue
+		}
+
+		if _, err := a.upload(filepath.Join(dir, f), blobDir+"/"+f); err != nil {
+			return err
+		}
+
+		m.Blobs = append(m.Blobs, f)
+	}
+
+	if n == len(m.Blobs) {
+		return nil
+	}
+
+	return a.save()
+}
+
+func (a *archiver) upload(path, key string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := a.store.Put(a.ctx, key, ctxReader{a.ctx, f}); err != nil {
+		return 0, fmt.Errorf("raft/storage: archive %s: %w", key, err)
+	}
+
+	return info.Size(), nil
+}
+
+func (a *archiver) load() (*ArchiveManifest, error) {
+	if a.manifest != nil {
+		return a.manifest, nil
+	}
+
+	m, err := ReadArchiveManifest(a.ctx, a.store)
+	if errors.Is(err, fs.ErrNotExist) {
+		m, err = new(ArchiveManifest), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.manifest = m
+	return m, nil
+}
+
+func (a *archiver) save() error {
+	buf, err := json.Marshal(a.manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := a.store.Put(a.ctx, archiveManifest, bytes.NewReader(buf)); err != nil {
+		return fmt.Errorf("raft/storage: archive manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ReadArchiveManifest reads the manifest of the WAL archive within the given object store,
+// or returns an error wrapping fs.ErrNotExist when nothing archived yet.
+func ReadArchiveManifest(ctx context.Context, store storage.ObjectStore) (*ArchiveManifest, error) {
+	size, err := store.Size(ctx, archiveManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := store.Get(ctx, archiveManifest, 0, size)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	m := new(ArchiveManifest)
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("raft/storage: decode archive manifest: %v", err)
+	}
+
+	return m, nil
+}
+
+// DownloadArchive downloads the WAL segments and blob files archived within the given object store
+// into the given WAL dir, so the archived segments read by ReadSegments.
+func DownloadArchive(ctx context.Context, store storage.ObjectStore, waldir string) error {
+	m, err := ReadArchiveManifest(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(waldir, blobDir), 0750); err != nil {
+		return err
+	}
+
+	for _, s := range m.Segments {
+		if err := download(ctx, store, s.Name, filepath.Join(waldir, s.Name)); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range m.Blobs {
+		if err := download(ctx, store, blobDir+"/"+b, filepath.Join(waldir, blobDir, b)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReplayArchive downloads the WAL segments archived within the given object store into a temporary dir,
+// then calls the given function with the archived entries in the log order.
+// The entries overwritten by a newer term dropped, as done when reading the WAL on boot.
+func ReplayArchive(ctx context.Context, store storage.ObjectStore, fn func(ent raftpb.Entry) error) error {
+	dir, err := os.MkdirTemp("", "raft-wal-archive")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+
+	if err := DownloadArchive(ctx, store, dir); err != nil {
+		return err
+	}
+
+	segs, err := ReadSegments(dir)
+	if err != nil {
+		return err
+	}
+
+	ents := []raftpb.Entry{}
+	for _, seg := range segs {
+		for _, ent := range seg.Entries {
+			if n := len(ents); n > 0 && ent.Index <= ents[n-1].Index {
+				i := sort.Search(n, func(i int) bool { return ents[i].Index >= ent.Index })
+				ents = ents[:i]
+			}
+			ents = append(ents, ent)
+		}
+	}
+
+	for _, ent := range ents {
+		if err := fn(ent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func download(ctx context.Context, store storage.ObjectStore, key, path string) error {
+	size, err := store.Size(ctx, key)
+	if err != nil {
+		return fmt.Errorf("raft/storage: download %s: %w", key, err)
+	}
+
+	r, err := store.Get(ctx, key, 0, size)
+	if err != nil {
+		return fmt.Errorf("raft/storage: download %s: %w", key, err)
+	}
+
+	defer r.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, ctxReader{ctx, r}); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,137 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"testing"
+
+	rafterrors "github.com/shaj13/raft/errors"
+	"github.com/shaj13/raft/internal/storage"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestDiskPurgeArchive(t *testing.T) {
+	dir := createTestDir("purge_archive", t)
+
+	files := []string{}
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf(format, i, i)
+		files = append(files, name+snapExt, name+walExt)
+	}
+
+	createTestFiles(dir, files, t)
+
+	// it retain the WAL files until archived.
+	disk := newTestDisk(dir)
+	disk.maxsnaps = 1
+	disk.archive = &archiver{ctx: context.TODO(), store: failObjectStore{newMemObjectStore()}}
+	disk.purge(math.MaxUint64)
+
+	wals, _ := list(dir, walExt)
+	require.Len(t, wals, 5)
+
+	// it archive and remove only the WAL files released by the WAL.
+	mem := newMemObjectStore()
+	disk.archive = &archiver{ctx: context.TODO(), store: mem}
+	disk.purge(2)
+
+	wals, _ = list(dir, walExt)
+	require.Len(t, wals, 3)
+	require.Len(t, mem.objects, 3)
+
+	// it archive the WAL files before removing them.
+	disk.purge(math.MaxUint64)
+
+	wals, _ = list(dir, walExt)
+	require.Len(t, wals, 1)
+	require.Len(t, mem.objects, 5)
+
+	m, err := ReadArchiveManifest(context.TODO(), mem)
+	require.NoError(t, err)
+	require.Len(t, m.Segments, 4)
+
+	for i, s := range m.Segments {
+		require.Equal(t, walName(uint64(i), uint64(i)), s.Name)
+		require.Equal(t, uint64(i), s.Seq)
+		require.Equal(t, uint64(i), s.Index)
+	}
+}
+
+func TestReplayArchive(t *testing.T) {
+	dir := createTestDir("wal", t)
+	blob := make([]byte, 64)
+
+	d := newTestDisk(dir)
+	d.spill = 32
+
+	_, _, _, _, err := d.Boot(context.TODO(), []byte("meta"))
+	require.NoError(t, err)
+
+	err = d.SaveEntries(raftpb.HardState{Term: 1, Commit: 1}, []raftpb.Entry{
+		{Term: 1, Index: 1, Data: []byte("a")},
+		{Term: 1, Index: 2, Data: blob},
+		{Term: 1, Index: 3, Data: []byte("c")},
+	})
+	require.NoError(t, err)
+
+	// it overwrite the entries of the former term.
+	err = d.SaveEntries(raftpb.HardState{Term: 2, Commit: 3}, []raftpb.Entry{
+		{Term: 2, Index: 3, Data: []byte("d")},
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.Close())
+
+	mem := newMemObjectStore()
+	a := &archiver{ctx: context.TODO(), store: mem}
+	require.NoError(t, a.segment(dir, walName(0, 0)))
+	require.NoError(t, a.blobs(filepath.Join(dir, blobDir), 10))
+	require.Len(t, a.manifest.Blobs, 1)
+
+	// it archive the segment once.
+	require.NoError(t, a.segment(dir, walName(0, 0)))
+	require.Len(t, a.manifest.Segments, 1)
+
+	got := []raftpb.Entry{}
+	err = ReplayArchive(context.TODO(), mem, func(ent raftpb.Entry) error {
+		got = append(got, ent)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []raftpb.Entry{
+		{Term: 1, Index: 1, Data: []byte("a")},
+		{Term: 1, Index: 2, Data: blob},
+		{Term: 2, Index: 3, Data: []byte("d")},
+	}, got)
+
+	// it return error when nothing archived.
+	err = ReplayArchive(context.TODO(), newMemObjectStore(), func(raftpb.Entry) error { return nil })
+	require.Error(t, err)
+
+	// it return error when an archived record corrupted.
+	ent := raftpb.Entry{Term: 1, Index: 1, Data: []byte("a")}
+	buf := pbutil.MustMarshal(&ent)
+	obj := mem.objects[walName(0, 0)]
+	i := bytes.Index(obj, buf)
+	require.NotEqual(t, -1, i)
+	obj[i+len(buf)-1] = 'b'
+
+	err = ReplayArchive(context.TODO(), mem, func(raftpb.Entry) error { return nil })
+	require.ErrorIs(t, err, storage.ErrWALCorrupted)
+	require.ErrorIs(t, err, rafterrors.ErrCorrupted)
+}
+
+// failObjectStore is an object store failing the uploads.
+type failObjectStore struct {
+	*memObjectStore
+}
+
+func (failObjectStore) Put(context.Context, string, io.Reader) error {
+	return errors.New("put failed")
+}
@@ -0,0 +1,137 @@
+package disk
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+const (
+	blobExt = ".blob"
+	blobDir = "blobs"
+)
+
+// blobMagic prefix the data of normal entries spilled to blob files,
+// followed by the crc32 of the original data.
+// Similar to compressMagic, it never collide with a marshaled replicate message.
+const blobMagic byte = 0xfe
+
+func blobName(term, index uint64) string {
+	return fmt.Sprintf(format, term, index) + blobExt
+}
+
+// spillEntries returns a copy of the given entries, where normal entries
+// data exceeding the threshold written into standalone blob files,
+// and replaced by a reference within the WAL.
+func spillEntries(dir string, threshold uint64, ents []raftpb.Entry) ([]raftpb.Entry, error) {
+	var out []raftpb.Entry
+
+	for i, ent := range ents {
+		if ent.Type != raftpb.EntryNormal || uint64(len(ent.Data)) <= threshold {
+			continue
+		}
+
+		if out == nil {
+			out = make([]raftpb.Entry, len(ents))
+			copy(out, ents)
+
+			if err := os.MkdirAll(dir, 0750); err != nil {
+				return nil, fmt.Errorf("raft/storage: create blobs dir: %v", err)
+			}
+		}
+
+		path := filepath.Join(dir, blobName(ent.Term, ent.Index))
+		if err := writeBlob(path, ent.Data); err != nil {
+			return nil, fmt.Errorf("raft/storage: write entry %d blob: %v", ent.Index, err)
+		}
+
+		ref := make([]byte, 5)
+		ref[0] = blobMagic
+		binary.BigEndian.PutUint32(ref[1:], crc32.ChecksumIEEE(ent.Data))
+		out[i].Data = ref
+	}
+
+	if out == nil {
+		return ents, nil
+	}
+
+	return out, nil
+}
+
+// loadEntries replace in place the spilled entries data by their blob files content.
+func loadEntries(dir string, ents []raftpb.Entry) error {
+	for i, ent := range ents {
+		if ent.Type != raftpb.EntryNormal ||
+			len(ent.Data) != 5 ||
+			ent.Data[0] != blobMagic {
+			continue
+		}
+
+		path := filepath.Join(dir, blobName(ent.Term, ent.Index))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("raft/storage: read entry %d blob: %v", ent.Index, err)
+		}
+
+		if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(ent.Data[1:]) {
+			return fmt.Errorf("raft/storage: entry %d blob corrupted, crc mismatch", ent.Index)
+		}
+
+		ents[i].Data = data
+	}
+
+	return nil
+}
+
+// purgeBlobs removes blob files of entries before the given index.
+func purgeBlobs(dir string, index uint64) error {
+	if !fileutil.Exist(dir) {
+		return nil
+	}
+
+	files, err := list(dir, blobExt)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		var bt, bi uint64
+		if _, err := fmt.Sscanf(f, format+blobExt, &bt, &bi); err != nil {
+			return err
+		}
+
+		if bi >= index {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, f)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeBlob(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := fileutil.Fsync(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,47 @@
+package disk
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestSpillEntries(t *testing.T) {
+	dir := filepath.Join(createTestDir("spill", t), blobDir)
+	data := bytes.Repeat([]byte("x"), 100)
+	ents := []raftpb.Entry{
+		{Index: 1, Term: 1, Type: raftpb.EntryNormal, Data: []byte("small")},
+		{Index: 2, Term: 1, Type: raftpb.EntryNormal, Data: data},
+		{Index: 3, Term: 1, Type: raftpb.EntryConfChange, Data: data},
+	}
+
+	got, err := spillEntries(dir, 10, ents)
+	require.NoError(t, err)
+
+	// it does not mutate the given entries.
+	require.Equal(t, data, ents[1].Data)
+	require.Equal(t, ents[0], got[0])
+	require.Equal(t, ents[2], got[2])
+	require.Equal(t, blobMagic, got[1].Data[0])
+	require.FileExists(t, filepath.Join(dir, blobName(1, 2)))
+
+	err = loadEntries(dir, got)
+	require.NoError(t, err)
+	require.Equal(t, ents, got)
+
+	// it return error when blob corrupted.
+	got, _ = spillEntries(dir, 10, ents)
+	err = os.WriteFile(filepath.Join(dir, blobName(1, 2)), []byte("corrupted"), 0600)
+	require.NoError(t, err)
+	err = loadEntries(dir, got)
+	require.Contains(t, err.Error(), "crc mismatch")
+
+	// it purge blobs before the given index.
+	err = purgeBlobs(dir, 3)
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(dir, blobName(1, 2)))
+}
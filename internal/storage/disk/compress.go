@@ -0,0 +1,70 @@
+package disk
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// compressMagic prefix the data of compressed normal entries.
+// The engine always propose a marshaled replicate message,
+// its first byte is a small protobuf field tag, hence it never
+// collide with the magic byte.
+const compressMagic byte = 0xff
+
+const (
+	_ byte = iota
+	snappyCodec
+)
+
+// compressEntries returns a copy of the given entries,
+// where normal entries data compressed using snappy.
+// An entry data kept as is, when compression does not save space.
+func compressEntries(ents []raftpb.Entry) []raftpb.Entry {
+	out := make([]raftpb.Entry, len(ents))
+	for i, ent := range ents {
+		out[i] = ent
+		if ent.Type != raftpb.EntryNormal || len(ent.Data) == 0 {
+			continue
+		}
+
+		buf := make([]byte, 2+snappy.MaxEncodedLen(len(ent.Data)))
+		buf[0] = compressMagic
+		buf[1] = snappyCodec
+		buf = buf[:2+len(snappy.Encode(buf[2:], ent.Data))]
+
+		if len(buf) < len(ent.Data) {
+			out[i].Data = buf
+		}
+	}
+	return out
+}
+
+// decompressEntries decompress in place the given entries data,
+// Uncompressed entries left as is.
+func decompressEntries(ents []raftpb.Entry) error {
+	for i, ent := range ents {
+		if ent.Type != raftpb.EntryNormal ||
+			len(ent.Data) < 2 ||
+			ent.Data[0] != compressMagic {
+			continue
+		}
+
+		if ent.Data[1] != snappyCodec {
+			return fmt.Errorf(
+				"raft/storage: entry %d compressed using unknown codec %d",
+				ent.Index,
+				ent.Data[1],
+			)
+		}
+
+		data, err := snappy.Decode(nil, ent.Data[2:])
+		if err != nil {
+			return fmt.Errorf("raft/storage: decompress entry %d: %v", ent.Index, err)
+		}
+
+		ents[i].Data = data
+	}
+	return nil
+}
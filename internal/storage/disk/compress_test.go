@@ -0,0 +1,37 @@
+package disk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestCompressEntries(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"key":"value"}`), 100)
+	ents := []raftpb.Entry{
+		{Index: 1, Type: raftpb.EntryNormal},
+		{Index: 2, Type: raftpb.EntryNormal, Data: data},
+		{Index: 3, Type: raftpb.EntryConfChange, Data: data},
+		{Index: 4, Type: raftpb.EntryNormal, Data: []byte{1}},
+	}
+
+	got := compressEntries(ents)
+
+	// it does not mutate the given entries.
+	require.Equal(t, data, ents[1].Data)
+	require.Less(t, len(got[1].Data), len(data))
+	require.Equal(t, compressMagic, got[1].Data[0])
+	require.Equal(t, data, got[2].Data)
+	require.Equal(t, []byte{1}, got[3].Data)
+
+	err := decompressEntries(got)
+	require.NoError(t, err)
+	require.Equal(t, ents, got)
+
+	err = decompressEntries([]raftpb.Entry{
+		{Type: raftpb.EntryNormal, Data: []byte{compressMagic, 10}},
+	})
+	require.Contains(t, err.Error(), "unknown codec")
+}
@@ -2,13 +2,17 @@ package disk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/shaj13/raft/internal/storage"
 	"github.com/shaj13/raft/raftlog"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 	"go.etcd.io/etcd/server/v3/wal"
 	"go.etcd.io/etcd/server/v3/wal/walpb"
@@ -18,22 +22,78 @@ var _ storage.Storage = &disk{}
 
 // Config define common configuration used by the New function.
 type Config interface {
-	StateDir() string
+	WALDir() string
+	SnapDir() string
 	MaxSnapshotFiles() int
+	MinSnapshotFiles() int
+	SnapshotRetention() time.Duration
+	WALCompression() bool
+	SnapshotCompression() bool
+	VerifyWALOnBoot() bool
+	WALDropCache() bool
+	WALReadahead() bool
+	LogRetention() time.Duration
+	LogRetentionSize() uint64
+	EntrySpillThreshold() uint64
+	StorageMetrics() storage.Metrics
+	SnapshotStore() storage.ObjectStore
+	SharedSnapshotStore() storage.ObjectStore
+	WALArchive() storage.ObjectStore
 	Context() context.Context
 	Logger() raftlog.Logger
 }
 
 // New return new disk storage.
 func New(cfg Config) storage.Storage {
-	snapdir := filepath.Join(cfg.StateDir(), "snap")
-	waldir := filepath.Join(cfg.StateDir(), "wal")
+	snapdir := cfg.SnapDir()
+	waldir := cfg.WALDir()
+	metrics := cfg.StorageMetrics()
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+
+	// the object store calls canceled once the storage closed,
+	// to not block the shutdown on the background purge.
+	ctx, cancel := context.WithCancel(cfg.Context())
+
+	var store snapshotStore = localStore(snapdir)
+	if s := cfg.SnapshotStore(); s != nil {
+		store = objectStore{ctx, s}
+	}
+
+	var shared snapshotStore
+	if s := cfg.SharedSnapshotStore(); s != nil {
+		shared = objectStore{ctx, s}
+	}
+
+	var archive *archiver
+	if s := cfg.WALArchive(); s != nil {
+		archive = &archiver{ctx: ctx, store: s}
+	}
+
 	disk := &disk{
+		cancel:   cancel,
+		archive:  archive,
 		maxsnaps: cfg.MaxSnapshotFiles(),
+		minsnaps: cfg.MinSnapshotFiles(),
+		snapage:  cfg.SnapshotRetention(),
+		compress: cfg.WALCompression(),
+		verify:   cfg.VerifyWALOnBoot(),
+		dropc:    cfg.WALDropCache(),
+		readah:   cfg.WALReadahead(),
+		retain:   cfg.LogRetention(),
+		maxsize:  cfg.LogRetentionSize(),
+		spill:    cfg.EntrySpillThreshold(),
+		metrics:  metrics,
 		logger:   cfg.Logger(),
 		waldir:   waldir,
 		snapdir:  snapdir,
-		shoter:   &snapshotter{snapdir: snapdir},
+		shoter: &snapshotter{
+			snapdir:     snapdir,
+			store:       store,
+			shared:      shared,
+			compression: snapshotCompression(cfg.SnapshotCompression()),
+		},
 	}
 
 	return disk
@@ -43,34 +103,144 @@ func New(cfg Config) storage.Storage {
 type disk struct {
 	wal      *wal.WAL
 	shoter   *snapshotter
+	archive  *archiver
+	cancel   context.CancelFunc
+	purgec   chan uint64
+	purgewg  sync.WaitGroup
+	released uint64
 	logger   raftlog.Logger
+	metrics  storage.Metrics
 	maxsnaps int
+	minsnaps int
+	snapage  time.Duration
+	compress bool
+	verify   bool
+	dropc    bool
+	readah   bool
+	retain   time.Duration
+	maxsize  uint64
+	spill    uint64
 	waldir   string
 	snapdir  string
 }
 
-func (d *disk) purge() {
+// retainSnapshot reports whether the snapshot at the given position of the
+// snapshots ordered from the newest, is retained by the count and age retention.
+func (d *disk) retainSnapshot(i int, term, index uint64, err error) bool {
+	switch {
+	case i < d.minsnaps:
+		return true
+	case i >= d.maxsnaps:
+		return false
+	case d.snapage == 0 || err != nil:
+		return true
+	}
+
+	state, err := d.shoter.state(term, index)
+	// unknown creation time, snapshots written before recording it.
+	if err != nil || state.CreatedAt == 0 {
+		return true
+	}
+
+	return time.Since(time.Unix(0, state.CreatedAt)) <= d.snapage
+}
+
+// schedulePurge signals the background purge of the oldest snapshots and the WAL files
+// released up to the given index, the purge may call the snapshot store and the WAL archive,
+// therefore not run on the caller.
+func (d *disk) schedulePurge(released uint64) {
+	if d.purgec == nil {
+		d.purgec = make(chan uint64, 1)
+		d.purgewg.Add(1)
+		go func() {
+			defer d.purgewg.Done()
+			for released := range d.purgec {
+				d.purge(released)
+			}
+		}()
+	}
+
+	select {
+	case d.purgec <- released:
+	default:
+		// replace the pending purge release index with the newer one.
+		select {
+		case <-d.purgec:
+		default:
+		}
+		d.purgec <- released
+	}
+}
+
+// stopPurge cancels the in-flight object store calls, then waits for the background purge to exit.
+func (d *disk) stopPurge() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	if d.purgec != nil {
+		close(d.purgec)
+		d.purgewg.Wait()
+		d.purgec = nil
+	}
+}
+
+// purge removes the oldest snapshots beyond the retention, and the WAL files
+// preceding the oldest retained snapshot and released up to the given index,
+// the WAL files archived before being removed.
+func (d *disk) purge(released uint64) {
+	start := time.Now()
+	defer func() {
+		d.metrics.ObserveCompaction(time.Since(start))
+		if files, err := list(d.waldir, walExt); err == nil {
+			d.metrics.SetSegments(len(files))
+		}
+	}()
+
 	fn := func() error {
-		files, err := list(d.snapdir, snapExt)
-		if err != nil || len(files) < d.maxsnaps || len(files) == 0 {
+		files, err := d.shoter.store.list()
+		if err != nil || len(files) < d.maxsnaps && d.snapage == 0 || len(files) == 0 {
 			return err
 		}
 
 		// snapshots.
 		var (
-			current = files[0]
-			oldest  string
+			current  = files[0]
+			oldest   string
+			retained = make(map[string]struct{})
 		)
 
 		for i, f := range files {
-			if f != current && i >= d.maxsnaps {
-				path := filepath.Join(d.snapdir, f)
-				if err := os.Remove(path); err != nil {
-					return err
-				}
+			var st, si uint64
+			_, serr := fmt.Sscanf(f, format+snapExt, &st, &si)
+
+			if f != current && !d.retainSnapshot(i, st, si, serr) {
 				continue
 			}
+
 			oldest = f
+			retained[f] = struct{}{}
+
+			// retain the snapshots a delta snapshot chained onto,
+			// a snapshot that can't be read has no chain to retain.
+			if serr != nil {
+				continue
+			}
+
+			chain, _ := d.shoter.Chain(st, si)
+			for _, m := range chain {
+				retained[snapshotName(m.Term, m.Index)] = struct{}{}
+			}
+		}
+
+		for _, f := range files {
+			if _, ok := retained[f]; ok {
+				continue
+			}
+
+			if err := d.shoter.store.remove(f); err != nil {
+				return err
+			}
 		}
 
 		// oldest snapshot term and index.
@@ -80,6 +250,11 @@ func (d *disk) purge() {
 			return err
 		}
 
+		// the WAL files beyond the release index still locked by the WAL.
+		if released < si {
+			si = released
+		}
+
 		files, err = list(d.waldir, walExt)
 		if err != nil {
 			return err
@@ -104,8 +279,44 @@ func (d *disk) purge() {
 			mark = len(files) - 1
 		}
 
+		if mark <= 0 {
+			return nil
+		}
+
+		// total size of WAL files, used by the size retention.
+		var total uint64
+		infos := make([]os.FileInfo, len(files))
+		for i, f := range files {
+			info, err := os.Stat(filepath.Join(d.waldir, f))
+			if err != nil {
+				return err
+			}
+			infos[i] = info
+			total += uint64(info.Size())
+		}
+
+		removed := 0
 		for i := 0; i < mark; i++ {
-			path := filepath.Join(d.waldir, files[len(files)-i-1])
+			idx := len(files) - i - 1
+			info := infos[idx]
+
+			// retain WAL files modified within the retention period,
+			// unless the WAL files exceed the retention size.
+			if d.retain > 0 &&
+				time.Since(info.ModTime()) < d.retain &&
+				(d.maxsize == 0 || total <= d.maxsize) {
+				break
+			}
+
+			// archive the WAL file before removing it,
+			// the WAL file retained until archived.
+			if d.archive != nil {
+				if err := d.archive.segment(d.waldir, files[idx]); err != nil {
+					return err
+				}
+			}
+
+			path := filepath.Join(d.waldir, files[idx])
 			lock, err := fileutil.TryLockFile(path, os.O_WRONLY, fileutil.PrivateFileMode)
 			if err != nil {
 				return err
@@ -117,21 +328,42 @@ func (d *disk) purge() {
 			if err != nil {
 				return err
 			}
+
+			total -= uint64(info.Size())
+			removed++
 		}
 
-		return nil
+		// blobs of entries before the oldest retained WAL file.
+		var ws, wi uint64
+		_, err = fmt.Sscanf(files[len(files)-removed-1], format+walExt, &ws, &wi)
+		if err != nil {
+			return err
+		}
+
+		if d.archive != nil {
+			if err := d.archive.blobs(filepath.Join(d.waldir, blobDir), wi); err != nil {
+				return err
+			}
+		}
+
+		return purgeBlobs(filepath.Join(d.waldir, blobDir), wi)
 	}
 
 	if err := fn(); err != nil {
 		d.logger.Warningf("raft.storage: purging oldest snapshots/WALs files: %v", err)
 	}
+
+	if d.dropc {
+		d.adviseWAL(adviceDontNeed, 1)
+	}
 }
 
 // SaveSnapshot saves a given snapshot into the WAL.
 // The raw snapshot must be saved into disk during the,
 // network transportation.
-func (d *disk) SaveSnapshot(snap raftpb.Snapshot) error {
-	defer d.purge()
+func (d *disk) SaveSnapshot(snap raftpb.Snapshot) (err error) {
+	defer recoverAbort(&err)
+	defer func() { d.schedulePurge(d.released) }()
 
 	walSnap := walpb.Snapshot{
 		Index:     snap.Metadata.Index,
@@ -150,17 +382,55 @@ func (d *disk) SaveSnapshot(snap raftpb.Snapshot) error {
 		return err
 	}
 
-	return d.wal.ReleaseLockTo(snap.Metadata.Index)
+	if err := d.wal.ReleaseLockTo(snap.Metadata.Index); err != nil {
+		return err
+	}
+
+	d.released = snap.Metadata.Index
+	return nil
 }
 
 // SaveEntries saves a given entries into the WAL.
-func (d *disk) SaveEntries(st raftpb.HardState, ents []raftpb.Entry) error {
-	return d.wal.Save(st, ents)
+// Normal entries data exceeding the spill threshold written into blob files,
+// and compressed when WAL compression enabled.
+func (d *disk) SaveEntries(st raftpb.HardState, ents []raftpb.Entry) (err error) {
+	defer recoverAbort(&err)
+
+	if d.spill > 0 {
+		ents, err = spillEntries(filepath.Join(d.waldir, blobDir), d.spill, ents)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.compress {
+		ents = compressEntries(ents)
+	}
+
+	start := time.Now()
+	if err := d.wal.Save(st, ents); err != nil {
+		return err
+	}
+
+	d.metrics.ObserveSync(time.Since(start))
+
+	n := uint64(st.Size())
+	for _, ent := range ents {
+		n += uint64(ent.Size())
+	}
+
+	d.metrics.AddBytesWritten(n)
+	return nil
 }
 
 // Boot return wal metadata, hard-state, entries, and newest snapshot,
 // Otherwise, it create new wal from given metadata alongside snapshots dir.
-func (d *disk) Boot(meta []byte) ([]byte, raftpb.HardState, []raftpb.Entry, *storage.Snapshot, error) {
+func (d *disk) Boot(
+	ctx context.Context,
+	meta []byte,
+) (_ []byte, _ raftpb.HardState, _ []raftpb.Entry, _ *storage.Snapshot, err error) {
+	defer recoverAbort(&err)
+
 	fail := func(err error) ([]byte, raftpb.HardState, []raftpb.Entry, *storage.Snapshot, error) {
 		return []byte{}, raftpb.HardState{}, []raftpb.Entry{}, nil, err
 	}
@@ -180,7 +450,7 @@ func (d *disk) Boot(meta []byte) ([]byte, raftpb.HardState, []raftpb.Entry, *sto
 			)
 		}
 
-		w, err := wal.Create(nil, d.waldir, meta)
+		w, err := wal.Create(newZapLogger(d.logger), d.waldir, meta)
 		if err != nil {
 			return fail(
 				fmt.Errorf("raft/storage: create WAL file: %v", err),
@@ -191,7 +461,7 @@ func (d *disk) Boot(meta []byte) ([]byte, raftpb.HardState, []raftpb.Entry, *sto
 		return meta, raftpb.HardState{}, []raftpb.Entry{}, nil, nil
 	}
 
-	walSnaps, err := wal.ValidSnapshotEntries(nil, d.waldir)
+	walSnaps, err := wal.ValidSnapshotEntries(newZapLogger(d.logger), d.waldir)
 
 	if err != nil {
 		return fail(
@@ -199,7 +469,7 @@ func (d *disk) Boot(meta []byte) ([]byte, raftpb.HardState, []raftpb.Entry, *sto
 		)
 	}
 
-	sf, err := decodeNewestAvailableSnapshot(d.snapdir, walSnaps)
+	sf, err := decodeNewestAvailableSnapshot(ctx, d.shoter.store, walSnaps)
 	if err == errNoSnapshot {
 		sf = new(storage.Snapshot)
 	} else if err != nil {
@@ -213,7 +483,41 @@ func (d *disk) Boot(meta []byte) ([]byte, raftpb.HardState, []raftpb.Entry, *sto
 		Term:  sf.Raw.Metadata.Term,
 	}
 
-	w, err := wal.Open(nil, d.waldir, walsnap)
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	if d.readah {
+		d.adviseWAL(adviceWillNeed, 0)
+	}
+
+	if d.verify {
+		v, err := verify(newZapLogger(d.logger), d.waldir, walsnap)
+		if err != nil {
+			return fail(err)
+		}
+
+		d.logger.Infof(
+			"raft.storage: WAL verified [entries: %d | first index: %d | last index: %d | commit: %d | term: %d]",
+			v.Entries,
+			v.FirstIndex,
+			v.LastIndex,
+			v.HardState.Commit,
+			v.HardState.Term,
+		)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	w, err := wal.Open(newZapLogger(d.logger), d.waldir, walsnap)
+	if errors.Is(err, wal.ErrFileNotFound) {
+		return fail(
+			fmt.Errorf("%w: open WAL at index %d: %v", storage.ErrSnapshotNotInWAL, walsnap.Index, err),
+		)
+	}
+
 	if err != nil {
 		return fail(
 			fmt.Errorf("raft/storage: open WAL: %v", err),
@@ -221,24 +525,133 @@ func (d *disk) Boot(meta []byte) ([]byte, raftpb.HardState, []raftpb.Entry, *sto
 	}
 	meta, st, ents, err := w.ReadAll()
 
+	if errors.Is(err, wal.ErrSnapshotNotFound) {
+		_ = w.Close()
+		return fail(
+			fmt.Errorf("%w: read WAL at index %d: %v", storage.ErrSnapshotNotInWAL, walsnap.Index, err),
+		)
+	}
+
 	if err != nil {
+		_ = w.Close()
 		return fail(
 			fmt.Errorf("raft/storage: read WAL: %v", err),
 		)
 	}
 
+	if err := validate(walsnap, st, ents); err != nil {
+		_ = w.Close()
+		return fail(err)
+	}
+
+	// entries may be compressed by a previous run,
+	// regardless of the current compression setting.
+	if err := decompressEntries(ents); err != nil {
+		_ = w.Close()
+		return fail(err)
+	}
+
+	if err := loadEntries(filepath.Join(d.waldir, blobDir), ents); err != nil {
+		_ = w.Close()
+		return fail(err)
+	}
+
+	// the sealed segments not read again until the next boot.
+	if d.dropc {
+		d.adviseWAL(adviceDontNeed, 1)
+	}
+
 	d.wal = w
+	d.released = walsnap.Index
 	return meta, st, ents, sf, nil
 }
 
+// adviseWAL applies the given access pattern advice on the WAL segments,
+// except the given number of the newest segments.
+func (d *disk) adviseWAL(advice, skip int) {
+	files, err := list(d.waldir, walExt)
+	if err == nil && len(files) > skip {
+		for _, f := range files[skip:] {
+			if err = fadvise(filepath.Join(d.waldir, f), advice); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		d.logger.Warningf("raft.storage: advising WAL files page cache: %v", err)
+	}
+}
+
 func (d *disk) Exist() bool {
 	return wal.Exist(d.waldir)
 }
 
+// Available returns the minimum available bytes between,
+// the WAL and snapshots filesystems.
+func (d *disk) Available() (uint64, error) {
+	wal, err := available(d.waldir)
+	if err != nil {
+		return 0, err
+	}
+
+	snap, err := available(d.snapdir)
+	if err != nil {
+		return 0, err
+	}
+
+	if snap < wal {
+		return snap, nil
+	}
+
+	return wal, nil
+}
+
 func (d *disk) Snapshotter() storage.Snapshotter {
 	return d.shoter
 }
 
 func (d *disk) Close() error {
+	d.stopPurge()
 	return d.wal.Close()
 }
+
+// validate cross-checks the snapshot, hard state, and entries read at boot,
+// so raft does not panic on inconsistent state.
+func validate(snap walpb.Snapshot, st raftpb.HardState, ents []raftpb.Entry) error {
+	last := snap.Index
+	if len(ents) > 0 {
+		if ents[0].Index != snap.Index+1 {
+			return fmt.Errorf(
+				"%w: snapshot index %d, first entry index %d",
+				storage.ErrEntriesGap,
+				snap.Index,
+				ents[0].Index,
+			)
+		}
+		last = ents[len(ents)-1].Index
+	}
+
+	if raft.IsEmptyHardState(st) {
+		return nil
+	}
+
+	if st.Commit < snap.Index || st.Commit > last {
+		return fmt.Errorf(
+			"%w: commit index %d, range [%d, %d]",
+			storage.ErrCommitOutOfRange,
+			st.Commit,
+			snap.Index,
+			last,
+		)
+	}
+
+	return nil
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveSync(time.Duration)       {}
+func (nopMetrics) AddBytesWritten(uint64)          {}
+func (nopMetrics) SetSegments(int)                 {}
+func (nopMetrics) ObserveCompaction(time.Duration) {}
@@ -1,11 +1,17 @@
 package disk
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
+	storagemock "github.com/shaj13/raft/internal/mocks/storage"
+	"github.com/shaj13/raft/internal/storage"
 	"github.com/shaj13/raft/raftlog"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
@@ -56,17 +62,17 @@ func TestDiskBootMkdir(t *testing.T) {
 	d.snapdir = ""
 	d.waldir = ""
 
-	_, _, _, _, err := d.Boot(nil)
+	_, _, _, _, err := d.Boot(context.TODO(), nil)
 	require.Contains(t, err.Error(), "create snapshot dir")
 
 	d.snapdir = os.TempDir()
-	_, _, _, _, err = d.Boot(nil)
+	_, _, _, _, err = d.Boot(context.TODO(), nil)
 	require.Contains(t, err.Error(), "create WAL dir")
 
 	// it now should create dir
 	d.snapdir = temp
 	d.waldir = temp
-	_, _, _, _, err = d.Boot(nil)
+	_, _, _, _, err = d.Boot(context.TODO(), nil)
 	require.NoError(t, err)
 	require.True(t, fileutil.Exist(temp))
 }
@@ -81,10 +87,10 @@ func TestDiskBoot(t *testing.T) {
 		defer d.Close()
 		os.RemoveAll(temp)
 
-		_, _, _, _, err := d.Boot(nil)
+		_, _, _, _, err := d.Boot(context.TODO(), nil)
 		require.NoError(t, err)
 
-		_, _, _, _, err = d.Boot(nil)
+		_, _, _, _, err = d.Boot(context.TODO(), nil)
 		require.Contains(t, err.Error(), "file already locked")
 	})
 
@@ -93,21 +99,56 @@ func TestDiskBoot(t *testing.T) {
 		os.RemoveAll(temp)
 		meta := []byte("wal metadata")
 
-		_, _, _, _, err := d.Boot(meta)
+		_, _, _, _, err := d.Boot(context.TODO(), meta)
 		require.NoError(t, err)
 		d.Close()
 
-		got, _, _, _, err := d.Boot(nil)
+		got, _, _, _, err := d.Boot(context.TODO(), nil)
 		require.NoError(t, err)
 		require.Equal(t, meta, got)
 	})
 }
 
+func TestDiskBootAdvice(t *testing.T) {
+	dir := createTestDir("advice", t)
+	d := newTestDisk(dir)
+	d.dropc = true
+	d.readah = true
+
+	_, _, _, _, err := d.Boot(context.TODO(), nil)
+	require.NoError(t, err)
+
+	ents := []raftpb.Entry{{Term: 1, Index: 1, Data: []byte("data")}}
+	require.NoError(t, d.SaveEntries(raftpb.HardState{Term: 1, Commit: 1}, ents))
+	require.NoError(t, d.Close())
+
+	// it read the advised WAL segments.
+	_, _, got, _, err := d.Boot(context.TODO(), nil)
+	require.NoError(t, err)
+	require.Equal(t, ents, got)
+	require.NoError(t, d.Close())
+
+	// it tolerate advising a missing WAL dir.
+	d.waldir = filepath.Join(dir, "missing")
+	d.adviseWAL(adviceDontNeed, 0)
+}
+
 func TestDiskExist(t *testing.T) {
 	d := new(disk)
 	require.False(t, d.Exist())
 }
 
+func TestDiskAvailable(t *testing.T) {
+	d := newTestDisk(os.TempDir())
+	n, err := d.Available()
+	require.NoError(t, err)
+	require.NotZero(t, n)
+
+	d.waldir = filepath.Join(os.TempDir(), "/test_disk_available")
+	_, err = d.Available()
+	require.Error(t, err)
+}
+
 func TestDiskPurge(t *testing.T) {
 	dir := createTestDir("purge", t)
 	defer os.RemoveAll(dir)
@@ -125,7 +166,7 @@ func TestDiskPurge(t *testing.T) {
 	// gc := newGC(context.TODO(), raftlog.DefaultLogger, dir, dir, 1)
 	disk := newTestDisk(dir)
 	disk.maxsnaps = 1
-	disk.purge()
+	disk.purge(math.MaxUint64)
 
 	snaps, _ := list(dir, snapExt)
 	wals, _ := list(dir, walExt)
@@ -135,16 +176,232 @@ func TestDiskPurge(t *testing.T) {
 	require.Equal(t, wals[0], fmt.Sprintf(format, 4, 4)+walExt)
 }
 
+func TestDiskSchedulePurge(t *testing.T) {
+	dir := createTestDir("schedule_purge", t)
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	store := &blockObjectStore{memObjectStore: newMemObjectStore(), listed: make(chan struct{})}
+	disk := newTestDisk(dir)
+	disk.maxsnaps = 1
+	disk.cancel = cancel
+	disk.shoter.store = objectStore{ctx, store}
+
+	// it purge in the background, without waiting for the object store.
+	disk.schedulePurge(math.MaxUint64)
+	<-store.listed
+	disk.schedulePurge(math.MaxUint64)
+
+	// it cancel the object store calls, once stopped.
+	disk.stopPurge()
+	require.Error(t, ctx.Err())
+}
+
+// blockObjectStore is an object store blocking the listing until the context done.
+type blockObjectStore struct {
+	*memObjectStore
+	listed chan struct{}
+}
+
+func (s *blockObjectStore) List(ctx context.Context) ([]string, error) {
+	select {
+	case s.listed <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDiskPurgeRetention(t *testing.T) {
+	dir := createTestDir("purge_retention", t)
+	defer os.RemoveAll(dir)
+
+	files := []string{}
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf(format, i, i)
+		files = append(files, name+snapExt, name+walExt)
+	}
+
+	createTestFiles(dir, files, t)
+
+	disk := newTestDisk(dir)
+	disk.maxsnaps = 1
+	disk.retain = time.Hour
+
+	// it retain all WAL files within the retention period.
+	disk.purge(math.MaxUint64)
+	wals, _ := list(dir, walExt)
+	require.Equal(t, 5, len(wals))
+
+	// it purge WAL files older than the retention period.
+	old := time.Now().Add(-2 * time.Hour)
+	for i := 0; i < 2; i++ {
+		path := filepath.Join(dir, fmt.Sprintf(format, i, i)+walExt)
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+
+	disk.purge(math.MaxUint64)
+	wals, _ = list(dir, walExt)
+	require.Equal(t, 3, len(wals))
+
+	// it purge WAL files when exceeding the retention size.
+	for _, f := range wals {
+		err := os.WriteFile(filepath.Join(dir, f), make([]byte, 10), 0600)
+		require.NoError(t, err)
+	}
+
+	disk.maxsize = 10
+	disk.purge(math.MaxUint64)
+	wals, _ = list(dir, walExt)
+	require.Equal(t, 1, len(wals))
+	require.Equal(t, fmt.Sprintf(format, 4, 4)+walExt, wals[0])
+}
+
+func TestDiskPurgeDeltaChain(t *testing.T) {
+	dir := createTestDir("purge_chain", t)
+	defer os.RemoveAll(dir)
+
+	disk := newTestDisk(dir)
+	disk.maxsnaps = 1
+
+	for i := uint64(1); i <= 4; i++ {
+		sf, _ := snapshotTestFile()
+		sf.Raw.Metadata.Index = i
+		// snapshot 4 chained onto snapshot 2.
+		if i == 4 {
+			sf.BaseTerm = 1
+			sf.BaseIndex = 2
+		}
+		require.NoError(t, disk.shoter.Write(&sf))
+	}
+
+	disk.purge(math.MaxUint64)
+
+	snaps, _ := list(dir, snapExt)
+	require.Equal(t, []string{snapshotName(1, 4), snapshotName(1, 2)}, snaps)
+}
+
+func TestDiskPurgeSnapshotRetention(t *testing.T) {
+	dir := createTestDir("purge_snap_retention", t)
+	defer os.RemoveAll(dir)
+
+	disk := newTestDisk(dir)
+	disk.maxsnaps = 5
+	disk.minsnaps = 3
+	disk.snapage = time.Hour
+
+	old := time.Now().Add(-2 * time.Hour).UnixNano()
+	for i := uint64(1); i <= 5; i++ {
+		sf, _ := snapshotTestFile()
+		sf.Raw.Metadata.Index = i
+		if i <= 3 {
+			sf.CreatedAt = old
+		}
+		require.NoError(t, disk.shoter.Write(&sf))
+	}
+
+	// it always keep the newest snapshots regardless of their age.
+	disk.purge(math.MaxUint64)
+	snaps, _ := list(dir, snapExt)
+	require.Equal(t, []string{snapshotName(1, 5), snapshotName(1, 4), snapshotName(1, 3)}, snaps)
+
+	// it purge snapshots older than the retention age.
+	disk.minsnaps = 1
+	disk.purge(math.MaxUint64)
+	snaps, _ = list(dir, snapExt)
+	require.Equal(t, []string{snapshotName(1, 5), snapshotName(1, 4)}, snaps)
+}
+
+func TestDiskMetrics(t *testing.T) {
+	dir := createTestDir("metrics", t)
+	defer os.RemoveAll(dir)
+
+	ctrl := gomock.NewController(t)
+	metrics := storagemock.NewMockMetrics(ctrl)
+	metrics.EXPECT().ObserveSync(gomock.Any())
+	metrics.EXPECT().AddBytesWritten(gomock.Any()).Do(func(n uint64) {
+		require.NotZero(t, n)
+	})
+	metrics.EXPECT().ObserveCompaction(gomock.Any())
+	metrics.EXPECT().SetSegments(gomock.Eq(1))
+
+	w, _ := wal.Create(nil, dir, nil)
+	disk := newTestDisk(dir)
+	disk.wal = w
+	disk.metrics = metrics
+	defer disk.Close()
+
+	err := disk.SaveEntries(raftpb.HardState{Term: 1}, []raftpb.Entry{{Index: 1, Term: 1}})
+	require.NoError(t, err)
+
+	disk.purge(math.MaxUint64)
+}
+
+func TestValidate(t *testing.T) {
+	table := []struct {
+		name string
+		snap walpb.Snapshot
+		hs   raftpb.HardState
+		ents []raftpb.Entry
+		err  error
+	}{
+		{
+			name: "it return nil on empty state",
+		},
+		{
+			name: "it return nil on consistent state",
+			snap: walpb.Snapshot{Index: 5, Term: 1},
+			hs:   raftpb.HardState{Term: 1, Commit: 6},
+			ents: []raftpb.Entry{{Index: 6, Term: 1}, {Index: 7, Term: 1}},
+		},
+		{
+			name: "it return ErrEntriesGap when entries does not follow snapshot",
+			snap: walpb.Snapshot{Index: 5, Term: 1},
+			ents: []raftpb.Entry{{Index: 8, Term: 1}},
+			err:  storage.ErrEntriesGap,
+		},
+		{
+			name: "it return ErrCommitOutOfRange when commit behind snapshot",
+			snap: walpb.Snapshot{Index: 5, Term: 1},
+			hs:   raftpb.HardState{Term: 1, Commit: 4},
+			err:  storage.ErrCommitOutOfRange,
+		},
+		{
+			name: "it return ErrCommitOutOfRange when commit beyond last entry",
+			snap: walpb.Snapshot{Index: 5, Term: 1},
+			hs:   raftpb.HardState{Term: 1, Commit: 7},
+			ents: []raftpb.Entry{{Index: 6, Term: 1}},
+			err:  storage.ErrCommitOutOfRange,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(tt.snap, tt.hs, tt.ents)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
 func newTestDisk(dir string) *disk {
 	d := new(disk)
 	d.logger = raftlog.DefaultLogger
+	d.metrics = nopMetrics{}
+	d.shoter = &snapshotter{snapdir: dir, store: localStore(dir)}
 	d.snapdir = dir
 	d.waldir = dir
 	return d
 }
 
 // type config struct{}
-// func (config) StateDir() (str string)    { return }
+// func (config) WALDir() (str string)      { return }
+// func (config) SnapDir() (str string)     { return }
 // func (config) MaxSnapshotFiles() (i int) { return }
+// func (config) WALCompression() (b bool)  { return }
+// func (config) VerifyWALOnBoot() (b bool) { return }
+// func (config) LogRetention() (d time.Duration) { return }
+// func (config) LogRetentionSize() (n uint64)     { return }
+// func (config) EntrySpillThreshold() (n uint64)  { return }
+// func (config) StorageMetrics() (m storage.Metrics) { return }
 // func (config) Context() context.Context  { return context.TODO() }
 // func (config) Logger() raftlog.Logger    { return raftlog.DefaultLogger }
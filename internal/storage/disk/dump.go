@@ -0,0 +1,222 @@
+package disk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/shaj13/raft/internal/storage"
+	"go.etcd.io/etcd/pkg/v3/crc"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/wal/walpb"
+)
+
+// WAL record types, see go.etcd.io/etcd/server/v3/wal.
+const (
+	metadataRecord int64 = iota + 1
+	entryRecord
+	stateRecord
+	crcRecord
+	snapshotRecord
+)
+
+// walCRCTable is the table of the WAL records rolling crc.
+var walCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Segment describes the content of a WAL segment file.
+type Segment struct {
+	// Name specifies the segment file name.
+	Name string
+	// Seq specifies the segment sequence number.
+	Seq uint64
+	// Index specifies the raft index the segment starts after.
+	Index uint64
+	// Size specifies the segment file size including the preallocated space.
+	Size int64
+	// CRC specifies the rolling crc the segment starts from.
+	CRC uint32
+	// Metadata specifies the WAL metadata recorded at the segment head.
+	Metadata []byte
+	// Snapshots specifies the snapshot records within the segment.
+	Snapshots []walpb.Snapshot
+	// HardState specifies the newest hard state within the segment.
+	HardState raftpb.HardState
+	// Entries specifies the entries within the segment, in the written order.
+	// The entries data decompressed and loaded from the blob files.
+	Entries []raftpb.Entry
+	// Torn reports whether the segment ends with a partially written record.
+	Torn bool
+}
+
+// FirstIndex returns the first entry index within the segment, Otherwise 0.
+func (s *Segment) FirstIndex() uint64 {
+	if len(s.Entries) == 0 {
+		return 0
+	}
+	return s.Entries[0].Index
+}
+
+// LastIndex returns the last entry index within the segment, Otherwise 0.
+func (s *Segment) LastIndex() uint64 {
+	if len(s.Entries) == 0 {
+		return 0
+	}
+	return s.Entries[len(s.Entries)-1].Index
+}
+
+// ReadSegments reads the WAL segments within the given WAL dir, ordered by sequence.
+// It's intended for offline inspection, the records checksums validated within each segment,
+// and it returns an error wrapping storage.ErrWALCorrupted on mismatch,
+// use the VerifyWALOnBoot option to validate the WAL as a whole.
+func ReadSegments(waldir string) ([]*Segment, error) {
+	names, err := list(waldir, walExt)
+	if err != nil {
+		return nil, fmt.Errorf("raft/storage: list WAL segments: %v", err)
+	}
+
+	sort.Strings(names)
+
+	segs := make([]*Segment, 0, len(names))
+	for _, name := range names {
+		seg, err := readSegment(waldir, name)
+		if err != nil {
+			return nil, fmt.Errorf("raft/storage: read WAL segment %s: %w", name, err)
+		}
+		segs = append(segs, seg)
+	}
+
+	return segs, nil
+}
+
+func readSegment(waldir, name string) (*Segment, error) {
+	seg := &Segment{Name: name}
+	if _, err := fmt.Sscanf(name, format+walExt, &seg.Seq, &seg.Index); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(waldir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	seg.Size = fi.Size()
+	r := bufio.NewReader(f)
+
+	// the rolling crc starts from the segment crc record,
+	// as done by go.etcd.io/etcd/server/v3/wal decoder.
+	sum := crc.New(0, walCRCTable)
+
+	for {
+		rec, err := readRecord(r, seg.Size)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			seg.Torn = true
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if rec.Type == crcRecord {
+			sum = crc.New(rec.Crc, walCRCTable)
+		} else {
+			_, _ = sum.Write(rec.Data)
+			if err := rec.Validate(sum.Sum32()); err != nil {
+				return nil, storage.ErrWALCorrupted
+			}
+		}
+
+		switch rec.Type {
+		case crcRecord:
+			seg.CRC = rec.Crc
+		case metadataRecord:
+			seg.Metadata = rec.Data
+		case snapshotRecord:
+			snap := walpb.Snapshot{}
+			if err := snap.Unmarshal(rec.Data); err != nil {
+				return nil, err
+			}
+			seg.Snapshots = append(seg.Snapshots, snap)
+		case stateRecord:
+			if err := seg.HardState.Unmarshal(rec.Data); err != nil {
+				return nil, err
+			}
+		case entryRecord:
+			ent := raftpb.Entry{}
+			if err := ent.Unmarshal(rec.Data); err != nil {
+				return nil, err
+			}
+			seg.Entries = append(seg.Entries, ent)
+		default:
+			return nil, fmt.Errorf("unexpected record type %d", rec.Type)
+		}
+	}
+
+	if err := decompressEntries(seg.Entries); err != nil {
+		return nil, err
+	}
+
+	if err := loadEntries(filepath.Join(waldir, blobDir), seg.Entries); err != nil {
+		return nil, err
+	}
+
+	return seg, nil
+}
+
+// readRecord reads the next WAL record frame,
+// It returns io.EOF at the end of file or the preallocated space,
+// and io.ErrUnexpectedEOF when the record exceeds the given max size or partially written.
+func readRecord(r io.Reader, max int64) (*walpb.Record, error) {
+	var l int64
+	if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	if l == 0 {
+		return nil, io.EOF
+	}
+
+	// the record size stored in the lower 56 bits,
+	// and the padding in the lower 3 bits of the MSB when set.
+	size := int64(uint64(l) & ^(uint64(0xff) << 56))
+	pad := int64(0)
+	if l < 0 {
+		pad = int64((uint64(l) >> 56) & 0x7)
+	}
+
+	if size+pad > max {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, size+pad)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	rec := new(walpb.Record)
+	if err := rec.Unmarshal(data[:size]); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return rec, nil
+}
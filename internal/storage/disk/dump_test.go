@@ -0,0 +1,64 @@
+package disk
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestReadSegments(t *testing.T) {
+	dir := createTestDir("wal", t)
+	blob := make([]byte, 64)
+
+	d := newTestDisk(dir)
+	d.compress = true
+	d.spill = 32
+
+	_, _, _, _, err := d.Boot(context.TODO(), []byte("meta"))
+	require.NoError(t, err)
+
+	hs := raftpb.HardState{Term: 1, Commit: 2}
+	err = d.SaveEntries(hs, []raftpb.Entry{
+		{Term: 1, Index: 1, Data: []byte("aaaaaaaaaaaaaaaaaaaaaaaa")},
+		{Term: 1, Index: 2, Data: blob},
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.Close())
+
+	segs, err := ReadSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segs, 1)
+
+	seg := segs[0]
+	require.Equal(t, []byte("meta"), seg.Metadata)
+	require.Equal(t, hs, seg.HardState)
+	require.Len(t, seg.Snapshots, 1)
+	require.False(t, seg.Torn)
+	require.Equal(t, uint64(1), seg.FirstIndex())
+	require.Equal(t, uint64(2), seg.LastIndex())
+
+	// it should decompress and load the spilled entries.
+	require.Equal(t, []byte("aaaaaaaaaaaaaaaaaaaaaaaa"), seg.Entries[0].Data)
+	require.Equal(t, blob, seg.Entries[1].Data)
+
+	// it should report the partially written records.
+	dir = createTestDir("torn", t)
+	frame := make([]byte, 18)
+	binary.LittleEndian.PutUint64(frame, 100)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, walName(0, 0)), frame, 0600))
+
+	segs, err = ReadSegments(dir)
+	require.NoError(t, err)
+	require.True(t, segs[0].Torn)
+	require.Empty(t, segs[0].Entries)
+}
+
+func walName(seq, index uint64) string {
+	return fmt.Sprintf(format, seq, index) + walExt
+}
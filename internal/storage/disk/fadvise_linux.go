@@ -0,0 +1,28 @@
+//go:build linux
+
+package disk
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// adviceWillNeed initiates a readahead of the file into the page cache.
+	adviceWillNeed = unix.FADV_WILLNEED
+	// adviceDontNeed drops the file clean pages from the page cache.
+	adviceDontNeed = unix.FADV_DONTNEED
+)
+
+// fadvise applies the given access pattern advice on the whole file of the given path.
+func fadvise(path string, advice int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return unix.Fadvise(int(f.Fd()), 0, 0, advice)
+}
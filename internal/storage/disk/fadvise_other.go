@@ -0,0 +1,13 @@
+//go:build !linux
+
+package disk
+
+const (
+	adviceWillNeed = iota
+	adviceDontNeed
+)
+
+// fadvise is a no-op, as the platform does not support posix_fadvise.
+func fadvise(path string, advice int) error {
+	return nil
+}
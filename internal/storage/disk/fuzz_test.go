@@ -0,0 +1,107 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+
+	internalraftpb "github.com/shaj13/raft/internal/raftpb"
+)
+
+func FuzzReadSegments(f *testing.F) {
+	dir := createTestDir("wal", f)
+	d := newTestDisk(dir)
+	d.compress = true
+
+	_, _, _, _, err := d.Boot(context.TODO(), []byte("meta"))
+	require.NoError(f, err)
+	err = d.SaveEntries(raftpb.HardState{Term: 1, Commit: 1}, []raftpb.Entry{
+		{Term: 1, Index: 1, Data: bytes.Repeat([]byte("data"), 16)},
+		{Term: 1, Index: 2, Type: raftpb.EntryConfChange},
+	})
+	require.NoError(f, err)
+	require.NoError(f, d.Close())
+
+	segs, err := ReadSegments(dir)
+	require.NoError(f, err)
+
+	seg, err := os.ReadFile(filepath.Join(dir, segs[0].Name))
+	require.NoError(f, err)
+
+	// trim the preallocated space.
+	f.Add(bytes.TrimRight(seg, "\x00"))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, walName(0, 0)), data, 0600))
+
+		segs, err := ReadSegments(dir)
+		if err != nil {
+			return
+		}
+
+		require.Len(t, segs, 1)
+		require.LessOrEqual(t, segs[0].Size, int64(len(data)))
+	})
+}
+
+func FuzzDecompressEntries(f *testing.F) {
+	ents := compressEntries([]raftpb.Entry{{Data: bytes.Repeat([]byte("data"), 16)}})
+	f.Add(ents[0].Data)
+	f.Add([]byte{compressMagic, snappyCodec})
+	f.Add([]byte{compressMagic, 0x7f, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ents := []raftpb.Entry{{Index: 1, Data: data}}
+		if err := decompressEntries(ents); err != nil {
+			return
+		}
+
+		// the decompressed data must round trip.
+		if len(data) > 1 && data[0] == compressMagic {
+			got := compressEntries(ents)
+			require.NoError(t, decompressEntries(got))
+			require.Equal(t, ents[0].Data, got[0].Data)
+		}
+	})
+}
+
+func FuzzDecodeSnapshot(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*"+snapExt))
+	require.NoError(f, err)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+
+	for _, c := range []internalraftpb.Compression{internalraftpb.NoCompression, internalraftpb.SnappyCompression} {
+		sf, _ := snapshotTestFile()
+		buf := new(bytes.Buffer)
+		require.NoError(f, writeSnapshot(buf, &sf, c))
+		f.Add(buf.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(t.TempDir(), snapshotName(1, 1))
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		sf, err := ReadSnapshot(context.TODO(), path)
+		if err != nil {
+			return
+		}
+
+		defer sf.Data.Close()
+
+		// the checksums verified, the snapshot data either read or fails, e.g. a malformed snappy stream.
+		_, _ = io.Copy(io.Discard, sf.Data)
+	})
+}
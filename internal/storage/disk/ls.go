@@ -9,6 +9,7 @@ import (
 const (
 	snapExt = ".snap"
 	walExt  = ".wal"
+	partExt = ".part"
 	format  = "%016x-%016x"
 )
 
@@ -3,14 +3,20 @@ package disk
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"hash/crc64"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/golang/snappy"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/storage"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
@@ -18,11 +24,18 @@ import (
 	"go.etcd.io/etcd/server/v3/wal/walpb"
 )
 
-var crcTable = crc64.MakeTable(crc64.ECMA)
+// chunkSize is the size of the snapshot data chunks checksummed separately.
+const chunkSize = 1 << 20
+
+var (
+	crcTable   = crc64.MakeTable(crc64.ECMA)
+	chunkTable = crc32.MakeTable(crc32.Castagnoli)
+)
 
 var (
 	errSnapshotFormat = errors.New("raft/storage: invalid snapshot file format")
-	errCRCMismatch    = errors.New("raft/storage: snapshot file corrupted, crc mismatch")
+	errCRCMismatch    = fmt.Errorf("%w, crc mismatch", storage.ErrSnapshotCorrupted)
+	errSHAMismatch    = fmt.Errorf("%w, sha256 mismatch", storage.ErrSnapshotCorrupted)
 	errNoSnapshot     = errors.New("raft/storage: no available snapshot")
 )
 
@@ -30,10 +43,10 @@ func snapshotName(term, index uint64) string {
 	return fmt.Sprintf(format, term, index) + snapExt
 }
 
-func decodeNewestAvailableSnapshot(dir string, snaps []walpb.Snapshot) (*storage.Snapshot, error) {
+func decodeNewestAvailableSnapshot(ctx context.Context, store snapshotStore, snaps []walpb.Snapshot) (*storage.Snapshot, error) {
 	files := map[string]struct{}{}
 	target := ""
-	ls, err := list(dir, snapExt)
+	ls, err := store.list()
 	if err != nil {
 		return nil, err
 	}
@@ -54,11 +67,12 @@ func decodeNewestAvailableSnapshot(dir string, snaps []walpb.Snapshot) (*storage
 		return nil, errNoSnapshot
 	}
 
-	return decodeSnapshot(filepath.Join(dir, target))
+	return decodeSnapshot(ctx, store, target)
 }
 
 func peekSnapshot(path string) (etcdraftpb.Snapshot, error) {
-	sf, err := decodeSnapshot(path)
+	store := localStore(filepath.Dir(path))
+	sf, err := decodeSnapshot(context.TODO(), store, filepath.Base(path))
 	if err != nil {
 		return etcdraftpb.Snapshot{}, err
 	}
@@ -68,50 +82,49 @@ func peekSnapshot(path string) (etcdraftpb.Snapshot, error) {
 	return sf.Raw, nil
 }
 
-func encodeSnapshot(path string, s *storage.Snapshot) error {
-	pathtmp := path + ".tmp"
+func encodeSnapshot(store snapshotStore, name string, s *storage.Snapshot, c raftpb.Compression) error {
+	return store.create(name, func(w io.Writer) error {
+		return writeSnapshot(w, s, c)
+	})
+}
 
-	f, err := os.Create(pathtmp)
-	if err != nil {
-		return err
+// writeSnapshot writes the snapshot data compressed using the given compression,
+// followed by the snapshot state and its size.
+func writeSnapshot(w io.Writer, s *storage.Snapshot, c raftpb.Compression) error {
+	d := newDigest()
+	mw := io.MultiWriter(d, w)
+	dst := mw
+
+	var sw *snappy.Writer
+	if c == raftpb.SnappyCompression {
+		sw = snappy.NewBufferedWriter(mw)
+		dst = sw
 	}
 
-	fw := writer{
-		bufio.NewWriter(f),
-		f,
+	_, err := io.Copy(dst, s.Data)
+	if err != nil {
+		return err
 	}
-	crc := crc64.New(crcTable)
-	w := io.MultiWriter(crc, fw)
-
-	defer func() {
-		if err != nil {
-			_ = f.Close()
-			_ = os.Remove(pathtmp)
-			return
-		}
 
-		err = fw.Close()
-		if err != nil {
-			return
+	if sw != nil {
+		if err := sw.Close(); err != nil {
+			return err
 		}
-
-		err = os.Rename(pathtmp, path)
-	}()
-
-	_, err = io.Copy(w, s.Data)
-	if err != nil {
-		return err
 	}
 
-	s.CRC = crc.Sum(nil)
+	s.CRC, s.SHA256, s.ChunkCRCs = d.sums()
 	s.Version = raftpb.V0
+	s.Compression = c
+	if s.CreatedAt == 0 {
+		s.CreatedAt = time.Now().UnixNano()
+	}
 
 	buf, err := s.Marshal()
 	if err != nil {
 		return err
 	}
 
-	_, err = fw.Write(buf)
+	_, err = w.Write(buf)
 	if err != nil {
 		return err
 	}
@@ -120,71 +133,138 @@ func encodeSnapshot(path string, s *storage.Snapshot) error {
 	bsize := make([]byte, 8)
 	binary.BigEndian.PutUint64(bsize, tsize)
 
-	_, err = fw.Write(bsize)
+	_, err = w.Write(bsize)
 	return err
 }
 
-func decodeSnapshot(path string) (*storage.Snapshot, error) {
-	f, err := os.Open(path)
+// transcodeSnapshot returns a reader of the snapshot file encoded using the given compression,
+// the file read as is when it is already encoded using the given compression.
+func transcodeSnapshot(ctx context.Context, store snapshotStore, name string, c raftpb.Compression) (io.ReadCloser, error) {
+	f, err := store.open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	state, _, err := readSnapshotState(f)
 	if err != nil {
+		_ = f.Close()
 		return nil, err
 	}
 
-	stat, err := f.Stat()
+	if state.Compression == c {
+		fr, err := f.reader(0, f.Size())
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		r := struct {
+			io.Reader
+			io.Closer
+		}{
+			ctxReader{ctx, fr},
+			closers{fr, f},
+		}
+		return r, nil
+	}
+
+	_ = f.Close()
+
+	sf, err := decodeSnapshot(ctx, store, name)
 	if err != nil {
 		return nil, err
 	}
 
+	pr, pw := io.Pipe()
+	go func() {
+		defer sf.Data.Close()
+		pw.CloseWithError(writeSnapshot(pw, sf, c))
+	}()
+
+	return pr, nil
+}
+
+// readSnapshotState reads the snapshot state from the end of the given file,
+// and returns the state alongside the end of the snapshot data offset.
+func readSnapshotState(f snapshotFile) (*raftpb.SnapshotState, int64, error) {
 	bsize := make([]byte, 8)
-	_, err = f.ReadAt(bsize, stat.Size()-8)
+	_, err := f.ReadAt(bsize, f.Size()-8)
 	if err == io.EOF {
-		return nil, errSnapshotFormat
+		return nil, 0, errSnapshotFormat
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	size := binary.BigEndian.Uint64(bsize)
-	eod := stat.Size() - int64(size+8)
+	if size > uint64(f.Size()-8) {
+		return nil, 0, errSnapshotFormat
+	}
+
+	eod := f.Size() - int64(size+8)
 	buf := make([]byte, size)
 	_, err = f.ReadAt(buf, eod)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	state := new(raftpb.SnapshotState)
 	if err := state.Unmarshal(buf); err != nil {
+		return nil, 0, err
+	}
+
+	return state, eod, nil
+}
+
+func decodeSnapshot(ctx context.Context, store snapshotStore, name string) (*storage.Snapshot, error) {
+	f, err := store.open(name)
+	if err != nil {
 		return nil, err
 	}
 
-	crc := crc64.New(crcTable)
-	br := bufio.NewReader(f)
-	lr := &io.LimitedReader{
-		R: br,
-		N: eod,
+	state, eod, err := readSnapshotState(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
 	}
 
-	_, err = io.Copy(crc, lr)
+	fr, err := f.reader(0, eod)
 	if err != nil {
+		_ = f.Close()
 		return nil, err
 	}
 
-	if !bytes.Equal(state.CRC, crc.Sum(nil)) {
-		return nil, errCRCMismatch
+	d := newDigest()
+	_, err = io.Copy(d, ctxReader{ctx, fr})
+	_ = fr.Close()
+	if err == nil {
+		err = d.verify(state)
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return nil, err
 	}
 
-	// Reset file offset to read snap data again.
-	_, _ = f.Seek(0, 0)
-	br.Reset(f)
-	lr.N = eod
+	// read snap data again.
+	fr, err = f.reader(0, eod)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	var r io.Reader = ctxReader{ctx, fr}
+	if state.Compression == raftpb.SnappyCompression {
+		r = snappy.NewReader(r)
+	}
 
 	data := struct {
 		io.Reader
 		io.Closer
 	}{
-		lr,
-		f,
+		r,
+		closers{fr, f},
 	}
 
 	s := new(storage.Snapshot)
@@ -194,6 +274,93 @@ func decodeSnapshot(path string) (*storage.Snapshot, error) {
 	return s, nil
 }
 
+// closers closes all the given closers, and returns the first error.
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var err error
+	for _, c := range cs {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// digest computes the snapshot data checksums.
+type digest struct {
+	crc    hash.Hash64
+	sha    hash.Hash
+	chunk  hash.Hash32
+	n      int
+	chunks []uint32
+}
+
+func newDigest() *digest {
+	return &digest{
+		crc:   crc64.New(crcTable),
+		sha:   sha256.New(),
+		chunk: crc32.New(chunkTable),
+	}
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	_, _ = d.crc.Write(p)
+	_, _ = d.sha.Write(p)
+
+	for buf := p; len(buf) > 0; {
+		n := chunkSize - d.n
+		if n > len(buf) {
+			n = len(buf)
+		}
+
+		_, _ = d.chunk.Write(buf[:n])
+		d.n += n
+		buf = buf[n:]
+
+		if d.n == chunkSize {
+			d.chunks = append(d.chunks, d.chunk.Sum32())
+			d.chunk.Reset()
+			d.n = 0
+		}
+	}
+
+	return len(p), nil
+}
+
+// sums returns the data crc64, sha256, and chunks crc32 sums.
+func (d *digest) sums() ([]byte, []byte, []uint32) {
+	chunks := d.chunks
+	if d.n > 0 {
+		chunks = append(chunks, d.chunk.Sum32())
+	}
+	return d.crc.Sum(nil), d.sha.Sum(nil), chunks
+}
+
+// verify the data checksums against the given snapshot state,
+// snapshot files written before sha256 and chunks sums only verified by the crc64 sum.
+func (d *digest) verify(state *raftpb.SnapshotState) error {
+	crc, sha, chunks := d.sums()
+
+	if len(state.ChunkCRCs) > 0 {
+		for i, sum := range chunks {
+			if i >= len(state.ChunkCRCs) || state.ChunkCRCs[i] != sum {
+				return fmt.Errorf("%w at offset %d", errCRCMismatch, int64(i)*chunkSize)
+			}
+		}
+	}
+
+	if !bytes.Equal(state.CRC, crc) {
+		return errCRCMismatch
+	}
+
+	if len(state.SHA256) > 0 && !bytes.Equal(state.SHA256, sha) {
+		return errSHAMismatch
+	}
+
+	return nil
+}
+
 type writer struct {
 	*bufio.Writer
 	*os.File
@@ -1,6 +1,8 @@
 package disk
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -17,21 +19,90 @@ import (
 
 func TestSnapshotCodec(t *testing.T) {
 	dir := createTestDir("read-write", t)
-	path := filepath.Join(dir, t.Name())
 	defer os.RemoveAll(dir)
 
-	expected, expectedData := snapshotTestFile()
-	err := encodeSnapshot(path, &expected)
-	require.NoError(t, err)
+	for _, c := range []raftpb.Compression{raftpb.NoCompression, raftpb.SnappyCompression} {
+		t.Run(c.String(), func(t *testing.T) {
+			expected, expectedData := snapshotTestFile()
+			err := encodeSnapshot(localStore(dir), c.String(), &expected, c)
+			require.NoError(t, err)
+
+			got, err := decodeSnapshot(context.TODO(), localStore(dir), c.String())
+			require.NoError(t, err)
+			require.Equal(t, expected.Raw, got.Raw)
+			require.Equal(t, expected.Members, got.Members)
+			require.Equal(t, c, got.Compression)
+
+			gotData, err := io.ReadAll(got.Data)
+			require.NoError(t, err)
+			require.Equal(t, expectedData, string(gotData))
+		})
+	}
+}
+
+func TestSnapshotChecksums(t *testing.T) {
+	dir := t.TempDir()
+	name := snapshotName(1, 1)
+	data := strings.Repeat("x", chunkSize+10)
 
-	got, err := decodeSnapshot(path)
+	sf, _ := snapshotTestFile()
+	sf.Data = io.NopCloser(strings.NewReader(data))
+	require.NoError(t, encodeSnapshot(localStore(dir), name, &sf, raftpb.NoCompression))
+
+	f, err := localStore(dir).open(name)
+	require.NoError(t, err)
+	state, _, err := readSnapshotState(f)
 	require.NoError(t, err)
-	require.Equal(t, expected.Raw, got.Raw)
-	require.Equal(t, expected.Members, got.Members)
+	require.NoError(t, f.Close())
+	require.Len(t, state.SHA256, 32)
+	require.Len(t, state.ChunkCRCs, 2)
+
+	// it return error when sha256 mismatch.
+	d := newDigest()
+	_, _ = d.Write([]byte(data))
+	state.SHA256[0]++
+	require.Equal(t, errSHAMismatch, d.verify(state))
+
+	// it return error with the corrupted chunk offset.
+	path := filepath.Join(dir, name)
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	buf[chunkSize+1] = 'y'
+	require.NoError(t, os.WriteFile(path, buf, 0600))
+
+	_, err = decodeSnapshot(context.TODO(), localStore(dir), name)
+	require.ErrorIs(t, err, storage.ErrSnapshotCorrupted)
+	require.Contains(t, err.Error(), fmt.Sprintf("offset %d", chunkSize))
+}
+
+func TestTranscodeSnapshot(t *testing.T) {
+	dir := createTestDir("transcode", t)
+	defer os.RemoveAll(dir)
 
-	gotData, err := io.ReadAll(got.Data)
+	sf, expectedData := snapshotTestFile()
+	err := encodeSnapshot(localStore(dir), snapshotName(1, 1), &sf, raftpb.SnappyCompression)
 	require.NoError(t, err)
-	require.Equal(t, expectedData, string(gotData))
+
+	for _, c := range []raftpb.Compression{raftpb.NoCompression, raftpb.SnappyCompression} {
+		t.Run(c.String(), func(t *testing.T) {
+			r, err := transcodeSnapshot(context.TODO(), localStore(dir), snapshotName(1, 1), c)
+			require.NoError(t, err)
+
+			dst := filepath.Join(dir, c.String())
+			buf, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			require.NoError(t, os.WriteFile(dst, buf, 0600))
+
+			got, err := decodeSnapshot(context.TODO(), localStore(dir), c.String())
+			require.NoError(t, err)
+			require.Equal(t, c, got.Compression)
+
+			gotData, err := io.ReadAll(got.Data)
+			require.NoError(t, err)
+			require.Equal(t, expectedData, string(gotData))
+		})
+	}
 }
 
 func TestPeekSnapshot(t *testing.T) {
@@ -60,7 +131,7 @@ func TestDecodeSnapErr(t *testing.T) {
 		},
 		{
 			name:     "it return error when snapshot empty",
-			file:     "./testdata/empty.snap",
+			file:     "empty.snap",
 			contains: "negative offset",
 		},
 		// {
@@ -70,14 +141,14 @@ func TestDecodeSnapErr(t *testing.T) {
 		// },
 		{
 			name:     "it return error when snapshot have invalid crc",
-			file:     "./testdata/crc.snap",
+			file:     "crc.snap",
 			contains: errCRCMismatch.Error(),
 		},
 	}
 
 	for _, tt := range table {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := decodeSnapshot(tt.file)
+			_, err := decodeSnapshot(context.TODO(), localStore("./testdata"), tt.file)
 			require.Contains(t, err.Error(), tt.contains)
 		})
 	}
@@ -85,18 +156,18 @@ func TestDecodeSnapErr(t *testing.T) {
 
 func TestDecodeNewestAvailableSnapshot(t *testing.T) {
 	// Round #1 it return error when snapshots dir does not exist
-	sf, err := decodeNewestAvailableSnapshot("", []walpb.Snapshot{})
+	sf, err := decodeNewestAvailableSnapshot(context.TODO(), localStore(""), []walpb.Snapshot{})
 	require.Nil(t, sf)
 	require.Contains(t, err.Error(), "no such file or directory")
 
 	// Round #2 it return error when no snapshots
-	sf, err = decodeNewestAvailableSnapshot("./testdata/", []walpb.Snapshot{})
+	sf, err = decodeNewestAvailableSnapshot(context.TODO(), localStore("./testdata/"), []walpb.Snapshot{})
 	require.Nil(t, sf)
 	require.Equal(t, errNoSnapshot, err)
 
 	// Round #3 it return latest snapshots
 	expected, _ := snapshotTestFile()
-	sf, err = decodeNewestAvailableSnapshot("./testdata/", []walpb.Snapshot{{Index: 3, Term: 3}})
+	sf, err = decodeNewestAvailableSnapshot(context.TODO(), localStore("./testdata/"), []walpb.Snapshot{{Index: 3, Term: 3}})
 	require.NoError(t, err)
 	require.Equal(t, expected.Raw, sf.Raw)
 }
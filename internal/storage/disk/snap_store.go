@@ -0,0 +1,269 @@
+package disk
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shaj13/raft/internal/storage"
+)
+
+// snapshotFile represents a snapshot file opened for reading.
+type snapshotFile interface {
+	io.ReaderAt
+	io.Closer
+	// Size returns the snapshot file size.
+	Size() int64
+	// reader returns a reader of n bytes starting at the given offset.
+	reader(off, n int64) (io.ReadCloser, error)
+}
+
+// snapshotStore stores the snapshot files,
+// either in the local snapshots dir or in an object store bucket.
+type snapshotStore interface {
+	// open the given snapshot file for reading.
+	open(name string) (snapshotFile, error)
+	// create the given snapshot file from the data written by fn,
+	// the snapshot file only created when fn succeed.
+	create(name string, fn func(io.Writer) error) error
+	// move the given local file into the store as the given snapshot file.
+	move(path, name string) error
+	// list returns the snapshot files names in descending order.
+	list() ([]string, error)
+	// remove the given snapshot file.
+	remove(name string) error
+	// size returns the given snapshot file size.
+	size(name string) (int64, error)
+}
+
+var (
+	_ snapshotStore = localStore("")
+	_ snapshotStore = objectStore{}
+)
+
+// copySnapshot copies the given snapshot file from the src store into the dst store.
+func copySnapshot(ctx context.Context, dst, src snapshotStore, name string) error {
+	f, err := src.open(name)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	r, err := f.reader(0, f.Size())
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	return dst.create(name, func(w io.Writer) error {
+		_, err := io.Copy(w, ctxReader{ctx, r})
+		return err
+	})
+}
+
+// localStore stores the snapshot files in a local dir.
+type localStore string
+
+func (s localStore) open(name string) (snapshotFile, error) {
+	f, err := os.Open(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return localFile{f, stat.Size()}, nil
+}
+
+func (s localStore) create(name string, fn func(io.Writer) error) (err error) {
+	path := s.path(name)
+	pathtmp := path + ".tmp"
+
+	f, err := os.Create(pathtmp)
+	if err != nil {
+		return err
+	}
+
+	fw := writer{
+		bufio.NewWriter(f),
+		f,
+	}
+
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(pathtmp)
+			return
+		}
+
+		err = fw.Close()
+		if err != nil {
+			return
+		}
+
+		err = os.Rename(pathtmp, path)
+	}()
+
+	return fn(fw)
+}
+
+func (s localStore) move(path, name string) error {
+	return os.Rename(path, s.path(name))
+}
+
+func (s localStore) list() ([]string, error) {
+	return list(string(s), snapExt)
+}
+
+func (s localStore) remove(name string) error {
+	return os.Remove(s.path(name))
+}
+
+func (s localStore) size(name string) (int64, error) {
+	stat, err := os.Stat(s.path(name))
+	if err != nil {
+		return 0, err
+	}
+	return stat.Size(), nil
+}
+
+func (s localStore) path(name string) string {
+	return filepath.Join(string(s), name)
+}
+
+// localFile is a snapshot file opened from a local dir.
+type localFile struct {
+	*os.File
+	size int64
+}
+
+func (f localFile) Size() int64 {
+	return f.size
+}
+
+func (f localFile) reader(off, n int64) (io.ReadCloser, error) {
+	r := bufio.NewReader(io.NewSectionReader(f.File, off, n))
+	return io.NopCloser(r), nil
+}
+
+// objectStore stores the snapshot files in an object store bucket.
+type objectStore struct {
+	ctx   context.Context
+	store storage.ObjectStore
+}
+
+func (s objectStore) open(name string) (snapshotFile, error) {
+	size, err := s.size(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return objectFile{s, name, size}, nil
+}
+
+func (s objectStore) create(name string, fn func(io.Writer) error) error {
+	pr, pw := io.Pipe()
+	errc := make(chan error, 1)
+
+	go func() {
+		err := s.store.Put(s.ctx, name, pr)
+		// unblock the writer when the upload fails.
+		pr.CloseWithError(err)
+		errc <- err
+	}()
+
+	werr := fn(pw)
+	// abort the upload when fn fails.
+	pw.CloseWithError(werr)
+
+	if err := <-errc; err != nil {
+		return err
+	}
+
+	return werr
+}
+
+func (s objectStore) move(path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	if err := s.store.Put(s.ctx, name, bufio.NewReader(f)); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func (s objectStore) list() ([]string, error) {
+	keys, err := s.store.List(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, k := range keys {
+		if strings.HasSuffix(k, snapExt) {
+			files = append(files, k)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+func (s objectStore) remove(name string) error {
+	return s.store.Delete(s.ctx, name)
+}
+
+func (s objectStore) size(name string) (int64, error) {
+	return s.store.Size(s.ctx, name)
+}
+
+// objectFile is a snapshot file opened from an object store bucket.
+type objectFile struct {
+	objectStore
+	name   string
+	length int64
+}
+
+func (f objectFile) ReadAt(p []byte, off int64) (int, error) {
+	r, err := f.reader(off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+
+	defer r.Close()
+
+	n, err := io.ReadFull(r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+func (f objectFile) Close() error {
+	return nil
+}
+
+func (f objectFile) Size() int64 {
+	return f.length
+}
+
+func (f objectFile) reader(off, n int64) (io.ReadCloser, error) {
+	return f.store.Get(f.ctx, f.name, off, n)
+}
@@ -0,0 +1,174 @@
+package disk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+	"testing"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectStoreSnapshotter(t *testing.T) {
+	dir := t.TempDir()
+	mem := newMemObjectStore()
+	shotter := &snapshotter{
+		snapdir: dir,
+		store:   objectStore{context.TODO(), mem},
+	}
+
+	// it write and read snapshot from the bucket.
+	sf, data := snapshotTestFile()
+	require.NoError(t, shotter.Write(&sf))
+	require.Contains(t, mem.objects, snapshotName(1, 1))
+
+	got, err := shotter.Read(context.TODO(), 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, sf.Raw, got.Raw)
+	buf, err := io.ReadAll(got.Data)
+	require.NoError(t, err)
+	require.Equal(t, data, string(buf))
+
+	// it upload received snapshot on commit.
+	r, err := shotter.Reader(context.TODO(), 1, 1, raftpb.NoCompression)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	w, err := shotter.Writer(2, 2, 0)
+	require.NoError(t, err)
+	_, err = w.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, w.Commit())
+	require.Equal(t, raw, mem.objects[snapshotName(2, 2)])
+
+	files, err := list(dir, partExt)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	offset, err := shotter.Offset(2, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(raw)), offset)
+
+	// it abort the upload when writing the snapshot fails.
+	sf, _ = snapshotTestFile()
+	sf.Raw.Metadata.Index = 3
+	sf.Data = io.NopCloser(errReader{})
+	require.Error(t, shotter.Write(&sf))
+	require.NotContains(t, mem.objects, snapshotName(1, 3))
+}
+
+func TestSharedSnapshotStore(t *testing.T) {
+	mem := newMemObjectStore()
+	shared := objectStore{context.TODO(), mem}
+	leaderDir, followerDir := t.TempDir(), t.TempDir()
+	leader := &snapshotter{snapdir: leaderDir, store: localStore(leaderDir), shared: shared}
+	follower := &snapshotter{snapdir: followerDir, store: localStore(followerDir), shared: shared}
+
+	for i := uint64(1); i <= 2; i++ {
+		sf, _ := snapshotTestFile()
+		sf.Raw.Metadata.Index = i
+		// snapshot 2 chained onto snapshot 1.
+		if i == 2 {
+			sf.BaseTerm = 1
+			sf.BaseIndex = 1
+		}
+		require.NoError(t, leader.Write(&sf))
+	}
+
+	// it publish the snapshot chain to the shared store.
+	key, err := leader.Publish(context.TODO(), 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, snapshotName(1, 2), key)
+	require.Len(t, mem.objects, 2)
+
+	// it fetch the snapshot chain from the shared store.
+	require.NoError(t, follower.Fetch(context.TODO(), key))
+	require.NoError(t, follower.Verify(context.TODO(), 1, 2))
+
+	// it return error when the key is not a snapshot file.
+	err = follower.Fetch(context.TODO(), key+"/../x")
+	require.Error(t, err)
+
+	// it return empty key when no shared store configured.
+	leader.shared = nil
+	key, err = leader.Publish(context.TODO(), 1, 2)
+	require.NoError(t, err)
+	require.Empty(t, key)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func newMemObjectStore() *memObjectStore {
+	return &memObjectStore{objects: make(map[string][]byte)}
+}
+
+// memObjectStore is an in memory object store.
+type memObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memObjectStore) Put(ctx context.Context, key string, r io.Reader) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = buf
+	return nil
+}
+
+func (m *memObjectStore) Get(ctx context.Context, key string, off, n int64) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.objects[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+
+	r := io.NewSectionReader(bytes.NewReader(buf), off, n)
+	return io.NopCloser(r), nil
+}
+
+func (m *memObjectStore) Size(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.objects[key]
+	if !ok {
+		return 0, fs.ErrNotExist
+	}
+
+	return int64(len(buf)), nil
+}
+
+func (m *memObjectStore) List(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := []string{}
+	for k := range m.objects {
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+func (m *memObjectStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
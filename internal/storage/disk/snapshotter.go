@@ -2,67 +2,354 @@ package disk
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/storage"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 )
 
 var _ storage.Snapshotter = &snapshotter{}
 
+// snapshotter reads and writes the snapshot files from the snapshot store,
+// partially received snapshot files kept in the local snapshots dir.
 type snapshotter struct {
 	snapdir string
+	store   snapshotStore
+	// shared is the snapshot store shared by the cluster members,
+	// to install snapshots out of band, nil when not configured.
+	shared      snapshotStore
+	compression raftpb.Compression
 }
 
-func (s snapshotter) Reader(term uint64, index uint64) (io.ReadCloser, error) {
-	path := s.path(term, index)
-	f, err := os.Open(path)
+// ReadSnapshot reads the snapshot file of the given path,
+// the snapshot data checksums verified before returning the snapshot.
+func ReadSnapshot(ctx context.Context, path string) (*storage.Snapshot, error) {
+	return snapshotter{}.ReadFrom(ctx, path)
+}
+
+// WriteSnapshot writes the given snapshot file into the given writer, using the given compression,
+// the snapshot data checksums computed while writing.
+func WriteSnapshot(w io.Writer, sf *storage.Snapshot, c raftpb.Compression) error {
+	return writeSnapshot(w, sf, c)
+}
+
+// snapshotCompression returns the compression used to write snapshots.
+func snapshotCompression(compress bool) raftpb.Compression {
+	if compress {
+		return raftpb.SnappyCompression
+	}
+	return raftpb.NoCompression
+}
+
+// Reader returns a reader of the snapshot file encoded using the given compression,
+// the snapshot transcoded on the fly when the file stored using a different compression.
+func (s snapshotter) Reader(ctx context.Context, term, index uint64, c raftpb.Compression) (io.ReadCloser, error) {
+	return transcodeSnapshot(ctx, s.store, snapshotName(term, index), c)
+}
+
+// Writer returns a writer that resumes the partially received snapshot file
+// from the given offset, any other partial snapshot files removed.
+// the received data discarded when the snapshot file already exist.
+func (s snapshotter) Writer(term, index, offset uint64) (storage.SnapshotWriter, error) {
+	name := snapshotName(term, index)
+	part := s.path(term, index) + partExt
+
+	if _, err := s.store.size(name); err == nil {
+		return discardWriter{}, nil
+	}
+
+	if err := s.removeParts(part); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(part, os.O_WRONLY|os.O_CREATE, fileutil.PrivateFileMode)
 	if err != nil {
 		return nil, err
 	}
 
-	r := struct {
-		io.Reader
-		io.Closer
-	}{
-		bufio.NewReader(f),
-		f,
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
 	}
 
-	return r, nil
-}
+	if uint64(stat.Size()) < offset {
+		_ = f.Close()
+		return nil, fmt.Errorf(
+			"raft/storage: snapshot offset %d exceeds received bytes %d",
+			offset,
+			stat.Size(),
+		)
+	}
 
-func (s snapshotter) Writer(term uint64, index uint64) (io.WriteCloser, error) {
-	path := s.path(term, index)
-	f, err := os.Create(path)
-	if err != nil {
+	if err := f.Truncate(int64(offset)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		_ = f.Close()
 		return nil, err
 	}
 
-	w := writer{
-		bufio.NewWriter(f),
-		f,
+	w := &partWriter{
+		writer: writer{bufio.NewWriter(f), f},
+		store:  s.store,
+		name:   name,
+		path:   part,
 	}
 
 	return w, nil
 }
 
+// Offset returns the number of bytes already received
+// of the given snapshot file.
+func (s snapshotter) Offset(term, index uint64) (uint64, error) {
+	if size, err := s.store.size(snapshotName(term, index)); err == nil {
+		return uint64(size), nil
+	}
+
+	stat, err := os.Stat(s.path(term, index) + partExt)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(stat.Size()), nil
+}
+
+func (s snapshotter) removeParts(except string) error {
+	files, err := list(s.snapdir, partExt)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		path := filepath.Join(s.snapdir, f)
+		if path == except {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s snapshotter) Write(sf *storage.Snapshot) error {
-	path := s.path(sf.Raw.Metadata.Term, sf.Raw.Metadata.Index)
-	return encodeSnapshot(path, sf)
+	name := snapshotName(sf.Raw.Metadata.Term, sf.Raw.Metadata.Index)
+	return encodeSnapshot(s.store, name, sf, s.compression)
+}
+
+func (s snapshotter) Read(ctx context.Context, term uint64, index uint64) (*storage.Snapshot, error) {
+	return decodeSnapshot(ctx, s.store, snapshotName(term, index))
+}
+
+func (s snapshotter) ReadFrom(ctx context.Context, path string) (*storage.Snapshot, error) {
+	store := localStore(filepath.Dir(path))
+	return decodeSnapshot(ctx, store, filepath.Base(path))
+}
+
+// Chain returns the snapshots the given snapshot chained onto,
+// ordered from the full base snapshot up to the given snapshot.
+func (s snapshotter) Chain(term, index uint64) ([]etcdraftpb.SnapshotMetadata, error) {
+	chain := []etcdraftpb.SnapshotMetadata{}
+	for {
+		chain = append(chain, etcdraftpb.SnapshotMetadata{Term: term, Index: index})
+
+		state, err := s.state(term, index)
+		if err != nil {
+			return nil, fmt.Errorf("raft/storage: snapshot %s chain: %w", snapshotName(term, index), err)
+		}
+
+		if state.BaseIndex == 0 {
+			break
+		}
+
+		if state.BaseIndex >= index {
+			return nil, fmt.Errorf(
+				"raft/storage: snapshot %s base index %d is not behind the snapshot",
+				snapshotName(term, index),
+				state.BaseIndex,
+			)
+		}
+
+		term, index = state.BaseTerm, state.BaseIndex
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	return chain, nil
+}
+
+// Verify the checksums of the given snapshot and the snapshots it chained onto,
+// corrupted snapshot files removed so they can be received again.
+func (s snapshotter) Verify(ctx context.Context, term, index uint64) error {
+	chain, err := s.Chain(term, index)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range chain {
+		name := snapshotName(m.Term, m.Index)
+		sf, err := decodeSnapshot(ctx, s.store, name)
+		if err == nil {
+			err = sf.Data.Close()
+		}
+
+		if errors.Is(err, storage.ErrSnapshotCorrupted) || errors.Is(err, errSnapshotFormat) {
+			_ = s.store.remove(name)
+		}
+
+		if err != nil {
+			return fmt.Errorf("raft/storage: verify snapshot %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Publish the given snapshot and the snapshots it chained onto to the shared snapshot store,
+// it returns the shared store key of the snapshot, or an empty key when no shared store configured.
+func (s snapshotter) Publish(ctx context.Context, term, index uint64) (string, error) {
+	if s.shared == nil {
+		return "", nil
+	}
+
+	chain, err := s.Chain(term, index)
+	if err != nil {
+		return "", err
+	}
+
+	for _, m := range chain {
+		name := snapshotName(m.Term, m.Index)
+		// already published, e.g. sent to another member.
+		_, err := s.shared.size(name)
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		if err := copySnapshot(ctx, s.shared, s.store, name); err != nil {
+			return "", fmt.Errorf("raft/storage: publish snapshot %s: %w", name, err)
+		}
+	}
+
+	return snapshotName(term, index), nil
 }
 
-func (s snapshotter) Read(term uint64, index uint64) (*storage.Snapshot, error) {
-	path := s.path(term, index)
-	return decodeSnapshot(path)
+// Fetch the snapshot of the given key and the snapshots it chained onto
+// from the shared snapshot store.
+func (s snapshotter) Fetch(ctx context.Context, key string) error {
+	if s.shared == nil {
+		return errors.New("raft/storage: fetch snapshot, no shared snapshot store")
+	}
+
+	for name := key; ; {
+		var term, index uint64
+		_, err := fmt.Sscanf(name, format+snapExt, &term, &index)
+		if err != nil || name != snapshotName(term, index) {
+			return fmt.Errorf("raft/storage: fetch snapshot, invalid key %q", name)
+		}
+
+		// the snapshot already exist, e.g. a base snapshot of a previous delta.
+		if _, err := s.store.size(name); err != nil {
+			if err := copySnapshot(ctx, s.store, s.shared, name); err != nil {
+				return fmt.Errorf("raft/storage: fetch snapshot %s: %w", name, err)
+			}
+		}
+
+		state, err := s.state(term, index)
+		if err != nil {
+			return err
+		}
+
+		if state.BaseIndex == 0 {
+			return nil
+		}
+
+		name = snapshotName(state.BaseTerm, state.BaseIndex)
+	}
 }
 
-func (s snapshotter) ReadFrom(path string) (*storage.Snapshot, error) {
-	return decodeSnapshot(path)
+func (s snapshotter) state(term, index uint64) (*raftpb.SnapshotState, error) {
+	f, err := s.store.open(snapshotName(term, index))
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	state, _, err := readSnapshotState(f)
+	return state, err
 }
 
 func (s snapshotter) path(term uint64, index uint64) string {
 	name := snapshotName(term, index)
 	return filepath.Join(s.snapdir, name)
 }
+
+// ctxReader stops reading once the context is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// partWriter writes into a partial snapshot file,
+// moved into the snapshot store on commit.
+type partWriter struct {
+	writer
+	store  snapshotStore
+	name   string
+	path   string
+	closed bool
+}
+
+func (w *partWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	return w.writer.Close()
+}
+
+func (w *partWriter) Commit() error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return w.store.move(w.path, w.name)
+}
+
+// discardWriter discards the data of an already received snapshot file.
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (discardWriter) Close() error                { return nil }
+func (discardWriter) Commit() error               { return nil }
@@ -1,10 +1,15 @@
 package disk
 
 import (
+	"context"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,11 +19,11 @@ func TestSnapshotterReaderWriter(t *testing.T) {
 	noFileDir := "no such file or directory"
 
 	callReader := func(s *snapshotter) error {
-		_, err := s.Reader(1, 1)
+		_, err := s.Reader(context.TODO(), 3, 3, raftpb.NoCompression)
 		return err
 	}
 	callWriter := func(s *snapshotter) error {
-		_, err := s.Writer(0, 0)
+		_, err := s.Writer(0, 0, 0)
 		return err
 	}
 
@@ -52,6 +57,7 @@ func TestSnapshotterReaderWriter(t *testing.T) {
 	for i, tt := range table {
 		s := new(snapshotter)
 		s.snapdir = tt.path
+		s.store = localStore(tt.path)
 		err := tt.call(s)
 		got := nils
 		if err != nil {
@@ -72,15 +78,145 @@ func TestSnapshotterReadWrite(t *testing.T) {
 
 	shotter := new(snapshotter)
 	shotter.snapdir = dir
+	shotter.store = localStore(dir)
 
-	snap, err := shotter.Read(1, 1)
+	snap, err := shotter.Read(context.TODO(), 1, 1)
 	require.NoError(t, err)
 
 	err = shotter.Write(snap)
 	require.NoError(t, err)
 
-	snap, err = shotter.ReadFrom(path)
+	snap, err = shotter.ReadFrom(context.TODO(), path)
 	require.NoError(t, err)
 	buf, _ = io.ReadAll(snap.Data)
 	require.Equal(t, "some app data", string(buf))
 }
+
+func TestSnapshotterResumeWriter(t *testing.T) {
+	dir := t.TempDir()
+	shotter := new(snapshotter)
+	shotter.snapdir = dir
+	shotter.store = localStore(dir)
+
+	// stale partial snapshot removed.
+	stale := filepath.Join(dir, snapshotName(1, 1)+partExt)
+	createTestFiles(dir, []string{snapshotName(1, 1) + partExt}, t)
+
+	w, err := shotter.Writer(2, 2, 0)
+	require.NoError(t, err)
+	require.NoFileExists(t, stale)
+
+	_, err = w.Write([]byte("some"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// it return received bytes.
+	offset, err := shotter.Offset(2, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), offset)
+
+	// it return error when offset exceeds received bytes.
+	_, err = shotter.Writer(2, 2, 10)
+	require.Contains(t, err.Error(), "exceeds received bytes")
+
+	// it resume from offset.
+	w, err = shotter.Writer(2, 2, 2)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("me data"))
+	require.NoError(t, err)
+	require.NoError(t, w.Commit())
+	require.NoError(t, w.Close())
+
+	buf, err := os.ReadFile(filepath.Join(dir, snapshotName(2, 2)))
+	require.NoError(t, err)
+	require.Equal(t, "some data", string(buf))
+
+	// it return the snapshot file size when already received.
+	offset, err = shotter.Offset(2, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), offset)
+
+	// it discard data of already received snapshot file.
+	w, err = shotter.Writer(2, 2, offset)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("other"))
+	require.NoError(t, err)
+	require.NoError(t, w.Commit())
+
+	buf, err = os.ReadFile(filepath.Join(dir, snapshotName(2, 2)))
+	require.NoError(t, err)
+	require.Equal(t, "some data", string(buf))
+}
+
+func TestSnapshotterChain(t *testing.T) {
+	dir := t.TempDir()
+	shotter := new(snapshotter)
+	shotter.snapdir = dir
+	shotter.store = localStore(dir)
+
+	write := func(index, base uint64) {
+		sf, _ := snapshotTestFile()
+		sf.Raw.Metadata.Term = 1
+		sf.Raw.Metadata.Index = index
+		if base > 0 {
+			sf.BaseTerm = 1
+			sf.BaseIndex = base
+		}
+		require.NoError(t, shotter.Write(&sf))
+	}
+
+	write(1, 0)
+	write(2, 1)
+	write(3, 2)
+
+	chain, err := shotter.Chain(1, 3)
+	require.NoError(t, err)
+	require.Len(t, chain, 3)
+	for i, m := range chain {
+		require.Equal(t, uint64(i+1), m.Index)
+	}
+
+	chain, err = shotter.Chain(1, 1)
+	require.NoError(t, err)
+	require.Len(t, chain, 1)
+
+	// it return error when a base snapshot missing.
+	require.NoError(t, os.Remove(filepath.Join(dir, snapshotName(1, 1))))
+	_, err = shotter.Chain(1, 3)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSnapshotterVerify(t *testing.T) {
+	dir := t.TempDir()
+	shotter := new(snapshotter)
+	shotter.snapdir = dir
+	shotter.store = localStore(dir)
+
+	sf, _ := snapshotTestFile()
+	require.NoError(t, shotter.Write(&sf))
+	require.NoError(t, shotter.Verify(context.TODO(), 1, 1))
+
+	// it remove corrupted snapshot file.
+	path := filepath.Join(dir, snapshotName(1, 1))
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	buf[0]++
+	require.NoError(t, os.WriteFile(path, buf, 0600))
+
+	err = shotter.Verify(context.TODO(), 1, 1)
+	require.ErrorIs(t, err, storage.ErrSnapshotCorrupted)
+	require.NoFileExists(t, path)
+}
+
+func TestCtxReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := ctxReader{ctx, strings.NewReader("data")}
+	buf := make([]byte, 2)
+
+	_, err := r.Read(buf)
+	require.NoError(t, err)
+
+	cancel()
+	_, err = r.Read(buf)
+	require.Equal(t, context.Canceled, err)
+}
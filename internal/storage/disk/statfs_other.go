@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package disk
+
+import "math"
+
+// available reports unlimited space, as the platform does not support statfs.
+func available(path string) (uint64, error) {
+	return math.MaxUint64, nil
+}
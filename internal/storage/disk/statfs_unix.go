@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd
+
+package disk
+
+import "syscall"
+
+// available returns the available bytes on the filesystem containing the given path.
+func available(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil //nolint:unconvert
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package disk
+
+import "golang.org/x/sys/windows"
+
+// available returns the available bytes on the volume containing the given path.
+func available(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, nil, nil); err != nil {
+		return 0, err
+	}
+
+	return free, nil
+}
@@ -0,0 +1,69 @@
+package disk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shaj13/raft/internal/storage"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/wal"
+	"go.etcd.io/etcd/server/v3/wal/walpb"
+	"go.uber.org/zap"
+)
+
+// verify performs a full scan of the WAL segments since the given snapshot,
+// validating records checksums, entries indices continuity, terms ordering,
+// and the hard state against the entries.
+// The entries indices continuity validated by the WAL ReadAll, which fails on a gap.
+func verify(lg *zap.Logger, waldir string, snap walpb.Snapshot) (*storage.Verification, error) {
+	fail := func(idx uint64, err error) (*storage.Verification, error) {
+		return nil, &storage.VerificationError{Index: idx, Err: err}
+	}
+
+	// validate records checksums and snapshot records.
+	if _, err := wal.Verify(lg, waldir, snap); err != nil {
+		return fail(0, err)
+	}
+
+	w, err := wal.OpenForRead(lg, waldir, snap)
+	if err != nil {
+		return fail(0, err)
+	}
+
+	defer w.Close()
+
+	_, st, ents, err := w.ReadAll()
+	if err != nil {
+		return fail(0, err)
+	}
+
+	v := &storage.Verification{
+		Entries:   len(ents),
+		HardState: st,
+		LastIndex: snap.Index,
+	}
+
+	prev := raftpb.Entry{Index: snap.Index, Term: snap.Term}
+	for _, ent := range ents {
+		if ent.Term < prev.Term {
+			return fail(ent.Index, fmt.Errorf("term %d is behind previous term %d", ent.Term, prev.Term))
+		}
+
+		prev = ent
+	}
+
+	if len(ents) > 0 {
+		v.FirstIndex = ents[0].Index
+		v.LastIndex = prev.Index
+	}
+
+	if st.Commit > v.LastIndex {
+		return fail(st.Commit, errors.New("committed index is out of range of last index"))
+	}
+
+	if st.Term < prev.Term {
+		return fail(prev.Index, fmt.Errorf("hard state term %d is behind entry term %d", st.Term, prev.Term))
+	}
+
+	return v, nil
+}
@@ -0,0 +1,150 @@
+package disk
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaj13/raft/internal/storage"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/wal"
+	"go.etcd.io/etcd/server/v3/wal/walpb"
+)
+
+func TestVerify(t *testing.T) {
+	table := []struct {
+		name string
+		hs   raftpb.HardState
+		ents []raftpb.Entry
+		err  string
+	}{
+		{
+			name: "it return verification result",
+			hs:   raftpb.HardState{Term: 2, Commit: 3},
+			ents: []raftpb.Entry{
+				{Index: 1, Term: 1},
+				{Index: 2, Term: 1},
+				{Index: 3, Term: 2},
+			},
+		},
+		{
+			name: "it return error when terms out of order",
+			hs:   raftpb.HardState{Term: 2, Commit: 2},
+			ents: []raftpb.Entry{
+				{Index: 1, Term: 2},
+				{Index: 2, Term: 1},
+			},
+			err: "term 1 is behind previous term 2",
+		},
+		{
+			name: "it return error when commit out of range",
+			hs:   raftpb.HardState{Term: 1, Commit: 5},
+			ents: []raftpb.Entry{
+				{Index: 1, Term: 1},
+			},
+			err: "committed index is out of range",
+		},
+		{
+			name: "it return error when hard state term behind",
+			hs:   raftpb.HardState{Term: 1, Commit: 1},
+			ents: []raftpb.Entry{
+				{Index: 1, Term: 2},
+			},
+			err: "hard state term 1 is behind entry term 2",
+		},
+		{
+			name: "it return error when entries indices not continuous",
+			hs:   raftpb.HardState{Term: 1, Commit: 1},
+			ents: []raftpb.Entry{
+				{Index: 1, Term: 1},
+				{Index: 3, Term: 1},
+			},
+			err: wal.ErrSliceOutOfRange.Error(),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestDir("verify", t)
+			defer os.RemoveAll(dir)
+
+			w, err := wal.Create(nil, dir, nil)
+			require.NoError(t, err)
+			err = w.Save(tt.hs, tt.ents)
+			require.NoError(t, err)
+			w.Close()
+
+			v, err := verify(nil, dir, walpb.Snapshot{})
+			if len(tt.err) > 0 {
+				verr := new(storage.VerificationError)
+				require.True(t, errors.As(err, &verr))
+				require.Contains(t, err.Error(), tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, len(tt.ents), v.Entries)
+			require.Equal(t, uint64(1), v.FirstIndex)
+			require.Equal(t, uint64(3), v.LastIndex)
+			require.Equal(t, tt.hs, v.HardState)
+		})
+	}
+}
+
+func TestVerifyCorruptedSegment(t *testing.T) {
+	table := []struct {
+		name   string
+		mutate func(t *testing.T, path string, i int)
+		err    string
+	}{
+		{
+			name: "it return error when a record corrupted",
+			mutate: func(t *testing.T, path string, i int) {
+				f, err := os.OpenFile(path, os.O_WRONLY, 0600)
+				require.NoError(t, err)
+				defer f.Close()
+				_, err = f.WriteAt([]byte("V"), int64(i))
+				require.NoError(t, err)
+			},
+			err: walpb.ErrCRCMismatch.Error(),
+		},
+		{
+			name: "it return error when the segment truncated",
+			mutate: func(t *testing.T, path string, i int) {
+				require.NoError(t, os.Truncate(path, int64(i+4)))
+			},
+			err: "hard state term 0 is behind entry term 1",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestDir("verify", t)
+			defer os.RemoveAll(dir)
+
+			w, err := wal.Create(nil, dir, nil)
+			require.NoError(t, err)
+			err = w.Save(raftpb.HardState{Term: 1, Commit: 1}, []raftpb.Entry{
+				{Index: 1, Term: 1},
+				{Index: 2, Term: 1, Data: []byte("verify-data")},
+			})
+			require.NoError(t, err)
+			w.Close()
+
+			path := filepath.Join(dir, walName(0, 0))
+			buf, err := os.ReadFile(path)
+			require.NoError(t, err)
+			i := bytes.Index(buf, []byte("verify-data"))
+			require.NotEqual(t, -1, i)
+			tt.mutate(t, path, i)
+
+			_, err = verify(nil, dir, walpb.Snapshot{})
+			verr := new(storage.VerificationError)
+			require.True(t, errors.As(err, &verr))
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package disk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shaj13/raft/raftlog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newZapLogger returns a zap logger writing into the given logger,
+// it used by the etcd WAL package.
+// Fatal and Panic entries abort the WAL operation instead of exiting the process,
+// the abort recovered by recoverAbort at the WAL call sites.
+func newZapLogger(lg raftlog.Logger) *zap.Logger {
+	if lg == nil {
+		return zap.NewNop()
+	}
+	return zap.New(
+		&zapCore{lg: lg},
+		zap.WithFatalHook(abortHook{}),
+		zap.WithPanicHook(abortHook{}),
+	)
+}
+
+// walAbort is the panic value raised by abortHook.
+type walAbort string
+
+// abortHook implements zapcore.CheckWriteHook,
+// it panics with a walAbort once the entry written.
+type abortHook struct{}
+
+func (abortHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	panic(walAbort(ce.Message))
+}
+
+// recoverAbort recovers a WAL operation aborted by a Fatal or Panic entry,
+// and sets the abort into the given error. Other panics are propagated.
+func recoverAbort(err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	msg, ok := v.(walAbort)
+	if !ok {
+		panic(v)
+	}
+
+	*err = fmt.Errorf("raft/storage: WAL aborted: %s", string(msg))
+}
+
+// zapCore implements zapcore.Core and writes entries
+// into raftlog.Logger, Fatal and Panic entries written as errors.
+type zapCore struct {
+	lg     raftlog.Logger
+	fields []zapcore.Field
+}
+
+func (c *zapCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= zapcore.InfoLevel
+}
+
+func (c *zapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapCore{
+		lg:     c.lg,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *zapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *zapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range append(c.fields, fields...) {
+		f.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("raft.storage: ")
+	sb.WriteString(ent.Message)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, enc.Fields[k])
+	}
+
+	msg := sb.String()
+
+	switch {
+	case ent.Level <= zapcore.InfoLevel:
+		c.lg.Info(msg)
+	case ent.Level == zapcore.WarnLevel:
+		c.lg.Warning(msg)
+	default:
+		c.lg.Error(msg)
+	}
+
+	return nil
+}
+
+func (c *zapCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package disk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shaj13/raft/raftlog"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestZapLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	lg := newZapLogger(raftlog.New(0, "", buf))
+
+	lg.Debug("debug")
+	require.Empty(t, buf.String())
+
+	lg.With(zap.String("b", "2")).Warn("warn", zap.Int("a", 1))
+	require.Contains(t, buf.String(), "raft.storage: warn a=1 b=2")
+}
+
+func TestZapLoggerAbort(t *testing.T) {
+	buf := new(bytes.Buffer)
+	lg := newZapLogger(raftlog.New(0, "", buf))
+
+	abort := func(fn func(string, ...zap.Field)) (err error) {
+		defer recoverAbort(&err)
+		fn("abort", zap.Int("a", 1))
+		return nil
+	}
+
+	// it abort the operation instead of exiting the process.
+	require.EqualError(t, abort(lg.Fatal), "raft/storage: WAL aborted: abort")
+	require.EqualError(t, abort(lg.Panic), "raft/storage: WAL aborted: abort")
+	require.Contains(t, buf.String(), "raft.storage: abort a=1")
+
+	// it propagate the other panics.
+	require.PanicsWithValue(t, "other", func() {
+		var err error
+		defer recoverAbort(&err)
+		panic("other")
+	})
+}
@@ -0,0 +1,294 @@
+// Package memory implements an in-memory raft storage, for local development and tests.
+//
+// The raft state lost once the process exits, and the snapshot files can't be received
+// from other members, therefore it suits single member clusters only.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"go.etcd.io/etcd/raft/v3"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/storage"
+	"github.com/shaj13/raft/internal/storage/disk"
+)
+
+var (
+	_ storage.Storage     = &memory{}
+	_ storage.Snapshotter = &snapshotter{}
+)
+
+// errTransferUnsupported is returned when transferring the snapshot files between the members.
+var errTransferUnsupported = errors.New("raft/storage: snapshot files transfer not supported by the in-memory storage")
+
+// New return new in-memory storage.
+func New() storage.Storage {
+	return &memory{
+		shoter: &snapshotter{
+			snaps: make(map[snapshotKey]*snapshotFile),
+		},
+	}
+}
+
+// memory implements storage.Storage, guarded by mu.
+type memory struct {
+	mu     sync.Mutex
+	meta   []byte
+	booted bool
+	hs     etcdraftpb.HardState
+	snap   etcdraftpb.SnapshotMetadata
+	ents   []etcdraftpb.Entry
+	shoter *snapshotter
+}
+
+// SaveSnapshot saves a given snapshot metadata,
+// the entries covered by the snapshot released.
+func (m *memory) SaveSnapshot(snap etcdraftpb.Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if snap.Metadata.Index <= m.snap.Index {
+		return nil
+	}
+
+	m.snap = snap.Metadata
+
+	i := 0
+	for i < len(m.ents) && m.ents[i].Index <= snap.Metadata.Index {
+		i++
+	}
+
+	m.ents = append([]etcdraftpb.Entry(nil), m.ents[i:]...)
+	return nil
+}
+
+// SaveEntries saves a given hard state and entries,
+// the saved entries conflicting with the given entries truncated.
+func (m *memory) SaveEntries(st etcdraftpb.HardState, ents []etcdraftpb.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(ents) > 0 {
+		first := ents[0].Index
+		i := len(m.ents)
+		for i > 0 && m.ents[i-1].Index >= first {
+			i--
+		}
+
+		m.ents = append(m.ents[:i], ents...)
+	}
+
+	if !raft.IsEmptyHardState(st) {
+		m.hs = st
+	}
+
+	return nil
+}
+
+// Boot return the metadata, hard-state, entries, and newest snapshot saved by a previous boot,
+// Otherwise, it saves the given metadata.
+func (m *memory) Boot(ctx context.Context, meta []byte) ([]byte, etcdraftpb.HardState, []etcdraftpb.Entry, *storage.Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.booted {
+		m.booted = true
+		m.meta = meta
+		return meta, etcdraftpb.HardState{}, []etcdraftpb.Entry{}, nil, nil
+	}
+
+	sf := new(storage.Snapshot)
+	if m.snap.Index > 0 {
+		var err error
+		sf, err = m.shoter.Read(ctx, m.snap.Term, m.snap.Index)
+		if err != nil {
+			return []byte{}, etcdraftpb.HardState{}, []etcdraftpb.Entry{}, nil, err
+		}
+	}
+
+	ents := make([]etcdraftpb.Entry, len(m.ents))
+	copy(ents, m.ents)
+	return m.meta, m.hs, ents, sf, nil
+}
+
+func (m *memory) Exist() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.booted
+}
+
+// Available returns the max uint64, the in-memory storage never runs out of space.
+func (m *memory) Available() (uint64, error) {
+	return math.MaxUint64, nil
+}
+
+func (m *memory) Snapshotter() storage.Snapshotter {
+	return m.shoter
+}
+
+func (m *memory) Close() error {
+	return nil
+}
+
+type snapshotKey struct {
+	term, index uint64
+}
+
+// snapshotFile is an in-memory snapshot file.
+type snapshotFile struct {
+	state raftpb.SnapshotState
+	data  []byte
+}
+
+// snapshotter implements storage.Snapshotter, guarded by mu.
+// It retains the newest snapshot and the snapshots it chained onto.
+type snapshotter struct {
+	mu     sync.Mutex
+	snaps  map[snapshotKey]*snapshotFile
+	newest snapshotKey
+}
+
+func (s *snapshotter) Writer(term, index, offset uint64) (storage.SnapshotWriter, error) {
+	return nil, errTransferUnsupported
+}
+
+func (s *snapshotter) Offset(term, index uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.snaps[snapshotKey{term, index}]
+	if !ok {
+		return 0, nil
+	}
+
+	return uint64(len(f.data)), nil
+}
+
+// Reader returns a reader of the snapshot file encoded using the given compression,
+// in the disk snapshot file format, e.g. to export the snapshot.
+func (s *snapshotter) Reader(ctx context.Context, term, index uint64, c raftpb.Compression) (io.ReadCloser, error) {
+	sf, err := s.Read(ctx, term, index)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := disk.WriteSnapshot(buf, sf, c); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(buf), nil
+}
+
+func (s *snapshotter) Write(sf *storage.Snapshot) error {
+	data, err := io.ReadAll(sf.Data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta := sf.Raw.Metadata
+	key := snapshotKey{meta.Term, meta.Index}
+	s.snaps[key] = &snapshotFile{
+		state: sf.SnapshotState,
+		data:  data,
+	}
+
+	if meta.Index > s.newest.index {
+		s.newest = key
+	}
+
+	// release the snapshots the newest snapshot not chained onto.
+	chain, err := s.chain(s.newest.term, s.newest.index)
+	if err != nil {
+		return err
+	}
+
+	retain := make(map[snapshotKey]*snapshotFile, len(chain))
+	for _, m := range chain {
+		k := snapshotKey{m.Term, m.Index}
+		retain[k] = s.snaps[k]
+	}
+
+	s.snaps = retain
+	return nil
+}
+
+func (s *snapshotter) Read(_ context.Context, term, index uint64) (*storage.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.snaps[snapshotKey{term, index}]
+	if !ok {
+		return nil, fmt.Errorf("raft/storage: snapshot %016x-%016x not found", term, index)
+	}
+
+	return &storage.Snapshot{
+		SnapshotState: f.state,
+		Data:          io.NopCloser(bytes.NewReader(f.data)),
+	}, nil
+}
+
+func (s *snapshotter) ReadFrom(ctx context.Context, path string) (*storage.Snapshot, error) {
+	return disk.ReadSnapshot(ctx, path)
+}
+
+// Chain returns the snapshots the given snapshot chained onto,
+// ordered from the full base snapshot up to the given snapshot.
+func (s *snapshotter) Chain(term, index uint64) ([]etcdraftpb.SnapshotMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chain(term, index)
+}
+
+func (s *snapshotter) chain(term, index uint64) ([]etcdraftpb.SnapshotMetadata, error) {
+	chain := []etcdraftpb.SnapshotMetadata{}
+	for {
+		chain = append([]etcdraftpb.SnapshotMetadata{{Term: term, Index: index}}, chain...)
+
+		f, ok := s.snaps[snapshotKey{term, index}]
+		if !ok {
+			return nil, fmt.Errorf("raft/storage: snapshot %016x-%016x not found", term, index)
+		}
+
+		if f.state.BaseIndex == 0 {
+			return chain, nil
+		}
+
+		if f.state.BaseIndex >= index {
+			return nil, fmt.Errorf(
+				"raft/storage: snapshot %016x-%016x base index %d is not behind the snapshot",
+				term,
+				index,
+				f.state.BaseIndex,
+			)
+		}
+
+		term, index = f.state.BaseTerm, f.state.BaseIndex
+	}
+}
+
+// Verify reports whether the given snapshot and the snapshots it chained onto exist,
+// the in-memory snapshots never corrupted.
+func (s *snapshotter) Verify(_ context.Context, term, index uint64) error {
+	_, err := s.Chain(term, index)
+	return err
+}
+
+func (s *snapshotter) Publish(context.Context, uint64, uint64) (string, error) {
+	return "", errTransferUnsupported
+}
+
+func (s *snapshotter) Fetch(context.Context, string) error {
+	return errTransferUnsupported
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/storage"
+)
+
+func TestMemoryBoot(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	require.False(t, m.Exist())
+
+	// it should save the metadata on the first boot.
+	meta, hs, ents, sf, err := m.Boot(ctx, []byte("meta"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("meta"), meta)
+	require.Empty(t, hs)
+	require.Empty(t, ents)
+	require.Nil(t, sf)
+	require.True(t, m.Exist())
+
+	require.NoError(t, m.SaveEntries(etcdraftpb.HardState{Term: 1, Commit: 2}, []etcdraftpb.Entry{
+		{Term: 1, Index: 1},
+		{Term: 1, Index: 2},
+		{Term: 1, Index: 3},
+	}))
+
+	// it should truncate the conflicting entries.
+	require.NoError(t, m.SaveEntries(etcdraftpb.HardState{}, []etcdraftpb.Entry{
+		{Term: 2, Index: 3},
+	}))
+
+	snap := newSnapshot(1, 2, "data")
+	require.NoError(t, m.Snapshotter().Write(snap))
+	require.NoError(t, m.SaveSnapshot(snap.Raw))
+
+	// it should return the saved state on the next boot.
+	meta, hs, ents, sf, err = m.Boot(ctx, []byte("other"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("meta"), meta)
+	require.Equal(t, etcdraftpb.HardState{Term: 1, Commit: 2}, hs)
+	require.Equal(t, []etcdraftpb.Entry{{Term: 2, Index: 3}}, ents)
+	require.Equal(t, uint64(2), sf.Raw.Metadata.Index)
+
+	data, err := io.ReadAll(sf.Data)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+}
+
+func TestSnapshotterChain(t *testing.T) {
+	ctx := context.Background()
+	s := New().Snapshotter()
+
+	base := newSnapshot(1, 5, "base")
+	delta := newSnapshot(1, 10, "delta")
+	delta.BaseTerm, delta.BaseIndex = 1, 5
+
+	require.NoError(t, s.Write(newSnapshot(1, 1, "old")))
+	require.NoError(t, s.Write(base))
+	require.NoError(t, s.Write(delta))
+
+	chain, err := s.Chain(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []etcdraftpb.SnapshotMetadata{{Term: 1, Index: 5}, {Term: 1, Index: 10}}, chain)
+	require.NoError(t, s.Verify(ctx, 1, 10))
+
+	// it should release the snapshots the newest snapshot not chained onto.
+	_, err = s.Read(ctx, 1, 1)
+	require.Error(t, err)
+
+	n, err := s.Offset(1, 5)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), n)
+
+	r, err := s.Reader(ctx, 1, 5, raftpb.NoCompression)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	_, err = s.Writer(1, 5, 0)
+	require.ErrorIs(t, err, errTransferUnsupported)
+}
+
+func newSnapshot(term, index uint64, data string) *storage.Snapshot {
+	sf := &storage.Snapshot{
+		Data: io.NopCloser(strings.NewReader(data)),
+	}
+	sf.Raw.Metadata.Term = term
+	sf.Raw.Metadata.Index = index
+	return sf
+}
@@ -1,13 +1,35 @@
 package storage
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"time"
 
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/raftpb"
 )
 
+var (
+	// ErrSnapshotNotInWAL is returned by Boot when the WAL does not
+	// cover the newest snapshot, e.g. WAL segments were removed.
+	ErrSnapshotNotInWAL = fmt.Errorf("%w: WAL does not cover the newest snapshot", rafterrors.ErrCorrupted)
+	// ErrEntriesGap is returned by Boot when the WAL entries does not
+	// directly follow the newest snapshot.
+	ErrEntriesGap = fmt.Errorf("%w: gap between snapshot and WAL entries", rafterrors.ErrCorrupted)
+	// ErrCommitOutOfRange is returned by Boot when the hard state commit index
+	// is out of the range of the snapshot and WAL entries.
+	ErrCommitOutOfRange = fmt.Errorf("%w: hard state commit index out of range", rafterrors.ErrCorrupted)
+	// ErrSnapshotCorrupted is returned when the snapshot file data
+	// does not match its checksums.
+	ErrSnapshotCorrupted = fmt.Errorf("%w: snapshot file corrupted", rafterrors.ErrCorrupted)
+	// ErrWALCorrupted is returned when a WAL record data
+	// does not match its checksum.
+	ErrWALCorrupted = fmt.Errorf("%w: WAL record checksum mismatch", rafterrors.ErrCorrupted)
+)
+
 //go:generate mockgen -package storagemock -source types.go -destination ../mocks/storage/storage.go
 
 // Snapshot is the state of a system at a particular point in time.
@@ -16,13 +38,26 @@ type Snapshot struct {
 	Data io.ReadCloser
 }
 
+// SnapshotWriter writes a snapshot file that can be resumed when interrupted,
+// Close persist the written data, and Commit marks the snapshot file as complete.
+type SnapshotWriter interface {
+	io.WriteCloser
+	Commit() error
+}
+
 // Snapshotter define a set of functions to read and write snapshots.
+// Snapshot data readers stop reading once the given context is done.
 type Snapshotter interface {
-	Writer(uint64, uint64) (io.WriteCloser, error)
-	Reader(uint64, uint64) (io.ReadCloser, error)
+	Writer(term, index, offset uint64) (SnapshotWriter, error)
+	Offset(uint64, uint64) (uint64, error)
+	Reader(context.Context, uint64, uint64, raftpb.Compression) (io.ReadCloser, error)
 	Write(*Snapshot) error
-	Read(uint64, uint64) (*Snapshot, error)
-	ReadFrom(string) (*Snapshot, error)
+	Read(context.Context, uint64, uint64) (*Snapshot, error)
+	ReadFrom(context.Context, string) (*Snapshot, error)
+	Chain(term, index uint64) ([]etcdraftpb.SnapshotMetadata, error)
+	Verify(ctx context.Context, term, index uint64) error
+	Publish(ctx context.Context, term, index uint64) (string, error)
+	Fetch(ctx context.Context, key string) error
 }
 
 // Storage define a set of functions to persist raft data,
@@ -31,7 +66,66 @@ type Storage interface {
 	SaveSnapshot(etcdraftpb.Snapshot) error
 	SaveEntries(etcdraftpb.HardState, []etcdraftpb.Entry) error
 	Snapshotter() Snapshotter
-	Boot([]byte) ([]byte, etcdraftpb.HardState, []etcdraftpb.Entry, *Snapshot, error)
+	Boot(context.Context, []byte) ([]byte, etcdraftpb.HardState, []etcdraftpb.Entry, *Snapshot, error)
 	Exist() bool
+	Available() (uint64, error)
 	Close() error
 }
+
+// ObjectStore define a set of functions to store snapshot files in an object store bucket,
+// such as S3, GCS, or MinIO, where keys are the snapshot file names.
+type ObjectStore interface {
+	// Put uploads the object read from the given reader, the object must only
+	// be visible once the upload complete, e.g. using a multipart upload.
+	// Put must abort the upload when reading from the given reader fails.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get returns a reader of n bytes of the object starting at the given offset.
+	Get(ctx context.Context, key string, off, n int64) (io.ReadCloser, error)
+	// Size returns the object size, or an error wrapping fs.ErrNotExist
+	// when the object does not exist.
+	Size(ctx context.Context, key string) (int64, error)
+	// List returns the keys of all the objects.
+	List(ctx context.Context) ([]string, error)
+	// Delete removes the object.
+	Delete(ctx context.Context, key string) error
+}
+
+// Metrics define a set of functions to report storage performance.
+type Metrics interface {
+	// ObserveSync observes the latency of persisting entries and hard state.
+	ObserveSync(time.Duration)
+	// AddBytesWritten adds the number of bytes written into the WAL.
+	AddBytesWritten(uint64)
+	// SetSegments sets the current number of WAL segments.
+	SetSegments(int)
+	// ObserveCompaction observes the duration of purging old snapshots and WAL segments.
+	ObserveCompaction(time.Duration)
+}
+
+// Verification is the result of a WAL verification.
+type Verification struct {
+	// Entries is the number of entries found in the WAL.
+	Entries int
+	// FirstIndex is the index of the first entry.
+	FirstIndex uint64
+	// LastIndex is the index of the last entry.
+	LastIndex uint64
+	// HardState is the last hard state found in the WAL.
+	HardState etcdraftpb.HardState
+}
+
+// VerificationError is returned when the WAL fails the verification.
+type VerificationError struct {
+	// Index of the entry failing the verification, 0 if unknown.
+	Index uint64
+	// Err is the underlying reason.
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("raft/storage: WAL verification failed at index %d: %v", e.Index, e.Err)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,32 @@
+package transport
+
+import (
+	"context"
+	"crypto/x509"
+)
+
+type credentialsKey struct{}
+
+// Credentials represents the credentials presented by the request source.
+type Credentials struct {
+	// Token is the bearer token sent along with the request.
+	Token string
+	// PeerCertificates are the verified TLS certificates of the request source,
+	// the first certificate is the leaf certificate.
+	PeerCertificates []*x509.Certificate
+	// ClusterID is the id of the cluster the request source belongs to, zero when unknown.
+	ClusterID uint64
+}
+
+// ContextWithCredentials returns a copy of the given ctx,
+// that carries the credentials of the request source.
+func ContextWithCredentials(ctx context.Context, c Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, c)
+}
+
+// CredentialsFromContext returns the credentials of the request source,
+// or empty credentials if the ctx does not carry them.
+func CredentialsFromContext(ctx context.Context) Credentials {
+	c, _ := ctx.Value(credentialsKey{}).(Credentials)
+	return c
+}
@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+// maxDecompressedSize is the maximum size of a decompressed message.
+const maxDecompressedSize = 1 << 30
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+)
+
+// Compressions lists the messages compression algorithms supported by the transport handlers.
+var Compressions = []raftpb.Compression{
+	raftpb.SnappyCompression,
+	raftpb.GzipCompression,
+	raftpb.ZstdCompression,
+}
+
+// AcceptCompression returns the supported compression algorithms,
+// formatted to be advertised by the transport handlers to the clients.
+func AcceptCompression() string {
+	names := make([]string, 0, len(Compressions))
+	for _, c := range Compressions {
+		names = append(names, c.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseCompression returns the compression algorithm of the given name.
+func ParseCompression(name string) (raftpb.Compression, error) {
+	if name == "" {
+		return raftpb.NoCompression, nil
+	}
+
+	v, ok := raftpb.Compression_value[name]
+	if !ok {
+		return raftpb.NoCompression, fmt.Errorf("raft/transport: unsupported compression %s", name)
+	}
+
+	return raftpb.Compression(v), nil
+}
+
+// Compress returns the given data compressed using the given compression algorithm.
+func Compress(c raftpb.Compression, data []byte) ([]byte, error) {
+	switch c {
+	case raftpb.NoCompression:
+		return data, nil
+	case raftpb.SnappyCompression:
+		return snappy.Encode(nil, data), nil
+	case raftpb.GzipCompression:
+		buf := new(bytes.Buffer)
+		w := gzip.NewWriter(buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case raftpb.ZstdCompression:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("raft/transport: unsupported compression %s", c)
+	}
+}
+
+// Decompress returns the given data decompressed using the given compression algorithm.
+func Decompress(c raftpb.Compression, data []byte) ([]byte, error) {
+	switch c {
+	case raftpb.NoCompression:
+		return data, nil
+	case raftpb.SnappyCompression:
+		n, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if n > maxDecompressedSize {
+			return nil, fmt.Errorf("raft/transport: decompressed size %d exceeds max size", n)
+		}
+		return snappy.Decode(nil, data)
+	case raftpb.GzipCompression:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		out, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+		if err == nil && len(out) > maxDecompressedSize {
+			err = fmt.Errorf("raft/transport: decompressed size exceeds max size")
+		}
+		return out, err
+	case raftpb.ZstdCompression:
+		return zstdDecoder.DecodeAll(data, nil)
+	default:
+		return nil, fmt.Errorf("raft/transport: unsupported compression %s", c)
+	}
+}
+
+// Compressor compresses the messages sent by a client,
+// using the configured compression algorithm, once accepted by the remote member handler.
+type Compressor struct {
+	compression raftpb.Compression
+	threshold   int
+	accepted    atomic.Bool
+}
+
+// NewCompressor returns a new Compressor from the given config.
+func NewCompressor(cfg Config) *Compressor {
+	return &Compressor{
+		compression: cfg.MessageCompression(),
+		threshold:   cfg.CompressionThreshold(),
+	}
+}
+
+// Accept records whether the remote member handler accepts the configured compression,
+// from the given accepted compression algorithms advertised by the handler,
+// e.g. the compression no longer accepted once the handler downgraded.
+// An empty value ignored, as not all the handler responses advertise the accepted compressions.
+func (c *Compressor) Accept(accept string) {
+	if c.compression == raftpb.NoCompression || accept == "" {
+		return
+	}
+
+	accepted := false
+	for _, name := range strings.Split(accept, ",") {
+		if strings.TrimSpace(name) == c.compression.String() {
+			accepted = true
+			break
+		}
+	}
+
+	c.accepted.Store(accepted)
+}
+
+// Compress returns the given data compressed and the compression algorithm used,
+// or the given data as is, when the compression disabled, not yet accepted by the remote member handler,
+// or the data size is below the compression threshold.
+func (c *Compressor) Compress(data []byte) ([]byte, raftpb.Compression, error) {
+	if c.compression == raftpb.NoCompression || !c.accepted.Load() || len(data) < c.threshold {
+		return data, raftpb.NoCompression, nil
+	}
+
+	out, err := Compress(c.compression, data)
+	return out, c.compression, err
+}
@@ -0,0 +1,92 @@
+package transport_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	transportmock "github.com/shaj13/raft/internal/mocks/transport"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+)
+
+func TestCompress(t *testing.T) {
+	data := bytes.Repeat([]byte("raft"), 1024)
+
+	for _, c := range append(transport.Compressions, raftpb.NoCompression) {
+		t.Run(c.String(), func(t *testing.T) {
+			out, err := transport.Compress(c, data)
+			require.NoError(t, err)
+
+			got, err := transport.Decompress(c, out)
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+		})
+	}
+
+	// it return error when compression unsupported.
+	_, err := transport.Compress(raftpb.Compression(100), data)
+	require.Error(t, err)
+	_, err = transport.Decompress(raftpb.Compression(100), data)
+	require.Error(t, err)
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range transport.Compressions {
+		got, err := transport.ParseCompression(c.String())
+		require.NoError(t, err)
+		require.Equal(t, c, got)
+	}
+
+	got, err := transport.ParseCompression("")
+	require.NoError(t, err)
+	require.Equal(t, raftpb.NoCompression, got)
+
+	_, err = transport.ParseCompression("unknown")
+	require.Error(t, err)
+}
+
+func TestCompressor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().MessageCompression().Return(raftpb.GzipCompression)
+	cfg.EXPECT().CompressionThreshold().Return(10)
+
+	c := transport.NewCompressor(cfg)
+	data := bytes.Repeat([]byte("raft"), 10)
+
+	// it does not compress until the handler accepts the compression.
+	out, comp, err := c.Compress(data)
+	require.NoError(t, err)
+	require.Equal(t, raftpb.NoCompression, comp)
+	require.Equal(t, data, out)
+
+	c.Accept("snappy,zstd")
+	_, comp, _ = c.Compress(data)
+	require.Equal(t, raftpb.NoCompression, comp)
+
+	// it compress when the handler accepts the compression.
+	c.Accept(transport.AcceptCompression())
+	out, comp, err = c.Compress(data)
+	require.NoError(t, err)
+	require.Equal(t, raftpb.GzipCompression, comp)
+	require.NotEqual(t, data, out)
+
+	// it does not compress data below the threshold.
+	_, comp, _ = c.Compress([]byte("raft"))
+	require.Equal(t, raftpb.NoCompression, comp)
+
+	// it keep compressing when the handler does not advertise the accepted compressions.
+	c.Accept("")
+	_, comp, _ = c.Compress(data)
+	require.Equal(t, raftpb.GzipCompression, comp)
+
+	// it stop compressing when the handler no longer accepts the compression.
+	c.Accept("snappy")
+	out, comp, err = c.Compress(data)
+	require.NoError(t, err)
+	require.Equal(t, raftpb.NoCompression, comp)
+	require.Equal(t, data, out)
+}
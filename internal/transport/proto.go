@@ -9,6 +9,14 @@ const (
 	GRPC Proto = iota + 1
 	// HTTP represents raft transportation using http.
 	HTTP
+	// QUIC represents raft transportation using QUIC.
+	QUIC
+	// LOCAL represents raft transportation using unix domain sockets or in-process pipes.
+	LOCAL
+	// WS represents raft transportation using websocket.
+	WS
+	// SIM represents raft transportation over the in-process simulated network of rafttest.
+	SIM
 	max
 )
 
@@ -60,6 +68,14 @@ func (c Proto) String() string {
 		return "gRPC"
 	case HTTP:
 		return "http"
+	case QUIC:
+		return "quic"
+	case LOCAL:
+		return "local"
+	case WS:
+		return "websocket"
+	case SIM:
+		return "simulation"
 	default:
 		return "unknown proto value " + strconv.Itoa(int(c))
 	}
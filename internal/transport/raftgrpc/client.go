@@ -5,17 +5,21 @@ import (
 	"context"
 	"io"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/transport"
 	"github.com/shaj13/raft/internal/transport/raftgrpc/pb"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 )
 
-var _ transport.Client = &client{}
+var _ transport.BatchClient = &client{}
 
 var bufferPool = sync.Pool{
 	New: func() interface{} {
@@ -24,8 +28,16 @@ var bufferPool = sync.Pool{
 }
 
 const (
-	snapshotHeader = "X-Raft-Snapshot"
-	groupIDHeader  = "X-Raft-Group-ID"
+	snapshotHeader       = "X-Raft-Snapshot"
+	snapshotOffsetHeader = "X-Raft-Snapshot-Offset"
+	groupIDHeader        = "X-Raft-Group-ID"
+	clusterIDHeader      = "X-Raft-Cluster-ID"
+	batchHeader          = "X-Raft-Message-Batch"
+	compressionHeader    = "X-Raft-Message-Compression"
+	acceptCompHeader     = "X-Raft-Accept-Compression"
+	acceptBatchHeader    = "X-Raft-Accept-Message-Batch"
+	authHeader           = "authorization"
+	bearerPrefix         = "Bearer "
 )
 
 // Dialer return's grpc dialer.
@@ -35,16 +47,24 @@ func Dialer(
 ) transport.Dialer {
 	return func(cfg transport.Config) transport.Dial {
 		return func(ctx context.Context, addr string) (transport.Client, error) {
-			conn, err := grpc.DialContext(ctx, addr, dopts(ctx)...)
+			opts := dopts(ctx)
+			// TLS credentials appended last to take precedence over the dial options.
+			if tc := cfg.TLSConfig(); tc != nil {
+				opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tc)))
+			}
+
+			conn, err := grpc.DialContext(ctx, addr, opts...)
 			if err != nil {
 				return nil, err
 			}
 
 			return &client{
-				conn:  conn,
-				copts: copts,
-				gid:   cfg.GroupID(),
-				ctrl:  cfg.Controller(),
+				conn:       conn,
+				copts:      copts,
+				gid:        cfg.GroupID(),
+				ctrl:       cfg.Controller(),
+				token:      cfg.AuthToken(),
+				compressor: transport.NewCompressor(cfg),
 			}, nil
 		}
 	}
@@ -52,16 +72,21 @@ func Dialer(
 
 // Client implements transport.Client.
 type client struct {
-	conn  *grpc.ClientConn
-	copts func(context.Context) []grpc.CallOption
-	gid   uint64
-	ctrl  transport.Controller
+	conn       *grpc.ClientConn
+	copts      func(context.Context) []grpc.CallOption
+	gid        uint64
+	ctrl       transport.Controller
+	token      string
+	compressor *transport.Compressor
+	// batch reports whether the remote member handler accepts the messages batches,
+	// the members predating the batches decode each message rpc as a single message.
+	batch atomic.Bool
 }
 
 func (c *client) PromoteMember(ctx context.Context, m raftpb.Member) error {
-	ctx = ctxWithGroupID(ctx, c.gid)
+	ctx = c.outgoingContext(ctx)
 	_, err := pb.NewRaftClient(c.conn).PromoteMember(ctx, &m, c.copts(ctx)...)
-	return err
+	return rafterrors.FromError(err)
 }
 
 func (c *client) Message(ctx context.Context, msg etcdraftpb.Message) error {
@@ -75,26 +100,64 @@ func (c *client) Message(ctx context.Context, msg etcdraftpb.Message) error {
 		return nil
 	}
 
-	return err
+	return rafterrors.FromError(err)
 }
 
 func (c *client) Join(ctx context.Context, m raftpb.Member) (*raftpb.JoinResponse, error) {
-	ctx = ctxWithGroupID(ctx, c.gid)
-	return pb.NewRaftClient(c.conn).Join(ctx, &m, c.copts(ctx)...)
+	ctx = c.outgoingContext(ctx)
+	resp, err := pb.NewRaftClient(c.conn).Join(ctx, &m, c.copts(ctx)...)
+	return resp, rafterrors.FromError(err)
 }
 
 func (c *client) Close() error {
 	return c.conn.Close()
 }
 
-func (c *client) message(ctx context.Context, msg etcdraftpb.Message) (err error) {
-	ctx = ctxWithGroupID(ctx, c.gid)
+// Messages sends the given raft messages in a single rpc,
+// the messages must not contain snapshot messages.
+// It sends the messages one by one until the remote member handler advertise the batches support.
+func (c *client) Messages(ctx context.Context, msgs []etcdraftpb.Message) error {
+	if !c.batch.Load() {
+		for _, msg := range msgs {
+			if err := c.message(ctx, msg); err != nil {
+				return rafterrors.FromError(err)
+			}
+		}
+		return nil
+	}
+
+	batch := &raftpb.MessageBatch{Messages: msgs}
+	data, err := batch.Marshal()
+	if err != nil {
+		return err
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, batchHeader, "true")
+	return rafterrors.FromError(c.stream(ctx, data))
+}
 
+func (c *client) message(ctx context.Context, msg etcdraftpb.Message) error {
 	data, err := msg.Marshal()
 	if err != nil {
 		return err
 	}
 
+	return c.stream(ctx, data)
+}
+
+// stream sends the given encoded message or messages batch over the message stream.
+func (c *client) stream(ctx context.Context, data []byte) (err error) {
+	ctx = c.outgoingContext(ctx)
+
+	data, comp, err := c.compressor.Compress(data)
+	if err != nil {
+		return err
+	}
+
+	if comp != raftpb.NoCompression {
+		ctx = metadata.AppendToOutgoingContext(ctx, compressionHeader, comp.String())
+	}
+
 	stream, err := pb.NewRaftClient(c.conn).Message(ctx, c.copts(ctx)...)
 	if err != nil {
 		return err
@@ -110,6 +173,14 @@ func (c *client) message(ctx context.Context, msg etcdraftpb.Message) (err error
 		if err == nil {
 			err = rerr
 		}
+
+		// negotiate the compression and the batches from the member handler advertised headers.
+		if md, herr := stream.Header(); herr == nil {
+			c.compressor.Accept(strings.Join(md.Get(acceptCompHeader), ","))
+			if len(md.Get(acceptBatchHeader)) > 0 {
+				c.batch.Store(true)
+			}
+		}
 	}()
 
 	enc := newEncoder(buf)
@@ -118,19 +189,48 @@ func (c *client) message(ctx context.Context, msg etcdraftpb.Message) (err error
 	})
 }
 
-func (c *client) snapshot(ctx context.Context, msg etcdraftpb.Message) (err error) {
+func (c *client) snapshot(ctx context.Context, msg etcdraftpb.Message) error {
 	meta := msg.Snapshot.Metadata
-	r, err := c.ctrl.SnapshotReader(c.gid, meta.Term, meta.Index)
+
+	// send only the snapshot key when published to the shared snapshot store,
+	// otherwise fallback to stream the snapshot files.
+	key, err := c.ctrl.SnapshotPublish(ctx, c.gid, meta.Term, meta.Index)
+	msg.Snapshot.Data = nil
+	if err == nil && key != "" {
+		msg.Snapshot.Data = []byte(key)
+		return c.message(ctx, msg)
+	}
+
+	chain, err := c.ctrl.SnapshotChain(c.gid, meta.Term, meta.Index)
+	if err != nil {
+		return err
+	}
+
+	// send the snapshots the delta snapshot chained onto before the snapshot itself.
+	for _, m := range chain {
+		if err := c.snapshotFile(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	return c.message(ctx, msg)
+}
+
+func (c *client) snapshotFile(ctx context.Context, meta etcdraftpb.SnapshotMetadata) (err error) {
+	offset, compress := c.snapshotOffset(ctx, meta.Term, meta.Index)
+	r, err := c.ctrl.SnapshotReader(c.gid, meta.Term, meta.Index, offset, compress)
 	if err != nil {
 		return err
 	}
 
+	defer r.Close()
+
 	md := metadata.Pairs(
 		snapshotHeader, strconv.FormatUint(meta.Term, 10),
 		snapshotHeader, strconv.FormatUint(meta.Index, 10),
-		groupIDHeader, strconv.FormatUint(c.gid, 10),
+		snapshotOffsetHeader, strconv.FormatUint(offset, 10),
 	)
-	ctx = metadata.NewOutgoingContext(ctx, md)
+	ctx = c.outgoingContext(metadata.NewOutgoingContext(ctx, md))
 
 	stream, err := pb.NewRaftClient(c.conn).Snapshot(ctx, c.copts(ctx)...)
 	if err != nil {
@@ -145,18 +245,39 @@ func (c *client) snapshot(ctx context.Context, msg etcdraftpb.Message) (err erro
 	}()
 
 	enc := newEncoder(r)
-	err = enc.Encode(func(c *pb.Chunk) error {
+	return enc.Encode(func(c *pb.Chunk) error {
 		return stream.Send(c)
 	})
+}
 
+// snapshotOffset returns the number of snapshot bytes already received by the remote member,
+// to resume an interrupted transfer, and whether the remote member accepts compressed snapshots.
+// it returns 0 when the remote member does not support resuming.
+func (c *client) snapshotOffset(ctx context.Context, term, index uint64) (uint64, bool) {
+	ctx = c.outgoingContext(ctx)
+	in := &pb.SnapshotMeta{
+		Term:  term,
+		Index: index,
+	}
+
+	out, err := pb.NewRaftClient(c.conn).SnapshotOffset(ctx, in, c.copts(ctx)...)
 	if err != nil {
-		return
+		return 0, false
 	}
 
-	return c.message(ctx, msg)
+	return out.Offset, out.Compression
 }
 
-func ctxWithGroupID(ctx context.Context, gid uint64) context.Context {
-	str := strconv.FormatUint(gid, 10)
-	return metadata.AppendToOutgoingContext(ctx, groupIDHeader, str)
+// outgoingContext returns a copy of the given ctx that carries the group id,
+// and the cluster id and the auth token if any.
+func (c *client) outgoingContext(ctx context.Context) context.Context {
+	str := strconv.FormatUint(c.gid, 10)
+	ctx = metadata.AppendToOutgoingContext(ctx, groupIDHeader, str)
+	if id := c.ctrl.ClusterID(c.gid); id != 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, clusterIDHeader, strconv.FormatUint(id, 10))
+	}
+	if len(c.token) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, authHeader, bearerPrefix+c.token)
+	}
+	return ctx
 }
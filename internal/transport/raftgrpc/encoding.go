@@ -3,7 +3,9 @@ package raftgrpc
 import (
 	"bufio"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"math"
 
 	"github.com/shaj13/raft/internal/transport/raftgrpc/pb"
 )
@@ -36,16 +38,18 @@ func (e *encoder) Encode(cb func(*pb.Chunk) error) error {
 }
 
 func (e *encoder) chunk() *pb.Chunk {
+	data := e.scanner.Bytes()
 	c := &pb.Chunk{
-		Index: e.index,
-		Data:  e.scanner.Bytes(),
+		Index:    e.index,
+		Data:     data,
+		Checksum: crc32.ChecksumIEEE(data),
 	}
 	e.index++
 	return c
 }
 
 func (e *encoder) scan(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	n := bufio.MaxScanTokenSize - (&pb.Chunk{Index: e.index}).Size()
+	n := bufio.MaxScanTokenSize - (&pb.Chunk{Index: e.index, Checksum: math.MaxUint32}).Size()
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
 	}
@@ -75,6 +79,10 @@ func (d *decoder) Decode(c *pb.Chunk) error {
 		)
 	}
 
+	if c.Checksum != 0 && c.Checksum != crc32.ChecksumIEEE(c.Data) {
+		return fmt.Errorf("raft/grpc: chunk with index %d corrupted, checksum mismatch", c.Index)
+	}
+
 	_, err := d.w.Write(c.Data)
 	return err
 }
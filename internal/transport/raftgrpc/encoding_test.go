@@ -31,6 +31,10 @@ func TestDecoder(t *testing.T) {
 
 	// space removed by split func.
 	assert.Equal(t, "TestDecoder", w.String())
+
+	// Round #3 it return error when checksum mismatch
+	err = dec.Decode(&pb.Chunk{Index: dec.index, Data: []byte("data"), Checksum: 1})
+	assert.Contains(t, err.Error(), "checksum mismatch")
 }
 
 func TestEncoder(t *testing.T) {
@@ -14,10 +14,16 @@ import (
 	"github.com/stretchr/testify/require"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	transportmock "github.com/shaj13/raft/internal/mocks/transport"
 	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
 	"github.com/shaj13/raft/internal/transport/raftgrpc/pb"
 	"github.com/shaj13/raft/raftlog"
 )
@@ -47,6 +53,8 @@ func TestMessage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
 			rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
 			srv.ctrl = rpcCtrl
 			err := c.Message(context.Background(), etcdraftpb.Message{})
@@ -57,6 +65,163 @@ func TestMessage(t *testing.T) {
 	}
 }
 
+func TestMessages(t *testing.T) {
+	ln, c, srv := testClientServer(t)
+	defer ln.Close()
+	defer c.Close()
+
+	msgs := []etcdraftpb.Message{
+		{Type: etcdraftpb.MsgApp, To: 1},
+		{Type: etcdraftpb.MsgHeartbeat, To: 1},
+	}
+
+	table := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "it return nil error when server process msgs",
+			err:  nil,
+		},
+		{
+			name: "it return error when server return error",
+			err:  fmt.Errorf("TestMessages Error"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []etcdraftpb.Message
+			ctrl := gomock.NewController(t)
+			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.
+				EXPECT().
+				Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ uint64, m etcdraftpb.Message) error {
+					got = append(got, m)
+					return tt.err
+				}).
+				MinTimes(1)
+			srv.ctrl = rpcCtrl
+			err := c.Messages(context.Background(), msgs)
+			if tt.err != nil {
+				require.Contains(t, err.Error(), tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, msgs, got)
+		})
+	}
+}
+
+func TestMessagesLegacyHandler(t *testing.T) {
+	ln := bufconn.Listen(1024)
+	defer ln.Close()
+
+	var got []etcdraftpb.Message
+	server := grpc.NewServer()
+	pb.RegisterRaftServer(server, &legacyHandler{push: func(m etcdraftpb.Message) {
+		got = append(got, m)
+	}})
+	go server.Serve(ln)
+	defer server.Stop()
+
+	c := testClient(t, ln)
+	defer c.Close()
+
+	msgs := []etcdraftpb.Message{
+		{Type: etcdraftpb.MsgApp, To: 1},
+		{Type: etcdraftpb.MsgHeartbeat, To: 1},
+	}
+
+	// it send the messages one by one to the handler predating the batches.
+	for i := 0; i < 2; i++ {
+		err := c.Messages(context.Background(), msgs)
+		require.NoError(t, err)
+		require.False(t, c.batch.Load())
+	}
+
+	require.Equal(t, append(msgs, msgs...), got)
+}
+
+func TestMessagesBatchNegotiation(t *testing.T) {
+	ln, c, srv := testClientServer(t)
+	defer ln.Close()
+	defer c.Close()
+
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	srv.ctrl = rpcCtrl
+
+	// it send batches once the handler advertised the batches support.
+	err := c.Message(context.Background(), etcdraftpb.Message{Type: etcdraftpb.MsgApp, To: 1})
+	require.NoError(t, err)
+	require.True(t, c.batch.Load())
+}
+
+func TestClusterID(t *testing.T) {
+	ln, c, srv := testClientServer(t)
+	defer ln.Close()
+	defer c.Close()
+
+	ctrl := gomock.NewController(t)
+	clientCtrl := transportmock.NewMockController(ctrl)
+	clientCtrl.EXPECT().ClusterID(gomock.Eq(testGroupID)).Return(uint64(7)).AnyTimes()
+	c.ctrl = clientCtrl
+
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.
+		EXPECT().
+		Authenticate(gomock.Any(), gomock.Eq(testGroupID)).
+		DoAndReturn(func(ctx context.Context, gid uint64) error {
+			require.Equal(t, uint64(7), transport.CredentialsFromContext(ctx).ClusterID)
+			return fmt.Errorf("%w: cluster mismatch", rafterrors.ErrUnauthenticated)
+		})
+	srv.ctrl = rpcCtrl
+
+	// it send the cluster id along with the request.
+	err := c.Message(context.Background(), etcdraftpb.Message{})
+	require.ErrorIs(t, err, rafterrors.ErrUnauthenticated)
+}
+
+func TestCompression(t *testing.T) {
+	ln, c, srv := testClientServer(t)
+	defer ln.Close()
+	defer c.Close()
+
+	ctrl := gomock.NewController(t)
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().MessageCompression().Return(raftpb.ZstdCompression)
+	cfg.EXPECT().CompressionThreshold().Return(0)
+	c.compressor = transport.NewCompressor(cfg)
+
+	msg := etcdraftpb.Message{
+		Type:    etcdraftpb.MsgApp,
+		Entries: []etcdraftpb.Entry{{Data: bytes.Repeat([]byte("raft"), 100)}},
+	}
+
+	rpcCtrl := transportmock.NewMockController(ctrl)
+
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Eq(msg)).Return(nil).Times(2)
+	srv.ctrl = rpcCtrl
+
+	// it negotiate the compression by the first message, and compress the next messages.
+	for i := 0; i < 2; i++ {
+		err := c.Message(context.Background(), msg)
+		require.NoError(t, err)
+	}
+
+	_, comp, _ := c.compressor.Compress(nil)
+	require.Equal(t, raftpb.ZstdCompression, comp)
+}
+
 func TestJoin(t *testing.T) {
 	ln, c, srv := testClientServer(t)
 	defer ln.Close()
@@ -85,7 +250,16 @@ func TestJoin(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			rpcCtrl := transportmock.NewMockController(ctrl)
-			rpcCtrl.EXPECT().Join(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.resp, tt.err)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.
+				EXPECT().
+				Join(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ uint64, _ *raftpb.Member) (*raftpb.JoinResponse, error) {
+					// it propagate the request source address.
+					require.NotEmpty(t, transport.SourceFromContext(ctx))
+					return tt.resp, tt.err
+				})
 			srv.ctrl = rpcCtrl
 			resp, err := c.Join(context.Background(), raftpb.Member{})
 			require.Equal(t, tt.resp, resp)
@@ -122,14 +296,30 @@ func TestSnapshot(t *testing.T) {
 			ctrl := gomock.NewController(t)
 
 			rpcCtrl := transportmock.NewMockController(ctrl)
+
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
 			rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
 			rpcCtrl.
 				EXPECT().
-				SnapshotReader(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				SnapshotPublish(gomock.Any(), gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return("", nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotChain(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return([]etcdraftpb.SnapshotMetadata{{Term: 1, Index: 1}}, nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotOffset(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return(uint64(5), nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotReader(gomock.Eq(testGroupID), gomock.Any(), gomock.Any(), gomock.Eq(uint64(5)), gomock.Eq(true)).
 				Return(io.NopCloser(strings.NewReader(snapData)), nil)
 			rpcCtrl.
 				EXPECT().
-				SnapshotWriter(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				SnapshotWriter(gomock.Eq(testGroupID), gomock.Any(), gomock.Any(), gomock.Eq(uint64(5))).
 				Return(writeCloser{buf}, nil)
 
 			srv.ctrl = rpcCtrl
@@ -144,6 +334,37 @@ func TestSnapshot(t *testing.T) {
 	}
 }
 
+func TestSnapshotPublished(t *testing.T) {
+	ln, c, srv := testClientServer(t)
+	defer ln.Close()
+	defer c.Close()
+
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	rpcCtrl.
+		EXPECT().
+		SnapshotPublish(gomock.Any(), gomock.Eq(testGroupID), gomock.Eq(uint64(1)), gomock.Eq(uint64(2))).
+		Return("key", nil)
+	rpcCtrl.
+		EXPECT().
+		Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, gid uint64, m etcdraftpb.Message) error {
+			// it send only the snapshot key.
+			require.Equal(t, []byte("key"), m.Snapshot.Data)
+			return nil
+		})
+
+	srv.ctrl = rpcCtrl
+	c.ctrl = rpcCtrl
+
+	msg := etcdraftpb.Message{Type: etcdraftpb.MsgSnap}
+	msg.Snapshot.Metadata = etcdraftpb.SnapshotMetadata{Term: 1, Index: 2}
+	err := c.snapshot(context.Background(), msg)
+	require.NoError(t, err)
+}
+
 func TestPromoteMember(t *testing.T) {
 	ts, c, srv := testClientServer(t)
 	defer ts.Close()
@@ -152,6 +373,7 @@ func TestPromoteMember(t *testing.T) {
 	table := []struct {
 		name string
 		err  error
+		is   error
 	}{
 		{
 			name: "it return nil error when server process promote",
@@ -161,22 +383,55 @@ func TestPromoteMember(t *testing.T) {
 			name: "it return error when server return error",
 			err:  fmt.Errorf("TestPromoteMember Error"),
 		},
+		{
+			name: "it return typed error when server return typed error",
+			err:  fmt.Errorf("%w: TestPromoteMember Error", rafterrors.ErrJoinDenied),
+			is:   rafterrors.ErrJoinDenied,
+		},
 	}
 
 	for _, tt := range table {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
 			rpcCtrl.EXPECT().PromoteMember(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
 			srv.ctrl = rpcCtrl
 			err := c.PromoteMember(context.Background(), raftpb.Member{})
 			if tt.err != nil {
 				require.Contains(t, err.Error(), tt.err.Error())
 			}
+			if tt.is != nil {
+				require.ErrorIs(t, err, tt.is)
+			}
 		})
 	}
 }
 
+func TestHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	srv := &handler{ctrl: rpcCtrl, logger: raftlog.DefaultLogger}
+
+	// it return serving when the node is serving.
+	rpcCtrl.EXPECT().Health(gomock.Eq(uint64(0))).Return(nil)
+	res, err := srv.Check(context.TODO(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+
+	// it return not serving when the group node is not serving.
+	rpcCtrl.EXPECT().Health(gomock.Eq(testGroupID)).Return(rafterrors.ErrNoLeader)
+	res, err = srv.Check(context.TODO(), &healthpb.HealthCheckRequest{Service: "1"})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.Status)
+
+	// it return not found when the service is unknown.
+	_, err = srv.Check(context.TODO(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func testClientServer(tb testing.TB) (*bufconn.Listener, *client, *handler) {
 	ln := bufconn.Listen(1024)
 	srv := new(handler)
@@ -189,6 +444,10 @@ func testClientServer(tb testing.TB) (*bufconn.Listener, *client, *handler) {
 		server.Serve(ln)
 	}()
 
+	return ln, testClient(tb, ln), srv
+}
+
+func testClient(tb testing.TB, ln *bufconn.Listener) *client {
 	dial := func(context.Context, string) (net.Conn, error) {
 		return ln.Dial()
 	}
@@ -206,14 +465,53 @@ func testClientServer(tb testing.TB) (*bufconn.Listener, *client, *handler) {
 	ctrl := gomock.NewController(tb)
 	cfg := transportmock.NewMockConfig(ctrl)
 	cfg.EXPECT().GroupID().Return(testGroupID).AnyTimes()
-	cfg.EXPECT().Controller()
+	cfg.EXPECT().Controller().Return(testController(ctrl))
+	cfg.EXPECT().TLSConfig()
+	cfg.EXPECT().AuthToken().AnyTimes()
+	cfg.EXPECT().MessageCompression().AnyTimes()
+	cfg.EXPECT().CompressionThreshold().AnyTimes()
 
 	c, err := Dialer(dopts, copts)(cfg)(ctx, "")
 	if err != nil {
 		tb.Fatal(err)
 	}
 
-	return ln, c.(*client), srv
+	return c.(*client)
+}
+
+// legacyHandler mimics the message handler of the members predating the messages batches,
+// it decodes each message rpc as a single message and advertise nothing.
+type legacyHandler struct {
+	pb.UnimplementedRaftServer
+	push func(etcdraftpb.Message)
+}
+
+func (h *legacyHandler) Message(stream pb.Raft_MessageServer) error {
+	buf := new(bytes.Buffer)
+	dec := newDecoder(buf)
+
+	for {
+		c, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := dec.Decode(c); err != nil {
+			return err
+		}
+	}
+
+	m := etcdraftpb.Message{}
+	if err := m.Unmarshal(buf.Bytes()); err != nil {
+		return err
+	}
+
+	h.push(m)
+	return stream.SendAndClose(&emptypb.Empty{})
 }
 
 type writeCloser struct {
@@ -223,3 +521,14 @@ type writeCloser struct {
 func (writeCloser) Close() error {
 	return nil
 }
+
+func (writeCloser) Commit() error {
+	return nil
+}
+
+// testController returns a client side controller mock, of a node that belongs to no cluster.
+func testController(ctrl *gomock.Controller) *transportmock.MockController {
+	c := transportmock.NewMockController(ctrl)
+	c.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	return c
+}
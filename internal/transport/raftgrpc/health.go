@@ -0,0 +1,72 @@
+package raftgrpc
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthWatchInterval is the interval of checking the node health, while watching it.
+const healthWatchInterval = time.Second
+
+var _ healthpb.HealthServer = &handler{}
+
+// Check implements the gRPC health service, it reflects the health of the raft node,
+// associated to the group id in the request service name, or the default group when empty.
+func (h *handler) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, err := h.health(req.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch implements the gRPC health service, it streams the health of the raft node on changes.
+func (h *handler) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		st, err := h.health(req.Service)
+		if err != nil {
+			return err
+		}
+
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (h *handler) health(service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	var gid uint64
+	if len(service) > 0 {
+		v, err := strconv.ParseUint(service, 0, 64)
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN, status.Errorf(codes.NotFound, "unknown service %q", service)
+		}
+		gid = v
+	}
+
+	if err := h.ctrl.Health(gid); err != nil {
+		h.logger.V(2).Infof("raft.grpc: group %d not serving: %v", gid, err)
+		return healthpb.HealthCheckResponse_NOT_SERVING, nil
+	}
+
+	return healthpb.HealthCheckResponse_SERVING, nil
+}
@@ -33,7 +33,9 @@ type Chunk struct {
 	// Index specifies the chunk index.
 	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
 	// Data specifies the raw chunk data.
-	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// Checksum specifies the crc32 of the chunk data, 0 to skip verification.
+	Checksum             uint32   `protobuf:"varint,3,opt,name=checksum,proto3" json:"checksum,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -72,8 +74,56 @@ func (m *Chunk) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Chunk proto.InternalMessageInfo
 
+type SnapshotMeta struct {
+	// Term specifies the snapshot term.
+	Term uint64 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	// Index specifies the snapshot index.
+	Index uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Offset specifies the number of bytes already received.
+	Offset uint64 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Compression reports whether the receiver accepts compressed snapshots.
+	Compression          bool     `protobuf:"varint,4,opt,name=compression,proto3" json:"compression,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnapshotMeta) Reset()         { *m = SnapshotMeta{} }
+func (m *SnapshotMeta) String() string { return proto.CompactTextString(m) }
+func (*SnapshotMeta) ProtoMessage()    {}
+func (*SnapshotMeta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3973619806d997ba, []int{1}
+}
+func (m *SnapshotMeta) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SnapshotMeta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SnapshotMeta.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SnapshotMeta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotMeta.Merge(m, src)
+}
+func (m *SnapshotMeta) XXX_Size() int {
+	return m.Size()
+}
+func (m *SnapshotMeta) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotMeta.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotMeta proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*Chunk)(nil), "pb.Chunk")
+	proto.RegisterType((*SnapshotMeta)(nil), "pb.SnapshotMeta")
 }
 
 func init() {
@@ -81,26 +131,32 @@ func init() {
 }
 
 var fileDescriptor_3973619806d997ba = []byte{
-	// 299 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x8e, 0x4f, 0x4b, 0x03, 0x31,
-	0x10, 0xc5, 0x37, 0x65, 0xeb, 0x9f, 0xa0, 0x1e, 0xc2, 0x22, 0x65, 0x85, 0xa5, 0x14, 0x84, 0x9e,
-	0x12, 0x6b, 0xbd, 0x78, 0x55, 0xbc, 0x08, 0x05, 0x59, 0x3f, 0x41, 0xd2, 0xa6, 0xd9, 0xb5, 0xdd,
-	0x4c, 0x48, 0xa6, 0xa0, 0xdf, 0xb0, 0xc7, 0x9e, 0x3c, 0xdb, 0x7e, 0x12, 0xd9, 0x5d, 0x2b, 0x88,
-	0x08, 0xde, 0xe6, 0xcd, 0x9b, 0x79, 0xef, 0x47, 0x2f, 0x4b, 0x8b, 0xda, 0x5b, 0xb9, 0x14, 0xe8,
-	0xa5, 0x0d, 0x0e, 0x3c, 0x0a, 0xe3, 0xdd, 0x54, 0x38, 0x25, 0xbc, 0x9c, 0x23, 0x77, 0x1e, 0x10,
-	0x58, 0xc7, 0xa9, 0x34, 0x31, 0x60, 0xa0, 0x91, 0xa2, 0x9e, 0x5a, 0x27, 0xbd, 0x30, 0x00, 0x66,
-	0xa9, 0x45, 0xa3, 0xd4, 0x6a, 0x2e, 0x74, 0xe5, 0xf0, 0xed, 0xcb, 0xbc, 0x31, 0x25, 0x16, 0x2b,
-	0xc5, 0xa7, 0x50, 0x89, 0x50, 0xc8, 0x97, 0xd1, 0xb8, 0x09, 0x5d, 0x94, 0x28, 0xbe, 0x7b, 0xeb,
-	0xc5, 0x8f, 0xb2, 0xc1, 0x88, 0x76, 0xef, 0x8b, 0x95, 0x5d, 0xb0, 0x84, 0x76, 0x4b, 0x3b, 0xd3,
-	0xaf, 0x3d, 0xd2, 0x27, 0xc3, 0x38, 0x6f, 0x05, 0x63, 0x34, 0x9e, 0x49, 0x94, 0xbd, 0x4e, 0x9f,
-	0x0c, 0x4f, 0xf2, 0x66, 0xbe, 0x7e, 0x27, 0x34, 0xce, 0xe5, 0x1c, 0xd9, 0x15, 0x3d, 0x9c, 0xe8,
-	0x10, 0xa4, 0xd1, 0xec, 0x98, 0x3b, 0xc5, 0x9b, 0xa0, 0xf4, 0x9c, 0xb7, 0x94, 0x7c, 0x4f, 0xc9,
-	0x1f, 0x6a, 0xca, 0x41, 0x34, 0x24, 0x6c, 0x44, 0x8f, 0x9e, 0xad, 0x74, 0xa1, 0x00, 0xfc, 0xef,
-	0x0b, 0xa7, 0xf1, 0x23, 0x94, 0x96, 0x9d, 0xf1, 0x16, 0x9e, 0x4f, 0x74, 0xa5, 0xb4, 0x4f, 0x93,
-	0xbd, 0xae, 0xdd, 0x5c, 0x07, 0x07, 0x36, 0xe8, 0x41, 0xc4, 0x6e, 0xe9, 0xe9, 0x93, 0x87, 0x0a,
-	0x50, 0xb7, 0x87, 0xbf, 0x1e, 0xff, 0x2c, 0xbb, 0x4b, 0xd6, 0xdb, 0x2c, 0xda, 0x6c, 0xb3, 0x68,
-	0xbd, 0xcb, 0xc8, 0x66, 0x97, 0x91, 0x8f, 0x5d, 0x46, 0xd4, 0x41, 0x73, 0x37, 0xfe, 0x0c, 0x00,
-	0x00, 0xff, 0xff, 0x43, 0x21, 0x90, 0x00, 0xbe, 0x01, 0x00, 0x00,
+	// 388 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x90, 0xdf, 0x6a, 0xd4, 0x40,
+	0x14, 0xc6, 0x33, 0x6b, 0x5a, 0xd7, 0xb1, 0x2d, 0x32, 0x2c, 0x25, 0x44, 0x08, 0x21, 0x20, 0xe4,
+	0x6a, 0xc6, 0x5a, 0x11, 0xbc, 0x55, 0xbc, 0x11, 0x82, 0x12, 0x9f, 0x60, 0x92, 0x9e, 0xfc, 0x71,
+	0x9b, 0x39, 0xc3, 0xcc, 0x04, 0xf4, 0x25, 0x7c, 0xae, 0x5e, 0xf6, 0x11, 0xec, 0x3e, 0x89, 0x64,
+	0xb2, 0xa9, 0x2b, 0x45, 0xe8, 0xdd, 0xf9, 0xce, 0x99, 0xf3, 0x7d, 0x67, 0x7e, 0xf4, 0x55, 0xaf,
+	0x1c, 0x18, 0x25, 0xaf, 0x85, 0x33, 0x52, 0x59, 0x8d, 0xc6, 0x89, 0xd6, 0xe8, 0x5a, 0xe8, 0x4a,
+	0x18, 0xd9, 0x38, 0xae, 0x0d, 0x3a, 0x64, 0x2b, 0x5d, 0xc5, 0x9b, 0x16, 0x5b, 0xf4, 0x52, 0x4c,
+	0xd5, 0x3c, 0x89, 0x5f, 0xb6, 0x88, 0xed, 0x35, 0x08, 0xaf, 0xaa, 0xb1, 0x11, 0x30, 0x68, 0xf7,
+	0x73, 0x3f, 0x7c, 0xdb, 0xf6, 0xae, 0x1b, 0x2b, 0x5e, 0xe3, 0x20, 0x6c, 0x27, 0xbf, 0x5f, 0x5c,
+	0x7a, 0xd3, 0x6d, 0xef, 0xc4, 0x7d, 0xee, 0xd4, 0xf8, 0x27, 0x2c, 0x2b, 0xe8, 0xd1, 0xc7, 0x6e,
+	0x54, 0x5b, 0xb6, 0xa1, 0x47, 0xbd, 0xba, 0x82, 0x1f, 0x11, 0x49, 0x49, 0x1e, 0x96, 0xb3, 0x60,
+	0x8c, 0x86, 0x57, 0xd2, 0xc9, 0x68, 0x95, 0x92, 0xfc, 0xa4, 0xf4, 0x35, 0x8b, 0xe9, 0xba, 0xee,
+	0xa0, 0xde, 0xda, 0x71, 0x88, 0x9e, 0xa4, 0x24, 0x3f, 0x2d, 0xef, 0x75, 0x66, 0xe8, 0xc9, 0x37,
+	0x25, 0xb5, 0xed, 0xd0, 0x15, 0xe0, 0xe4, 0xb4, 0xef, 0xc0, 0x0c, 0x7b, 0x53, 0x5f, 0xff, 0x4d,
+	0x5a, 0x1d, 0x26, 0x9d, 0xd3, 0x63, 0x6c, 0x1a, 0x0b, 0xce, 0x7b, 0x86, 0xe5, 0x5e, 0xb1, 0x94,
+	0x3e, 0xaf, 0x71, 0xd0, 0x06, 0xac, 0xed, 0x51, 0x45, 0x61, 0x4a, 0xf2, 0x75, 0x79, 0xd8, 0x7a,
+	0xf3, 0x6b, 0x45, 0xc3, 0x52, 0x36, 0x8e, 0xbd, 0xa6, 0x4f, 0x0b, 0xb0, 0x56, 0xb6, 0xc0, 0x9e,
+	0x71, 0x5d, 0x71, 0xff, 0xb1, 0xf8, 0x9c, 0xcf, 0xd4, 0xf8, 0x42, 0x8d, 0x7f, 0x9a, 0xa8, 0x65,
+	0x41, 0x4e, 0xd8, 0x05, 0x5d, 0x2f, 0xe7, 0x3e, 0x76, 0x85, 0xd3, 0xf0, 0x33, 0xf6, 0x8a, 0x9d,
+	0xf1, 0x19, 0x26, 0x2f, 0x60, 0xa8, 0xc0, 0xc4, 0x9b, 0x45, 0x4f, 0xd3, 0x12, 0xac, 0x46, 0x65,
+	0x21, 0x0b, 0xd8, 0x7b, 0x7a, 0xfa, 0xd5, 0xe0, 0x80, 0x0e, 0xe6, 0x87, 0x0f, 0x16, 0xff, 0x1b,
+	0xc6, 0xde, 0xd1, 0xb3, 0xe5, 0xba, 0x2f, 0x33, 0x8c, 0x17, 0xd3, 0x8d, 0x87, 0x80, 0xe3, 0x07,
+	0x9d, 0x2c, 0xf8, 0xb0, 0xb9, 0xb9, 0x4b, 0x82, 0xdb, 0xbb, 0x24, 0xb8, 0xd9, 0x25, 0xe4, 0x76,
+	0x97, 0x90, 0xdf, 0xbb, 0x84, 0x54, 0xc7, 0xde, 0xff, 0xf2, 0xcf, 0x00, 0x86, 0x53, 0xaa, 0xf3,
+	0x86, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -119,6 +175,7 @@ type RaftClient interface {
 	Snapshot(ctx context.Context, opts ...grpc.CallOption) (Raft_SnapshotClient, error)
 	Join(ctx context.Context, in *raftpb.Member, opts ...grpc.CallOption) (*raftpb.JoinResponse, error)
 	PromoteMember(ctx context.Context, in *raftpb.Member, opts ...grpc.CallOption) (*empty.Empty, error)
+	SnapshotOffset(ctx context.Context, in *SnapshotMeta, opts ...grpc.CallOption) (*SnapshotMeta, error)
 }
 
 type raftClient struct {
@@ -215,12 +272,22 @@ func (c *raftClient) PromoteMember(ctx context.Context, in *raftpb.Member, opts
 	return out, nil
 }
 
+func (c *raftClient) SnapshotOffset(ctx context.Context, in *SnapshotMeta, opts ...grpc.CallOption) (*SnapshotMeta, error) {
+	out := new(SnapshotMeta)
+	err := c.cc.Invoke(ctx, "/pb.Raft/SnapshotOffset", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RaftServer is the server API for Raft service.
 type RaftServer interface {
 	Message(Raft_MessageServer) error
 	Snapshot(Raft_SnapshotServer) error
 	Join(context.Context, *raftpb.Member) (*raftpb.JoinResponse, error)
 	PromoteMember(context.Context, *raftpb.Member) (*empty.Empty, error)
+	SnapshotOffset(context.Context, *SnapshotMeta) (*SnapshotMeta, error)
 }
 
 // UnimplementedRaftServer can be embedded to have forward compatible implementations.
@@ -239,6 +306,9 @@ func (*UnimplementedRaftServer) Join(ctx context.Context, req *raftpb.Member) (*
 func (*UnimplementedRaftServer) PromoteMember(ctx context.Context, req *raftpb.Member) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PromoteMember not implemented")
 }
+func (*UnimplementedRaftServer) SnapshotOffset(ctx context.Context, req *SnapshotMeta) (*SnapshotMeta, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SnapshotOffset not implemented")
+}
 
 func RegisterRaftServer(s *grpc.Server, srv RaftServer) {
 	s.RegisterService(&_Raft_serviceDesc, srv)
@@ -332,6 +402,24 @@ func _Raft_PromoteMember_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Raft_SnapshotOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotMeta)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftServer).SnapshotOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Raft/SnapshotOffset",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftServer).SnapshotOffset(ctx, req.(*SnapshotMeta))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Raft_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Raft",
 	HandlerType: (*RaftServer)(nil),
@@ -344,6 +432,10 @@ var _Raft_serviceDesc = grpc.ServiceDesc{
 			MethodName: "PromoteMember",
 			Handler:    _Raft_PromoteMember_Handler,
 		},
+		{
+			MethodName: "SnapshotOffset",
+			Handler:    _Raft_SnapshotOffset_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -384,6 +476,11 @@ func (m *Chunk) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Checksum != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Checksum))
+		i--
+		dAtA[i] = 0x18
+	}
 	if len(m.Data) > 0 {
 		i -= len(m.Data)
 		copy(dAtA[i:], m.Data)
@@ -399,6 +496,58 @@ func (m *Chunk) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *SnapshotMeta) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SnapshotMeta) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SnapshotMeta) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Compression {
+		i--
+		if m.Compression {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Offset != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Offset))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Index != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Index))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Term != 0 {
+		i = encodeVarintRaft(dAtA, i, uint64(m.Term))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintRaft(dAtA []byte, offset int, v uint64) int {
 	offset -= sovRaft(v)
 	base := offset
@@ -423,6 +572,33 @@ func (m *Chunk) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovRaft(uint64(l))
 	}
+	if m.Checksum != 0 {
+		n += 1 + sovRaft(uint64(m.Checksum))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SnapshotMeta) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Term != 0 {
+		n += 1 + sovRaft(uint64(m.Term))
+	}
+	if m.Index != 0 {
+		n += 1 + sovRaft(uint64(m.Index))
+	}
+	if m.Offset != 0 {
+		n += 1 + sovRaft(uint64(m.Offset))
+	}
+	if m.Compression {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -517,6 +693,153 @@ func (m *Chunk) Unmarshal(dAtA []byte) error {
 				m.Data = []byte{}
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checksum", wireType)
+			}
+			m.Checksum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Checksum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRaft(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRaft
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SnapshotMeta) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRaft
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SnapshotMeta: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SnapshotMeta: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			m.Term = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Term |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+			}
+			m.Offset = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Offset |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaft
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Compression = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaft(dAtA[iNdEx:])
@@ -4,16 +4,21 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/transport"
 	"github.com/shaj13/raft/internal/transport/raftgrpc/pb"
 	"github.com/shaj13/raft/raftlog"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -35,8 +40,13 @@ type handler struct {
 }
 
 func (h *handler) PromoteMember(ctx context.Context, m *raftpb.Member) (*empty.Empty, error) {
+	ctx, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	gid := groupID(ctx)
-	err := h.ctrl.PromoteMember(ctx, gid, *m)
+	err = h.ctrl.PromoteMember(withSource(ctx), gid, *m)
 	return &emptypb.Empty{}, err
 }
 
@@ -52,6 +62,21 @@ func (h *handler) Message(stream pb.Raft_MessageServer) (err error) {
 		}
 	}()
 
+	ctx, err := h.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	// advertise the supported compressions and the messages batches to the client,
+	// the clients of the members predating the batches never send them.
+	md := metadata.Pairs(
+		acceptCompHeader, transport.AcceptCompression(),
+		acceptBatchHeader, "true",
+	)
+	if err := stream.SetHeader(md); err != nil {
+		return err
+	}
+
 	for {
 		c, err := stream.Recv()
 		if err == io.EOF {
@@ -67,15 +92,16 @@ func (h *handler) Message(stream pb.Raft_MessageServer) (err error) {
 		}
 	}
 
-	ctx := stream.Context()
 	gid := groupID(ctx)
-	m := new(etcdraftpb.Message)
-	if err := m.Unmarshal(buf.Bytes()); err != nil {
+	msgs, err := messages(ctx, buf.Bytes())
+	if err != nil {
 		return err
 	}
 
-	if err := h.ctrl.Push(ctx, gid, *m); err != nil {
-		return err
+	for _, m := range msgs {
+		if err := h.ctrl.Push(ctx, gid, m); err != nil {
+			return err
+		}
 	}
 
 	return stream.SendAndClose(&emptypb.Empty{})
@@ -88,7 +114,11 @@ func (h *handler) Snapshot(stream pb.Raft_SnapshotServer) (err error) {
 		}
 	}()
 
-	ctx := stream.Context()
+	ctx, err := h.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+
 	gid := groupID(ctx)
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -110,9 +140,22 @@ func (h *handler) Snapshot(stream pb.Raft_SnapshotServer) (err error) {
 		return err
 	}
 
-	h.logger.V(2).Infof("raft.grpc: downloading sanpshot file [term: %d, index: %d]", term, index)
+	var offset uint64
+	if vals := md.Get(snapshotOffsetHeader); len(vals) > 0 {
+		offset, err = strconv.ParseUint(vals[0], 0, 64)
+		if err != nil {
+			return err
+		}
+	}
 
-	w, err := h.ctrl.SnapshotWriter(gid, term, index)
+	h.logger.V(2).Infof(
+		"raft.grpc: downloading sanpshot file [term: %d, index: %d, offset: %d]",
+		term,
+		index,
+		offset,
+	)
+
+	w, err := h.ctrl.SnapshotWriter(gid, term, index, offset)
 	if err != nil {
 		return err
 	}
@@ -135,9 +178,33 @@ func (h *handler) Snapshot(stream pb.Raft_SnapshotServer) (err error) {
 		}
 	}
 
+	if err := w.Commit(); err != nil {
+		return err
+	}
+
 	return stream.SendAndClose(&emptypb.Empty{})
 }
 
+func (h *handler) SnapshotOffset(ctx context.Context, m *pb.SnapshotMeta) (*pb.SnapshotMeta, error) {
+	ctx, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gid := groupID(ctx)
+	offset, err := h.ctrl.SnapshotOffset(gid, m.Term, m.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SnapshotMeta{
+		Term:        m.Term,
+		Index:       m.Index,
+		Offset:      offset,
+		Compression: true,
+	}, nil
+}
+
 func (h *handler) Join(ctx context.Context, m *raftpb.Member) (resp *raftpb.JoinResponse, err error) {
 	defer func() {
 		if err != nil {
@@ -145,10 +212,84 @@ func (h *handler) Join(ctx context.Context, m *raftpb.Member) (resp *raftpb.Join
 		}
 	}()
 
+	ctx, err = h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	gid := groupID(ctx)
 	h.logger.V(2).Infof("raft.grpc: new member asks to join the cluster on address %s", m.Address)
 
-	return h.ctrl.Join(ctx, gid, m)
+	return h.ctrl.Join(withSource(ctx), gid, m)
+}
+
+// authenticate returns a copy of the given ctx that carries the rpc credentials,
+// or an error if the controller rejects them.
+func (h *handler) authenticate(ctx context.Context) (context.Context, error) {
+	creds := transport.Credentials{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if vals := md.Get(authHeader); len(vals) > 0 {
+		creds.Token = strings.TrimPrefix(vals[0], bearerPrefix)
+	}
+
+	if vals := md.Get(clusterIDHeader); len(vals) > 0 {
+		creds.ClusterID, _ = strconv.ParseUint(vals[0], 10, 64)
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			creds.PeerCertificates = info.State.PeerCertificates
+		}
+	}
+
+	ctx = transport.ContextWithCredentials(ctx, creds)
+	if err := h.ctrl.Authenticate(ctx, groupID(ctx)); err != nil {
+		if !errors.Is(err, rafterrors.ErrUnauthenticated) {
+			err = fmt.Errorf("%w: %v", rafterrors.ErrUnauthenticated, err)
+		}
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// withSource returns a copy of the given ctx that carries the rpc peer address.
+func withSource(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ctx
+	}
+	return transport.ContextWithSource(ctx, p.Addr.String())
+}
+
+// messages unmarshal the received raft message, or messages when sent in a batch.
+func messages(ctx context.Context, data []byte) ([]etcdraftpb.Message, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if vals := md.Get(compressionHeader); len(vals) > 0 {
+		c, err := transport.ParseCompression(vals[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if data, err = transport.Decompress(c, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(md.Get(batchHeader)) > 0 {
+		batch := new(raftpb.MessageBatch)
+		if err := batch.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		return batch.Messages, nil
+	}
+
+	m := new(etcdraftpb.Message)
+	if err := m.Unmarshal(data); err != nil {
+		return nil, err
+	}
+
+	return []etcdraftpb.Message{*m}, nil
 }
 
 func groupID(ctx context.Context) uint64 {
@@ -3,6 +3,8 @@ package rafthttp
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +12,7 @@ import (
 	"strings"
 	"sync"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/transport"
 	"go.etcd.io/etcd/pkg/v3/pbutil"
@@ -17,12 +20,24 @@ import (
 )
 
 const (
-	snapshotHeader = "X-Raft-Snapshot"
-	groupIDHeader  = "X-Raft-Group-ID"
-	messageURI     = "/message"
-	snapshotURI    = "/snapshot"
-	joinURI        = "/join"
-	promoteURI     = "/promote"
+	snapshotHeader       = "X-Raft-Snapshot"
+	snapshotOffsetHeader = "X-Raft-Snapshot-Offset"
+	snapshotCompHeader   = "X-Raft-Snapshot-Compression"
+	snappyCompression    = "snappy"
+	groupIDHeader        = "X-Raft-Group-ID"
+	clusterIDHeader      = "X-Raft-Cluster-ID"
+	compressionHeader    = "X-Raft-Message-Compression"
+	acceptCompHeader     = "X-Raft-Accept-Compression"
+	authHeader           = "Authorization"
+	errReasonHeader      = "X-Raft-Error-Reason"
+	bearerPrefix         = "Bearer "
+	messageURI           = "/message"
+	messagesURI          = "/messages"
+	snapshotURI          = "/snapshot"
+	snapshotOffsetURI    = "/snapshot/offset"
+	joinURI              = "/join"
+	promoteURI           = "/promote"
+	healthzURI           = "/healthz"
 )
 
 var bufferPool = sync.Pool{
@@ -33,21 +48,30 @@ var bufferPool = sync.Pool{
 func Dialer(tr func(context.Context) http.RoundTripper, basePath string) transport.Dialer {
 	return func(cfg transport.Config) transport.Dial {
 		return func(ctx context.Context, addr string) (transport.Client, error) {
+			tr := tr
+			if tc := cfg.TLSConfig(); tc != nil {
+				tr = tlsTransport(tr(ctx), tc)
+			}
+
 			return &client{
-				transport: tr,
-				gid:       cfg.GroupID(),
-				url:       join(addr, basePath),
-				ctrl:      cfg.Controller(),
+				transport:  tr,
+				gid:        cfg.GroupID(),
+				url:        join(addr, basePath),
+				ctrl:       cfg.Controller(),
+				token:      cfg.AuthToken(),
+				compressor: transport.NewCompressor(cfg),
 			}, nil
 		}
 	}
 }
 
 type client struct {
-	transport func(context.Context) http.RoundTripper
-	gid       uint64
-	url       string
-	ctrl      transport.Controller
+	transport  func(context.Context) http.RoundTripper
+	gid        uint64
+	url        string
+	ctrl       transport.Controller
+	token      string
+	compressor *transport.Compressor
 }
 
 func (c *client) Close() (err error) { return }
@@ -79,15 +103,50 @@ func (c *client) PromoteMember(ctx context.Context, msg raftpb.Member) error {
 	return err
 }
 
+// Messages sends the given raft messages in a single rpc,
+// the messages must not contain snapshot messages.
+func (c *client) Messages(ctx context.Context, msgs []etcdraftpb.Message) error {
+	// nolint:bodyclose
+	_, err := c.requestMessage(ctx, messagesURI, &raftpb.MessageBatch{Messages: msgs})
+	return err
+}
+
 func (c *client) message(ctx context.Context, msg etcdraftpb.Message) error {
 	// nolint:bodyclose
-	_, err := c.requestProto(ctx, messageURI, &msg, nil)
+	_, err := c.requestMessage(ctx, messageURI, &msg)
 	return err
 }
 
 func (c *client) snapshot(ctx context.Context, msg etcdraftpb.Message) error {
 	meta := msg.Snapshot.Metadata
-	r, err := c.ctrl.SnapshotReader(c.gid, meta.Term, meta.Index)
+
+	// send only the snapshot key when published to the shared snapshot store,
+	// otherwise fallback to stream the snapshot files.
+	key, err := c.ctrl.SnapshotPublish(ctx, c.gid, meta.Term, meta.Index)
+	msg.Snapshot.Data = nil
+	if err == nil && key != "" {
+		msg.Snapshot.Data = []byte(key)
+		return c.message(ctx, msg)
+	}
+
+	chain, err := c.ctrl.SnapshotChain(c.gid, meta.Term, meta.Index)
+	if err != nil {
+		return err
+	}
+
+	// send the snapshots the delta snapshot chained onto before the snapshot itself.
+	for _, m := range chain {
+		if err := c.snapshotFile(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	return c.message(ctx, msg)
+}
+
+func (c *client) snapshotFile(ctx context.Context, meta etcdraftpb.SnapshotMetadata) error {
+	offset, compress := c.snapshotOffset(ctx, meta.Term, meta.Index)
+	r, err := c.ctrl.SnapshotReader(c.gid, meta.Term, meta.Index, offset, compress)
 	if err != nil {
 		return err
 	}
@@ -102,13 +161,35 @@ func (c *client) snapshot(ctx context.Context, msg etcdraftpb.Message) error {
 
 	req.Header.Add(snapshotHeader, strconv.FormatUint(meta.Term, 10))
 	req.Header.Add(snapshotHeader, strconv.FormatUint(meta.Index, 10))
+	req.Header.Set(snapshotOffsetHeader, strconv.FormatUint(offset, 10))
 
 	// nolint:bodyclose
-	if _, err := c.roundTrip(ctx, req, nil); err != nil {
-		return err
+	_, err = c.roundTrip(ctx, req, nil)
+	return err
+}
+
+// snapshotOffset returns the number of snapshot bytes already received by the remote member,
+// to resume an interrupted transfer, and whether the remote member accepts compressed snapshots.
+// it returns 0 when the remote member does not support resuming.
+func (c *client) snapshotOffset(ctx context.Context, term, index uint64) (uint64, bool) {
+	u := join(c.url, snapshotOffsetURI)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return 0, false
 	}
 
-	return c.message(ctx, msg)
+	req.Header.Add(snapshotHeader, strconv.FormatUint(term, 10))
+	req.Header.Add(snapshotHeader, strconv.FormatUint(index, 10))
+
+	// nolint:bodyclose
+	res, err := c.roundTrip(ctx, req, nil)
+	if err != nil {
+		return 0, false
+	}
+
+	offset, _ := strconv.ParseUint(res.Header.Get(snapshotOffsetHeader), 0, 64)
+	compress := res.Header.Get(snapshotCompHeader) == snappyCompression
+	return offset, compress
 }
 
 func (c *client) requestProto(
@@ -137,9 +218,42 @@ func (c *client) requestProto(
 	return c.roundTrip(ctx, req, out)
 }
 
+// requestMessage sends the given raft message or messages batch,
+// compressed when the compression negotiated with the member handler.
+func (c *client) requestMessage(ctx context.Context, uri string, in pbutil.Marshaler) (*http.Response, error) {
+	data, err := in.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	data, comp, err := c.compressor.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	u := join(c.url, uri)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if comp != raftpb.NoCompression {
+		req.Header.Set(compressionHeader, comp.String())
+	}
+
+	return c.roundTrip(ctx, req, nil)
+}
+
 func (c *client) roundTrip(ctx context.Context, req *http.Request, out pbutil.Unmarshaler) (*http.Response, error) {
 	gid := strconv.FormatUint(c.gid, 10)
 	req.Header.Set(groupIDHeader, gid)
+	if id := c.ctrl.ClusterID(c.gid); id != 0 {
+		req.Header.Set(clusterIDHeader, strconv.FormatUint(id, 10))
+	}
+
+	if len(c.token) > 0 {
+		req.Header.Set(authHeader, bearerPrefix+c.token)
+	}
 
 	res, err := c.transport(ctx).RoundTrip(req)
 	if err != nil {
@@ -148,6 +262,9 @@ func (c *client) roundTrip(ctx context.Context, req *http.Request, out pbutil.Un
 
 	defer res.Body.Close()
 
+	// negotiate the compression from the member handler advertised compressions.
+	c.compressor.Accept(res.Header.Get(acceptCompHeader))
+
 	// return if rpc does not return response.
 	if res.StatusCode == http.StatusNoContent && out == nil {
 		return res, nil
@@ -163,7 +280,11 @@ func (c *client) roundTrip(ctx context.Context, req *http.Request, out pbutil.Un
 	}
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("raft/http: server returned: %v : %v", res.Status, b.String())
+		msg := fmt.Sprintf("raft/http: server returned: %v : %v", res.Status, b.String())
+		if rerr := rafterrors.FromReason(res.Header.Get(errReasonHeader), msg); rerr != nil {
+			return nil, rerr
+		}
+		return nil, errors.New(msg)
 	}
 
 	err = out.Unmarshal(b.Bytes())
@@ -174,6 +295,22 @@ func (c *client) roundTrip(ctx context.Context, req *http.Request, out pbutil.Un
 	return res, nil
 }
 
+// tlsTransport returns a copy of the given round tripper that uses the given TLS config,
+// or a copy of the http.DefaultTransport if the given round tripper is not an *http.Transport.
+func tlsTransport(rt http.RoundTripper, tc *tls.Config) func(context.Context) http.RoundTripper {
+	tr, ok := rt.(*http.Transport)
+	if !ok {
+		tr = http.DefaultTransport.(*http.Transport)
+	}
+
+	tr = tr.Clone()
+	tr.TLSClientConfig = tc.Clone()
+
+	return func(context.Context) http.RoundTripper {
+		return tr
+	}
+}
+
 func join(u, p string) string {
 	u = strings.TrimSuffix(u, "/")
 	p = strings.TrimPrefix(p, "/")
@@ -4,6 +4,7 @@ package rafthttp
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,8 +16,10 @@ import (
 	"github.com/stretchr/testify/require"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	transportmock "github.com/shaj13/raft/internal/mocks/transport"
 	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
 	"github.com/shaj13/raft/raftlog"
 )
 
@@ -45,6 +48,8 @@ func TestMessage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
 			rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
 			srv.ctrl = rpcCtrl
 			err := c.Message(context.Background(), etcdraftpb.Message{})
@@ -55,6 +60,90 @@ func TestMessage(t *testing.T) {
 	}
 }
 
+func TestMessages(t *testing.T) {
+	ts, c, srv := testClientServer(t)
+	defer ts.Close()
+	defer c.Close()
+
+	msgs := []etcdraftpb.Message{
+		{Type: etcdraftpb.MsgApp, To: 1},
+		{Type: etcdraftpb.MsgHeartbeat, To: 1},
+	}
+
+	table := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "it return nil error when server process msgs",
+			err:  nil,
+		},
+		{
+			name: "it return error when server return error",
+			err:  fmt.Errorf("TestMessages Error"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []etcdraftpb.Message
+			ctrl := gomock.NewController(t)
+			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.
+				EXPECT().
+				Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ uint64, m etcdraftpb.Message) error {
+					got = append(got, m)
+					return tt.err
+				}).
+				MinTimes(1)
+			srv.ctrl = rpcCtrl
+			err := c.Messages(context.Background(), msgs)
+			if tt.err != nil {
+				require.Contains(t, err.Error(), tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, msgs, got)
+		})
+	}
+}
+
+func TestCompression(t *testing.T) {
+	ts, c, srv := testClientServer(t)
+	defer ts.Close()
+	defer c.Close()
+
+	ctrl := gomock.NewController(t)
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().MessageCompression().Return(raftpb.ZstdCompression)
+	cfg.EXPECT().CompressionThreshold().Return(0)
+	c.compressor = transport.NewCompressor(cfg)
+
+	msg := etcdraftpb.Message{
+		Type:    etcdraftpb.MsgApp,
+		Entries: []etcdraftpb.Entry{{Data: bytes.Repeat([]byte("raft"), 100)}},
+	}
+
+	rpcCtrl := transportmock.NewMockController(ctrl)
+
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Eq(msg)).Return(nil).Times(2)
+	srv.ctrl = rpcCtrl
+
+	// it negotiate the compression by the first message, and compress the next messages.
+	for i := 0; i < 2; i++ {
+		err := c.Message(context.Background(), msg)
+		require.NoError(t, err)
+	}
+
+	_, comp, _ := c.compressor.Compress(nil)
+	require.Equal(t, raftpb.ZstdCompression, comp)
+}
+
 func TestJoin(t *testing.T) {
 	ts, c, srv := testClientServer(t)
 	defer ts.Close()
@@ -84,7 +173,16 @@ func TestJoin(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			rpcCtrl := transportmock.NewMockController(ctrl)
-			rpcCtrl.EXPECT().Join(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.resp, tt.err)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.
+				EXPECT().
+				Join(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ uint64, _ *raftpb.Member) (*raftpb.JoinResponse, error) {
+					// it propagate the request source address.
+					require.NotEmpty(t, transport.SourceFromContext(ctx))
+					return tt.resp, tt.err
+				})
 			srv.ctrl = rpcCtrl
 			resp, err := c.Join(context.Background(), raftpb.Member{})
 			require.Equal(t, tt.resp, resp)
@@ -121,14 +219,30 @@ func TestSnapshot(t *testing.T) {
 			ctrl := gomock.NewController(t)
 
 			rpcCtrl := transportmock.NewMockController(ctrl)
+
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
 			rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
 			rpcCtrl.
 				EXPECT().
-				SnapshotReader(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				SnapshotPublish(gomock.Any(), gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return("", nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotChain(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return([]etcdraftpb.SnapshotMetadata{{Term: 1, Index: 1}}, nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotOffset(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return(uint64(5), nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotReader(gomock.Eq(testGroupID), gomock.Any(), gomock.Any(), gomock.Eq(uint64(5)), gomock.Eq(true)).
 				Return(io.NopCloser(strings.NewReader(snapData)), nil)
 			rpcCtrl.
 				EXPECT().
-				SnapshotWriter(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				SnapshotWriter(gomock.Eq(testGroupID), gomock.Any(), gomock.Any(), gomock.Eq(uint64(5))).
 				Return(writeCloser{buf}, nil)
 
 			srv.ctrl = rpcCtrl
@@ -143,6 +257,37 @@ func TestSnapshot(t *testing.T) {
 	}
 }
 
+func TestSnapshotPublished(t *testing.T) {
+	ts, c, srv := testClientServer(t)
+	defer ts.Close()
+	defer c.Close()
+
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	rpcCtrl.
+		EXPECT().
+		SnapshotPublish(gomock.Any(), gomock.Eq(testGroupID), gomock.Eq(uint64(1)), gomock.Eq(uint64(2))).
+		Return("key", nil)
+	rpcCtrl.
+		EXPECT().
+		Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, gid uint64, m etcdraftpb.Message) error {
+			// it send only the snapshot key.
+			require.Equal(t, []byte("key"), m.Snapshot.Data)
+			return nil
+		})
+
+	srv.ctrl = rpcCtrl
+	c.ctrl = rpcCtrl
+
+	msg := etcdraftpb.Message{Type: etcdraftpb.MsgSnap}
+	msg.Snapshot.Metadata = etcdraftpb.SnapshotMetadata{Term: 1, Index: 2}
+	err := c.snapshot(context.Background(), msg)
+	require.NoError(t, err)
+}
+
 func TestPromoteMember(t *testing.T) {
 	ts, c, srv := testClientServer(t)
 	defer ts.Close()
@@ -166,6 +311,8 @@ func TestPromoteMember(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
 			rpcCtrl.EXPECT().PromoteMember(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
 			srv.ctrl = rpcCtrl
 			err := c.PromoteMember(context.Background(), raftpb.Member{})
@@ -176,6 +323,122 @@ func TestPromoteMember(t *testing.T) {
 	}
 }
 
+func TestAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+
+	srv := new(handler)
+	srv.logger = raftlog.DefaultLogger
+	srv.ctrl = rpcCtrl
+	ts := httptest.NewServer(mux(srv, ""))
+	defer ts.Close()
+
+	clientCtrl := transportmock.NewMockController(ctrl)
+	clientCtrl.EXPECT().ClusterID(gomock.Eq(testGroupID)).Return(uint64(7)).AnyTimes()
+
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().Controller().Return(clientCtrl).AnyTimes()
+	cfg.EXPECT().GroupID().Return(testGroupID).AnyTimes()
+	cfg.EXPECT().TLSConfig().AnyTimes()
+	cfg.EXPECT().AuthToken().Return("secret").AnyTimes()
+	cfg.EXPECT().MessageCompression().AnyTimes()
+	cfg.EXPECT().CompressionThreshold().AnyTimes()
+
+	tr := func(context.Context) http.RoundTripper {
+		return http.DefaultTransport
+	}
+
+	c, err := Dialer(tr, "")(cfg)(context.TODO(), ts.URL)
+	require.NoError(t, err)
+
+	// it send the token and the cluster id and the controller accept them.
+	rpcCtrl.
+		EXPECT().
+		Authenticate(gomock.Any(), gomock.Eq(testGroupID)).
+		DoAndReturn(func(ctx context.Context, gid uint64) error {
+			creds := transport.CredentialsFromContext(ctx)
+			require.Equal(t, "secret", creds.Token)
+			require.Equal(t, uint64(7), creds.ClusterID)
+			return nil
+		})
+
+	err = c.Message(context.TODO(), etcdraftpb.Message{})
+	require.NoError(t, err)
+
+	// it return error when the controller reject the credentials.
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(errors.New("denied"))
+	err = c.Message(context.TODO(), etcdraftpb.Message{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401")
+	require.ErrorIs(t, err, rafterrors.ErrUnauthenticated)
+}
+
+func TestTLS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+
+	srv := new(handler)
+	srv.logger = raftlog.DefaultLogger
+	srv.ctrl = rpcCtrl
+	ts := httptest.NewTLSServer(mux(srv, ""))
+	defer ts.Close()
+
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().Controller().Return(testController(ctrl)).AnyTimes()
+	cfg.EXPECT().GroupID().Return(testGroupID).AnyTimes()
+	cfg.EXPECT().AuthToken().AnyTimes()
+	cfg.EXPECT().MessageCompression().AnyTimes()
+	cfg.EXPECT().CompressionThreshold().AnyTimes()
+
+	tr := func(context.Context) http.RoundTripper {
+		return http.DefaultTransport
+	}
+
+	// it fails to verify the server certificate without the TLS config.
+	cfg.EXPECT().TLSConfig()
+	c, err := Dialer(tr, "")(cfg)(context.TODO(), ts.URL)
+	require.NoError(t, err)
+	err = c.Message(context.TODO(), etcdraftpb.Message{})
+	require.Error(t, err)
+
+	// it dial the server using the TLS config.
+	tc := ts.Client().Transport.(*http.Transport).TLSClientConfig
+	cfg.EXPECT().TLSConfig().Return(tc)
+	c, err = Dialer(tr, "")(cfg)(context.TODO(), ts.URL)
+	require.NoError(t, err)
+	err = c.Message(context.TODO(), etcdraftpb.Message{})
+	require.NoError(t, err)
+}
+
+func TestHealthz(t *testing.T) {
+	ts, _, srv := testClientServer(t)
+	defer ts.Close()
+
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	srv.ctrl = rpcCtrl
+
+	// it return ok when the node is serving.
+	rpcCtrl.EXPECT().Health(gomock.Eq(uint64(0))).Return(nil)
+	res, err := ts.Client().Get(ts.URL + healthzURI)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// it return unavailable when the group node is not serving.
+	rpcCtrl.EXPECT().Health(gomock.Eq(testGroupID)).Return(rafterrors.ErrNoLeader)
+	res, err = ts.Client().Get(ts.URL + healthzURI + "?group=1")
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
+
 func testClientServer(tb testing.TB) (*httptest.Server, *client, *handler) {
 	srv := new(handler)
 	srv.logger = raftlog.DefaultLogger
@@ -184,8 +447,12 @@ func testClientServer(tb testing.TB) (*httptest.Server, *client, *handler) {
 	ctx := context.TODO()
 	ctrl := gomock.NewController(tb)
 	cfg := transportmock.NewMockConfig(ctrl)
-	cfg.EXPECT().Controller()
+	cfg.EXPECT().Controller().Return(testController(ctrl))
 	cfg.EXPECT().GroupID().Return(testGroupID).AnyTimes()
+	cfg.EXPECT().TLSConfig()
+	cfg.EXPECT().AuthToken().AnyTimes()
+	cfg.EXPECT().MessageCompression().AnyTimes()
+	cfg.EXPECT().CompressionThreshold().AnyTimes()
 
 	tr := func(context.Context) http.RoundTripper {
 		return testRoundTripper{ts.Client()}
@@ -207,6 +474,10 @@ func (writeCloser) Close() error {
 	return nil
 }
 
+func (writeCloser) Commit() error {
+	return nil
+}
+
 type testRoundTripper struct {
 	c *http.Client
 }
@@ -214,3 +485,10 @@ type testRoundTripper struct {
 func (trt testRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 	return trt.c.Do(r)
 }
+
+// testController returns a client side controller mock, of a node that belongs to no cluster.
+func testController(ctrl *gomock.Controller) *transportmock.MockController {
+	c := transportmock.NewMockController(ctrl)
+	c.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	return c
+}
@@ -1,14 +1,18 @@
 package rafthttp
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"go.etcd.io/etcd/pkg/v3/pbutil"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/transport"
 	"github.com/shaj13/raft/raftlog"
@@ -33,7 +37,7 @@ type handler struct {
 func (h *handler) message(w http.ResponseWriter, r *http.Request) (int, error) {
 	gid := groupID(r)
 	msg := new(etcdraftpb.Message)
-	if code, err := decode(r.Body, msg); err != nil {
+	if code, err := decodeMessage(w, r, msg); err != nil {
 		return code, err
 	}
 
@@ -44,27 +48,46 @@ func (h *handler) message(w http.ResponseWriter, r *http.Request) (int, error) {
 	return http.StatusNoContent, nil
 }
 
-func (h *handler) snapshot(w http.ResponseWriter, r *http.Request) (int, error) {
+func (h *handler) messages(w http.ResponseWriter, r *http.Request) (int, error) {
 	gid := groupID(r)
+	batch := new(raftpb.MessageBatch)
+	if code, err := decodeMessage(w, r, batch); err != nil {
+		return code, err
+	}
 
-	vals := r.Header.Values(snapshotHeader)
-	if len(vals) < 2 {
-		return http.StatusBadRequest, errors.New("raft/http: snapshot header missing")
+	for _, msg := range batch.Messages {
+		if err := h.ctrl.Push(r.Context(), gid, msg); err != nil {
+			return http.StatusInternalServerError, err
+		}
 	}
 
-	term, err := strconv.ParseUint(vals[0], 0, 64)
+	return http.StatusNoContent, nil
+}
+
+func (h *handler) snapshot(w http.ResponseWriter, r *http.Request) (int, error) {
+	gid := groupID(r)
+
+	term, index, err := snapshotMeta(r)
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
 
-	index, err := strconv.ParseUint(vals[1], 0, 64)
-	if err != nil {
-		return http.StatusBadRequest, err
+	var offset uint64
+	if str := r.Header.Get(snapshotOffsetHeader); len(str) > 0 {
+		offset, err = strconv.ParseUint(str, 0, 64)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
 	}
 
-	h.logger.V(2).Infof("raft.http: downloading sanpshot file [term: %d, index: %d]", term, index)
+	h.logger.V(2).Infof(
+		"raft.http: downloading sanpshot file [term: %d, index: %d, offset: %d]",
+		term,
+		index,
+		offset,
+	)
 
-	wr, err := h.ctrl.SnapshotWriter(gid, term, index)
+	wr, err := h.ctrl.SnapshotWriter(gid, term, index, offset)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -76,6 +99,28 @@ func (h *handler) snapshot(w http.ResponseWriter, r *http.Request) (int, error)
 		return http.StatusInternalServerError, err
 	}
 
+	if err := wr.Commit(); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func (h *handler) snapshotOffset(w http.ResponseWriter, r *http.Request) (int, error) {
+	gid := groupID(r)
+
+	term, index, err := snapshotMeta(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	offset, err := h.ctrl.SnapshotOffset(gid, term, index)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	w.Header().Set(snapshotOffsetHeader, strconv.FormatUint(offset, 10))
+	w.Header().Set(snapshotCompHeader, snappyCompression)
 	return http.StatusNoContent, nil
 }
 
@@ -88,7 +133,8 @@ func (h *handler) join(w http.ResponseWriter, r *http.Request) (int, error) {
 
 	h.logger.V(2).Infof("raft.http: new member asks to join the cluster on address %s", m.Address)
 
-	resp, err := h.ctrl.Join(r.Context(), gid, m)
+	ctx := transport.ContextWithSource(r.Context(), r.RemoteAddr)
+	resp, err := h.ctrl.Join(ctx, gid, m)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -110,13 +156,38 @@ func (h *handler) promoteMember(w http.ResponseWriter, r *http.Request) (int, er
 		return code, err
 	}
 
-	if err := h.ctrl.PromoteMember(r.Context(), gid, *m); err != nil {
+	ctx := transport.ContextWithSource(r.Context(), r.RemoteAddr)
+	if err := h.ctrl.PromoteMember(ctx, gid, *m); err != nil {
 		return http.StatusInternalServerError, err
 	}
 
 	return http.StatusNoContent, nil
 }
 
+// healthz reflects the health of the raft node, associated to the group id
+// in the request header or the group query parameter, or the default group when absent.
+// It responds with 200 when the node is serving, otherwise 503.
+func (h *handler) healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		code := http.StatusMethodNotAllowed
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	gid := groupID(r)
+	if str := r.URL.Query().Get("group"); len(str) > 0 {
+		gid, _ = strconv.ParseUint(str, 0, 64)
+	}
+
+	if err := h.ctrl.Health(gid); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, "ok")
+}
+
 type handlerFunc func(w http.ResponseWriter, r *http.Request) (int, error)
 
 func httpHandler(h handlerFunc, logger raftlog.Logger) http.HandlerFunc {
@@ -130,6 +201,10 @@ func httpHandler(h handlerFunc, logger raftlog.Logger) http.HandlerFunc {
 		code, err := h(w, r)
 		if err != nil {
 			logger.Infof("raft.http: handle %s: %v", r.URL.Path, err)
+			// advertise the typed error reason, so the client can restore it.
+			if reason := rafterrors.ReasonOf(err); reason != "" {
+				w.Header().Set(errReasonHeader, reason)
+			}
 			http.Error(w, err.Error(), code)
 			return
 		}
@@ -141,12 +216,42 @@ func httpHandler(h handlerFunc, logger raftlog.Logger) http.HandlerFunc {
 	})
 }
 
+// authenticate returns handlerFunc that calls h, only if the controller accepts the request credentials.
+func (s *handler) authenticate(h handlerFunc) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) (int, error) {
+		creds := transport.Credentials{
+			Token: strings.TrimPrefix(r.Header.Get(authHeader), bearerPrefix),
+		}
+
+		if r.TLS != nil {
+			creds.PeerCertificates = r.TLS.PeerCertificates
+		}
+
+		if str := r.Header.Get(clusterIDHeader); len(str) > 0 {
+			creds.ClusterID, _ = strconv.ParseUint(str, 10, 64)
+		}
+
+		ctx := transport.ContextWithCredentials(r.Context(), creds)
+		if err := s.ctrl.Authenticate(ctx, groupID(r)); err != nil {
+			if !errors.Is(err, rafterrors.ErrUnauthenticated) {
+				err = fmt.Errorf("%w: %v", rafterrors.ErrUnauthenticated, err)
+			}
+			return http.StatusUnauthorized, err
+		}
+
+		return h(w, r.WithContext(ctx))
+	}
+}
+
 func mux(s *handler, basePath string) http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc(join(basePath, messageURI), httpHandler(s.message, s.logger))
-	mux.HandleFunc(join(basePath, snapshotURI), httpHandler(s.snapshot, s.logger))
-	mux.HandleFunc(join(basePath, joinURI), httpHandler(s.join, s.logger))
-	mux.HandleFunc(join(basePath, promoteURI), httpHandler(s.promoteMember, s.logger))
+	mux.HandleFunc(join(basePath, messageURI), httpHandler(s.authenticate(s.message), s.logger))
+	mux.HandleFunc(join(basePath, messagesURI), httpHandler(s.authenticate(s.messages), s.logger))
+	mux.HandleFunc(join(basePath, snapshotURI), httpHandler(s.authenticate(s.snapshot), s.logger))
+	mux.HandleFunc(join(basePath, snapshotOffsetURI), httpHandler(s.authenticate(s.snapshotOffset), s.logger))
+	mux.HandleFunc(join(basePath, joinURI), httpHandler(s.authenticate(s.join), s.logger))
+	mux.HandleFunc(join(basePath, promoteURI), httpHandler(s.authenticate(s.promoteMember), s.logger))
+	mux.HandleFunc(join(basePath, healthzURI), s.healthz)
 	return mux
 }
 
@@ -163,6 +268,43 @@ func decode(r io.Reader, u pbutil.Unmarshaler) (int, error) {
 	return 0, nil
 }
 
+// decodeMessage decodes the request raft message or messages batch, decompressed when compressed,
+// and advertise the supported compressions to the client.
+func decodeMessage(w http.ResponseWriter, r *http.Request, u pbutil.Unmarshaler) (int, error) {
+	w.Header().Set(acceptCompHeader, transport.AcceptCompression())
+
+	c, err := transport.ParseCompression(r.Header.Get(compressionHeader))
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return http.StatusPreconditionFailed, err
+	}
+
+	if data, err = transport.Decompress(c, data); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	return decode(bytes.NewReader(data), u)
+}
+
+func snapshotMeta(r *http.Request) (term, index uint64, err error) {
+	vals := r.Header.Values(snapshotHeader)
+	if len(vals) < 2 {
+		return 0, 0, errors.New("raft/http: snapshot header missing")
+	}
+
+	term, err = strconv.ParseUint(vals[0], 0, 64)
+	if err != nil {
+		return
+	}
+
+	index, err = strconv.ParseUint(vals[1], 0, 64)
+	return
+}
+
 func groupID(r *http.Request) uint64 {
 	str := r.Header.Get(groupIDHeader)
 	gid, _ := strconv.ParseUint(str, 0, 64)
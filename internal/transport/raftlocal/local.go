@@ -0,0 +1,178 @@
+package raftlocal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/internal/transport/raftgrpc"
+)
+
+const (
+	// UnixScheme is the address scheme of the unix domain sockets, e.g. unix:///tmp/raft.sock.
+	UnixScheme = "unix://"
+	// PipeScheme is the address scheme of the in-process pipes, e.g. pipe://node-1.
+	PipeScheme = "pipe://"
+)
+
+var _ net.Listener = &pipeListener{}
+
+var errListenerClosed = errors.New("raft/local: listener closed")
+
+// pipes is the registry of the in-process pipe listeners.
+var pipes = struct {
+	mu sync.Mutex
+	m  map[string]*pipeListener
+}{
+	m: make(map[string]*pipeListener),
+}
+
+// NewHandler return an local transport Handler.
+//
+// NewHandler compatible with transport.NewHandler.
+func NewHandler(cfg transport.Config) transport.Handler {
+	return raftgrpc.NewHandler(cfg)
+}
+
+// Dialer return's local dialer.
+//
+// The local transport reuses the gRPC rpc's over unix domain sockets or in-process pipes,
+// therefore the connections are insecure unless a TLS config set.
+func Dialer(
+	dopts func(context.Context) []grpc.DialOption,
+	copts func(context.Context) []grpc.CallOption,
+) transport.Dialer {
+	opts := func(ctx context.Context) []grpc.DialOption {
+		return append(
+			[]grpc.DialOption{
+				grpc.WithContextDialer(Dial),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			},
+			dopts(ctx)...,
+		)
+	}
+
+	dialer := raftgrpc.Dialer(opts, copts)
+
+	return func(cfg transport.Config) transport.Dial {
+		dial := dialer(cfg)
+		return func(ctx context.Context, addr string) (transport.Client, error) {
+			// passthrough the address as is to the context dialer.
+			return dial(ctx, "passthrough:///"+addr)
+		}
+	}
+}
+
+// Listen announces on the given unix domain socket or in-process pipe address.
+func Listen(addr string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(addr, UnixScheme):
+		return net.Listen("unix", strings.TrimPrefix(addr, UnixScheme))
+	case strings.HasPrefix(addr, PipeScheme):
+		return listenPipe(strings.TrimPrefix(addr, PipeScheme))
+	default:
+		return nil, fmt.Errorf("raft/local: unsupported address %s", addr)
+	}
+}
+
+// Dial connects to the given unix domain socket or in-process pipe address.
+func Dial(ctx context.Context, addr string) (net.Conn, error) {
+	switch {
+	case strings.HasPrefix(addr, UnixScheme):
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", strings.TrimPrefix(addr, UnixScheme))
+	case strings.HasPrefix(addr, PipeScheme):
+		return dialPipe(ctx, strings.TrimPrefix(addr, PipeScheme))
+	default:
+		return nil, fmt.Errorf("raft/local: unsupported address %s", addr)
+	}
+}
+
+func listenPipe(name string) (net.Listener, error) {
+	pipes.mu.Lock()
+	defer pipes.mu.Unlock()
+
+	if _, ok := pipes.m[name]; ok {
+		return nil, fmt.Errorf("raft/local: pipe %s already in use", name)
+	}
+
+	ln := &pipeListener{
+		name:  name,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+
+	pipes.m[name] = ln
+	return ln, nil
+}
+
+func dialPipe(ctx context.Context, name string) (net.Conn, error) {
+	pipes.mu.Lock()
+	ln, ok := pipes.m[name]
+	pipes.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("raft/local: pipe %s not found", name)
+	}
+
+	client, server := net.Pipe()
+
+	select {
+	case ln.conns <- server:
+		return client, nil
+	case <-ln.done:
+		return nil, errListenerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// pipeListener implements net.Listener over in-process pipes.
+type pipeListener struct {
+	name  string
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (ln *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-ln.conns:
+		return conn, nil
+	case <-ln.done:
+		return nil, errListenerClosed
+	}
+}
+
+func (ln *pipeListener) Close() error {
+	ln.once.Do(func() {
+		pipes.mu.Lock()
+		delete(pipes.m, ln.name)
+		pipes.mu.Unlock()
+		close(ln.done)
+	})
+
+	return nil
+}
+
+func (ln *pipeListener) Addr() net.Addr {
+	return pipeAddr(ln.name)
+}
+
+// pipeAddr implements net.Addr for the in-process pipes.
+type pipeAddr string
+
+func (pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (a pipeAddr) String() string {
+	return PipeScheme + string(a)
+}
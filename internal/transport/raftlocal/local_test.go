@@ -0,0 +1,145 @@
+package raftlocal
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+	"google.golang.org/grpc"
+
+	transportmock "github.com/shaj13/raft/internal/mocks/transport"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport/raftgrpc/pb"
+	"github.com/shaj13/raft/raftlog"
+)
+
+const testGroupID = uint64(1)
+
+func TestListen(t *testing.T) {
+	// it return error when address scheme unsupported.
+	_, err := Listen("127.0.0.1:8080")
+	require.Error(t, err)
+
+	// it return error when pipe already in use.
+	ln, err := Listen("pipe://TestListen")
+	require.NoError(t, err)
+	_, err = Listen("pipe://TestListen")
+	require.Error(t, err)
+
+	// it release the pipe name when listener closed.
+	require.NoError(t, ln.Close())
+	ln, err = Listen("pipe://TestListen")
+	require.NoError(t, err)
+	require.Equal(t, "pipe://TestListen", ln.Addr().String())
+	require.NoError(t, ln.Close())
+}
+
+func TestDial(t *testing.T) {
+	table := []struct {
+		name string
+		addr string
+	}{
+		{
+			name: "it connects over in-process pipe",
+			addr: "pipe://TestDial",
+		},
+		{
+			name: "it connects over unix domain socket",
+			addr: "unix://" + filepath.Join(t.TempDir(), "raft.sock"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := Listen(tt.addr)
+			require.NoError(t, err)
+			defer ln.Close()
+
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+
+			conn, err := Dial(context.Background(), tt.addr)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("ping"))
+			require.NoError(t, err)
+
+			buf := make([]byte, 4)
+			_, err = io.ReadFull(conn, buf)
+			require.NoError(t, err)
+			require.Equal(t, "ping", string(buf))
+		})
+	}
+
+	// it return error when pipe not found.
+	_, err := Dial(context.Background(), "pipe://TestDial")
+	require.Error(t, err)
+}
+
+func TestClientServer(t *testing.T) {
+	table := []struct {
+		name string
+		addr string
+	}{
+		{
+			name: "it serve rpc's over in-process pipe",
+			addr: "pipe://TestClientServer",
+		},
+		{
+			name: "it serve rpc's over unix domain socket",
+			addr: "unix://" + filepath.Join(t.TempDir(), "raft.sock"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+			rpcCtrl.EXPECT().PromoteMember(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+
+			cfg := transportmock.NewMockConfig(ctrl)
+			cfg.EXPECT().Controller().Return(rpcCtrl).AnyTimes()
+			cfg.EXPECT().Logger().Return(raftlog.DefaultLogger)
+			cfg.EXPECT().GroupID().Return(testGroupID).AnyTimes()
+			cfg.EXPECT().TLSConfig()
+			cfg.EXPECT().AuthToken().AnyTimes()
+			cfg.EXPECT().MessageCompression().AnyTimes()
+			cfg.EXPECT().CompressionThreshold().AnyTimes()
+
+			ln, err := Listen(tt.addr)
+			require.NoError(t, err)
+
+			server := grpc.NewServer()
+			pb.RegisterRaftServer(server, NewHandler(cfg).(pb.RaftServer))
+			go func() { _ = server.Serve(ln) }()
+			defer server.Stop()
+
+			dopts := func(context.Context) []grpc.DialOption { return nil }
+			copts := func(context.Context) []grpc.CallOption { return nil }
+
+			c, err := Dialer(dopts, copts)(cfg)(context.Background(), tt.addr)
+			require.NoError(t, err)
+			defer c.Close()
+
+			err = c.Message(context.Background(), etcdraftpb.Message{})
+			require.NoError(t, err)
+
+			err = c.PromoteMember(context.Background(), raftpb.Member{})
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package raftmux
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// sniffTimeout is the max duration to wait for the first bytes of a connection,
+// to detect its protocol.
+const sniffTimeout = 10 * time.Second
+
+// http2Preface is the client connection preface of HTTP/2, sent first by the gRPC clients.
+var http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// ErrListenerClosed is returned by the mux listeners Accept method when the listener or mux closed.
+var ErrListenerClosed = errors.New("raft/mux: listener closed")
+
+var _ net.Listener = &listener{}
+
+// Mux multiplexes the connections accepted on a single listener, by sniffing their first bytes,
+// the HTTP/2 connections (gRPC) dispatched to the GRPC listener, and the others to the HTTP listener.
+//
+// The sniffing requires plain connections, therefore TLS must be terminated
+// by the given listener, e.g. tls.NewListener.
+type Mux struct {
+	root net.Listener
+	grpc *listener
+	http *listener
+	done chan struct{}
+	once sync.Once
+}
+
+// New return's a new Mux that dispatches the connections accepted on the given listener.
+func New(ln net.Listener) *Mux {
+	m := &Mux{
+		root: ln,
+		done: make(chan struct{}),
+	}
+	m.grpc = newListener(ln.Addr())
+	m.http = newListener(ln.Addr())
+	return m
+}
+
+// GRPC return's the listener of the HTTP/2 connections, e.g. gRPC.
+func (m *Mux) GRPC() net.Listener {
+	return m.grpc
+}
+
+// HTTP return's the listener of the connections other than HTTP/2, e.g. HTTP/1.x.
+func (m *Mux) HTTP() net.Listener {
+	return m.http
+}
+
+// Serve accepts the connections on the mux listener and dispatches them,
+// it blocks until the mux listener fails or the mux closed.
+func (m *Mux) Serve() error {
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			select {
+			case <-m.done:
+				return nil
+			default:
+			}
+			_ = m.Close()
+			return err
+		}
+
+		go m.dispatch(conn)
+	}
+}
+
+// Close closes the mux listener, and the GRPC and HTTP listeners.
+func (m *Mux) Close() (err error) {
+	m.once.Do(func() {
+		close(m.done)
+		err = m.root.Close()
+		_ = m.grpc.Close()
+		_ = m.http.Close()
+	})
+
+	return err
+}
+
+func (m *Mux) dispatch(conn net.Conn) {
+	buf := make([]byte, len(http2Preface))
+	n := 0
+
+	// read until the bytes diverge from the preface, so short HTTP/1.x requests never wait.
+	_ = conn.SetReadDeadline(time.Now().Add(sniffTimeout))
+	for n < len(buf) && bytes.Equal(buf[:n], http2Preface[:n]) {
+		r, err := conn.Read(buf[n:])
+		n += r
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	ln := m.http
+	if bytes.Equal(buf[:n], http2Preface) {
+		ln = m.grpc
+	}
+
+	ln.dispatch(&sniffedConn{Conn: conn, buf: buf[:n]})
+}
+
+// sniffedConn replays the sniffed bytes before reading from the underlying connection.
+type sniffedConn struct {
+	net.Conn
+	buf []byte
+}
+
+func (c *sniffedConn) Read(p []byte) (int, error) {
+	if len(c.buf) > 0 {
+		n := copy(p, c.buf)
+		c.buf = c.buf[n:]
+		return n, nil
+	}
+
+	return c.Conn.Read(p)
+}
+
+func newListener(addr net.Addr) *listener {
+	return &listener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+// listener implements net.Listener over the connections dispatched by the mux.
+type listener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func (ln *listener) dispatch(conn net.Conn) {
+	select {
+	case ln.conns <- conn:
+	case <-ln.done:
+		_ = conn.Close()
+	}
+}
+
+func (ln *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-ln.conns:
+		return conn, nil
+	case <-ln.done:
+		return nil, ErrListenerClosed
+	}
+}
+
+func (ln *listener) Close() error {
+	ln.once.Do(func() {
+		close(ln.done)
+	})
+
+	return nil
+}
+
+func (ln *listener) Addr() net.Addr {
+	return ln.addr
+}
@@ -0,0 +1,85 @@
+package raftmux
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMux(t *testing.T) {
+	table := []struct {
+		name string
+		data string
+		grpc bool
+	}{
+		{
+			name: "it dispatch HTTP/2 connections to the gRPC listener",
+			data: string(http2Preface) + "frames",
+			grpc: true,
+		},
+		{
+			name: "it dispatch HTTP/1.x connections to the HTTP listener",
+			data: "GET /_raft/ HTTP/1.1\r\nHost: raft\r\n\r\n",
+		},
+		{
+			name: "it dispatch short requests to the HTTP listener",
+			data: "GET /\r\n",
+		},
+		{
+			name: "it dispatch partial preface to the HTTP listener",
+			data: "PRI * HTTP/1.1",
+		},
+	}
+
+	root, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	m := New(root)
+	defer m.Close()
+	go func() { _ = m.Serve() }()
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", root.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = conn.Write([]byte(tt.data))
+			require.NoError(t, err)
+
+			ln := m.HTTP()
+			if tt.grpc {
+				ln = m.GRPC()
+			}
+
+			sconn, err := ln.Accept()
+			require.NoError(t, err)
+			defer sconn.Close()
+
+			buf := make([]byte, len(tt.data))
+			_, err = io.ReadFull(sconn, buf)
+			require.NoError(t, err)
+			require.Equal(t, tt.data, string(buf))
+		})
+	}
+}
+
+func TestMuxClose(t *testing.T) {
+	root, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	m := New(root)
+	errc := make(chan error)
+	go func() { errc <- m.Serve() }()
+
+	require.NoError(t, m.Close())
+	require.NoError(t, <-errc)
+
+	_, err = m.GRPC().Accept()
+	require.Equal(t, ErrListenerClosed, err)
+	_, err = m.HTTP().Accept()
+	require.Equal(t, ErrListenerClosed, err)
+	require.Equal(t, root.Addr(), m.GRPC().Addr())
+}
@@ -0,0 +1,294 @@
+package raftquic
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+)
+
+var _ transport.BatchClient = &client{}
+
+// Dialer return's quic dialer.
+//
+// The TLS config set by the transport config takes precedence over the given TLS config.
+func Dialer(tc *tls.Config, qc *quic.Config) transport.Dialer {
+	return func(cfg transport.Config) transport.Dial {
+		return func(ctx context.Context, addr string) (transport.Client, error) {
+			tlsConf := tc
+			if c := cfg.TLSConfig(); c != nil {
+				tlsConf = c
+			}
+
+			if tlsConf == nil {
+				return nil, errors.New("raft/quic: TLS config is required, use raft.WithTLS()")
+			}
+
+			tlsConf = tlsConf.Clone()
+			tlsConf.NextProtos = []string{NextProto}
+
+			// the connection established lazily by the first rpc,
+			// and re-established when lost.
+			// the handlers negotiates the raft QUIC application protocol,
+			// therefore accept all the supported compressions.
+			compressor := transport.NewCompressor(cfg)
+			compressor.Accept(transport.AcceptCompression())
+
+			return &client{
+				addr:       addr,
+				tls:        tlsConf,
+				quic:       qc,
+				gid:        cfg.GroupID(),
+				ctrl:       cfg.Controller(),
+				token:      cfg.AuthToken(),
+				compressor: compressor,
+			}, nil
+		}
+	}
+}
+
+// client implements transport.Client.
+type client struct {
+	addr       string
+	tls        *tls.Config
+	quic       *quic.Config
+	gid        uint64
+	ctrl       transport.Controller
+	token      string
+	compressor *transport.Compressor
+	mu         sync.Mutex // protects the conn
+	conn       quic.Connection
+	closed     bool
+}
+
+func (c *client) Message(ctx context.Context, msg etcdraftpb.Message) error {
+	fn := c.message
+	if msg.Type == etcdraftpb.MsgSnap {
+		fn = c.snapshot
+	}
+
+	err := fn(ctx, msg)
+	if err == io.EOF {
+		return nil
+	}
+
+	return err
+}
+
+func (c *client) Join(ctx context.Context, m raftpb.Member) (*raftpb.JoinResponse, error) {
+	data, err := c.call(ctx, header{method: joinMethod}, pbutil.MustMarshal(&m))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(raftpb.JoinResponse)
+	return resp, resp.Unmarshal(data)
+}
+
+func (c *client) PromoteMember(ctx context.Context, m raftpb.Member) error {
+	_, err := c.call(ctx, header{method: promoteMethod}, pbutil.MustMarshal(&m))
+	return err
+}
+
+func (c *client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.CloseWithError(0, "")
+}
+
+// Messages sends the given raft messages in a single rpc,
+// the messages must not contain snapshot messages.
+func (c *client) Messages(ctx context.Context, msgs []etcdraftpb.Message) error {
+	batch := &raftpb.MessageBatch{Messages: msgs}
+	data, err := batch.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return c.callMessage(ctx, messagesMethod, data)
+}
+
+func (c *client) message(ctx context.Context, msg etcdraftpb.Message) error {
+	data, err := msg.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return c.callMessage(ctx, messageMethod, data)
+}
+
+// callMessage sends the given raft message or messages batch, compressed when enabled.
+func (c *client) callMessage(ctx context.Context, m method, data []byte) error {
+	data, comp, err := c.compressor.Compress(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.call(ctx, header{method: m, compression: comp}, data)
+	return err
+}
+
+func (c *client) snapshot(ctx context.Context, msg etcdraftpb.Message) error {
+	meta := msg.Snapshot.Metadata
+
+	// send only the snapshot key when published to the shared snapshot store,
+	// otherwise fallback to stream the snapshot files.
+	key, err := c.ctrl.SnapshotPublish(ctx, c.gid, meta.Term, meta.Index)
+	msg.Snapshot.Data = nil
+	if err == nil && key != "" {
+		msg.Snapshot.Data = []byte(key)
+		return c.message(ctx, msg)
+	}
+
+	chain, err := c.ctrl.SnapshotChain(c.gid, meta.Term, meta.Index)
+	if err != nil {
+		return err
+	}
+
+	// send the snapshots the delta snapshot chained onto before the snapshot itself.
+	for _, m := range chain {
+		if err := c.snapshotFile(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	return c.message(ctx, msg)
+}
+
+func (c *client) snapshotFile(ctx context.Context, meta etcdraftpb.SnapshotMetadata) error {
+	offset, compress := c.snapshotOffset(ctx, meta.Term, meta.Index)
+	r, err := c.ctrl.SnapshotReader(c.gid, meta.Term, meta.Index, offset, compress)
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	h := header{
+		method: snapshotMethod,
+		term:   meta.Term,
+		index:  meta.Index,
+		offset: offset,
+	}
+
+	_, err = c.stream(ctx, h, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+
+	return err
+}
+
+// snapshotOffset returns the number of snapshot bytes already received by the remote member,
+// to resume an interrupted transfer, and whether the remote member accepts compressed snapshots.
+// it returns 0 when the remote member does not support resuming.
+func (c *client) snapshotOffset(ctx context.Context, term, index uint64) (uint64, bool) {
+	h := header{
+		method: snapshotOffsetMethod,
+		term:   term,
+		index:  index,
+	}
+
+	data, err := c.call(ctx, h, nil)
+	if err != nil || len(data) == 0 {
+		return 0, false
+	}
+
+	offset, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, false
+	}
+
+	return offset, len(data) > n && data[n] == 1
+}
+
+// call sends the given rpc request payload and returns the response payload.
+func (c *client) call(ctx context.Context, h header, p []byte) ([]byte, error) {
+	return c.stream(ctx, h, func(w io.Writer) error {
+		return writeFrame(w, p)
+	})
+}
+
+// stream opens a new stream to send the given rpc request, written by fn,
+// and returns the response payload.
+func (c *client) stream(ctx context.Context, h header, fn func(io.Writer) error) ([]byte, error) {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// abort the stream when the ctx canceled.
+	stop := context.AfterFunc(ctx, func() {
+		s.CancelRead(0)
+		s.CancelWrite(0)
+	})
+
+	defer stop()
+
+	h.gid = c.gid
+	h.token = c.token
+	h.clusterID = c.ctrl.ClusterID(c.gid)
+
+	w := bufio.NewWriter(s)
+	if err := writeHeader(w, h); err != nil {
+		return nil, err
+	}
+
+	if err := fn(w); err != nil {
+		return nil, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	// close the write side to signal the request end.
+	if err := s.Close(); err != nil {
+		return nil, err
+	}
+
+	return readResponse(bufio.NewReader(s))
+}
+
+// connection returns the member connection, and dial it if not yet established or lost.
+func (c *client) connection(ctx context.Context) (quic.Connection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, errors.New("raft/quic: client closed")
+	}
+
+	if c.conn != nil && c.conn.Context().Err() == nil {
+		return c.conn, nil
+	}
+
+	conn, err := quic.DialAddr(ctx, c.addr, c.tls, c.quic)
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn = conn
+	return conn, nil
+}
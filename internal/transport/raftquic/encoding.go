@@ -0,0 +1,171 @@
+package raftquic
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/shaj13/raft/internal/raftpb"
+)
+
+// NextProto is the TLS application protocol negotiated by the raft QUIC transport.
+const NextProto = "raft"
+
+// maxFrameSize is the maximum size of a frame payload.
+const maxFrameSize = 1 << 30
+
+// method identifies the rpc of a stream.
+type method byte
+
+const (
+	messageMethod method = iota + 1
+	snapshotMethod
+	snapshotOffsetMethod
+	joinMethod
+	promoteMethod
+	messagesMethod
+)
+
+// clusterIDFlag is set on the header method when the header carries the cluster id,
+// so the headers without cluster id keep their layout.
+const clusterIDFlag method = 0x80
+
+// status of the rpc response.
+const (
+	statusOK byte = iota
+	statusError
+)
+
+// header is sent at the beginning of each stream to describe the rpc.
+type header struct {
+	method method
+	gid    uint64
+	token  string
+	// clusterID of the cluster the client belongs to, zero when unknown.
+	clusterID uint64
+	// compression of the message and messages rpc's payload.
+	compression raftpb.Compression
+	// term, index, and offset of the snapshot file,
+	// only set by the snapshot and snapshot offset rpc's.
+	term   uint64
+	index  uint64
+	offset uint64
+}
+
+func writeHeader(w io.Writer, h header) error {
+	m := h.method
+	if h.clusterID != 0 {
+		m |= clusterIDFlag
+	}
+
+	buf := make([]byte, 0, 2+binary.MaxVarintLen64*6+len(h.token))
+	buf = append(buf, byte(m), byte(h.compression))
+	buf = binary.AppendUvarint(buf, h.gid)
+	buf = binary.AppendUvarint(buf, h.term)
+	buf = binary.AppendUvarint(buf, h.index)
+	buf = binary.AppendUvarint(buf, h.offset)
+	buf = binary.AppendUvarint(buf, uint64(len(h.token)))
+	buf = append(buf, h.token...)
+	if h.clusterID != 0 {
+		buf = binary.AppendUvarint(buf, h.clusterID)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readHeader(r *bufio.Reader) (h header, err error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+
+	h.method = method(b) &^ clusterIDFlag
+	hasClusterID := method(b)&clusterIDFlag != 0
+
+	if b, err = r.ReadByte(); err != nil {
+		return
+	}
+
+	h.compression = raftpb.Compression(b)
+
+	for _, v := range []*uint64{&h.gid, &h.term, &h.index, &h.offset} {
+		if *v, err = binary.ReadUvarint(r); err != nil {
+			return
+		}
+	}
+
+	token, err := readFrame(r)
+	if err != nil {
+		return
+	}
+
+	h.token = string(token)
+	if hasClusterID {
+		h.clusterID, err = binary.ReadUvarint(r)
+	}
+
+	return
+}
+
+// writeFrame writes the given payload prefixed by its length.
+func writeFrame(w io.Writer, p []byte) error {
+	buf := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64), uint64(len(p)))
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	_, err := w.Write(p)
+	return err
+}
+
+// readFrame reads a payload written by writeFrame.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > maxFrameSize {
+		return nil, fmt.Errorf("raft/quic: frame size %d exceeds max frame size", n)
+	}
+
+	p := make([]byte, n)
+	_, err = io.ReadFull(r, p)
+	return p, err
+}
+
+// writeResponse writes the rpc response, the given payload or the error message.
+func writeResponse(w io.Writer, p []byte, err error) error {
+	status := statusOK
+	if err != nil {
+		status = statusError
+		p = []byte(err.Error())
+	}
+
+	if _, err := w.Write([]byte{status}); err != nil {
+		return err
+	}
+
+	return writeFrame(w, p)
+}
+
+// readResponse reads the rpc response payload, or returns the error message as error.
+func readResponse(r *bufio.Reader) ([]byte, error) {
+	status, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != statusOK {
+		return nil, errors.New("raft/quic: server returned: " + string(p))
+	}
+
+	return p, nil
+}
@@ -0,0 +1,401 @@
+//nolint:dupl
+package raftquic
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/quic-go/quic-go"
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	transportmock "github.com/shaj13/raft/internal/mocks/transport"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/raftlog"
+)
+
+const testGroupID = uint64(1)
+
+func TestMessage(t *testing.T) {
+	table := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "it return nil error when server process msg",
+			err:  nil,
+		},
+		{
+			name: "it return error when server return error",
+			err:  fmt.Errorf("TestMessage Error"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
+			ln, c := testClientServer(t, rpcCtrl)
+			defer ln.Close()
+			defer c.Close()
+
+			err := c.Message(context.Background(), etcdraftpb.Message{})
+			if tt.err != nil {
+				require.Contains(t, err.Error(), tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	msgs := []etcdraftpb.Message{
+		{Type: etcdraftpb.MsgApp, To: 1},
+		{Type: etcdraftpb.MsgHeartbeat, To: 1},
+	}
+
+	table := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "it return nil error when server process msgs",
+			err:  nil,
+		},
+		{
+			name: "it return error when server return error",
+			err:  fmt.Errorf("TestMessages Error"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu  sync.Mutex
+				got []etcdraftpb.Message
+			)
+
+			ctrl := gomock.NewController(t)
+			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.
+				EXPECT().
+				Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ uint64, m etcdraftpb.Message) error {
+					mu.Lock()
+					defer mu.Unlock()
+					got = append(got, m)
+					return tt.err
+				}).
+				MinTimes(1)
+			ln, c := testClientServer(t, rpcCtrl)
+			defer ln.Close()
+			defer c.Close()
+
+			err := c.Messages(context.Background(), msgs)
+			if tt.err != nil {
+				require.Contains(t, err.Error(), tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, msgs, got)
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	table := []struct {
+		name string
+		resp *raftpb.JoinResponse
+		err  error
+	}{
+		{
+			name: "it return join resp when joined",
+			resp: &raftpb.JoinResponse{
+				ID:      11,
+				Members: []raftpb.Member{{ID: 12}},
+			},
+			err: nil,
+		},
+		{
+			name: "it return error when server return error",
+			err:  fmt.Errorf("TestJoin Error"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.
+				EXPECT().
+				Join(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ uint64, _ *raftpb.Member) (*raftpb.JoinResponse, error) {
+					// it propagate the request source address.
+					require.NotEmpty(t, transport.SourceFromContext(ctx))
+					return tt.resp, tt.err
+				})
+			ln, c := testClientServer(t, rpcCtrl)
+			defer ln.Close()
+			defer c.Close()
+
+			resp, err := c.Join(context.Background(), raftpb.Member{})
+			if tt.err != nil {
+				require.Contains(t, err.Error(), tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.resp, resp)
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	table := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "it return nil error when snapshot uploaded",
+			err:  nil,
+		},
+		{
+			name: "it return error when server return error",
+			err:  fmt.Errorf("TestSnapshot Error"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			snapData := "some snap data"
+			ctrl := gomock.NewController(t)
+
+			rpcCtrl := transportmock.NewMockController(ctrl)
+
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
+			rpcCtrl.
+				EXPECT().
+				SnapshotPublish(gomock.Any(), gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return("", nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotChain(gomock.Eq(testGroupID), gomock.Any(), gomock.Any()).
+				Return([]etcdraftpb.SnapshotMetadata{{Term: 1, Index: 1}}, nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotOffset(gomock.Eq(testGroupID), gomock.Eq(uint64(1)), gomock.Eq(uint64(1))).
+				Return(uint64(5), nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotReader(gomock.Eq(testGroupID), gomock.Any(), gomock.Any(), gomock.Eq(uint64(5)), gomock.Eq(true)).
+				Return(io.NopCloser(strings.NewReader(snapData)), nil)
+			rpcCtrl.
+				EXPECT().
+				SnapshotWriter(gomock.Eq(testGroupID), gomock.Eq(uint64(1)), gomock.Eq(uint64(1)), gomock.Eq(uint64(5))).
+				Return(writeCloser{buf}, nil)
+
+			ln, c := testClientServer(t, rpcCtrl)
+			defer ln.Close()
+			defer c.Close()
+
+			err := c.snapshot(context.Background(), etcdraftpb.Message{})
+			if tt.err != nil {
+				require.Contains(t, err.Error(), tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, snapData, buf.String())
+		})
+	}
+}
+
+func TestPromoteMember(t *testing.T) {
+	table := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "it return nil error when server process promote",
+			err:  nil,
+		},
+		{
+			name: "it return error when server return error",
+			err:  fmt.Errorf("TestPromoteMember Error"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			rpcCtrl := transportmock.NewMockController(ctrl)
+			rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+			rpcCtrl.EXPECT().PromoteMember(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(tt.err)
+			ln, c := testClientServer(t, rpcCtrl)
+			defer ln.Close()
+			defer c.Close()
+
+			err := c.PromoteMember(context.Background(), raftpb.Member{})
+			if tt.err != nil {
+				require.Contains(t, err.Error(), tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Eq(testGroupID)).Return(uint64(7)).AnyTimes()
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+
+	gomock.InOrder(
+		rpcCtrl.
+			EXPECT().
+			Authenticate(gomock.Any(), gomock.Eq(testGroupID)).
+			DoAndReturn(func(ctx context.Context, gid uint64) error {
+				creds := transport.CredentialsFromContext(ctx)
+				require.Equal(t, "secret", creds.Token)
+				require.Equal(t, uint64(7), creds.ClusterID)
+				return nil
+			}),
+		rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(errors.New("denied")),
+	)
+
+	ln, c := testClientServer(t, rpcCtrl)
+	defer ln.Close()
+	defer c.Close()
+	c.token = "secret"
+
+	// it send the token and the controller accept it.
+	err := c.Message(context.Background(), etcdraftpb.Message{})
+	require.NoError(t, err)
+
+	// it return error when the controller reject the credentials.
+	err = c.Message(context.Background(), etcdraftpb.Message{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "denied")
+}
+
+func TestDialer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().TLSConfig()
+
+	// it return error when no TLS config.
+	_, err := Dialer(nil, nil)(cfg)(context.Background(), "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "TLS config is required")
+}
+
+func testClientServer(tb testing.TB, rpcCtrl transport.Controller) (*quic.Listener, *client) {
+	serverConf, clientConf := testTLSConfig(tb)
+	serverConf.NextProtos = []string{NextProto}
+
+	ln, err := quic.ListenAddr("127.0.0.1:0", serverConf, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	srv := new(handler)
+	srv.logger = raftlog.DefaultLogger
+	srv.ctrl = rpcCtrl
+	go func() { _ = srv.Serve(ln) }()
+
+	ctrl := gomock.NewController(tb)
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().Controller().Return(rpcCtrl)
+	cfg.EXPECT().GroupID().Return(testGroupID).AnyTimes()
+	cfg.EXPECT().TLSConfig()
+	cfg.EXPECT().AuthToken().AnyTimes()
+	cfg.EXPECT().MessageCompression().AnyTimes()
+	cfg.EXPECT().CompressionThreshold().AnyTimes()
+
+	c, err := Dialer(clientConf, nil)(cfg)(context.Background(), ln.Addr().String())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return ln, c.(*client)
+}
+
+func testTLSConfig(tb testing.TB) (server, client *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "raft"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	server = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+
+	client = &tls.Config{
+		RootCAs: pool,
+	}
+
+	return server, client
+}
+
+type writeCloser struct {
+	io.Writer
+}
+
+func (writeCloser) Close() error {
+	return nil
+}
+
+func (writeCloser) Commit() error {
+	return nil
+}
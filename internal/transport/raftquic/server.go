@@ -0,0 +1,224 @@
+package raftquic
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/quic-go/quic-go"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/raftlog"
+)
+
+// NewHandler return an QUIC transport Handler.
+//
+// NewHandler compatible with transport.NewHandler.
+func NewHandler(cfg transport.Config) transport.Handler {
+	return &handler{
+		ctrl:   cfg.Controller(),
+		logger: cfg.Logger(),
+	}
+}
+
+// Server serves the raft rpc's over QUIC connections.
+type Server interface {
+	// Serve accepts incoming connections on the given listener,
+	// and serves their streams until the listener closed.
+	Serve(ln *quic.Listener) error
+}
+
+type handler struct {
+	logger raftlog.Logger
+	ctrl   transport.Controller
+}
+
+func (h *handler) Serve(ln *quic.Listener) error {
+	for {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			return err
+		}
+
+		go h.serveConn(conn)
+	}
+}
+
+func (h *handler) serveConn(conn quic.Connection) {
+	for {
+		s, err := conn.AcceptStream(conn.Context())
+		if err != nil {
+			return
+		}
+
+		go h.serveStream(conn, s)
+	}
+}
+
+func (h *handler) serveStream(conn quic.Connection, s quic.Stream) {
+	defer s.Close()
+
+	r := bufio.NewReader(s)
+	hdr, err := readHeader(r)
+	if err != nil {
+		h.logger.Warningf("raft.quic: reading stream header: %v", err)
+		s.CancelRead(0)
+		return
+	}
+
+	ctx := transport.ContextWithSource(conn.Context(), conn.RemoteAddr().String())
+	ctx = transport.ContextWithCredentials(ctx, transport.Credentials{
+		Token:            hdr.token,
+		PeerCertificates: conn.ConnectionState().TLS.PeerCertificates,
+		ClusterID:        hdr.clusterID,
+	})
+
+	var resp []byte
+	err = h.ctrl.Authenticate(ctx, hdr.gid)
+	if err == nil {
+		resp, err = h.handle(ctx, hdr, r)
+	}
+
+	if err != nil {
+		h.logger.Warningf("raft.quic: handle rpc #%d: %v", hdr.method, err)
+	}
+
+	if err := writeResponse(s, resp, err); err != nil {
+		h.logger.Warningf("raft.quic: writing rpc #%d response: %v", hdr.method, err)
+	}
+}
+
+func (h *handler) handle(ctx context.Context, hdr header, r *bufio.Reader) ([]byte, error) {
+	switch hdr.method {
+	case messageMethod:
+		return nil, h.message(ctx, hdr, r)
+	case messagesMethod:
+		return nil, h.messages(ctx, hdr, r)
+	case snapshotMethod:
+		return nil, h.snapshot(hdr, r)
+	case snapshotOffsetMethod:
+		return h.snapshotOffset(hdr)
+	case joinMethod:
+		return h.join(ctx, hdr, r)
+	case promoteMethod:
+		return nil, h.promoteMember(ctx, hdr, r)
+	default:
+		return nil, fmt.Errorf("raft/quic: unknown rpc #%d", hdr.method)
+	}
+}
+
+func (h *handler) message(ctx context.Context, hdr header, r *bufio.Reader) error {
+	data, err := readMessageFrame(hdr, r)
+	if err != nil {
+		return err
+	}
+
+	m := new(etcdraftpb.Message)
+	if err := m.Unmarshal(data); err != nil {
+		return err
+	}
+
+	return h.ctrl.Push(ctx, hdr.gid, *m)
+}
+
+func (h *handler) messages(ctx context.Context, hdr header, r *bufio.Reader) error {
+	data, err := readMessageFrame(hdr, r)
+	if err != nil {
+		return err
+	}
+
+	batch := new(raftpb.MessageBatch)
+	if err := batch.Unmarshal(data); err != nil {
+		return err
+	}
+
+	for _, m := range batch.Messages {
+		if err := h.ctrl.Push(ctx, hdr.gid, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *handler) snapshot(hdr header, r *bufio.Reader) error {
+	h.logger.V(2).Infof(
+		"raft.quic: downloading sanpshot file [term: %d, index: %d, offset: %d]",
+		hdr.term,
+		hdr.index,
+		hdr.offset,
+	)
+
+	w, err := h.ctrl.SnapshotWriter(hdr.gid, hdr.term, hdr.index, hdr.offset)
+	if err != nil {
+		return err
+	}
+
+	defer w.Close()
+
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	}
+
+	return w.Commit()
+}
+
+func (h *handler) snapshotOffset(hdr header) ([]byte, error) {
+	offset, err := h.ctrl.SnapshotOffset(hdr.gid, hdr.term, hdr.index)
+	if err != nil {
+		return nil, err
+	}
+
+	// the offset followed by the compression support.
+	buf := binary.AppendUvarint(nil, offset)
+	return append(buf, 1), nil
+}
+
+func (h *handler) join(ctx context.Context, hdr header, r *bufio.Reader) ([]byte, error) {
+	m, err := readMember(r)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.V(2).Infof("raft.quic: new member asks to join the cluster on address %s", m.Address)
+
+	resp, err := h.ctrl.Join(ctx, hdr.gid, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Marshal()
+}
+
+func (h *handler) promoteMember(ctx context.Context, hdr header, r *bufio.Reader) error {
+	m, err := readMember(r)
+	if err != nil {
+		return err
+	}
+
+	return h.ctrl.PromoteMember(ctx, hdr.gid, *m)
+}
+
+func readMember(r *bufio.Reader) (*raftpb.Member, error) {
+	data, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(raftpb.Member)
+	return m, m.Unmarshal(data)
+}
+
+// readMessageFrame reads the raft message or messages batch frame, decompressed when compressed.
+func readMessageFrame(hdr header, r *bufio.Reader) ([]byte, error) {
+	data, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.Decompress(hdr.compression, data)
+}
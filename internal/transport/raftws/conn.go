@@ -0,0 +1,131 @@
+package raftws
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var _ net.Conn = &conn{}
+
+var errListenerClosed = errors.New("raft/ws: listener closed")
+
+// conn implements net.Conn over a websocket connection,
+// the data written as binary messages and read as a stream.
+type conn struct {
+	ws   *websocket.Conn
+	r    io.Reader
+	rmu  sync.Mutex
+	wmu  sync.Mutex
+	done chan struct{}
+	once sync.Once
+}
+
+func newConn(ws *websocket.Conn) *conn {
+	return &conn{
+		ws:   ws,
+		done: make(chan struct{}),
+	}
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	for {
+		if c.r == nil {
+			_, r, err := c.ws.NextReader()
+			if err != nil {
+				var cerr *websocket.CloseError
+				if errors.As(err, &cerr) {
+					return 0, io.EOF
+				}
+				return 0, err
+			}
+			c.r = r
+		}
+
+		n, err := c.r.Read(p)
+		if errors.Is(err, io.EOF) {
+			c.r = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if err := c.ws.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (c *conn) Close() (err error) {
+	c.once.Do(func() {
+		close(c.done)
+		err = c.ws.Close()
+	})
+
+	return err
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return c.ws.LocalAddr()
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return c.ws.RemoteAddr()
+}
+
+func (c *conn) SetDeadline(t time.Time) error {
+	if err := c.ws.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.ws.SetWriteDeadline(t)
+}
+
+func (c *conn) SetReadDeadline(t time.Time) error {
+	return c.ws.SetReadDeadline(t)
+}
+
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	return c.ws.SetWriteDeadline(t)
+}
+
+// connListener implements net.Listener that accepts a single connection,
+// then blocks until the connection closed.
+type connListener struct {
+	conn     *conn
+	accepted bool
+}
+
+func (ln *connListener) Accept() (net.Conn, error) {
+	if !ln.accepted {
+		ln.accepted = true
+		return ln.conn, nil
+	}
+
+	<-ln.conn.done
+	return nil, errListenerClosed
+}
+
+func (ln *connListener) Close() error {
+	return nil
+}
+
+func (ln *connListener) Addr() net.Addr {
+	return ln.conn.LocalAddr()
+}
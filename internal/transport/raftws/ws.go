@@ -0,0 +1,138 @@
+package raftws
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/internal/transport/rafthttp"
+	"github.com/shaj13/raft/raftlog"
+)
+
+// tunnelHost is the host of the HTTP requests tunneled over the websocket connections.
+const tunnelHost = "http://raft"
+
+// NewHandlerFunc return's func that create a websocket transport handler,
+// that serves the HTTP transport handler over the websocket connections.
+//
+// The handler serves the plain HTTP transport requests as well.
+func NewHandlerFunc(basePath string) transport.NewHandler {
+	nh := rafthttp.NewHandlerFunc(basePath)
+	return func(cfg transport.Config) transport.Handler {
+		return &handler{
+			h:      nh(cfg).(http.Handler),
+			logger: cfg.Logger(),
+			upgrader: websocket.Upgrader{
+				// the members are not browsers, the clients authenticated by the raft handler.
+				CheckOrigin: func(*http.Request) bool { return true },
+			},
+		}
+	}
+}
+
+type handler struct {
+	h        http.Handler
+	logger   raftlog.Logger
+	upgrader websocket.Upgrader
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		h.h.ServeHTTP(w, r)
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Warningf("raft.ws: upgrading connection from %s: %v", r.RemoteAddr, err)
+		return
+	}
+
+	// serve the tunneled requests until the connection closed.
+	srv := &http.Server{Handler: h.h}
+	_ = srv.Serve(&connListener{conn: newConn(ws)})
+}
+
+// Dialer return's websocket dialer, that tunnels the HTTP transport requests
+// over websocket connections, using the given websocket dialer.
+func Dialer(d func(context.Context) *websocket.Dialer, basePath string) transport.Dialer {
+	return func(cfg transport.Config) transport.Dial {
+		return func(ctx context.Context, addr string) (transport.Client, error) {
+			wd := *d(ctx)
+			if tc := cfg.TLSConfig(); tc != nil {
+				wd.TLSClientConfig = tc.Clone()
+			}
+
+			u := wsURL(addr, basePath)
+			tr := &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					ws, _, err := wd.DialContext(ctx, u, nil)
+					if err != nil {
+						return nil, err
+					}
+					return newConn(ws), nil
+				},
+			}
+
+			rt := func(context.Context) http.RoundTripper { return tr }
+			c, err := rafthttp.Dialer(rt, basePath)(tunnelConfig{cfg})(ctx, tunnelHost)
+			if err != nil {
+				return nil, err
+			}
+
+			return &client{Client: c, tr: tr}, nil
+		}
+	}
+}
+
+// tunnelConfig is the config of the HTTP transport client tunneled over the websocket connections,
+// without TLS since the websocket connections secured.
+type tunnelConfig struct {
+	transport.Config
+}
+
+func (tunnelConfig) TLSConfig() *tls.Config {
+	return nil
+}
+
+// client implements transport.Client, over the HTTP transport client.
+type client struct {
+	transport.Client
+	tr *http.Transport
+}
+
+func (c *client) Messages(ctx context.Context, msgs []etcdraftpb.Message) error {
+	return c.Client.(transport.BatchClient).Messages(ctx, msgs)
+}
+
+func (c *client) Close() error {
+	c.tr.CloseIdleConnections()
+	return c.Client.Close()
+}
+
+// wsURL returns the websocket url of the given member address and base path,
+// the http and https schemes replaced by ws and wss, and ws assumed when the scheme missing.
+func wsURL(addr, basePath string) string {
+	switch {
+	case strings.HasPrefix(addr, "https://"):
+		addr = "wss://" + strings.TrimPrefix(addr, "https://")
+	case strings.HasPrefix(addr, "http://"):
+		addr = "ws://" + strings.TrimPrefix(addr, "http://")
+	case !strings.HasPrefix(addr, "ws://") && !strings.HasPrefix(addr, "wss://"):
+		addr = "ws://" + addr
+	}
+
+	u, err := url.JoinPath(addr, basePath)
+	if err != nil {
+		return strings.TrimSuffix(addr, "/") + "/" + strings.Trim(basePath, "/")
+	}
+
+	return u
+}
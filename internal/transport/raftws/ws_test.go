@@ -0,0 +1,129 @@
+package raftws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	transportmock "github.com/shaj13/raft/internal/mocks/transport"
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/internal/transport/rafthttp"
+	"github.com/shaj13/raft/raftlog"
+)
+
+const (
+	testGroupID  = uint64(1)
+	testBasePath = "/_raft/"
+)
+
+func TestWSURL(t *testing.T) {
+	table := []struct {
+		addr     string
+		expected string
+	}{
+		{addr: "http://127.0.0.1:8080", expected: "ws://127.0.0.1:8080/_raft/"},
+		{addr: "https://127.0.0.1:8080/", expected: "wss://127.0.0.1:8080/_raft/"},
+		{addr: "ws://127.0.0.1:8080", expected: "ws://127.0.0.1:8080/_raft/"},
+		{addr: "wss://127.0.0.1:8080", expected: "wss://127.0.0.1:8080/_raft/"},
+		{addr: "127.0.0.1:8080", expected: "ws://127.0.0.1:8080/_raft/"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.addr, func(t *testing.T) {
+			require.Equal(t, tt.expected, wsURL(tt.addr, testBasePath))
+		})
+	}
+}
+
+func TestClientServer(t *testing.T) {
+	err := fmt.Errorf("TestClientServer error")
+	msgs := []etcdraftpb.Message{
+		{Type: etcdraftpb.MsgApp, To: 1},
+		{Type: etcdraftpb.MsgHeartbeat, To: 1},
+	}
+
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	ts, c := testClientServer(t, rpcCtrl)
+	defer ts.Close()
+	defer c.Close()
+
+	// it tunnel the messages over the websocket connection.
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+	require.NoError(t, c.Message(context.Background(), etcdraftpb.Message{}))
+
+	// it return error when server return error.
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(err)
+	got := c.Message(context.Background(), etcdraftpb.Message{})
+	require.Contains(t, got.Error(), err.Error())
+
+	// it tunnel the messages batch over the websocket connection.
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil).Times(len(msgs))
+	require.NoError(t, c.(transport.BatchClient).Messages(context.Background(), msgs))
+
+	// it tunnel the member promotion over the websocket connection.
+	rpcCtrl.EXPECT().PromoteMember(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+	require.NoError(t, c.PromoteMember(context.Background(), raftpb.Member{}))
+
+	// it tunnel the join over the websocket connection.
+	rpcCtrl.
+		EXPECT().
+		Join(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).
+		Return(&raftpb.JoinResponse{ID: 1}, nil)
+	resp, got := c.Join(context.Background(), raftpb.Member{})
+	require.NoError(t, got)
+	require.Equal(t, uint64(1), resp.ID)
+}
+
+func TestPlainHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rpcCtrl := transportmock.NewMockController(ctrl)
+	rpcCtrl.EXPECT().ClusterID(gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes()
+	rpcCtrl.EXPECT().Push(gomock.Any(), gomock.Eq(testGroupID), gomock.Any()).Return(nil)
+	cfg := testConfig(ctrl, rpcCtrl)
+
+	ts := httptest.NewServer(NewHandlerFunc(testBasePath)(cfg).(http.Handler))
+	defer ts.Close()
+
+	// it serve the plain http transport requests.
+	rt := func(context.Context) http.RoundTripper { return http.DefaultTransport }
+	c, err := rafthttp.Dialer(rt, testBasePath)(cfg)(context.Background(), ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, c.Message(context.Background(), etcdraftpb.Message{}))
+}
+
+func testConfig(ctrl *gomock.Controller, rpcCtrl transport.Controller) *transportmock.MockConfig {
+	cfg := transportmock.NewMockConfig(ctrl)
+	cfg.EXPECT().Controller().Return(rpcCtrl).AnyTimes()
+	cfg.EXPECT().Logger().Return(raftlog.DefaultLogger).AnyTimes()
+	cfg.EXPECT().GroupID().Return(testGroupID).AnyTimes()
+	cfg.EXPECT().TLSConfig().AnyTimes()
+	cfg.EXPECT().AuthToken().AnyTimes()
+	cfg.EXPECT().MessageCompression().AnyTimes()
+	cfg.EXPECT().CompressionThreshold().AnyTimes()
+	return cfg
+}
+
+func testClientServer(t *testing.T, rpcCtrl transport.Controller) (*httptest.Server, transport.Client) {
+	ctrl := gomock.NewController(t)
+	cfg := testConfig(ctrl, rpcCtrl)
+
+	ts := httptest.NewServer(NewHandlerFunc(testBasePath)(cfg).(http.Handler))
+
+	d := func(context.Context) *websocket.Dialer { return websocket.DefaultDialer }
+	c, err := Dialer(d, testBasePath)(cfg)(context.Background(), ts.URL)
+	require.NoError(t, err)
+
+	return ts, c
+}
@@ -0,0 +1,18 @@
+package transport
+
+import "context"
+
+type sourceKey struct{}
+
+// ContextWithSource returns a copy of the given ctx,
+// that carries the network address of the request source.
+func ContextWithSource(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, addr)
+}
+
+// SourceFromContext returns the network address of the request source,
+// or an empty string if the ctx does not carry it.
+func SourceFromContext(ctx context.Context) string {
+	addr, _ := ctx.Value(sourceKey{}).(string)
+	return addr
+}
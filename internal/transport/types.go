@@ -2,7 +2,9 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
+	"time"
 
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 
@@ -17,6 +19,25 @@ type Config interface {
 	Controller() Controller
 	Logger() raftlog.Logger
 	GroupID() uint64
+	TLSConfig() *tls.Config
+	AuthToken() string
+	MessageCompression() raftpb.Compression
+	CompressionThreshold() int
+}
+
+// Metrics define a set of functions to report the transport traffic and latency per member.
+type Metrics interface {
+	// AddMessagesSent adds the number of raft messages sent to the given member.
+	AddMessagesSent(id uint64, n int)
+	// AddBytesSent adds the number of bytes of the raft messages sent to the given member.
+	AddBytesSent(id uint64, n int)
+	// AddMessagesReceived adds the number of raft messages received from the given member.
+	AddMessagesReceived(id uint64, n int)
+	// AddBytesReceived adds the number of bytes of the raft messages received from the given member.
+	AddBytesReceived(id uint64, n int)
+	// ObserveLatency observes the latency of sending raft messages rpc to the given member,
+	// and whether the rpc failed.
+	ObserveLatency(id uint64, d time.Duration, failed bool)
 }
 
 // Handler responds to an RPC request.
@@ -39,12 +60,37 @@ type Client interface {
 	Close() error
 }
 
+// BatchClient is implemented by the clients that can send multiple raft messages in a single rpc.
+type BatchClient interface {
+	Client
+	Messages(context.Context, []etcdraftpb.Message) error
+}
+
+// SnapshotWriter writes a snapshot file that can be resumed when interrupted,
+// Close persist the received data, and Commit marks the snapshot file as complete.
+type SnapshotWriter interface {
+	io.WriteCloser
+	Commit() error
+}
+
 // Controller implements operations defined by raft raftpb.
 // and acts as a bridge between the RPC and raft daemon.
 type Controller interface {
+	// Authenticate returns error if the credentials carried by the given ctx,
+	// are not allowed to call the group rpc's, See CredentialsFromContext.
+	Authenticate(ctx context.Context, gid uint64) error
+	// ClusterID returns the id of the cluster the group node belongs to, zero when unknown,
+	// sent along with the requests so the remote members reject the requests of a different cluster.
+	ClusterID(gid uint64) uint64
 	Push(context.Context, uint64, etcdraftpb.Message) error
 	Join(context.Context, uint64, *raftpb.Member) (*raftpb.JoinResponse, error)
 	PromoteMember(context.Context, uint64, raftpb.Member) error
-	SnapshotWriter(uint64, uint64, uint64) (io.WriteCloser, error)
-	SnapshotReader(uint64, uint64, uint64) (io.ReadCloser, error)
+	// Health returns error if the group node is not serving,
+	// i.e. it has no elected leader or its applied index is not advancing.
+	Health(gid uint64) error
+	SnapshotChain(gid, term, index uint64) ([]etcdraftpb.SnapshotMetadata, error)
+	SnapshotPublish(ctx context.Context, gid, term, index uint64) (string, error)
+	SnapshotOffset(gid, term, index uint64) (uint64, error)
+	SnapshotWriter(gid, term, index, offset uint64) (SnapshotWriter, error)
+	SnapshotReader(gid, term, index, offset uint64, compress bool) (io.ReadCloser, error)
 }
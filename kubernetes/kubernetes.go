@@ -0,0 +1,306 @@
+// Package kubernetes provides helpers to bootstrap a raft cluster running as a kubernetes StatefulSet,
+// governed by a headless Service.
+//
+// The pods derive a stable address from their StatefulSet ordinal, while their member id
+// generated once and persisted into the pod state dir, so a pod that lost its state joins the cluster
+// as a new member, rather than reusing the id of a removed member which can never rejoin.
+// The first pod initializes the cluster when it can't join any of the initial pods,
+// and the other pods join the cluster through the initial pods.
+//
+//	opts, err := kubernetes.StartOptions(kubernetes.Config{
+//		Service:  "raft",
+//		Port:     8080,
+//		Replicas: 3,
+//		StateDir: "/var/lib/raft",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	node.Start(opts...)
+package kubernetes
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaj13/raft"
+)
+
+// namespaceFile is the service account namespace file mounted in the pods.
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// idFile is the file persisting the member id within the pod state dir.
+const idFile = "member.id"
+
+// Config define the StatefulSet pods configuration.
+type Config struct {
+	// Service is the headless service name governing the StatefulSet.
+	Service string
+	// Namespace of the StatefulSet pods,
+	// Default Value: $POD_NAMESPACE, the pod service account namespace, or "default".
+	Namespace string
+	// ClusterDomain is the kubernetes cluster domain,
+	// Default Value: "cluster.local".
+	ClusterDomain string
+	// Port is the raft port listened on by the pods.
+	Port int
+	// Replicas is the number of the initial pods,
+	// the pods join the cluster through the pods with ordinals lower than Replicas.
+	Replicas int
+	// Hostname is the pod name, e.g. "raft-0",
+	// Default Value: os.Hostname().
+	Hostname string
+	// JoinTimeout is the timeout of joining the cluster,
+	// Default Value: 10s.
+	JoinTimeout time.Duration
+	// ClusterToken is the token of the cluster initialized by the first pod, See raft.WithClusterToken.
+	ClusterToken string
+	// StateDir is the pod durable state dir, where the member id persisted,
+	// it must be the state dir of the raft node, See raft.WithStateDIR.
+	// Default Value: os.TempDir().
+	StateDir string
+}
+
+// Ordinal returns the StatefulSet ordinal of the given pod name.
+func Ordinal(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, fmt.Errorf("raft/kubernetes: pod name %q is not a StatefulSet pod name", hostname)
+	}
+
+	ordinal, err := strconv.Atoi(hostname[i+1:])
+	if err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("raft/kubernetes: pod name %q is not a StatefulSet pod name", hostname)
+	}
+
+	return ordinal, nil
+}
+
+// MemberID returns the member id of the given StatefulSet ordinal,
+// used by the pods state predating the persisted member id.
+func MemberID(ordinal int) uint64 {
+	// member id 0 is reserved as raft.None.
+	return uint64(ordinal) + 1
+}
+
+// Member returns the current pod member, with the member id persisted into the state dir,
+// or a newly generated one if the state dir has no member id.
+func Member(cfg Config) (raft.RawMember, error) {
+	c, err := cfg.defaults()
+	if err != nil {
+		return raft.RawMember{}, err
+	}
+
+	ordinal, err := Ordinal(c.Hostname)
+	if err != nil {
+		return raft.RawMember{}, err
+	}
+
+	id, err := c.memberID(ordinal)
+	if err != nil {
+		return raft.RawMember{}, err
+	}
+
+	return raft.RawMember{
+		ID:      id,
+		Address: c.address(ordinal),
+	}, nil
+}
+
+// StartOptions returns the raft start options of the current pod.
+//
+// The pods restart from their existing state if any, otherwise they join the cluster through any of the initial pods,
+// and the first pod initializes the cluster when none of the initial pods is a cluster member yet.
+//
+// Note: a pod that lost its state joins the cluster as a new member,
+// its former member remains unreachable until removed from the cluster, See raft.WithDeadMemberTimeout.
+// The first pod that lost its state while all the other initial pods down initializes a new cluster,
+// which is fenced from the former cluster by the cluster id.
+func StartOptions(cfg Config) ([]raft.StartOption, error) {
+	c, err := cfg.defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	mem, err := Member(c)
+	if err != nil {
+		return nil, err
+	}
+
+	ordinal, _ := Ordinal(c.Hostname)
+
+	fallbacks := []raft.StartOption{raft.WithRestart()}
+	for i := 0; i < c.Replicas; i++ {
+		if i != ordinal {
+			fallbacks = append(fallbacks, raft.WithJoin(c.address(i), c.JoinTimeout))
+		}
+	}
+
+	opts := []raft.StartOption{raft.WithMembers(mem)}
+
+	if ordinal == 0 {
+		fallbacks = append(fallbacks, raft.WithInitCluster())
+		if len(c.ClusterToken) > 0 {
+			opts = append(opts, raft.WithClusterToken(c.ClusterToken))
+		}
+	}
+
+	return append(opts, raft.WithFallback(fallbacks...)), nil
+}
+
+// Address returns the stable network address of the pod of the given ordinal.
+func (c Config) Address(ordinal int) (string, error) {
+	c, err := c.defaults()
+	if err != nil {
+		return "", err
+	}
+
+	return c.address(ordinal), nil
+}
+
+func (c Config) address(ordinal int) string {
+	pod := c.Hostname[:strings.LastIndex(c.Hostname, "-")+1] + strconv.Itoa(ordinal)
+	return fmt.Sprintf("%s.%s.%s.svc.%s:%d", pod, c.Service, c.Namespace, c.ClusterDomain, c.Port)
+}
+
+// memberID returns the member id persisted into the state dir,
+// the pods state predating the persisted member id keep their ordinal member id.
+func (c Config) memberID(ordinal int) (uint64, error) {
+	path := filepath.Join(c.StateDir, idFile)
+
+	buf, err := os.ReadFile(path)
+	if err == nil {
+		id, err := strconv.ParseUint(strings.TrimSpace(string(buf)), 16, 64)
+		if err != nil || id == 0 {
+			return 0, fmt.Errorf("raft/kubernetes: member id file %s corrupted", path)
+		}
+		return id, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("raft/kubernetes: reading member id: %w", err)
+	}
+
+	id := MemberID(ordinal)
+	if _, err := os.Stat(filepath.Join(c.StateDir, "wal")); os.IsNotExist(err) {
+		if id, err = newMemberID(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writeMemberID(path, id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// newMemberID returns a random non-zero member id.
+func newMemberID() (uint64, error) {
+	var buf [8]byte
+	for {
+		if _, err := crand.Read(buf[:]); err != nil {
+			return 0, fmt.Errorf("raft/kubernetes: generating member id: %w", err)
+		}
+
+		if id := binary.BigEndian.Uint64(buf[:]); id != 0 {
+			return id, nil
+		}
+	}
+}
+
+// writeMemberID writes atomically the given member id into the given path.
+func writeMemberID(path string, id uint64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("raft/kubernetes: creating state dir: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("raft/kubernetes: writing member id: %w", err)
+	}
+
+	_, err = f.WriteString(strconv.FormatUint(id, 16) + "\n")
+	if err == nil {
+		err = f.Sync()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("raft/kubernetes: writing member id: %w", err)
+	}
+
+	return nil
+}
+
+func (c Config) defaults() (Config, error) {
+	if len(c.Service) == 0 {
+		return c, errors.New("raft/kubernetes: headless service name is required")
+	}
+
+	if c.Port <= 0 {
+		return c, errors.New("raft/kubernetes: raft port is required")
+	}
+
+	if c.Replicas <= 0 {
+		return c, errors.New("raft/kubernetes: initial replicas must be positive")
+	}
+
+	if len(c.Hostname) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return c, fmt.Errorf("raft/kubernetes: reading pod name: %w", err)
+		}
+		c.Hostname = hostname
+	}
+
+	if _, err := Ordinal(c.Hostname); err != nil {
+		return c, err
+	}
+
+	if len(c.Namespace) == 0 {
+		c.Namespace = namespace()
+	}
+
+	if len(c.ClusterDomain) == 0 {
+		c.ClusterDomain = "cluster.local"
+	}
+
+	if c.JoinTimeout == 0 {
+		c.JoinTimeout = time.Second * 10
+	}
+
+	if len(c.StateDir) == 0 {
+		c.StateDir = os.TempDir()
+	}
+
+	return c, nil
+}
+
+func namespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); len(ns) > 0 {
+		return ns
+	}
+
+	if buf, err := os.ReadFile(namespaceFile); err == nil && len(strings.TrimSpace(string(buf))) > 0 {
+		return strings.TrimSpace(string(buf))
+	}
+
+	return "default"
+}
@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaj13/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdinal(t *testing.T) {
+	table := []struct {
+		hostname string
+		ordinal  int
+		err      bool
+	}{
+		{hostname: "raft-0", ordinal: 0},
+		{hostname: "my-raft-12", ordinal: 12},
+		{hostname: "raft", err: true},
+		{hostname: "raft-x", err: true},
+		{hostname: "raft-", err: true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.hostname, func(t *testing.T) {
+			ordinal, err := Ordinal(tt.hostname)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.ordinal, ordinal)
+		})
+	}
+}
+
+func TestMember(t *testing.T) {
+	cfg := Config{
+		Service:   "raft",
+		Namespace: "ns",
+		Port:      8080,
+		Replicas:  3,
+		Hostname:  "raft-1",
+		StateDir:  t.TempDir(),
+	}
+
+	// it generate and persist the member id.
+	mem, err := Member(cfg)
+	require.NoError(t, err)
+	require.NotZero(t, mem.ID)
+	require.Equal(t, "raft-1.raft.ns.svc.cluster.local:8080", mem.Address)
+
+	// it return the persisted member id.
+	again, err := Member(cfg)
+	require.NoError(t, err)
+	require.Equal(t, mem, again)
+
+	// it generate a new member id once the state lost.
+	require.NoError(t, os.Remove(filepath.Join(cfg.StateDir, idFile)))
+	again, err = Member(cfg)
+	require.NoError(t, err)
+	require.NotEqual(t, mem.ID, again.ID)
+
+	// it keep the ordinal member id of the state predating the persisted member id.
+	cfg.StateDir = t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(cfg.StateDir, "wal"), 0750))
+	mem, err = Member(cfg)
+	require.NoError(t, err)
+	require.Equal(t, raft.RawMember{ID: 2, Address: "raft-1.raft.ns.svc.cluster.local:8080"}, mem)
+
+	// it return error on corrupted member id file.
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.StateDir, idFile), []byte("x"), 0600))
+	_, err = Member(cfg)
+	require.Error(t, err)
+
+	// it return error on invalid config.
+	_, err = Member(Config{Service: "raft", Port: 8080, Replicas: 3, Hostname: "raft"})
+	require.Error(t, err)
+}
+
+func TestStartOptions(t *testing.T) {
+	cfg := Config{
+		Service:  "raft",
+		Port:     8080,
+		Replicas: 3,
+		Hostname: "raft-3",
+		StateDir: t.TempDir(),
+	}
+
+	opts, err := StartOptions(cfg)
+	require.NoError(t, err)
+	require.Len(t, opts, 2)
+
+	// it set the cluster token of the first pod.
+	cfg.Hostname = "raft-0"
+	cfg.ClusterToken = "token"
+	opts, err = StartOptions(cfg)
+	require.NoError(t, err)
+	require.Len(t, opts, 3)
+
+	// it return error on invalid config.
+	_, err = StartOptions(Config{Port: 8080, Replicas: 3, Hostname: "raft-0"})
+	require.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package lease
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shaj13/raft"
+)
+
+var _ raft.MetadataStateMachine = &Lessor{}
+
+// metadataKey is the proposal metadata key marking the leases entries,
+// its value is the entries encoding version.
+const (
+	metadataKey     = "raft-lease"
+	metadataVersion = "1"
+)
+
+// Possible values for op.
+const (
+	opGrant op = iota + 1
+	opRenew
+	opRevoke
+	opExpire
+)
+
+// op is a replicated lease operation.
+type op byte
+
+// command is a replicated lease entry.
+type command struct {
+	op  op
+	id  uint64
+	ttl time.Duration
+	// rev is the lease revision at expiry, set by the expire operation.
+	rev uint64
+}
+
+func (c command) marshal() []byte {
+	buf := make([]byte, 1, 1+binary.MaxVarintLen64*2)
+	buf[0] = byte(c.op)
+	buf = binary.AppendUvarint(buf, c.id)
+
+	switch c.op {
+	case opGrant:
+		buf = binary.AppendVarint(buf, int64(c.ttl))
+	case opExpire:
+		buf = binary.AppendUvarint(buf, c.rev)
+	}
+
+	return buf
+}
+
+func (c *command) unmarshal(data []byte) (err error) {
+	if len(data) == 0 {
+		return errors.New("raft/lease: empty entry")
+	}
+
+	r := bytes.NewReader(data[1:])
+	c.op = op(data[0])
+	if c.id, err = binary.ReadUvarint(r); err != nil {
+		return fmt.Errorf("raft/lease: malformed entry: %w", err)
+	}
+
+	switch c.op {
+	case opGrant:
+		var ttl int64
+		ttl, err = binary.ReadVarint(r)
+		c.ttl = time.Duration(ttl)
+	case opExpire:
+		c.rev, err = binary.ReadUvarint(r)
+	case opRenew, opRevoke:
+	default:
+		return fmt.Errorf("raft/lease: unknown entry operation %d", c.op)
+	}
+
+	if err != nil {
+		return fmt.Errorf("raft/lease: malformed entry: %w", err)
+	}
+
+	return nil
+}
+
+// Apply delegates the given entry to the wrapped state machine.
+func (l *Lessor) Apply(data []byte) error {
+	return l.fsm.Apply(data)
+}
+
+// ApplyWithMetadata applies the leases entries,
+// and delegates the other entries to the wrapped state machine.
+func (l *Lessor) ApplyWithMetadata(data []byte, md map[string]string) error {
+	if _, ok := md[metadataKey]; ok {
+		return l.apply(data)
+	}
+
+	if sm, ok := l.fsm.(raft.MetadataStateMachine); ok {
+		return sm.ApplyWithMetadata(data, md)
+	}
+
+	return l.fsm.Apply(data)
+}
+
+func (l *Lessor) apply(data []byte) error {
+	c := command{}
+	if err := c.unmarshal(data); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+
+	ls, ok := l.leases[c.id]
+	switch c.op {
+	case opGrant:
+		if ok {
+			l.mu.Unlock()
+			return ErrLeaseExists
+		}
+
+		l.rev++
+		l.leases[c.id] = &lease{ttl: c.ttl, rev: l.rev, ticks: l.ticks(c.ttl)}
+		l.mu.Unlock()
+		return nil
+	case opRenew:
+		if !ok {
+			l.mu.Unlock()
+			return ErrLeaseNotFound
+		}
+
+		l.rev++
+		ls.rev = l.rev
+		ls.ticks = l.ticks(ls.ttl)
+		l.mu.Unlock()
+		return nil
+	case opRevoke:
+		if !ok {
+			l.mu.Unlock()
+			return ErrLeaseNotFound
+		}
+	case opExpire:
+		// the lease renewed or revoked since its expiry proposed.
+		if !ok || ls.rev != c.rev {
+			l.mu.Unlock()
+			return nil
+		}
+	}
+
+	delete(l.leases, c.id)
+	revoked := Lease{ID: c.id, TTL: ls.ttl}
+	l.mu.Unlock()
+
+	if sm, ok := l.fsm.(StateMachine); ok {
+		return sm.LeaseRevoked(revoked, c.op == opExpire)
+	}
+
+	return nil
+}
+
+// Snapshot returns the leases followed by the wrapped state machine snapshot.
+func (l *Lessor) Snapshot() (io.ReadCloser, error) {
+	l.mu.Lock()
+	buf := binary.AppendUvarint(nil, l.rev)
+	buf = binary.AppendUvarint(buf, uint64(len(l.leases)))
+	for id, ls := range l.leases {
+		buf = binary.AppendUvarint(buf, id)
+		buf = binary.AppendVarint(buf, int64(ls.ttl))
+		buf = binary.AppendUvarint(buf, ls.rev)
+	}
+	l.mu.Unlock()
+
+	rc, err := l.fsm.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return readCloser{
+		Reader: io.MultiReader(bytes.NewReader(buf), rc),
+		Closer: rc,
+	}, nil
+}
+
+// Restore restores the leases, and the wrapped state machine from the remaining snapshot data.
+// The restored leases count down restarted.
+func (l *Lessor) Restore(rc io.ReadCloser) error {
+	br := bufio.NewReader(rc)
+	rev, leases, err := l.readLeases(br)
+	if err != nil {
+		rc.Close()
+		return fmt.Errorf("raft/lease: malformed snapshot: %w", err)
+	}
+
+	l.mu.Lock()
+	l.rev, l.leases = rev, leases
+	l.mu.Unlock()
+
+	return l.fsm.Restore(readCloser{Reader: br, Closer: rc})
+}
+
+func (l *Lessor) readLeases(r io.ByteReader) (uint64, map[uint64]*lease, error) {
+	rev, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	leases := make(map[uint64]*lease)
+	for i := uint64(0); i < n; i++ {
+		id, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		ttl, err := binary.ReadVarint(r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		lrev, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		leases[id] = &lease{
+			ttl:   time.Duration(ttl),
+			rev:   lrev,
+			ticks: l.ticks(time.Duration(ttl)),
+		}
+	}
+
+	return rev, leases, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,333 @@
+// Package lease provides leases with time to live replicated through the raft log,
+// to build TTL semantics, e.g. sessions, locks, or ephemeral keys, on top of a state machine.
+//
+// The leases granted, renewed, and revoked by replicated entries, hence all the members agree on them.
+// The leases expiry counted by the leader raft ticks, the followers never expire a lease,
+// instead the leader replicates the revocation of the expired leases, so the members clocks skew doesn't matter.
+// A new leader restarts the count of all leases, therefore a lease never expires before its TTL,
+// but may outlive it by up to an election.
+//
+// The Lessor wraps the application state machine, passed to raft.NewNode,
+// and notifies it of the leases revocation, See StateMachine.
+//
+//	lessor := lease.NewLessor(fsm)
+//	node := raft.NewNode(lessor, transport.GRPC)
+//	go node.Start(opts...)
+//	go lessor.Run(ctx, node)
+//
+//	l, err := lessor.Grant(ctx, 10*time.Second)
+package lease
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/raftlog"
+)
+
+var (
+	// ErrLeaseNotFound is returned when the lease does not exist, or already revoked.
+	ErrLeaseNotFound = errors.New("raft/lease: lease not found")
+	// ErrLeaseExists is returned when granting a lease of an existing lease id.
+	ErrLeaseExists = errors.New("raft/lease: lease already exists")
+	// ErrInvalidTTL is returned when granting a lease with a non-positive TTL.
+	ErrInvalidTTL = errors.New("raft/lease: invalid ttl")
+	// ErrNotRunning is returned when proposing a lease operation while the lessor not running, See Lessor.Run.
+	ErrNotRunning = errors.New("raft/lease: lessor not running")
+)
+
+// expireTimeout is the timeout of replicating the revocation of an expired lease,
+// the revocation proposed again on the next tick if it fails.
+var expireTimeout = 5 * time.Second
+
+// Node is the raft node replicating the leases entries, implemented by *raft.Node.
+type Node interface {
+	Replicate(ctx context.Context, data []byte) error
+	Leader() uint64
+	Whoami() uint64
+}
+
+// StateMachine is an optional interface implemented by the application state machine wrapped by the Lessor,
+// to release the resources attached to a lease, e.g. delete the lease keys, once revoked.
+type StateMachine interface {
+	raft.StateMachine
+
+	// LeaseRevoked called on all the members once the lease revocation applied,
+	// expired reports whether the leader revoked the lease on expiry, rather than explicitly revoked.
+	LeaseRevoked(l Lease, expired bool) error
+}
+
+// Lease is a replicated lease.
+type Lease struct {
+	// ID is the lease unique id.
+	ID uint64
+	// TTL is the lease time to live, since granted or last renewed.
+	TTL time.Duration
+	// Remaining is the time remaining until the lease expiry as counted by the leader,
+	// The other members never count it down.
+	Remaining time.Duration
+}
+
+// Option configures the Lessor.
+type Option func(*Lessor)
+
+// WithTickInterval set the interval of the ticks counting down the leases on the leader,
+// the leases expiry resolution.
+//
+// Default Value: 100'ms.
+func WithTickInterval(d time.Duration) Option {
+	return func(l *Lessor) {
+		l.interval = d
+	}
+}
+
+// WithClock set the clock providing the ticks counting down the leases,
+// e.g. the virtual clock driving the nodes of a simulation, See rafttest.
+//
+// Default Value: nil, the wall clock.
+func WithClock(clock raft.Clock) Option {
+	return func(l *Lessor) {
+		l.clock = clock
+	}
+}
+
+// WithLogger set the lessor logger.
+//
+// Default Value: raftlog.DefaultLogger.
+func WithLogger(lg raftlog.Logger) Option {
+	return func(l *Lessor) {
+		l.logger = lg
+	}
+}
+
+// NewLessor returns a new Lessor wrapping the given application state machine.
+func NewLessor(fsm raft.StateMachine, opts ...Option) *Lessor {
+	l := &Lessor{
+		fsm:      fsm,
+		interval: time.Millisecond * 100,
+		logger:   raftlog.DefaultLogger,
+		leases:   make(map[uint64]*lease),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Lessor grants, renews, and revokes the replicated leases,
+// and expires them while the local member is the leader.
+// It implements raft.StateMachine, applying the leases entries and delegating other entries to the wrapped state machine.
+//
+// Note: the optional state machine interfaces are not supported by the Lessor,
+// except raft.MetadataStateMachine.
+type Lessor struct {
+	fsm      raft.StateMachine
+	interval time.Duration
+	clock    raft.Clock
+	logger   raftlog.Logger
+
+	mu     sync.Mutex
+	node   Node
+	leader bool
+	// rev increased by each applied grant or renewal,
+	// so a revocation proposed on expiry ignored once the lease renewed.
+	rev    uint64
+	leases map[uint64]*lease
+}
+
+// lease is the lessor record of a lease.
+type lease struct {
+	ttl time.Duration
+	rev uint64
+	// ticks remaining until expiry, counted down by the leader.
+	ticks int64
+}
+
+// Grant proposes a new lease with the given TTL, and returns it once applied.
+func (l *Lessor) Grant(ctx context.Context, ttl time.Duration) (Lease, error) {
+	if ttl <= 0 {
+		return Lease{}, ErrInvalidTTL
+	}
+
+	id := rand.Uint64()
+	for id == 0 {
+		id = rand.Uint64()
+	}
+
+	if err := l.propose(ctx, command{op: opGrant, id: id, ttl: ttl}); err != nil {
+		return Lease{}, err
+	}
+
+	return Lease{ID: id, TTL: ttl, Remaining: ttl}, nil
+}
+
+// Renew proposes to restart the count down of the given lease,
+// It returns ErrLeaseNotFound if the lease already expired or revoked.
+func (l *Lessor) Renew(ctx context.Context, id uint64) error {
+	return l.propose(ctx, command{op: opRenew, id: id})
+}
+
+// Revoke proposes to revoke the given lease,
+// It returns ErrLeaseNotFound if the lease already expired or revoked.
+func (l *Lessor) Revoke(ctx context.Context, id uint64) error {
+	return l.propose(ctx, command{op: opRevoke, id: id})
+}
+
+// Lease returns the lease of the given id, as known by the local member.
+func (l *Lessor) Lease(id uint64) (Lease, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ls, ok := l.leases[id]
+	if !ok {
+		return Lease{}, false
+	}
+
+	return l.lease(id, ls), true
+}
+
+// Leases returns the leases known by the local member, ordered by their ids.
+func (l *Lessor) Leases() []Lease {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	leases := make([]Lease, 0, len(l.leases))
+	for id, ls := range l.leases {
+		leases = append(leases, l.lease(id, ls))
+	}
+
+	sort.Slice(leases, func(i, j int) bool { return leases[i].ID < leases[j].ID })
+	return leases
+}
+
+// Run attaches the lessor to the given node, and counts down the leases while the node is the leader,
+// until the given context done. The lease operations fails with ErrNotRunning unless the lessor is running.
+func (l *Lessor) Run(ctx context.Context, node Node) error {
+	t := l.newTicker()
+	defer t.Stop()
+
+	l.mu.Lock()
+	l.node = node
+	l.leader = false
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.node = nil
+		l.leader = false
+		l.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C():
+			for _, c := range l.tick() {
+				l.expire(ctx, c)
+			}
+		}
+	}
+}
+
+// tick counts down the leases by a single tick while the local member is the leader,
+// and returns the revocation commands of the expired leases.
+func (l *Lessor) tick() []command {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.node == nil {
+		return nil
+	}
+
+	id := l.node.Whoami()
+	leader := id != 0 && l.node.Leader() == id
+	promoted := leader && !l.leader
+	l.leader = leader
+
+	if !leader {
+		return nil
+	}
+
+	// the previous leader count is unknown, restart the count of all leases.
+	if promoted {
+		for _, ls := range l.leases {
+			ls.ticks = l.ticks(ls.ttl)
+		}
+	}
+
+	var expired []command
+	for id, ls := range l.leases {
+		if ls.ticks > 0 {
+			ls.ticks--
+		}
+
+		if ls.ticks == 0 {
+			expired = append(expired, command{op: opExpire, id: id, rev: ls.rev})
+		}
+	}
+
+	return expired
+}
+
+// expire proposes the revocation of an expired lease.
+func (l *Lessor) expire(ctx context.Context, c command) {
+	ctx, cancel := context.WithTimeout(ctx, expireTimeout)
+	defer cancel()
+
+	if err := l.propose(ctx, c); err != nil {
+		l.logger.Warningf("raft/lease: failed to revoke expired lease %x: %v", c.id, err)
+	}
+}
+
+func (l *Lessor) propose(ctx context.Context, c command) error {
+	l.mu.Lock()
+	node := l.node
+	l.mu.Unlock()
+
+	if node == nil {
+		return ErrNotRunning
+	}
+
+	ctx = raft.ContextWithMetadata(ctx, map[string]string{metadataKey: metadataVersion})
+	return node.Replicate(ctx, c.marshal())
+}
+
+func (l *Lessor) lease(id uint64, ls *lease) Lease {
+	return Lease{
+		ID:        id,
+		TTL:       ls.ttl,
+		Remaining: time.Duration(ls.ticks) * l.interval,
+	}
+}
+
+// ticks returns the number of ticks of the given ttl, rounded up.
+func (l *Lessor) ticks(ttl time.Duration) int64 {
+	n := int64((ttl + l.interval - 1) / l.interval)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (l *Lessor) newTicker() raft.Ticker {
+	if l.clock == nil {
+		return realTicker{time.NewTicker(l.interval)}
+	}
+	return l.clock.NewTicker(l.interval)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,234 @@
+package lease
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/rafttest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLessor(t *testing.T) {
+	fsm := new(testStateMachine)
+	l := NewLessor(fsm, WithTickInterval(time.Second))
+	ctx := context.TODO()
+
+	// it reject the operations while not running.
+	_, err := l.Grant(ctx, time.Second)
+	require.ErrorIs(t, err, ErrNotRunning)
+
+	l.node = &testNode{l: l, id: 1}
+
+	_, err = l.Grant(ctx, 0)
+	require.ErrorIs(t, err, ErrInvalidTTL)
+
+	// it grant the lease.
+	ls, err := l.Grant(ctx, 3*time.Second)
+	require.NoError(t, err)
+	got, ok := l.Lease(ls.ID)
+	require.True(t, ok)
+	require.Equal(t, ls, got)
+	require.Equal(t, []Lease{ls}, l.Leases())
+
+	// it renew and revoke the lease.
+	require.NoError(t, l.Renew(ctx, ls.ID))
+	require.NoError(t, l.Revoke(ctx, ls.ID))
+	require.Empty(t, l.Leases())
+	require.Equal(t, []revocation{{Lease{ID: ls.ID, TTL: ls.TTL}, false}}, fsm.revoked)
+
+	// it return error when the lease not found.
+	require.ErrorIs(t, l.Renew(ctx, ls.ID), ErrLeaseNotFound)
+	require.ErrorIs(t, l.Revoke(ctx, ls.ID), ErrLeaseNotFound)
+
+	// it delegate the other entries to the wrapped state machine.
+	require.NoError(t, l.ApplyWithMetadata([]byte("data"), nil))
+	require.Equal(t, "data", fsm.data)
+}
+
+func TestLessorExpire(t *testing.T) {
+	fsm := new(testStateMachine)
+	l := NewLessor(fsm, WithTickInterval(time.Second))
+	node := &testNode{l: l, id: 1, leader: 2}
+	l.node = node
+	ctx := context.TODO()
+
+	ls, err := l.Grant(ctx, 2*time.Second)
+	require.NoError(t, err)
+
+	tick := func() {
+		for _, c := range l.tick() {
+			l.expire(ctx, c)
+		}
+	}
+
+	// it never expire the leases on the followers.
+	for i := 0; i < 5; i++ {
+		tick()
+	}
+	_, ok := l.Lease(ls.ID)
+	require.True(t, ok)
+
+	// it restart the count once promoted, and count down on the leader.
+	node.leader = 1
+	tick()
+	got, _ := l.Lease(ls.ID)
+	require.Equal(t, time.Second, got.Remaining)
+
+	// it restart the count once renewed.
+	require.NoError(t, l.Renew(ctx, ls.ID))
+	tick()
+	got, _ = l.Lease(ls.ID)
+	require.Equal(t, time.Second, got.Remaining)
+
+	// it revoke the lease once expired.
+	tick()
+	_, ok = l.Lease(ls.ID)
+	require.False(t, ok)
+	require.Equal(t, []revocation{{Lease{ID: ls.ID, TTL: ls.TTL}, true}}, fsm.revoked)
+}
+
+func TestLessorRun(t *testing.T) {
+	clock := rafttest.NewVirtualClock()
+	fsm := new(testStateMachine)
+	l := NewLessor(fsm, WithClock(clock), WithTickInterval(time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- l.Run(ctx, &testNode{l: l, id: 1})
+	}()
+
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.node != nil
+	}, time.Second, time.Millisecond)
+
+	ls, err := l.Grant(ctx, 2*time.Second)
+	require.NoError(t, err)
+
+	// it revoke the lease once expired, the last tick wait the revocation of the previous one.
+	clock.Advance(3 * time.Second)
+	require.Equal(t, []revocation{{Lease{ID: ls.ID, TTL: ls.TTL}, true}}, fsm.revoked)
+
+	// it detach the node once stopped.
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	_, err = l.Grant(context.Background(), time.Second)
+	require.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestLessorExpireRenewed(t *testing.T) {
+	l := NewLessor(new(testStateMachine))
+	l.node = &testNode{l: l, id: 1}
+
+	ls, err := l.Grant(context.TODO(), time.Second)
+	require.NoError(t, err)
+	stale := command{op: opExpire, id: ls.ID, rev: l.leases[ls.ID].rev}
+	require.NoError(t, l.Renew(context.TODO(), ls.ID))
+
+	// it ignore the expiry proposed before the lease renewed.
+	require.NoError(t, l.ApplyWithMetadata(stale.marshal(), map[string]string{metadataKey: metadataVersion}))
+	_, ok := l.Lease(ls.ID)
+	require.True(t, ok)
+}
+
+func TestLessorSnapshot(t *testing.T) {
+	fsm := &testStateMachine{data: "data"}
+	l := NewLessor(fsm)
+	l.node = &testNode{l: l, id: 1}
+
+	ls, err := l.Grant(context.TODO(), time.Second)
+	require.NoError(t, err)
+
+	rc, err := l.Snapshot()
+	require.NoError(t, err)
+
+	restored := new(testStateMachine)
+	rl := NewLessor(restored)
+	err = rl.Restore(rc)
+	require.NoError(t, err)
+	require.Equal(t, "data", restored.data)
+	require.Equal(t, l.Leases(), rl.Leases())
+	require.Equal(t, l.rev, rl.rev)
+	require.Equal(t, l.leases[ls.ID].rev, rl.leases[ls.ID].rev)
+
+	err = rl.Restore(io.NopCloser(strings.NewReader("")))
+	require.Error(t, err)
+}
+
+func TestCommandMarshal(t *testing.T) {
+	table := []command{
+		{op: opGrant, id: 1, ttl: time.Minute},
+		{op: opRenew, id: 2},
+		{op: opRevoke, id: 3},
+		{op: opExpire, id: 4, rev: 5},
+	}
+
+	for _, c := range table {
+		got := command{}
+		err := got.unmarshal(c.marshal())
+		require.NoError(t, err)
+		require.Equal(t, c, got)
+	}
+
+	err := new(command).unmarshal([]byte{100, 1})
+	require.Error(t, err)
+}
+
+// testNode applies the replicated entries directly to the lessor.
+type testNode struct {
+	l      *Lessor
+	id     uint64
+	leader uint64
+}
+
+func (n *testNode) Replicate(ctx context.Context, data []byte) error {
+	return n.l.ApplyWithMetadata(data, raft.MetadataFromContext(ctx))
+}
+
+func (n *testNode) Leader() uint64 {
+	if n.leader == 0 {
+		return n.id
+	}
+	return n.leader
+}
+
+func (n *testNode) Whoami() uint64 {
+	return n.id
+}
+
+type revocation struct {
+	lease   Lease
+	expired bool
+}
+
+type testStateMachine struct {
+	data    string
+	revoked []revocation
+}
+
+func (sm *testStateMachine) Apply(data []byte) error {
+	sm.data = string(data)
+	return nil
+}
+
+func (sm *testStateMachine) Snapshot() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(sm.data)), nil
+}
+
+func (sm *testStateMachine) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	sm.data = string(b)
+	return err
+}
+
+func (sm *testStateMachine) LeaseRevoked(l Lease, expired bool) error {
+	sm.revoked = append(sm.revoked, revocation{l, expired})
+	return nil
+}
@@ -5,16 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
+	"reflect"
+	"sync"
 	"time"
 
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/raft/v3/tracker"
 
+	rafterrors "github.com/shaj13/raft/errors"
 	"github.com/shaj13/raft/internal/membership"
 	"github.com/shaj13/raft/internal/raftengine"
 	"github.com/shaj13/raft/internal/raftpb"
 	"github.com/shaj13/raft/internal/storage"
 	"github.com/shaj13/raft/internal/storage/disk"
+	"github.com/shaj13/raft/internal/storage/memory"
 	"github.com/shaj13/raft/internal/transport"
 	etransport "github.com/shaj13/raft/transport"
 )
@@ -24,16 +28,76 @@ var (
 	// Shutdown or when it has not started.
 	ErrNodeStopped = raftengine.ErrStopped
 	// ErrNotLeader is returned when an operation can't be completed on a
-	// follower or candidate node
-	ErrNotLeader = errors.New("raft: node is not the leader")
+	// follower or candidate node, the returned error may be a *NotLeaderError
+	// that carries the leader hint.
+	ErrNotLeader = rafterrors.ErrNotLeader
+	// ErrNoLeader is returned when leader lost, or no elected cluster leader.
+	ErrNoLeader = raftengine.ErrNoLeader
+	// ErrOverloaded is returned when the node buffers are full and the request dropped.
+	ErrOverloaded = raftengine.ErrOverloaded
+	// ErrProposalDropped is returned by the Node Replicate method when the proposal dropped
+	// before being handed to raft, e.g. its context expired while queued, it will never be committed.
+	ErrProposalDropped = raftengine.ErrProposalDropped
+	// ErrMemberNotFound is returned when the requested member is not part of the cluster.
+	ErrMemberNotFound = rafterrors.ErrMemberNotFound
+	// ErrCorrupted is returned when the persisted or received data is corrupted or inconsistent,
+	// e.g. ErrSnapshotNotInWAL, ErrEntriesGap, ErrCommitOutOfRange, and ErrSnapshotCorrupted.
+	ErrCorrupted = rafterrors.ErrCorrupted
+	// ErrApplyStalled is returned by the Node Health method when the applied index not advancing.
+	ErrApplyStalled = rafterrors.ErrApplyStalled
 	// ErrAlreadySnapshotting can be returned by the StateMachine.Snapshot method
 	// to indicate that a snapshot is already in progress.
 	ErrAlreadySnapshotting = raftengine.ErrAlreadySnapshotting
 	// ErrFailedPrecondition can be returned by the StateMachine.Snapshot method
 	// to indicate that the precondition for creating a snapshot is not met.
 	ErrFailedPrecondition = raftengine.ErrFailedPrecondition
+	// ErrNoSpace is returned by the Node Replicate method when the cluster
+	// has an active NOSPACE alarm.
+	ErrNoSpace = raftengine.ErrNoSpace
+	// ErrMetadataTooLarge is returned by the Node Replicate method,
+	// when the metadata attached by ContextWithMetadata exceeds MaxMetadataSize.
+	ErrMetadataTooLarge = raftengine.ErrMetadataTooLarge
+	// ErrJoinRejected is returned by the Node Start method when the cluster rejected the join request,
+	// e.g. denied by the admission policy or unauthenticated.
+	ErrJoinRejected = raftengine.ErrJoinRejected
+	// ErrClusterNotReady is returned by the Node Start method when the cluster can't serve the join request,
+	// e.g. unreachable or no elected leader, after the join retries exhausted, See WithJoinRetry.
+	ErrClusterNotReady = raftengine.ErrClusterNotReady
+	// ErrIncompatibleVersion is returned by the Node Start method when the cluster rejected the join request,
+	// since the node protocol version is older than the cluster version, i.e. the cluster can't be downgraded.
+	ErrIncompatibleVersion = raftengine.ErrIncompatibleVersion
+	// ErrFeatureUnsupported is returned by the Node DisarmAlarm method when the alarms
+	// not supported yet by all the cluster members, e.g. during a rolling upgrade.
+	ErrFeatureUnsupported = raftengine.ErrFeatureUnsupported
+	// ErrClusterMismatch is returned by the transport handlers, wrapped by ErrUnauthenticated,
+	// when the request source belongs to a different cluster, e.g. a node restored from another cluster backup.
+	ErrClusterMismatch = raftengine.ErrClusterMismatch
+	// ErrCompacted is returned by the Node Watch method when the requested index
+	// compacted from the retained log entries, the watcher must resync from the state machine.
+	ErrCompacted = raftengine.ErrCompacted
+	// ErrSnapshotNotInWAL is returned by the Node Start method when the WAL
+	// does not cover the newest snapshot, e.g. WAL segments were removed.
+	ErrSnapshotNotInWAL = storage.ErrSnapshotNotInWAL
+	// ErrEntriesGap is returned by the Node Start method when the WAL entries
+	// does not directly follow the newest snapshot.
+	ErrEntriesGap = storage.ErrEntriesGap
+	// ErrCommitOutOfRange is returned by the Node Start method when the persisted
+	// commit index is out of the range of the snapshot and WAL entries.
+	ErrCommitOutOfRange = storage.ErrCommitOutOfRange
+	// ErrSnapshotCorrupted is returned when a snapshot file data
+	// does not match its checksums.
+	ErrSnapshotCorrupted = storage.ErrSnapshotCorrupted
 )
 
+// NotLeaderError is returned when an operation can't be completed on a
+// follower or candidate node, it carries the known leader hint and matches ErrNotLeader.
+//
+//	var nle *raft.NotLeaderError
+//	if errors.As(err, &nle) {
+//		// retry against nle.Address.
+//	}
+type NotLeaderError = rafterrors.NotLeaderError
+
 // NewNode construct a new node from the given configuration.
 // The returned node is in a stopped state, therefore it must be start explicitly.
 func NewNode(fsm StateMachine, proto etransport.Proto, opts ...Option) *Node {
@@ -46,7 +110,11 @@ func NewNode(fsm StateMachine, proto etransport.Proto, opts ...Option) *Node {
 	cfg := newConfig(opts...)
 	cfg.fsm = fsm
 	cfg.controller = ctrl
-	cfg.storage = disk.New(cfg)
+	if cfg.inMemory {
+		cfg.storage = memory.New()
+	} else {
+		cfg.storage = disk.New(cfg)
+	}
 	cfg.dial = dialer(cfg)
 	cfg.pool = membership.New(cfg)
 	cfg.engine = raftengine.New(cfg)
@@ -58,15 +126,117 @@ func NewNode(fsm StateMachine, proto etransport.Proto, opts ...Option) *Node {
 	node.dial = cfg.dial
 	node.cfg = cfg
 	node.handler = newHandler(cfg)
+	node.quota = newProposalQuota(cfg)
 
 	ctrl.node = node
 	ctrl.engine = cfg.engine
 	ctrl.pool = cfg.pool
 	ctrl.storage = cfg.storage
+	ctrl.admission = newAdmission(cfg.admission, cfg.logger)
+	ctrl.auth = cfg.auth
+	ctrl.metrics = cfg.transportMetrics
+	ctrl.relay = cfg.relay
+	ctrl.quota = node.quota
 
 	return node
 }
 
+// ValidateSnapshot validates the snapshot file of the given path without touching the live node,
+// it decodes the snapshot, verifies its checksums and members metadata, and restores it
+// into a scratch state machine returned by newFSM, unless newFSM is nil.
+// One use case for this feature would be checking archived snapshots before trusting them for restores.
+//
+// Note: delta snapshots can't be restored without the snapshots they chained onto,
+// therefore ValidateSnapshot return an error when given a delta snapshot and newFSM.
+func ValidateSnapshot(path string, newFSM func() StateMachine) error {
+	sf, err := disk.ReadSnapshot(context.Background(), path)
+	if err != nil {
+		return err
+	}
+
+	defer sf.Data.Close()
+
+	if err := validateSnapshotMembers(sf); err != nil {
+		return fmt.Errorf("raft: invalid snapshot %s: %w", path, err)
+	}
+
+	if newFSM == nil {
+		return nil
+	}
+
+	if sf.BaseIndex != 0 {
+		return fmt.Errorf("raft: snapshot %s is a delta snapshot, it can't be restored alone", path)
+	}
+
+	fsm := newFSM()
+	if fsm == nil {
+		return errors.New("raft: cannot validate snapshot using nil state machine")
+	}
+
+	if err := fsm.Restore(sf.Data); err != nil {
+		return fmt.Errorf("raft: restore snapshot %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReplayWALArchive calls the given function with the replicated data entries archived into the given
+// object store, with an index greater than the given index, in the log order, See WithWALArchive.
+// One use case for this feature would be rebuilding a state machine restored from a snapshot
+// up to a point in time, or auditing the full mutation history.
+func ReplayWALArchive(ctx context.Context, s SnapshotStore, after uint64, fn func(ev WatchEvent) error) error {
+	return disk.ReplayArchive(ctx, s, func(ent etcdraftpb.Entry) error {
+		if ent.Index <= after || ent.Type != etcdraftpb.EntryNormal || len(ent.Data) == 0 {
+			return nil
+		}
+
+		r := new(raftpb.Replicate)
+		if err := r.Unmarshal(ent.Data); err != nil {
+			return fmt.Errorf("raft: decode archived entry %d: %w", ent.Index, err)
+		}
+
+		if r.Type != raftpb.ReplicateData {
+			return nil
+		}
+
+		return fn(WatchEvent{
+			Index:    ent.Index,
+			Term:     ent.Term,
+			Data:     r.Data,
+			Metadata: r.Metadata,
+		})
+	})
+}
+
+// validateSnapshotMembers verifies the snapshot members metadata
+// is consistent with the snapshot conf state.
+func validateSnapshotMembers(sf *storage.Snapshot) error {
+	membs := make(map[uint64]raftpb.Member, len(sf.Members))
+	for _, m := range sf.Members {
+		if m.ID == 0 {
+			return errors.New("member with zero id")
+		}
+
+		if _, ok := membs[m.ID]; ok {
+			return fmt.Errorf("duplicate member %x", m.ID)
+		}
+
+		membs[m.ID] = m
+	}
+
+	cs := sf.Raw.Metadata.ConfState
+	for _, set := range [][]uint64{cs.Voters, cs.Learners, cs.VotersOutgoing, cs.LearnersNext} {
+		for _, id := range set {
+			m, ok := membs[id]
+			if !ok || m.Type == raftpb.RemovedMember {
+				return fmt.Errorf("conf state member %x not found in the snapshot members", id)
+			}
+		}
+	}
+
+	return nil
+}
+
 // NewNodeGroup returns a new NodeGroup.
 // the returned node group will lazily initialize,
 // from the first node registered within it, So it's recommended to apply
@@ -177,6 +347,8 @@ type Node struct {
 	pool    membership.Pool
 	storage storage.Storage
 	engine  raftengine.Engine
+	quota   *proposalQuota
+	health  healthTracker
 	cfg     *config
 	// exec pre conditions, its used by tests.
 	exec func(fns ...func(c *Node) error) error
@@ -204,6 +376,35 @@ func (n *Node) Handler() etransport.Handler {
 	return n.handler
 }
 
+// Status returns the current raft node status.
+// It return ErrNodeStopped, if the node not yet started or stopped.
+func (n *Node) Status() (Status, error) {
+	rs, err := n.engine.Status()
+	if err != nil {
+		return Status{}, err
+	}
+
+	s := Status{
+		ID:             rs.ID,
+		Role:           rs.RaftState,
+		Term:           rs.Term,
+		Vote:           rs.Vote,
+		Leader:         rs.Lead,
+		LeadTransferee: rs.LeadTransferee,
+		Committed:      rs.Commit,
+		Applied:        rs.Applied,
+	}
+
+	if rs.Progress != nil {
+		s.Progress = make(map[uint64]MemberProgress, len(rs.Progress))
+		for id, pr := range rs.Progress {
+			s.Progress[id] = newMemberProgress(pr)
+		}
+	}
+
+	return s, nil
+}
+
 // LinearizableRead implies that once a write completes,
 // all later reads should return the value of that write,
 // or the value of a later write.
@@ -221,10 +422,95 @@ func (n *Node) LinearizableRead(ctx context.Context) error {
 	return n.engine.LinearizableRead(ctx)
 }
 
+// LinearizableGet performs a linearizable read, then calls the given function with the state machine,
+// so the read function observes all the writes completed before the call.
+// The function is guaranteed to not run concurrently with a snapshot restore,
+// which swaps the state machine state, but may run concurrently with applying new entries.
+//
+//	var v string
+//	err := n.LinearizableGet(ctx, func(sm raft.StateMachine) error {
+//		v = sm.(*kv).Get("key")
+//		return nil
+//	})
+func (n *Node) LinearizableGet(ctx context.Context, fn func(sm StateMachine) error) error {
+	if err := n.LinearizableRead(ctx); err != nil {
+		return err
+	}
+
+	return n.engine.ReadStateMachine(fn)
+}
+
+// AuditLog performs a linearizable read, then returns the records of the replicated audit log
+// applied after the given raft log index, in the index order.
+// The conf changes, the leadership transfers, the forced snapshots, and the restores are recorded,
+// along with the metadata attached to the action context via ContextWithMetadata, e.g. the principal.
+// Consumers stream the audit log by passing the index of the last received record.
+//
+// The conf changes recorded once applied, from the conf change entry itself, including the conf changes
+// proposed by the node, e.g. the staging members promotion and the dead members removal.
+// The other actions have no log entry, therefore recorded asynchronously by a follow-up entry,
+// which is lost if the node crashes right after the action.
+//
+// Note: the node retains the latest records, the older records are dropped, See WithAuditLogRetention.
+func (n *Node) AuditLog(ctx context.Context, sinceIndex uint64) ([]AuditRecord, error) {
+	if err := n.LinearizableRead(ctx); err != nil {
+		return nil, err
+	}
+
+	return n.engine.AuditLog(sinceIndex), nil
+}
+
+// Watch returns a channel streaming the replicated data entries applied to the local state machine,
+// in the log order, starting from the given raft log index, or from the next applied entry if zero.
+// The entries applied before the call replayed from the log entries retained since the latest compaction,
+// so a consumer resumes a change feed by passing the index of the last received event plus one.
+//
+// The channel closed once the given context done or the node stopped.
+// A watcher falling behind the log compaction receives an event carrying ErrCompacted,
+// then its channel closed, See WithRetainEntries.
+func (n *Node) Watch(ctx context.Context, fromIndex uint64) (<-chan WatchEvent, error) {
+	return n.engine.Watch(ctx, fromIndex)
+}
+
+// MembershipSnapshot performs a linearizable read, then returns the cluster members
+// along with the raft configuration they belongs to. Therefore, controllers acting on the
+// membership never act on a stale view during concurrent conf changes.
+func (n *Node) MembershipSnapshot(ctx context.Context) (MembershipSnapshot, error) {
+	if err := n.LinearizableRead(ctx); err != nil {
+		return MembershipSnapshot{}, err
+	}
+
+	for {
+		rs, err := n.engine.Status()
+		if err != nil {
+			return MembershipSnapshot{}, err
+		}
+
+		cs := (&tracker.ProgressTracker{Config: rs.Config}).ConfState()
+		membs := n.pool.Members()
+
+		// read the status again, to detect a conf change applied while reading the members.
+		rs, err = n.engine.Status()
+		if err != nil {
+			return MembershipSnapshot{}, err
+		}
+
+		if reflect.DeepEqual(cs, (&tracker.ProgressTracker{Config: rs.Config}).ConfState()) {
+			return newMembershipSnapshot(rs.Applied, cs, membs), nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return MembershipSnapshot{}, err
+		}
+	}
+}
+
 // Snapshot is used to manually force node to take a snapshot. Returns a io.ReadCloser
 // that can be used to to read snapshot file.
 // the caller must invoke close method on the returned io.ReadCloser explicitly,
 // Otherwise, the underlying os.File remain open.
+// Note: the snapshot might be a delta snapshot when the state machine
+// implements IncrementalStateMachine.
 func (n *Node) Snapshot() (io.ReadCloser, error) {
 	err := n.preCond(
 		joined(),
@@ -240,7 +526,8 @@ func (n *Node) Snapshot() (io.ReadCloser, error) {
 	}
 
 	meta := snap.Metadata
-	return n.storage.Snapshotter().Reader(meta.Term, meta.Index)
+	c := n.cfg.snapshotCompression()
+	return n.storage.Snapshotter().Reader(n.cfg.Context(), meta.Term, meta.Index, c)
 }
 
 // TransferLeadership proposes to transfer leadership to the given member id.
@@ -249,6 +536,7 @@ func (n *Node) TransferLeadership(ctx context.Context, id uint64) error {
 		joined(),
 		notMember(id),
 		memberRemoved(id),
+		memberDraining(id),
 		noLeader(),
 		notType(n.Whoami(), VoterMember),
 		disableForwarding(), // TODO: verify this.
@@ -262,7 +550,9 @@ func (n *Node) TransferLeadership(ctx context.Context, id uint64) error {
 	return n.engine.TransferLeadership(ctx, id)
 }
 
-// Stepdown proposes to transfer leadership to the longest active member in the cluster.
+// Stepdown proposes to transfer leadership to the best caught-up active voter member in the cluster,
+// the member with the highest replicated log index, or the longest active one on ties,
+// and returns when the member becomes the leader.
 // This must be run on the leader or it will fail.
 func (n *Node) Stepdown(ctx context.Context) error {
 	err := n.preCond(
@@ -275,27 +565,30 @@ func (n *Node) Stepdown(ctx context.Context) error {
 		return err
 	}
 
-	// we can do the following, because member's active since is given from the current node clock.
-	longest := time.Now().Add(math.MaxInt64)
-	id := n.Whoami()
-	cond := func(m Member) bool {
-		since := m.ActiveSince()
-		ok := m.IsActive() && m.Type() == VoterMember && since.Before(longest) && id != m.ID()
-		if ok {
-			longest = since
-			return true
-		}
+	rs, _ := n.engine.Status()
 
-		return false
+	var (
+		transferee Member
+		match      uint64
+	)
+
+	// we can compare the active since, because member's active since is given from the current node clock.
+	for _, m := range n.members(func(m Member) bool {
+		return m.ID() != rs.ID && m.IsActive() && m.Type() == VoterMember && !m.Draining()
+	}) {
+		pr := rs.Progress[m.ID()]
+		if transferee == nil ||
+			pr.Match > match ||
+			(pr.Match == match && m.ActiveSince().Before(transferee.ActiveSince())) {
+			transferee, match = m, pr.Match
+		}
 	}
 
-	// get longest active member then transfer leadership to it.
-	membs := n.members(cond)
-	if len(membs) == 0 {
-		return errors.New("raft: failed to find longest active member")
+	if transferee == nil {
+		return errors.New("raft: failed to find an active voter member to transfer leadership to")
 	}
 
-	return n.engine.TransferLeadership(ctx, membs[0].ID())
+	return n.engine.TransferLeadership(ctx, transferee.ID())
 }
 
 // Start start the node and accepts incoming requests on the handler or on local node methods.
@@ -305,7 +598,7 @@ func (n *Node) Stepdown(ctx context.Context) error {
 func (n *Node) Start(opts ...StartOption) error {
 	cfg := new(startConfig)
 	cfg.apply(opts...)
-	return n.engine.Start(cfg.addr, cfg.operators...)
+	return n.engine.Start(cfg.address(), cfg.operators...)
 }
 
 // Leave proposes to remove current effective member.
@@ -322,6 +615,17 @@ func (n *Node) Leave(ctx context.Context) error {
 // the replication is complete,
 // Replicate returns the context's error, otherwise it returns any
 // error returned due to the replication.
+//
+// A context error means the data may still be committed, whereas ErrProposalDropped means
+// the proposal dropped before being handed to raft, e.g. the context expired while queued,
+// or raft rejected it, therefore the data will never be committed and safe to retry.
+//
+// Metadata attached to the context via ContextWithMetadata is delivered
+// alongside the data to the state machine and the apply hook.
+// The proposal scheduled by the priority attached via ContextWithPriority.
+//
+// Replicate returns ErrProposalTooLarge or ErrRateLimited when the proposal exceeds the node quotas,
+// See WithMaxProposalSize and WithProposalRateLimit.
 func (n *Node) Replicate(ctx context.Context, data []byte) error {
 	err := n.preCond(
 		joined(),
@@ -335,6 +639,10 @@ func (n *Node) Replicate(ctx context.Context, data []byte) error {
 		return err
 	}
 
+	if err := n.quota.admit("", len(data)); err != nil {
+		return err
+	}
+
 	return n.engine.ProposeReplicate(ctx, data)
 }
 
@@ -364,7 +672,7 @@ func (n *Node) UpdateMember(ctx context.Context, raw *RawMember) error {
 		return err
 	}
 
-	mem, _ := n.GetMemebr(raw.ID)
+	mem, _ := n.Member(raw.ID)
 	raw.Type = mem.Type()
 
 	return n.engine.ProposeConfChange(ctx, raw, etcdraftpb.ConfChangeUpdateNode)
@@ -398,7 +706,7 @@ func (n *Node) RemoveMember(ctx context.Context, id uint64) error {
 		return err
 	}
 
-	mem, _ := n.GetMemebr(id)
+	mem, _ := n.Member(id)
 	raw := mem.Raw()
 	raw.Type = raftpb.RemovedMember
 
@@ -444,8 +752,10 @@ func (n *Node) AddMember(ctx context.Context, raw *RawMember) error {
 	return n.engine.ProposeConfChange(ctx, raw, cct)
 }
 
-// PromoteMember proposes to promote a learner member to a voting member,
+// PromoteMember proposes to promote a learner or staging member to a voting member,
 // It considered complete after reaching a majority.
+// The promotion rejected when the member not caught up with the leader log yet,
+// or when the active voters can't form a quorum after promoting it, e.g. inactive member.
 // After committing the promotion, each member in the
 // cluster updates the given member type on its pool.
 //
@@ -457,6 +767,7 @@ func (n *Node) PromoteMember(ctx context.Context, id uint64) error {
 }
 
 // DemoteMember proposes to take away a member vote.
+// The demotion rejected when the remaining active voters can't form a quorum.
 // It considered complete after reaching a majority.
 // After committing the demotion, each member in the
 // cluster updates the given member type on its pool.
@@ -475,23 +786,178 @@ func (n *Node) DemoteMember(ctx context.Context, id uint64) error {
 		notType(id, VoterMember),
 		disableForwarding(),
 		available(),
+		quorumPreserved(id, LearnerMember),
 	)
 
 	if err != nil {
 		return err
 	}
 
-	mem, _ := n.GetMemebr(id)
+	mem, _ := n.Member(id)
 	raw := mem.Raw()
 	(&raw).Type = LearnerMember
 
 	return n.engine.ProposeConfChange(ctx, &raw, etcdraftpb.ConfChangeAddLearnerNode)
 }
 
+// DrainMember proposes to mark the given member as draining, to support rolling maintenance.
+// A draining member does not receive the leadership, and clients should stop targeting it,
+// See Member.Draining.
+// It considered complete after reaching a majority.
+// After committing the update, each member in the
+// cluster updates the given member on its pool.
+//
+// Note: DrainMember does not transfer the leadership of a draining leader,
+// use Stepdown or TransferLeadership.
+func (n *Node) DrainMember(ctx context.Context, id uint64) error {
+	return n.drainMember(ctx, id, true)
+}
+
+// UndrainMember proposes to unmark the given draining member,
+// once its maintenance is done. See DrainMember.
+func (n *Node) UndrainMember(ctx context.Context, id uint64) error {
+	return n.drainMember(ctx, id, false)
+}
+
+func (n *Node) drainMember(ctx context.Context, id uint64, drain bool) error {
+	err := n.preCond(
+		joined(),
+		notMember(id),
+		memberRemoved(id),
+		noLeader(),
+		notType(n.Whoami(), VoterMember),
+		disableForwarding(),
+		available(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	mem, _ := n.Member(id)
+	raw := mem.Raw()
+	(&raw).Draining = drain
+
+	return n.engine.ProposeConfChange(ctx, &raw, etcdraftpb.ConfChangeUpdateNode)
+}
+
+// Alarms returns the cluster active alarms.
+func (n *Node) Alarms() []Alarm {
+	return n.engine.Alarms()
+}
+
+// RecentEvents returns the last significant engine events, the oldest first,
+// such as elections, snapshot installs, conf changes, and dropped messages.
+// So postmortems don't depend solely on the enabled log level. See WithEventBufferSize.
+func (n *Node) RecentEvents() []Event {
+	return n.engine.RecentEvents()
+}
+
+// SlowOps returns the number of the state machine applies and the storage syncs
+// that exceeded their thresholds since the node started,
+// See WithSlowApplyThreshold and WithSlowSyncThreshold.
+func (n *Node) SlowOps() SlowOps {
+	return n.engine.SlowOps()
+}
+
+// Waiters returns the number of the outstanding waiters of the proposals,
+// the linearizable reads, and the conf changes results.
+// A steadily growing number indicates abandoned waiters, See WithMaxWaiters and WithWaiterTTL.
+func (n *Node) Waiters() int {
+	return n.engine.Waiters()
+}
+
+// ClusterID returns the id of the cluster the node belongs to,
+// generated when the cluster initialized, and received by the joining members.
+// It returns zero when the node not started yet or its state predates the cluster id.
+func (n *Node) ClusterID() uint64 {
+	return n.engine.ClusterID()
+}
+
+// ClusterVersion returns the protocol version supported by all the cluster members,
+// the minimum version advertised by the members.
+// During a rolling upgrade, it's raised once all the members upgraded and restarted.
+func (n *Node) ClusterVersion() uint32 {
+	return n.engine.ClusterVersion()
+}
+
+// Supports reports whether the given feature is active in the cluster,
+// i.e. all the cluster members support it.
+func (n *Node) Supports(f Feature) bool {
+	return n.engine.Supports(f)
+}
+
+// DisarmAlarm proposes to deactivate the given alarm,
+// It considered complete after reaching a majority.
+//
+// If the provided context expires before, the deactivation is complete,
+// DisarmAlarm returns the context's error, otherwise it returns any
+// error returned due to the deactivation.
+func (n *Node) DisarmAlarm(ctx context.Context, a *Alarm) error {
+	if a == nil {
+		return errors.New("raft: cannot disarm nil alarm")
+	}
+
+	err := n.preCond(
+		joined(),
+		noLeader(),
+		disableForwarding(),
+		available(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	ac := raftpb.AlarmChange{
+		Action: raftpb.DeactivateAlarm,
+		Alarm:  *a,
+	}
+
+	return n.engine.ProposeAlarm(ctx, ac)
+}
+
+// Member returns member associated to the given id if exist,
+// Otherwise, it return nil and false.
+func (n *Node) Member(id uint64) (Member, bool) {
+	mem, ok := n.pool.Get(id)
+	if !ok {
+		return nil, false
+	}
+	return member{mem, n.engine}, true
+}
+
 // GetMemebr returns member associated to the given id if exist,
 // Otherwise, it return nil and false.
+//
+// Deprecated: use Member.
 func (n *Node) GetMemebr(id uint64) (Member, bool) {
-	return n.pool.Get(id)
+	return n.Member(id)
+}
+
+// LocalMember returns the current effective member,
+// It return nil and false, if the node stopped or not yet part of a raft cluster.
+func (n *Node) LocalMember() (Member, bool) {
+	return n.Member(n.Whoami())
+}
+
+// LeaderMember returns the raft cluster leader member, if there any.
+// Otherwise, it return nil and false.
+func (n *Node) LeaderMember() (Member, bool) {
+	return n.Member(n.Leader())
+}
+
+// notLeaderError returns a NotLeaderError that carries the given known leader hint.
+func (n *Node) notLeaderError(lead uint64) error {
+	err := &NotLeaderError{Leader: lead}
+	if lead == None {
+		return err
+	}
+
+	if m, ok := n.Member(lead); ok {
+		err.Address = m.Address()
+	}
+	return err
 }
 
 // Members returns the list of raft Members in the Cluster.
@@ -499,6 +965,88 @@ func (n *Node) Members() []Member {
 	return n.members(func(m Member) bool { return true })
 }
 
+// Ready returns a channel that's closed once the node has an elected cluster leader,
+// and has replayed its WAL, so the application can gate its serving endpoints on raft readiness.
+//
+// Note: the channel renewed on each Start, Ready must be called again after restarting the node.
+func (n *Node) Ready() <-chan struct{} {
+	return n.engine.Ready()
+}
+
+// WaitForLeader blocks until the raft cluster has an elected leader known by the node,
+// or the context done. It can be called before Start.
+func (n *Node) WaitForLeader(ctx context.Context) error {
+	ticker := time.NewTicker(n.cfg.tickInterval)
+	defer ticker.Stop()
+
+	for {
+		if n.Leader() != None {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health returns nil if the node is serving, i.e. it has an elected cluster leader,
+// and its applied index is advancing toward the committed index.
+// Otherwise, it returns ErrNoLeader, or ErrApplyStalled when the applied index
+// not advanced within the health stall timeout, See WithHealthStallTimeout.
+//
+// Health is reflected by the transports health checks,
+// e.g. the gRPC health service and the HTTP healthz endpoint.
+func (n *Node) Health() error {
+	st, err := n.engine.Status()
+	if err != nil {
+		return err
+	}
+
+	if st.Lead == None {
+		return ErrNoLeader
+	}
+
+	if n.health.stalled(st.Applied, st.Commit, n.cfg.healthStall) {
+		return fmt.Errorf("%w: applied index %d, committed index %d", ErrApplyStalled, st.Applied, st.Commit)
+	}
+
+	return nil
+}
+
+// healthTracker tracks the applied index progress.
+type healthTracker struct {
+	mu      sync.Mutex
+	applied uint64
+	since   time.Time
+}
+
+// stalled reports whether the given applied index lags the given committed index,
+// and not advanced within the given timeout.
+func (h *healthTracker) stalled(applied, committed uint64, timeout time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if applied >= committed || applied != h.applied || h.since.IsZero() {
+		h.applied = applied
+		h.since = now
+		return false
+	}
+
+	return now.Sub(h.since) > timeout
+}
+
+// LeaderChanges returns a channel that receives the raft cluster leader changes,
+// and the current node raft state changes.
+// The channel holds only the latest change, the older pending changes are coalesced,
+// so slow receivers always observe the latest leader.
+func (n *Node) LeaderChanges() <-chan LeaderInfo {
+	return n.engine.LeaderChanges()
+}
+
 // Whoami returns the id associated with current effective member.
 // It return None, if the node stopped or not yet part of a raft cluster.
 func (n *Node) Whoami() uint64 {
@@ -516,13 +1064,88 @@ func (n *Node) Leader() uint64 {
 func (n *Node) members(cond func(m Member) bool) []Member {
 	mems := []Member{}
 	for _, m := range n.pool.Members() {
-		if cond(m) {
-			mems = append(mems, m)
+		mem := member{m, n.engine}
+		if cond(mem) {
+			mems = append(mems, mem)
 		}
 	}
 	return mems
 }
 
+// newMembershipSnapshot returns a membership snapshot of the given conf state,
+// the members not yet or no longer part of the conf state are left out.
+func newMembershipSnapshot(index uint64, cs ConfState, membs []membership.Member) MembershipSnapshot {
+	ids := make(map[uint64]struct{})
+	for _, list := range [][]uint64{cs.Voters, cs.VotersOutgoing, cs.Learners, cs.LearnersNext} {
+		for _, id := range list {
+			ids[id] = struct{}{}
+		}
+	}
+
+	ms := MembershipSnapshot{
+		Index:     index,
+		ConfState: cs,
+		Members:   []RawMember{},
+	}
+
+	for _, m := range membs {
+		if _, ok := ids[m.ID()]; ok || m.Type() == RemovedMember {
+			ms.Members = append(ms.Members, m.Raw())
+		}
+	}
+
+	return ms
+}
+
+// member wraps a pool member to expose its replication progress.
+type member struct {
+	membership.Member
+	engine raftengine.Engine
+}
+
+func (m member) Info() MemberInfo {
+	var lead uint64
+	if rs, err := m.engine.Status(); err == nil {
+		lead = rs.Lead
+	}
+
+	return MemberInfo{
+		ID:          m.ID(),
+		Address:     m.Address(),
+		Type:        m.Type(),
+		Labels:      m.Labels(),
+		Active:      m.IsActive(),
+		ActiveSince: m.ActiveSince(),
+		LastContact: m.LastContact(),
+		Draining:    m.Draining(),
+		Version:     m.Version(),
+		Leader:      lead != None && lead == m.ID(),
+	}
+}
+
+func (m member) Progress() (MemberProgress, bool) {
+	rs, err := m.engine.Status()
+	if err != nil {
+		return MemberProgress{}, false
+	}
+
+	pr, ok := rs.Progress[m.ID()]
+	if !ok {
+		return MemberProgress{}, false
+	}
+
+	return newMemberProgress(pr), true
+}
+
+func newMemberProgress(pr tracker.Progress) MemberProgress {
+	return MemberProgress{
+		State:        pr.State.String(),
+		Match:        pr.Match,
+		Next:         pr.Next,
+		RecentActive: pr.RecentActive,
+	}
+}
+
 func (n *Node) preCond(fns ...func(c *Node) error) error {
 	if n.exec != nil {
 		return n.exec(fns...)
@@ -542,9 +1165,10 @@ func (n *Node) promoteMember(ctx context.Context, id uint64, forwarded bool) err
 		notMember(id),
 		noLeader(),
 		notType(n.Whoami(), VoterMember),
-		notType(id, LearnerMember),
+		notPromotable(id),
 		disableForwarding(),
 		available(),
+		quorumPreserved(id, VoterMember),
 	)
 
 	if err != nil {
@@ -562,11 +1186,11 @@ func (n *Node) promoteMember(ctx context.Context, id uint64, forwarded bool) err
 		return raftengine.ErrNoLeader
 	}
 
-	mem, _ := n.GetMemebr(id)
+	mem, _ := n.Member(id)
 	raw := mem.Raw()
 
 	if rs.Progress == nil {
-		lmem, ok := n.GetMemebr(rs.Lead)
+		lmem, ok := n.Member(rs.Lead)
 		// leader lost, because rs.Lead = None.
 		if !ok {
 			return raftengine.ErrNoLeader
@@ -619,10 +1243,43 @@ func available() func(c *Node) error {
 	}
 }
 
+// quorumPreserved verifies that the active voters still form a quorum,
+// after changing the given member type to the given type.
+func quorumPreserved(id uint64, t MemberType) func(c *Node) error {
+	return func(c *Node) error {
+		voters, reachables := 0, 0
+		for _, m := range c.Members() {
+			mt := m.Type()
+			if m.ID() == id {
+				mt = t
+			}
+
+			if mt != VoterMember {
+				continue
+			}
+
+			voters++
+			if m.IsActive() {
+				reachables++
+			}
+		}
+
+		if voters == 0 {
+			return fmt.Errorf("raft: operation not permitted, member %x is the last voter", id)
+		}
+
+		if reachables < voters/2+1 {
+			return fmt.Errorf("raft: operation not permitted, changing member %x to a %s loses the quorum", id, t)
+		}
+
+		return nil
+	}
+}
+
 func notMember(id uint64) func(c *Node) error {
 	return func(c *Node) error {
-		if _, ok := c.GetMemebr(id); !ok {
-			return fmt.Errorf("raft: unknown member %x", id)
+		if _, ok := c.Member(id); !ok {
+			return fmt.Errorf("%w: unknown member %x", ErrMemberNotFound, id)
 		}
 		return nil
 	}
@@ -630,7 +1287,7 @@ func notMember(id uint64) func(c *Node) error {
 
 func memberRemoved(id uint64) func(c *Node) error {
 	return func(c *Node) error {
-		m, ok := c.GetMemebr(id)
+		m, ok := c.Member(id)
 		if ok && m.Type() == RemovedMember {
 			return fmt.Errorf("raft: member %x removed", id)
 		}
@@ -638,6 +1295,16 @@ func memberRemoved(id uint64) func(c *Node) error {
 	}
 }
 
+func memberDraining(id uint64) func(c *Node) error {
+	return func(c *Node) error {
+		m, ok := c.Member(id)
+		if ok && m.Draining() {
+			return fmt.Errorf("raft: member %x is draining", id)
+		}
+		return nil
+	}
+}
+
 func addressInUse(mid uint64, addr string) func(c *Node) error {
 	return func(c *Node) error {
 		membs := c.members(func(m Member) bool {
@@ -654,8 +1321,8 @@ func addressInUse(mid uint64, addr string) func(c *Node) error {
 
 func notLeader() func(c *Node) error {
 	return func(c *Node) error {
-		if c.Whoami() != c.Leader() {
-			return ErrNotLeader
+		if lead := c.Leader(); c.Whoami() != lead {
+			return c.notLeaderError(lead)
 		}
 		return nil
 	}
@@ -672,7 +1339,7 @@ func leader(id uint64) func(c *Node) error {
 
 func idInUse(id uint64) func(c *Node) error {
 	return func(c *Node) error {
-		if _, ok := c.GetMemebr(id); ok {
+		if _, ok := c.Member(id); ok {
 			return fmt.Errorf("raft: id used by member %x", id)
 		}
 		return nil
@@ -691,8 +1358,18 @@ func noLeader() func(c *Node) error {
 func disableForwarding() func(c *Node) error {
 	return func(c *Node) error {
 		disable := c.cfg.rcfg.DisableProposalForwarding
-		if c.Leader() != c.Whoami() && disable {
-			return ErrNotLeader
+		if lead := c.Leader(); lead != c.Whoami() && disable {
+			return c.notLeaderError(lead)
+		}
+		return nil
+	}
+}
+
+func notPromotable(id uint64) func(c *Node) error {
+	return func(c *Node) error {
+		mem, _ := c.Member(id)
+		if mt := mem.Type(); mt != LearnerMember && mt != StagingMember {
+			return fmt.Errorf("raft: memebr (%x) is a %s not a %s or %s", id, mt, LearnerMember, StagingMember)
 		}
 		return nil
 	}
@@ -700,7 +1377,7 @@ func disableForwarding() func(c *Node) error {
 
 func notType(id uint64, t MemberType) func(c *Node) error {
 	return func(c *Node) error {
-		mem, _ := c.GetMemebr(id)
+		mem, _ := c.Member(id)
 		if mt := mem.Type(); mt != t {
 			return fmt.Errorf("raft: memebr (%x) is a %s not a %s", id, mt, t)
 		}
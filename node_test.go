@@ -2,7 +2,12 @@ package raft
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"hash/crc64"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,9 +18,11 @@ import (
 	storagemock "github.com/shaj13/raft/internal/mocks/storage"
 	transportmock "github.com/shaj13/raft/internal/mocks/transport"
 	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/storage"
 	"github.com/shaj13/raft/internal/transport"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/quorum"
 	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 	"go.etcd.io/etcd/raft/v3/tracker"
 )
@@ -54,6 +61,7 @@ func TestNodePreConditions(t *testing.T) {
 				joined(),
 				notMember(0),
 				memberRemoved(0),
+				memberDraining(0),
 				noLeader(),
 				notType(0, 0),
 				disableForwarding(),
@@ -123,9 +131,10 @@ func TestNodePreConditions(t *testing.T) {
 				notMember(0),
 				noLeader(),
 				notType(0, 0),
-				notType(0, 0),
+				notPromotable(0),
 				disableForwarding(),
 				available(),
+				quorumPreserved(0, 0),
 			},
 		},
 		{
@@ -140,6 +149,28 @@ func TestNodePreConditions(t *testing.T) {
 				notType(0, 0),
 				disableForwarding(),
 				available(),
+				quorumPreserved(0, 0),
+			},
+		},
+		{
+			call: func(n *Node) error { return n.DrainMember(ctx, 0) },
+			expected: []func(c *Node) error{
+				joined(),
+				notMember(0),
+				memberRemoved(0),
+				noLeader(),
+				notType(0, 0),
+				disableForwarding(),
+				available(),
+			},
+		},
+		{
+			call: func(n *Node) error { return n.DisarmAlarm(ctx, &Alarm{}) },
+			expected: []func(c *Node) error{
+				joined(),
+				noLeader(),
+				disableForwarding(),
+				available(),
 			},
 		},
 	}
@@ -215,6 +246,125 @@ func TestNodeLinearizableRead(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestNodeStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	n := new(Node)
+	n.engine = eng
+
+	// it return the engine error.
+	eng.EXPECT().Status().Return(raft.Status{}, ErrNodeStopped)
+	_, err := n.Status()
+	require.ErrorIs(t, err, ErrNodeStopped)
+
+	// it return the node status.
+	rs := raft.Status{
+		BasicStatus: raft.BasicStatus{
+			ID:        1,
+			HardState: etcdraftpb.HardState{Term: 2, Vote: 1, Commit: 10},
+			SoftState: raft.SoftState{Lead: 1, RaftState: raft.StateLeader},
+			Applied:   9,
+		},
+		Progress: map[uint64]tracker.Progress{
+			2: {Match: 5, Next: 6, State: tracker.StateReplicate, RecentActive: true},
+		},
+	}
+	eng.EXPECT().Status().Return(rs, nil)
+	s, err := n.Status()
+	require.NoError(t, err)
+	require.Equal(t, Status{
+		ID:        1,
+		Role:      StateLeader,
+		Term:      2,
+		Vote:      1,
+		Leader:    1,
+		Committed: 10,
+		Applied:   9,
+		Progress: map[uint64]MemberProgress{
+			2: {State: "StateReplicate", Match: 5, Next: 6, RecentActive: true},
+		},
+	}, s)
+}
+
+func TestNodeLinearizableGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	eng.EXPECT().Status().Return(raft.Status{}, nil).AnyTimes()
+	n := new(Node)
+	n.engine = eng
+	n.exec = testPreCond
+	called := false
+	fn := func(StateMachine) error {
+		called = true
+		return nil
+	}
+
+	// it return the linearizable read error without calling the function.
+	eng.EXPECT().LinearizableRead(gomock.Any()).Return(ErrNotLeader)
+	err := n.LinearizableGet(context.TODO(), fn)
+	require.ErrorIs(t, err, ErrNotLeader)
+	require.False(t, called)
+
+	// it call the function after the linearizable read.
+	eng.EXPECT().LinearizableRead(gomock.Any()).Return(nil)
+	eng.EXPECT().ReadStateMachine(gomock.Any()).DoAndReturn(func(fn func(StateMachine) error) error {
+		return fn(nil)
+	})
+	err = n.LinearizableGet(context.TODO(), fn)
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+func TestNodeMembershipSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	membs := []membership.Member{}
+
+	for i, typ := range []MemberType{VoterMember, LearnerMember, RemovedMember, VoterMember} {
+		m := membershipmock.NewMockMember(ctrl)
+		m.EXPECT().ID().Return(uint64(i + 1)).AnyTimes()
+		m.EXPECT().Type().Return(typ).AnyTimes()
+		m.EXPECT().Raw().Return(RawMember{ID: uint64(i + 1), Type: typ}).AnyTimes()
+		membs = append(membs, m)
+	}
+
+	prev := raft.Status{}
+	prev.Config.Voters[0] = quorum.MajorityConfig{1: {}}
+	curr := raft.Status{}
+	curr.Applied = 10
+	curr.Config.Voters[0] = quorum.MajorityConfig{1: {}}
+	curr.Config.Learners = map[uint64]struct{}{2: {}}
+
+	// the conf change applied while reading the members, then it retry.
+	statuses := []raft.Status{{}, prev, curr, curr, curr}
+	eng.EXPECT().Status().DoAndReturn(func() (raft.Status, error) {
+		rs := statuses[0]
+		statuses = statuses[1:]
+		return rs, nil
+	}).Times(5)
+	eng.EXPECT().LinearizableRead(gomock.Any()).Return(nil)
+	pool.EXPECT().Members().Return(membs).Times(2)
+
+	n := new(Node)
+	n.engine = eng
+	n.pool = pool
+	n.exec = testPreCond
+
+	ms, err := n.MembershipSnapshot(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), ms.Index)
+	require.Equal(t, []uint64{1}, ms.ConfState.Voters)
+	require.Equal(t, []uint64{2}, ms.ConfState.Learners)
+
+	// it left out the members not part of the conf state.
+	require.Equal(t, []RawMember{
+		{ID: 1, Type: VoterMember},
+		{ID: 2, Type: LearnerMember},
+		{ID: 3, Type: RemovedMember},
+	}, ms.Members)
+}
+
 func TestNodeSnapshot(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	eng := raftenginemock.NewMockEngine(ctrl)
@@ -223,12 +373,13 @@ func TestNodeSnapshot(t *testing.T) {
 
 	eng.EXPECT().CreateSnapshot().Return(etcdraftpb.Snapshot{}, nil)
 	stg.EXPECT().Snapshotter().Return(shotter)
-	shotter.EXPECT().Reader(gomock.Any(), gomock.Any()).Return(nil, nil)
+	shotter.EXPECT().Reader(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 
 	n := new(Node)
 	n.engine = eng
 	n.exec = testPreCond
 	n.storage = stg
+	n.cfg = newConfig()
 	_, err := n.Snapshot()
 	require.NoError(t, err)
 }
@@ -254,29 +405,43 @@ func TestNodeStepDown(t *testing.T) {
 	m2 := membershipmock.NewMockMember(ctrl)
 	m3 := membershipmock.NewMockMember(ctrl)
 
+	now := time.Now()
 	for i, m := range []*membershipmock.MockMember{m1, m2, m3} {
 		m.EXPECT().ID().Return(uint64(i)).AnyTimes()
-		m.EXPECT().Type().Return(VoterMember)
-		m.EXPECT().IsActive().Return(true)
-		m.EXPECT().ActiveSince().Return(time.Now().Add(time.Second * time.Duration(i)))
+		m.EXPECT().Type().Return(VoterMember).AnyTimes()
+		m.EXPECT().IsActive().Return(true).AnyTimes()
+		m.EXPECT().Draining().Return(false).AnyTimes()
+		m.EXPECT().ActiveSince().Return(now.Add(time.Second * time.Duration(i))).AnyTimes()
 	}
 
-	pool.EXPECT().Members().Return([]membership.Member{m1, m2})
-	eng.EXPECT().Status().Return(raft.Status{}, nil).AnyTimes()
-	eng.EXPECT().TransferLeadership(gomock.Any(), gomock.Eq(uint64(1)))
+	rs := raft.Status{}
+	eng.EXPECT().Status().DoAndReturn(func() (raft.Status, error) { return rs, nil }).AnyTimes()
 
 	n := new(Node)
 	n.exec = testPreCond
 	n.engine = eng
 	n.pool = pool
 
+	// it transfer leadership to the longest active member on ties.
+	pool.EXPECT().Members().Return([]membership.Member{m1, m3, m2})
+	eng.EXPECT().TransferLeadership(gomock.Any(), gomock.Eq(uint64(1)))
 	err := n.Stepdown(context.TODO())
 	require.NoError(t, err)
 
+	// it transfer leadership to the best caught-up member.
+	rs.Progress = map[uint64]tracker.Progress{
+		1: {Match: 5},
+		2: {Match: 10},
+	}
+	pool.EXPECT().Members().Return([]membership.Member{m1, m2, m3})
+	eng.EXPECT().TransferLeadership(gomock.Any(), gomock.Eq(uint64(2)))
+	err = n.Stepdown(context.TODO())
+	require.NoError(t, err)
+
 	pool.EXPECT().Members().Return(nil)
 	err = n.Stepdown(context.TODO())
 	require.Error(t, err)
-	require.Contains(t, err.Error(), "longest active member")
+	require.Contains(t, err.Error(), "active voter member")
 }
 
 func TestNodeUpdateMember(t *testing.T) {
@@ -299,6 +464,45 @@ func TestNodeUpdateMember(t *testing.T) {
 	require.Equal(t, LearnerMember, raw.Type)
 }
 
+func TestNodeDrainMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pool := membershipmock.NewMockPool(ctrl)
+	m1 := membershipmock.NewMockMember(ctrl)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	m1.EXPECT().Raw().Return(RawMember{ID: 1, Type: LearnerMember}).AnyTimes()
+	pool.EXPECT().Get(gomock.Any()).Return(m1, true).AnyTimes()
+	eng.EXPECT().Status().Return(raft.Status{}, nil).AnyTimes()
+
+	n := new(Node)
+	n.engine = eng
+	n.pool = pool
+	n.exec = testPreCond
+
+	for _, drain := range []bool{true, false} {
+		eng.
+			EXPECT().
+			ProposeConfChange(gomock.Any(), gomock.Any(), gomock.Eq(etcdraftpb.ConfChangeUpdateNode)).
+			DoAndReturn(func(ctx context.Context, m *raftpb.Member, _ etcdraftpb.ConfChangeType) error {
+				require.Equal(t, drain, m.Draining)
+				require.Equal(t, LearnerMember, m.Type)
+				return nil
+			})
+
+		var err error
+		if drain {
+			err = n.DrainMember(context.TODO(), 1)
+		} else {
+			err = n.UndrainMember(context.TODO(), 1)
+		}
+		require.NoError(t, err)
+	}
+
+	// it reject leadership transfer to a draining member.
+	m1.EXPECT().Draining().Return(true)
+	err := memberDraining(1)(n)
+	require.ErrorContains(t, err, "draining")
+}
+
 func TestNodeReplicate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	eng := raftenginemock.NewMockEngine(ctrl)
@@ -312,6 +516,69 @@ func TestNodeReplicate(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestNodeAlarms(t *testing.T) {
+	alarms := []Alarm{{ID: 1, Type: NoSpaceAlarm}}
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	eng.EXPECT().Alarms().Return(alarms)
+
+	n := new(Node)
+	n.engine = eng
+	require.Equal(t, alarms, n.Alarms())
+}
+
+func TestNodeAuditLog(t *testing.T) {
+	records := []AuditRecord{{Index: 2, Action: AuditTransferLeadership}}
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	eng.EXPECT().Status().Return(raft.Status{}, nil).AnyTimes()
+	eng.EXPECT().LinearizableRead(gomock.Any()).Return(nil)
+	eng.EXPECT().AuditLog(gomock.Eq(uint64(1))).Return(records)
+
+	n := new(Node)
+	n.engine = eng
+	n.exec = testPreCond
+	got, err := n.AuditLog(context.TODO(), 1)
+	require.NoError(t, err)
+	require.Equal(t, records, got)
+
+	// it return err when the linearizable read fails.
+	eng.EXPECT().LinearizableRead(gomock.Any()).Return(ErrNoLeader)
+	_, err = n.AuditLog(context.TODO(), 1)
+	require.ErrorIs(t, err, ErrNoLeader)
+}
+
+func TestNodeRecentEvents(t *testing.T) {
+	evs := []Event{{Type: EventLeaderChange, Member: 1}}
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	eng.EXPECT().RecentEvents().Return(evs)
+
+	n := new(Node)
+	n.engine = eng
+	require.Equal(t, evs, n.RecentEvents())
+}
+
+func TestNodeDisarmAlarm(t *testing.T) {
+	alarm := &Alarm{ID: 1, Type: NoSpaceAlarm}
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	eng.EXPECT().ProposeAlarm(gomock.Any(), gomock.Eq(raftpb.AlarmChange{
+		Action: raftpb.DeactivateAlarm,
+		Alarm:  *alarm,
+	})).Return(nil)
+
+	n := new(Node)
+	n.engine = eng
+	n.exec = testPreCond
+	err := n.DisarmAlarm(context.TODO(), alarm)
+	require.NoError(t, err)
+
+	// it return err when alarm nil.
+	err = n.DisarmAlarm(context.TODO(), nil)
+	require.Error(t, err)
+}
+
 func TestNodeRemoveMember(t *testing.T) {
 	fn := func(raw *raftpb.Member, n *Node) {
 		err := n.RemoveMember(context.TODO(), 0)
@@ -365,6 +632,41 @@ func TestNodeMembers(t *testing.T) {
 	require.Equal(t, 2, len(membs))
 }
 
+func TestNodeMemberProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	mem.EXPECT().ID().Return(uint64(2)).AnyTimes()
+	pool.EXPECT().Get(uint64(2)).Return(mem, true).AnyTimes()
+	pool.EXPECT().Get(uint64(3)).Return(nil, false)
+	n := new(Node)
+	n.pool = pool
+	n.engine = eng
+
+	// it return false when member not found.
+	_, ok := n.Member(3)
+	require.False(t, ok)
+
+	// it return false when the current node is not the leader.
+	eng.EXPECT().Status().Return(raft.Status{}, nil)
+	m, ok := n.Member(2)
+	require.True(t, ok)
+	_, ok = m.Progress()
+	require.False(t, ok)
+
+	// it return the member progress.
+	rs := raft.Status{
+		Progress: map[uint64]tracker.Progress{
+			2: {Match: 5, Next: 6, State: tracker.StateReplicate, RecentActive: true},
+		},
+	}
+	eng.EXPECT().Status().Return(rs, nil)
+	pr, ok := m.Progress()
+	require.True(t, ok)
+	require.Equal(t, MemberProgress{State: "StateReplicate", Match: 5, Next: 6, RecentActive: true}, pr)
+}
+
 func TestNNodeLeader(t *testing.T) {
 	st := raft.Status{
 		BasicStatus: raft.BasicStatus{
@@ -379,6 +681,132 @@ func TestNNodeLeader(t *testing.T) {
 	require.Equal(t, st.Lead, n.Leader())
 }
 
+func TestNodeMemberInfo(t *testing.T) {
+	now := time.Now()
+	st := raft.Status{
+		BasicStatus: raft.BasicStatus{
+			ID:        1,
+			SoftState: raft.SoftState{Lead: 2},
+		},
+	}
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	eng.EXPECT().Status().Return(st, nil).AnyTimes()
+	pool.EXPECT().Get(uint64(1)).Return(nil, false)
+	pool.EXPECT().Get(uint64(2)).Return(mem, true)
+	mem.EXPECT().ID().Return(uint64(2)).AnyTimes()
+	mem.EXPECT().Address().Return(":8080")
+	mem.EXPECT().Type().Return(VoterMember)
+	mem.EXPECT().Labels().Return(map[string]string{"zone": "a"})
+	mem.EXPECT().IsActive().Return(true)
+	mem.EXPECT().ActiveSince().Return(now)
+	mem.EXPECT().LastContact().Return(now)
+	mem.EXPECT().Draining().Return(false)
+	mem.EXPECT().Version().Return(ProtocolVersion)
+	n := new(Node)
+	n.pool = pool
+	n.engine = eng
+
+	// it return false when the local member not found.
+	_, ok := n.LocalMember()
+	require.False(t, ok)
+
+	// it return the leader member info.
+	m, ok := n.LeaderMember()
+	require.True(t, ok)
+	require.Equal(t, MemberInfo{
+		ID:          2,
+		Address:     ":8080",
+		Type:        VoterMember,
+		Labels:      map[string]string{"zone": "a"},
+		Active:      true,
+		ActiveSince: now,
+		LastContact: now,
+		Version:     ProtocolVersion,
+		Leader:      true,
+	}, m.Info())
+}
+
+func TestNodeNotLeaderError(t *testing.T) {
+	st := raft.Status{
+		BasicStatus: raft.BasicStatus{
+			ID:        1,
+			SoftState: raft.SoftState{Lead: 2},
+		},
+	}
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	pool := membershipmock.NewMockPool(ctrl)
+	mem := membershipmock.NewMockMember(ctrl)
+	eng.EXPECT().Status().Return(st, nil).AnyTimes()
+	pool.EXPECT().Get(uint64(2)).Return(mem, true)
+	mem.EXPECT().Address().Return(":8080")
+	n := new(Node)
+	n.pool = pool
+	n.engine = eng
+
+	err := notLeader()(n)
+	require.ErrorIs(t, err, ErrNotLeader)
+
+	nle := new(NotLeaderError)
+	require.ErrorAs(t, err, &nle)
+	require.Equal(t, uint64(2), nle.Leader)
+	require.Equal(t, ":8080", nle.Address)
+}
+
+func TestNodeWaitForLeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	n := new(Node)
+	n.engine = eng
+	n.cfg = newConfig(WithTickInterval(time.Millisecond))
+
+	// it return when the leader elected.
+	eng.EXPECT().Status().Return(raft.Status{}, ErrNodeStopped)
+	eng.EXPECT().Status().Return(raft.Status{BasicStatus: raft.BasicStatus{SoftState: raft.SoftState{Lead: 1}}}, nil)
+	err := n.WaitForLeader(context.Background())
+	require.NoError(t, err)
+
+	// it return the context error.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	eng.EXPECT().Status().Return(raft.Status{}, nil).AnyTimes()
+	err = n.WaitForLeader(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNodeHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eng := raftenginemock.NewMockEngine(ctrl)
+	n := new(Node)
+	n.engine = eng
+	n.cfg = newConfig(WithHealthStallTimeout(time.Millisecond))
+	status := func(lead, applied, commit uint64) raft.Status {
+		st := raft.Status{}
+		st.Applied = applied
+		st.Lead = lead
+		st.Commit = commit
+		return st
+	}
+
+	// it return error when there is no leader.
+	eng.EXPECT().Status().Return(status(0, 1, 1), nil)
+	require.ErrorIs(t, n.Health(), ErrNoLeader)
+
+	// it return nil when the applied index caught up or advancing.
+	eng.EXPECT().Status().Return(status(1, 1, 1), nil)
+	require.NoError(t, n.Health())
+	eng.EXPECT().Status().Return(status(1, 2, 5), nil)
+	require.NoError(t, n.Health())
+
+	// it return error when the applied index stalled.
+	time.Sleep(time.Millisecond * 2)
+	eng.EXPECT().Status().Return(status(1, 2, 5), nil)
+	require.ErrorIs(t, n.Health(), ErrApplyStalled)
+}
+
 func TestNodeStart(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	eng := raftenginemock.NewMockEngine(ctrl)
@@ -387,6 +815,11 @@ func TestNodeStart(t *testing.T) {
 	n.engine = eng
 	err := n.Start()
 	require.NoError(t, err)
+
+	// it start the node with the advertised address.
+	eng.EXPECT().Start(gomock.Eq("10.0.0.1:8080")).Return(nil)
+	err = n.Start(WithAddress(":8080"), WithAdvertiseAddress("10.0.0.1:8080"))
+	require.NoError(t, err)
 }
 
 func TestNodePromoteMember(t *testing.T) {
@@ -704,6 +1137,54 @@ func TestPreConditions(t *testing.T) {
 				n.pool = pool
 			},
 		},
+		{
+			fn:       quorumPreserved(1, LearnerMember),
+			contains: "last voter",
+			expect: func(n *Node) {
+				ctrl := gomock.NewController(t)
+				pool := membershipmock.NewMockPool(ctrl)
+				m1 := membershipmock.NewMockMember(ctrl)
+				m1.EXPECT().ID().Return(uint64(1)).AnyTimes()
+				m1.EXPECT().Type().Return(VoterMember)
+				pool.EXPECT().Members().Return([]membership.Member{m1})
+				n.pool = pool
+			},
+		},
+		{
+			fn:       quorumPreserved(2, VoterMember),
+			contains: "loses the quorum",
+			expect: func(n *Node) {
+				ctrl := gomock.NewController(t)
+				pool := membershipmock.NewMockPool(ctrl)
+				m1 := membershipmock.NewMockMember(ctrl)
+				m2 := membershipmock.NewMockMember(ctrl)
+				m1.EXPECT().ID().Return(uint64(1)).AnyTimes()
+				m2.EXPECT().ID().Return(uint64(2)).AnyTimes()
+				m1.EXPECT().Type().Return(VoterMember)
+				m2.EXPECT().Type().Return(LearnerMember)
+				m1.EXPECT().IsActive().Return(true)
+				m2.EXPECT().IsActive().Return(false)
+				pool.EXPECT().Members().Return([]membership.Member{m1, m2})
+				n.pool = pool
+			},
+		},
+		{
+			fn:       quorumPreserved(2, LearnerMember),
+			contains: nilErr.Error(),
+			expect: func(n *Node) {
+				ctrl := gomock.NewController(t)
+				pool := membershipmock.NewMockPool(ctrl)
+				m1 := membershipmock.NewMockMember(ctrl)
+				m2 := membershipmock.NewMockMember(ctrl)
+				m1.EXPECT().ID().Return(uint64(1)).AnyTimes()
+				m2.EXPECT().ID().Return(uint64(2)).AnyTimes()
+				m1.EXPECT().Type().Return(VoterMember)
+				m2.EXPECT().Type().Return(VoterMember)
+				m1.EXPECT().IsActive().Return(true)
+				pool.EXPECT().Members().Return([]membership.Member{m1, m2})
+				n.pool = pool
+			},
+		},
 		{
 			fn:       available(),
 			contains: nilErr.Error(),
@@ -789,3 +1270,134 @@ func testConfChange(t *testing.T, fn func(*RawMember, *Node)) {
 func testPreCond(fns ...func(c *Node) error) error {
 	return nil
 }
+
+func TestValidateSnapshot(t *testing.T) {
+	const data = "some app data"
+	dir := t.TempDir()
+	cs := etcdraftpb.ConfState{Voters: []uint64{1}, Learners: []uint64{2}}
+	membs := []raftpb.Member{{ID: 1}, {ID: 2, Type: raftpb.LearnerMember}}
+
+	valid := writeTestSnapshot(t, dir, "valid.snap", data, cs, membs, 0)
+	delta := writeTestSnapshot(t, dir, "delta.snap", data, cs, membs, 1)
+	invalid := writeTestSnapshot(t, dir, "invalid.snap", data, cs, membs[:1], 0)
+
+	fsm := new(restoreFSM)
+	newFSM := func() StateMachine { return fsm }
+
+	// it validate the snapshot without restoring it.
+	require.NoError(t, ValidateSnapshot(valid, nil))
+
+	// it restore the snapshot into the scratch state machine.
+	require.NoError(t, ValidateSnapshot(valid, newFSM))
+	require.Equal(t, data, fsm.data)
+
+	// it return error when the state machine fails to restore the snapshot.
+	fsm.err = fmt.Errorf("restore failed")
+	require.ErrorIs(t, ValidateSnapshot(valid, newFSM), fsm.err)
+
+	// it return error when the snapshot members mismatch the conf state.
+	require.ErrorContains(t, ValidateSnapshot(invalid, nil), "member 2 not found")
+
+	// it return error when restoring a delta snapshot.
+	require.NoError(t, ValidateSnapshot(delta, nil))
+	require.ErrorContains(t, ValidateSnapshot(delta, newFSM), "delta snapshot")
+
+	// it return error when the snapshot corrupted.
+	err := ValidateSnapshot("./internal/storage/disk/testdata/crc.snap", nil)
+	require.ErrorIs(t, err, ErrSnapshotCorrupted)
+}
+
+func TestValidateSnapshotMembers(t *testing.T) {
+	table := []struct {
+		name  string
+		cs    etcdraftpb.ConfState
+		membs []raftpb.Member
+		err   string
+	}{
+		{
+			name:  "it return nil on consistent members",
+			cs:    etcdraftpb.ConfState{Voters: []uint64{1, 2}, LearnersNext: []uint64{3}},
+			membs: []raftpb.Member{{ID: 1}, {ID: 2}, {ID: 3}},
+		},
+		{
+			name:  "it return error on zero member id",
+			membs: []raftpb.Member{{ID: 0}},
+			err:   "zero id",
+		},
+		{
+			name:  "it return error on duplicate members",
+			membs: []raftpb.Member{{ID: 1}, {ID: 1}},
+			err:   "duplicate member",
+		},
+		{
+			name:  "it return error on removed conf state member",
+			cs:    etcdraftpb.ConfState{VotersOutgoing: []uint64{1}},
+			membs: []raftpb.Member{{ID: 1, Type: raftpb.RemovedMember}},
+			err:   "member 1 not found",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			sf := new(storage.Snapshot)
+			sf.Raw.Metadata.ConfState = tt.cs
+			sf.Members = tt.membs
+
+			err := validateSnapshotMembers(sf)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+// writeTestSnapshot writes a snapshot file in the disk snapshot format and returns its path.
+func writeTestSnapshot(
+	t *testing.T,
+	dir, name, data string,
+	cs etcdraftpb.ConfState,
+	membs []raftpb.Member,
+	base uint64,
+) string {
+	st := raftpb.SnapshotState{
+		Members:   membs,
+		BaseTerm:  base,
+		BaseIndex: base,
+	}
+	st.Raw.Metadata = etcdraftpb.SnapshotMetadata{ConfState: cs, Term: 2, Index: 2}
+	h := crc64.New(crc64.MakeTable(crc64.ECMA))
+	_, _ = h.Write([]byte(data))
+	st.CRC = h.Sum(nil)
+
+	raw, err := st.Marshal()
+	require.NoError(t, err)
+
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(raw)))
+
+	buf := append([]byte(data), raw...)
+	buf = append(buf, size...)
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, buf, 0600))
+	return path
+}
+
+type restoreFSM struct {
+	StateMachine
+	data string
+	err  error
+}
+
+func (fsm *restoreFSM) Restore(r io.ReadCloser) error {
+	if fsm.err != nil {
+		return fsm.err
+	}
+
+	buf, err := io.ReadAll(r)
+	fsm.data = string(buf)
+	return err
+}
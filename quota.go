@@ -0,0 +1,188 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	rafterrors "github.com/shaj13/raft/errors"
+	"github.com/shaj13/raft/internal/raftpb"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// ErrProposalTooLarge is returned by the Node Replicate method,
+// when the proposal data exceeds the max proposal size, See WithMaxProposalSize.
+var ErrProposalTooLarge = rafterrors.ErrProposalTooLarge
+
+// maxQuotaClients is the number of the tracked clients buckets,
+// beyond which the idle buckets dropped.
+const maxQuotaClients = 1024
+
+func newProposalQuota(c *config) *proposalQuota {
+	if c.maxPropSize <= 0 && c.propRate <= 0 && c.clientPropRate <= 0 {
+		return nil
+	}
+
+	q := &proposalQuota{
+		maxSize: c.maxPropSize,
+		rate:    c.clientPropRate,
+		burst:   c.clientPropBurst,
+		clients: make(map[string]*bucket),
+	}
+
+	if c.propRate > 0 {
+		q.global = newBucket(c.propRate, c.propBurst)
+	}
+
+	return q
+}
+
+// proposalQuota enforces the proposals size and rate quotas,
+// so a misbehaving writer can't overwhelm the cluster.
+type proposalQuota struct {
+	maxSize int
+	global  *bucket
+	rate    float64
+	burst   int
+	mu      sync.Mutex
+	clients map[string]*bucket
+}
+
+// admit returns error if the proposal of the given size from the given client exceeds the quotas,
+// the client is empty for the local proposals.
+func (q *proposalQuota) admit(client string, size int) error {
+	if q == nil {
+		return nil
+	}
+
+	if q.maxSize > 0 && size > q.maxSize {
+		return fmt.Errorf("%w: %d bytes exceeds %d bytes", ErrProposalTooLarge, size, q.maxSize)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+
+	if q.global != nil && !q.global.take(now) {
+		return fmt.Errorf("%w: proposals", ErrRateLimited)
+	}
+
+	if q.rate <= 0 || len(client) == 0 {
+		return nil
+	}
+
+	// drop the idle buckets to not grow with the number of clients.
+	if len(q.clients) >= maxQuotaClients {
+		for k, b := range q.clients {
+			if b.full(now) {
+				delete(q.clients, k)
+			}
+		}
+	}
+
+	b, ok := q.clients[client]
+	if !ok {
+		b = newBucket(q.rate, q.burst)
+		q.clients[client] = b
+	}
+
+	if !b.take(now) {
+		return fmt.Errorf("%w: proposals of client %q", ErrRateLimited, client)
+	}
+
+	return nil
+}
+
+// admitForwarded returns error if the proposals forwarded by a member
+// within the given message exceeds the quotas.
+func (q *proposalQuota) admitForwarded(ctx context.Context, m etcdraftpb.Message) error {
+	if q == nil || m.Type != etcdraftpb.MsgProp {
+		return nil
+	}
+
+	client := proposalClient(ctx, m)
+	for _, ent := range m.Entries {
+		if ent.Type != etcdraftpb.EntryNormal || len(ent.Data) == 0 {
+			continue
+		}
+
+		r := new(raftpb.Replicate)
+		if err := r.Unmarshal(ent.Data); err != nil {
+			return err
+		}
+
+		if err := q.admit(client, len(r.Data)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// proposalClient returns the identity of the client forwarding the proposals,
+// the mutual TLS identity if any, otherwise the forwarding member id.
+func proposalClient(ctx context.Context, m etcdraftpb.Message) string {
+	creds := CredentialsFromContext(ctx)
+	if len(creds.PeerCertificates) > 0 {
+		leaf := creds.PeerCertificates[0]
+		if len(leaf.URIs) > 0 {
+			return leaf.URIs[0].String()
+		}
+		if len(leaf.Subject.CommonName) > 0 {
+			return leaf.Subject.CommonName
+		}
+	}
+
+	return strconv.FormatUint(m.From, 16)
+}
+
+// bucket is a token bucket, that refills at rate tokens per second up to burst tokens.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// take reports whether a token taken from the bucket.
+func (b *bucket) take(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// full reports whether the bucket refilled up to its burst, i.e. idle.
+func (b *bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+}
@@ -0,0 +1,64 @@
+package raft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/stretchr/testify/require"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestProposalQuota(t *testing.T) {
+	// it admit all proposals when no quota.
+	q := newProposalQuota(newConfig())
+	require.Nil(t, q)
+	require.NoError(t, q.admit("", 1<<20))
+
+	q = newProposalQuota(newConfig(
+		WithMaxProposalSize(10),
+		WithProposalRateLimit(1, 3),
+		WithClientProposalRateLimit(1, 1),
+	))
+
+	// it reject proposals larger than the max size.
+	err := q.admit("", 11)
+	require.ErrorIs(t, err, ErrProposalTooLarge)
+
+	// it reject client proposals beyond the client rate limit.
+	require.NoError(t, q.admit("a", 10))
+	err = q.admit("a", 10)
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	// it reject proposals beyond the global rate limit.
+	require.NoError(t, q.admit("", 10))
+	err = q.admit("b", 10)
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	// it refill the buckets.
+	q.global.last = time.Now().Add(-time.Hour)
+	q.clients["a"].last = time.Now().Add(-time.Hour)
+	require.NoError(t, q.admit("a", 10))
+}
+
+func TestProposalQuotaForwarded(t *testing.T) {
+	q := newProposalQuota(newConfig(WithMaxProposalSize(1)))
+	r := raftpb.Replicate{Data: []byte("data")}
+	data, err := r.Marshal()
+	require.NoError(t, err)
+
+	m := etcdraftpb.Message{
+		Type:    etcdraftpb.MsgProp,
+		From:    2,
+		Entries: []etcdraftpb.Entry{{Data: data}},
+	}
+
+	// it ignore the non proposals messages.
+	require.NoError(t, q.admitForwarded(context.TODO(), etcdraftpb.Message{Entries: m.Entries}))
+
+	// it reject the forwarded proposals larger than the max size.
+	err = q.admitForwarded(context.TODO(), m)
+	require.ErrorIs(t, err, ErrProposalTooLarge)
+	require.Equal(t, "2", proposalClient(context.TODO(), m))
+}
@@ -2,10 +2,13 @@ package raft
 
 import (
 	"context"
+	"crypto/tls"
 	"os"
+	"path/filepath"
 	"time"
 
 	"go.etcd.io/etcd/raft/v3"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 
 	"github.com/shaj13/raft/internal/membership"
 	"github.com/shaj13/raft/internal/raftengine"
@@ -34,11 +37,201 @@ const (
 // MemberType used to distinguish members (voter, learner, etc).
 type MemberType = raftpb.MemberType
 
+const (
+	// NoCompression disables the raft messages compression.
+	NoCompression Compression = raftpb.NoCompression
+	// SnappyCompression compress the raft messages using snappy.
+	SnappyCompression Compression = raftpb.SnappyCompression
+	// GzipCompression compress the raft messages using gzip.
+	GzipCompression Compression = raftpb.GzipCompression
+	// ZstdCompression compress the raft messages using zstd.
+	ZstdCompression Compression = raftpb.ZstdCompression
+)
+
+// Compression represents the compression algorithm of the raft messages sent over the transport.
+type Compression = raftpb.Compression
+
+// ProtocolVersion is the protocol version supported by the current node,
+// advertised to the cluster within the member record, See Node.ClusterVersion.
+const ProtocolVersion = raftengine.ProtocolVersion
+
+const (
+	// FeatureAuditLog replicates the administrative actions records, See Node.AuditLog.
+	FeatureAuditLog Feature = raftengine.FeatureAuditLog
+	// FeatureCorruptCheck replicates the state machine hash checks, See WithCorruptCheck.
+	FeatureCorruptCheck Feature = raftengine.FeatureCorruptCheck
+)
+
+// Feature represents a protocol feature replicated to the cluster members,
+// it activated only once all the cluster members support it, See Node.Supports.
+type Feature = raftengine.Feature
+
+// RetryPolicy define the members dial timeout and the messages retry policy, See WithRetryPolicy.
+type RetryPolicy = membership.RetryPolicy
+
+// QueuePolicy define the bounds of the members outbound messages queue, See WithQueuePolicy.
+type QueuePolicy = membership.QueuePolicy
+
+// QueueStats represents the member outbound messages queue statistics.
+type QueueStats = membership.QueueStats
+
 // RawMember represents a raft cluster member and holds its metadata.
 type RawMember = raftpb.Member
 
+// Well-known member labels keys, See WithLabels.
+const (
+	// LabelZone specifies the availability zone of the member.
+	LabelZone = "zone"
+	// LabelRack specifies the rack of the member.
+	LabelRack = "rack"
+	// LabelVersion specifies the build version of the member.
+	LabelVersion = "version"
+)
+
 type StateType = raft.StateType
 
+// Alarm represents a cluster alarm raised by a member.
+type Alarm = raftpb.Alarm
+
+// AlarmType used to distinguish alarms (nospace, etc).
+type AlarmType = raftpb.AlarmType
+
+// NoSpaceAlarm is raised when a member runs out of disk space, or the
+// available disk space falls below the low watermark.
+// While active, the cluster rejects new replicate proposals,
+// but still serves reads and compacts the log.
+const NoSpaceAlarm AlarmType = raftpb.NoSpaceAlarm
+
+// CorruptAlarm is raised by the leader for a member whose state machine diverged
+// from the leader state machine at the same applied index, See WithCorruptionCheck.
+const CorruptAlarm AlarmType = raftpb.CorruptAlarm
+
+// Message is a raft message exchanged between the members, See WithMessageTap.
+type Message = etcdraftpb.Message
+
+// MessageType used to distinguish the raft messages (MsgApp, MsgHeartbeat, etc).
+type MessageType = etcdraftpb.MessageType
+
+// Direction is the direction of a raft message, relative to the local member, See WithMessageTap.
+type Direction = raftengine.Direction
+
+const (
+	// Inbound is the direction of the messages received from the members.
+	Inbound = raftengine.Inbound
+	// Outbound is the direction of the messages sent to the members.
+	Outbound = raftengine.Outbound
+)
+
+// Clock provides the tickers driving the node, e.g. the raft logical clock, See WithClock.
+type Clock = raftengine.Clock
+
+// Ticker delivers ticks at intervals, See Clock.
+type Ticker = raftengine.Ticker
+
+// FaultInjector define the fault points injected into the node for chaos testing, See WithFaultInjector.
+type FaultInjector = raftengine.FaultInjector
+
+// FaultAction is the fault injected into an outbound message, See FaultInjector.
+type FaultAction = raftengine.FaultAction
+
+// Possible values for FaultAction.
+const (
+	FaultNone      = raftengine.FaultNone
+	FaultDrop      = raftengine.FaultDrop
+	FaultDuplicate = raftengine.FaultDuplicate
+	FaultDelay     = raftengine.FaultDelay
+)
+
+// ApplyStage is the stage of applying an entry, at which a crash injected, See FaultInjector.
+type ApplyStage = raftengine.ApplyStage
+
+// Possible values for ApplyStage.
+const (
+	BeforeApply = raftengine.BeforeApply
+	AfterApply  = raftengine.AfterApply
+)
+
+// AuditRecord represents an administrative action recorded into the replicated audit log,
+// the member performed it, when, and what, along with the metadata attached to the action context,
+// See Node.AuditLog.
+type AuditRecord = raftpb.AuditRecord
+
+// AuditAction used to distinguish the audited administrative actions.
+type AuditAction = raftpb.AuditAction
+
+const (
+	// AuditConfChange is recorded when a member added, removed, promoted, or updated.
+	AuditConfChange AuditAction = raftpb.AuditConfChange
+	// AuditTransferLeadership is recorded when the leadership transferred.
+	AuditTransferLeadership AuditAction = raftpb.AuditTransferLeadership
+	// AuditForceSnapshot is recorded when a snapshot manually forced, See Node.Snapshot.
+	AuditForceSnapshot AuditAction = raftpb.AuditForceSnapshot
+	// AuditRestore is recorded when the cluster restored from a snapshot file, See WithRestore.
+	AuditRestore AuditAction = raftpb.AuditRestore
+)
+
+// StorageMetrics define a set of functions to report storage performance,
+// such as sync latency, bytes written, WAL segments count, and compaction duration.
+type StorageMetrics = storage.Metrics
+
+// TransportMetrics define a set of functions to report the transport traffic per member,
+// such as messages and bytes sent and received, and rpc latency.
+type TransportMetrics = transport.Metrics
+
+// SnapshotStore define a set of functions to store snapshot files in an
+// object store bucket, such as S3, GCS, or MinIO, See WithSnapshotStore.
+type SnapshotStore = storage.ObjectStore
+
+// WALVerificationError is returned by the node start when
+// the WAL fails the boot verification, See WithVerifyWALOnBoot.
+type WALVerificationError = storage.VerificationError
+
+// SnapshotEvent describes the creation of a local snapshot,
+// or the transfer of a snapshot to a member, See WithSnapshotEventCh.
+type SnapshotEvent = raftengine.SnapshotEvent
+
+// SnapshotEventType used to distinguish snapshot events (created, sent).
+type SnapshotEventType = raftengine.SnapshotEventType
+
+// Possible values for SnapshotEventType.
+const (
+	SnapshotCreated = raftengine.SnapshotCreated
+	SnapshotSent    = raftengine.SnapshotSent
+)
+
+// MemberEvent describes an action taken automatically on a cluster member,
+// See WithMemberEventCh.
+type MemberEvent = raftengine.MemberEvent
+
+// MemberEventType used to distinguish member events.
+type MemberEventType = raftengine.MemberEventType
+
+// Possible values for MemberEventType.
+const (
+	MemberDeadRemoved = raftengine.MemberDeadRemoved
+	MemberUnreachable = raftengine.MemberUnreachable
+	MemberReachable   = raftengine.MemberReachable
+)
+
+// Event describes a significant engine event, See Node.RecentEvents.
+type Event = raftengine.Event
+
+// EventType used to distinguish engine events.
+type EventType = raftengine.EventType
+
+// Possible values for EventType.
+const (
+	EventLeaderChange      = raftengine.EventLeaderChange
+	EventSnapshotInstalled = raftengine.EventSnapshotInstalled
+	EventConfChange        = raftengine.EventConfChange
+	EventMessageDropped    = raftengine.EventMessageDropped
+	EventCorruption        = raftengine.EventCorruption
+)
+
+// LeaderInfo describes a change of the raft cluster leader,
+// or the current node raft state, See Node.LeaderChanges.
+type LeaderInfo = raftengine.LeaderInfo
+
 // Possible values for StateType.
 const (
 	StateFollower     = raft.StateFollower
@@ -53,14 +246,218 @@ type Member interface {
 	Address() string
 	ActiveSince() time.Time
 	IsActive() bool
+	// LastContact returns the time of the last message successfully sent to the member.
+	LastContact() time.Time
+	// Failures returns the number of consecutive messages failed to be sent to the member.
+	Failures() int
+	// QueueStats returns the member outbound messages queue statistics.
+	QueueStats() QueueStats
+	// Progress returns the member replication progress,
+	// it returns false if the current node is not the leader.
+	Progress() (MemberProgress, bool)
 	Type() MemberType
+	Labels() map[string]string
+	// Draining reports whether the member is draining for maintenance, See Node.DrainMember.
+	Draining() bool
 	Raw() RawMember
+	// Info returns a point-in-time copy of the member details.
+	Info() MemberInfo
+}
+
+// MemberInfo represents a point-in-time copy of the member details, See Member.Info.
+type MemberInfo struct {
+	// ID is the member id.
+	ID uint64
+	// Address is the member transport address.
+	Address string
+	// Type is the member type, e.g. VoterMember or LearnerMember.
+	Type MemberType
+	// Labels are the member labels.
+	Labels map[string]string
+	// Active reports whether the member is reachable from the current node.
+	Active bool
+	// ActiveSince is the time since the member has been active, zero when inactive.
+	ActiveSince time.Time
+	// LastContact is the time of the last message successfully sent to the member.
+	LastContact time.Time
+	// Draining reports whether the member is draining for maintenance.
+	Draining bool
+	// Version is the member protocol version, zero for the members predating the protocol versioning.
+	Version uint32
+	// Leader reports whether the member is the raft cluster leader.
+	Leader bool
+}
+
+// MemberProgress represents the member replication progress as tracked by the leader.
+type MemberProgress struct {
+	// State is the replication state, one of StateProbe, StateReplicate, or StateSnapshot.
+	State string
+	// Match is the highest known log index replicated to the member.
+	Match uint64
+	// Next is the log index of the next entry to be sent to the member.
+	Next uint64
+	// RecentActive reports whether the member has been recently active.
+	RecentActive bool
+}
+
+// Status represents the current raft node status, See Node.Status.
+type Status struct {
+	// ID is the current node member id.
+	ID uint64
+	// Role is the current node raft state, one of StateFollower, StateCandidate, StateLeader,
+	// or StatePreCandidate.
+	Role StateType
+	// Term is the current raft term.
+	Term uint64
+	// Vote is the member id the current node voted for in the current term.
+	Vote uint64
+	// Leader is the raft cluster leader member id, None when there is no elected leader.
+	Leader uint64
+	// LeadTransferee is the member id the leadership being transferred to, if there any.
+	LeadTransferee uint64
+	// Committed is the highest log index known to be committed.
+	Committed uint64
+	// Applied is the highest log index applied to the state machine.
+	Applied uint64
+	// Progress is the replication progress of the members, keyed by the member id,
+	// it only set when the current node is the leader.
+	Progress map[uint64]MemberProgress
+}
+
+// IDGenerator generates the unique ids of the proposals, the linearizable reads,
+// and the conf changes, it must be safe for concurrent use, See WithIDGenerator.
+type IDGenerator = raftengine.IDGenerator
+
+// ConfState represents the raft configuration, the voters and learners ids.
+type ConfState = etcdraftpb.ConfState
+
+// MembershipSnapshot represents a consistent view of the cluster membership,
+// See Node.MembershipSnapshot.
+type MembershipSnapshot struct {
+	// Index is the applied index at which the view taken.
+	Index uint64
+	// ConfState is the raft configuration at Index.
+	ConfState ConfState
+	// Members are the members of the ConfState, and the removed members.
+	Members []RawMember
 }
 
 // StateMachine define an interface that must be implemented by
 // application to make use of the raft replicated log.
 type StateMachine = raftengine.StateMachine
 
+// StateMachineV2 define a context-aware revision of the StateMachine interface,
+// the contexts cancelled on shutdown so the long-running operations terminate cleanly,
+// See FromStateMachineV2.
+//
+// Note: the optional IncrementalStateMachine and TwoPhaseStateMachine interfaces,
+// are not supported by a StateMachineV2.
+type StateMachineV2 = raftengine.StateMachineV2
+
+// FromStateMachineV2 returns a StateMachine that delegates to the given context-aware state machine,
+// to be passed to NewNode. The node calls it with contexts cancelled on shutdown.
+//
+//	node := raft.NewNode(raft.FromStateMachineV2(fsm), transport.GRPC)
+func FromStateMachineV2(sm StateMachineV2) StateMachine {
+	return raftengine.FromStateMachineV2(sm)
+}
+
+// AsStateMachineV2 returns the context-aware state machine wrapped by FromStateMachineV2,
+// e.g. within Node.LinearizableGet.
+func AsStateMachineV2(sm StateMachine) (StateMachineV2, bool) {
+	return raftengine.AsStateMachineV2(sm)
+}
+
+// MetadataStateMachine is an optional interface implemented by a StateMachine,
+// to receive the application metadata attached to the entries proposals, See ContextWithMetadata.
+//
+// Note: a StateMachineV2 receives the metadata from the Apply context, See MetadataFromContext.
+type MetadataStateMachine = raftengine.MetadataStateMachine
+
+// ApplyEvent describes a committed raft log entry applied to the state machine, See WithApplyHook.
+type ApplyEvent = raftengine.ApplyEvent
+
+// WatchEvent is a replicated data entry applied to the state machine, streamed by Node.Watch.
+type WatchEvent = raftengine.WatchEvent
+
+// SlowOpType is the type of a slow operation, See WithSlowOpHook.
+type SlowOpType = raftengine.SlowOpType
+
+const (
+	// SlowApply is emitted when applying an entry to the state machine exceeds the threshold.
+	SlowApply = raftengine.SlowApply
+	// SlowSync is emitted when syncing the entries to the storage exceeds the threshold.
+	SlowSync = raftengine.SlowSync
+)
+
+// SlowOpEvent describes a state machine apply or a storage sync that exceeded its threshold,
+// See WithSlowApplyThreshold and WithSlowSyncThreshold.
+type SlowOpEvent = raftengine.SlowOpEvent
+
+// SlowOps holds the number of the slow operations since the node started, See Node.SlowOps.
+type SlowOps = raftengine.SlowOps
+
+// MaxMetadataSize is the maximum size in bytes of the metadata keys and values,
+// attached to a proposal.
+const MaxMetadataSize = raftengine.MaxMetadataSize
+
+// ContextWithMetadata returns a copy of the given context that carries the given metadata,
+// e.g. request id, trace id, or principal. The metadata attached to the proposal
+// when the context passed to Node.Replicate, stored alongside the replicated data,
+// and handed to the state machine on apply and to the apply hook.
+//
+//	ctx = raft.ContextWithMetadata(ctx, map[string]string{"request-id": id})
+//	err := node.Replicate(ctx, data)
+func ContextWithMetadata(ctx context.Context, md map[string]string) context.Context {
+	return raftengine.ContextWithMetadata(ctx, md)
+}
+
+// MetadataFromContext returns the metadata carried by the given context, if any.
+// A StateMachineV2 receives the entry proposal metadata from the Apply context.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	return raftengine.MetadataFromContext(ctx)
+}
+
+// Priority is the scheduling lane of a proposal, See ContextWithPriority.
+type Priority = raftengine.Priority
+
+const (
+	// PriorityNormal is the lane of the bulk data proposals.
+	PriorityNormal = raftengine.PriorityNormal
+	// PriorityHigh is the lane of the small latency-sensitive proposals,
+	// such as the control operations and the metadata updates.
+	PriorityHigh = raftengine.PriorityHigh
+)
+
+// ContextWithPriority returns a copy of the given context that carries the given proposal priority.
+// The proposals of the high priority lane scheduled ahead of the normal ones,
+// so bulk imports can't starve the latency-sensitive updates, See WithProposalWeight.
+//
+//	ctx = raft.ContextWithPriority(ctx, raft.PriorityHigh)
+//	err := node.Replicate(ctx, data)
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return raftengine.ContextWithPriority(ctx, p)
+}
+
+// IncrementalStateMachine is an optional interface implemented by a StateMachine,
+// to write snapshots that only hold the changes applied since the previous snapshot,
+// chained onto a full snapshot, See WithMaxSnapshotDeltas.
+type IncrementalStateMachine = raftengine.IncrementalStateMachine
+
+// TwoPhaseStateMachine is an optional interface implemented by a StateMachine,
+// to decouple capturing a consistent view of the state, from serializing it to a snapshot file.
+//
+// Note: delta snapshots of an IncrementalStateMachine still use IncrementalSnapshot.
+type TwoPhaseStateMachine = raftengine.TwoPhaseStateMachine
+
+// HashStateMachine is an optional interface implemented by a StateMachine,
+// to detect the state machines divergence across the members, See WithCorruptionCheck.
+type HashStateMachine = raftengine.HashStateMachine
+
+// SnapshotSource is a consistent view of the state machine state,
+// captured by a TwoPhaseStateMachine to be serialized into a snapshot file.
+type SnapshotSource = raftengine.SnapshotSource
+
 // Option configures raft node using the functional options paradigm popularized by Rob Pike and Dave Cheney.
 // If you're unfamiliar with this style,
 // see https://commandcenter.blogspot.com/2014/01/self-referential-functions-and-design.html and
@@ -151,6 +548,51 @@ func WithStateDIR(dir string) Option {
 	})
 }
 
+// WithInMemoryStorage stores the raft state (WAL logs and Snapshots) in memory instead of the state dir,
+// for local development and tests, See NewSingleNode.
+//
+// Note: the state lost once the process exits, and the snapshots can't be sent to other members,
+// therefore WithInMemoryStorage suits single member clusters only.
+//
+// Default Value: false.
+func WithInMemoryStorage() Option {
+	return optionFunc(func(c *config) {
+		c.inMemory = true
+	})
+}
+
+// WithWALDIR is the directory to store the WAL logs,
+// One use case for this feature would be in placing the WAL on a dedicated fast device.
+//
+// Default Value: <state dir>/wal.
+func WithWALDIR(dir string) Option {
+	return optionFunc(func(c *config) {
+		c.waldir = dir
+	})
+}
+
+// WithSnapshotDIR is the directory to store the snapshots files,
+// One use case for this feature would be in placing the snapshots on a cheaper device.
+//
+// Default Value: <state dir>/snap.
+func WithSnapshotDIR(dir string) Option {
+	return optionFunc(func(c *config) {
+		c.snapdir = dir
+	})
+}
+
+// WithDiskLowWatermark is the minimum available bytes on the WAL and snapshots
+// filesystems, below which the member raises a NOSPACE alarm.
+// Note: 0 for no watermark, the alarm is still raised on snapshot ENOSPC errors,
+// whereas a WAL ENOSPC error stops the node, a watermark raises the alarm ahead.
+//
+// Default Value: 0.
+func WithDiskLowWatermark(n uint64) Option {
+	return optionFunc(func(c *config) {
+		c.lowWatermark = n
+	})
+}
+
 // WithMaxSnapshotFiles is the number of snapshots to keep beyond the
 // current snapshot.
 //
@@ -161,6 +603,128 @@ func WithMaxSnapshotFiles(max int) Option {
 	})
 }
 
+// WithMinSnapshotFiles is the number of the newest snapshots always kept,
+// regardless of the snapshot retention age.
+//
+// Default Value: 1.
+func WithMinSnapshotFiles(min int) Option {
+	return optionFunc(func(c *config) {
+		c.minSnapshotFiles = min
+	})
+}
+
+// WithSnapshotRetention purge snapshots older than the given duration,
+// even if within the MaxSnapshotFiles count, except the newest
+// MinSnapshotFiles snapshots that always kept.
+// Note: 0 to only bound the snapshots by count.
+//
+// Default Value: 0.
+func WithSnapshotRetention(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.snapRetention = d
+	})
+}
+
+// WithWALCompression compress entries data using snappy before
+// writing them into the WAL, Which saves disk space for log-heavy
+// state machines with compressible payloads.
+// Note: entries written while compression was enabled remain
+// readable after disabling it.
+//
+// Default Value: false.
+func WithWALCompression() Option {
+	return optionFunc(func(c *config) {
+		c.walCompression = true
+	})
+}
+
+// WithSnapshotCompression compress snapshots data using snappy before
+// writing them into the snapshot files, Snapshots sent to members that
+// does not support compression transcoded on the fly.
+// Note: snapshots written while compression was enabled remain
+// readable after disabling it.
+//
+// Default Value: false.
+func WithSnapshotCompression() Option {
+	return optionFunc(func(c *config) {
+		c.snapCompression = true
+	})
+}
+
+// WithVerifyWALOnBoot performs a full scan of the WAL segments at boot
+// before the node joins the cluster, validating records checksums,
+// entries indices continuity, and terms ordering.
+// Boot fails with a *WALVerificationError when the WAL is corrupted.
+//
+// Default Value: false.
+func WithVerifyWALOnBoot() Option {
+	return optionFunc(func(c *config) {
+		c.verifyWAL = true
+	})
+}
+
+// WithWALDropCache drops the pages of the sealed WAL segments from the page cache,
+// after reading them at boot and after each WAL files purge, using posix_fadvise(POSIX_FADV_DONTNEED).
+// The WAL segments are read and written through the page cache, not memory mapped,
+// and the sealed segments not read again until the next boot, Which keeps large WALs
+// from inflating the memory charged to memory-constrained nodes, e.g. a container cgroup.
+// Note: it's a no-op on the platforms not supporting posix_fadvise.
+//
+// Default Value: false.
+func WithWALDropCache() Option {
+	return optionFunc(func(c *config) {
+		c.walDropCache = true
+	})
+}
+
+// WithWALReadahead prefetches the WAL segments into the page cache at boot,
+// using posix_fadvise(POSIX_FADV_WILLNEED), Which speeds up reading large WALs on slow disks.
+// Note: it's a no-op on the platforms not supporting posix_fadvise.
+//
+// Default Value: false.
+func WithWALReadahead() Option {
+	return optionFunc(func(c *config) {
+		c.walReadahead = true
+	})
+}
+
+// WithEntrySpillThreshold is the max byte size of an entry data stored
+// within the WAL, oversized entries data stored in standalone blob files
+// referenced by the WAL, Which keeps the WAL segments size predictable
+// for workloads with occasional large payloads.
+// Note: 0 to store all entries data within the WAL.
+//
+// Default Value: 0.
+func WithEntrySpillThreshold(max uint64) Option {
+	return optionFunc(func(c *config) {
+		c.spillThreshold = max
+	})
+}
+
+// WithLogRetention retains WAL files modified within the given duration,
+// regardless of snapshots, Which keeps recent history available
+// for debugging and auditing even after the log compaction.
+// Note: 0 to purge WAL files as soon as they are covered by the retained snapshots.
+//
+// Default Value: 0.
+func WithLogRetention(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.logRetention = d
+	})
+}
+
+// WithLogRetentionSize is the max byte size of WAL files retained by
+// the log retention, once exceeded the oldest WAL files purged
+// even if they still within the retention duration.
+// Note: 0 for unlimited.
+//
+// Default Value: 0.
+func WithLogRetentionSize(max uint64) Option {
+	return optionFunc(func(c *config) {
+		c.logRetentionSize = max
+	})
+}
+
 // WithSnapshotInterval is the number of log entries between snapshots.
 //
 // Default Value: 1000.
@@ -170,6 +734,49 @@ func WithSnapshotInterval(i uint64) Option {
 	})
 }
 
+// WithRetainEntries is the number of trailing log entries kept after a snapshot,
+// for the slow followers to catch up from the log instead of a snapshot transfer.
+// Note: 0 to compact the log up to the snapshot index.
+//
+// Default Value: the snapshot interval.
+func WithRetainEntries(n uint64) Option {
+	return optionFunc(func(c *config) {
+		c.retainEntries = int64(n)
+	})
+}
+
+// WithSnapshotSchedule triggers a snapshot on the given schedule, independent of
+// the number of log entries between snapshots, so the log compaction happens predictably,
+// e.g. at low traffic times.
+//
+// The schedule is either a standard cron expression with five fields
+// (minute, hour, day of month, month, day of week) evaluated in the local time zone,
+// a predefined schedule (@yearly, @monthly, @weekly, @daily, @hourly),
+// or an interval (@every <duration>).
+//
+//	WithSnapshotSchedule("0 3 * * *")  // every day at 03:00.
+//	WithSnapshotSchedule("@every 6h") // every 6 hours.
+//
+// Note: an invalid schedule is reported when starting the node.
+//
+// Default Value: "" (disabled).
+func WithSnapshotSchedule(spec string) Option {
+	return optionFunc(func(c *config) {
+		c.snapSchedule = spec
+	})
+}
+
+// WithMaxSnapshotDeltas is the maximum number of delta snapshots chained onto
+// a full snapshot, before writing a new full snapshot.
+// Delta snapshots only written when the state machine implements IncrementalStateMachine.
+//
+// Default Value: 10.
+func WithMaxSnapshotDeltas(max int) Option {
+	return optionFunc(func(c *config) {
+		c.maxSnapDeltas = max
+	})
+}
+
 // WithElectionTick is the number of node tick (WithTickInterval) invocations that must
 // pass between elections. That is, if a follower does not receive any message from the
 // leader of current term before ElectionTick has elapsed, it will become candidate and
@@ -280,73 +887,603 @@ func WithDisableProposalForwarding() Option {
 	})
 }
 
-// WithContext set raft node parent ctx, The provided ctx must be non-nil.
-//
-// The context controls the entire lifetime of the raft node:
-// obtaining a connection, sending the msgs, reading the response, and process msgs.
+// WithDisableAutoPromotion disable the automatic promotion of staging members,
+// once they caught up with the leader, so operators keep control of the voter membership.
+// Staging members then remain non-voting members until promoted using Node.PromoteMember.
 //
-// Default Value: context.Background().
-func WithContext(ctx context.Context) Option {
+// Default Value: false.
+func WithDisableAutoPromotion() Option {
 	return optionFunc(func(c *config) {
-		c.ctx = ctx
+		c.noAutoPromotion = true
 	})
 }
 
-// WithLogger sets logger that is used to generates lines of output.
+// WithPromotionMinHealthy set the minimum duration a staging member must be continuously
+// reachable, before being automatically promoted to a voter member.
 //
-// Default Value: raftlog.DefaultLogger.
-func WithLogger(lg raftlog.Logger) Option {
+// Default Value: 0.
+func WithPromotionMinHealthy(d time.Duration) Option {
 	return optionFunc(func(c *config) {
-		c.logger = lg
+		c.promoteHealthy = d
 	})
 }
 
-func WithStateChangeCh(ch chan raft.StateType) Option {
+// WithHealthStallTimeout set the duration the applied index may lag the committed index without advancing,
+// before the node reported unhealthy, See Node.Health.
+//
+// Default Value: 30s.
+func WithHealthStallTimeout(d time.Duration) Option {
 	return optionFunc(func(c *config) {
-		c.stateChangeCh = ch
+		c.healthStall = d
 	})
 }
 
-// WithPipelining is the process to send successive requests,
-// over the same persistent connection, without waiting for the answer.
-// This avoids latency of the connection. Theoretically,
-// performance could also be improved if two or more requests were to be packed into the same connection.
+// WithAdmissionPolicy set the server side admission policy of the join and promote member requests,
+// such as the max learners at once, the allowed sources, and the per source rate limit.
 //
-// Note: pipelining spawn 4 goroutines per remote member connection.
-func WithPipelining() Option {
+// Default Value: nil, all requests admitted.
+func WithAdmissionPolicy(p AdmissionPolicy) Option {
 	return optionFunc(func(c *config) {
-		c.pipelining = true
+		c.admission = &p
 	})
 }
 
-// WithJoin send rpc request to join an existing cluster.
-func WithJoin(addr string, timeout time.Duration) StartOption {
-	return startOptionFunc(func(c *startConfig) {
-		opr := raftengine.Join(addr, timeout)
-		c.appendOperator(opr)
+// WithMaxProposalSize set the max size in bytes of the data proposed by Node.Replicate,
+// including the proposals forwarded by the followers, the larger proposals rejected with ErrProposalTooLarge.
+// A zero value means no limit.
+//
+// Default Value: 0.
+func WithMaxProposalSize(bytes int) Option {
+	return optionFunc(func(c *config) {
+		c.maxPropSize = bytes
 	})
 }
 
-// WithForceJoin send rpc request to join an existing cluster even if already part of a cluster.
-func WithForceJoin(addr string, timeout time.Duration) StartOption {
-	return startOptionFunc(func(c *startConfig) {
-		opr := raftengine.ForceJoin(addr, timeout)
-		c.appendOperator(opr)
+// WithProposalRateLimit set a token bucket cap on the proposals per second handled by the node,
+// including the proposals forwarded by the followers, the proposals beyond the limit rejected with ErrRateLimited.
+// The burst is the number of proposals allowed at once.
+// A zero rate means no limit.
+//
+// Default Value: 0.
+func WithProposalRateLimit(rate float64, burst int) Option {
+	return optionFunc(func(c *config) {
+		c.propRate = rate
+		c.propBurst = burst
 	})
 }
 
-// WithInitCluster initialize a new cluster and create first raft node.
-func WithInitCluster() StartOption {
-	return startOptionFunc(func(c *startConfig) {
-		opr := raftengine.InitCluster()
-		c.appendOperator(opr)
+// WithClientProposalRateLimit set a token bucket cap on the proposals per second forwarded by each client,
+// identified by its mutual TLS identity, or its member id, via the transport.
+// The proposals beyond the limit rejected with ErrRateLimited.
+// The burst is the number of proposals allowed at once.
+// A zero rate means no limit.
+//
+// Note: the forwarded proposals rejected by the leader are dropped,
+// therefore the followers Replicate calls wait until their context expires.
+//
+// Default Value: 0.
+func WithClientProposalRateLimit(rate float64, burst int) Option {
+	return optionFunc(func(c *config) {
+		c.clientPropRate = rate
+		c.clientPropBurst = burst
 	})
 }
 
-// WithForceNewCluster initialize a new cluster from state dir. One use case for
-// this feature would be in restoring cluster quorum.
+// WithAuthPolicy set the authentication policy of the rpc's between the cluster members,
+// such as a shared bearer token, or an allow list of mutual TLS identities.
+// Therefore, arbitrary hosts on the network can't join the cluster or inject raft messages.
 //
-// Note: ForceNewCluster preserve the same node id.
+// Note: the policy must be applied to all cluster nodes,
+// and the mutual TLS identities require the transport server to verify the client certificates, See WithTLS.
+//
+// Default Value: nil, all requests accepted.
+func WithAuthPolicy(p AuthPolicy) Option {
+	return optionFunc(func(c *config) {
+		c.auth = &p
+	})
+}
+
+// WithContext set raft node parent ctx, The provided ctx must be non-nil.
+//
+// The context controls the entire lifetime of the raft node:
+// obtaining a connection, sending the msgs, reading the response, and process msgs.
+//
+// Default Value: context.Background().
+func WithContext(ctx context.Context) Option {
+	return optionFunc(func(c *config) {
+		c.ctx = ctx
+	})
+}
+
+// WithLogger sets logger that is used to generates lines of output.
+//
+// Default Value: raftlog.DefaultLogger.
+func WithLogger(lg raftlog.Logger) Option {
+	return optionFunc(func(c *config) {
+		c.logger = lg
+	})
+}
+
+// WithStorageMetrics sets the sink that storage performance reported into.
+//
+// Default Value: nil.
+func WithStorageMetrics(m StorageMetrics) Option {
+	return optionFunc(func(c *config) {
+		c.storageMetrics = m
+	})
+}
+
+// WithTransportMetrics sets the sink that transport traffic and latency per member reported into,
+// for all the transports, which reveals the slow member links before elections start flapping.
+//
+// Default Value: nil.
+func WithTransportMetrics(m TransportMetrics) Option {
+	return optionFunc(func(c *config) {
+		c.transportMetrics = m
+	})
+}
+
+// WithSnapshotStore stores the snapshot files in the given object store bucket,
+// instead of the snapshot dir, Which avoids keeping a local copy of snapshots
+// and eases disaster recovery.
+// Partially received snapshot files are still kept in the snapshot dir
+// until the transfer completes.
+// Note: the store must not be shared with other nodes, e.g. use a key prefix per node.
+//
+// Default Value: nil.
+func WithSnapshotStore(s SnapshotStore) Option {
+	return optionFunc(func(c *config) {
+		c.snapshotStore = s
+	})
+}
+
+// WithSharedSnapshotStore set an object store bucket shared by the cluster members,
+// to install snapshots out of band. The leader publishes the snapshot files to the shared store
+// once, and sends the followers only the snapshot key to fetch it directly from the store,
+// Which offloads the leader when many followers are catching up.
+// Note: all the cluster members must be configured with the same shared store.
+//
+// Default Value: nil.
+func WithSharedSnapshotStore(s SnapshotStore) Option {
+	return optionFunc(func(c *config) {
+		c.sharedStore = s
+	})
+}
+
+// WithWALArchive archives the compacted WAL files into the given object store bucket,
+// along with an index manifest, before deleting them from the WAL dir.
+// The WAL files archived in the background, off the raft loop, and a WAL file retained until archived,
+// therefore an unavailable store delays the WAL files purge.
+// Which retains the full mutation history, as required by some compliance regimes,
+// See ReplayWALArchive.
+// Note: the store must not be shared with other nodes, e.g. use a key prefix per node.
+//
+// Default Value: nil.
+func WithWALArchive(s SnapshotStore) Option {
+	return optionFunc(func(c *config) {
+		c.walArchive = s
+	})
+}
+
+// WithStateChangeCh set the channel to receive the current node raft state changes.
+//
+// Deprecated: use Node.LeaderChanges.
+func WithStateChangeCh(ch chan raft.StateType) Option {
+	return optionFunc(func(c *config) {
+		c.stateChangeCh = ch
+	})
+}
+
+// WithSnapshotEventCh set the channel to receive the snapshot events,
+// such as the creation of a local snapshot or the transfer of a snapshot to a member,
+// including the number of bytes, duration, destination member, and the failure reason.
+//
+// Note: events are dropped when the channel is full, to not block the raft node.
+//
+// Default Value: nil.
+func WithSnapshotEventCh(ch chan SnapshotEvent) Option {
+	return optionFunc(func(c *config) {
+		c.snapEventCh = ch
+	})
+}
+
+// WithMemberEventCh set the channel to receive the member events,
+// such as the removal of a dead member, See WithDeadMemberTimeout,
+// or the member transition to unreachable and back to reachable, See WithRetryPolicy.
+//
+// Note: events are dropped when the channel is full, to not block the raft node.
+//
+// Default Value: nil.
+func WithMemberEventCh(ch chan MemberEvent) Option {
+	return optionFunc(func(c *config) {
+		c.membEventCh = ch
+	})
+}
+
+// WithDeadMemberTimeout set the duration after which the leader proposes to remove
+// a voter or learner member that has been unreachable, so dead members no longer
+// degrade the cluster quorum. The removal skipped when the remaining reachable voters
+// would not hold a quorum. A zero value disable the automatic removal.
+//
+// Note: the duration measured by the current leader, therefore it restarts on leader changes.
+//
+// Default Value: 0.
+func WithDeadMemberTimeout(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.deadTimeout = d
+	})
+}
+
+// WithMemberTypeMatcher set a function that classify the cluster members,
+// so embedders can change how the node interacts with certain members.
+// e.g. match a member as LocalMember to not dial it, or as RemovedMember to drop its messages.
+// The current node member is always matched as LocalMember.
+//
+// Note: the matcher only applied to the node view of the member, it does not change
+// the member type replicated cluster-wide, and must be safe for concurrent use.
+//
+// Default Value: nil.
+func WithMemberTypeMatcher(fn func(RawMember) MemberType) Option {
+	return optionFunc(func(c *config) {
+		c.typeMatcher = fn
+	})
+}
+
+// WithIDGenerator set a function that returns the generator of the unique ids,
+// of the proposals, the linearizable reads, and the conf changes, for the given current node member id.
+// It called on each node start.
+//
+// The default generator derives the ids from the member id and the wall clock,
+// so clock jumps may collide ids, e.g. use a monotonic per-node counter persisted by the application.
+//
+// Default Value: nil, the ids derived from the member id and the wall clock.
+func WithIDGenerator(fn func(memberID uint64) IDGenerator) Option {
+	return optionFunc(func(c *config) {
+		c.idGenerator = fn
+	})
+}
+
+// WithApplyHook set a function that called after applying each committed entry to the state machine,
+// along with the entry proposal metadata, e.g. to audit or trace the writes end-to-end.
+// See ContextWithMetadata.
+//
+// Note: the hook called synchronously on the apply path, therefore it must not block.
+//
+// Default Value: nil.
+func WithApplyHook(fn func(ApplyEvent)) Option {
+	return optionFunc(func(c *config) {
+		c.applyHook = fn
+	})
+}
+
+// WithProposalWeight set the number of the high priority proposals,
+// handed to raft for each normal priority proposal, when both lanes are pending.
+// Values lower than 1 treated as 1. See ContextWithPriority.
+//
+// Default Value: 4.
+func WithProposalWeight(n int) Option {
+	return optionFunc(func(c *config) {
+		c.proposalWeight = n
+	})
+}
+
+// WithSlowApplyThreshold set the duration beyond which applying an entry to the state machine
+// considered slow, logged as a warning along with the entry index, and counted. See Node.SlowOps.
+// Zero or negative value disables the slow apply detection.
+//
+// Default Value: 100ms.
+func WithSlowApplyThreshold(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.slowApply = d
+	})
+}
+
+// WithSlowSyncThreshold set the duration beyond which syncing the entries to the storage
+// considered slow, logged as a warning along with the last entry index, and counted. See Node.SlowOps.
+// Zero or negative value disables the slow sync detection.
+//
+// Default Value: 1s.
+func WithSlowSyncThreshold(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.slowSync = d
+	})
+}
+
+// WithSlowOpHook set a function that called on each slow state machine apply or storage sync,
+// e.g. to export them as events or metrics.
+//
+// Note: the hook called synchronously on the apply path, therefore it must not block.
+//
+// Default Value: nil.
+func WithSlowOpHook(fn func(SlowOpEvent)) Option {
+	return optionFunc(func(c *config) {
+		c.slowOpHook = fn
+	})
+}
+
+// WithMessageTap set a function that called for every inbound and outbound raft message,
+// so the wire behavior can be captured during an incident investigation.
+// See WithMessageTapSampling and WithMessageTapTypes.
+//
+// Note: the tap called synchronously on the messages path, therefore it must not block,
+// and must not retain or modify the message entries and snapshot data.
+//
+// Default Value: nil.
+func WithMessageTap(fn func(dir Direction, m Message)) Option {
+	return optionFunc(func(c *config) {
+		c.msgTap = fn
+	})
+}
+
+// WithMessageTapSampling set the message tap sampling, one of each n messages handed to the tap.
+// Values lower than 2 hand every message to the tap. See WithMessageTap.
+//
+// Default Value: 1.
+func WithMessageTapSampling(n uint64) Option {
+	return optionFunc(func(c *config) {
+		c.msgTapSampling = n
+	})
+}
+
+// WithMessageTapTypes set the types of the messages handed to the tap,
+// e.g. to exclude the heartbeats. See WithMessageTap.
+//
+// Default Value: nil, all the message types.
+func WithMessageTapTypes(types ...MessageType) Option {
+	return optionFunc(func(c *config) {
+		c.msgTapTypes = types
+	})
+}
+
+// WithEventBufferSize set the number of the last significant engine events retained in memory,
+// such as elections, snapshot installs, conf changes, and dropped messages, See Node.RecentEvents.
+// Zero or negative value disables the events recording.
+//
+// Default Value: 256.
+func WithEventBufferSize(n int) Option {
+	return optionFunc(func(c *config) {
+		c.eventBufSize = n
+	})
+}
+
+// WithAuditLogRetention set the number of the latest audit log records retained by the node,
+// the older records dropped, See Node.AuditLog.
+// Zero or negative value retains all the records.
+//
+// Default Value: 1024.
+func WithAuditLogRetention(n int) Option {
+	return optionFunc(func(c *config) {
+		c.auditRetention = n
+	})
+}
+
+// WithCorruptionCheck set the interval of the state machines consistency checks.
+// The leader periodically proposes a check, every member hashes its state machine
+// when applying the check entry, at the same index, and reports the hash.
+// The leader raises a CORRUPT alarm and records an EventCorruption for each member
+// whose hash diverged from the leader hash.
+// Zero or negative value disables the checks.
+//
+// Note: the state machine must implement HashStateMachine.
+//
+// Default Value: 0.
+func WithCorruptionCheck(interval time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.corruptCheck = interval
+	})
+}
+
+// WithClock set the clock providing the tickers driving the node, such as the raft logical clock,
+// the members monitors, and the linearizable reads retries.
+// It used to drive the node by a virtual clock in deterministic simulations, See rafttest.
+//
+// Default Value: nil, the wall clock.
+func WithClock(clock Clock) Option {
+	return optionFunc(func(c *config) {
+		c.clock = clock
+	})
+}
+
+// WithFaultInjector set the fault injector, that drops, duplicates, or delays the outbound messages per member,
+// fails the storage syncs, and crashes the node before or after applying an entry.
+// It used to run chaos suites against the state machine, See rafttest.Faults.
+//
+// Note: WithFaultInjector must be used for testing only.
+//
+// Default Value: nil, no faults injected.
+func WithFaultInjector(fi FaultInjector) Option {
+	return optionFunc(func(c *config) {
+		c.faults = fi
+	})
+}
+
+// WithMaxWaiters set the max number of the outstanding waiters of the proposals,
+// the linearizable reads, and the conf changes results.
+// The operations fail with ErrOverloaded once the limit reached.
+// Zero or negative value disables the limit.
+//
+// Default Value: 65536.
+func WithMaxWaiters(n int) Option {
+	return optionFunc(func(c *config) {
+		c.maxWaiters = n
+	})
+}
+
+// WithWaiterTTL set the duration after which an outstanding waiter considered abandoned,
+// e.g. its result never broadcasted, and garbage collected, the waiter if any receives an error.
+// It must exceed the longest operation context timeout.
+// Zero or negative value disables the garbage collection.
+//
+// Default Value: 10'm.
+func WithWaiterTTL(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.waiterTTL = d
+	})
+}
+
+// WithTLS set the TLS config used to dial the cluster members, over the gRPC or HTTP transports,
+// including the snapshot streams and the join requests.
+// For mutual TLS, the config must hold the node certificate,
+// and the root CAs used to verify the members certificates.
+//
+// Note: the transport server is created by the application, therefore it must be configured
+// with the same TLS config, and require the client certificates.
+// e.g. grpc.NewServer(grpc.Creds(credentials.NewTLS(cfg))) or http.Server{TLSConfig: cfg},
+// where cfg.ClientAuth set to tls.RequireAndVerifyClientCert.
+// The HTTP transport members addresses must use the https scheme.
+//
+// Use CertReloader to rotate the certificates without restarting the node.
+//
+// Default Value: nil.
+func WithTLS(cfg *tls.Config) Option {
+	return optionFunc(func(c *config) {
+		c.tlsConfig = cfg
+	})
+}
+
+// WithPipelining is the process to send successive requests,
+// over the same persistent connection, without waiting for the answer.
+// This avoids latency of the connection. Theoretically,
+// performance could also be improved if two or more requests were to be packed into the same connection.
+//
+// Note: pipelining spawn 4 goroutines per remote member connection.
+func WithPipelining() Option {
+	return optionFunc(func(c *config) {
+		c.pipelining = true
+	})
+}
+
+// WithMessageRelay relay the raft messages destined to a member that is unreachable directly,
+// through another reachable member, for hub-and-spoke or firewalled edge topologies
+// where full mesh connectivity is impossible. The snapshots are never relayed.
+//
+// Note: the relaying members must enable the message relay to forward the messages.
+//
+// Default Value: disabled.
+func WithMessageRelay() Option {
+	return optionFunc(func(c *config) {
+		c.relay = true
+	})
+}
+
+// WithMessageBatching batches up to size raft messages destined for the same member,
+// into a single rpc, instead of one rpc per message, which reduces the rpc overhead
+// of the heartbeat and append traffic at scale.
+// The window is the max duration to wait for more messages before sending a batch,
+// zero means only the messages already queued are batched, without adding latency.
+//
+// Note: all the cluster members must support message batching.
+//
+// Default Value: disabled.
+func WithMessageBatching(size int, window time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.batchSize = size
+		c.batchWindow = window
+	})
+}
+
+// WithMessageCompression compress the raft messages sent to the members,
+// using the given compression algorithm, which cross-region clusters benefit from.
+// The compression negotiated per member connection, therefore the messages sent uncompressed
+// until the member transport handler accepts the compression.
+// The messages smaller than the given threshold in bytes are never compressed,
+// so tiny messages (e.g. heartbeats) aren't compressed.
+//
+// Default Value: NoCompression.
+func WithMessageCompression(c Compression, threshold int) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.msgCompression = c
+		cfg.compThreshold = threshold
+	})
+}
+
+// WithRetryPolicy set the members dial timeout, and the retry policy of the raft messages
+// failed to be sent to a member, with exponential backoff between retries,
+// before reporting the member unreachable to raft.
+// The member transitions to unreachable and back to reachable emitted as member events,
+// See WithMemberEventCh.
+//
+// Note: the retries delay the subsequent messages destined for the same member.
+//
+// Default Value: no dial timeout and no retries.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return optionFunc(func(c *config) {
+		c.retryPolicy = p
+	})
+}
+
+// WithQueuePolicy set the bounds of each member outbound messages queue, in messages and bytes,
+// and whether to drop the oldest queued messages or reject the new messages when the queue is full,
+// so an unreachable member can't cause unbounded buffering.
+// The dropped and rejected messages reported to raft as unreachable, to be resent later.
+//
+// Default Value: 4096 messages (64 with pipelining), no bytes limit, reject new messages.
+func WithQueuePolicy(p QueuePolicy) Option {
+	return optionFunc(func(c *config) {
+		c.queuePolicy = p
+	})
+}
+
+// WithJoin send rpc request to join an existing cluster.
+func WithJoin(addr string, timeout time.Duration) StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.Join(addr, timeout)
+		c.appendOperator(opr)
+	})
+}
+
+// WithForceJoin send rpc request to join an existing cluster even if already part of a cluster.
+func WithForceJoin(addr string, timeout time.Duration) StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.ForceJoin(addr, timeout)
+		c.appendOperator(opr)
+	})
+}
+
+// WithJoinRetry retries the join requests with exponential backoff, starting from the initial backoff
+// and doubling up to the max backoff, while the cluster not ready to serve them,
+// e.g. unreachable or no elected leader. The rejected join requests are not retried.
+//
+// When composed with WithFallback, the fallback join options retried together on each attempt.
+//
+//	n.Start(
+//		WithJoinRetry(5, time.Second, 10*time.Second),
+//		WithFallback(
+//			WithJoin(<node A>, time.Second),
+//			WithJoin(<node B>, time.Second),
+//		),
+//	)
+//
+// Default Value: no retries.
+func WithJoinRetry(maxRetries int, initialBackoff, maxBackoff time.Duration) StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.JoinRetry(membership.RetryPolicy{
+			MaxRetries:     maxRetries,
+			InitialBackoff: initialBackoff,
+			MaxBackoff:     maxBackoff,
+		})
+		c.appendOperator(opr)
+	})
+}
+
+// WithInitCluster initialize a new cluster and create first raft node.
+func WithInitCluster() StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.InitCluster()
+		c.appendOperator(opr)
+	})
+}
+
+// WithForceNewCluster initialize a new cluster from state dir. One use case for
+// this feature would be in restoring cluster quorum,
+// when a majority of the members are permanently lost.
+//
+// The WAL and the latest snapshot rewritten so the current node becomes
+// the single voter of the cluster, preserving its state machine data,
+// the uncommitted entries discarded and the other members removed.
+// The remaining members can then be added back by WithMembers, AddMember, or join.
+//
+// Note: ForceNewCluster preserve the same node id.
 func WithForceNewCluster() StartOption {
 	return startOptionFunc(func(c *startConfig) {
 		opr := raftengine.ForceNewCluster()
@@ -354,15 +1491,28 @@ func WithForceNewCluster() StartOption {
 	})
 }
 
-// WithRestore initialize a new cluster from snapshot file. One use case for
-// this feature would be in restoring cluster data.
-func WithRestore(path string) StartOption {
+// WithRestoreFromSnapshot initialize a new cluster from an exported snapshot file,
+// seeding the state machine and raft state from the snapshot. One use case for
+// this feature would be in disaster recovery, restoring the cluster data on fresh nodes.
+//
+// The snapshot conf state is re-stamped with the new cluster members,
+// the current node and the members added by WithMembers.
+//
+// Note: the snapshot file must be a full snapshot, delta snapshots rejected.
+func WithRestoreFromSnapshot(path string) StartOption {
 	return startOptionFunc(func(c *startConfig) {
 		opr := raftengine.Restore(path)
 		c.appendOperator(opr)
 	})
 }
 
+// WithRestore initialize a new cluster from snapshot file.
+//
+// Deprecated: use WithRestoreFromSnapshot.
+func WithRestore(path string) StartOption {
+	return WithRestoreFromSnapshot(path)
+}
+
 // WithRestart restart raft node from state dir.
 func WithRestart() StartOption {
 	return startOptionFunc(func(c *startConfig) {
@@ -374,7 +1524,7 @@ func WithRestart() StartOption {
 // WithMembers add the given members to the raft node.
 //
 // WithMembers safe to be used with initiate cluster kind options,
-// ("WithForceNewCluster", "WithRestore", "WithInitCluster")
+// ("WithForceNewCluster", "WithRestoreFromSnapshot", "WithInitCluster")
 // Otherwise, it may conflicts with other options like WithJoin.
 //
 // As long as only one url member, WithMembers will only set the current node,
@@ -397,6 +1547,79 @@ func WithMembers(membs ...RawMember) StartOption {
 	})
 }
 
+// WithInitialMembers bootstrap the raft node from a static declared cluster,
+// similar to etcd initial-cluster, so the deployments are reproducible.
+//
+// All cluster nodes must start with the same initial members list,
+// where each member has a stable id and address, and they form the initial voters together.
+// The current node identified by its address, therefore WithAddress must be applied too.
+//
+// The node initialize the cluster on first start, and restart from state dir afterward,
+// so WithInitialMembers must not be composed with other start options except WithAddress, WithLabels,
+// and WithClusterToken, which must be applied to fence the cluster from the other clusters of the same members.
+//
+//	members := []RawMember{
+//		{ID: 1, Address: "node-a:8080"},
+//		{ID: 2, Address: "node-b:8080"},
+//		{ID: 3, Address: "node-c:8080"},
+//	}
+//
+//	Node A:
+//	n.Start(WithAddress("node-a:8080"), WithInitialMembers(members...))
+//
+//	Node B:
+//	n.Start(WithAddress("node-b:8080"), WithInitialMembers(members...))
+func WithInitialMembers(membs ...RawMember) StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.InitialMembers(membs...)
+		c.appendOperator(opr)
+	})
+}
+
+// WithClusterToken set the token of a new cluster, similar to etcd initial-cluster-token,
+// the cluster id derived from the token and the initial members, so all the initial members agree on it,
+// while the clusters bootstrapped from the same members config with different tokens fenced from each other.
+// See Node.ClusterID.
+//
+// A single member cluster initialized without a token gets a random cluster id,
+// whereas a multiple members cluster initialized without a token has no cluster id, and not fenced.
+//
+// Note: the token must be unique per cluster bootstrap, e.g. a generated uuid,
+// and applied to all the initial members, along with WithInitCluster or WithInitialMembers.
+func WithClusterToken(token string) StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.ClusterToken(token)
+		c.appendOperator(opr)
+	})
+}
+
+// WithLabels set the raft node member labels, such as zone, rack, and build version.
+// The labels replicated cluster-wide along with the member,
+// and exposed by Member.Labels on all cluster nodes.
+//
+// Note: WithLabels only applied when the node first joins or initialize the cluster,
+// Use Node.UpdateMember to change the labels of an existing member.
+func WithLabels(labels map[string]string) StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.Labels(labels)
+		c.appendOperator(opr)
+	})
+}
+
+// WithLearner set the raft node member as a learner, when the node first joins the cluster,
+// so it does not participate in elections or log entry commitment while catching up the leader log.
+// The leader automatically promotes it to a voter once caught up, per the promotion policy,
+// See WithPromotionMinHealthy and WithDisableAutoPromotion.
+//
+// Note: WithLearner can't be composed with WithInitCluster,
+// Use Node.DemoteMember to turn an existing voter into a learner.
+func WithLearner() StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		opr := raftengine.Learner()
+		c.appendOperator(opr)
+	})
+}
+
 // WithAddress set the raft node address.
 func WithAddress(addr string) StartOption {
 	return startOptionFunc(func(c *startConfig) {
@@ -404,6 +1627,19 @@ func WithAddress(addr string) StartOption {
 	})
 }
 
+// WithAdvertiseAddress set the raft node address advertised to the cluster members,
+// distinct from the address set by WithAddress which the node transport server listens on.
+// The advertised address registered in the node member record, sent within the join request,
+// and used to identify the node within the initial members.
+// Nodes behind NAT or load balancers use it to register a reachable address instead of their local bind address.
+//
+// Default Value: the address set by WithAddress.
+func WithAdvertiseAddress(addr string) StartOption {
+	return startOptionFunc(func(c *startConfig) {
+		c.advertiseAddr = addr
+	})
+}
+
 // WithFallback can be used if other options do not succeed.
 //
 //	WithFallback(
@@ -423,8 +1659,17 @@ func WithFallback(opts ...StartOption) StartOption {
 }
 
 type startConfig struct {
-	operators []raftengine.Operator
-	addr      string
+	operators     []raftengine.Operator
+	addr          string
+	advertiseAddr string
+}
+
+// address returns the address registered in the node member record.
+func (c *startConfig) address() string {
+	if len(c.advertiseAddr) > 0 {
+		return c.advertiseAddr
+	}
+	return c.addr
 }
 
 func (c *startConfig) appendOperator(opr raftengine.Operator) {
@@ -444,8 +1689,28 @@ type config struct {
 	streamTimeOut    time.Duration
 	drainTimeOut     time.Duration
 	statedir         string
+	waldir           string
+	snapdir          string
+	lowWatermark     uint64
+	walCompression   bool
+	snapCompression  bool
+	verifyWAL        bool
+	walDropCache     bool
+	walReadahead     bool
+	logRetention     time.Duration
+	logRetentionSize uint64
+	spillThreshold   uint64
+	storageMetrics   storage.Metrics
+	snapshotStore    storage.ObjectStore
+	sharedStore      storage.ObjectStore
+	walArchive       storage.ObjectStore
 	maxSnapshotFiles int
+	minSnapshotFiles int
+	snapRetention    time.Duration
 	snapInterval     uint64
+	maxSnapDeltas    int
+	snapSchedule     string
+	retainEntries    int64
 	groupID          uint64
 	controller       transport.Controller
 	storage          storage.Storage
@@ -457,6 +1722,46 @@ type config struct {
 	logger           raftlog.Logger
 	pipelining       bool
 	stateChangeCh    chan raft.StateType
+	snapEventCh      chan SnapshotEvent
+	membEventCh      chan MemberEvent
+	deadTimeout      time.Duration
+	noAutoPromotion  bool
+	promoteHealthy   time.Duration
+	admission        *AdmissionPolicy
+	maxPropSize      int
+	healthStall      time.Duration
+	propRate         float64
+	propBurst        int
+	clientPropRate   float64
+	clientPropBurst  int
+	typeMatcher      func(RawMember) MemberType
+	idGenerator      func(uint64) IDGenerator
+	applyHook        func(ApplyEvent)
+	proposalWeight   int
+	slowApply        time.Duration
+	slowSync         time.Duration
+	slowOpHook       func(SlowOpEvent)
+	msgTap           func(Direction, Message)
+	msgTapSampling   uint64
+	msgTapTypes      []MessageType
+	eventBufSize     int
+	corruptCheck     time.Duration
+	clock            Clock
+	inMemory         bool
+	faults           FaultInjector
+	maxWaiters       int
+	waiterTTL        time.Duration
+	auditRetention   int
+	tlsConfig        *tls.Config
+	auth             *AuthPolicy
+	batchSize        int
+	batchWindow      time.Duration
+	msgCompression   Compression
+	compThreshold    int
+	retryPolicy      RetryPolicy
+	queuePolicy      QueuePolicy
+	transportMetrics TransportMetrics
+	relay            bool
 }
 
 func (c *config) Logger() raftlog.Logger {
@@ -491,10 +1796,91 @@ func (c *config) StateDir() string {
 	return c.statedir
 }
 
+func (c *config) WALDir() string {
+	if len(c.waldir) == 0 {
+		return filepath.Join(c.statedir, "wal")
+	}
+	return c.waldir
+}
+
+func (c *config) SnapDir() string {
+	if len(c.snapdir) == 0 {
+		return filepath.Join(c.statedir, "snap")
+	}
+	return c.snapdir
+}
+
+func (c *config) DiskLowWatermark() uint64 {
+	return c.lowWatermark
+}
+
 func (c *config) MaxSnapshotFiles() int {
 	return c.maxSnapshotFiles
 }
 
+func (c *config) MinSnapshotFiles() int {
+	return c.minSnapshotFiles
+}
+
+func (c *config) SnapshotRetention() time.Duration {
+	return c.snapRetention
+}
+
+func (c *config) WALCompression() bool {
+	return c.walCompression
+}
+
+func (c *config) SnapshotCompression() bool {
+	return c.snapCompression
+}
+
+func (c *config) snapshotCompression() raftpb.Compression {
+	if c.snapCompression {
+		return raftpb.SnappyCompression
+	}
+	return raftpb.NoCompression
+}
+
+func (c *config) VerifyWALOnBoot() bool {
+	return c.verifyWAL
+}
+
+func (c *config) WALDropCache() bool {
+	return c.walDropCache
+}
+
+func (c *config) WALReadahead() bool {
+	return c.walReadahead
+}
+
+func (c *config) LogRetention() time.Duration {
+	return c.logRetention
+}
+
+func (c *config) LogRetentionSize() uint64 {
+	return c.logRetentionSize
+}
+
+func (c *config) EntrySpillThreshold() uint64 {
+	return c.spillThreshold
+}
+
+func (c *config) SnapshotStore() storage.ObjectStore {
+	return c.snapshotStore
+}
+
+func (c *config) SharedSnapshotStore() storage.ObjectStore {
+	return c.sharedStore
+}
+
+func (c *config) WALArchive() storage.ObjectStore {
+	return c.walArchive
+}
+
+func (c *config) StorageMetrics() storage.Metrics {
+	return c.storageMetrics
+}
+
 func (c *config) Controller() transport.Controller {
 	return c.controller
 }
@@ -507,6 +1893,21 @@ func (c *config) SnapInterval() uint64 {
 	return c.snapInterval
 }
 
+func (c *config) MaxSnapshotDeltas() int {
+	return c.maxSnapDeltas
+}
+
+func (c *config) RetainEntries() uint64 {
+	if c.retainEntries < 0 {
+		return c.snapInterval
+	}
+	return uint64(c.retainEntries)
+}
+
+func (c *config) SnapshotSchedule() string {
+	return c.snapSchedule
+}
+
 func (c *config) RaftConfig() *raft.Config {
 	return c.rcfg
 }
@@ -531,14 +1932,149 @@ func (c *config) Mux() raftengine.Mux {
 	return c.mux
 }
 
+func (c *config) AllowRelay() bool {
+	return c.relay
+}
+
 func (c *config) AllowPipelining() bool {
 	return c.pipelining
 }
 
+func (c *config) BatchSize() int {
+	return c.batchSize
+}
+
+func (c *config) BatchWindow() time.Duration {
+	return c.batchWindow
+}
+
+func (c *config) RetryPolicy() RetryPolicy {
+	return c.retryPolicy
+}
+
+func (c *config) TransportMetrics() transport.Metrics {
+	return c.transportMetrics
+}
+
+func (c *config) QueuePolicy() QueuePolicy {
+	return c.queuePolicy
+}
+
+func (c *config) MessageCompression() raftpb.Compression {
+	return c.msgCompression
+}
+
+func (c *config) CompressionThreshold() int {
+	return c.compThreshold
+}
+
 func (c *config) StateChangeCh() chan raft.StateType {
 	return c.stateChangeCh
 }
 
+func (c *config) SnapshotEventCh() chan SnapshotEvent {
+	return c.snapEventCh
+}
+
+func (c *config) MemberEventCh() chan MemberEvent {
+	return c.membEventCh
+}
+
+func (c *config) DeadMemberTimeout() time.Duration {
+	return c.deadTimeout
+}
+
+func (c *config) DisableAutoPromotion() bool {
+	return c.noAutoPromotion
+}
+
+func (c *config) PromotionMinHealthy() time.Duration {
+	return c.promoteHealthy
+}
+
+func (c *config) TLSConfig() *tls.Config {
+	return c.tlsConfig
+}
+
+func (c *config) AuthToken() string {
+	if c.auth == nil {
+		return ""
+	}
+	return c.auth.Token
+}
+
+func (c *config) MemberTypeMatcher() func(raftpb.Member) raftpb.MemberType {
+	return c.typeMatcher
+}
+
+func (c *config) IDGenerator() func(memberID uint64) IDGenerator {
+	return c.idGenerator
+}
+
+func (c *config) ApplyHook() func(ApplyEvent) {
+	return c.applyHook
+}
+
+func (c *config) ProposalWeight() int {
+	return c.proposalWeight
+}
+
+func (c *config) SlowApplyThreshold() time.Duration {
+	return c.slowApply
+}
+
+func (c *config) SlowSyncThreshold() time.Duration {
+	return c.slowSync
+}
+
+func (c *config) SlowOpHook() func(SlowOpEvent) {
+	return c.slowOpHook
+}
+
+func (c *config) MessageTap() func(Direction, Message) {
+	return c.msgTap
+}
+
+func (c *config) MessageTapSampling() uint64 {
+	return c.msgTapSampling
+}
+
+func (c *config) MessageTapTypes() []MessageType {
+	return c.msgTapTypes
+}
+
+func (c *config) EventBufferSize() int {
+	return c.eventBufSize
+}
+
+func (c *config) CorruptCheckInterval() time.Duration {
+	return c.corruptCheck
+}
+
+func (c *config) Clock() Clock {
+	return c.clock
+}
+
+func (c *config) InMemoryStorage() bool {
+	return c.inMemory
+}
+
+func (c *config) FaultInjector() FaultInjector {
+	return c.faults
+}
+
+func (c *config) MaxWaiters() int {
+	return c.maxWaiters
+}
+
+func (c *config) WaiterTTL() time.Duration {
+	return c.waiterTTL
+}
+
+func (c *config) AuditLogRetention() int {
+	return c.auditRetention
+}
+
 func newConfig(opts ...Option) *config {
 	c := &config{
 		rcfg: &raft.Config{
@@ -553,7 +2089,19 @@ func newConfig(opts ...Option) *config {
 		streamTimeOut:    time.Second * 10,
 		drainTimeOut:     time.Second * 10,
 		maxSnapshotFiles: 5,
+		minSnapshotFiles: 1,
 		snapInterval:     1000,
+		maxSnapDeltas:    10,
+		proposalWeight:   4,
+		healthStall:      time.Second * 30,
+		slowApply:        time.Millisecond * 100,
+		slowSync:         time.Second,
+		msgTapSampling:   1,
+		eventBufSize:     256,
+		maxWaiters:       1 << 16,
+		waiterTTL:        time.Minute * 10,
+		auditRetention:   1024,
+		retainEntries:    -1,
 		logger:           raftlog.DefaultLogger,
 		statedir:         os.TempDir(),
 		pipelining:       false,
@@ -2,17 +2,21 @@ package raft
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/shaj13/raft/raftlog"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/v3"
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
 )
 
 func TestConfig(t *testing.T) {
+	snapEventCh := make(chan SnapshotEvent)
 	table := []struct {
 		defaults interface{}
 		expected interface{}
@@ -25,6 +29,258 @@ func TestConfig(t *testing.T) {
 			opt:      WithPipelining(),
 			value:    func(c *config) interface{} { return c.pipelining },
 		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithMessageRelay(),
+			value:    func(c *config) interface{} { return c.AllowRelay() },
+		},
+		{
+			defaults: 0,
+			expected: 10,
+			opt:      WithMessageBatching(10, time.Millisecond),
+			value:    func(c *config) interface{} { return c.BatchSize() },
+		},
+		{
+			defaults: time.Duration(0),
+			expected: time.Millisecond,
+			opt:      WithMessageBatching(10, time.Millisecond),
+			value:    func(c *config) interface{} { return c.BatchWindow() },
+		},
+		{
+			defaults: NoCompression,
+			expected: ZstdCompression,
+			opt:      WithMessageCompression(ZstdCompression, 512),
+			value:    func(c *config) interface{} { return c.MessageCompression() },
+		},
+		{
+			defaults: 0,
+			expected: 512,
+			opt:      WithMessageCompression(ZstdCompression, 512),
+			value:    func(c *config) interface{} { return c.CompressionThreshold() },
+		},
+		{
+			defaults: time.Second * 30,
+			expected: time.Second,
+			opt:      WithHealthStallTimeout(time.Second),
+			value:    func(c *config) interface{} { return c.healthStall },
+		},
+		{
+			defaults: 0,
+			expected: 1024,
+			opt:      WithMaxProposalSize(1024),
+			value:    func(c *config) interface{} { return c.maxPropSize },
+		},
+		{
+			defaults: []interface{}{float64(0), 0},
+			expected: []interface{}{float64(10), 20},
+			opt:      WithProposalRateLimit(10, 20),
+			value:    func(c *config) interface{} { return []interface{}{c.propRate, c.propBurst} },
+		},
+		{
+			defaults: []interface{}{float64(0), 0},
+			expected: []interface{}{float64(1), 2},
+			opt:      WithClientProposalRateLimit(1, 2),
+			value:    func(c *config) interface{} { return []interface{}{c.clientPropRate, c.clientPropBurst} },
+		},
+		{
+			defaults: 4,
+			expected: 8,
+			opt:      WithProposalWeight(8),
+			value:    func(c *config) interface{} { return c.ProposalWeight() },
+		},
+		{
+			defaults: time.Millisecond * 100,
+			expected: time.Second,
+			opt:      WithSlowApplyThreshold(time.Second),
+			value:    func(c *config) interface{} { return c.SlowApplyThreshold() },
+		},
+		{
+			defaults: time.Second,
+			expected: time.Second * 5,
+			opt:      WithSlowSyncThreshold(time.Second * 5),
+			value:    func(c *config) interface{} { return c.SlowSyncThreshold() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithSlowOpHook(func(SlowOpEvent) {}),
+			value:    func(c *config) interface{} { return c.SlowOpHook() != nil },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithMessageTap(func(Direction, Message) {}),
+			value:    func(c *config) interface{} { return c.MessageTap() != nil },
+		},
+		{
+			defaults: uint64(1),
+			expected: uint64(10),
+			opt:      WithMessageTapSampling(10),
+			value:    func(c *config) interface{} { return c.MessageTapSampling() },
+		},
+		{
+			defaults: []MessageType(nil),
+			expected: []MessageType{etcdraftpb.MsgApp},
+			opt:      WithMessageTapTypes(etcdraftpb.MsgApp),
+			value:    func(c *config) interface{} { return c.MessageTapTypes() },
+		},
+		{
+			defaults: time.Duration(0),
+			expected: time.Minute,
+			opt:      WithCorruptionCheck(time.Minute),
+			value:    func(c *config) interface{} { return c.CorruptCheckInterval() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithClock(new(testClock)),
+			value:    func(c *config) interface{} { return c.Clock() != nil },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithInMemoryStorage(),
+			value:    func(c *config) interface{} { return c.InMemoryStorage() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithFaultInjector(new(testFaults)),
+			value:    func(c *config) interface{} { return c.FaultInjector() != nil },
+		},
+		{
+			defaults: 1 << 16,
+			expected: 10,
+			opt:      WithMaxWaiters(10),
+			value:    func(c *config) interface{} { return c.MaxWaiters() },
+		},
+		{
+			defaults: time.Minute * 10,
+			expected: time.Minute,
+			opt:      WithWaiterTTL(time.Minute),
+			value:    func(c *config) interface{} { return c.WaiterTTL() },
+		},
+		{
+			defaults: 256,
+			expected: 10,
+			opt:      WithEventBufferSize(10),
+			value:    func(c *config) interface{} { return c.EventBufferSize() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithApplyHook(func(ApplyEvent) {}),
+			value:    func(c *config) interface{} { return c.ApplyHook() != nil },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithIDGenerator(func(uint64) IDGenerator { return nil }),
+			value:    func(c *config) interface{} { return c.IDGenerator() != nil },
+		},
+		{
+			defaults: RetryPolicy{},
+			expected: RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond},
+			opt:      WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}),
+			value:    func(c *config) interface{} { return c.RetryPolicy() },
+		},
+		{
+			defaults: QueuePolicy{},
+			expected: QueuePolicy{MaxBytes: 1024, DropOldest: true},
+			opt:      WithQueuePolicy(QueuePolicy{MaxBytes: 1024, DropOldest: true}),
+			value:    func(c *config) interface{} { return c.QueuePolicy() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithWALCompression(),
+			value:    func(c *config) interface{} { return c.WALCompression() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithSnapshotCompression(),
+			value:    func(c *config) interface{} { return c.SnapshotCompression() },
+		},
+		{
+			defaults: (chan SnapshotEvent)(nil),
+			expected: snapEventCh,
+			opt:      WithSnapshotEventCh(snapEventCh),
+			value:    func(c *config) interface{} { return c.SnapshotEventCh() },
+		},
+		{
+			defaults: 1,
+			expected: 3,
+			opt:      WithMinSnapshotFiles(3),
+			value:    func(c *config) interface{} { return c.MinSnapshotFiles() },
+		},
+		{
+			defaults: time.Duration(0),
+			expected: time.Hour,
+			opt:      WithSnapshotRetention(time.Hour),
+			value:    func(c *config) interface{} { return c.SnapshotRetention() },
+		},
+		{
+			defaults: uint64(1000),
+			expected: uint64(0),
+			opt:      WithRetainEntries(0),
+			value:    func(c *config) interface{} { return c.RetainEntries() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithDisableAutoPromotion(),
+			value:    func(c *config) interface{} { return c.DisableAutoPromotion() },
+		},
+		{
+			defaults: time.Duration(0),
+			expected: time.Minute,
+			opt:      WithPromotionMinHealthy(time.Minute),
+			value:    func(c *config) interface{} { return c.PromotionMinHealthy() },
+		},
+		{
+			defaults: (*tls.Config)(nil),
+			expected: &tls.Config{ServerName: "raft"},
+			opt:      WithTLS(&tls.Config{ServerName: "raft"}),
+			value:    func(c *config) interface{} { return c.TLSConfig() },
+		},
+		{
+			defaults: time.Duration(0),
+			expected: time.Minute,
+			opt:      WithDeadMemberTimeout(time.Minute),
+			value:    func(c *config) interface{} { return c.DeadMemberTimeout() },
+		},
+		{
+			defaults: "",
+			expected: "0 3 * * *",
+			opt:      WithSnapshotSchedule("0 3 * * *"),
+			value:    func(c *config) interface{} { return c.SnapshotSchedule() },
+		},
+		{
+			defaults: false,
+			expected: true,
+			opt:      WithVerifyWALOnBoot(),
+			value:    func(c *config) interface{} { return c.VerifyWALOnBoot() },
+		},
+		{
+			defaults: time.Duration(0),
+			expected: time.Hour,
+			opt:      WithLogRetention(time.Hour),
+			value:    func(c *config) interface{} { return c.LogRetention() },
+		},
+		{
+			defaults: uint64(0),
+			expected: uint64(1 << 30),
+			opt:      WithLogRetentionSize(1 << 30),
+			value:    func(c *config) interface{} { return c.LogRetentionSize() },
+		},
+		{
+			defaults: uint64(0),
+			expected: uint64(1 << 20),
+			opt:      WithEntrySpillThreshold(1 << 20),
+			value:    func(c *config) interface{} { return c.EntrySpillThreshold() },
+		},
 		{
 			defaults: raft.ReadOnlySafe,
 			expected: raft.ReadOnlySafe,
@@ -73,6 +329,24 @@ func TestConfig(t *testing.T) {
 			opt:      WithStateDIR("/var/lib"),
 			value:    func(c *config) interface{} { return c.StateDir() },
 		},
+		{
+			defaults: filepath.Join(os.TempDir(), "wal"),
+			expected: "/mnt/nvme/wal",
+			opt:      WithWALDIR("/mnt/nvme/wal"),
+			value:    func(c *config) interface{} { return c.WALDir() },
+		},
+		{
+			defaults: filepath.Join(os.TempDir(), "snap"),
+			expected: "/mnt/hdd/snap",
+			opt:      WithSnapshotDIR("/mnt/hdd/snap"),
+			value:    func(c *config) interface{} { return c.SnapDir() },
+		},
+		{
+			defaults: uint64(0),
+			expected: uint64(1024),
+			opt:      WithDiskLowWatermark(1024),
+			value:    func(c *config) interface{} { return c.DiskLowWatermark() },
+		},
 		{
 			defaults: 5,
 			expected: 10,
@@ -85,6 +359,12 @@ func TestConfig(t *testing.T) {
 			opt:      WithSnapshotInterval(2000),
 			value:    func(c *config) interface{} { return c.SnapInterval() },
 		},
+		{
+			defaults: 10,
+			expected: 5,
+			opt:      WithMaxSnapshotDeltas(5),
+			value:    func(c *config) interface{} { return c.MaxSnapshotDeltas() },
+		},
 		{
 			defaults: 10,
 			expected: 100,
@@ -157,11 +437,14 @@ func TestStartConfig(t *testing.T) {
 	}{
 		{expected: "raftengine.join", opt: WithJoin("", 0)},
 		{expected: "raftengine.forceJoin", opt: WithForceJoin("", 0)},
+		{expected: "raftengine.joinRetry", opt: WithJoinRetry(1, 0, 0)},
+		{expected: "raftengine.learner", opt: WithLearner()},
 		{expected: "raftengine.initCluster", opt: WithInitCluster()},
 		{expected: "raftengine.forceNewCluster", opt: WithForceNewCluster()},
 		{expected: "raftengine.restart", opt: WithRestart()},
 		{expected: "*raftengine.fallback", opt: WithFallback()},
 		{expected: "raftengine.restore", opt: WithRestore("")},
+		{expected: "raftengine.restore", opt: WithRestoreFromSnapshot("")},
 		{expected: "raftengine.members", opt: WithMembers()},
 	}
 
@@ -180,3 +463,34 @@ func TestWithAddress(t *testing.T) {
 	opt.apply(c)
 	require.Equal(t, addr, c.addr)
 }
+
+func TestWithAdvertiseAddress(t *testing.T) {
+	c := new(startConfig)
+	c.apply(WithAddress("0.0.0.0:8080"))
+	require.Equal(t, "0.0.0.0:8080", c.address())
+
+	// it advertise the given address instead of the listen address.
+	c.apply(WithAdvertiseAddress("node-a.example.com:8080"))
+	require.Equal(t, "0.0.0.0:8080", c.addr)
+	require.Equal(t, "node-a.example.com:8080", c.address())
+}
+
+type testClock struct{}
+
+type testFaults struct{}
+
+func (testFaults) MessageFault(etcdraftpb.Message) (FaultAction, time.Duration) {
+	return FaultNone, 0
+}
+
+func (testFaults) SyncFault(uint64) error {
+	return nil
+}
+
+func (testFaults) ApplyFault(ApplyStage, uint64) error {
+	return nil
+}
+
+func (testClock) NewTicker(time.Duration) Ticker {
+	return nil
+}
@@ -0,0 +1,190 @@
+// Package raftapi implements an optional gRPC service exposing the raft node API,
+// so non-Go clients and sidecars talk to the cluster without embedding the library.
+//
+// The Raft service replicates and reads the state machine, and the RaftAdmin service
+// inspects and manages the cluster membership, See raftapi.proto.
+//
+//	srv := grpc.NewServer()
+//	raftgrpc.RegisterHandler(srv, node.Handler())
+//	raftapi.Register(srv, node, raftapi.WithQuery(query))
+//
+// The node errors returned to the clients as gRPC statuses, carrying the raft error reason,
+// e.g. the not leader error carries the known leader hint, See the errors package.
+package raftapi
+
+import (
+	"context"
+
+	"github.com/shaj13/raft"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// QueryFunc evaluates the given raw query against the state machine, and returns the raw result,
+// See WithQuery.
+type QueryFunc func(ctx context.Context, sm raft.StateMachine, query []byte) ([]byte, error)
+
+// Node is the raft node served by the API, implemented by *raft.Node.
+type Node interface {
+	Replicate(ctx context.Context, data []byte) error
+	LinearizableGet(ctx context.Context, fn func(sm raft.StateMachine) error) error
+	Status() (raft.Status, error)
+	ClusterID() uint64
+	Members() []raft.Member
+	AddMember(ctx context.Context, raw *raft.RawMember) error
+	RemoveMember(ctx context.Context, id uint64) error
+	PromoteMember(ctx context.Context, id uint64) error
+	DemoteMember(ctx context.Context, id uint64) error
+	TransferLeadership(ctx context.Context, id uint64) error
+}
+
+// Option configures the Server.
+type Option func(*Server)
+
+// WithQuery set the function evaluating the Read RPC queries,
+// it runs after a linearizable read, so the query observes all the writes completed before the call.
+//
+// Default Value: nil, the Read RPC returns codes.Unimplemented.
+func WithQuery(fn QueryFunc) Option {
+	return func(s *Server) {
+		s.query = fn
+	}
+}
+
+// Register registers the Raft and RaftAdmin services of the given node to the given gRPC server.
+func Register(srv *grpc.Server, n Node, opts ...Option) {
+	s := NewServer(n, opts...)
+	RegisterRaftServer(srv, s)
+	RegisterRaftAdminServer(srv, s)
+}
+
+// NewServer returns a new Server of the given node.
+func NewServer(n Node, opts ...Option) *Server {
+	s := &Server{n: n}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Server implements the RaftServer and RaftAdminServer interfaces on top of a raft node.
+type Server struct {
+	n     Node
+	query QueryFunc
+}
+
+// Replicate implements RaftServer.
+func (s *Server) Replicate(ctx context.Context, req *ReplicateRequest) (*emptypb.Empty, error) {
+	if len(req.Metadata) > 0 {
+		ctx = raft.ContextWithMetadata(ctx, req.Metadata)
+	}
+
+	return new(emptypb.Empty), s.n.Replicate(ctx, req.Data)
+}
+
+// Read implements RaftServer.
+func (s *Server) Read(ctx context.Context, req *ReadRequest) (*ReadResponse, error) {
+	if s.query == nil {
+		return nil, status.Error(codes.Unimplemented, "raft/raftapi: read queries not configured")
+	}
+
+	resp := new(ReadResponse)
+	err := s.n.LinearizableGet(ctx, func(sm raft.StateMachine) (err error) {
+		resp.Result, err = s.query(ctx, sm, req.Query)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Status implements RaftAdminServer.
+func (s *Server) Status(context.Context, *emptypb.Empty) (*StatusResponse, error) {
+	st, err := s.n.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatusResponse{
+		ID:        st.ID,
+		Role:      st.Role.String(),
+		Term:      st.Term,
+		Vote:      st.Vote,
+		Leader:    st.Leader,
+		Committed: st.Committed,
+		Applied:   st.Applied,
+		ClusterID: s.n.ClusterID(),
+	}, nil
+}
+
+// Members implements RaftAdminServer.
+func (s *Server) Members(context.Context, *emptypb.Empty) (*MembersResponse, error) {
+	mems := s.n.Members()
+	resp := &MembersResponse{
+		Members: make([]Member, 0, len(mems)),
+	}
+
+	for _, m := range mems {
+		resp.Members = append(resp.Members, Member{
+			ID:       m.ID(),
+			Address:  m.Address(),
+			Type:     MemberType(m.Type()),
+			Labels:   m.Labels(),
+			Active:   m.IsActive(),
+			Draining: m.Draining(),
+		})
+	}
+
+	return resp, nil
+}
+
+// AddMember implements RaftAdminServer, it returns the added member,
+// carrying the assigned id when the requested id is 0.
+func (s *Server) AddMember(ctx context.Context, req *Member) (*Member, error) {
+	if req.Type != VoterMember && req.Type != LearnerMember {
+		return nil, status.Errorf(codes.InvalidArgument, "raft/raftapi: member type %s not addable", req.Type)
+	}
+
+	raw := &raft.RawMember{
+		ID:      req.ID,
+		Address: req.Address,
+		Type:    raft.MemberType(req.Type),
+		Labels:  req.Labels,
+	}
+
+	if err := s.n.AddMember(ctx, raw); err != nil {
+		return nil, err
+	}
+
+	return &Member{
+		ID:      raw.ID,
+		Address: raw.Address,
+		Type:    req.Type,
+		Labels:  raw.Labels,
+	}, nil
+}
+
+// RemoveMember implements RaftAdminServer.
+func (s *Server) RemoveMember(ctx context.Context, req *MemberRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), s.n.RemoveMember(ctx, req.ID)
+}
+
+// PromoteMember implements RaftAdminServer.
+func (s *Server) PromoteMember(ctx context.Context, req *MemberRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), s.n.PromoteMember(ctx, req.ID)
+}
+
+// DemoteMember implements RaftAdminServer.
+func (s *Server) DemoteMember(ctx context.Context, req *MemberRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), s.n.DemoteMember(ctx, req.ID)
+}
+
+// TransferLeadership implements RaftAdminServer.
+func (s *Server) TransferLeadership(ctx context.Context, req *MemberRequest) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), s.n.TransferLeadership(ctx, req.ID)
+}
@@ -0,0 +1,158 @@
+package raftapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/shaj13/raft"
+	rafterrors "github.com/shaj13/raft/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestRaft(t *testing.T) {
+	ctx := context.Background()
+	fsm := new(testStateMachine)
+	node := testNode(t, fsm)
+
+	query := func(ctx context.Context, sm raft.StateMachine, q []byte) ([]byte, error) {
+		return append(q, sm.(*testStateMachine).String()...), nil
+	}
+
+	conn := testConn(t, node, WithQuery(query))
+	c := NewRaftClient(conn)
+
+	// it replicate the data along with its metadata.
+	_, err := c.Replicate(ctx, &ReplicateRequest{Data: []byte("a"), Metadata: map[string]string{"k": "v"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"k": "v"}, fsm.metadata())
+
+	// it evaluate the query against the state machine.
+	resp, err := c.Read(ctx, &ReadRequest{Query: []byte("q:")})
+	require.NoError(t, err)
+	require.Equal(t, "q:a", string(resp.Result))
+
+	// it return unimplemented without a query function.
+	_, err = NewRaftClient(testConn(t, node)).Read(ctx, &ReadRequest{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestRaftAdmin(t *testing.T) {
+	ctx := context.Background()
+	node := testNode(t, new(testStateMachine))
+	c := NewRaftAdminClient(testConn(t, node))
+
+	st, err := c.Status(ctx, new(emptypb.Empty))
+	require.NoError(t, err)
+	require.Equal(t, node.Whoami(), st.ID)
+	require.Equal(t, node.Whoami(), st.Leader)
+	require.Equal(t, "StateLeader", st.Role)
+	require.Equal(t, node.ClusterID(), st.ClusterID)
+	require.NotZero(t, st.Applied)
+
+	mems, err := c.Members(ctx, new(emptypb.Empty))
+	require.NoError(t, err)
+	require.Len(t, mems.Members, 1)
+	require.Equal(t, node.Whoami(), mems.Members[0].ID)
+
+	// it reject the members types not addable.
+	_, err = c.AddMember(ctx, &Member{Address: ":1", Type: StagingMember})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// it assign the added member id.
+	mem, err := c.AddMember(ctx, &Member{Address: ":1", Type: LearnerMember})
+	require.NoError(t, err)
+	require.NotZero(t, mem.ID)
+	require.Len(t, node.Members(), 2)
+
+	_, err = c.RemoveMember(ctx, &MemberRequest{ID: mem.ID})
+	require.NoError(t, err)
+
+	// it return the node errors as statuses carrying the error reason.
+	_, err = c.PromoteMember(ctx, &MemberRequest{ID: 100})
+	require.ErrorIs(t, rafterrors.FromError(err), raft.ErrMemberNotFound)
+}
+
+func testNode(t *testing.T, fsm raft.StateMachine) *raft.Node {
+	node, err := raft.NewSingleNode(fsm)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = node.Shutdown(context.Background())
+	})
+	return node
+}
+
+func testConn(t *testing.T, n Node, opts ...Option) *grpc.ClientConn {
+	ln := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	Register(srv, n, opts...)
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	dial := func(context.Context, string) (net.Conn, error) {
+		return ln.Dial()
+	}
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(dial))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+
+	return conn
+}
+
+type testStateMachine struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	md  map[string]string
+}
+
+func (sm *testStateMachine) Apply(data []byte) error {
+	return sm.ApplyWithMetadata(data, nil)
+}
+
+func (sm *testStateMachine) ApplyWithMetadata(data []byte, md map[string]string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.md = md
+	_, err := sm.buf.Write(data)
+	return err
+}
+
+func (sm *testStateMachine) Snapshot() (io.ReadCloser, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(sm.buf.Bytes())), nil
+}
+
+func (sm *testStateMachine) Restore(r io.ReadCloser) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.buf.Reset()
+	_, err := sm.buf.ReadFrom(r)
+	return err
+}
+
+func (sm *testStateMachine) String() string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.buf.String()
+}
+
+func (sm *testStateMachine) metadata() map[string]string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.md
+}
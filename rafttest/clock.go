@@ -0,0 +1,148 @@
+package rafttest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shaj13/raft"
+)
+
+var _ raft.Clock = &VirtualClock{}
+
+// epoch is the initial time of the virtual clocks.
+var epoch = time.Unix(0, 0).UTC()
+
+// NewVirtualClock returns a new virtual clock, set to the unix epoch.
+func NewVirtualClock() *VirtualClock {
+	return &VirtualClock{
+		now: epoch,
+	}
+}
+
+// VirtualClock is a raft.Clock that only moves forward when advanced explicitly,
+// so the nodes logical clocks, e.g. the elections and heartbeats timeouts,
+// are driven by the test rather than the wall clock.
+//
+// Unlike time.Ticker, a virtual ticker never drops a tick,
+// Advance blocks until every due tick is received, or the ticker stopped.
+type VirtualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	seq     uint64
+	tickers []*virtualTicker
+}
+
+// Now returns the current virtual time.
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a new virtual ticker, that delivers the ticks at the given interval.
+// It panics if d <= 0.
+func (c *VirtualClock) NewTicker(d time.Duration) raft.Ticker {
+	if d <= 0 {
+		panic("rafttest: non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	t := &virtualTicker{
+		clock:  c,
+		seq:    c.seq,
+		period: d,
+		next:   c.now.Add(d),
+		c:      make(chan time.Time),
+		done:   make(chan struct{}),
+	}
+
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the virtual time forward by the given duration,
+// and delivers the due ticks in the order of their time, then of the tickers creation.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		t := c.due(end)
+		if t == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+
+		now := t.next
+		c.now = now
+		t.next = t.next.Add(t.period)
+		c.mu.Unlock()
+
+		t.deliver(now)
+	}
+}
+
+// due returns the ticker of the earliest tick up to the given time, if any.
+// It must be called while holding the clock lock.
+func (c *VirtualClock) due(end time.Time) *virtualTicker {
+	sort.SliceStable(c.tickers, func(i, j int) bool {
+		if c.tickers[i].next.Equal(c.tickers[j].next) {
+			return c.tickers[i].seq < c.tickers[j].seq
+		}
+		return c.tickers[i].next.Before(c.tickers[j].next)
+	})
+
+	if len(c.tickers) == 0 || c.tickers[0].next.After(end) {
+		return nil
+	}
+
+	return c.tickers[0]
+}
+
+func (c *VirtualClock) remove(t *virtualTicker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, v := range c.tickers {
+		if v == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// virtualTicker implements raft.Ticker over a virtual clock.
+type virtualTicker struct {
+	clock  *VirtualClock
+	seq    uint64
+	period time.Duration
+	next   time.Time
+	c      chan time.Time
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (t *virtualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *virtualTicker) Stop() {
+	t.once.Do(func() {
+		close(t.done)
+		t.clock.remove(t)
+	})
+}
+
+func (t *virtualTicker) deliver(now time.Time) {
+	select {
+	case t.c <- now:
+	case <-t.done:
+	}
+}
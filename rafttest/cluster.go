@@ -0,0 +1,395 @@
+package rafttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shaj13/raft"
+	"github.com/shaj13/raft/transport"
+)
+
+// Option configures a simulated cluster using the functional options paradigm.
+type Option func(*Cluster)
+
+// WithSeed sets the seed of the network random source, e.g. the messages drops.
+//
+// Default Value: 1.
+func WithSeed(seed int64) Option {
+	return func(c *Cluster) {
+		c.seed = seed
+	}
+}
+
+// WithTickInterval sets the nodes tick interval, and the virtual time each Tick advances.
+//
+// Default Value: 100'ms.
+func WithTickInterval(d time.Duration) Option {
+	return func(c *Cluster) {
+		c.tickInterval = d
+	}
+}
+
+// WithSettle sets the wall time each Tick waits for the nodes to process
+// the delivered ticks and messages before returning.
+//
+// Default Value: 1'ms.
+func WithSettle(d time.Duration) Option {
+	return func(c *Cluster) {
+		c.settle = d
+	}
+}
+
+// WithNodeOptions appends the given options to the options of every node of the cluster.
+func WithNodeOptions(opts ...raft.Option) Option {
+	return func(c *Cluster) {
+		c.nodeOpts = append(c.nodeOpts, opts...)
+	}
+}
+
+// WithStateMachine sets the function that returns the state machine of the given member.
+//
+// Default Value: NewStateMachine.
+func WithStateMachine(fn func(id uint64) raft.StateMachine) Option {
+	return func(c *Cluster) {
+		c.newFSM = fn
+	}
+}
+
+// Member is a member of a simulated cluster.
+type Member struct {
+	// ID is the member id.
+	ID uint64
+	// Address is the member address in the simulated network.
+	Address string
+	// Node is the member raft node, replaced when the member restarted.
+	Node *raft.Node
+	// FSM is the member state machine, replaced when the member restarted.
+	FSM raft.StateMachine
+	// Faults is the member fault injector, preserved when the member restarted.
+	Faults *Faults
+
+	dir     string
+	running bool
+	done    chan struct{}
+}
+
+// NewCluster returns a new simulated cluster of the given size.
+// The members identified by the ids 1 to size, and the cluster must be started explicitly.
+func NewCluster(t testing.TB, size int, opts ...Option) *Cluster {
+	c := &Cluster{
+		t:            t,
+		seed:         1,
+		tickInterval: time.Millisecond * 100,
+		settle:       time.Millisecond,
+		newFSM: func(uint64) raft.StateMachine {
+			return NewStateMachine()
+		},
+		members: make(map[uint64]*Member),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.clock = NewVirtualClock()
+	c.net = NewNetwork(c.clock, c.seed)
+
+	for id := uint64(1); id <= uint64(size); id++ {
+		c.members[id] = &Member{
+			ID:      id,
+			Address: fmt.Sprintf("node-%d", id),
+			Faults:  NewFaults(c.seed + int64(id)),
+			dir:     t.TempDir(),
+		}
+	}
+
+	t.Cleanup(c.Shutdown)
+
+	return c
+}
+
+// Cluster is an in-memory raft cluster, running over a virtual clock and a simulated network,
+// so the tests drive the elections, heartbeats, partitions, and delays explicitly.
+type Cluster struct {
+	t            testing.TB
+	seed         int64
+	tickInterval time.Duration
+	settle       time.Duration
+	nodeOpts     []raft.Option
+	newFSM       func(id uint64) raft.StateMachine
+	clock        *VirtualClock
+	net          *Network
+	mu           sync.Mutex
+	members      map[uint64]*Member
+}
+
+// Clock returns the cluster virtual clock.
+func (c *Cluster) Clock() *VirtualClock {
+	return c.clock
+}
+
+// Network returns the cluster simulated network.
+func (c *Cluster) Network() *Network {
+	return c.net
+}
+
+// Member returns the member of the given id, or nil if it does not exist.
+func (c *Cluster) Member(id uint64) *Member {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.members[id]
+}
+
+// Members returns the cluster members, sorted by their ids.
+func (c *Cluster) Members() []*Member {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	membs := make([]*Member, 0, len(c.members))
+	for _, m := range c.members {
+		membs = append(membs, m)
+	}
+
+	sort.Slice(membs, func(i, j int) bool { return membs[i].ID < membs[j].ID })
+	return membs
+}
+
+// Start initialize the cluster and starts all its members.
+func (c *Cluster) Start() {
+	membs := c.Members()
+	raws := make([]raft.RawMember, 0, len(membs))
+	for _, m := range membs {
+		raws = append(raws, raft.RawMember{ID: m.ID, Address: m.Address})
+	}
+
+	// create all nodes first, so they are reachable once the first node starts.
+	for _, m := range membs {
+		c.create(m)
+	}
+
+	for _, m := range membs {
+		// the first member assigned to the current node.
+		list := []raft.RawMember{{ID: m.ID, Address: m.Address}}
+		for _, raw := range raws {
+			if raw.ID != m.ID {
+				list = append(list, raw)
+			}
+		}
+
+		c.start(m, raft.WithInitCluster(), raft.WithMembers(list...))
+	}
+}
+
+// Stop shutdowns the member of the given id, it's state dir preserved so it can be restarted.
+func (c *Cluster) Stop(id uint64) {
+	m := c.mustMember(id)
+
+	c.mu.Lock()
+	running := m.running
+	m.running = false
+	done := m.done
+	c.mu.Unlock()
+
+	if done == nil {
+		return
+	}
+
+	if running {
+		c.shutdown(m.ID, m.Node)
+	}
+
+	<-done
+}
+
+// IsRunning reports whether the member of the given id is running,
+// neither stopped nor crashed by an injected fault.
+func (c *Cluster) IsRunning(id uint64) bool {
+	return c.isRunning(c.mustMember(id))
+}
+
+// Restart restarts the member of the given id from its state dir, with a new state machine,
+// the member stopped first if still running.
+func (c *Cluster) Restart(id uint64) {
+	m := c.mustMember(id)
+	c.Stop(id)
+	c.create(m)
+	c.start(m, raft.WithAddress(m.Address), raft.WithRestart())
+}
+
+// Shutdown shutdowns all the cluster members.
+func (c *Cluster) Shutdown() {
+	wg := sync.WaitGroup{}
+	for _, m := range c.Members() {
+		wg.Add(1)
+		go func(id uint64) {
+			defer wg.Done()
+			c.Stop(id)
+		}(m.ID)
+	}
+
+	wg.Wait()
+}
+
+// Tick advances the virtual clock by a tick interval, delivers the due delayed messages,
+// then waits for the members to settle.
+func (c *Cluster) Tick() {
+	c.Advance(c.tickInterval)
+}
+
+// Advance advances the virtual clock by the given duration, delivers the due delayed messages,
+// then waits for the members to settle.
+func (c *Cluster) Advance(d time.Duration) {
+	c.clock.Advance(d)
+	c.net.Flush()
+	time.Sleep(c.settle)
+}
+
+// RunUntil ticks the cluster until the given condition met, or the given ticks exhausted.
+// It returns whether the condition met.
+func (c *Cluster) RunUntil(cond func() bool, maxTicks int) bool {
+	for i := 0; i < maxTicks; i++ {
+		if cond() {
+			return true
+		}
+		c.Tick()
+	}
+
+	return cond()
+}
+
+// Leader returns the leader agreed on by all the running members, or raft.None.
+func (c *Cluster) Leader() uint64 {
+	lead := raft.None
+	for _, m := range c.Members() {
+		if !c.isRunning(m) {
+			continue
+		}
+
+		l := m.Node.Leader()
+		if l == raft.None || (lead != raft.None && l != lead) {
+			return raft.None
+		}
+
+		lead = l
+	}
+
+	return lead
+}
+
+// WaitLeader ticks the cluster until all the running members agree on a leader, and returns it.
+// It fails the test if no leader elected within the given ticks.
+func (c *Cluster) WaitLeader(maxTicks int) uint64 {
+	c.t.Helper()
+
+	if !c.RunUntil(func() bool { return c.Leader() != raft.None }, maxTicks) {
+		c.t.Fatalf("rafttest: no leader elected within %d ticks", maxTicks)
+	}
+
+	return c.Leader()
+}
+
+// Replicate proposes the given data through the member of the given id,
+// and ticks the cluster until the proposal committed and applied, or the given ticks exhausted.
+func (c *Cluster) Replicate(id uint64, data []byte, maxTicks int) error {
+	m := c.mustMember(id)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- m.Node.Replicate(ctx, data)
+	}()
+
+	for i := 0; i <= maxTicks; i++ {
+		select {
+		case err := <-errc:
+			return err
+		default:
+		}
+
+		c.Tick()
+	}
+
+	cancel()
+	return fmt.Errorf("rafttest: replicate not completed within %d ticks: %w", maxTicks, <-errc)
+}
+
+func (c *Cluster) mustMember(id uint64) *Member {
+	m := c.Member(id)
+	if m == nil {
+		c.t.Fatalf("rafttest: unknown member %d", id)
+	}
+
+	return m
+}
+
+func (c *Cluster) isRunning(m *Member) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return m.running
+}
+
+func (c *Cluster) create(m *Member) {
+	ep := endpoint{
+		id:   m.ID,
+		addr: m.Address,
+		net:  c.net,
+	}
+
+	opts := []raft.Option{
+		raft.WithContext(contextWithEndpoint(context.Background(), ep)),
+		raft.WithStateDIR(m.dir),
+		raft.WithTickInterval(c.tickInterval),
+		raft.WithClock(c.clock),
+		raft.WithFaultInjector(m.Faults),
+	}
+
+	opts = append(opts, c.nodeOpts...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m.FSM = c.newFSM(m.ID)
+	m.Node = raft.NewNode(m.FSM, transport.SIM, opts...)
+}
+
+func (c *Cluster) start(m *Member, opts ...raft.StartOption) {
+	c.mu.Lock()
+	m.running = true
+	m.done = make(chan struct{})
+	node := m.Node
+	c.mu.Unlock()
+
+	go func(done chan struct{}) {
+		defer close(done)
+		err := node.Start(opts...)
+		if errors.Is(err, ErrInjectedFault) {
+			// crashed, shutdown the remaining node goroutines.
+			c.mu.Lock()
+			m.running = false
+			c.mu.Unlock()
+			c.shutdown(m.ID, node)
+			return
+		}
+
+		if err != nil && !errors.Is(err, raft.ErrNodeStopped) {
+			c.t.Errorf("rafttest: member %d start returned: %v", m.ID, err)
+		}
+	}(m.done)
+}
+
+func (c *Cluster) shutdown(id uint64, node *raft.Node) {
+	// force the shutdown, the virtual time does not move while waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := node.Shutdown(ctx); err != nil && !errors.Is(err, raft.ErrNodeStopped) {
+		c.t.Errorf("rafttest: member %d shutdown: %v", id, err)
+	}
+}
@@ -1,2 +1,30 @@
-// Package rafttest provides functional tests for raft implementation.
+// Package rafttest provides functional tests for raft implementation,
+// and a simulation harness to test raft based applications.
+//
+// The harness runs an in-memory cluster of raft nodes over a virtual clock
+// and an in-process simulated network, the test drives the time explicitly,
+// and programs the network partitions, delays, and drops.
+//
+//	c := rafttest.NewCluster(t, 3)
+//	c.Start()
+//	lead := c.WaitLeader(100)
+//	c.Network().Isolate(lead)
+//	c.RunUntil(func() bool { return c.Leader() != raft.None && c.Leader() != lead }, 100)
+//
+// The timers of the nodes, the delayed messages, and the network random source are deterministic,
+// the ticks delivered in the order of their time, then of the tickers creation,
+// and the delayed messages in the order of their due time, then of their sending.
+// However, the nodes goroutines scheduling, and the elections timeouts randomization
+// of the underlying raft library are not controlled by the harness,
+// therefore the tests must assert on the cluster outcomes rather than on exact interleavings.
+//
+// The History records the operations of concurrent clients, so the tests verify
+// the state machine linearizability under the induced failures, using CheckRegister
+// for a register state machine, or exporting the history to Porcupine or Jepsen Knossos.
+//
+//	h := rafttest.NewHistory()
+//	h.Record(client, rafttest.RegisterWrite, v, func() (interface{}, error) {
+//		return nil, node.Replicate(ctx, []byte(v))
+//	})
+//	err := rafttest.CheckRegister(h.Operations())
 package rafttest
@@ -0,0 +1,166 @@
+package rafttest
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft"
+)
+
+// ErrInjectedFault is returned by the node Start when crashed by an injected fault.
+var ErrInjectedFault = errors.New("rafttest: injected fault")
+
+var _ raft.FaultInjector = &Faults{}
+
+// NewFaults returns a new fault injector, that injects the faults rates
+// using a random source of the given seed.
+func NewFaults(seed int64) *Faults {
+	return &Faults{
+		rand:  rand.New(rand.NewSource(seed)), //nolint:gosec
+		peers: make(map[uint64]peerFaults),
+	}
+}
+
+// peerFaults holds the faults of the messages sent to a member.
+type peerFaults struct {
+	drop      float64
+	duplicate float64
+	delay     time.Duration
+}
+
+// Faults is a programmable raft.FaultInjector, that drops, duplicates, or delays the messages per member,
+// fails the storage syncs, and crashes the node before or after applying an entry.
+// The sync and apply faults injected once, so the node can be restarted.
+//
+//	faults := rafttest.NewFaults(1)
+//	faults.CrashAfterApply(10)
+//	node := raft.NewNode(fsm, proto, raft.WithFaultInjector(faults))
+type Faults struct {
+	mu          sync.Mutex
+	rand        *rand.Rand
+	peers       map[uint64]peerFaults
+	syncAt      uint64
+	beforeApply uint64
+	afterApply  uint64
+}
+
+// DropMessages drops the given fraction of the messages sent to the given member,
+// zero stops dropping, and one drops all the messages.
+func (f *Faults) DropMessages(to uint64, rate float64) {
+	f.update(to, func(p *peerFaults) { p.drop = rate })
+}
+
+// DuplicateMessages duplicates the given fraction of the messages sent to the given member,
+// zero stops duplicating.
+func (f *Faults) DuplicateMessages(to uint64, rate float64) {
+	f.update(to, func(p *peerFaults) { p.duplicate = rate })
+}
+
+// DelayMessages delays the messages sent to the given member by the given duration of the node clock,
+// zero stops delaying.
+func (f *Faults) DelayMessages(to uint64, d time.Duration) {
+	f.update(to, func(p *peerFaults) { p.delay = d })
+}
+
+// FailSync fails the first storage sync of the entries up to the given index or beyond.
+func (f *Faults) FailSync(index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.syncAt = index
+}
+
+// CrashBeforeApply crashes the node before applying the entry at the given index.
+func (f *Faults) CrashBeforeApply(index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.beforeApply = index
+}
+
+// CrashAfterApply crashes the node after applying the entry at the given index.
+func (f *Faults) CrashAfterApply(index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.afterApply = index
+}
+
+// Reset removes all the faults.
+func (f *Faults) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.peers = make(map[uint64]peerFaults)
+	f.syncAt = 0
+	f.beforeApply = 0
+	f.afterApply = 0
+}
+
+// MessageFault implements raft.FaultInjector.
+func (f *Faults) MessageFault(m etcdraftpb.Message) (raft.FaultAction, time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.peers[m.To]
+	if !ok {
+		return raft.FaultNone, 0
+	}
+
+	switch {
+	case p.drop > 0 && f.rand.Float64() < p.drop:
+		return raft.FaultDrop, 0
+	case p.duplicate > 0 && f.rand.Float64() < p.duplicate:
+		return raft.FaultDuplicate, 0
+	case p.delay > 0:
+		return raft.FaultDelay, p.delay
+	default:
+		return raft.FaultNone, 0
+	}
+}
+
+// SyncFault implements raft.FaultInjector.
+func (f *Faults) SyncFault(index uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.syncAt == 0 || index < f.syncAt {
+		return nil
+	}
+
+	f.syncAt = 0
+	return ErrInjectedFault
+}
+
+// ApplyFault implements raft.FaultInjector.
+func (f *Faults) ApplyFault(stage raft.ApplyStage, index uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	at := &f.beforeApply
+	if stage == raft.AfterApply {
+		at = &f.afterApply
+	}
+
+	if *at == 0 || index != *at {
+		return nil
+	}
+
+	*at = 0
+	return ErrInjectedFault
+}
+
+func (f *Faults) update(to uint64, fn func(*peerFaults)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := f.peers[to]
+	fn(&p)
+
+	if p == (peerFaults{}) {
+		delete(f.peers, to)
+		return
+	}
+
+	f.peers[to] = p
+}
@@ -0,0 +1,70 @@
+package rafttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/shaj13/raft"
+)
+
+var _ raft.StateMachine = &StateMachine{}
+
+// NewStateMachine returns a new empty state machine.
+func NewStateMachine() *StateMachine {
+	return &StateMachine{}
+}
+
+// StateMachine is a raft.StateMachine that records the applied entries in order,
+// so the tests compare the members histories.
+type StateMachine struct {
+	mu      sync.Mutex
+	entries [][]byte
+}
+
+// Apply records the given entry.
+func (s *StateMachine) Apply(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, append([]byte(nil), data...))
+	return nil
+}
+
+// Snapshot returns the recorded entries.
+func (s *StateMachine) Snapshot() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, err := json.Marshal(s.entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(buf)), nil
+}
+
+// Restore replace the recorded entries with the snapshot entries.
+func (s *StateMachine) Restore(r io.ReadCloser) error {
+	defer r.Close()
+
+	entries := [][]byte{}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = entries
+	return nil
+}
+
+// Entries returns a copy of the recorded entries.
+func (s *StateMachine) Entries() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([][]byte, len(s.entries))
+	copy(list, s.entries)
+	return list
+}
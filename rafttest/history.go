@@ -0,0 +1,281 @@
+package rafttest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Possible values for Status.
+const (
+	// Pending is the status of an invoked operation not completed yet.
+	Pending Status = iota
+	// Ok is the status of an operation known to take effect.
+	Ok
+	// Fail is the status of an operation known to never take effect.
+	Fail
+	// Info is the status of an operation that may or may not take effect, e.g. a timed out proposal.
+	Info
+)
+
+// Status is the completion status of a recorded operation.
+type Status int
+
+func (s Status) String() string {
+	switch s {
+	case Ok:
+		return "ok"
+	case Fail:
+		return "fail"
+	case Info:
+		return "info"
+	default:
+		return "invoke"
+	}
+}
+
+// Operation is an operation recorded by a history, from its invocation to its completion.
+type Operation struct {
+	// ClientID is the id of the client that invoked the operation.
+	ClientID int
+	// F is the operation function name, e.g. read or write.
+	F string
+	// Input is the operation input, e.g. the written value.
+	Input interface{}
+	// Output is the operation output, e.g. the read value, nil unless the operation status is Ok.
+	Output interface{}
+	// Call is the operation invocation time in nanoseconds since the history creation.
+	Call int64
+	// Return is the operation completion time in nanoseconds since the history creation,
+	// math.MaxInt64 unless the operation status is Ok or Fail.
+	Return int64
+	// Status is the operation completion status.
+	Status Status
+}
+
+// NewHistory returns a new empty history.
+func NewHistory() *History {
+	return &History{
+		start: time.Now(),
+	}
+}
+
+// History records the operations invoked by concurrent clients of a cluster,
+// so the tests verify the operations linearizability, e.g. using CheckRegister,
+// or an external checker such as Porcupine or Jepsen Knossos.
+//
+// The timestamps are taken from the monotonic wall clock rather than the virtual clock,
+// since the clients operations overlap within a single virtual tick.
+type History struct {
+	mu    sync.Mutex
+	start time.Time
+	last  int64
+	ops   []*Operation
+}
+
+// Invoke records the invocation of an operation and returns its handle,
+// the operation must be completed using one of the handle methods.
+func (h *History) Invoke(client int, f string, input interface{}) *Call {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op := &Operation{
+		ClientID: client,
+		F:        f,
+		Input:    input,
+		Call:     h.now(),
+		Return:   math.MaxInt64,
+	}
+
+	h.ops = append(h.ops, op)
+	return &Call{h: h, op: op}
+}
+
+// Record records the operation performed by the given function.
+// The operation status is Ok when the function succeeds, Otherwise Info,
+// since a failed proposal, e.g. a timed out proposal, may still be committed.
+func (h *History) Record(client int, f string, input interface{}, fn func() (interface{}, error)) (interface{}, error) {
+	call := h.Invoke(client, f, input)
+	out, err := fn()
+	if err != nil {
+		call.Info()
+		return nil, err
+	}
+
+	call.Ok(out)
+	return out, nil
+}
+
+// Operations returns a copy of the recorded operations, ordered by their invocation.
+func (h *History) Operations() []Operation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ops := make([]Operation, len(h.ops))
+	for i, op := range h.ops {
+		ops[i] = *op
+	}
+
+	return ops
+}
+
+// WritePorcupine writes the recorded operations as a JSON array of Porcupine operations,
+// that can be decoded into []porcupine.Operation. The failed operations omitted,
+// and the operation input encoded as {"f": F, "value": Input}.
+func (h *History) WritePorcupine(w io.Writer) error {
+	type input struct {
+		F     string      `json:"f"`
+		Value interface{} `json:"value"`
+	}
+
+	type operation struct {
+		ClientId int //nolint:revive,stylecheck
+		Input    input
+		Call     int64
+		Output   interface{}
+		Return   int64
+	}
+
+	ops := []operation{}
+	for _, op := range h.Operations() {
+		if op.Status == Fail {
+			continue
+		}
+
+		ops = append(ops, operation{
+			ClientId: op.ClientID,
+			Input:    input{F: op.F, Value: op.Input},
+			Call:     op.Call,
+			Output:   op.Output,
+			Return:   op.Return,
+		})
+	}
+
+	return json.NewEncoder(w).Encode(ops)
+}
+
+// WriteJepsen writes the recorded operations as a Jepsen history, an EDN map per line,
+// with an invoke event per operation, followed by its completion event unless pending.
+// The invoke event value is the operation input, and the completion event value is
+// the operation output when Ok, Otherwise the operation input.
+func (h *History) WriteJepsen(w io.Writer) error {
+	type event struct {
+		typ   Status
+		op    Operation
+		time  int64
+		value interface{}
+	}
+
+	ops := h.Operations()
+	events := make([]event, 0, len(ops)*2)
+	for _, op := range ops {
+		events = append(events, event{typ: Pending, op: op, time: op.Call, value: op.Input})
+		switch op.Status {
+		case Ok:
+			events = append(events, event{typ: Ok, op: op, time: op.Return, value: op.Output})
+		case Fail, Info:
+			events = append(events, event{typ: op.Status, op: op, time: op.Return, value: op.Input})
+		}
+	}
+
+	// the info events have no completion time, hence placed at the end of the history.
+	end := int64(0)
+	for _, ev := range events {
+		if ev.time != math.MaxInt64 && ev.time > end {
+			end = ev.time
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].time < events[j].time })
+
+	bw := bufio.NewWriter(w)
+	for i, ev := range events {
+		t := ev.time
+		if t == math.MaxInt64 {
+			t = end
+		}
+
+		fmt.Fprintf(
+			bw,
+			"{:index %d, :type :%s, :f :%s, :value %s, :process %d, :time %d}\n",
+			i,
+			ev.typ,
+			ev.op.F,
+			ednValue(ev.value),
+			ev.op.ClientID,
+			t,
+		)
+	}
+
+	return bw.Flush()
+}
+
+// now returns the current time in nanoseconds since the history creation,
+// strictly increasing so the recorded events are totally ordered.
+func (h *History) now() int64 {
+	t := time.Since(h.start).Nanoseconds()
+	if t <= h.last {
+		t = h.last + 1
+	}
+
+	h.last = t
+	return t
+}
+
+// Call is the handle of an invoked operation.
+type Call struct {
+	h  *History
+	op *Operation
+}
+
+// Ok completes the operation with the given output, the operation known to take effect.
+func (c *Call) Ok(output interface{}) {
+	c.complete(Ok, output)
+}
+
+// Fail completes the operation, the operation known to never take effect.
+func (c *Call) Fail() {
+	c.complete(Fail, nil)
+}
+
+// Info completes the operation, the operation may or may not take effect.
+func (c *Call) Info() {
+	c.complete(Info, nil)
+}
+
+func (c *Call) complete(st Status, output interface{}) {
+	c.h.mu.Lock()
+	defer c.h.mu.Unlock()
+
+	if c.op.Status != Pending {
+		return
+	}
+
+	c.op.Status = st
+	c.op.Output = output
+	if st != Info {
+		c.op.Return = c.h.now()
+	}
+}
+
+// ednValue returns the EDN representation of the given value.
+func ednValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return strconv.Quote(string(v))
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,164 @@
+package rafttest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	raft "github.com/shaj13/raft"
+	"github.com/shaj13/raft/rafttest"
+)
+
+func TestCheckRegister(t *testing.T) {
+	op := func(f string, in, out interface{}, call, ret int64, st rafttest.Status) rafttest.Operation {
+		return rafttest.Operation{F: f, Input: in, Output: out, Call: call, Return: ret, Status: st}
+	}
+
+	table := []struct {
+		name string
+		ops  []rafttest.Operation
+		ok   bool
+	}{
+		{
+			name: "it linearize concurrent write and read",
+			ops: []rafttest.Operation{
+				op(rafttest.RegisterWrite, "a", nil, 1, 4, rafttest.Ok),
+				op(rafttest.RegisterRead, nil, "a", 2, 3, rafttest.Ok),
+			},
+			ok: true,
+		},
+		{
+			name: "it reject a stale read",
+			ops: []rafttest.Operation{
+				op(rafttest.RegisterWrite, "a", nil, 1, 2, rafttest.Ok),
+				op(rafttest.RegisterWrite, "b", nil, 3, 4, rafttest.Ok),
+				op(rafttest.RegisterRead, nil, "a", 5, 6, rafttest.Ok),
+			},
+		},
+		{
+			name: "it allow an indeterminate write to take effect",
+			ops: []rafttest.Operation{
+				op(rafttest.RegisterWrite, "a", nil, 1, 1<<62, rafttest.Info),
+				op(rafttest.RegisterRead, nil, "a", 5, 6, rafttest.Ok),
+				op(rafttest.RegisterRead, nil, "a", 7, 8, rafttest.Ok),
+			},
+			ok: true,
+		},
+		{
+			name: "it ignore failed writes",
+			ops: []rafttest.Operation{
+				op(rafttest.RegisterWrite, "a", nil, 1, 2, rafttest.Fail),
+				op(rafttest.RegisterRead, nil, "a", 5, 6, rafttest.Ok),
+			},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rafttest.CheckRegister(tt.ops)
+			require.Equal(t, tt.ok, err == nil, err)
+		})
+	}
+}
+
+func TestHistoryExport(t *testing.T) {
+	h := rafttest.NewHistory()
+	h.Invoke(1, rafttest.RegisterWrite, "a").Ok(nil)
+	h.Invoke(2, rafttest.RegisterRead, nil).Ok("a")
+	h.Invoke(3, rafttest.RegisterWrite, "b").Info()
+
+	ops := h.Operations()
+	require.Len(t, ops, 3)
+	require.Less(t, ops[0].Call, ops[0].Return)
+	require.Less(t, ops[0].Return, ops[1].Call)
+	require.NoError(t, rafttest.CheckRegister(ops))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, h.WritePorcupine(buf))
+
+	porcupine := []struct {
+		ClientId int //nolint:revive,stylecheck
+		Input    struct {
+			F     string
+			Value interface{}
+		}
+		Output interface{}
+		Call   int64
+		Return int64
+	}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &porcupine))
+	require.Len(t, porcupine, 3)
+	require.Equal(t, 2, porcupine[1].ClientId)
+	require.Equal(t, "read", porcupine[1].Input.F)
+	require.Equal(t, "a", porcupine[1].Output)
+
+	buf.Reset()
+	require.NoError(t, h.WriteJepsen(buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 6)
+	require.True(t, strings.HasPrefix(lines[0], `{:index 0, :type :invoke, :f :write, :value "a", :process 1, :time `))
+	require.True(t, strings.HasPrefix(lines[3], `{:index 3, :type :ok, :f :read, :value "a", :process 2, :time `))
+	require.True(t, strings.HasPrefix(lines[5], `{:index 5, :type :info, :f :write, :value "b", :process 3, :time `))
+}
+
+func TestLinearizableRegister(t *testing.T) {
+	c := rafttest.NewCluster(t, 3, rafttest.WithStateMachine(func(uint64) raft.StateMachine {
+		return rafttest.NewRegisterStateMachine()
+	}))
+	c.Start()
+
+	lead := c.WaitLeader(200)
+	h := rafttest.NewHistory()
+
+	// the clients run concurrently through every member, while the test drives the cluster.
+	var (
+		wg      sync.WaitGroup
+		pending int32 = 3
+	)
+
+	for client := 1; client <= 3; client++ {
+		wg.Add(1)
+		go func(client int) {
+			defer wg.Done()
+			defer atomic.AddInt32(&pending, -1)
+
+			m := c.Member(uint64(client))
+			for i := 0; i < 5; i++ {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+				if i%2 == 0 {
+					v := fmt.Sprintf("%d-%d", client, i)
+					_, _ = h.Record(client, rafttest.RegisterWrite, v, func() (interface{}, error) {
+						return nil, m.Node.Replicate(ctx, []byte(v))
+					})
+				} else {
+					_, _ = h.Record(client, rafttest.RegisterRead, nil, func() (interface{}, error) {
+						if err := m.Node.LinearizableRead(ctx); err != nil {
+							return nil, err
+						}
+						return m.FSM.(*rafttest.RegisterStateMachine).Value(), nil
+					})
+				}
+				cancel()
+			}
+		}(client)
+	}
+
+	// isolate the leader midway, its clients operations become indeterminate.
+	c.RunUntil(func() bool { return len(h.Operations()) >= 6 }, 100)
+	c.Network().Isolate(lead)
+
+	ok := c.RunUntil(func() bool { return atomic.LoadInt32(&pending) == 0 }, 1000)
+	wg.Wait()
+	require.True(t, ok)
+
+	require.NoError(t, rafttest.CheckRegister(h.Operations()))
+}
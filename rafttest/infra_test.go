@@ -109,31 +109,42 @@ func (l *loopbackClient) Message(ctx context.Context, msg etcdraftpb.Message) er
 	if msg.Type == etcdraftpb.MsgSnap {
 		gid := l.to.GroupID()
 		meta := msg.Snapshot.Metadata
-		r, err := l.from.Controller().SnapshotReader(gid, meta.Term, meta.Index)
+		chain, err := l.from.Controller().SnapshotChain(gid, meta.Term, meta.Index)
 		if err != nil {
 			return err
 		}
 
-		w, err := l.to.Controller().SnapshotWriter(gid, meta.Term, meta.Index)
-		if err != nil {
-			return err
+		for _, m := range chain {
+			if err := l.snapshot(gid, m); err != nil {
+				return err
+			}
 		}
+	}
 
-		_, err = io.Copy(w, r)
-		if err != nil {
-			return err
-		}
+	return l.to.Controller().Push(ctx, l.to.GroupID(), msg)
+}
 
-		if err := r.Close(); err != nil {
-			return err
-		}
+func (l *loopbackClient) snapshot(gid uint64, meta etcdraftpb.SnapshotMetadata) error {
+	r, err := l.from.Controller().SnapshotReader(gid, meta.Term, meta.Index, 0, true)
+	if err != nil {
+		return err
+	}
 
-		if err := w.Close(); err != nil {
-			return err
-		}
+	w, err := l.to.Controller().SnapshotWriter(gid, meta.Term, meta.Index, 0)
+	if err != nil {
+		return err
 	}
 
-	return l.to.Controller().Push(ctx, l.to.GroupID(), msg)
+	_, err = io.Copy(w, r)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Close(); err != nil {
+		return err
+	}
+
+	return w.Commit()
 }
 
 func (l *loopbackClient) Join(ctx context.Context, mem raftpb.Member) (*raftpb.JoinResponse, error) {
@@ -322,7 +333,7 @@ func (o *orchestrator) follower() *node {
 		}
 
 		fid := n.raftnode.Whoami()
-		mem, _ := n.raftnode.GetMemebr(fid)
+		mem, _ := n.raftnode.Member(fid)
 		if fid != id && mem.Type() == raft.VoterMember {
 			return n
 		}
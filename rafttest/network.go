@@ -0,0 +1,344 @@
+package rafttest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	etcdraftpb "go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/shaj13/raft/internal/raftpb"
+	"github.com/shaj13/raft/internal/transport"
+)
+
+func init() {
+	transport.SIM.Register(newHandler, dialer)
+}
+
+type networkKey struct{}
+
+// endpoint is the network endpoint of a member.
+type endpoint struct {
+	id   uint64
+	addr string
+	net  *Network
+}
+
+// contextWithEndpoint returns a copy of the given context that carries the given endpoint,
+// the context passed to the node, See raft.WithContext.
+func contextWithEndpoint(ctx context.Context, ep endpoint) context.Context {
+	return context.WithValue(ctx, networkKey{}, ep)
+}
+
+// endpointConfig is the transport config of a simulated node.
+type endpointConfig interface {
+	Context() context.Context
+	transport.Config
+}
+
+func newHandler(transport.Config) transport.Handler {
+	return nil
+}
+
+func dialer(cfg transport.Config) transport.Dial {
+	ec, ok := cfg.(endpointConfig)
+	if !ok {
+		panic("rafttest: transport config does not carry the node context")
+	}
+
+	ep, ok := ec.Context().Value(networkKey{}).(endpoint)
+	if !ok {
+		panic("rafttest: node not created by the simulated cluster")
+	}
+
+	ep.net.attach(ep, ec)
+
+	return func(_ context.Context, addr string) (transport.Client, error) {
+		return &client{net: ep.net, from: ep.addr, to: addr}, nil
+	}
+}
+
+// link is a directed link between two members.
+type link struct {
+	from, to uint64
+}
+
+// envelope is a message in flight.
+type envelope struct {
+	seq uint64
+	at  time.Time
+	to  string
+	msg etcdraftpb.Message
+}
+
+// NewNetwork returns a new in-process simulated network, that delivers the delayed messages
+// by the given virtual clock, and drops messages using a random source of the given seed.
+func NewNetwork(clock *VirtualClock, seed int64) *Network {
+	return &Network{
+		clock:  clock,
+		rand:   rand.New(rand.NewSource(seed)), //nolint:gosec
+		nodes:  make(map[string]endpointConfig),
+		ids:    make(map[string]uint64),
+		groups: make(map[uint64]int),
+		delays: make(map[link]time.Duration),
+		drops:  make(map[link]float64),
+	}
+}
+
+// Network is an in-process simulated network between the members of a cluster,
+// with programmable partitions, delays, and drops.
+type Network struct {
+	mu       sync.Mutex
+	clock    *VirtualClock
+	rand     *rand.Rand
+	nodes    map[string]endpointConfig
+	ids      map[string]uint64
+	groups   map[uint64]int
+	delays   map[link]time.Duration
+	drops    map[link]float64
+	inflight []envelope
+	seq      uint64
+}
+
+func (n *Network) attach(ep endpoint, cfg endpointConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nodes[ep.addr] = cfg
+	n.ids[ep.addr] = ep.id
+}
+
+// Partition splits the network into the given groups of members ids,
+// the members of different groups can't reach each other.
+// The members not listed in any group form their own group.
+func (n *Network) Partition(groups ...[]uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.groups = make(map[uint64]int)
+	for i, g := range groups {
+		for _, id := range g {
+			n.groups[id] = i + 1
+		}
+	}
+}
+
+// Isolate partitions the given member from the rest of the cluster.
+func (n *Network) Isolate(id uint64) {
+	n.Partition([]uint64{id}, n.others(id))
+}
+
+// Heal removes the partitions.
+func (n *Network) Heal() {
+	n.Partition()
+}
+
+// Delay delays the messages sent from a member to another by the given duration of the virtual clock,
+// zero removes the delay.
+func (n *Network) Delay(from, to uint64, d time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if d <= 0 {
+		delete(n.delays, link{from, to})
+		return
+	}
+
+	n.delays[link{from, to}] = d
+}
+
+// Drop drops the given fraction of the messages sent from a member to another,
+// zero stops dropping, and one drops all the messages.
+func (n *Network) Drop(from, to uint64, rate float64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if rate <= 0 {
+		delete(n.drops, link{from, to})
+		return
+	}
+
+	n.drops[link{from, to}] = rate
+}
+
+// Flush delivers the in-flight messages due by the virtual clock,
+// in the order of their due time, then of their sending.
+func (n *Network) Flush() {
+	now := n.clock.Now()
+
+	n.mu.Lock()
+	sort.SliceStable(n.inflight, func(i, j int) bool {
+		if n.inflight[i].at.Equal(n.inflight[j].at) {
+			return n.inflight[i].seq < n.inflight[j].seq
+		}
+		return n.inflight[i].at.Before(n.inflight[j].at)
+	})
+
+	i := sort.Search(len(n.inflight), func(i int) bool {
+		return n.inflight[i].at.After(now)
+	})
+
+	due := n.inflight[:i:i]
+	n.inflight = append([]envelope(nil), n.inflight[i:]...)
+	n.mu.Unlock()
+
+	for _, env := range due {
+		// the message dropped if the member no longer reachable.
+		_ = n.push(context.Background(), env.to, env.msg)
+	}
+}
+
+func (n *Network) others(id uint64) []uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ids := []uint64{}
+	for _, v := range n.ids {
+		if v != id {
+			ids = append(ids, v)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// route returns the destination config and the link delay,
+// or an error if the destination unreachable or the message dropped.
+func (n *Network) route(from, to string) (endpointConfig, time.Duration, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	cfg, ok := n.nodes[to]
+	if !ok {
+		return nil, 0, fmt.Errorf("rafttest: unknown address %s", to)
+	}
+
+	l := link{n.ids[from], n.ids[to]}
+	if n.groups[l.from] != n.groups[l.to] {
+		return nil, 0, fmt.Errorf("rafttest: member %x partitioned from member %x", l.to, l.from)
+	}
+
+	if rate, ok := n.drops[l]; ok && n.rand.Float64() < rate {
+		return nil, 0, fmt.Errorf("rafttest: message from member %x to member %x dropped", l.from, l.to)
+	}
+
+	return cfg, n.delays[l], nil
+}
+
+func (n *Network) send(ctx context.Context, from, to string, msg etcdraftpb.Message) error {
+	_, d, err := n.route(from, to)
+	if err != nil {
+		return err
+	}
+
+	if msg.Type == etcdraftpb.MsgSnap {
+		if err := n.snapshot(from, to, msg); err != nil {
+			return err
+		}
+	}
+
+	if d == 0 {
+		return n.push(ctx, to, msg)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.seq++
+	n.inflight = append(n.inflight, envelope{
+		seq: n.seq,
+		at:  n.clock.Now().Add(d),
+		to:  to,
+		msg: msg,
+	})
+
+	return nil
+}
+
+func (n *Network) push(ctx context.Context, to string, msg etcdraftpb.Message) error {
+	n.mu.Lock()
+	cfg, ok := n.nodes[to]
+	n.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("rafttest: unknown address %s", to)
+	}
+
+	return cfg.Controller().Push(ctx, cfg.GroupID(), msg)
+}
+
+// snapshot copies the snapshot files chain of the given message from a member to another.
+func (n *Network) snapshot(from, to string, msg etcdraftpb.Message) error {
+	n.mu.Lock()
+	src, dst := n.nodes[from], n.nodes[to]
+	n.mu.Unlock()
+
+	meta := msg.Snapshot.Metadata
+	chain, err := src.Controller().SnapshotChain(src.GroupID(), meta.Term, meta.Index)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range chain {
+		r, err := src.Controller().SnapshotReader(src.GroupID(), m.Term, m.Index, 0, true)
+		if err != nil {
+			return err
+		}
+
+		w, err := dst.Controller().SnapshotWriter(dst.GroupID(), m.Term, m.Index, 0)
+		if err != nil {
+			r.Close()
+			return err
+		}
+
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err != nil {
+			w.Close()
+			return err
+		}
+
+		if err := w.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// client implements transport.Client over the simulated network.
+type client struct {
+	net  *Network
+	from string
+	to   string
+}
+
+func (c *client) Message(ctx context.Context, msg etcdraftpb.Message) error {
+	return c.net.send(ctx, c.from, c.to, msg)
+}
+
+func (c *client) Join(ctx context.Context, m raftpb.Member) (*raftpb.JoinResponse, error) {
+	cfg, _, err := c.net.route(c.from, c.to)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Controller().Join(ctx, cfg.GroupID(), &m)
+}
+
+func (c *client) PromoteMember(ctx context.Context, m raftpb.Member) error {
+	cfg, _, err := c.net.route(c.from, c.to)
+	if err != nil {
+		return err
+	}
+
+	return cfg.Controller().PromoteMember(ctx, cfg.GroupID(), m)
+}
+
+func (c *client) Close() error {
+	return nil
+}
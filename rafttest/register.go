@@ -0,0 +1,161 @@
+package rafttest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/shaj13/raft"
+)
+
+// Possible values for the register operations function name.
+const (
+	// RegisterRead is the function name of a register read, its output is the read value.
+	RegisterRead = "read"
+	// RegisterWrite is the function name of a register write, its input is the written value.
+	RegisterWrite = "write"
+)
+
+var _ raft.StateMachine = &RegisterStateMachine{}
+
+// NewRegisterStateMachine returns a new register state machine, holding an empty value.
+func NewRegisterStateMachine() *RegisterStateMachine {
+	return &RegisterStateMachine{}
+}
+
+// RegisterStateMachine is a raft.StateMachine of a single register,
+// every applied entry overwrites the register value.
+type RegisterStateMachine struct {
+	mu    sync.Mutex
+	value string
+}
+
+// Apply writes the given data into the register.
+func (r *RegisterStateMachine) Apply(data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.value = string(data)
+	return nil
+}
+
+// Snapshot returns the register value.
+func (r *RegisterStateMachine) Snapshot() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(r.Value())), nil
+}
+
+// Restore replace the register value with the snapshot value.
+func (r *RegisterStateMachine) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	buf, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.value = string(buf)
+	return nil
+}
+
+// Value returns the register value.
+func (r *RegisterStateMachine) Value() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.value
+}
+
+// CheckRegister verifies the given register operations are linearizable,
+// i.e. every operation takes effect atomically at some point between its invocation and its completion.
+// The register initially holds an empty value, the write inputs and the read outputs must be strings.
+// The failed operations ignored, and the Info and Pending operations may or may not take effect.
+//
+// It performs an exhaustive search of the operations orders, pruned by the real time order,
+// therefore it suits the short histories recorded by the tests.
+func CheckRegister(ops []Operation) error {
+	list := make([]Operation, 0, len(ops))
+	for _, op := range ops {
+		if op.Status == Fail {
+			continue
+		}
+
+		if op.F != RegisterRead && op.F != RegisterWrite {
+			return fmt.Errorf("rafttest: unknown register operation %q", op.F)
+		}
+
+		list = append(list, op)
+	}
+
+	c := &registerChecker{
+		ops:     list,
+		visited: make(map[string]struct{}),
+	}
+
+	if !c.search(new(big.Int), "") {
+		return fmt.Errorf("rafttest: register history of %d operations is not linearizable", len(list))
+	}
+
+	return nil
+}
+
+// registerChecker searches for a linearization of the register operations.
+type registerChecker struct {
+	ops     []Operation
+	visited map[string]struct{}
+}
+
+// search reports whether the operations not within done can be linearized
+// from the given register value.
+func (c *registerChecker) search(done *big.Int, value string) bool {
+	// the next linearized operation must be invoked before
+	// the completion of every operation not linearized yet.
+	deadline := int64(math.MaxInt64)
+	remaining := 0
+	for i, op := range c.ops {
+		if done.Bit(i) == 1 {
+			continue
+		}
+
+		remaining++
+		if op.Return < deadline {
+			deadline = op.Return
+		}
+	}
+
+	if remaining == 0 {
+		return true
+	}
+
+	key := done.String() + "/" + value
+	if _, ok := c.visited[key]; ok {
+		return false
+	}
+
+	c.visited[key] = struct{}{}
+
+	for i, op := range c.ops {
+		if done.Bit(i) == 1 || op.Call > deadline {
+			continue
+		}
+
+		next := value
+		switch op.F {
+		case RegisterWrite:
+			next = fmt.Sprint(op.Input)
+		case RegisterRead:
+			// the output of an uncompleted read is unknown.
+			if op.Status == Ok && fmt.Sprint(op.Output) != value {
+				continue
+			}
+		}
+
+		if c.search(new(big.Int).SetBit(done, i, 1), next) {
+			return true
+		}
+	}
+
+	return false
+}
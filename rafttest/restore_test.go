@@ -42,7 +42,7 @@ func TestSnapshotShare(t *testing.T) {
 
 	// verify node 1 snapshot copied to node 2.
 	cfg := otr.loopback.get(raw.Address)
-	_, err := cfg.Controller().SnapshotReader(0, 2, 9)
+	_, err := cfg.Controller().SnapshotReader(0, 2, 9, 0, false)
 	require.NoError(t, err)
 }
 
@@ -104,7 +104,7 @@ func testRestore(t *testing.T, cb func(), opt raft.StartOption, interval uint64,
 	require.NoError(t, err)
 
 	for i := 0; i < 5; i++ {
-		mem, _ := leader.raftnode.GetMemebr(followerID)
+		mem, _ := leader.raftnode.Member(followerID)
 		if !mem.IsActive() {
 			break
 		}
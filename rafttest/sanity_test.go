@@ -125,7 +125,7 @@ func TestDisableForwarding(t *testing.T) {
 	ctx := context.Background()
 
 	err := otr.follower().raftnode.Replicate(ctx, []byte{})
-	require.Equal(t, raft.ErrNotLeader, err)
+	require.ErrorIs(t, err, raft.ErrNotLeader)
 
 	err = otr.leader().raftnode.Replicate(ctx, newBytesEntry(1, 1))
 	require.NoError(t, err)
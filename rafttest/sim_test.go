@@ -0,0 +1,144 @@
+package rafttest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	raft "github.com/shaj13/raft"
+	"github.com/shaj13/raft/rafttest"
+)
+
+func TestVirtualClock(t *testing.T) {
+	clock := rafttest.NewVirtualClock()
+	start := clock.Now()
+
+	fast := clock.NewTicker(time.Second)
+	slow := clock.NewTicker(time.Second * 2)
+	defer slow.Stop()
+
+	got := make(chan string, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			select {
+			case <-fast.C():
+				got <- "fast"
+			case <-slow.C():
+				got <- "slow"
+			}
+		}
+	}()
+
+	clock.Advance(time.Second * 2)
+	<-done
+	close(got)
+
+	order := []string{}
+	for v := range got {
+		order = append(order, v)
+	}
+
+	require.Equal(t, []string{"fast", "fast", "slow"}, order)
+	require.Equal(t, start.Add(time.Second*2), clock.Now())
+
+	// stopped tickers don't block advancing.
+	fast.Stop()
+	slow.Stop()
+	clock.Advance(time.Second * 10)
+	require.Equal(t, start.Add(time.Second*12), clock.Now())
+}
+
+func TestSimulatedCluster(t *testing.T) {
+	c := rafttest.NewCluster(t, 3)
+	c.Start()
+
+	lead := c.WaitLeader(200)
+	require.NoError(t, c.Replicate(lead, []byte("a"), 100))
+
+	// isolate the leader, the majority elects a new leader.
+	c.Network().Isolate(lead)
+	ok := c.RunUntil(func() bool {
+		for _, m := range c.Members() {
+			if m.ID != lead && m.Node.Leader() != raft.None && m.Node.Leader() != lead {
+				return true
+			}
+		}
+		return false
+	}, 200)
+	require.True(t, ok)
+
+	// delay the messages, the proposals committed once the messages delivered.
+	c.Network().Heal()
+	lead = c.WaitLeader(200)
+	for _, m := range c.Members() {
+		c.Network().Delay(lead, m.ID, time.Millisecond*300)
+	}
+
+	require.NoError(t, c.Replicate(lead, []byte("b"), 100))
+
+	ok = c.RunUntil(func() bool {
+		for _, m := range c.Members() {
+			if len(m.FSM.(*rafttest.StateMachine).Entries()) != 2 {
+				return false
+			}
+		}
+		return true
+	}, 100)
+	require.True(t, ok)
+
+	for _, m := range c.Members() {
+		require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, m.FSM.(*rafttest.StateMachine).Entries())
+	}
+
+	// restarted members catch up.
+	c.Restart(lead)
+	c.WaitLeader(200)
+	ok = c.RunUntil(func() bool {
+		return len(c.Member(lead).FSM.(*rafttest.StateMachine).Entries()) == 2
+	}, 100)
+	require.True(t, ok)
+}
+
+func TestSimulatedFaults(t *testing.T) {
+	c := rafttest.NewCluster(t, 3)
+	c.Start()
+
+	lead := c.WaitLeader(200)
+	follower := lead%3 + 1
+	entries := func(id uint64) int {
+		return len(c.Member(id).FSM.(*rafttest.StateMachine).Entries())
+	}
+
+	// crash the follower after applying the next entry.
+	st, err := c.Member(follower).Node.Status()
+	require.NoError(t, err)
+	c.Member(follower).Faults.CrashAfterApply(st.Applied + 1)
+
+	require.NoError(t, c.Replicate(lead, []byte("a"), 100))
+	require.True(t, c.RunUntil(func() bool { return !c.IsRunning(follower) }, 100))
+
+	// the majority commits the proposals while the follower down.
+	require.NoError(t, c.Replicate(lead, []byte("b"), 100))
+
+	// the restarted follower replays its WAL and catches up.
+	c.Restart(follower)
+	require.True(t, c.RunUntil(func() bool { return entries(follower) == 2 }, 200))
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, c.Member(follower).FSM.(*rafttest.StateMachine).Entries())
+
+	// the dropped messages never delivered, the leader retries on the next heartbeats.
+	c.Member(lead).Faults.DropMessages(follower, 1)
+	require.NoError(t, c.Replicate(lead, []byte("c"), 100))
+	c.Tick()
+	require.Equal(t, 2, entries(follower))
+
+	c.Member(lead).Faults.Reset()
+	require.True(t, c.RunUntil(func() bool { return entries(follower) == 3 }, 200))
+
+	// the failed sync crashes the follower.
+	c.Member(follower).Faults.FailSync(1)
+	require.NoError(t, c.Replicate(lead, []byte("d"), 100))
+	require.True(t, c.RunUntil(func() bool { return !c.IsRunning(follower) }, 100))
+}
@@ -0,0 +1,95 @@
+package raft
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/shaj13/raft/internal/transport"
+	etransport "github.com/shaj13/raft/transport"
+)
+
+// NewRuntime returns a new Runtime that shares the given options,
+// such as the logger, the metrics, and the TLS config, between its nodes.
+func NewRuntime(proto etransport.Proto, opts ...Option) *Runtime {
+	cfg := newConfig(opts...)
+	nh, _ := transport.Proto(proto).Get()
+
+	router := &router{
+		ctrls: make(map[uint64]transport.Controller),
+	}
+	cfg.controller = router
+
+	return &Runtime{
+		proto:   proto,
+		opts:    opts,
+		handler: nh(cfg),
+		router:  router,
+	}
+}
+
+// Runtime shares the process resources between multiple independent raft nodes,
+// each node associated to its own group id, so multi-tenant control planes
+// can run many raft clusters in one process.
+//
+// Unlike NodeGroup, the runtime nodes run their own raft loops,
+// and may have different member ids and configurations.
+// They share a single transport handler, registered once with the transport server,
+// and the runtime options. The runtime nodes state directories namespaced by their group ids,
+// e.g. <state dir>/<group id>, unless overridden by the node options.
+type Runtime struct {
+	proto   etransport.Proto
+	opts    []Option
+	handler transport.Handler
+	router  *router
+}
+
+// Handler return runtime transportation handler,
+// that delegated to respond to RPC requests over the wire for all the runtime nodes.
+// the returned handler must be registered with the transportation server.
+func (rt *Runtime) Handler() etransport.Handler {
+	return rt.handler
+}
+
+// NewNode construct and returns a new node that associated to the given group id,
+// the node configured by the runtime options, then the given options.
+//
+// Each group id must have its own node object.
+// The returned node is in a stopped state, therefore it must be start explicitly.
+func (rt *Runtime) NewNode(groupID uint64, fsm StateMachine, opts ...Option) *Node {
+	nopts := make([]Option, 0, len(rt.opts)+len(opts)+1)
+	nopts = append(nopts, rt.opts...)
+	nopts = append(nopts, groupDIR(groupID))
+	nopts = append(nopts, opts...)
+
+	n := NewNode(fsm, rt.proto, nopts...)
+	n.cfg.groupID = groupID
+	n.handler = rt.handler
+	rt.router.add(groupID, n.cfg.controller)
+	return n
+}
+
+// Remove remove node related to the given group id.
+// after the removal, the actual node no longer receives requests,
+// it must coordinate with node shutdown explicitly.
+//
+//	rt.Remove(12)
+//	node.Shutdown(ctx)
+func (rt *Runtime) Remove(groupID uint64) {
+	rt.router.remove(groupID)
+}
+
+// groupDIR namespaces the state directories by the given group id.
+func groupDIR(groupID uint64) Option {
+	return optionFunc(func(c *config) {
+		gid := strconv.FormatUint(groupID, 10)
+		c.statedir = filepath.Join(c.statedir, gid)
+
+		if len(c.waldir) > 0 {
+			c.waldir = filepath.Join(c.waldir, gid)
+		}
+
+		if len(c.snapdir) > 0 {
+			c.snapdir = filepath.Join(c.snapdir, gid)
+		}
+	})
+}
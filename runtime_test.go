@@ -0,0 +1,40 @@
+package raft
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	etransport "github.com/shaj13/raft/transport"
+	_ "github.com/shaj13/raft/transport/rafthttp"
+)
+
+func TestRuntimeNewNode(t *testing.T) {
+	dir := t.TempDir()
+	rt := NewRuntime(etransport.HTTP, WithStateDIR(dir), WithWALDIR(filepath.Join(dir, "wal")))
+
+	n1 := rt.NewNode(1, new(restoreFSM))
+	n2 := rt.NewNode(2, new(restoreFSM), WithStateDIR(filepath.Join(dir, "custom")))
+
+	// it share the runtime handler.
+	require.Equal(t, rt.Handler(), n1.Handler())
+	require.Equal(t, rt.Handler(), n2.Handler())
+
+	// it associate the nodes to their group ids.
+	require.Equal(t, uint64(1), n1.cfg.GroupID())
+	require.Equal(t, uint64(2), n2.cfg.GroupID())
+	_, err := rt.router.get(1)
+	require.NoError(t, err)
+
+	// it namespace the nodes state directories by their group ids.
+	require.Equal(t, filepath.Join(dir, "1"), n1.cfg.StateDir())
+	require.Equal(t, filepath.Join(dir, "wal", "1"), n1.cfg.WALDir())
+	require.Equal(t, filepath.Join(dir, "1", "snap"), n1.cfg.SnapDir())
+	require.Equal(t, filepath.Join(dir, "custom"), n2.cfg.StateDir())
+
+	// it remove the node from the runtime.
+	rt.Remove(1)
+	_, err = rt.router.get(1)
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package raft
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	etransport "github.com/shaj13/raft/transport"
+	"github.com/shaj13/raft/transport/raftlocal"
+)
+
+// singleNodes is the number of the created single nodes, used to assign unique pipe addresses.
+var singleNodes uint64
+
+// NewSingleNode construct and start a single voter cluster node, backed by an in-memory storage,
+// and an in-process loopback transport, for local development and unit tests of the state machine logic.
+// It blocks until the node elected itself as the cluster leader,
+// and returns the started node, or the error returned by Start if it failed to start.
+//
+//	node, err := raft.NewSingleNode(fsm)
+//	if err != nil {
+//		return err
+//	}
+//	defer node.Shutdown(ctx)
+//	err = node.Replicate(ctx, data)
+//
+// The node state lost on shutdown, therefore it must not be used in production.
+// To graduate to a real cluster, replace NewSingleNode with a node of a durable state dir,
+// served over a network transport, then add the other members by AddMember or WithJoin:
+//
+//	node := raft.NewNode(fsm, transport.GRPC, raft.WithStateDIR(dir))
+//	raftgrpc.RegisterHandler(srv, node.Handler())
+//	go node.Start(raft.WithInitCluster(), raft.WithAddress(addr))
+//
+// The given options applied on top of WithInMemoryStorage.
+func NewSingleNode(fsm StateMachine, opts ...Option) (*Node, error) {
+	id := atomic.AddUint64(&singleNodes, 1)
+	addr := fmt.Sprintf("%ssingle-node-%d", raftlocal.PipeScheme, id)
+
+	opts = append([]Option{WithInMemoryStorage()}, opts...)
+	node := NewNode(fsm, etransport.LOCAL, opts...)
+	ready := node.Ready()
+
+	errc := make(chan error, 1)
+	go func() {
+		err := node.Start(WithInitCluster(), WithAddress(addr))
+		if !errors.Is(err, ErrNodeStopped) {
+			node.cfg.logger.Errorf("raft.node: single node %s stopped: %v", addr, err)
+		}
+		errc <- err
+	}()
+
+	select {
+	case <-ready:
+		return node, nil
+	case err := <-errc:
+		return nil, err
+	}
+}
@@ -0,0 +1,77 @@
+package raft
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type singleFSM struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (fsm *singleFSM) Apply(data []byte) error {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	_, err := fsm.buf.Write(data)
+	return err
+}
+
+func (fsm *singleFSM) Snapshot() (io.ReadCloser, error) {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(fsm.buf.Bytes())), nil
+}
+
+func (fsm *singleFSM) Restore(r io.ReadCloser) error {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	fsm.buf.Reset()
+	_, err := fsm.buf.ReadFrom(r)
+	return err
+}
+
+func (fsm *singleFSM) String() string {
+	fsm.mu.Lock()
+	defer fsm.mu.Unlock()
+	return fsm.buf.String()
+}
+
+func TestNewSingleNode(t *testing.T) {
+	ctx := context.Background()
+	fsm := new(singleFSM)
+
+	node, err := NewSingleNode(fsm)
+	require.NoError(t, err)
+	defer node.Shutdown(ctx) //nolint:errcheck
+
+	require.Equal(t, node.Whoami(), node.Leader())
+	require.Len(t, node.Members(), 1)
+
+	require.NoError(t, node.Replicate(ctx, []byte("a")))
+	require.NoError(t, node.Replicate(ctx, []byte("b")))
+	require.NoError(t, node.LinearizableRead(ctx))
+	require.Equal(t, "ab", fsm.String())
+
+	// it should export the in-memory snapshot in the snapshot file format.
+	r, err := node.Snapshot()
+	require.NoError(t, err)
+	defer r.Close()
+
+	path := filepath.Join(t.TempDir(), "snap")
+	buf, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, buf, 0600))
+
+	restored := new(singleFSM)
+	err = ValidateSnapshot(path, func() StateMachine { return restored })
+	require.NoError(t, err)
+	require.Equal(t, "ab", restored.String())
+}
@@ -0,0 +1,116 @@
+package raft
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader loads a certificate key pair from disk, and reloads it when the files change,
+// so short-lived certificates (e.g. issued by cert-manager or SPIFFE) rotate without
+// restarting the node or tearing down the established members connections.
+//
+// The files are checked on each TLS handshake, See CertReloader.TLSConfig.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTime  time.Time
+}
+
+// NewCertReloader returns a new CertReloader of the given certificate and key files.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if _, err := r.certificate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate implements tls.Config GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.certificate()
+}
+
+// GetClientCertificate implements tls.Config GetClientCertificate.
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.certificate()
+}
+
+// TLSConfig returns a copy of the given TLS config that serves the reloaded certificate,
+// to both the servers and the clients. It can be used with WithTLS and the transport server.
+func (r *CertReloader) TLSConfig(cfg *tls.Config) *tls.Config {
+	if cfg == nil {
+		cfg = new(tls.Config)
+	}
+
+	cfg = cfg.Clone()
+	cfg.Certificates = nil
+	cfg.GetCertificate = r.GetCertificate
+	cfg.GetClientCertificate = r.GetClientCertificate
+	return cfg
+}
+
+func (r *CertReloader) certificate() (*tls.Certificate, error) {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return r.fallback(err)
+	}
+
+	r.mu.RLock()
+	cert, ok := r.cert, r.modTime.Equal(modTime)
+	r.mu.RUnlock()
+
+	if ok {
+		return cert, nil
+	}
+
+	kp, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		// the files might be in the middle of a rotation.
+		return r.fallback(err)
+	}
+
+	r.mu.Lock()
+	r.cert, r.modTime = &kp, modTime
+	r.mu.Unlock()
+
+	return &kp, nil
+}
+
+// fallback returns the last loaded certificate if any, Otherwise, it returns the given error.
+func (r *CertReloader) fallback(err error) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.cert == nil {
+		return nil, fmt.Errorf("raft: loading certificate: %w", err)
+	}
+
+	return r.cert, nil
+}
+
+// lastModified returns the latest modification time of the certificate and key files.
+func (r *CertReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		stat, err := os.Stat(name)
+		if err != nil {
+			return latest, err
+		}
+
+		if stat.ModTime().After(latest) {
+			latest = stat.ModTime()
+		}
+	}
+
+	return latest, nil
+}
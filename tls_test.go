@@ -0,0 +1,83 @@
+package raft
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	// it return error when the files not found.
+	_, err := NewCertReloader(certFile, keyFile)
+	require.Error(t, err)
+
+	writeTestCert(t, certFile, keyFile, "first", time.Now().Add(-time.Minute))
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	cfg := r.TLSConfig(&tls.Config{ServerName: "raft"})
+	require.Equal(t, "raft", cfg.ServerName)
+
+	cert, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "first", leafName(t, cert))
+
+	// it reload the rotated certificate.
+	writeTestCert(t, certFile, keyFile, "second", time.Now())
+	cert, err = cfg.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "second", leafName(t, cert))
+
+	// it return the last loaded certificate when the files are invalid.
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0600))
+	cert, err = r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "second", leafName(t, cert))
+}
+
+func leafName(t *testing.T, cert *tls.Certificate) string {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func writeTestCert(t *testing.T, certFile, keyFile, name string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	require.NoError(t, os.WriteFile(certFile, certPem, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPem, 0600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
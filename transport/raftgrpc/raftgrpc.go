@@ -3,6 +3,7 @@ package raftgrpc
 
 import (
 	"context"
+	"time"
 
 	itransport "github.com/shaj13/raft/internal/transport"
 	"github.com/shaj13/raft/internal/transport/raftgrpc"
@@ -10,15 +11,27 @@ import (
 	"github.com/shaj13/raft/raftlog"
 	"github.com/shaj13/raft/transport"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 )
 
+// serverOptions are the server options derived from the registered options,
+// applied by NewServer.
+var serverOptions []grpc.ServerOption
+
 func init() {
 	Register()
 }
 
 type config struct {
-	copts func(context.Context) []grpc.CallOption
-	dopts func(context.Context) []grpc.DialOption
+	copts   func(context.Context) []grpc.CallOption
+	dopts   func(context.Context) []grpc.DialOption
+	unary   []grpc.UnaryClientInterceptor
+	stream  []grpc.StreamClientInterceptor
+	kaTime  time.Duration
+	kaWait  time.Duration
+	maxSend int
+	maxRecv int
 }
 
 // Option configures grpc using the functional options paradigm popularized by Rob Pike and Dave Cheney.
@@ -55,6 +68,47 @@ func WithDialOptions(opts ...grpc.DialOption) Option {
 	})
 }
 
+// WithUnaryInterceptors configures grpc client connections to chain the given unary interceptors,
+// e.g. auth, tracing, and quota middleware applied to the raft rpc's.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return optionFunc(func(c *config) {
+		c.unary = append(c.unary, interceptors...)
+	})
+}
+
+// WithStreamInterceptors configures grpc client connections to chain the given stream interceptors,
+// e.g. auth, tracing, and quota middleware applied to the raft rpc's.
+func WithStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return optionFunc(func(c *config) {
+		c.stream = append(c.stream, interceptors...)
+	})
+}
+
+// WithKeepalive configures grpc client connections to ping the members after the given time of inactivity,
+// and close the connection when the ping is not acknowledged within the given timeout,
+// so a dead member connection detected without waiting for the rpc timeouts.
+// The servers created by NewServer allow the clients pings at the given time.
+//
+// Default Value: grpc defaults, no keepalive pings.
+func WithKeepalive(t, timeout time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.kaTime = t
+		c.kaWait = timeout
+	})
+}
+
+// WithMaxMessageSize configures grpc client connections, and the servers created by NewServer,
+// with the given max size in bytes of the sent and received messages,
+// to not fail the large append messages and snapshot chunks, zero means the grpc default.
+//
+// Default Value: grpc defaults, math.MaxInt32 to send, and 4MB to receive.
+func WithMaxMessageSize(send, recv int) Option {
+	return optionFunc(func(c *config) {
+		c.maxSend = send
+		c.maxRecv = recv
+	})
+}
+
 // Register registers the gRPC for use with all clients and servers communication.
 //
 // NOTE: this function must only be called during initialization time (i.e. in
@@ -68,7 +122,18 @@ func Register(opts ...Option) {
 		opt.apply(c)
 	}
 
-	dialer := raftgrpc.Dialer(c.dopts, c.copts)
+	extra, sopts := c.options()
+	serverOptions = sopts
+
+	dopts := c.dopts
+	if len(extra) > 0 {
+		dopts = func(ctx context.Context) []grpc.DialOption {
+			opts := c.dopts(ctx)
+			return append(opts[:len(opts):len(opts)], extra...)
+		}
+	}
+
+	dialer := raftgrpc.Dialer(dopts, c.copts)
 	nh := raftgrpc.NewHandler
 
 	itransport.GRPC.Register(nh, dialer)
@@ -83,3 +148,83 @@ func RegisterHandler(s *grpc.Server, h transport.Handler) {
 
 	raftlog.Fatalf("raft.grpc: type %T does not implement gRPC transport handler", h)
 }
+
+// RegisterHealthHandler registers the gRPC health service of the transport handler to the gRPC server,
+// that reflects the raft node health, serving only when the node has an elected leader,
+// and its applied index is advancing, See raft.Node.Health.
+//
+// The health service name is the node group id, or empty for the default group,
+// so load balancers and Kubernetes gRPC probes can gate traffic on raft health.
+func RegisterHealthHandler(s *grpc.Server, h transport.Handler) {
+	if hs, ok := h.(healthpb.HealthServer); ok {
+		healthpb.RegisterHealthServer(s, hs)
+		return
+	}
+
+	raftlog.Fatalf("raft.grpc: type %T does not implement gRPC health handler", h)
+}
+
+// NewServer return's gRPC server created from the given options, e.g. grpc.ChainUnaryInterceptor,
+// with the transport handler and its health service registered.
+//
+// The server applies the keepalive and max message size registered options, See Register,
+// unless overridden by the given options.
+func NewServer(h transport.Handler, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(append(serverOptions[:len(serverOptions):len(serverOptions)], opts...)...)
+	RegisterHandler(s, h)
+	RegisterHealthHandler(s, h)
+	return s
+}
+
+// options returns the client dial options and the server options derived from the config.
+// the dial options appended to the user dial options, so they take precedence.
+func (c *config) options() ([]grpc.DialOption, []grpc.ServerOption) {
+	var (
+		dopts []grpc.DialOption
+		sopts []grpc.ServerOption
+		copts []grpc.CallOption
+	)
+
+	if len(c.unary) > 0 || len(c.stream) > 0 {
+		dopts = append(
+			dopts,
+			grpc.WithChainUnaryInterceptor(c.unary...),
+			grpc.WithChainStreamInterceptor(c.stream...),
+		)
+	}
+
+	if c.kaTime > 0 {
+		dopts = append(dopts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.kaTime,
+			Timeout:             c.kaWait,
+			PermitWithoutStream: true,
+		}))
+		sopts = append(
+			sopts,
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				Time:    c.kaTime,
+				Timeout: c.kaWait,
+			}),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             c.kaTime,
+				PermitWithoutStream: true,
+			}),
+		)
+	}
+
+	if c.maxSend > 0 {
+		copts = append(copts, grpc.MaxCallSendMsgSize(c.maxSend))
+		sopts = append(sopts, grpc.MaxSendMsgSize(c.maxSend))
+	}
+
+	if c.maxRecv > 0 {
+		copts = append(copts, grpc.MaxCallRecvMsgSize(c.maxRecv))
+		sopts = append(sopts, grpc.MaxRecvMsgSize(c.maxRecv))
+	}
+
+	if len(copts) > 0 {
+		dopts = append(dopts, grpc.WithDefaultCallOptions(copts...))
+	}
+
+	return dopts, sopts
+}
@@ -75,6 +75,11 @@ func Register(opts ...Option) {
 }
 
 // Handler return's http.Handler for http transport server.
+//
+// The handler serves a healthz endpoint under the base path, e.g. GET /_raft/healthz,
+// that responds with 200 only when the raft node has an elected leader, and its applied index is advancing,
+// otherwise 503, so load balancers and Kubernetes probes can gate traffic on raft health, See raft.Node.Health.
+// The group id of the node selected by the group query parameter, e.g. /_raft/healthz?group=1.
 func Handler(h transport.Handler) http.Handler {
 	if h, ok := h.(http.Handler); ok {
 		return h
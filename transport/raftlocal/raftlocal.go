@@ -0,0 +1,106 @@
+// Package raftlocal implements unix domain socket and in-process pipe transportation layer for raft.
+//
+// The local transport avoids TCP entirely, for single-host multi-process topologies over unix domain sockets,
+// e.g. unix:///var/run/raft-1.sock, or multiple nodes within the same process (tests) over in-memory pipes,
+// e.g. pipe://node-1. The members addresses must be set with the same scheme.
+package raftlocal
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+
+	itransport "github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/internal/transport/raftgrpc/pb"
+	"github.com/shaj13/raft/internal/transport/raftlocal"
+	"github.com/shaj13/raft/raftlog"
+	"github.com/shaj13/raft/transport"
+)
+
+const (
+	// UnixScheme is the address scheme of the unix domain sockets, e.g. unix:///tmp/raft.sock.
+	UnixScheme = raftlocal.UnixScheme
+	// PipeScheme is the address scheme of the in-process pipes, e.g. pipe://node-1.
+	PipeScheme = raftlocal.PipeScheme
+)
+
+func init() {
+	Register()
+}
+
+type config struct {
+	copts func(context.Context) []grpc.CallOption
+	dopts func(context.Context) []grpc.DialOption
+}
+
+// Option configures local using the functional options paradigm popularized by Rob Pike and Dave Cheney.
+// If you're unfamiliar with this style,
+// see https://commandcenter.blogspot.com/2014/01/self-referential-functions-and-design.html and
+// https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis.
+type Option interface {
+	apply(c *config)
+}
+
+// OptionFunc implements Option interface.
+type optionFunc func(c *config)
+
+// Apply the configuration to the provided strategy.
+func (fn optionFunc) apply(c *config) {
+	fn(c)
+}
+
+// WithCallOptions configures grpc client call from the given options.
+func WithCallOptions(opts ...grpc.CallOption) Option {
+	return optionFunc(func(c *config) {
+		c.copts = func(c context.Context) []grpc.CallOption {
+			return opts
+		}
+	})
+}
+
+// WithDialOptions configures grpc dial from the given options.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return optionFunc(func(c *config) {
+		c.dopts = func(c context.Context) []grpc.DialOption {
+			return opts
+		}
+	})
+}
+
+// Register registers the local transport for use with all clients and servers communication.
+//
+// NOTE: this function must only be called during initialization time (i.e. in
+// an init() function), and is not thread-safe.
+func Register(opts ...Option) {
+	c := new(config)
+	c.copts = func(c context.Context) []grpc.CallOption { return nil }
+	c.dopts = func(c context.Context) []grpc.DialOption { return nil }
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	dialer := raftlocal.Dialer(c.dopts, c.copts)
+	nh := raftlocal.NewHandler
+
+	itransport.LOCAL.Register(nh, dialer)
+}
+
+// Listen announces on the given unix domain socket or in-process pipe address.
+func Listen(addr string) (net.Listener, error) {
+	return raftlocal.Listen(addr)
+}
+
+// NewServer return's gRPC server that serves the transport handler rpc's,
+// on the listeners returned by Listen.
+func NewServer(h transport.Handler, opts ...grpc.ServerOption) *grpc.Server {
+	rs, ok := h.(pb.RaftServer)
+	if !ok {
+		raftlog.Fatalf("raft.local: type %T does not implement local transport handler", h)
+	}
+
+	s := grpc.NewServer(opts...)
+	pb.RegisterRaftServer(s, rs)
+	return s
+}
@@ -0,0 +1,66 @@
+// Package raftmux multiplexes the raft transport and the application API on a single listener.
+//
+// The gRPC transport handler co-registered with the application gRPC services on the same server,
+// See raftgrpc.RegisterHandler, and the HTTP transport handler mounted on the application HTTP mux,
+// See rafthttp.Handler. The connections are dispatched by sniffing their first bytes,
+// the HTTP/2 connections to the gRPC server, and the others to the HTTP server.
+package raftmux
+
+import (
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+
+	"github.com/shaj13/raft/internal/transport/raftmux"
+)
+
+// ErrListenerClosed is returned by the mux listeners Accept method when the listener or mux closed.
+var ErrListenerClosed = raftmux.ErrListenerClosed
+
+// Mux multiplexes the connections accepted on a single listener, by sniffing their first bytes,
+// the HTTP/2 connections (gRPC) dispatched to the GRPC listener, and the others to the HTTP listener.
+//
+// The sniffing requires plain connections, therefore TLS must be terminated
+// by the given listener, e.g. tls.NewListener.
+type Mux = raftmux.Mux
+
+// New return's a new Mux that dispatches the connections accepted on the given listener,
+// Mux.Serve must be called to start accepting the connections.
+func New(ln net.Listener) *Mux {
+	return raftmux.New(ln)
+}
+
+// Serve serves the given gRPC server and HTTP handler on the given listener,
+// either may be nil when unused. It blocks until one of the servers stops,
+// then stops the others and return its error.
+func Serve(ln net.Listener, gs *grpc.Server, h http.Handler) error {
+	m := raftmux.New(ln)
+	errc := make(chan error, 3)
+
+	var hs *http.Server
+	if h != nil {
+		hs = &http.Server{Handler: h}
+		go func() { errc <- hs.Serve(m.HTTP()) }()
+	}
+
+	if gs != nil {
+		go func() { errc <- gs.Serve(m.GRPC()) }()
+	}
+
+	go func() { errc <- m.Serve() }()
+
+	err := <-errc
+
+	if gs != nil {
+		gs.Stop()
+	}
+
+	if hs != nil {
+		_ = hs.Close()
+	}
+
+	_ = m.Close()
+
+	return err
+}
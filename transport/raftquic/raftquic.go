@@ -0,0 +1,100 @@
+// Package raftquic implements QUIC transportation layer for raft.
+//
+// QUIC multiplexes the raft rpc's over a single connection per member, without head-of-line blocking,
+// and recovers faster from packet loss, e.g. over lossy WAN links between datacenters.
+// QUIC connections are always encrypted, therefore a TLS config must be provided,
+// using raft.WithTLS or WithTLSConfig.
+package raftquic
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	itransport "github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/internal/transport/raftquic"
+	"github.com/shaj13/raft/raftlog"
+	"github.com/shaj13/raft/transport"
+)
+
+// NextProto is the TLS application protocol negotiated by the raft QUIC transport.
+const NextProto = raftquic.NextProto
+
+func init() {
+	Register()
+}
+
+type config struct {
+	tls  *tls.Config
+	quic *quic.Config
+}
+
+// Option configures quic using the functional options paradigm popularized by Rob Pike and Dave Cheney.
+// If you're unfamiliar with this style,
+// see https://commandcenter.blogspot.com/2014/01/self-referential-functions-and-design.html and
+// https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis.
+type Option interface {
+	apply(c *config)
+}
+
+// OptionFunc implements Option interface.
+type optionFunc func(c *config)
+
+// Apply the configuration to the provided strategy.
+func (fn optionFunc) apply(c *config) {
+	fn(c)
+}
+
+// WithTLSConfig configures the quic client TLS config,
+// The TLS config set by raft.WithTLS takes precedence.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return optionFunc(func(c *config) {
+		c.tls = cfg
+	})
+}
+
+// WithQUICConfig configures the quic client connections.
+// Default: keep alive period of 10s.
+func WithQUICConfig(cfg *quic.Config) Option {
+	return optionFunc(func(c *config) {
+		c.quic = cfg
+	})
+}
+
+// Register registers the QUIC for use with all clients and servers communication.
+//
+// NOTE: this function must only be called during initialization time (i.e. in
+// an init() function), and is not thread-safe.
+func Register(opts ...Option) {
+	c := new(config)
+	c.quic = &quic.Config{KeepAlivePeriod: 10 * time.Second}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	dialer := raftquic.Dialer(c.tls, c.quic)
+	nh := raftquic.NewHandler
+
+	itransport.QUIC.Register(nh, dialer)
+}
+
+// Listen creates a QUIC listener on the given network address,
+// that negotiates the raft QUIC transport application protocol.
+func Listen(addr string, tlsConf *tls.Config, conf *quic.Config) (*quic.Listener, error) {
+	tlsConf = tlsConf.Clone()
+	tlsConf.NextProtos = []string{NextProto}
+	return quic.ListenAddr(addr, tlsConf, conf)
+}
+
+// Serve accepts incoming connections on the given listener,
+// and serves the transport handler rpc's until the listener closed.
+func Serve(ln *quic.Listener, h transport.Handler) error {
+	if s, ok := h.(raftquic.Server); ok {
+		return s.Serve(ln)
+	}
+
+	raftlog.Fatalf("raft.quic: type %T does not implement QUIC transport handler", h)
+	return nil
+}
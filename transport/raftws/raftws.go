@@ -0,0 +1,105 @@
+// Package raftws implements websocket transportation layer for raft.
+//
+// The websocket transport tunnels the HTTP transport requests over websocket connections,
+// so the raft traffic traverse the HTTP-only proxies and middleboxes where gRPC and h2c blocked.
+// The members addresses may be set with the ws, wss, http, or https scheme.
+package raftws
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+
+	itransport "github.com/shaj13/raft/internal/transport"
+	"github.com/shaj13/raft/internal/transport/raftws"
+	"github.com/shaj13/raft/raftlog"
+	"github.com/shaj13/raft/transport"
+)
+
+func init() {
+	Register()
+}
+
+type config struct {
+	dialer   func(context.Context) *websocket.Dialer
+	basePath string
+}
+
+// Option configures websocket using the functional options paradigm popularized by Rob Pike and Dave Cheney.
+// If you're unfamiliar with this style,
+// see https://commandcenter.blogspot.com/2014/01/self-referential-functions-and-design.html and
+// https://dave.cheney.net/2014/10/17/functional-options-for-friendly-apis.
+type Option interface {
+	apply(c *config)
+}
+
+// OptionFunc implements Option interface.
+type optionFunc func(c *config)
+
+// Apply the configuration to the provided strategy.
+func (fn optionFunc) apply(c *config) {
+	fn(c)
+}
+
+// WithDialer optionally specifies the websocket dialer for the client
+// to use when it connects to the members, e.g. to set the proxy, or handshake timeout.
+// Default: websocket.DefaultDialer, which uses the proxy from the environment.
+func WithDialer(d *websocket.Dialer) Option {
+	return optionFunc(func(c *config) {
+		c.dialer = func(context.Context) *websocket.Dialer {
+			return d
+		}
+	})
+}
+
+// WithProxy specifies the proxy the client connects through to the members.
+// Default: http.ProxyFromEnvironment.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return optionFunc(func(c *config) {
+		d := *websocket.DefaultDialer
+		d.Proxy = proxy
+		c.dialer = func(context.Context) *websocket.Dialer {
+			return &d
+		}
+	})
+}
+
+// WithBasePath specifies the HTTP path that will serve raft requests.
+// Default: "/_raft/".
+func WithBasePath(basePath string) Option {
+	return optionFunc(func(c *config) {
+		c.basePath = basePath
+	})
+}
+
+// Register registers the websocket for use with all clients and servers communication.
+//
+// NOTE: this function must only be called during initialization time (i.e. in
+// an init() function), and is not thread-safe.
+func Register(opts ...Option) {
+	c := new(config)
+	c.dialer = func(context.Context) *websocket.Dialer { return websocket.DefaultDialer }
+	c.basePath = "/_raft/"
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	dialer := raftws.Dialer(c.dialer, c.basePath)
+	nh := raftws.NewHandlerFunc(c.basePath)
+
+	itransport.WS.Register(nh, dialer)
+}
+
+// Handler return's http.Handler for websocket transport server,
+// it serves the plain HTTP transport requests as well.
+func Handler(h transport.Handler) http.Handler {
+	if h, ok := h.(http.Handler); ok {
+		return h
+	}
+
+	raftlog.Fatalf("raft.ws: type %T does not implement websocket transport handler", h)
+	return nil
+}
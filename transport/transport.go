@@ -8,6 +8,14 @@ const (
 	GRPC Proto = Proto(transport.GRPC)
 	// HTTP represents raft transportation using http.
 	HTTP Proto = Proto(transport.HTTP)
+	// QUIC represents raft transportation using QUIC.
+	QUIC Proto = Proto(transport.QUIC)
+	// LOCAL represents raft transportation using unix domain sockets or in-process pipes.
+	LOCAL Proto = Proto(transport.LOCAL)
+	// WS represents raft transportation using websocket.
+	WS Proto = Proto(transport.WS)
+	// SIM represents raft transportation over the in-process simulated network of rafttest.
+	SIM Proto = Proto(transport.SIM)
 )
 
 // Proto is a portmanteau of protocol